@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+)
+
+// defaultCodes 默认压测的股票代码，覆盖沪深主板、创业板和科创板
+var defaultCodes = []string{
+	"600519.SH", // 贵州茅台
+	"601899.SH", // 紫金矿业
+	"600036.SH", // 招商银行
+	"000001.SZ", // 平安银行
+	"000858.SZ", // 五粮液
+	"002594.SZ", // 比亚迪
+	"300750.SZ", // 宁德时代
+	"300059.SZ", // 东方财富
+	"688981.SH", // 中芯国际
+	"688111.SH", // 金山办公
+}
+
+func main() {
+	codesFlag := flag.String("codes", strings.Join(defaultCodes, ","), "压测的股票代码，逗号分隔")
+	periodsFlag := flag.String("periods", "daily,weekly,monthly,yearly", "压测的K线周期，逗号分隔")
+	years := flag.Int("years", 5, "每次请求的K线年数")
+	firstN := flag.Int("first-n", 0, "分别统计前N次请求（冷启动）和其余请求（稳定阶段），0表示不区分")
+	version := flag.String("version", "dev", "被测版本，写入压测结果")
+	output := flag.String("output", "", "压测结果输出的JSON文件路径，为空时不保存")
+	compare := flag.String("compare", "", "与之对比的上次压测结果JSON文件路径")
+	threshold := flag.Float64("threshold", 10, "对比时判定为性能退化的变化幅度，单位：%")
+	cacheDir := flag.String("cache-dir", "", "采集响应缓存目录，为空时不缓存；开启后重复请求不访问上游，只用于调试压测流程")
+	flag.Parse()
+
+	logger.InitGlobalLogger(logger.LogConfig{Level: "warn", Format: "text"})
+	factory := collector.GetCollectorFactory(logger.GetGlobalLogger())
+	factory.ApplyResponseCache(collector.ResponseCacheConfig{Enabled: *cacheDir != "", Dir: *cacheDir})
+	c := factory.GetEastMoneyCollector()
+
+	codes := strings.Split(*codesFlag, ",")
+	endDate := time.Now()
+	startDate := endDate.AddDate(-*years, 0, 0)
+
+	fetchers := map[string]func(tsCode string) (int, error){
+		"daily": func(tsCode string) (int, error) {
+			data, err := c.GetDailyKLine(tsCode, startDate, endDate)
+			return len(data), err
+		},
+		"weekly": func(tsCode string) (int, error) {
+			data, err := c.GetWeeklyKLine(tsCode, startDate, endDate)
+			return len(data), err
+		},
+		"monthly": func(tsCode string) (int, error) {
+			data, err := c.GetMonthlyKLine(tsCode, startDate, endDate)
+			return len(data), err
+		},
+		"yearly": func(tsCode string) (int, error) {
+			data, err := c.GetYearlyKLine(tsCode, startDate, endDate)
+			return len(data), err
+		},
+	}
+
+	report := &BenchmarkReport{
+		Date:    time.Now().Format(time.RFC3339),
+		Version: *version,
+		Codes:   codes,
+	}
+
+	fmt.Printf("K线采集压测：%d 只股票，最近 %d 年\n", len(codes), *years)
+	fmt.Printf("===========================================\n")
+
+	for _, period := range strings.Split(*periodsFlag, ",") {
+		fetch, ok := fetchers[period]
+		if !ok {
+			fmt.Printf("不支持的K线周期: %s\n", period)
+			os.Exit(1)
+		}
+
+		samples := make([]requestSample, 0, len(codes))
+		for _, tsCode := range codes {
+			start := time.Now()
+			records, err := fetch(tsCode)
+			samples = append(samples, requestSample{Records: records, Latency: time.Since(start), Failed: err != nil})
+			if err != nil {
+				fmt.Printf("  [%s] 获取 %s 失败: %v\n", period, tsCode, err)
+			}
+		}
+
+		result := buildPeriodResult(period, samples, *firstN)
+		report.Periods = append(report.Periods, result)
+		printPeriodResult(result)
+	}
+
+	if *output != "" {
+		if err := SaveReport(*output, report); err != nil {
+			fmt.Printf("保存压测结果失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("压测结果已保存到 %s\n", *output)
+	}
+
+	if *compare != "" {
+		previous, err := LoadReport(*compare)
+		if err != nil {
+			fmt.Printf("读取对比结果失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		regressions := CompareReports(previous, report, *threshold)
+		if len(regressions) == 0 {
+			fmt.Printf("与 %s (%s) 相比没有超过 %.1f%% 的性能退化\n", previous.Version, previous.Date, *threshold)
+			return
+		}
+
+		fmt.Printf("与 %s (%s) 相比发现 %d 项性能退化：\n", previous.Version, previous.Date, len(regressions))
+		for _, r := range regressions {
+			fmt.Printf("  [%s] %s: %.2f -> %.2f (变差 %.1f%%)\n", r.Period, r.Metric, r.Previous, r.Current, r.ChangePct)
+		}
+		os.Exit(1)
+	}
+}
+
+// printPeriodResult 输出单个周期的压测结果
+func printPeriodResult(result PeriodResult) {
+	printStats := func(label string, s SampleStats) {
+		fmt.Printf("  %-6s 请求 %d 次（失败 %d），记录 %d 条，%.1f 条/秒，平均耗时 %.1f ms\n",
+			label, s.Requests, s.Failed, s.Records, s.RecordsPerSec, s.AvgLatencyMs)
+	}
+
+	fmt.Printf("[%s]\n", result.Period)
+	printStats("全部", result.Total)
+	if result.FirstN != nil && result.Rest != nil {
+		printStats("前N次", *result.FirstN)
+		printStats("其余", *result.Rest)
+	}
+}
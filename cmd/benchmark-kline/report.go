@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// requestSample 单次K线请求的采样数据
+type requestSample struct {
+	Records int           // 返回的记录条数
+	Latency time.Duration // 请求耗时
+	Failed  bool          // 请求是否失败
+}
+
+// SampleStats 一组请求的统计结果
+type SampleStats struct {
+	Requests      int     `json:"requests"`        // 请求次数
+	Failed        int     `json:"failed"`          // 失败次数
+	Records       int     `json:"records"`         // 记录总数
+	RecordsPerSec float64 `json:"records_per_sec"` // 每秒处理记录数
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`  // 平均请求耗时，单位：毫秒
+}
+
+// PeriodResult 单个K线周期的压测结果
+type PeriodResult struct {
+	Period string       `json:"period"`            // K线周期：daily、weekly、monthly、yearly
+	Total  SampleStats  `json:"total"`             // 全部请求的统计
+	FirstN *SampleStats `json:"first_n,omitempty"` // 前N次请求（冷启动阶段）的统计，未开启采样时为空
+	Rest   *SampleStats `json:"rest,omitempty"`    // 其余请求（稳定阶段）的统计，未开启采样时为空
+}
+
+// BenchmarkReport 一次压测的完整结果
+type BenchmarkReport struct {
+	Date    string         `json:"date"`    // 压测时间，RFC3339格式
+	Version string         `json:"version"` // 被测版本
+	Codes   []string       `json:"codes"`   // 压测使用的股票代码
+	Periods []PeriodResult `json:"periods"` // 各周期的压测结果
+}
+
+// Regression 两次压测之间的指标变化
+type Regression struct {
+	Period    string  `json:"period"`     // K线周期
+	Metric    string  `json:"metric"`     // 指标名称：records_per_sec、avg_latency_ms
+	Previous  float64 `json:"previous"`   // 上次结果
+	Current   float64 `json:"current"`    // 本次结果
+	ChangePct float64 `json:"change_pct"` // 变化百分比，正数表示变差
+}
+
+// summarize 汇总一组采样数据，吞吐按请求耗时之和计算，与请求之间的限流等待无关
+func summarize(samples []requestSample) SampleStats {
+	stats := SampleStats{Requests: len(samples)}
+	var elapsed time.Duration
+	for _, s := range samples {
+		if s.Failed {
+			stats.Failed++
+		}
+		stats.Records += s.Records
+		elapsed += s.Latency
+	}
+
+	if len(samples) > 0 {
+		stats.AvgLatencyMs = float64(elapsed.Microseconds()) / 1000 / float64(len(samples))
+	}
+	if elapsed > 0 {
+		stats.RecordsPerSec = float64(stats.Records) / elapsed.Seconds()
+	}
+	return stats
+}
+
+// buildPeriodResult 生成单个周期的压测结果，firstN>0且请求数多于firstN时分别统计前N次和其余请求
+func buildPeriodResult(period string, samples []requestSample, firstN int) PeriodResult {
+	result := PeriodResult{Period: period, Total: summarize(samples)}
+	if firstN > 0 && len(samples) > firstN {
+		first := summarize(samples[:firstN])
+		rest := summarize(samples[firstN:])
+		result.FirstN = &first
+		result.Rest = &rest
+	}
+	return result
+}
+
+// CompareReports 对比两次压测结果，返回变差幅度超过threshold（百分比）的指标
+// 吞吐下降和耗时上升都视为变差；上次结果中不存在的周期不参与对比
+func CompareReports(previous, current *BenchmarkReport, threshold float64) []Regression {
+	prevPeriods := make(map[string]SampleStats, len(previous.Periods))
+	for _, p := range previous.Periods {
+		prevPeriods[p.Period] = p.Total
+	}
+
+	var regressions []Regression
+	for _, p := range current.Periods {
+		prev, ok := prevPeriods[p.Period]
+		if !ok {
+			continue
+		}
+
+		// 吞吐下降的百分比
+		if prev.RecordsPerSec > 0 {
+			change := (prev.RecordsPerSec - p.Total.RecordsPerSec) / prev.RecordsPerSec * 100
+			if change > threshold {
+				regressions = append(regressions, Regression{
+					Period: p.Period, Metric: "records_per_sec",
+					Previous: prev.RecordsPerSec, Current: p.Total.RecordsPerSec, ChangePct: change,
+				})
+			}
+		}
+
+		// 平均耗时上升的百分比
+		if prev.AvgLatencyMs > 0 {
+			change := (p.Total.AvgLatencyMs - prev.AvgLatencyMs) / prev.AvgLatencyMs * 100
+			if change > threshold {
+				regressions = append(regressions, Regression{
+					Period: p.Period, Metric: "avg_latency_ms",
+					Previous: prev.AvgLatencyMs, Current: p.Total.AvgLatencyMs, ChangePct: change,
+				})
+			}
+		}
+	}
+	return regressions
+}
+
+// LoadReport 从JSON文件读取压测结果
+func LoadReport(path string) (*BenchmarkReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report %s failed: %w", path, err)
+	}
+
+	var report BenchmarkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse report %s failed: %w", path, err)
+	}
+	return &report, nil
+}
+
+// SaveReport 将压测结果写入JSON文件
+func SaveReport(path string, report *BenchmarkReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report failed: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write report %s failed: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPeriodResult(t *testing.T) {
+	samples := []requestSample{
+		{Records: 100, Latency: 400 * time.Millisecond},
+		{Records: 100, Latency: 100 * time.Millisecond},
+		{Records: 100, Latency: 100 * time.Millisecond},
+		{Records: 0, Latency: 200 * time.Millisecond, Failed: true},
+	}
+
+	result := buildPeriodResult("daily", samples, 1)
+	assert.Equal(t, "daily", result.Period)
+	assert.Equal(t, 4, result.Total.Requests)
+	assert.Equal(t, 1, result.Total.Failed)
+	assert.Equal(t, 300, result.Total.Records)
+	assert.InDelta(t, 375.0, result.Total.RecordsPerSec, 1e-9)
+	assert.InDelta(t, 200.0, result.Total.AvgLatencyMs, 1e-9)
+
+	require.NotNil(t, result.FirstN)
+	require.NotNil(t, result.Rest)
+	assert.InDelta(t, 400.0, result.FirstN.AvgLatencyMs, 1e-9)
+	assert.Equal(t, 3, result.Rest.Requests)
+	assert.InDelta(t, 500.0, result.Rest.RecordsPerSec, 1e-9)
+
+	// 未开启采样或请求数不足时不拆分
+	assert.Nil(t, buildPeriodResult("daily", samples, 0).FirstN)
+	assert.Nil(t, buildPeriodResult("daily", samples, 4).Rest)
+}
+
+func TestCompareReports(t *testing.T) {
+	previous := &BenchmarkReport{Version: "v1", Periods: []PeriodResult{
+		{Period: "daily", Total: SampleStats{RecordsPerSec: 1000, AvgLatencyMs: 100}},
+		{Period: "weekly", Total: SampleStats{RecordsPerSec: 500, AvgLatencyMs: 80}},
+		{Period: "monthly", Total: SampleStats{RecordsPerSec: 200, AvgLatencyMs: 50}},
+	}}
+	current := &BenchmarkReport{Version: "v2", Periods: []PeriodResult{
+		{Period: "daily", Total: SampleStats{RecordsPerSec: 850, AvgLatencyMs: 105}},  // 吞吐下降15%，耗时上升5%
+		{Period: "weekly", Total: SampleStats{RecordsPerSec: 600, AvgLatencyMs: 100}}, // 吞吐提升，耗时上升25%
+		{Period: "monthly", Total: SampleStats{RecordsPerSec: 195, AvgLatencyMs: 52}}, // 波动在阈值内
+		{Period: "yearly", Total: SampleStats{RecordsPerSec: 10, AvgLatencyMs: 500}},  // 上次没有该周期
+	}}
+
+	regressions := CompareReports(previous, current, 10)
+	require.Len(t, regressions, 2)
+
+	assert.Equal(t, "daily", regressions[0].Period)
+	assert.Equal(t, "records_per_sec", regressions[0].Metric)
+	assert.InDelta(t, 15.0, regressions[0].ChangePct, 1e-9)
+
+	assert.Equal(t, "weekly", regressions[1].Period)
+	assert.Equal(t, "avg_latency_ms", regressions[1].Metric)
+	assert.InDelta(t, 25.0, regressions[1].ChangePct, 1e-9)
+
+	// 提高阈值后不再判定为退化
+	assert.Empty(t, CompareReports(previous, current, 30))
+}
+
+func TestSaveAndLoadReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "benchmark.json")
+	report := &BenchmarkReport{
+		Date:    "2025-01-01T00:00:00+08:00",
+		Version: "v1",
+		Codes:   []string{"600519.SH"},
+		Periods: []PeriodResult{{Period: "daily", Total: SampleStats{Requests: 1, Records: 250}}},
+	}
+
+	require.NoError(t, SaveReport(path, report))
+	loaded, err := LoadReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report, loaded)
+
+	_, err = LoadReport(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
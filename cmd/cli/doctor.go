@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/config"
+	"stock/internal/database"
+	"stock/internal/logger"
+	"stock/internal/notification"
+	"stock/internal/utils"
+)
+
+// doctorProbeTimeout 单个连通性探测的超时时间
+const doctorProbeTimeout = 10 * time.Second
+
+// doctorProbeCode 探测采集器时请求K线的股票，选择长期正常交易的平安银行
+const doctorProbeCode = "000001.SZ"
+
+// doctorCollectors doctor探测的采集器，与定时任务和Web服务实际使用的数据源一致
+var doctorCollectors = []string{"eastmoney", "tonghuashun"}
+
+// doctorStatus 自检项的结果
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "PASS"
+	doctorWarn doctorStatus = "WARN" // 不影响启动，但可能不是预期的配置
+	doctorFail doctorStatus = "FAIL"
+)
+
+// doctorCheck 单个自检项的结果
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+}
+
+// doctorProbes doctor使用的连通性探测，测试时替换为不访问网络的实现
+type doctorProbes struct {
+	database  func(cfg *config.Config) error
+	webhook   func(ctx context.Context, url string) error
+	collector func(ctx context.Context, name string) error
+}
+
+// defaultDoctorProbes 访问真实数据库、Webhook和采集器的探测
+func defaultDoctorProbes(log *logger.Logger) doctorProbes {
+	client := &http.Client{Timeout: doctorProbeTimeout}
+	return doctorProbes{
+		database: func(cfg *config.Config) error {
+			dbManager, err := database.NewDatabase(&cfg.Database, log)
+			if err != nil {
+				return err
+			}
+			defer dbManager.Close()
+			return dbManager.HealthCheck()
+		},
+		// 只检查Webhook地址能否连通，不发送消息，任何HTTP响应都视为可达
+		webhook: func(ctx context.Context, url string) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			return resp.Body.Close()
+		},
+		collector: func(ctx context.Context, name string) error {
+			collectors := collector.GetCollectorFactory(log).CreateDefaultCollectors()
+			dataCollector, ok := collectors[name]
+			if !ok {
+				return fmt.Errorf("collector %s not found", name)
+			}
+			if err := dataCollector.Connect(); err != nil {
+				return fmt.Errorf("failed to connect: %w", err)
+			}
+			// 最近30天至少包含一个交易日，长假期间也能取到K线
+			endDate := time.Now()
+			data, err := dataCollector.GetDailyKLine(doctorProbeCode, endDate.AddDate(0, 0, -30), endDate)
+			if err != nil {
+				return err
+			}
+			if len(data) == 0 {
+				return fmt.Errorf("no daily K-line returned for %s", doctorProbeCode)
+			}
+			return nil
+		},
+	}
+}
+
+// doctor 执行部署前自检并输出清单，不初始化服务，配置或连接有问题时也能运行，有失败项时返回非0退出码
+func doctor() int {
+	cfg, err := config.Load()
+	if err != nil {
+		printDoctorReport(os.Stdout, []doctorCheck{{"config file", doctorFail, err.Error()}})
+		return 1
+	}
+
+	checks := append([]doctorCheck{{"config file", doctorPass, ""}},
+		runDoctor(context.Background(), cfg, defaultDoctorProbes(logger.NewLogger(cfg.Log)))...)
+	if printDoctorReport(os.Stdout, checks) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runDoctor 依次校验配置、数据库连接、已启用的通知Webhook和采集器，返回所有自检项的结果
+// 配置校验失败不中止后续检查，一次列出所有问题
+func runDoctor(ctx context.Context, cfg *config.Config, probes doctorProbes) []doctorCheck {
+	checks := validateDoctorConfig(cfg)
+
+	if err := probes.database(cfg); err != nil {
+		checks = append(checks, doctorCheck{"database connection", doctorFail, err.Error()})
+	} else {
+		checks = append(checks, doctorCheck{"database connection", doctorPass,
+			fmt.Sprintf("%s@%s:%d/%s", cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)})
+	}
+
+	for _, webhook := range doctorWebhooks(cfg.Notify) {
+		name := webhook.name + " webhook"
+		probeCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+		err := probes.webhook(probeCtx, webhook.url)
+		cancel()
+		if err != nil {
+			checks = append(checks, doctorCheck{name, doctorFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{name, doctorPass, "reachable"})
+		}
+	}
+
+	for _, name := range doctorCollectors {
+		probeCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+		err := probes.collector(probeCtx, name)
+		cancel()
+		if err != nil {
+			checks = append(checks, doctorCheck{"collector " + name, doctorFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{"collector " + name, doctorPass, "daily K-line of " + doctorProbeCode + " fetched"})
+		}
+	}
+	return checks
+}
+
+// doctorWebhook 已启用的通知Webhook
+type doctorWebhook struct {
+	name string
+	url  string
+}
+
+// doctorWebhooks 获取已启用且配置了地址的通知机器人和事件Webhook
+func doctorWebhooks(notify notification.Config) []doctorWebhook {
+	var webhooks []doctorWebhook
+	if notify.DingTalk != nil && notify.DingTalk.Enabled && notify.DingTalk.Webhook != "" {
+		webhooks = append(webhooks, doctorWebhook{"dingtalk", notify.DingTalk.Webhook})
+	}
+	if notify.WeWork != nil && notify.WeWork.Enabled && notify.WeWork.Webhook != "" {
+		webhooks = append(webhooks, doctorWebhook{"wework", notify.WeWork.Webhook})
+	}
+	if events := notify.Events; events != nil && events.Webhook != nil && events.Webhook.Enabled && events.Webhook.URL != "" {
+		webhooks = append(webhooks, doctorWebhook{"event", events.Webhook.URL})
+	}
+	return webhooks
+}
+
+// validateDoctorConfig 校验必填配置项和取值范围，不访问网络
+func validateDoctorConfig(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+	check := func(name string, err error) {
+		if err != nil {
+			checks = append(checks, doctorCheck{name, doctorFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{name, doctorPass, ""})
+		}
+	}
+
+	check("database config", validateDatabaseConfig(cfg.Database))
+
+	location, err := time.LoadLocation(cfg.App.Timezone)
+	check("app.timezone", err)
+	if err == nil {
+		session, err := utils.NewMarketSession(cfg.Worker.MarketCloseTime, location)
+		check("worker.market_close_time", err)
+		if err == nil {
+			check("worker.data_final_after", session.SetDataFinalAfter(cfg.Worker.DataFinalAfter))
+		}
+	}
+
+	if cfg.Worker.SyncVerifyThreshold > 1 {
+		check("worker.sync_verify_threshold", fmt.Errorf("must be between 0 and 1, got %v", cfg.Worker.SyncVerifyThreshold))
+	}
+
+	switch cfg.App.CollectorConnect {
+	case "", collector.ConnectAtStartup, collector.ConnectOnDemand:
+	default:
+		check("app.collector_connect", fmt.Errorf("unsupported value %q, valid options: %s, %s",
+			cfg.App.CollectorConnect, collector.ConnectAtStartup, collector.ConnectOnDemand))
+	}
+
+	if cfg.Auth.Enabled && cfg.Auth.Token == "" {
+		check("auth.token", fmt.Errorf("auth is enabled but token is empty"))
+	}
+
+	checks = append(checks, checkNotifyConfig(cfg.Notify)...)
+	return checks
+}
+
+// validateDatabaseConfig 校验数据库连接参数，只支持MySQL
+func validateDatabaseConfig(db config.DatabaseConfig) error {
+	if db.Driver != "" && db.Driver != "mysql" {
+		return fmt.Errorf("unsupported driver %q, only mysql is supported", db.Driver)
+	}
+	if db.Host == "" {
+		return fmt.Errorf("database.host is required")
+	}
+	if db.Port <= 0 || db.Port > 65535 {
+		return fmt.Errorf("database.port %d is out of range", db.Port)
+	}
+	if db.Name == "" {
+		return fmt.Errorf("database.name is required")
+	}
+	if db.User == "" {
+		return fmt.Errorf("database.user is required")
+	}
+	return nil
+}
+
+// checkNotifyConfig 校验通知配置，只配置了Webhook但未启用的机器人给出警告，其消息不会发送
+func checkNotifyConfig(notify notification.Config) []doctorCheck {
+	var checks []doctorCheck
+	if err := notification.ValidateConfig(&notify); err != nil {
+		checks = append(checks, doctorCheck{"notify config", doctorFail, err.Error()})
+	} else {
+		checks = append(checks, doctorCheck{"notify config", doctorPass, ""})
+	}
+
+	if notify.DingTalk != nil && !notify.DingTalk.Enabled && notify.DingTalk.Webhook != "" {
+		checks = append(checks, doctorCheck{"notify.dingtalk", doctorWarn, "webhook is configured but the bot is disabled"})
+	}
+	if notify.WeWork != nil && !notify.WeWork.Enabled && notify.WeWork.Webhook != "" {
+		checks = append(checks, doctorCheck{"notify.wework", doctorWarn, "webhook is configured but the bot is disabled"})
+	}
+	if _, err := notification.LoadJobTemplates(notify.Templates); err != nil {
+		checks = append(checks, doctorCheck{"notify.templates", doctorFail, err.Error()})
+	}
+	return checks
+}
+
+// printDoctorReport 输出自检清单，返回失败项数量
+func printDoctorReport(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+		if c.Detail == "" {
+			fmt.Fprintf(w, "[%s] %s\n", c.Status, c.Name)
+		} else {
+			fmt.Fprintf(w, "[%s] %s: %s\n", c.Status, c.Name, c.Detail)
+		}
+	}
+	fmt.Fprintf(w, "\n%d checks, %d failed\n", len(checks), failed)
+	return failed
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"stock/internal/config"
+	"stock/internal/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doctorTestConfig 各项都有效的测试配置
+func doctorTestConfig() *config.Config {
+	return &config.Config{
+		App:      config.AppConfig{Timezone: "Asia/Shanghai", CollectorConnect: "startup"},
+		Database: config.DatabaseConfig{Driver: "mysql", Host: "127.0.0.1", Port: 3306, Name: "stock", User: "root"},
+		Worker:   config.WorkerConfig{MarketCloseTime: "15:30", SyncVerifyThreshold: 0.9},
+		Notify: notification.Config{
+			DingTalk: &notification.DingTalkConfig{Enabled: true, Webhook: "https://oapi.dingtalk.com/robot/send?access_token=abc"},
+			WeWork:   &notification.WeWorkConfig{Webhook: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=abc"},
+		},
+	}
+}
+
+// stubDoctorProbes 不访问网络的探测，记录被探测的Webhook
+func stubDoctorProbes(webhooks *[]string) doctorProbes {
+	return doctorProbes{
+		database: func(cfg *config.Config) error {
+			if cfg.Database.Port <= 0 {
+				return errors.New("dial tcp: invalid port")
+			}
+			return nil
+		},
+		webhook: func(ctx context.Context, url string) error {
+			*webhooks = append(*webhooks, url)
+			return nil
+		},
+		collector: func(ctx context.Context, name string) error { return nil },
+	}
+}
+
+func findDoctorCheck(t *testing.T, checks []doctorCheck, name string) doctorCheck {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	require.Failf(t, "check not found", "%s", name)
+	return doctorCheck{}
+}
+
+func TestRunDoctor_AllPass(t *testing.T) {
+	var webhooks []string
+	cfg := doctorTestConfig()
+	checks := runDoctor(context.Background(), cfg, stubDoctorProbes(&webhooks))
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, printDoctorReport(&out, checks))
+	assert.Contains(t, out.String(), "[PASS] collector eastmoney")
+
+	// 未启用的企微机器人不探测，只给出警告
+	assert.Equal(t, []string{cfg.Notify.DingTalk.Webhook}, webhooks)
+	assert.Equal(t, doctorWarn, findDoctorCheck(t, checks, "notify.wework").Status)
+}
+
+func TestRunDoctor_BadField(t *testing.T) {
+	var webhooks []string
+	cfg := doctorTestConfig()
+	cfg.Database.Port = 0
+	cfg.Notify.DingTalk.Webhook = ""
+	cfg.Worker.MarketCloseTime = "25:00"
+
+	checks := runDoctor(context.Background(), cfg, stubDoctorProbes(&webhooks))
+
+	assert.Equal(t, doctorFail, findDoctorCheck(t, checks, "database config").Status)
+	assert.Equal(t, doctorFail, findDoctorCheck(t, checks, "database connection").Status)
+	assert.Equal(t, doctorFail, findDoctorCheck(t, checks, "worker.market_close_time").Status)
+	notify := findDoctorCheck(t, checks, "notify config")
+	assert.Equal(t, doctorFail, notify.Status)
+	assert.Contains(t, notify.Detail, "Webhook为空")
+
+	// 配置校验失败不影响采集器探测，一次列出所有问题
+	assert.Equal(t, doctorPass, findDoctorCheck(t, checks, "collector tonghuashun").Status)
+	assert.Empty(t, webhooks)
+
+	var out bytes.Buffer
+	assert.Equal(t, 4, printDoctorReport(&out, checks))
+	assert.Contains(t, out.String(), "[FAIL] database config: database.port 0 is out of range")
+}
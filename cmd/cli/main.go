@@ -1,21 +1,128 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/user"
 	"stock/internal/logger"
+	"strings"
+	"time"
 
+	"stock/internal/collector"
 	"stock/internal/config"
+	"stock/internal/database"
+	"stock/internal/model"
+	"stock/internal/repository"
 	"stock/internal/service"
+	"stock/internal/utils"
 )
 
+// backfillProgressInterval 全量回填时每处理多少只股票输出一次进度
+const backfillProgressInterval = 100
+
+// defaultUpdateConcurrency 未配置K线采集并发数时update-data的默认并发数
+const defaultUpdateConcurrency = 10
+
+// defaultPerformanceCheckpoint backfill-performance的默认断点文件
+const defaultPerformanceCheckpoint = "performance_backfill.json"
+
+// defaultDailyMigrationCheckpoint migrate-daily-data的默认断点文件
+const defaultDailyMigrationCheckpoint = "daily_data_migration.json"
+
+// errUnknownCommand 命令不存在
+var errUnknownCommand = errors.New("unknown command")
+
+// cliOptions 命令行参数
+type cliOptions struct {
+	strategy    string
+	limit       int
+	checkpoint  string
+	code        string
+	period      string
+	sourceTable string
+	batchSize   int
+	clean       bool
+}
+
+// cliEnv 命令执行所需的配置和服务
+type cliEnv struct {
+	cfg      *config.Config
+	log      *logger.Logger
+	services *service.Services
+}
+
+// commands 各命令的执行函数，新增命令时同时更新printUsage
+var commands = map[string]func(env *cliEnv, opts cliOptions) error{
+	"init-db": func(env *cliEnv, opts cliOptions) error {
+		return initDatabase(env.services)
+	},
+	"migrate": func(env *cliEnv, opts cliOptions) error {
+		return migrateDatabase(env.services)
+	},
+	"update-data": func(env *cliEnv, opts cliOptions) error {
+		return updateData(env.cfg, env.log, opts.code, opts.clean)
+	},
+	"select-stocks": func(env *cliEnv, opts cliOptions) error {
+		return selectStocks(env.cfg, env.log, opts.strategy, opts.limit)
+	},
+	"backfill-performance": func(env *cliEnv, opts cliOptions) error {
+		return backfillPerformance(env.cfg, env.log, checkpointOrDefault(opts.checkpoint, defaultPerformanceCheckpoint))
+	},
+	"recompute-indicators": func(env *cliEnv, opts cliOptions) error {
+		periods, err := service.ParseIndicatorPeriods(opts.period)
+		if err != nil {
+			return err
+		}
+		checkpoint := checkpointOrDefault(opts.checkpoint, fmt.Sprintf("indicator_recompute_%s.json", strings.ToLower(opts.period)))
+		return recomputeIndicators(env.cfg, env.log, periods, checkpoint)
+	},
+	"migrate-daily-data": func(env *cliEnv, opts cliOptions) error {
+		return migrateDailyData(env.cfg, env.log, opts.sourceTable, opts.batchSize,
+			checkpointOrDefault(opts.checkpoint, defaultDailyMigrationCheckpoint))
+	},
+	"schema-info": func(env *cliEnv, opts cliOptions) error {
+		return schemaInfo(env.cfg, env.log)
+	},
+}
+
+// readOnlyCommands 不修改数据的命令，执行时不写入审计记录
+var readOnlyCommands = map[string]bool{
+	"select-stocks": true,
+	"schema-info":   true,
+}
+
+// runCommand 执行指定命令，命令不存在时返回errUnknownCommand
+func runCommand(name string, env *cliEnv, opts cliOptions) error {
+	run, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownCommand, name)
+	}
+	return run(env, opts)
+}
+
+// checkpointOrDefault 未指定断点文件时使用命令的默认断点文件，各命令的断点互不覆盖
+func checkpointOrDefault(checkpoint, defaultPath string) string {
+	if checkpoint == "" {
+		return defaultPath
+	}
+	return checkpoint
+}
+
 func main() {
 	var (
-		command  = flag.String("cmd", "", "Command to execute: init-db, migrate, update-data, select-stocks")
-		strategy = flag.String("strategy", "technical", "Selection strategy: technical, fundamental, combined")
+		command  = flag.String("cmd", "", "Command to execute: doctor, init-db, migrate, update-data, select-stocks, backfill-performance, recompute-indicators, migrate-daily-data, schema-info")
+		strategy = flag.String("strategy", "technical", "Selection strategy registered in the strategy registry, built-in: technical, fundamental, combined")
 		limit    = flag.Int("limit", 20, "Number of stocks to select")
+		resume   = flag.String("checkpoint", "", "Checkpoint file for backfill-performance, recompute-indicators and migrate-daily-data; empty uses the command's default file")
+		code     = flag.String("code", "", "Stock code for update-data, e.g. 000001.SZ; empty means all active stocks")
+		period   = flag.String("period", "daily", "Indicator period for recompute-indicators: daily, weekly, monthly, yearly, all")
+		table    = flag.String("source-table", service.DefaultLegacyDailyTable, "Legacy unsharded daily K-line table for migrate-daily-data")
+		batch    = flag.Int("batch-size", 1000, "Rows per batch for migrate-daily-data, each batch is committed in its own transaction")
+		clean    = flag.Bool("clean", false, "Delete stored K-lines from the history start date before update-data re-syncs them")
 	)
 	flag.Parse()
 
@@ -24,15 +131,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	// doctor在初始化服务之前执行，数据库或通知配置有问题时服务初始化会直接退出
+	if *command == "doctor" {
+		os.Exit(doctor())
+	}
+
 	// 初始化配置
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := utils.SetAppTimezone(cfg.App.Timezone); err != nil {
+		log.Fatalf("Invalid app config: %v", err)
+	}
+	utils.SetStrictMode(cfg.App.StrictMode)
+	repository.SetSkipUnchangedUpsert(cfg.App.SkipUnchangedUpsert)
 
 	// 初始化日志
 	log := logger.NewLogger(cfg.Log)
 
+	// 应用配置的采集器自定义请求头、Cookie和响应缓存
+	collectorFactory := collector.GetCollectorFactory(log)
+	collectorFactory.ApplyHeaderOverrides(cfg.Collectors)
+	collectorFactory.ApplyResponseCache(cfg.CollectorCache)
+	collectorFactory.ApplyDebug(cfg.App.Debug)
+	collectorFactory.ApplyRequestStats(cfg.UpstreamStats)
+	collectorFactory.ApplyCircuitBreaker(cfg.CircuitBreaker)
+	collectorFactory.GetEastMoneyCollector().SetStockListConcurrency(cfg.Worker.StockListConcurrency)
+
 	// 初始化服务
 	services, err := service.NewServices(cfg, log)
 	if err != nil {
@@ -40,18 +166,25 @@ func main() {
 	}
 
 	// 执行命令
-	switch *command {
-	case "init-db":
-		err = initDatabase(services)
-	case "migrate":
-		err = migrateDatabase(services)
-	case "select-stocks":
-		err = selectStocks(services, *strategy, *limit)
-	default:
+	opts := cliOptions{
+		strategy:    *strategy,
+		limit:       *limit,
+		checkpoint:  *resume,
+		code:        strings.ToUpper(strings.TrimSpace(*code)),
+		period:      *period,
+		sourceTable: *table,
+		batchSize:   *batch,
+		clean:       *clean,
+	}
+	err = runCommand(*command, &cliEnv{cfg: cfg, log: log, services: services}, opts)
+	if errors.Is(err, errUnknownCommand) {
 		fmt.Printf("Unknown command: %s\n", *command)
 		printUsage()
 		os.Exit(1)
 	}
+	if !readOnlyCommands[*command] {
+		recordAudit(cfg, log, *command, opts, err)
+	}
 
 	if err != nil {
 		logger.Errorf("Command failed: %v", err)
@@ -64,14 +197,58 @@ func main() {
 func printUsage() {
 	fmt.Println("Usage: cli -cmd <command> [options]")
 	fmt.Println("\nCommands:")
+	fmt.Println("  doctor       Validate config and check database, notification webhooks and collectors")
 	fmt.Println("  init-db      Initialize database")
 	fmt.Println("  migrate      Run database migration")
 	fmt.Println("  update-data  Update stock data")
 	fmt.Println("  select-stocks Execute stock selection")
+	fmt.Println("  backfill-performance Backfill performance reports for all stocks")
+	fmt.Println("  recompute-indicators Recompute technical indicators for all active stocks")
+	fmt.Println("  migrate-daily-data Copy the legacy unsharded daily K-line table into the sharded tables")
+	fmt.Println("  schema-info  List all tables with row counts and K-line trade date ranges")
 	fmt.Println("\nOptions:")
 	fmt.Println("  -strategy    Selection strategy (technical, fundamental, combined)")
 	fmt.Println("  -limit       Number of stocks to select")
 	fmt.Println("  -source      Data source (tushare, akshare, yahoo)")
+	fmt.Println("  -checkpoint  Checkpoint file for backfill-performance, recompute-indicators and migrate-daily-data, rerun with the same file to resume")
+	fmt.Println("  -code        Stock code for update-data, all active stocks when omitted")
+	fmt.Println("  -period      Indicator period for recompute-indicators (daily, weekly, monthly, yearly, all)")
+	fmt.Println("  -source-table Legacy daily K-line table for migrate-daily-data (default daily_data)")
+	fmt.Println("  -batch-size  Rows committed per transaction by migrate-daily-data (default 1000)")
+	fmt.Println("  -clean       Delete stored daily/weekly/monthly/yearly K-lines before update-data re-syncs them")
+}
+
+// recordAudit 为修改数据的命令写入审计记录，操作者为执行命令的系统用户，记录失败只记日志，不影响命令的退出码
+func recordAudit(cfg *config.Config, log *logger.Logger, command string, opts cliOptions, cmdErr error) {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		logger.Errorf("Failed to record audit log: %v", err)
+		return
+	}
+	defer dbManager.Close()
+
+	actor := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		actor = u.Username
+	}
+	params := map[string]interface{}{}
+	if opts.checkpoint != "" {
+		params["checkpoint"] = opts.checkpoint
+	}
+	switch command {
+	case "update-data":
+		if opts.clean {
+			params["clean"] = true
+		}
+	case "recompute-indicators":
+		params["period"] = opts.period
+	case "migrate-daily-data":
+		params["source_table"] = opts.sourceTable
+		params["batch_size"] = opts.batchSize
+	}
+	if _, err := service.GetAuditService(dbManager.GetDB()).Record(model.AuditSourceCLI, command, opts.code, actor, params, cmdErr); err != nil {
+		logger.Errorf("Failed to record audit log: %v", err)
+	}
 }
 
 func initDatabase(services *service.Services) error {
@@ -84,8 +261,254 @@ func migrateDatabase(services *service.Services) error {
 	return services.Database.Migrate()
 }
 
-func selectStocks(services *service.Services, strategy string, limit int) error {
-	// todo
+// selectStocks 使用注册表中的选股策略对全部股票选股，输出评分最高的limit只
+func selectStocks(cfg *config.Config, log *logger.Logger, strategy string, limit int) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+	db := dbManager.GetDB()
+
+	registry := service.GetStrategyRegistry(db)
+	stocks, err := repository.NewStock(db).GetAllStocks()
+	if err != nil {
+		return fmt.Errorf("failed to get stocks: %v", err)
+	}
+
+	fmt.Printf("Selecting stocks with strategy %s from %d stocks...\n", strategy, len(stocks))
+	// 策略未注册时返回的错误中包含可用的策略名称
+	results, err := registry.Select(context.Background(), strategy, stocks, limit)
+	if err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		fmt.Printf("%3d. %-10s %8.2f  %s\n", i+1, result.TsCode, result.Score, result.Reason)
+	}
+	fmt.Printf("Selected %d stocks\n", len(results))
+	return nil
+}
+
+// backfillPerformance 为所有股票全量回填业绩报表，按配置的业绩报表任务并发数和限流采集
+func backfillPerformance(cfg *config.Config, log *logger.Logger, checkpoint string) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+	db := dbManager.GetDB()
+
+	eastMoneyCollector := collector.GetCollectorFactory(log).GetEastMoneyCollector()
+	performanceService := service.NewPerformanceService(repository.NewPerformance(db), repository.NewStock(db), eastMoneyCollector)
+
+	fmt.Printf("Backfilling performance reports, checkpoint: %s\n", checkpoint)
+	result, err := performanceService.BackfillAllPerformanceReports(context.Background(), service.PerformanceBackfillOptions{
+		Concurrency:    cfg.Worker.Performance.Concurrency,
+		RateLimit:      cfg.Worker.Performance.RateLimit,
+		CheckpointPath: checkpoint,
+		Progress: func(p service.PerformanceBackfillProgress) {
+			if p.Done%backfillProgressInterval == 0 || p.Done == p.Total {
+				fmt.Printf("Progress: %d/%d (failed: %d), elapsed: %v, ETA: %v\n",
+					p.Done, p.Total, p.Failed, p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Backfill finished: %d stocks, %d resumed, %d succeeded, %d failed, %d reports saved in %v\n",
+		result.Total, result.Resumed, result.Succeeded, result.Failed, result.Reports, result.Duration.Round(time.Second))
+	if result.Failed > 0 {
+		fmt.Printf("Failed stocks will be retried on the next run: %v\n", result.FailedCodes)
+	}
+	return nil
+}
+
+// recomputeIndicators 为所有活跃股票重新计算指定周期的技术指标，用于修正指标算法或新增指标列后回填
+func recomputeIndicators(cfg *config.Config, log *logger.Logger, periods []model.TechnicalIndicatorPeriod, checkpoint string) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+
+	fmt.Printf("Recomputing %v indicators, checkpoint: %s\n", periods, checkpoint)
+	result, err := service.GetIndicatorService(dbManager.GetDB()).RecomputeAllIndicators(context.Background(), service.IndicatorRecomputeOptions{
+		Periods:        periods,
+		CheckpointPath: checkpoint,
+		Progress: func(p service.IndicatorRecomputeProgress) {
+			if p.Done%backfillProgressInterval == 0 || p.Done == p.Total {
+				fmt.Printf("Progress: %d/%d (failed: %d), elapsed: %v, ETA: %v\n",
+					p.Done, p.Total, p.Failed, p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Recompute finished: %d stocks, %d resumed, %d succeeded, %d failed in %v\n",
+		result.Total, result.Resumed, result.Succeeded, result.Failed, result.Duration.Round(time.Second))
+	if result.Failed > 0 {
+		fmt.Printf("Failed stocks will be retried on the next run: %v\n", result.FailedCodes)
+	}
+	return nil
+}
+
+// migrateDailyData 将未分表的旧日K线表迁移到分表，每批单独提交事务并记录断点，中断后重新执行会从断点继续
+func migrateDailyData(cfg *config.Config, log *logger.Logger, sourceTable string, batchSize int, checkpoint string) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+
+	fmt.Printf("Migrating daily data from %s in batches of %d, checkpoint: %s\n", sourceTable, batchSize, checkpoint)
+	var batches int
+	result, err := service.MigrateLegacyDailyData(context.Background(), dbManager.GetDB(), service.DailyDataMigrationOptions{
+		SourceTable:    sourceTable,
+		BatchSize:      batchSize,
+		CheckpointPath: checkpoint,
+		Progress: func(p service.DailyDataMigrationProgress) {
+			if batches++; batches%backfillProgressInterval == 0 {
+				fmt.Printf("Progress: %s %d rows migrated, at %s %d, elapsed: %v\n",
+					p.Phase, p.Migrated, p.LastTsCode, p.LastTradeDate, p.Elapsed.Round(time.Second))
+			}
+		},
+	})
+	if err != nil {
+		if result != nil {
+			fmt.Printf("Migration interrupted after %d batches, rerun with the same checkpoint to resume\n", result.Batches)
+		}
+		return err
+	}
+
+	fmt.Printf("Migration finished: %d rows migrated (resumed: %v, caught up from %d) in %v\n",
+		result.Migrated, result.Resumed, result.CatchUpFrom, result.Duration.Round(time.Second))
+	return nil
+}
+
+// updateData 增量更新K线数据和基本面数据（业绩报表、股东户数），code为空时更新所有活跃股票
+// K线从数据库中最新一根开始采集，与定时任务的增量同步一致；并发和限流使用定时任务K线采集的配置
+// clean为true时先删除历史起始日期之后已保存的K线，从起始日期重新采集（修复数据源更正过的历史K线）
+func updateData(cfg *config.Config, log *logger.Logger, code string, clean bool) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+	db := dbManager.GetDB()
+
+	dataService := service.GetDataService(db, log)
+	klinePersistence := service.GetKLinePersistenceService(db, log)
+	dataService.SetChunkedHistoryFetch(cfg.Worker.ChunkedHistoryFetch)
+	dataService.SetLocalKLineAggregation(cfg.Worker.LocalKLineAggregation)
+	failover, err := collector.GetCollectorFactory(log).NewFailoverManager(cfg.CircuitBreaker)
+	if err != nil {
+		return fmt.Errorf("failed to set up collector failover: %v", err)
+	}
+	dataService.SetCollectorManager(failover)
+	eastMoneyCollector := collector.GetCollectorFactory(log).GetEastMoneyCollector()
+	performanceService := service.NewPerformanceService(repository.NewPerformance(db), repository.NewStock(db), eastMoneyCollector)
+	shareholderService := service.NewShareholderService(repository.NewShareholder(db), eastMoneyCollector)
+	shareholderService.SetSharesRepository(repository.NewStockShares(db))
+
+	var stocks []*model.Stock
+	if code != "" {
+		stock, err := dataService.GetStockInfo(code)
+		if err != nil {
+			return fmt.Errorf("failed to get stock %s: %v", code, err)
+		}
+		if stock == nil {
+			return fmt.Errorf("stock %s not found, run the stock list sync first", code)
+		}
+		stocks = []*model.Stock{stock}
+	} else if stocks, err = dataService.GetAllStocks(); err != nil {
+		return fmt.Errorf("failed to get stocks: %v", err)
+	}
+
+	historyStart := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	concurrency := cfg.Worker.KLine.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultUpdateConcurrency
+	}
+	executor := utils.NewConcurrentExecutor(concurrency, 2*time.Hour)
+	executor.SetRateLimit(cfg.Worker.KLine.RateLimit)
+	defer executor.Close()
+
+	tasks := make([]utils.Task, 0, len(stocks))
+	for _, stock := range stocks {
+		stock := stock
+		tasks = append(tasks, &utils.SimpleTask{
+			ID:          fmt.Sprintf("update-data-%s", stock.TsCode),
+			Description: fmt.Sprintf("更新股票 %s 的K线和基本面数据", stock.TsCode),
+			Func: func(ctx context.Context) error {
+				start := historyStart
+				if cfg.Worker.CollectSinceListDate {
+					start = dataService.ClampHistoryStart(stock, historyStart)
+				}
+				if clean {
+					if err := cleanStockKLines(klinePersistence, stock.TsCode, start); err != nil {
+						return fmt.Errorf("%s: %v", stock.TsCode, err)
+					}
+				}
+				if _, err := dataService.UpdateStockKLine(stock, start); err != nil {
+					return fmt.Errorf("%s: %v", stock.TsCode, err)
+				}
+				if err := performanceService.SyncPerformanceReports(ctx, stock.TsCode); err != nil {
+					return fmt.Errorf("%s: 同步业绩报表失败: %v", stock.TsCode, err)
+				}
+				if err := shareholderService.SyncShareholderCounts(ctx, stock.TsCode); err != nil {
+					return fmt.Errorf("%s: 同步股东户数失败: %v", stock.TsCode, err)
+				}
+				return nil
+			},
+		})
+	}
+
+	fmt.Printf("Updating data for %d stocks...\n", len(tasks))
+	results, stats := executor.ExecuteBatch(context.Background(), tasks)
+	for _, result := range results {
+		if !result.Success {
+			fmt.Printf("Update failed: %v\n", result.Error)
+		}
+	}
+	fmt.Printf("Update finished: %d stocks, %d failed, took %v\n",
+		stats.TotalTasks, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime).Round(time.Second))
+
+	if code != "" && stats.FailedTasks > 0 {
+		return fmt.Errorf("failed to update %s", code)
+	}
+	return nil
+}
+
+// cleanStockKLines 删除股票交易日期不早于start的日、周、月、年K线，之后的同步从start重新采集完整历史
+func cleanStockKLines(klinePersistence *service.KLinePersistenceService, tsCode string, start time.Time) error {
+	for _, dataType := range []string{"daily", "weekly", "monthly", "yearly"} {
+		deleted, err := klinePersistence.DeleteDataRange(tsCode, start, time.Time{}, dataType)
+		if err != nil {
+			return fmt.Errorf("清理%s K线失败: %v", dataType, err)
+		}
+		logger.Debugf("Cleaned %d %s bars of %s since %s", deleted, dataType, tsCode, start.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// schemaInfo 输出所有数据表（含K线分表）的行数及K线表的交易日期范围
+func schemaInfo(cfg *config.Config, log *logger.Logger) error {
+	dbManager, err := database.NewDatabase(&cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer dbManager.Close()
+
+	infos, err := dbManager.SchemaInfo()
+	if err != nil {
+		return err
+	}
 
+	fmt.Print(database.FormatSchemaInfo(infos))
 	return nil
 }
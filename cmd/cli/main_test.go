@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommand_DispatchesUpdateData(t *testing.T) {
+	require.Contains(t, commands, "update-data")
+
+	original := commands["update-data"]
+	t.Cleanup(func() { commands["update-data"] = original })
+
+	var got cliOptions
+	commands["update-data"] = func(env *cliEnv, opts cliOptions) error {
+		got = opts
+		return nil
+	}
+
+	require.NoError(t, runCommand("update-data", &cliEnv{}, cliOptions{code: "000001.SZ"}))
+	assert.Equal(t, "000001.SZ", got.code)
+}
+
+func TestRunCommand_UnknownCommand(t *testing.T) {
+	err := runCommand("update-everything", &cliEnv{}, cliOptions{})
+	assert.True(t, errors.Is(err, errUnknownCommand))
+}
+
+func TestCommands_CoverAdvertisedCommands(t *testing.T) {
+	for _, name := range []string{"init-db", "migrate", "update-data", "select-stocks", "backfill-performance", "recompute-indicators", "migrate-daily-data", "schema-info"} {
+		assert.Contains(t, commands, name)
+	}
+}
+
+func TestRunCommand_RecomputeIndicatorsRejectsUnknownPeriod(t *testing.T) {
+	err := runCommand("recompute-indicators", &cliEnv{}, cliOptions{period: "hourly"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hourly")
+}
+
+func TestCheckpointOrDefault(t *testing.T) {
+	assert.Equal(t, "performance_backfill.json", checkpointOrDefault("", defaultPerformanceCheckpoint))
+	assert.Equal(t, "custom.json", checkpointOrDefault("custom.json", defaultPerformanceCheckpoint))
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"stock/internal/api"
@@ -11,6 +13,9 @@ import (
 	"stock/internal/database"
 	"stock/internal/logger"
 	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/service"
+	"stock/internal/utils"
 )
 
 func main() {
@@ -19,6 +24,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := utils.SetAppTimezone(cfg.App.Timezone); err != nil {
+		log.Fatalf("Invalid app config: %v", err)
+	}
+	utils.SetStrictMode(cfg.App.StrictMode)
+	repository.SetSkipUnchangedUpsert(cfg.App.SkipUnchangedUpsert)
 
 	// 初始化日志
 	utilsLogger := logger.NewLogger(cfg.Log)
@@ -34,23 +44,43 @@ func main() {
 
 	db := dbManager.DB
 
-	// 自动迁移数据库表
-	if err := db.AutoMigrate(&model.Stock{}, &model.DailyData{}, &model.PerformanceReport{}); err != nil {
+	// 按配置自动迁移数据库表
+	if _, err := migrateOnStartup(cfg.Database.AutoMigrateOnStartup, utilsLogger, func() error {
+		return db.AutoMigrate(&model.Stock{}, &model.DailyData{}, &model.PerformanceReport{}, &model.Index{}, &model.IndexDaily{}, &model.StockScore{}, &model.Watchlist{}, &model.StockIdentityChange{}, &model.SelectionResult{}, &model.StockDataQuality{}, &model.DataExclusion{}, &model.NorthboundHolding{}, &model.JobRun{}, &model.StockShares{})
+	}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
 	// 创建数据采集器
-	eastMoneyCollector := collector.GetCollectorFactory(logger.GetGlobalLogger()).GetEastMoneyCollector()
-	if err := eastMoneyCollector.Connect(); err != nil {
-		log.Fatalf("Failed to connect to data source: %v", err)
-	}
-
-	// 创建采集器管理器
+	collectorFactory := collector.GetCollectorFactory(logger.GetGlobalLogger())
+	collectorFactory.ApplyHeaderOverrides(cfg.Collectors)
+	collectorFactory.ApplyResponseCache(cfg.CollectorCache)
+	collectorFactory.ApplyDebug(cfg.App.Debug)
+	collectorFactory.ApplyRequestStats(cfg.UpstreamStats)
+	collectorFactory.ApplyCircuitBreaker(cfg.CircuitBreaker)
+	eastMoneyCollector := collectorFactory.GetEastMoneyCollector()
+	eastMoneyCollector.SetStockListConcurrency(cfg.Worker.StockListConcurrency)
+
+	// 创建采集器管理器，数据源暂时不可用时不中断启动，首次使用时重新连接
 	collectorManager := collector.NewCollectorManager(utilsLogger)
-	collectorManager.RegisterCollector("eastmoney", eastMoneyCollector)
+	if err := collectorManager.RegisterCollector(eastMoneyCollector); err != nil {
+		log.Fatalf("Failed to register collector: %v", err)
+	}
+	// 开启熔断时注册熔断后改用的采集器，东方财富和同花顺熔断时互相切换
+	if err := collectorFactory.ApplyFailover(collectorManager, cfg.CircuitBreaker); err != nil {
+		log.Fatalf("Failed to set up collector failover: %v", err)
+	}
+	if cfg.App.CollectorConnect != collector.ConnectOnDemand {
+		collectorManager.ConnectOnStartup()
+	}
 
 	// 创建API处理器（传入数据库连接）
 	apiHandler := api.NewHandler(collectorManager, logrusLogger, db)
+	service.GetStockScoreService(db).SetWeights(cfg.Score.Weights)
+
+	// 定期清理超过保留期的已结束任务
+	retention := time.Duration(cfg.Task.RetentionDays) * 24 * time.Hour
+	service.GetTaskService(db, logrusLogger).StartRetentionCleanup(context.Background(), retention, cfg.Task.CleanupInterval)
 
 	// 设置Gin模式
 	gin.SetMode(gin.ReleaseMode)
@@ -62,7 +92,7 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, "+api.APIKeyHeader)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -72,20 +102,19 @@ func main() {
 		c.Next()
 	})
 
-	// API路由组
-	v1 := router.Group("/api/v1")
-	{
-		// 股票相关接口
-		stocks := v1.Group("/stocks")
-		{
-			stocks.GET("/", apiHandler.GetStockList)                           // 获取股票列表
-			stocks.GET("/:code", apiHandler.GetStockDetail)                    // 获取股票详情
-			stocks.GET("/:code/kline", apiHandler.GetKLineData)                // 获取K线数据
-			stocks.GET("/:code/performance", apiHandler.GetPerformanceReports) // 获取业绩报表数据
+	// API路由，写操作和管理接口按配置开启令牌鉴权
+	authToken := ""
+	if cfg.Auth.Enabled {
+		if cfg.Auth.Token == "" {
+			log.Fatalf("auth.enabled is true but auth.token is empty")
 		}
+		authToken = cfg.Auth.Token
+	}
+	api.RegisterRoutes(router, apiHandler, authToken)
 
-		// 实时数据接口
-		v1.GET("/realtime", apiHandler.GetRealtimeData) // 获取实时数据
+	// 上游请求统计，与管理接口一样需要鉴权
+	if cfg.Metrics.Enabled {
+		router.GET(cfg.Metrics.Path, api.AuthMiddleware(authToken), api.UpstreamStatsHandler(collectorFactory.RequestStats(), collectorFactory.CircuitBreakers()))
 	}
 
 	// 静态文件服务
@@ -104,3 +133,19 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// migrateOnStartup 按配置在启动时执行表结构迁移，返回是否执行了迁移
+// 未开启时跳过并提示通过 cli -cmd=migrate 显式迁移
+func migrateOnStartup(enabled bool, log *logger.Logger, migrate func() error) (bool, error) {
+	if !enabled {
+		log.Info("Skipping database auto-migration on startup (database.auto_migrate_on_startup=false), run `cli -cmd=migrate` to migrate explicitly")
+		return false, nil
+	}
+
+	log.Info("Running database auto-migration on startup (database.auto_migrate_on_startup=true)")
+	if err := migrate(); err != nil {
+		return true, err
+	}
+	log.Info("Database auto-migration completed")
+	return true, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateOnStartup(t *testing.T) {
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+
+	calls := 0
+	migrate := func() error {
+		calls++
+		return nil
+	}
+
+	// 关闭时不执行迁移
+	ran, err := migrateOnStartup(false, log, migrate)
+	assert.NoError(t, err)
+	assert.False(t, ran)
+	assert.Equal(t, 0, calls)
+
+	ran, err = migrateOnStartup(true, log, migrate)
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 1, calls)
+
+	migrateErr := errors.New("lock wait timeout")
+	ran, err = migrateOnStartup(true, log, func() error { return migrateErr })
+	assert.ErrorIs(t, err, migrateErr)
+	assert.True(t, ran)
+}
@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -44,6 +45,29 @@ func main() {
 	}
 	db := dbManager.DB
 
+	if err := utils.SetAppTimezone(cfg.App.Timezone); err != nil {
+		logger.Fatalf("Invalid app config: %v", err)
+	}
+	utils.SetStrictMode(cfg.App.StrictMode)
+	repository.SetSkipUnchangedUpsert(cfg.App.SkipUnchangedUpsert)
+
+	workerConfig = cfg.Worker
+	if marketSession, err = utils.NewMarketSession(workerConfig.MarketCloseTime, utils.AppLocation()); err != nil {
+		logger.Fatalf("Invalid worker config: %v", err)
+	}
+	if err := marketSession.SetDataFinalAfter(workerConfig.DataFinalAfter); err != nil {
+		logger.Fatalf("Invalid worker config: %v", err)
+	}
+
+	// 应用配置的采集器自定义请求头、Cookie和响应缓存
+	collectorFactory := collector.GetCollectorFactory(logger.GetGlobalLogger())
+	collectorFactory.ApplyHeaderOverrides(cfg.Collectors)
+	collectorFactory.ApplyResponseCache(cfg.CollectorCache)
+	collectorFactory.ApplyDebug(cfg.App.Debug)
+	collectorFactory.ApplyRequestStats(cfg.UpstreamStats)
+	collectorFactory.ApplyCircuitBreaker(cfg.CircuitBreaker)
+	collectorFactory.GetEastMoneyCollector().SetStockListConcurrency(cfg.Worker.StockListConcurrency)
+
 	// 初始化服务
 	services, err := initServicesWithDB(cfg, db)
 	if err != nil {
@@ -52,8 +76,8 @@ func main() {
 
 	logger.Info("Worker starting...")
 
-	// 创建定时任务调度器
-	c := cron.New(cron.WithSeconds())
+	// 创建定时任务调度器，按应用时区触发，服务器时区为UTC时仍在北京时间收盘后执行
+	c := cron.New(cron.WithSeconds(), cron.WithLocation(utils.AppLocation()))
 
 	// 设置定时任务
 	setupCronJobs(c, services)
@@ -62,12 +86,20 @@ func main() {
 	c.Start()
 	logger.Info("Cron scheduler started")
 
+	// 启动通知重试循环
+	retryCtx, stopRetry := context.WithCancel(context.Background())
+	defer stopRetry()
+	if retry := cfg.Notify.Retry; retry != nil && retry.Enabled {
+		services.NotifyManger.StartRetryLoop(retryCtx, retry.Interval)
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("Worker shutting down...")
+	stopRetry()
 
 	// 停止调度器
 	ctx := c.Stop()
@@ -76,12 +108,173 @@ func main() {
 	logger.Info("Worker exited")
 }
 
-const maxConcurrent = 100 // 最大并发量
+// workerConfig 定时任务配置，启动时从配置文件加载
+var workerConfig config.WorkerConfig
+
+// marketSession 交易时段，判断当日K线是否已收盘定型，启动时按配置的收盘时间和数据定型时间重新创建
+var marketSession, _ = utils.NewMarketSession(utils.DefaultMarketCloseTime, nil)
+
+// defaultHistoryStartDate 全量同步的默认起始日期
+var defaultHistoryStartDate = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// historyStartDate 获取全量同步的起始日期，开启collect_since_list_date时跳过上市前的区间，上市日期未知时先补全
+func historyStartDate(services *service.Services, stock *model.Stock) time.Time {
+	if !workerConfig.CollectSinceListDate {
+		return defaultHistoryStartDate
+	}
+	return services.DataService.ClampHistoryStart(stock, defaultHistoryStartDate)
+}
+
+const maxConcurrent = 100 // 未配置并发数时的默认最大并发量
+const dailyQuota = 100    // 限流任务每日处理的股票数量上限（优先股票不受此限制）
+
+// newJobExecutor 按采集任务类型的并发和限流配置创建并发执行器，未配置并发数时使用maxConcurrent
+// 数据库连接数耗尽时按worker.db_backoff_min_concurrency降低并发
+func newJobExecutor(limit config.JobLimitConfig, timeout time.Duration) *utils.ConcurrentExecutor {
+	concurrency := limit.Concurrency
+	if concurrency <= 0 {
+		concurrency = maxConcurrent
+	}
+	executor := utils.NewConcurrentExecutor(concurrency, timeout)
+	executor.SetRateLimit(limit.RateLimit)
+	executor.SetDBBackoff(workerConfig.DBBackoffMinConcurrency)
+	return executor
+}
+
+// strictAbort 严格模式下K线同步发现数据不一致时取消整批任务，尚未开始的任务不再执行
+type strictAbort struct {
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+func newStrictAbort(ctx context.Context) (context.Context, *strictAbort) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, &strictAbort{cancel: cancel}
+}
+
+// check 检查任务返回的错误，数据不一致时记录首个错误并取消整批任务，原样返回err
+func (a *strictAbort) check(err error) error {
+	if errors.Is(err, utils.ErrDataInconsistency) {
+		a.once.Do(func() {
+			a.err = err
+			a.cancel()
+		})
+	}
+	return err
+}
+
+// finish 释放上下文，整批任务因数据不一致中止时记录日志、通知机器人并发布任务失败事件
+func (a *strictAbort) finish(services *service.Services, job string) {
+	a.cancel()
+	if a.err == nil {
+		return
+	}
+	logger.Errorf("%s因数据不一致中止: %v", job, a.err)
+	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
+		Content: fmt.Sprintf("🚫 %s因数据不一致中止（严格模式），err:%s", job, a.err.Error()),
+		MsgType: notification.MessageTypeText,
+	})
+	services.NotifyManger.PublishJobEvent(context.Background(), notification.NewJobFailedEvent(job, a.err))
+	recordCronAudit(services, job, nil, a.err)
+}
+
+// loadStockUniverse 获取批量采集任务的股票列表，股票表为空时记录告警并通知机器人
+// 返回的ok为false时任务应直接结束，不发送运行统计，避免零只股票的空跑被当作成功
+func loadStockUniverse(services *service.Services, job string) (stocks []*model.Stock, ok bool, err error) {
+	stocks, err = services.DataService.GetStockUniverse()
+	if errors.Is(err, service.ErrEmptyStockUniverse) {
+		logger.Warnf("%s跳过: %v", job, err)
+		services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
+			Content: fmt.Sprintf("⚠️ %s未执行：股票列表为空，请先同步股票列表", job),
+			MsgType: notification.MessageTypeText,
+		})
+		services.NotifyManger.PublishJobEvent(context.Background(), notification.NewJobFailedEvent(job, err))
+		recordCronAudit(services, job, nil, err)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("获取股票列表失败: %v", err)
+	}
+	return stocks, true, nil
+}
+
+// reportJobSummary 保存采集任务的运行记录，把统计发送给机器人并发布任务完成事件，记录失败只记日志，不影响通知
+func reportJobSummary(services *service.Services, startedAt time.Time, summary notification.JobSummary) {
+	if startedAt.IsZero() { // 没有任务时执行器不记录开始时间
+		startedAt = time.Now().Add(-summary.Duration)
+	}
+	if services.JobRuns != nil {
+		if _, err := services.JobRuns.Record(summary, startedAt); err != nil {
+			logger.Errorf("保存任务运行记录失败: %v", err)
+		}
+	}
+	var failed error
+	if summary.Failed > 0 {
+		failed = fmt.Errorf("%d of %d tasks failed", summary.Failed, summary.Total)
+	}
+	recordCronAudit(services, summary.Job, map[string]interface{}{
+		"started_at": startedAt,
+		"total":      summary.Total,
+		"success":    summary.Success,
+		"failed":     summary.Failed,
+	}, failed)
+	services.NotifyManger.SendJobSummary(context.Background(), summary)
+	services.NotifyManger.PublishJobEvent(context.Background(), notification.NewJobCompletedEvent(summary, startedAt))
+	logUpstreamStats()
+}
+
+// logUpstreamStats 开启上游请求统计时输出各接口自启动以来的累计统计
+func logUpstreamStats() {
+	stats := collector.GetCollectorFactory(logger.GetGlobalLogger()).RequestStats()
+	if stats == nil {
+		return
+	}
+	for _, endpoint := range stats.Snapshot() {
+		logger.Infof("上游接口统计 %s %s: 请求=%d, 失败率=%.2f%%, p50=%.1fms, p95=%.1fms, 响应=%d字节",
+			endpoint.Collector, endpoint.Endpoint, endpoint.Count, endpoint.ErrorRate*100, endpoint.P50Ms, endpoint.P95Ms, endpoint.Bytes)
+	}
+}
+
+// recordCronAudit 写入定时任务的审计记录，opErr为任务失败的原因，记录失败只记日志
+func recordCronAudit(services *service.Services, action string, params interface{}, opErr error) {
+	if services.Audit == nil {
+		return
+	}
+	if _, err := services.Audit.Record(model.AuditSourceCron, action, "", "worker", params, opErr); err != nil {
+		logger.Errorf("保存审计记录失败: %v", err)
+	}
+}
+
+// limitStocks 按worker.test_limit截断采集任务的股票列表，测试部署只处理少量股票，未配置时原样返回
+func limitStocks(stocks []*model.Stock) []*model.Stock {
+	if limit := workerConfig.StockCap(len(stocks)); limit < len(stocks) {
+		logger.Infof("已配置test_limit，本次任务只处理前 %d 只股票（共 %d 只）", limit, len(stocks))
+		return stocks[:limit]
+	}
+	return stocks
+}
+
+// isPriorityStock 判断股票是否需要优先同步
+func isPriorityStock(stock *model.Stock) bool {
+	return stock.Priority
+}
+
+// inTradingSession 判断是否处于A股连续竞价时段（9:30-11:30、13:00-15:00）
+func inTradingSession(now time.Time) bool {
+	hm := now.Hour()*100 + now.Minute()
+	return (hm >= 930 && hm <= 1130) || (hm >= 1300 && hm <= 1500)
+}
+
 func setupCronJobs(c *cron.Cron, services *service.Services) {
 
 	c.AddFunc("0 0 12 * * *", func() {
-		stocks, err := services.DataService.GetAllStocks()
+		stocks, ok, err := loadStockUniverse(services, "指标信号计算")
 		if err != nil {
+			logger.Errorf("指标信号计算失败: %v", err)
+			return
+		}
+		if !ok {
 			return
 		}
 
@@ -122,21 +315,38 @@ func setupCronJobs(c *cron.Cron, services *service.Services) {
 		}
 	})
 
+	c.AddFunc("0 */5 9-15 * * 1-5", func() {
+		now := utils.AppNow()
+		if !work || !utils.IsTradingDay(now) || !inTradingSession(now) {
+			return
+		}
+		// 盘中每5分钟刷新全市场实时行情，批次大小和并发由worker.realtime_batch_size/realtime_concurrency控制
+		err := services.DataService.SyncAllRealtimeData(workerConfig.RealtimeBatchSize, workerConfig.RealtimeConcurrency)
+		if err != nil {
+			logger.Errorf("同步实时行情失败: %v", err)
+		}
+		recordCronAudit(services, "realtime", nil, err)
+	})
+
 	c.AddFunc("0 10 16 * * *", func() {
-		if !isWorkingDay(time.Now()) {
+		if !utils.IsTradingDay(utils.AppNow()) {
 			return
 		}
 		// 除权、退市股票处理 - 第一优先级
-		_ = collectSkipStock(services)
+		recordCronAudit(services, "stock_list", nil, collectSkipStock(services))
 	})
 
 	c.AddFunc("0 10 18 * * *", func() {
 		if !work {
 			return
 		}
-		// 从数据库获取所有活跃股票列表
-		stocks, err := services.DataService.GetAllStocks()
+		// 从数据库获取所有活跃股票列表，股票列表为空时告警并跳过
+		stocks, ok, err := loadStockUniverse(services, "K线数据采集")
 		if err != nil {
+			logger.Errorf("K线数据采集失败: %v", err)
+			return
+		}
+		if !ok {
 			return
 		}
 		var list = make([]*model.Stock, 0, len(stocks))
@@ -145,8 +355,9 @@ func setupCronJobs(c *cron.Cron, services *service.Services) {
 				list = append(list, stock)
 			}
 		}
-		// 更新日K线数据
+		// 更新日K线数据，并检查当日K线的覆盖率
 		_ = collectTodayKLineData(services, list)
+		verifyDailySync(services, list)
 		// 更新周K线数据
 		_ = collectThisWeeklyKLineData(services, list)
 		// 更新月K线数据
@@ -155,6 +366,48 @@ func setupCronJobs(c *cron.Cron, services *service.Services) {
 		_ = collectThisYearlyKLineData(services, list)
 	})
 
+	c.AddFunc("0 30 17 * * *", func() {
+		if !work {
+			return
+		}
+		// 收盘后保存全市场当日资金流向
+		_, err := services.DataService.SyncFundFlows()
+		if err != nil {
+			logger.Errorf("同步资金流向失败: %v", err)
+		}
+		recordCronAudit(services, "fund_flow", nil, err)
+	})
+
+	c.AddFunc("0 40 17 * * *", func() {
+		if !work {
+			return
+		}
+		// 更新指数日K线数据，作为个股相对收益等功能的基准
+		err := services.IndexService.SyncAllIndexDaily()
+		if err != nil {
+			logger.Errorf("更新指数日K线数据失败: %v", err)
+		}
+		recordCronAudit(services, "index_daily", nil, err)
+	})
+
+	c.AddFunc("0 0 19 * * *", func() {
+		if !work {
+			return
+		}
+		// 日K线更新完成后计算当日综合评分
+		_, _, err := services.StockScoreService.ScoreAllStocks()
+		if err != nil {
+			logger.Errorf("计算股票综合评分失败: %v", err)
+		}
+		recordCronAudit(services, "score", nil, err)
+		// 重新评估日K线的数据质量
+		_, err = services.DataQuality.EvaluateAllStocks()
+		if err != nil {
+			logger.Errorf("评估股票数据质量失败: %v", err)
+		}
+		recordCronAudit(services, "data_quality", nil, err)
+	})
+
 	c.AddFunc("0 10 22 * * *", func() {
 		if !work {
 			return
@@ -169,6 +422,23 @@ func setupCronJobs(c *cron.Cron, services *service.Services) {
 		_ = collectAndPersistShareholderCounts(services)
 	})
 
+	c.AddFunc("0 30 23 * * *", func() {
+		if !work {
+			return
+		}
+		// 港交所在次一交易日早间披露上一交易日的北向持股，夜间同步最近的持股记录
+		_ = collectAndPersistNorthboundHoldings(services)
+	})
+
+	c.AddFunc("0 0 3 * * 0", func() {
+		// 每周日凌晨按保留年限清理过期的K线数据
+		_, err := services.DataService.PruneKLineHistory(workerConfig.Retention, utils.AppNow())
+		if err != nil {
+			logger.Errorf("清理过期K线数据失败: %v", err)
+		}
+		recordCronAudit(services, "prune_kline", workerConfig.Retention, err)
+	})
+
 	logger.Info("定时任务配置完成！")
 }
 
@@ -184,6 +454,15 @@ func initServicesWithDB(cfg *config.Config, db *gorm.DB) (*service.Services, err
 
 	// 初始化需要数据库连接的服务
 	services.DataService = service.GetDataService(db, logger.GetGlobalLogger())
+	services.DataService.SetWriteConcurrency(cfg.Worker.DBWriteConcurrency)
+	services.DataService.SetRealtimeSnapshot(cfg.Worker.RealtimeSnapshot)
+	services.DataService.SetChunkedHistoryFetch(cfg.Worker.ChunkedHistoryFetch)
+	services.DataService.SetLocalKLineAggregation(cfg.Worker.LocalKLineAggregation)
+	failover, err := collector.GetCollectorFactory(logger.GetGlobalLogger()).NewFailoverManager(cfg.CircuitBreaker)
+	if err != nil {
+		return nil, fmt.Errorf("创建采集器故障切换失败: %v", err)
+	}
+	services.DataService.SetCollectorManager(failover)
 
 	// 为PerformanceService创建必要的依赖
 	performanceRepo := repository.NewPerformance(db)
@@ -194,9 +473,41 @@ func initServicesWithDB(cfg *config.Config, db *gorm.DB) (*service.Services, err
 	// 为ShareholderService创建必要的依赖
 	shareholderRepo := repository.NewShareholder(db)
 	services.ShareholderService = service.NewShareholderService(shareholderRepo, eastMoneyCollector)
+	if err := db.AutoMigrate(&model.StockShares{}); err != nil {
+		return nil, fmt.Errorf("迁移股本变动记录表失败: %v", err)
+	}
+	services.ShareholderService.SetSharesRepository(repository.NewStockShares(db))
+
+	services.NorthboundService = service.NewNorthboundService(repository.NewNorthbound(db), eastMoneyCollector)
 
 	services.IndicatorService = service.GetIndicatorService(db)
 
+	// 为IndexService创建必要的依赖
+	services.IndexService = service.NewIndexService(repository.NewIndex(db), repository.NewIndexDaily(db), eastMoneyCollector)
+
+	services.StockScoreService = service.GetStockScoreService(db)
+	services.StockScoreService.SetWeights(cfg.Score.Weights)
+	services.DataQuality = service.GetDataQualityService(db)
+
+	if err := db.AutoMigrate(&model.JobRun{}); err != nil {
+		return nil, fmt.Errorf("迁移任务运行记录表失败: %v", err)
+	}
+	services.JobRuns = service.GetJobRunService(db)
+
+	if err := db.AutoMigrate(&model.AuditLog{}); err != nil {
+		return nil, fmt.Errorf("迁移审计记录表失败: %v", err)
+	}
+	services.Audit = service.GetAuditService(db)
+
+	// 开启通知持久化重试队列
+	if retry := cfg.Notify.Retry; retry != nil && retry.Enabled {
+		if err := db.AutoMigrate(&model.PendingNotification{}); err != nil {
+			return nil, fmt.Errorf("迁移待重发通知表失败: %v", err)
+		}
+		services.NotifyManger.EnableRetryQueue(repository.NewPendingNotification(db), retry.MaxAge)
+		logger.Infof("通知重试队列已开启，最长保留时间: %v", retry.MaxAge)
+	}
+
 	logger.Info("所有服务初始化完成")
 	return services, nil
 }
@@ -232,7 +543,7 @@ func collectSkipStock(services *service.Services) error {
 		return fmt.Errorf("股票信息同步失败: %v", err)
 	}
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.KLine, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
 	ctx := context.Background()
 
@@ -265,13 +576,43 @@ func collectSkipStock(services *service.Services) error {
 	return nil
 }
 
+// verifyDailySync 检查日K线同步后股票池中有当日K线的股票占比，低于worker.sync_verify_threshold时@所有人告警并发布任务失败事件
+func verifyDailySync(services *service.Services, stocks []*model.Stock) {
+	threshold := workerConfig.SyncVerifyThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	coverage, err := services.DataService.CheckDailySyncCoverage(limitStocks(stocks), utils.TodayTradeDate())
+	if err != nil {
+		logger.Errorf("检查日K线同步覆盖率失败: %v", err)
+		return
+	}
+	logger.Infof("日K线同步覆盖率: %d/%d (%.1f%%)", coverage.Updated, coverage.Expected, coverage.Ratio()*100)
+	if !coverage.BelowThreshold(threshold) {
+		return
+	}
+
+	err = fmt.Errorf("交易日 %d 只有 %d/%d 只股票有日K线，覆盖率 %.1f%% 低于 %.1f%%",
+		coverage.TradeDate, coverage.Updated, coverage.Expected, coverage.Ratio()*100, threshold*100)
+	logger.Errorf("日K线同步疑似大面积失败: %v", err)
+	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
+		Content: fmt.Sprintf("🚨 日K线同步疑似大面积失败，请检查数据源是否封禁或鉴权变化\n%s", err.Error()),
+		MsgType: notification.MessageTypeText,
+		AtAll:   true,
+	})
+	services.NotifyManger.PublishJobEvent(context.Background(), notification.NewJobFailedEvent(notification.JobDailyKLine, err))
+}
+
 // collectTodayKLineData 更新本周K线数据
 func collectTodayKLineData(services *service.Services, stocks []*model.Stock) error {
 	logger.Info("开始更新本日K线数据...")
+	stocks = limitStocks(stocks)
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.KLine, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
-	ctx := context.Background()
+	ctx, abort := newStrictAbort(context.Background())
+	defer abort.finish(services, "日K线数据采集")
 
 	// 创建任务列表
 	tasks := make([]utils.Task, 0, len(stocks))
@@ -281,7 +622,7 @@ func collectTodayKLineData(services *service.Services, stocks []*model.Stock) er
 			ID:          fmt.Sprintf("daily_kline_%s", stock.TsCode),
 			Description: fmt.Sprintf("采集股票 %s 的日K线数据", stock.TsCode),
 			Func: func(ctx context.Context) error {
-				return syncStockDailyKLine(services, stock)
+				return abort.check(syncStockDailyKLine(services, stock))
 			},
 		})
 	}
@@ -300,16 +641,17 @@ func collectTodayKLineData(services *service.Services, stocks []*model.Stock) er
 		}
 	}
 
-	completionMsg := fmt.Sprintf("📊 日K线数据采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("日K线数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: completionMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobDailyKLine,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 	return nil
 }
@@ -317,10 +659,12 @@ func collectTodayKLineData(services *service.Services, stocks []*model.Stock) er
 // collectThisWeeklyKLineData 更新本周K线数据
 func collectThisWeeklyKLineData(services *service.Services, stocks []*model.Stock) error {
 	logger.Info("开始更新本周K线数据...")
+	stocks = limitStocks(stocks)
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.KLine, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
-	ctx := context.Background()
+	ctx, abort := newStrictAbort(context.Background())
+	defer abort.finish(services, "周K线数据采集")
 
 	// 创建任务列表
 	tasks := make([]utils.Task, 0, len(stocks))
@@ -330,7 +674,7 @@ func collectThisWeeklyKLineData(services *service.Services, stocks []*model.Stoc
 			ID:          fmt.Sprintf("weekly_kline_%s", stock.TsCode),
 			Description: fmt.Sprintf("采集股票 %s 的周K线数据", stock.TsCode),
 			Func: func(ctx context.Context) error {
-				return syncStockWeeklyKLine(services, stock)
+				return abort.check(syncStockWeeklyKLine(services, stock))
 			},
 		})
 	}
@@ -349,16 +693,17 @@ func collectThisWeeklyKLineData(services *service.Services, stocks []*model.Stoc
 		}
 	}
 
-	completionMsg := fmt.Sprintf("📊 周K线数据采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("周K线数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: completionMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobWeeklyKLine,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 	return nil
 }
@@ -366,10 +711,12 @@ func collectThisWeeklyKLineData(services *service.Services, stocks []*model.Stoc
 // collectThisMonthlyKLineData 更新本月K线数据
 func collectThisMonthlyKLineData(services *service.Services, stocks []*model.Stock) error {
 	logger.Info("开始更新本月K线数据...")
+	stocks = limitStocks(stocks)
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.KLine, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
-	ctx := context.Background()
+	ctx, abort := newStrictAbort(context.Background())
+	defer abort.finish(services, "月K线数据采集")
 
 	// 创建任务列表
 	tasks := make([]utils.Task, 0, len(stocks))
@@ -379,7 +726,7 @@ func collectThisMonthlyKLineData(services *service.Services, stocks []*model.Sto
 			ID:          fmt.Sprintf("monthly_kline_%s", stock.TsCode),
 			Description: fmt.Sprintf("采集股票 %s 的月K线数据", stock.TsCode),
 			Func: func(ctx context.Context) error {
-				return syncStockMonthlyKLine(services, stock)
+				return abort.check(syncStockMonthlyKLine(services, stock))
 			},
 		})
 	}
@@ -398,16 +745,17 @@ func collectThisMonthlyKLineData(services *service.Services, stocks []*model.Sto
 		}
 	}
 
-	completionMsg := fmt.Sprintf("📊 月K线数据采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("周K线数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: completionMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobMonthlyKLine,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 	return nil
 }
@@ -415,10 +763,12 @@ func collectThisMonthlyKLineData(services *service.Services, stocks []*model.Sto
 // collectThisYearlyKLineData 更新本年K线数据
 func collectThisYearlyKLineData(services *service.Services, stocks []*model.Stock) error {
 	logger.Info("开始更新本年K线数据...")
+	stocks = limitStocks(stocks)
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.KLine, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
-	ctx := context.Background()
+	ctx, abort := newStrictAbort(context.Background())
+	defer abort.finish(services, "年K线数据采集")
 
 	// 创建任务列表
 	tasks := make([]utils.Task, 0, len(stocks))
@@ -428,7 +778,7 @@ func collectThisYearlyKLineData(services *service.Services, stocks []*model.Stoc
 			ID:          fmt.Sprintf("yearly_kline_%s", stock.TsCode),
 			Description: fmt.Sprintf("采集股票 %s 的年K线数据", stock.TsCode),
 			Func: func(ctx context.Context) error {
-				return syncStockYearlyKLine(services, stock)
+				return abort.check(syncStockYearlyKLine(services, stock))
 			},
 		})
 	}
@@ -447,43 +797,35 @@ func collectThisYearlyKLineData(services *service.Services, stocks []*model.Stoc
 		}
 	}
 
-	completionMsg := fmt.Sprintf("📊 年K线数据采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("年K线数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: completionMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobYearlyKLine,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 	return nil
 }
 
-// markStockInactive 标记股票为非活跃状态
-func markStockInactive(services *service.Services, tsCode string) error {
-	logger.Infof("标记股票 %s 为非活跃状态", tsCode)
-
-	// 获取股票信息
-	stock, err := services.DataService.GetStockInfo(tsCode)
+// refreshStaleStockStatus 日K线长期未更新时按数据源的停牌标志区分停牌和退市
+// 停牌股票保持活跃，复牌后继续采集；只有确认退市的股票才标记为非活跃
+func refreshStaleStockStatus(services *service.Services, tsCode string) error {
+	status, err := services.DataService.RefreshStockStatus(tsCode)
 	if err != nil {
-		return fmt.Errorf("获取股票信息失败: %v", err)
-	}
-
-	// 检查股票是否已经是非活跃状态
-	if !stock.IsActive {
-		logger.Debugf("股票 %s 已经是非活跃状态", tsCode)
-		return nil
+		return err
 	}
 
-	// 更新股票状态为非活跃
-	err = services.DataService.UpdateStockStatus(tsCode, false)
-	if err != nil {
-		return fmt.Errorf("更新股票状态失败: %v", err)
+	switch status {
+	case model.StockStatusSuspended:
+		logger.Infof("股票 %s 日K线长期未更新，当前处于停牌状态", tsCode)
+	case model.StockStatusDelisted:
+		logger.Infof("股票 %s 已退市，标记为非活跃状态", tsCode)
 	}
-
-	logger.Infof("成功标记股票 %s 为非活跃状态", tsCode)
 	return nil
 }
 
@@ -498,18 +840,17 @@ func syncStockDailyKLine(services *service.Services, stock *model.Stock) error {
 	var startDate time.Time
 	if latestData == nil {
 		// 数据库中没有数据，进行全量同步
-		startDate = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+		startDate = historyStartDate(services, stock)
 		logger.Infof("股票 %s 进行全量日K线同步，起始日期: %s", stock.TsCode, startDate.Format("2006-01-02"))
 	} else {
 		// 将TradeDate从int转换为time.Time进行比较
-		tradeDateStr := fmt.Sprintf("%d", latestData.TradeDate)
 		tradeDate, err := utils.ParseTradeDate(latestData.TradeDate)
 		if err != nil {
 			return fmt.Errorf("解析交易日期失败: %v", err)
 		}
 		startDate = tradeDate
-		if time.Now().Format("20060102") == tradeDateStr {
-			if latestData.UpdatedAt.Format(time.TimeOnly) > "16:00:00" { // 今日收盘后已经更新过一次，无需再更新
+		if utils.TodayTradeDate() == latestData.TradeDate {
+			if marketSession.IsFinal(tradeDate, latestData.UpdatedAt) { // 今日数据定型后已经更新过一次，无需再更新；定型前保存的是临时数据，继续更新
 				return nil
 			}
 			return updateStockTodayKLine(services, stock)
@@ -517,30 +858,29 @@ func syncStockDailyKLine(services *service.Services, stock *model.Stock) error {
 	}
 
 	// 实现真正的数据同步逻辑
-	endDate := time.Now()
+	endDate := utils.AppNow()
 	logger.Debugf("股票 %s 需要同步日K线数据，时间范围: %s 到 %s",
 		stock.TsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
 	// 调用DataService进行数据同步
 	syncCount, err := services.DataService.SyncDailyData(stock.TsCode, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("同步日K线数据失败: %v", err)
+		return fmt.Errorf("同步日K线数据失败: %w", err)
 	}
 
 	logger.Debugf("股票 %s 日K线数据同步完成，共同步 %d 条记录", stock.TsCode, syncCount)
 
 	latestData, _ = services.DataService.GetLatestPrice(stock.TsCode)
-	if latestData != nil { // 日k一个月没更新，可能已经退市了
+	if latestData != nil { // 日k一个月没更新，可能是长期停牌或已经退市
 		tradeDate, err := utils.ParseTradeDate(latestData.TradeDate)
 		if err != nil {
 			return fmt.Errorf("解析交易日期失败: %v", err)
 		}
 		// 检查最新数据是否超过一个月
-		oneMonthAgo := time.Now().AddDate(0, -1, 0)
+		oneMonthAgo := utils.AppNow().AddDate(0, -1, 0)
 		if tradeDate.Before(oneMonthAgo) {
-			// 标记为非活跃股票
-			if err := markStockInactive(services, stock.TsCode); err != nil {
-				logger.Errorf("标记股票 %s 为非活跃状态失败: %v", stock.TsCode, err)
+			if err := refreshStaleStockStatus(services, stock.TsCode); err != nil {
+				logger.Errorf("更新股票 %s 上市状态失败: %v", stock.TsCode, err)
 			}
 		}
 	}
@@ -560,15 +900,22 @@ func syncStockWeeklyKLine(services *service.Services, stock *model.Stock) error
 	// 第二步：确定采集的起始时间
 	var startDate time.Time
 	if latestWeeklyData == nil {
-		// 如果没有最新一条数据，默认起始时间为1990年1月1日
-		startDate = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
-		logger.Debugf("股票 %s 没有历史周K线数据，从1990年1月1日开始采集", stock.TsCode)
+		// 如果没有最新一条数据，默认起始时间为1990年1月1日（已知上市日期时从上市日期开始）
+		startDate = historyStartDate(services, stock)
+		logger.Debugf("股票 %s 没有历史周K线数据，从%s开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	} else {
 		// 删除最新的一条周K线数据，确保数据完整性
 		tradeDate, err := utils.ParseTradeDate(latestWeeklyData.TradeDate)
 		if err != nil {
 			return fmt.Errorf("解析最新周K线交易日期失败: %v", err)
 		}
+		// 最新一根周K线属于本周时只刷新本周K线
+		if model.KLineBucketWeek.IsForming(latestWeeklyData.TradeDate, utils.TodayTradeDate()) {
+			if formingBarIsFresh(latestWeeklyData.UpdatedAt) {
+				return nil
+			}
+			return updateStockThisWeekKLine(services, stock, latestWeeklyData.TradeDate)
+		}
 		// 删除最新的周K线数据
 		if err := klinePersistence.DeleteData(stock.TsCode, tradeDate, "weekly"); err != nil {
 			logger.Errorf("删除最新周K线数据失败: %v", err)
@@ -576,17 +923,13 @@ func syncStockWeeklyKLine(services *service.Services, stock *model.Stock) error
 		}
 		logger.Debugf("已删除股票 %s 最新的周K线数据，交易日期: %d", stock.TsCode, latestWeeklyData.TradeDate)
 
-		if IsSameISOWeek(tradeDate, time.Now()) {
-			return updateStockThisWeekKLine(services, stock)
-		}
-
 		// 从最新一条数据的时间开始采集
 		startDate = tradeDate
 		logger.Debugf("股票 %s 从最新周K线数据日期 %s 开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	}
 
 	// 采集到当前时间的数据
-	endDate := time.Now()
+	endDate := utils.AppNow()
 
 	logger.Debugf("股票 %s 需要同步周K线数据，时间范围: %s 到 %s",
 		stock.TsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -594,7 +937,7 @@ func syncStockWeeklyKLine(services *service.Services, stock *model.Stock) error
 	// 调用DataService进行数据同步
 	syncCount, err := services.DataService.SyncWeeklyData(stock.TsCode, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("同步周K线数据失败: %v", err)
+		return fmt.Errorf("同步周K线数据失败: %w", err)
 	}
 
 	logger.Debugf("股票 %s 周K线数据同步完成，共同步 %d 条记录", stock.TsCode, syncCount)
@@ -614,9 +957,9 @@ func syncStockMonthlyKLine(services *service.Services, stock *model.Stock) error
 	// 第三步：确定采集的起始时间
 	var startDate time.Time
 	if latestMonthlyData == nil {
-		// 如果没有最新一条数据，默认起始时间为1990年1月1日
-		startDate = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
-		logger.Debugf("股票 %s 没有历史月K线数据，从1990年1月1日开始采集", stock.TsCode)
+		// 如果没有最新一条数据，默认起始时间为1990年1月1日（已知上市日期时从上市日期开始）
+		startDate = historyStartDate(services, stock)
+		logger.Debugf("股票 %s 没有历史月K线数据，从%s开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	} else {
 		// 删除最新的一条月K线数据，确保数据完整性
 		tradeDate, err := utils.ParseTradeDate(latestMonthlyData.TradeDate)
@@ -626,23 +969,26 @@ func syncStockMonthlyKLine(services *service.Services, stock *model.Stock) error
 		if isLastTradeDate(latestMonthlyData.TradeDate) { // 今天的数据已经固化成功
 			return nil
 		}
+		// 最新一根月K线属于本月时只刷新本月K线
+		if model.KLineBucketMonth.IsForming(latestMonthlyData.TradeDate, utils.TodayTradeDate()) {
+			if formingBarIsFresh(latestMonthlyData.UpdatedAt) {
+				return nil
+			}
+			return updateStockThisMonthKLine(services, stock, latestMonthlyData.TradeDate)
+		}
 		// 删除最新的月K线数据
 		if err := klinePersistence.DeleteData(stock.TsCode, tradeDate, "monthly"); err != nil {
 			logger.Errorf("删除最新月K线数据失败: %v", err)
 			return fmt.Errorf("删除最新月K线数据失败: %v", err)
 		}
 		logger.Debugf("已删除股票 %s 最新的月K线数据，交易日期: %d", stock.TsCode, latestMonthlyData.TradeDate)
-
-		if IsSameMonth(tradeDate, time.Now()) {
-			return updateStockThisMonthKLine(services, stock)
-		}
 		// 从最新一条数据的时间开始采集
 		startDate = tradeDate
 		logger.Debugf("股票 %s 从最新月K线数据日期 %s 开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	}
 
 	// 采集到当前时间的数据
-	endDate := time.Now()
+	endDate := utils.AppNow()
 
 	logger.Debugf("股票 %s 需要同步月K线数据，时间范围: %s 到 %s",
 		stock.TsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -650,7 +996,7 @@ func syncStockMonthlyKLine(services *service.Services, stock *model.Stock) error
 	// 调用DataService进行数据同步
 	syncCount, err := services.DataService.SyncMonthlyData(stock.TsCode, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("同步月K线数据失败: %v", err)
+		return fmt.Errorf("同步月K线数据失败: %w", err)
 	}
 
 	logger.Debugf("股票 %s 月K线数据同步完成，共同步 %d 条记录", stock.TsCode, syncCount)
@@ -669,9 +1015,9 @@ func syncStockYearlyKLine(services *service.Services, stock *model.Stock) error
 	// 第二步：确定采集的起始时间
 	var startDate time.Time
 	if latestYearlyData == nil {
-		// 如果没有最新一条数据，默认起始时间为1990年1月1日
-		startDate = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
-		logger.Debugf("股票 %s 没有历史年K线数据，从1990年1月1日开始采集", stock.TsCode)
+		// 如果没有最新一条数据，默认起始时间为1990年1月1日（已知上市日期时从上市日期开始）
+		startDate = historyStartDate(services, stock)
+		logger.Debugf("股票 %s 没有历史年K线数据，从%s开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	} else {
 		// 删除最新的一条年K线数据，确保数据完整性
 		tradeDate, err := utils.ParseTradeDate(latestYearlyData.TradeDate)
@@ -682,23 +1028,26 @@ func syncStockYearlyKLine(services *service.Services, stock *model.Stock) error
 		if isLastTradeDate(latestYearlyData.TradeDate) { // 今天的数据已经固化成功
 			return nil
 		}
+		// 最新一根年K线属于本年时只刷新本年K线
+		if model.KLineBucketYear.IsForming(latestYearlyData.TradeDate, utils.TodayTradeDate()) {
+			if formingBarIsFresh(latestYearlyData.UpdatedAt) {
+				return nil
+			}
+			return updateStockThisYearKLine(services, stock, latestYearlyData.TradeDate)
+		}
 		// 删除最新的年K线数据
 		if err := klinePersistence.DeleteData(stock.TsCode, tradeDate, "yearly"); err != nil {
 			logger.Errorf("删除最新年K线数据失败: %v", err)
 			return fmt.Errorf("删除最新年K线数据失败: %v", err)
 		}
 		logger.Debugf("已删除股票 %s 最新的年K线数据，交易日期: %d", stock.TsCode, latestYearlyData.TradeDate)
-
-		if IsSameYear(tradeDate, time.Now()) {
-			return updateStockThisYearKLine(services, stock)
-		}
 		// 从最新一条数据的时间开始采集
 		startDate = tradeDate
 		logger.Debugf("股票 %s 从最新年K线数据日期 %s 开始采集", stock.TsCode, startDate.Format("2006-01-02"))
 	}
 
 	// 采集到当前时间的数据
-	endDate := time.Now()
+	endDate := utils.AppNow()
 
 	logger.Debugf("股票 %s 需要同步年K线数据，时间范围: %s 到 %s",
 		stock.TsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -706,7 +1055,7 @@ func syncStockYearlyKLine(services *service.Services, stock *model.Stock) error
 	// 调用DataService进行数据同步
 	syncCount, err := services.DataService.SyncYearlyData(stock.TsCode, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("同步年K线数据失败: %v", err)
+		return fmt.Errorf("同步年K线数据失败: %w", err)
 	}
 
 	logger.Debugf("股票 %s 年K线数据同步完成，共同步 %d 条记录", stock.TsCode, syncCount)
@@ -728,78 +1077,146 @@ func updateStockTodayKLine(services *service.Services, stock *model.Stock) error
 	return services.DataService.UpsertKLineData([]model.DailyData{*today})
 }
 
-// updateStockWeeklyKLine 更新单只股票本周K线数据
-func updateStockThisWeekKLine(services *service.Services, stock *model.Stock) error {
+// formingBarIsFresh 判断数据库中当期K线是否仍然有效，有效时跳过同花顺当期K线请求，减少夜间任务的重复请求
+func formingBarIsFresh(updatedAt time.Time) bool {
+	return marketSession.IsFresh(updatedAt, utils.AppNow(), workerConfig.FormingBarMaxAge)
+}
+
+// updateStockThisWeekKLine 更新单只股票本周K线数据，latestDate为数据库中本周K线的交易日期
+func updateStockThisWeekKLine(services *service.Services, stock *model.Stock, latestDate int) error {
+	// 开启本地聚合时由已保存的日K线生成当期K线，本期还没有日K线时仍从数据源获取
+	if services.DataService.LocalKLineAggregation() {
+		current, err := services.DataService.AggregatePeriodKLine(stock.TsCode, model.KLineBucketWeek, utils.TodayTradeDate())
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			return replaceFormingBar(services, stock.TsCode, model.KLineBucketWeek, "weekly", latestDate, model.WeeklyData(*current))
+		}
+	}
+
 	c, err := collector.GetCollectorFactory(logger.GetGlobalLogger()).CreateCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return err
 	}
 
-	today, err := c.GetThisWeekData(stock.TsCode)
+	current, err := c.GetThisWeekData(stock.TsCode)
 	if err != nil {
 		return err
 	}
 
-	return services.DataService.UpsertKLineData([]model.WeeklyData{*today})
+	return replaceFormingBar(services, stock.TsCode, model.KLineBucketWeek, "weekly", latestDate, *current)
 }
 
-// updateStockMonthlyKLine 更新单只股票本月K线数据
-func updateStockThisMonthKLine(services *service.Services, stock *model.Stock) error {
+// updateStockThisMonthKLine 更新单只股票本月K线数据，latestDate为数据库中本月K线的交易日期
+func updateStockThisMonthKLine(services *service.Services, stock *model.Stock, latestDate int) error {
+	// 开启本地聚合时由已保存的日K线生成当期K线，本期还没有日K线时仍从数据源获取
+	if services.DataService.LocalKLineAggregation() {
+		current, err := services.DataService.AggregatePeriodKLine(stock.TsCode, model.KLineBucketMonth, utils.TodayTradeDate())
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			return replaceFormingBar(services, stock.TsCode, model.KLineBucketMonth, "monthly", latestDate, model.MonthlyData(*current))
+		}
+	}
+
 	c, err := collector.GetCollectorFactory(logger.GetGlobalLogger()).CreateCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return err
 	}
 
-	today, err := c.GetThisMonthData(stock.TsCode)
+	current, err := c.GetThisMonthData(stock.TsCode)
 	if err != nil {
 		return err
 	}
 
-	return services.DataService.UpsertKLineData([]model.MonthlyData{*today})
+	return replaceFormingBar(services, stock.TsCode, model.KLineBucketMonth, "monthly", latestDate, *current)
 }
 
-// updateStockThisYearKLine 更新单只股票本年K线数据
-func updateStockThisYearKLine(services *service.Services, stock *model.Stock) error {
+// updateStockThisYearKLine 更新单只股票本年K线数据，latestDate为数据库中本年K线的交易日期
+func updateStockThisYearKLine(services *service.Services, stock *model.Stock, latestDate int) error {
+	// 开启本地聚合时由已保存的日K线生成当期K线，本期还没有日K线时仍从数据源获取
+	if services.DataService.LocalKLineAggregation() {
+		current, err := services.DataService.AggregatePeriodKLine(stock.TsCode, model.KLineBucketYear, utils.TodayTradeDate())
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			return replaceFormingBar(services, stock.TsCode, model.KLineBucketYear, "yearly", latestDate, model.YearlyData(*current))
+		}
+	}
+
 	c, err := collector.GetCollectorFactory(logger.GetGlobalLogger()).CreateCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return err
 	}
 
-	today, err := c.GetThisYearData(stock.TsCode)
+	current, err := c.GetThisYearData(stock.TsCode)
 	if err != nil {
 		return err
 	}
 
-	return services.DataService.UpsertKLineData([]model.YearlyData{*today})
+	return replaceFormingBar(services, stock.TsCode, model.KLineBucketYear, "yearly", latestDate, *current)
+}
+
+// replaceFormingBar 用数据源返回的当期K线替换数据库中尚在形成中的当期K线，latestDate为数据库中当期K线的交易日期
+// 先获取再替换，数据源返回的K线不能替换时保留原有K线，替换规则见model.KLineBucket.ReplaceForming
+func replaceFormingBar[T model.TradeDated](services *service.Services, tsCode string, bucket model.KLineBucket,
+	dataType string, latestDate int, bar T) error {
+	replace, deleteStale := bucket.ReplaceForming(latestDate, bar.GetTradeDate(), utils.TodayTradeDate())
+	if !replace {
+		logger.Warnf("股票 %s 数据源返回的当期%s K线日期 %d 不属于当期或早于已保存的 %d，保留原有K线",
+			tsCode, dataType, bar.GetTradeDate(), latestDate)
+		return nil
+	}
+
+	if deleteStale {
+		staleDate, err := utils.ParseTradeDate(latestDate)
+		if err != nil {
+			return err
+		}
+		klinePersistence := service.GetKLinePersistenceService(services.DataService.GetDB(), logger.GetGlobalLogger())
+		if err := klinePersistence.DeleteData(tsCode, staleDate, dataType); err != nil {
+			return fmt.Errorf("删除旧的当期K线数据失败: %v", err)
+		}
+	}
+	return services.DataService.UpsertKLineData([]T{bar})
 }
 
 // collectAndPersistPerformanceReports 采集并保存业绩报表数据
 func collectAndPersistPerformanceReports(services *service.Services) error {
 	logger.Info("开始采集业绩报表数据...")
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 30*time.Minute) // 最大100个并发，30分钟超时
+	executor := newJobExecutor(workerConfig.Performance, 30*time.Minute) // 30分钟超时
 	defer executor.Close()
 	ctx := context.Background()
 
-	// 从数据库获取所有股票列表
-	stocks, err := services.DataService.GetAllStocks()
-	if err != nil {
-		return fmt.Errorf("获取股票列表失败: %v", err)
+	// 从数据库获取所有股票列表（优先同步的股票排在前面）
+	stocks, ok, err := loadStockUniverse(services, "业绩报表数据采集")
+	if !ok {
+		return err
 	}
 
 	logger.Infof("从数据库获取到 %d 只股票，开始采集业绩报表数据", len(stocks))
 
+	// 一次查询所有股票最新一期报表，避免逐只股票查询
+	latestReports, err := services.PerformanceService.GetLatestPerformanceReports(nil)
+	if err != nil {
+		return fmt.Errorf("获取最新业绩报表失败: %v", err)
+	}
+
+	// 一个月前
+	date := utils.AppNow().AddDate(0, -1, 0)
+	// 优先股票始终采集，其余股票一天只更新100条，防止封ip
+	selected := utils.SelectWithQuota(stocks, dailyQuota, isPriorityStock, func(stock *model.Stock) bool {
+		report, ok := latestReports[stock.TsCode]
+		return !ok || !report.UpdatedAt.After(date) // 一个月内更新过，直接跳过
+	})
+	selected = limitStocks(selected)
+
 	// 创建并发任务列表
 	var tasks []utils.Task
-	// 一个月前
-	date := time.Now().AddDate(0, -1, 0)
-	for _, stock := range stocks {
-		report, err := services.PerformanceService.GetLatestPerformanceReport(ctx, stock.TsCode)
-		if err != nil {
-			continue
-		}
-		if report != nil && report.UpdatedAt.After(date) { // 一个月内更新过，直接跳过
-			continue
-		}
+	for _, stock := range selected {
 		// 为每只股票创建一个采集任务
 		tsCode := stock.TsCode // 捕获循环变量
 		task := &utils.SimpleTask{
@@ -810,9 +1227,6 @@ func collectAndPersistPerformanceReports(services *service.Services) error {
 			},
 		}
 		tasks = append(tasks, task)
-		if len(tasks) >= 100 { // 一天只更新200条，防止封ip
-			break
-		}
 	}
 
 	// 执行任务
@@ -832,16 +1246,17 @@ func collectAndPersistPerformanceReports(services *service.Services) error {
 		}
 	}
 
-	performanceMsg := fmt.Sprintf("📈 业绩报表采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("业绩报表数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 同步报表: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, totalReports, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: performanceMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobPerformance,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 
 	return nil
@@ -851,43 +1266,45 @@ func collectAndPersistPerformanceReports(services *service.Services) error {
 func collectAndPersistShareholderCounts(services *service.Services) error {
 	logger.Info("开始采集股东人数数据...")
 
-	executor := utils.NewConcurrentExecutor(maxConcurrent, 45*time.Minute) // 最大50个并发，45分钟超时
+	executor := newJobExecutor(workerConfig.Shareholder, 45*time.Minute) // 45分钟超时
 	defer executor.Close()
 	ctx := context.Background()
 
-	// 从数据库获取所有活跃股票列表
-	stocks, err := services.DataService.GetAllStocks()
-	if err != nil {
-		return fmt.Errorf("获取股票列表失败: %v", err)
+	// 从数据库获取所有活跃股票列表（优先同步的股票排在前面）
+	stocks, ok, err := loadStockUniverse(services, "股东人数数据采集")
+	if !ok {
+		return err
 	}
 
 	logger.Infof("从数据库获取到 %d 只股票，开始采集股东人数数据", len(stocks))
 
+	// 一次查询所有股票最新一期股东人数，避免逐只股票查询
+	latestCounts, err := services.ShareholderService.GetLatestShareholderCounts(nil)
+	if err != nil {
+		return fmt.Errorf("获取最新股东人数失败: %v", err)
+	}
+
+	date := utils.AppNow().AddDate(0, 0, -7)
+	// 优先股票始终采集，其余股票一天只更新100条，防止封ip
+	selected := utils.SelectWithQuota(stocks, dailyQuota, isPriorityStock, func(stock *model.Stock) bool {
+		count, ok := latestCounts[stock.TsCode]
+		return !ok || !count.UpdatedAt.After(date) // 7天内更新过，直接跳过
+	})
+	selected = limitStocks(selected)
+
 	// 创建并发任务列表
 	var tasks []utils.Task
-	date := time.Now().AddDate(0, 0, -7)
-	for _, stock := range stocks {
-		count, err := services.ShareholderService.GetLatestShareholderCount(stock.TsCode)
-		if err != nil {
-			continue
-		}
-		if count != nil && count.UpdatedAt.After(date) { // 7天内更新过，直接跳过
-			continue
-		}
-
+	for _, stock := range selected {
 		// 为每只股票创建一个采集任务
 		tsCode := stock.TsCode // 捕获循环变量
 		task := &utils.SimpleTask{
 			ID:          fmt.Sprintf("shareholder-count-%s", tsCode),
 			Description: fmt.Sprintf("采集股票 %s 的股东人数", tsCode),
 			Func: func(ctx context.Context) error {
-				return services.ShareholderService.SyncShareholderCounts(tsCode)
+				return services.ShareholderService.SyncShareholderCounts(ctx, tsCode)
 			},
 		}
 		tasks = append(tasks, task)
-		if len(tasks) >= 100 { // 一天只更新100条，防止封ip
-			break
-		}
 	}
 
 	if len(tasks) == 0 {
@@ -911,17 +1328,101 @@ func collectAndPersistShareholderCounts(services *service.Services) error {
 		}
 	}
 
-	shareholderMsg := fmt.Sprintf("👥 股东人数采集完成\n总数: %d\n成功: %d\n失败: %d\n总耗时: %v\n平均耗时: %v",
-		stats.TotalTasks, successCount, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
-
 	logger.Infof("股东人数数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 同步股票: %d, 总耗时: %v, 平均耗时: %v",
 		stats.TotalTasks, successCount, stats.FailedTasks, totalCounts, stats.EndTime.Sub(stats.StartTime),
 		stats.AverageDuration)
 
-	// 同步日志信息给机器人
-	services.NotifyManger.SendToAllBots(context.Background(), &notification.Message{
-		Content: shareholderMsg,
-		MsgType: notification.MessageTypeText,
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobShareholder,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
+	})
+
+	return nil
+}
+
+// collectAndPersistNorthboundHoldings 采集并保存北向资金持股数据
+func collectAndPersistNorthboundHoldings(services *service.Services) error {
+	logger.Info("开始采集北向持股数据...")
+
+	executor := newJobExecutor(workerConfig.Northbound, 45*time.Minute) // 45分钟超时
+	defer executor.Close()
+	ctx := context.Background()
+
+	// 从数据库获取所有活跃股票列表（优先同步的股票排在前面）
+	stocks, ok, err := loadStockUniverse(services, "北向持股数据采集")
+	if !ok {
+		return err
+	}
+
+	logger.Infof("从数据库获取到 %d 只股票，开始采集北向持股数据", len(stocks))
+
+	// 一次查询所有股票最新一期北向持股，避免逐只股票查询
+	latestHoldings, err := services.NorthboundService.GetLatestNorthboundHoldings(nil)
+	if err != nil {
+		return fmt.Errorf("获取最新北向持股失败: %v", err)
+	}
+
+	date := utils.AppNow().AddDate(0, 0, -1)
+	// 优先股票始终采集，其余股票一天只更新100条，防止封ip
+	selected := utils.SelectWithQuota(stocks, dailyQuota, isPriorityStock, func(stock *model.Stock) bool {
+		holding, ok := latestHoldings[stock.TsCode]
+		return !ok || !holding.UpdatedAt.After(date) // 一天内更新过，直接跳过
+	})
+	selected = limitStocks(selected)
+
+	// 创建并发任务列表
+	var tasks []utils.Task
+	var totalHoldings int64
+	for _, stock := range selected {
+		// 为每只股票创建一个采集任务
+		tsCode := stock.TsCode // 捕获循环变量
+		task := &utils.SimpleTask{
+			ID:          fmt.Sprintf("northbound-holding-%s", tsCode),
+			Description: fmt.Sprintf("采集股票 %s 的北向持股", tsCode),
+			Func: func(ctx context.Context) error {
+				n, err := services.NorthboundService.SyncNorthboundHoldings(ctx, tsCode)
+				atomic.AddInt64(&totalHoldings, int64(n))
+				return err
+			},
+		}
+		tasks = append(tasks, task)
+	}
+
+	if len(tasks) == 0 {
+		logger.Warn("没有找到需要采集北向持股数据的活跃股票")
+		return nil
+	}
+
+	// 执行任务
+	results, stats := executor.ExecuteBatch(ctx, tasks)
+
+	// 统计结果
+	successCount := 0
+	for _, result := range results {
+		if result.Success {
+			successCount++
+		} else {
+			logger.Errorf("北向持股采集失败: %v", result.Error)
+		}
+	}
+
+	logger.Infof("北向持股数据采集完成 - 总数: %d, 成功: %d, 失败: %d, 保存记录: %d, 总耗时: %v, 平均耗时: %v",
+		stats.TotalTasks, successCount, stats.FailedTasks, atomic.LoadInt64(&totalHoldings),
+		stats.EndTime.Sub(stats.StartTime), stats.AverageDuration)
+
+	// 保存运行记录并同步日志信息给机器人
+	reportJobSummary(services, stats.StartTime, notification.JobSummary{
+		Job:             notification.JobNorthbound,
+		Total:           stats.TotalTasks,
+		Success:         successCount,
+		Failed:          stats.FailedTasks,
+		Duration:        stats.EndTime.Sub(stats.StartTime),
+		AverageDuration: stats.AverageDuration,
 	})
 
 	return nil
@@ -937,8 +1438,7 @@ func calculateStockSignal(stock *model.Stock, ch []chan *model.Stock) error {
 	if err != nil {
 		return err
 	}
-	today := time.Now().Format("20060102")
-	i, _ := strconv.Atoi(today)
+	i := utils.TodayTradeDate()
 	res := indicator.RedThree(daily)
 	if res != nil {
 		if len(res.Signals.BuySignals) > 0 && res.Signals.BuySignals[len(res.Signals.BuySignals)-1] == i {
@@ -984,8 +1484,8 @@ func isLastTradeDate(tradeDate int) bool {
 		return false
 	}
 
-	// 获取当前时间
-	now := time.Now()
+	// 获取应用时区的当前时间
+	now := utils.AppNow()
 
 	// 如果输入日期是未来日期，返回false
 	if inputDate.After(now) {
@@ -996,7 +1496,7 @@ func isLastTradeDate(tradeDate int) bool {
 	currentDate := now
 	for {
 		// 检查当前日期是否为交易日（周一到周五，排除节假日）
-		if isWorkingDay(currentDate) {
+		if utils.IsTradingDay(currentDate) {
 			// 找到最近的交易日，比较是否与输入日期相同
 			lastTradeDate := currentDate.Year()*10000 + int(currentDate.Month())*100 + currentDate.Day()
 			return tradeDate == lastTradeDate
@@ -1012,39 +1512,3 @@ func isLastTradeDate(tradeDate int) bool {
 
 	return false
 }
-
-// isWorkingDay 判断是否为工作日（周一到周五，简化版本，不考虑部分节假日）
-func isWorkingDay(date time.Time) bool {
-	weekday := date.Weekday()
-	// 周一到周五为工作日
-	if !(weekday >= time.Monday && weekday <= time.Friday) {
-		return false
-	}
-
-	if date.Month() == time.October && date.Day() <= 7 { // 国庆
-		return false
-	}
-
-	if date.Month() == time.May && date.Day() <= 4 { // 五一
-		return false
-	}
-
-	return true
-}
-
-// IsSameISOWeek 判断两个时间是否在同一ISO周（周一为周开始）
-func IsSameISOWeek(t1, t2 time.Time) bool {
-	y1, w1 := t1.ISOWeek()
-	y2, w2 := t2.ISOWeek()
-	return y1 == y2 && w1 == w2
-}
-
-// IsSameMonth 判断两个时间是否在同一月
-func IsSameMonth(t1, t2 time.Time) bool {
-	return t1.Year() == t2.Year() && t1.Month() == t2.Month()
-}
-
-// IsSameYear 判断两个时间是否在同一年
-func IsSameYear(t1, t2 time.Time) bool {
-	return t1.Year() == t2.Year()
-}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"stock/internal/logger"
+	"stock/internal/notification"
+	"stock/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// recordingBot 记录收到的消息的机器人
+type recordingBot struct {
+	notification.NotificationBot
+	messages []*notification.Message
+}
+
+func (b *recordingBot) SendMessage(ctx context.Context, message *notification.Message) error {
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func (b *recordingBot) GetBotType() notification.BotType { return notification.BotTypeDingTalk }
+
+// recordingSink 记录收到的任务事件的接收端
+type recordingSink struct {
+	events []notification.JobEvent
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Publish(ctx context.Context, event notification.JobEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestLoadStockUniverse_EmptyStocksNotifies(t *testing.T) {
+	// DryRun连接上的查询不返回任何行，相当于股票表为空
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	manager := notification.NewManager(log)
+	bot := &recordingBot{}
+	require.NoError(t, manager.RegisterBot(notification.BotTypeDingTalk, bot))
+	sink := &recordingSink{}
+	require.NoError(t, manager.RegisterSink(sink))
+	services := &service.Services{DataService: service.GetDataService(db, log), NotifyManger: manager}
+
+	stocks, ok, err := loadStockUniverse(services, "K线数据采集")
+	require.NoError(t, err)
+	assert.False(t, ok, "股票列表为空时跳过任务")
+	assert.Empty(t, stocks)
+
+	require.Len(t, bot.messages, 1)
+	assert.Contains(t, bot.messages[0].Content, "K线数据采集未执行")
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, notification.JobEventFailed, sink.events[0].Type)
+	assert.Equal(t, "K线数据采集", sink.events[0].Job)
+	assert.Contains(t, sink.events[0].Error, service.ErrEmptyStockUniverse.Error())
+}
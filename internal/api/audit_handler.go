@@ -0,0 +1,77 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计记录查询返回数量的默认值和上限
+const (
+	defaultAuditLogsLimit = 100
+	maxAuditLogsLimit     = 1000
+)
+
+// GetAuditLogs 获取修改数据的操作的审计记录，按时间倒序
+// source为操作来源（cron、api、cli），action为操作，ts_code为股票代码，start_date、end_date限定日期区间（包含首尾两天），
+// 均为空时返回全部记录；limit限制返回数量
+func (h *Handler) GetAuditLogs(c *gin.Context) {
+	filter := repository.AuditLogFilter{
+		Source: model.AuditSource(c.Query("source")),
+		Action: c.Query("action"),
+		TsCode: strings.ToUpper(c.Query("ts_code")),
+		Limit:  defaultAuditLogsLimit,
+	}
+	switch filter.Source {
+	case "", model.AuditSourceCron, model.AuditSourceAPI, model.AuditSourceCLI:
+	default:
+		Error(c, CodeInvalidParam, "source参数错误，可选值：cron、api、cli")
+		return
+	}
+
+	now := utils.AppNow()
+	if value := c.Query("start_date"); value != "" {
+		date, err := parseTradeDate(value, now)
+		if err != nil {
+			Error(c, CodeInvalidParam, "start_date日期格式错误，应为YYYYMMDD或YYYY-MM-DD")
+			return
+		}
+		filter.Since = date
+	}
+	if value := c.Query("end_date"); value != "" {
+		date, err := parseTradeDate(value, now)
+		if err != nil {
+			Error(c, CodeInvalidParam, "end_date日期格式错误，应为YYYYMMDD或YYYY-MM-DD")
+			return
+		}
+		filter.Until = date.AddDate(0, 0, 1)
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLogsLimit {
+			Error(c, CodeInvalidParam, "limit应为1到1000之间的整数")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	h.logger.Infof("API: Getting audit logs, source %q, action %q, ts_code %q", filter.Source, filter.Action, filter.TsCode)
+
+	entries, err := h.auditService.List(filter)
+	if err != nil {
+		h.logger.Errorf("Failed to get audit logs: %v", err)
+		Error(c, CodeInternalError, "获取审计记录失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count": len(entries),
+		"logs":  entries,
+	})
+}
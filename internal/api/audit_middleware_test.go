@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stock/internal/model"
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestAuditMiddleware_RecordsSync(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var sqls []string
+	var recorded []model.AuditLog
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+		if entry, ok := tx.Statement.Dest.(*model.AuditLog); ok {
+			recorded = append(recorded, *entry)
+		}
+	}))
+	audit := service.NewAuditService(db)
+
+	router := gin.New()
+	router.Use(AuditMiddleware(audit, "/api/v1/realtime/batch"))
+	router.POST("/api/v1/stocks/:code/sync", func(c *gin.Context) {
+		var req struct {
+			Force bool `json:"force"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		assert.True(t, req.Force, "handler must still read the request body")
+		Success(c, gin.H{"task_id": "task-1"})
+	})
+	router.GET("/api/v1/stocks/:code", func(c *gin.Context) { Success(c, nil) })
+	router.POST("/api/v1/realtime/batch", func(c *gin.Context) { Success(c, nil) })
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/api/v1/stocks/600519.sh/sync?source=eastmoney", strings.NewReader(`{"force":true}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v1/stocks/600519.SH", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/realtime/batch", strings.NewReader(`{"codes":["600519.SH"]}`)),
+	} {
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// 只读请求和排除的POST接口不记录
+	require.Len(t, sqls, 1)
+	assert.Contains(t, sqls[0], "INSERT INTO `audit_log`")
+	require.Len(t, recorded, 1)
+	entry := recorded[0]
+	assert.Equal(t, model.AuditSourceAPI, entry.Source)
+	assert.Equal(t, "POST /api/v1/stocks/:code/sync", entry.Action)
+	assert.Equal(t, "600519.SH", entry.TsCode)
+	assert.NotEmpty(t, entry.Actor)
+	assert.True(t, entry.Success)
+	assert.JSONEq(t, `{"path":{"code":"600519.sh"},"query":{"source":["eastmoney"]},"body":"{\"force\":true}"}`, entry.Params)
+
+	// 鉴权失败的同步请求同样记录
+	sqls, recorded = nil, nil
+	protected := gin.New()
+	RegisterRoutes(protected, &Handler{logger: logrus.New(), auditService: audit}, testAuthToken)
+	status, _ := serve(t, protected, http.MethodPost, "/api/v1/stocks/000001.SZ/sync", nil)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "POST /api/v1/stocks/:code/sync", recorded[0].Action)
+	assert.False(t, recorded[0].Success)
+	assert.Equal(t, "HTTP 401", recorded[0].Error)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchLatestCodes 批量获取最新K线时单次最多请求的股票数
+const maxBatchLatestCodes = 100
+
+// BatchLatestBarsResult 批量获取最新K线的结果
+type BatchLatestBarsResult struct {
+	Items   []model.DailyData `json:"items"`   // 有数据的股票最新一根日K线，与请求顺序一致
+	Missing []string          `json:"missing"` // 数据库中没有K线数据的股票代码
+}
+
+// parseBatchCodes 解析逗号分隔的股票代码，统一转为大写并去重，校验失败时返回错误码和错误信息
+func parseBatchCodes(raw string) ([]string, int, string) {
+	seen := make(map[string]bool)
+	codes := make([]string, 0)
+	for _, code := range strings.Split(raw, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		if !strings.Contains(code, ".") {
+			return nil, CodeInvalidTsCode, fmt.Sprintf("股票代码格式错误：%s，应为：000001.SZ 或 600000.SH", code)
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+
+	if len(codes) == 0 {
+		return nil, CodeEmptyTsCode, "股票代码不能为空"
+	}
+	if len(codes) > maxBatchLatestCodes {
+		return nil, CodeInvalidParam, fmt.Sprintf("单次最多请求%d只股票", maxBatchLatestCodes)
+	}
+	return codes, CodeSuccess, ""
+}
+
+// buildBatchLatestResult 按请求顺序整理最新K线，没有数据的股票放入Missing
+func buildBatchLatestResult(codes []string, latest map[string]model.DailyData) BatchLatestBarsResult {
+	result := BatchLatestBarsResult{
+		Items:   make([]model.DailyData, 0, len(codes)),
+		Missing: make([]string, 0),
+	}
+	for _, code := range codes {
+		if data, ok := latest[code]; ok {
+			result.Items = append(result.Items, data)
+		} else {
+			result.Missing = append(result.Missing, code)
+		}
+	}
+	return result
+}
+
+// GetBatchLatestBars 批量获取多只股票最新的日K线数据
+func (h *Handler) GetBatchLatestBars(c *gin.Context) {
+	codes, code, msg := parseBatchCodes(c.Query("codes"))
+	if code != CodeSuccess {
+		Error(c, code, msg)
+		return
+	}
+
+	h.logger.Infof("API: Getting latest daily bars for %d stocks", len(codes))
+
+	latest, err := repository.NewDailyData(h.db).GetLatestDailyDataBatch(codes)
+	if err != nil {
+		h.logger.Errorf("Failed to get latest daily data: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+
+	Success(c, buildBatchLatestResult(codes, latest))
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBatchCodes(t *testing.T) {
+	codes, code, _ := parseBatchCodes(" 000001.sz,600036.SH,,000001.SZ ")
+	require.Equal(t, CodeSuccess, code)
+	assert.Equal(t, []string{"000001.SZ", "600036.SH"}, codes)
+
+	_, code, _ = parseBatchCodes("")
+	assert.Equal(t, CodeEmptyTsCode, code)
+
+	_, code, msg := parseBatchCodes("000001.SZ,600036")
+	assert.Equal(t, CodeInvalidTsCode, code)
+	assert.Contains(t, msg, "600036")
+
+	many := make([]string, maxBatchLatestCodes+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("%06d.SZ", i+1)
+	}
+	_, code, _ = parseBatchCodes(strings.Join(many, ","))
+	assert.Equal(t, CodeInvalidParam, code)
+}
+
+func TestBuildBatchLatestResult(t *testing.T) {
+	latest := map[string]model.DailyData{
+		"600036.SH": {TsCode: "600036.SH", TradeDate: 20250630, Close: 45.1},
+		"000001.SZ": {TsCode: "000001.SZ", TradeDate: 20250630, Close: 12.3},
+	}
+
+	result := buildBatchLatestResult([]string{"000001.SZ", "600036.SH"}, latest)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "000001.SZ", result.Items[0].TsCode)
+	assert.Equal(t, "600036.SH", result.Items[1].TsCode)
+	assert.Empty(t, result.Missing)
+
+	// 没有数据的股票放入missing
+	result = buildBatchLatestResult([]string{"000001.SZ", "920001.BJ"}, latest)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "000001.SZ", result.Items[0].TsCode)
+	assert.Equal(t, []string{"920001.BJ"}, result.Missing)
+}
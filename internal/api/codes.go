@@ -0,0 +1,58 @@
+package api
+
+import "net/http"
+
+// 业务错误码，统一放在响应体的code字段中返回，0表示成功
+const (
+	CodeSuccess               = 0    // 成功
+	CodeInvalidParam          = 1001 // 请求参数错误（缺少参数、格式错误、取值越界等）
+	CodeEmptyTsCode           = 1002 // 股票代码不能为空
+	CodeInvalidTsCode         = 1003 // 股票代码格式错误，应为：000001.SZ 或 600000.SH
+	CodeNotFound              = 1004 // 请求的资源不存在（股票、任务、报表等）
+	CodeInternalError         = 1005 // 服务内部错误（数据库查询、写入失败等）
+	CodeDataSourceUnavailable = 1006 // 数据源不可用（采集器未注册或未连接）
+	CodeDataSourceError       = 1007 // 数据源请求失败（上游接口返回错误、解析失败等）
+	CodeUnauthorized          = 1008 // 未授权（缺少或无效的访问令牌）
+)
+
+// codeMessages 错误码对应的默认描述
+var codeMessages = map[int]string{
+	CodeSuccess:               "success",
+	CodeInvalidParam:          "请求参数错误",
+	CodeEmptyTsCode:           "股票代码不能为空",
+	CodeInvalidTsCode:         "股票代码格式错误，应为：000001.SZ 或 600000.SH",
+	CodeNotFound:              "请求的资源不存在",
+	CodeInternalError:         "服务内部错误",
+	CodeDataSourceUnavailable: "数据源不可用",
+	CodeDataSourceError:       "数据源请求失败",
+	CodeUnauthorized:          "未授权的访问",
+}
+
+// codeHTTPStatus 错误码对应的HTTP状态码
+var codeHTTPStatus = map[int]int{
+	CodeSuccess:               http.StatusOK,
+	CodeInvalidParam:          http.StatusBadRequest,
+	CodeEmptyTsCode:           http.StatusBadRequest,
+	CodeInvalidTsCode:         http.StatusBadRequest,
+	CodeNotFound:              http.StatusNotFound,
+	CodeInternalError:         http.StatusInternalServerError,
+	CodeDataSourceUnavailable: http.StatusServiceUnavailable,
+	CodeDataSourceError:       http.StatusBadGateway,
+	CodeUnauthorized:          http.StatusUnauthorized,
+}
+
+// HTTPStatus 获取错误码对应的HTTP状态码，未登记的错误码按服务内部错误处理
+func HTTPStatus(code int) int {
+	if status, ok := codeHTTPStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeMessage 获取错误码对应的默认描述
+func CodeMessage(code int) string {
+	if msg, ok := codeMessages[code]; ok {
+		return msg
+	}
+	return codeMessages[CodeInternalError]
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// performRequest 构造请求并执行处理函数，返回HTTP状态码和解析后的响应体
+func performRequest(t *testing.T, handler gin.HandlerFunc, method, target, body string, params gin.Params) (int, Response) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+
+	handler(c)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := map[int]int{
+		CodeSuccess:               http.StatusOK,
+		CodeInvalidParam:          http.StatusBadRequest,
+		CodeEmptyTsCode:           http.StatusBadRequest,
+		CodeInvalidTsCode:         http.StatusBadRequest,
+		CodeNotFound:              http.StatusNotFound,
+		CodeInternalError:         http.StatusInternalServerError,
+		CodeDataSourceUnavailable: http.StatusServiceUnavailable,
+		CodeDataSourceError:       http.StatusBadGateway,
+		9999:                      http.StatusInternalServerError, // 未登记的错误码
+	}
+
+	for code, status := range cases {
+		assert.Equal(t, status, HTTPStatus(code), "code=%d", code)
+	}
+}
+
+func TestError_DefaultMessage(t *testing.T) {
+	status, resp := performRequest(t, func(c *gin.Context) {
+		Error(c, CodeNotFound, "")
+	}, http.MethodGet, "/", "", nil)
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, CodeNotFound, resp.Code)
+	assert.Equal(t, CodeMessage(CodeNotFound), resp.Message)
+	assert.Nil(t, resp.Data)
+}
+
+func TestHandler_ErrorCodes(t *testing.T) {
+	h := &Handler{logger: logrus.New()}
+	ph := NewPerformanceHandler(nil)
+	sh := NewShareholderHandler(nil)
+	nh := NewNotificationHandler(nil)
+
+	cases := []struct {
+		name    string
+		handler gin.HandlerFunc
+		method  string
+		target  string
+		body    string
+		params  gin.Params
+		code    int
+	}{
+		{"股票详情-代码为空", h.GetStockDetail, http.MethodGet, "/stocks/", "", nil, CodeEmptyTsCode},
+		{"股票详情-代码格式错误", h.GetStockDetail, http.MethodGet, "/stocks/000001", "", gin.Params{{Key: "code", Value: "000001"}}, CodeInvalidTsCode},
+		{"K线-代码为空", h.GetKLineData, http.MethodGet, "/stocks//kline", "", nil, CodeEmptyTsCode},
+		{"K线-代码格式错误", h.GetKLineData, http.MethodGet, "/stocks/abc/kline", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"K线-开始日期格式错误", h.GetKLineData, http.MethodGet, "/stocks/000001.SZ/kline?start=2020/01/01", "", gin.Params{{Key: "code", Value: "000001.SZ"}}, CodeInvalidParam},
+		{"K线-开始日期晚于结束日期", h.GetKLineData, http.MethodGet, "/stocks/000001.SZ/kline?start=2020-06-30&end=2020-01-01", "", gin.Params{{Key: "code", Value: "000001.SZ"}}, CodeInvalidParam},
+		{"刷新K线-代码格式错误", h.RefreshKLineData, http.MethodPost, "/stocks/abc/refresh", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"K线范围-代码为空", h.GetKLineDataRange, http.MethodGet, "/stocks//range", "", nil, CodeEmptyTsCode},
+		{"数据新鲜度-代码格式错误", h.CheckKLineDataFreshness, http.MethodGet, "/stocks/abc/freshness", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"价格异常-代码格式错误", h.DetectPriceAnomalies, http.MethodGet, "/stocks/abc/kline/anomalies", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"涨跌停-代码格式错误", h.GetLimitDays, http.MethodGet, "/stocks/abc/limit-days", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"涨跌停-日期格式错误", h.GetLimitDays, http.MethodGet, "/stocks/600519.SH/limit-days?start=2024/01/01", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"全市场涨停-日期格式错误", h.GetMarketLimitUp, http.MethodGet, "/market/limit-up?date=2024/01/02", "", nil, CodeInvalidParam},
+		{"导出数据包-代码格式错误", h.ExportStockData, http.MethodGet, "/stocks/abc/export.json", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"导出数据包-区间参数错误", h.ExportStockData, http.MethodGet, "/stocks/600519.SH/export.json?range=1w", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"排除区间-代码格式错误", h.ListDataExclusions, http.MethodGet, "/data-exclusions?code=abc", "", nil, CodeInvalidTsCode},
+		{"创建排除区间-请求体错误", h.CreateDataExclusion, http.MethodPost, "/data-exclusions", "{", nil, CodeInvalidParam},
+		{"创建排除区间-代码为空", h.CreateDataExclusion, http.MethodPost, "/data-exclusions", `{"start_date":20240102,"end_date":20240110}`, nil, CodeEmptyTsCode},
+		{"创建排除区间-日期倒置", h.CreateDataExclusion, http.MethodPost, "/data-exclusions", `{"ts_code":"600519.SH","start_date":20240110,"end_date":20240102}`, nil, CodeInvalidParam},
+		{"创建排除区间-周期错误", h.CreateDataExclusion, http.MethodPost, "/data-exclusions", `{"ts_code":"600519.SH","period":"hourly","start_date":20240102,"end_date":20240110}`, nil, CodeInvalidParam},
+		{"更新排除区间-ID错误", h.UpdateDataExclusion, http.MethodPut, "/data-exclusions/abc", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"删除排除区间-ID为0", h.DeleteDataExclusion, http.MethodDelete, "/data-exclusions/0", "", gin.Params{{Key: "id", Value: "0"}}, CodeInvalidParam},
+		{"北向持股-代码为空", h.GetNorthbound, http.MethodGet, "/stocks//northbound", "", nil, CodeEmptyTsCode},
+		{"北向持股-代码格式错误", h.GetNorthbound, http.MethodGet, "/stocks/abc/northbound", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"北向持股-日期格式错误", h.GetNorthbound, http.MethodGet, "/stocks/600519.SH/northbound?end=2024/06/01", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"全市场信号-类型错误", h.GetRecentSignals, http.MethodGet, "/signals/recent?type=golden_cross,unknown", "", nil, CodeInvalidParam},
+		{"全市场信号-数量错误", h.GetRecentSignals, http.MethodGet, "/signals/recent?limit=0", "", nil, CodeInvalidParam},
+		{"全市场信号-日期格式错误", h.GetRecentSignals, http.MethodGet, "/signals/recent?date=2025/09/30", "", nil, CodeInvalidParam},
+		{"换手率振幅-代码为空", h.GetDailyMetrics, http.MethodGet, "/stocks//kline/metrics", "", nil, CodeEmptyTsCode},
+		{"换手率振幅-日期格式错误", h.GetDailyMetrics, http.MethodGet, "/stocks/600519.SH/kline/metrics?start=2024/01/01", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"任务运行记录-数量错误", h.GetJobRuns, http.MethodGet, "/admin/job-runs?job=weekly_kline&limit=5000", "", nil, CodeInvalidParam},
+		{"任务运行记录-数量非数字", h.GetJobRuns, http.MethodGet, "/admin/job-runs?limit=abc", "", nil, CodeInvalidParam},
+		{"业绩披露-日期格式错误", h.GetEarningsAnnouncements, http.MethodGet, "/earnings?from=2025/07/01", "", nil, CodeInvalidParam},
+		{"业绩披露-起始晚于结束", h.GetEarningsAnnouncements, http.MethodGet, "/earnings?from=20250801&to=20250701", "", nil, CodeInvalidParam},
+		{"业绩披露-区间过长", h.GetEarningsAnnouncements, http.MethodGet, "/earnings?from=20230101&to=20250701", "", nil, CodeInvalidParam},
+		{"业绩披露-阈值错误", h.GetEarningsAnnouncements, http.MethodGet, "/earnings?surprise_threshold=-5", "", nil, CodeInvalidParam},
+		{"审计记录-来源错误", h.GetAuditLogs, http.MethodGet, "/admin/audit-logs?source=web", "", nil, CodeInvalidParam},
+		{"审计记录-日期格式错误", h.GetAuditLogs, http.MethodGet, "/admin/audit-logs?start_date=2025/10/01", "", nil, CodeInvalidParam},
+		{"审计记录-数量错误", h.GetAuditLogs, http.MethodGet, "/admin/audit-logs?limit=0", "", nil, CodeInvalidParam},
+		{"指标分布-指标为空", h.GetMetricDistribution, http.MethodGet, "/market/distribution", "", nil, CodeInvalidParam},
+		{"指标分布-指标不支持", h.GetMetricDistribution, http.MethodGet, "/market/distribution?metric=pe", "", nil, CodeInvalidParam},
+		{"指标百分位-代码为空", h.GetStockPercentile, http.MethodGet, "/stocks//percentile?metric=roe", "", nil, CodeEmptyTsCode},
+		{"指标百分位-代码格式错误", h.GetStockPercentile, http.MethodGet, "/stocks/600519/percentile?metric=roe", "", gin.Params{{Key: "code", Value: "600519"}}, CodeInvalidTsCode},
+		{"指标百分位-指标不支持", h.GetStockPercentile, http.MethodGet, "/stocks/600519.SH/percentile?metric=pe", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"刷新基本面-代码为空", h.RefreshFundamentals, http.MethodPost, "/stocks//fundamentals/refresh", "", nil, CodeEmptyTsCode},
+		{"刷新基本面-代码格式错误", h.RefreshFundamentals, http.MethodPost, "/stocks/600519/fundamentals/refresh", "", gin.Params{{Key: "code", Value: "600519"}}, CodeInvalidTsCode},
+		{"刷新基本面-数据源不可用", h.RefreshFundamentals, http.MethodPost, "/stocks/600519.SH/fundamentals/refresh", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeDataSourceUnavailable},
+		{"连涨连跌-代码为空", h.GetPriceAction, http.MethodGet, "/analysis/price-action/", "", nil, CodeEmptyTsCode},
+		{"连涨连跌-代码格式错误", h.GetPriceAction, http.MethodGet, "/analysis/price-action/abc", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"连涨连跌-K线数量错误", h.GetPriceAction, http.MethodGet, "/analysis/price-action/600519.SH?bars=1", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"设置优先同步-请求体错误", h.SetStockPriority, http.MethodPut, "/admin/stocks/priority", "{", nil, CodeInvalidParam},
+		{"设置优先同步-缺少priority", h.SetStockPriority, http.MethodPut, "/admin/stocks/priority", `{"ts_codes":["600519.SH"]}`, nil, CodeInvalidParam},
+		{"设置优先同步-代码为空", h.SetStockPriority, http.MethodPut, "/admin/stocks/priority", `{"ts_codes":[],"priority":true}`, nil, CodeEmptyTsCode},
+		{"设置优先同步-代码格式错误", h.SetStockPriority, http.MethodPut, "/admin/stocks/priority", `{"ts_codes":["600519.SH","600000"],"priority":true}`, nil, CodeInvalidTsCode},
+		{"实时数据-来源参数错误", h.GetRealtimeData, http.MethodGet, "/realtime?codes=600519.SH&source=cache", "", nil, CodeInvalidParam},
+		{"相关性-代码为空", h.GetReturnCorrelation, http.MethodGet, "/correlation?b=600036.SH", "", nil, CodeEmptyTsCode},
+		{"相关性-代码格式错误", h.GetReturnCorrelation, http.MethodGet, "/correlation?a=000001.SZ&b=600036", "", nil, CodeInvalidTsCode},
+		{"相关性-窗口错误", h.GetReturnCorrelation, http.MethodGet, "/correlation?a=000001.SZ&b=600036.SH&window=1", "", nil, CodeInvalidParam},
+		{"实时数据-代码为空", h.GetRealtimeData, http.MethodGet, "/realtime", "", nil, CodeEmptyTsCode},
+		{"实时数据-无有效代码", h.GetRealtimeData, http.MethodGet, "/realtime?codes=abc,def", "", nil, CodeInvalidTsCode},
+		{"批量实时数据-参数错误", h.GetBatchRealtimeData, http.MethodPost, "/realtime/batch", "{", nil, CodeInvalidParam},
+		{"批量实时数据-代码为空", h.GetBatchRealtimeData, http.MethodPost, "/realtime/batch", `{"codes":[]}`, nil, CodeEmptyTsCode},
+		{"批量实时数据-无有效代码", h.GetBatchRealtimeData, http.MethodPost, "/realtime/batch", `{"codes":["abc"]}`, nil, CodeInvalidTsCode},
+		{"任务状态-ID为空", h.GetTaskStatus, http.MethodGet, "/tasks/", "", nil, CodeInvalidParam},
+		{"取消任务-ID为空", h.CancelTask, http.MethodPost, "/tasks//cancel", "", nil, CodeInvalidParam},
+		{"单股同步-代码格式错误", h.SyncSingleStockAsync, http.MethodPost, "/stocks/abc/sync", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"相对收益-代码格式错误", h.GetRelativeReturns, http.MethodGet, "/stocks/abc/relative-returns", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"相对收益-基准格式错误", h.GetRelativeReturns, http.MethodGet, "/stocks/600519.SH/relative-returns?benchmark=000300", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"相对收益-窗口参数错误", h.GetRelativeReturns, http.MethodGet, "/stocks/600519.SH/relative-returns?windows=5,abc", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"均线交叉-代码格式错误", h.GetMACrossovers, http.MethodGet, "/stocks/abc/crossovers", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"均线交叉-周期参数错误", h.GetMACrossovers, http.MethodGet, "/stocks/600519.SH/crossovers?fast=0", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"均线交叉-快线不小于慢线", h.GetMACrossovers, http.MethodGet, "/stocks/600519.SH/crossovers?fast=20&slow=10", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"均线交叉-日期格式错误", h.GetMACrossovers, http.MethodGet, "/stocks/600519.SH/crossovers?start=2024/01/01", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"指数K线-代码为空", h.GetIndexKLine, http.MethodGet, "/index//kline", "", nil, CodeEmptyTsCode},
+		{"指数K线-缺少交易所后缀", h.GetIndexKLine, http.MethodGet, "/index/000001/kline", "", gin.Params{{Key: "code", Value: "000001"}}, CodeInvalidTsCode},
+		{"基本面选股-阈值格式错误", h.ScreenFundamental, http.MethodGet, "/screener/fundamental?min_eps=abc", "", nil, CodeInvalidParam},
+		{"基本面选股-排序字段错误", h.ScreenFundamental, http.MethodGet, "/screener/fundamental?min_roe=10&sort_by=pe", "", nil, CodeInvalidParam},
+		{"基本面选股-市盈率需使用估值选股", h.ScreenFundamental, http.MethodGet, "/screener/fundamental?max_pe=20", "", nil, CodeInvalidParam},
+		{"估值选股-阈值格式错误", h.ScreenValuation, http.MethodGet, "/screener/valuation?max_pe=abc", "", nil, CodeInvalidParam},
+		{"估值选股-排序字段错误", h.ScreenValuation, http.MethodGet, "/screener/valuation?sort_by=roe", "", nil, CodeInvalidParam},
+		{"估值选股-排序方向错误", h.ScreenValuation, http.MethodGet, "/screener/valuation?order=up", "", nil, CodeInvalidParam},
+		{"估值选股-数量错误", h.ScreenValuation, http.MethodGet, "/screener/valuation?limit=501", "", nil, CodeInvalidParam},
+		{"个股估值-代码为空", h.GetStockValuation, http.MethodGet, "/stocks//valuation", "", nil, CodeEmptyTsCode},
+		{"个股估值-代码格式错误", h.GetStockValuation, http.MethodGet, "/stocks/abc/valuation", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"基本面选股-数量错误", h.ScreenFundamental, http.MethodGet, "/screener/fundamental?limit=0", "", nil, CodeInvalidParam},
+		{"复杂指标信号-代码为空", h.GetComplexSignals, http.MethodGet, "/analysis/signals/", "", nil, CodeEmptyTsCode},
+		{"复杂指标信号-代码格式错误", h.GetComplexSignals, http.MethodGet, "/analysis/signals/abc", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"复杂指标信号-K线数量过少", h.GetComplexSignals, http.MethodGet, "/analysis/signals/600519.SH?bars=20", "", gin.Params{{Key: "code", Value: "600519.SH"}}, CodeInvalidParam},
+		{"综合评分-代码为空", h.GetStockScore, http.MethodGet, "/stocks//score", "", nil, CodeEmptyTsCode},
+		{"综合评分-代码格式错误", h.GetStockScore, http.MethodGet, "/stocks/abc/score", "", gin.Params{{Key: "code", Value: "abc"}}, CodeInvalidTsCode},
+		{"综合评分排名-limit过大", h.GetScoreRanking, http.MethodGet, "/analysis/scores/ranking?limit=1000", "", nil, CodeInvalidParam},
+		{"综合评分排名-limit非法", h.GetScoreRanking, http.MethodGet, "/analysis/scores/ranking?limit=abc", "", nil, CodeInvalidParam},
+		{"创建自选股-请求体错误", h.CreateWatchlist, http.MethodPost, "/watchlists", "{", nil, CodeInvalidParam},
+		{"创建自选股-名称为空", h.CreateWatchlist, http.MethodPost, "/watchlists", `{"codes":["000001.SZ"]}`, nil, CodeInvalidParam},
+		{"创建自选股-代码为空", h.CreateWatchlist, http.MethodPost, "/watchlists", `{"name":"核心持仓","codes":[" "]}`, nil, CodeEmptyTsCode},
+		{"创建自选股-代码格式错误", h.CreateWatchlist, http.MethodPost, "/watchlists", `{"name":"核心持仓","codes":["000001"]}`, nil, CodeInvalidTsCode},
+		{"身份变更记录-代码格式错误", h.GetStockIdentityChanges, http.MethodGet, "/stocks/identity-changes?code=abc", "", nil, CodeInvalidTsCode},
+		{"身份变更记录-limit非法", h.GetStockIdentityChanges, http.MethodGet, "/stocks/identity-changes?limit=0", "", nil, CodeInvalidParam},
+		{"批量最新K线-代码为空", h.GetBatchLatestBars, http.MethodGet, "/stocks/batch", "", nil, CodeEmptyTsCode},
+		{"批量最新K线-代码格式错误", h.GetBatchLatestBars, http.MethodGet, "/stocks/batch?codes=000001.SZ,abc", "", nil, CodeInvalidTsCode},
+		{"选股结果解释-ID错误", h.ExplainSelectionResult, http.MethodGet, "/screener/results/abc/explain", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"选股结果解释-ID为0", h.ExplainSelectionResult, http.MethodGet, "/screener/results/0/explain", "", gin.Params{{Key: "id", Value: "0"}}, CodeInvalidParam},
+		{"同步自选股-ID错误", h.SyncWatchlist, http.MethodPost, "/watchlists/abc/sync", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"创建股票分组-请求体错误", h.CreateStockGroup, http.MethodPost, "/groups", "{", nil, CodeInvalidParam},
+		{"创建股票分组-名称为空", h.CreateStockGroup, http.MethodPost, "/groups", `{"codes":["000001.SZ"]}`, nil, CodeInvalidParam},
+		{"创建股票分组-类型错误", h.CreateStockGroup, http.MethodPost, "/groups", `{"name":"银行","kind":"fund","codes":["000001.SZ"]}`, nil, CodeInvalidParam},
+		{"创建股票分组-指数代码错误", h.CreateStockGroup, http.MethodPost, "/groups", `{"name":"沪深300","kind":"index","source":"000300"}`, nil, CodeInvalidParam},
+		{"创建股票分组-代码为空", h.CreateStockGroup, http.MethodPost, "/groups", `{"name":"银行","kind":"sector","codes":[" "]}`, nil, CodeEmptyTsCode},
+		{"创建股票分组-代码格式错误", h.CreateStockGroup, http.MethodPost, "/groups", `{"name":"银行","codes":["000001"]}`, nil, CodeInvalidTsCode},
+		{"股票分组列表-类型错误", h.GetStockGroups, http.MethodGet, "/groups?kind=fund", "", nil, CodeInvalidParam},
+		{"股票分组-ID错误", h.GetStockGroup, http.MethodGet, "/groups/abc", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"删除股票分组-ID错误", h.DeleteStockGroup, http.MethodDelete, "/groups/0", "", gin.Params{{Key: "id", Value: "0"}}, CodeInvalidParam},
+		{"同步股票分组-ID错误", h.SyncStockGroup, http.MethodPost, "/groups/abc/sync", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"分组选股-排序字段错误", h.ScreenStockGroup, http.MethodGet, "/groups/1/screen?sort_by=pe", "", gin.Params{{Key: "id", Value: "1"}}, CodeInvalidParam},
+		{"导出股票分组-ID错误", h.ExportStockGroup, http.MethodGet, "/groups/abc/export", "", gin.Params{{Key: "id", Value: "abc"}}, CodeInvalidParam},
+		{"业绩报表-代码为空", ph.GetPerformanceReports, http.MethodGet, "/performance/", "", nil, CodeEmptyTsCode},
+		{"业绩报表范围-日期为空", ph.GetPerformanceReportsByDateRange, http.MethodGet, "/performance/000001.SZ/range", "", gin.Params{{Key: "code", Value: "000001.SZ"}}, CodeInvalidParam},
+		{"业绩报表范围-日期格式错误", ph.GetPerformanceReportsByDateRange, http.MethodGet, "/performance/000001.SZ/range?start_date=2025&end_date=2025-01-01", "", gin.Params{{Key: "code", Value: "000001.SZ"}}, CodeInvalidParam},
+		{"业绩排行-排序字段不支持", ph.GetTopPerformers, http.MethodGet, "/performance/top-performers?order_by=roa", "", nil, CodeInvalidParam},
+		{"业绩排行-排序方向错误", ph.GetTopPerformers, http.MethodGet, "/performance/top-performers?order=up", "", nil, CodeInvalidParam},
+		{"创建业绩报表-参数错误", ph.CreatePerformanceReport, http.MethodPost, "/performance", "{", nil, CodeInvalidParam},
+		{"股东户数-代码为空", sh.GetShareholderCounts, http.MethodGet, "/shareholder/", "", nil, CodeEmptyTsCode},
+		{"股东户数范围-日期为空", sh.GetShareholderCountsByDateRange, http.MethodGet, "/shareholder/000001.SZ/range", "", gin.Params{{Key: "ts_code", Value: "000001.SZ"}}, CodeInvalidParam},
+		{"股东户数排行-排序方向错误", sh.GetTopByHolderNum, http.MethodGet, "/shareholder/top/holder-num?order=up", "", nil, CodeInvalidParam},
+		{"户均市值排行-排序方向错误", sh.GetTopByAvgMarketCap, http.MethodGet, "/shareholder/top/avg-market-cap?order=ascending", "", nil, CodeInvalidParam},
+		{"股东户数分页-日期为空", sh.GetShareholderCountsWithPagination, http.MethodGet, "/shareholder/list", "", nil, CodeInvalidParam},
+		{"股东户数分页-日期格式错误", sh.GetShareholderCountsWithPagination, http.MethodGet, "/shareholder/list?start_date=20250101&end_date=2025-06-30", "", nil, CodeInvalidParam},
+		{"发送文本消息-参数错误", nh.SendTextMessage, http.MethodPost, "/notification/text", "{}", nil, CodeInvalidParam},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, resp := performRequest(t, tc.handler, tc.method, tc.target, tc.body, tc.params)
+
+			assert.Equal(t, tc.code, resp.Code)
+			assert.Equal(t, HTTPStatus(tc.code), status)
+			assert.NotEmpty(t, resp.Message)
+			assert.Nil(t, resp.Data)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"strings"
+
+	"stock/internal/collector"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lookupSessionCollector 按名称查找支持会话管理的采集器，不存在或不支持时写入错误响应并返回false
+func (h *Handler) lookupSessionCollector(c *gin.Context) (string, collector.SessionCollector, bool) {
+	name := c.Param("name")
+	if name == "" {
+		Error(c, CodeInvalidParam, "采集器名称不能为空")
+		return "", nil, false
+	}
+
+	dataCollector, ok := h.collectorManager.LookupCollector(name)
+	if !ok {
+		Error(c, CodeNotFound, "采集器不存在: "+name)
+		return "", nil, false
+	}
+	sessionCollector, ok := dataCollector.(collector.SessionCollector)
+	if !ok {
+		Error(c, CodeInvalidParam, "采集器不使用浏览器会话: "+name)
+		return "", nil, false
+	}
+	return name, sessionCollector, true
+}
+
+// collectorSessionView 采集器当前会话的响应，Cookie只返回各项名称和值的前几位
+func collectorSessionView(name string, session collector.SessionCollector) gin.H {
+	cookie := session.GetCurrentCookie()
+	return gin.H{
+		"name":          name,
+		"user_agent":    session.GetCurrentUserAgent(),
+		"cookie":        maskCookie(cookie),
+		"cookie_length": len(cookie),
+	}
+}
+
+// maskCookie 遮盖Cookie中每一项的值，只保留名称和值的前2位，便于确认有哪些Cookie而不泄露会话标识
+func maskCookie(cookie string) string {
+	parts := strings.Split(cookie, ";")
+	masked := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			masked = append(masked, "***")
+			continue
+		}
+		if len(value) > 2 {
+			value = value[:2]
+		} else {
+			value = ""
+		}
+		masked = append(masked, key+"="+value+"***")
+	}
+	return strings.Join(masked, "; ")
+}
+
+// GetCollectorSession 查看采集器当前使用的User-Agent和遮盖后的Cookie，用于排查上游反爬问题
+func (h *Handler) GetCollectorSession(c *gin.Context) {
+	name, session, ok := h.lookupSessionCollector(c)
+	if !ok {
+		return
+	}
+
+	h.logger.Infof("API: Getting session of collector %s", name)
+	Success(c, collectorSessionView(name, session))
+}
+
+// RotateCollectorSession 强制采集器更换User-Agent和Cookie，上游开始返回反爬验证页时无需重启服务即可重置会话
+// 配置了自定义Cookie（collectors.<name>.cookie）时请求仍使用配置的Cookie
+func (h *Handler) RotateCollectorSession(c *gin.Context) {
+	name, session, ok := h.lookupSessionCollector(c)
+	if !ok {
+		return
+	}
+
+	session.ForceUpdateUserAgentAndCookie()
+	h.logger.Infof("API: Rotated session of collector %s", name)
+	Success(c, collectorSessionView(name, session))
+}
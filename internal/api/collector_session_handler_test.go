@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionTestHandler(t *testing.T) (*Handler, *collector.TongHuaShunCollector) {
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	manager := collector.NewCollectorManager(log)
+	ths := collector.GetCollectorFactory(log).GetTongHuaShunCollector()
+	require.NoError(t, manager.RegisterCollector(ths))
+	return &Handler{collectorManager: manager, logger: logrus.New()}, ths
+}
+
+func TestRotateCollectorSession(t *testing.T) {
+	h, ths := newSessionTestHandler(t)
+	oldCookie := ths.GetCurrentCookie()
+	params := gin.Params{{Key: "name", Value: "tonghuashun"}}
+
+	status, resp := performRequest(t, h.RotateCollectorSession, http.MethodPost,
+		"/admin/collectors/tonghuashun/session/rotate", "", params)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, CodeSuccess, resp.Code)
+
+	// 会话已更换，响应返回新的User-Agent和遮盖后的Cookie
+	newCookie := ths.GetCurrentCookie()
+	assert.NotEqual(t, oldCookie, newCookie)
+
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "tonghuashun", data["name"])
+	assert.Equal(t, ths.GetCurrentUserAgent(), data["user_agent"])
+	assert.Equal(t, maskCookie(newCookie), data["cookie"])
+	assert.NotEqual(t, newCookie, data["cookie"])
+	assert.Equal(t, float64(len(newCookie)), data["cookie_length"])
+}
+
+func TestCollectorSession_Errors(t *testing.T) {
+	h, _ := newSessionTestHandler(t)
+
+	status, resp := performRequest(t, h.GetCollectorSession, http.MethodGet,
+		"/admin/collectors/unknown/session", "", gin.Params{{Key: "name", Value: "unknown"}})
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, CodeNotFound, resp.Code)
+
+	status, resp = performRequest(t, h.RotateCollectorSession, http.MethodPost,
+		"/admin/collectors//session/rotate", "", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, CodeInvalidParam, resp.Code)
+}
+
+func TestMaskCookie(t *testing.T) {
+	assert.Equal(t, "qgqp_b_id=70***; st_si=***; ***",
+		maskCookie("qgqp_b_id=70e1191db491f7e8; st_si=1; flag"))
+	assert.Equal(t, "", maskCookie(""))
+}
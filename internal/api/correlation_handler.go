@@ -0,0 +1,82 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCorrelationWindow 收益率相关性的默认统计窗口（交易日）
+const defaultCorrelationWindow = 120
+
+// parseCorrelationCode 解析并校验相关性接口的股票代码参数，失败时已写出错误响应
+func parseCorrelationCode(c *gin.Context, name string) (string, bool) {
+	tsCode := strings.ToUpper(strings.TrimSpace(c.Query(name)))
+	if tsCode == "" {
+		Error(c, CodeEmptyTsCode, name+"参数股票代码不能为空")
+		return "", false
+	}
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, name+"参数股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return "", false
+	}
+	return tsCode, true
+}
+
+// GetReturnCorrelation 计算两只股票最近window个交易日日收益率的皮尔逊相关系数，只使用两只股票都有数据的交易日
+func (h *Handler) GetReturnCorrelation(c *gin.Context) {
+	codeA, ok := parseCorrelationCode(c, "a")
+	if !ok {
+		return
+	}
+	codeB, ok := parseCorrelationCode(c, "b")
+	if !ok {
+		return
+	}
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(defaultCorrelationWindow)))
+	if err != nil || window < 2 || window > maxReturnWindow {
+		Error(c, CodeInvalidParam, "window参数错误，应为2-1000之间的交易日数")
+		return
+	}
+
+	// 按约250个交易日/年换算自然日，并预留节假日和停牌的余量
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -(window*3/2 + 30))
+
+	h.logger.Infof("API: Getting return correlation between %s and %s, window: %d", codeA, codeB, window)
+
+	dataA, err := h.klineService.GetKLineData(codeA, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get K-line data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	dataB, err := h.klineService.GetKLineData(codeB, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get K-line data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(dataA) == 0 || len(dataB) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	result, ok := indicator.CorrelateReturns(dataA, dataB, window)
+	if !ok {
+		Error(c, CodeNotFound, "两只股票的共同交易日不足或收益率没有波动，无法计算相关系数")
+		return
+	}
+
+	Success(c, gin.H{
+		"a":           codeA,
+		"b":           codeB,
+		"window":      window,
+		"correlation": result,
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 均线交叉查询的默认参数
+const (
+	defaultCrossoverFast = 5   // 默认快线周期
+	defaultCrossoverSlow = 20  // 默认慢线周期
+	maxCrossoverPeriod   = 250 // 均线周期上限
+)
+
+// parseCrossoverPeriod 解析均线周期，为空时使用默认值
+func parseCrossoverPeriod(param string, defaultPeriod int) (int, bool) {
+	if param == "" {
+		return defaultPeriod, true
+	}
+	period, err := strconv.Atoi(param)
+	if err != nil || period < 1 || period > maxCrossoverPeriod {
+		return 0, false
+	}
+	return period, true
+}
+
+// GetMACrossovers 获取个股收盘价快慢均线的金叉、死叉记录
+// 时间范围参数与K线查询一致，默认最近一年；均线按范围起点之前的日K线预热，保证区间内第一天的均线完整
+func (h *Handler) GetMACrossovers(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	fast, ok := parseCrossoverPeriod(c.Query("fast"), defaultCrossoverFast)
+	if !ok {
+		Error(c, CodeInvalidParam, "fast参数错误，应为1-250之间的整数")
+		return
+	}
+	slow, ok := parseCrossoverPeriod(c.Query("slow"), defaultCrossoverSlow)
+	if !ok {
+		Error(c, CodeInvalidParam, "slow参数错误，应为1-250之间的整数")
+		return
+	}
+	if fast >= slow {
+		Error(c, CodeInvalidParam, "fast参数必须小于slow参数")
+		return
+	}
+
+	startDate, endDate, _, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.DefaultQuery("days", "365"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting MA%d/MA%d crossovers for %s (%s ~ %s)", fast, slow, tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+
+	// 按约250个交易日/年换算自然日，并预留节假日和停牌的余量
+	warmupStart := startDate.AddDate(0, 0, -(slow*3/2 + 30))
+	klineData, err := h.klineService.GetKLineData(tsCode, warmupStart, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get K-line data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(klineData) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	since := startDate.Year()*10000 + int(startDate.Month())*100 + startDate.Day()
+	crossovers := make([]indicator.Crossover, 0)
+	for _, crossover := range indicator.MACrossovers(klineData, fast, slow) {
+		if crossover.TradeDate >= since {
+			crossovers = append(crossovers, crossover)
+		}
+	}
+
+	Success(c, gin.H{
+		"code":       tsCode,
+		"fast":       fast,
+		"slow":       slow,
+		"start":      startDate.Format("2006-01-02"),
+		"end":        endDate.Format("2006-01-02"),
+		"count":      len(crossovers),
+		"crossovers": crossovers,
+	})
+}
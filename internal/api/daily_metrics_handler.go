@@ -0,0 +1,104 @@
+package api
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDailyMetrics 获取股票日期区间内每个交易日的换手率和振幅，按交易日期升序
+// 日期区间参数与K线查询接口一致；换手率按每个交易日当时的流通股本计算，没有股本变动记录时使用股票信息中的流通股本，流通股本未知时为0，
+// 振幅以前一交易日收盘价为基准，区间首日使用区间之前最后一根K线的收盘价
+func (h *Handler) GetDailyMetrics(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	startDate, endDate, _, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.Query("days"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting daily metrics for %s (%s ~ %s)", tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+
+	klineData, err := h.klineService.GetKLineData(tsCode, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get K-line data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+
+	var floatShares int64
+	stock, err := repository.NewStock(h.db).GetStockByTsCode(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get stock %s: %v", tsCode, err)
+		Error(c, CodeInternalError, "获取股票信息失败")
+		return
+	}
+	if stock != nil {
+		floatShares = stock.FloatShares
+	}
+
+	shares, err := repository.NewStockShares(h.db).GetHistory(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get shares history of %s: %v", tsCode, err)
+		Error(c, CodeInternalError, "获取股本变动记录失败")
+		return
+	}
+
+	start := startDate.Year()*10000 + int(startDate.Month())*100 + startDate.Day()
+	prevBars, err := repository.NewDailyData(h.db).GetPrevDailyDataBatch([]string{tsCode}, start)
+	if err != nil {
+		h.logger.Errorf("Failed to get previous daily data: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	var prev *model.DailyData
+	if bar, ok := prevBars[tsCode]; ok {
+		prev = &bar
+	}
+
+	metrics := model.ComputeDailyMetricsWithShares(klineData, prev, model.NewSharesTimeline(shares), floatShares)
+	Success(c, gin.H{
+		"code":          tsCode,
+		"start":         startDate.Format("2006-01-02"),
+		"end":           endDate.Format("2006-01-02"),
+		"float_shares":  floatShares,
+		"count":         len(metrics),
+		"avg_turnover":  averageMetric(metrics, func(m model.DailyMetric) float64 { return m.TurnoverRate }),
+		"avg_amplitude": averageMetric(metrics, func(m model.DailyMetric) float64 { return m.Amplitude }),
+		"metrics":       metrics,
+	})
+}
+
+// averageMetric 计算指标的平均值（保留2位小数），跳过为0（无法计算）的交易日，全部为0时返回0
+func averageMetric(metrics []model.DailyMetric, value func(m model.DailyMetric) float64) float64 {
+	var sum float64
+	var count int
+	for _, m := range metrics {
+		if v := value(m); v > 0 {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return math.Round(sum/float64(count)*100) / 100
+}
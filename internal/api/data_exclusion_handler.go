@@ -0,0 +1,154 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dataExclusionRequest 创建或更新数据排除区间的请求体
+type dataExclusionRequest struct {
+	TsCode    string `json:"ts_code"`    // 股票代码
+	Period    string `json:"period"`     // K线周期，缺省为daily
+	StartDate int    `json:"start_date"` // 起始交易日期（含），YYYYMMDD格式
+	EndDate   int    `json:"end_date"`   // 结束交易日期（含），YYYYMMDD格式
+	Reason    string `json:"reason"`     // 排除原因
+}
+
+// bindDataExclusion 解析并校验请求体，填充到exclusion中，失败时已写出错误响应
+func bindDataExclusion(c *gin.Context, exclusion *model.DataExclusion) bool {
+	var req dataExclusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, CodeInvalidParam, "请求参数错误")
+		return false
+	}
+
+	exclusion.TsCode = req.TsCode
+	exclusion.Period = model.TechnicalIndicatorPeriod(strings.ToLower(req.Period))
+	exclusion.StartDate = req.StartDate
+	exclusion.EndDate = req.EndDate
+	exclusion.Reason = strings.TrimSpace(req.Reason)
+	exclusion.Normalize()
+
+	if exclusion.TsCode == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return false
+	}
+	if !strings.Contains(exclusion.TsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return false
+	}
+	if err := exclusion.Validate(); err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return false
+	}
+	return true
+}
+
+// parseDataExclusionID 解析路径中的排除区间ID，失败时已写出错误响应
+func parseDataExclusionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		Error(c, CodeInvalidParam, "排除区间ID错误")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListDataExclusions 获取数据排除区间，可按code、period过滤
+func (h *Handler) ListDataExclusions(c *gin.Context) {
+	tsCode := strings.ToUpper(c.Query("code"))
+	if tsCode != "" && !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+	period := model.TechnicalIndicatorPeriod(strings.ToLower(c.Query("period")))
+
+	exclusions, err := repository.NewDataExclusion(h.db).List(tsCode, period)
+	if err != nil {
+		h.logger.Errorf("Failed to list data exclusions: %v", err)
+		Error(c, CodeInternalError, "获取排除区间失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count":      len(exclusions),
+		"exclusions": exclusions,
+	})
+}
+
+// CreateDataExclusion 标记问题数据区间，指标计算和回测加载数据时跳过该区间，数据本身不删除
+func (h *Handler) CreateDataExclusion(c *gin.Context) {
+	exclusion := &model.DataExclusion{}
+	if !bindDataExclusion(c, exclusion) {
+		return
+	}
+
+	if err := repository.NewDataExclusion(h.db).Create(exclusion); err != nil {
+		h.logger.Errorf("Failed to create data exclusion: %v", err)
+		Error(c, CodeInternalError, "创建排除区间失败")
+		return
+	}
+
+	h.logger.Infof("API: Created data exclusion %d for %s %s %d~%d: %s", exclusion.ID, exclusion.TsCode,
+		exclusion.Period, exclusion.StartDate, exclusion.EndDate, exclusion.Reason)
+	Success(c, exclusion)
+}
+
+// UpdateDataExclusion 更新数据排除区间
+func (h *Handler) UpdateDataExclusion(c *gin.Context) {
+	id, ok := parseDataExclusionID(c)
+	if !ok {
+		return
+	}
+
+	repo := repository.NewDataExclusion(h.db)
+	exclusion, err := repo.GetByID(id)
+	if err != nil {
+		h.logger.Errorf("Failed to get data exclusion: %v", err)
+		Error(c, CodeInternalError, "获取排除区间失败")
+		return
+	}
+	if exclusion == nil {
+		Error(c, CodeNotFound, "排除区间不存在")
+		return
+	}
+
+	if !bindDataExclusion(c, exclusion) {
+		return
+	}
+	if err := repo.Update(exclusion); err != nil {
+		h.logger.Errorf("Failed to update data exclusion: %v", err)
+		Error(c, CodeInternalError, "更新排除区间失败")
+		return
+	}
+
+	h.logger.Infof("API: Updated data exclusion %d", id)
+	Success(c, exclusion)
+}
+
+// DeleteDataExclusion 删除数据排除区间，被排除的数据重新参与计算
+func (h *Handler) DeleteDataExclusion(c *gin.Context) {
+	id, ok := parseDataExclusionID(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := repository.NewDataExclusion(h.db).Delete(id)
+	if err != nil {
+		h.logger.Errorf("Failed to delete data exclusion: %v", err)
+		Error(c, CodeInternalError, "删除排除区间失败")
+		return
+	}
+	if deleted == 0 {
+		Error(c, CodeNotFound, "排除区间不存在")
+		return
+	}
+
+	h.logger.Infof("API: Deleted data exclusion %d", id)
+	Success(c, gin.H{"id": id})
+}
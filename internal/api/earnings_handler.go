@@ -0,0 +1,79 @@
+package api
+
+import (
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/service"
+	"stock/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 业绩披露查询的默认区间天数和最大区间天数
+const (
+	defaultEarningsDays = 30
+	maxEarningsDays     = 366
+)
+
+// GetEarningsAnnouncements 获取公告日期在[from, to]之间披露业绩报表的股票，用于跟踪财报季
+// from、to为YYYYMMDD或YYYY-MM-DD格式，to默认为今天，from默认为to之前30天；
+// surprise_threshold为业绩大幅变动的阈值（单位：%，默认50），每股收益或营业收入同比变动幅度达到阈值的报表标记surprise
+func (h *Handler) GetEarningsAnnouncements(c *gin.Context) {
+	now := utils.AppNow()
+	to, err := parseTradeDate(c.Query("to"), now)
+	if err != nil {
+		Error(c, CodeInvalidParam, "to日期格式错误，应为YYYYMMDD或YYYY-MM-DD")
+		return
+	}
+	from := to.AddDate(0, 0, -defaultEarningsDays)
+	if value := c.Query("from"); value != "" {
+		if from, err = parseTradeDate(value, now); err != nil {
+			Error(c, CodeInvalidParam, "from日期格式错误，应为YYYYMMDD或YYYY-MM-DD")
+			return
+		}
+	}
+	if from.After(to) {
+		Error(c, CodeInvalidParam, "from不能晚于to")
+		return
+	}
+	if to.Sub(from) > maxEarningsDays*24*time.Hour {
+		Error(c, CodeInvalidParam, "查询区间不能超过366天")
+		return
+	}
+
+	threshold := model.DefaultEarningsSurpriseThreshold
+	v, ok := parseOptionalFloat(c, "surprise_threshold")
+	if !ok || (v != nil && *v <= 0) {
+		Error(c, CodeInvalidParam, "surprise_threshold参数错误，应为大于0的数字")
+		return
+	}
+	if v != nil {
+		threshold = *v
+	}
+
+	h.logger.Infof("API: Getting earnings announcements from %s to %s", from.Format("20060102"), to.Format("20060102"))
+
+	announcements, err := service.GetEarningsService(h.db).ListAnnouncements(from, to, threshold)
+	if err != nil {
+		h.logger.Errorf("Failed to get earnings announcements: %v", err)
+		Error(c, CodeInternalError, "获取业绩披露失败")
+		return
+	}
+
+	surprises := 0
+	for _, a := range announcements {
+		if a.Surprise {
+			surprises++
+		}
+	}
+
+	Success(c, gin.H{
+		"from":               utils.TradeDateOf(from),
+		"to":                 utils.TradeDateOf(to),
+		"surprise_threshold": threshold,
+		"count":              len(announcements),
+		"surprise_count":     surprises,
+		"stocks":             announcements,
+	})
+}
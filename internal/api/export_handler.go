@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportDailyBatchSize 导出数据包时每批从数据库读取并写出的日K线条数
+const exportDailyBatchSize = 1000
+
+// exportRangePattern 导出区间参数格式：数字加单位d（天）、m（月）、y（年），如30d、6m、1y
+var exportRangePattern = regexp.MustCompile(`^(\d+)([dmy])$`)
+
+// parseExportRange 解析导出区间参数，返回日K线的起始日期（YYYYMMDD格式），为空或all时返回0表示导出全部历史
+func parseExportRange(value string, now time.Time) (int, error) {
+	if value == "" || value == "all" {
+		return 0, nil
+	}
+
+	matches := exportRangePattern.FindStringSubmatch(strings.ToLower(value))
+	if matches == nil {
+		return 0, fmt.Errorf("range参数错误，应为all或数字加单位d/m/y，如1y")
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("range参数错误，应为all或数字加单位d/m/y，如1y")
+	}
+
+	var start time.Time
+	switch matches[2] {
+	case "d":
+		start = now.AddDate(0, 0, -n)
+	case "m":
+		start = now.AddDate(0, -n, 0)
+	default:
+		start = now.AddDate(-n, 0, 0)
+	}
+	return start.Year()*10000 + int(start.Month())*100 + start.Day(), nil
+}
+
+// stockExport 股票完整数据包的内容，日K线通过eachDaily分批读取
+type stockExport struct {
+	TsCode       string
+	Range        string
+	StartDate    int
+	ExportedAt   time.Time
+	Stock        *model.Stock
+	Performance  []model.PerformanceReport
+	Shareholders []*model.ShareholderCount
+	EachDaily    func(fn func([]model.DailyData) error) error
+}
+
+// writeStockExport 将数据包以JSON对象写出，结构为：
+//
+//	{
+//	  "ts_code": "600519.SH", "range": "1y", "start_date": 20230102, "exported_at": "...",
+//	  "stock": {...},           // 股票基础信息
+//	  "performance": [...],     // 业绩报表，按报告期降序
+//	  "shareholders": [...],    // 股东户数，按截止日期降序
+//	  "daily": [...],           // 日K线，按交易日期升序
+//	  "daily_count": 242        // 日K线条数
+//	}
+//
+// 日K线最后分批写出，每批写完后刷新，不在内存中缓存全部历史；写出过程中出错时数据包不完整
+func writeStockExport(w io.Writer, export stockExport) error {
+	flush := func() {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	header := []struct {
+		key   string
+		value interface{}
+	}{
+		{"ts_code", export.TsCode},
+		{"range", export.Range},
+		{"start_date", export.StartDate},
+		{"exported_at", export.ExportedAt},
+		{"stock", export.Stock},
+		{"performance", export.Performance},
+		{"shareholders", export.Shareholders},
+	}
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for _, field := range header {
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field.key, err)
+		}
+		if _, err := fmt.Fprintf(w, "%q:%s,", field.key, value); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, `"daily":[`); err != nil {
+		return err
+	}
+	flush()
+
+	count := 0
+	err := export.EachDaily(func(batch []model.DailyData) error {
+		for _, bar := range batch {
+			value, err := json.Marshal(bar)
+			if err != nil {
+				return fmt.Errorf("failed to encode daily data %d: %w", bar.TradeDate, err)
+			}
+			if count > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(value); err != nil {
+				return err
+			}
+			count++
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `],"daily_count":%d}`, count)
+	return err
+}
+
+// ExportStockData 导出股票的完整数据包（基础信息、日K线、业绩报表、股东户数），用于离线分析
+// range参数限制日K线的区间（如1y、6m、30d），缺省导出全部历史；响应直接为数据包JSON而非统一响应格式，日K线流式写出
+func (h *Handler) ExportStockData(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	rangeParam := c.DefaultQuery("range", "all")
+	startDate, err := parseExportRange(rangeParam, time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Exporting data bundle for %s, range: %s", tsCode, rangeParam)
+
+	stock, err := repository.NewStock(h.db).GetStockByTsCode(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get stock from database: %v", err)
+		Error(c, CodeInternalError, "获取股票信息失败")
+		return
+	}
+	if stock == nil {
+		Error(c, CodeNotFound, "股票不存在")
+		return
+	}
+
+	reports, err := repository.NewPerformance(h.db).GetByTsCode(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get performance reports from database: %v", err)
+		Error(c, CodeInternalError, "获取业绩报表失败")
+		return
+	}
+
+	counts, err := repository.NewShareholder(h.db).GetByTsCode(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get shareholder counts from database: %v", err)
+		Error(c, CodeInternalError, "获取股东户数失败")
+		return
+	}
+
+	dailyRepo := repository.NewDailyData(h.db)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, tsCode))
+	c.Status(http.StatusOK)
+
+	// 响应头已写出，出错时只能记录日志，客户端收到不完整的JSON
+	if err := writeStockExport(c.Writer, stockExport{
+		TsCode:       tsCode,
+		Range:        rangeParam,
+		StartDate:    startDate,
+		ExportedAt:   time.Now(),
+		Stock:        stock,
+		Performance:  reports,
+		Shareholders: counts,
+		EachDaily: func(fn func([]model.DailyData) error) error {
+			return dailyRepo.EachDailyDataBatch(tsCode, startDate, exportDailyBatchSize, fn)
+		},
+	}); err != nil {
+		h.logger.Errorf("Failed to export data bundle for %s: %v", tsCode, err)
+	}
+}
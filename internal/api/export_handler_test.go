@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportRange(t *testing.T) {
+	now := time.Date(2024, 6, 15, 10, 0, 0, 0, time.Local)
+
+	start, err := parseExportRange("", now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, start)
+
+	start, err = parseExportRange("all", now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, start)
+
+	start, err = parseExportRange("1y", now)
+	require.NoError(t, err)
+	assert.Equal(t, 20230615, start)
+
+	start, err = parseExportRange("6M", now)
+	require.NoError(t, err)
+	assert.Equal(t, 20231215, start)
+
+	start, err = parseExportRange("30d", now)
+	require.NoError(t, err)
+	assert.Equal(t, 20240516, start)
+
+	for _, value := range []string{"1w", "0y", "y", "-1y"} {
+		_, err = parseExportRange(value, now)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestWriteStockExport(t *testing.T) {
+	export := stockExport{
+		TsCode:       "600519.SH",
+		Range:        "1y",
+		StartDate:    20230615,
+		ExportedAt:   time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC),
+		Stock:        &model.Stock{TsCode: "600519.SH", Name: "贵州茅台"},
+		Performance:  []model.PerformanceReport{{TsCode: "600519.SH", ReportDate: 20231231}},
+		Shareholders: []*model.ShareholderCount{{TsCode: "600519.SH", EndDate: 20231231}},
+		EachDaily: func(fn func([]model.DailyData) error) error {
+			// 分两批写出日K线
+			if err := fn([]model.DailyData{{TsCode: "600519.SH", TradeDate: 20240102}, {TsCode: "600519.SH", TradeDate: 20240103}}); err != nil {
+				return err
+			}
+			return fn([]model.DailyData{{TsCode: "600519.SH", TradeDate: 20240104}})
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeStockExport(&buf, export))
+
+	var bundle struct {
+		TsCode       string                    `json:"ts_code"`
+		Range        string                    `json:"range"`
+		StartDate    int                       `json:"start_date"`
+		Stock        *model.Stock              `json:"stock"`
+		Performance  []model.PerformanceReport `json:"performance"`
+		Shareholders []*model.ShareholderCount `json:"shareholders"`
+		Daily        []model.DailyData         `json:"daily"`
+		DailyCount   int                       `json:"daily_count"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &bundle))
+
+	assert.Equal(t, "600519.SH", bundle.TsCode)
+	assert.Equal(t, "1y", bundle.Range)
+	assert.Equal(t, 20230615, bundle.StartDate)
+	require.NotNil(t, bundle.Stock)
+	assert.Equal(t, "贵州茅台", bundle.Stock.Name)
+	require.Len(t, bundle.Performance, 1)
+	require.Len(t, bundle.Shareholders, 1)
+	assert.Equal(t, 20231231, bundle.Shareholders[0].EndDate)
+	require.Len(t, bundle.Daily, 3)
+	assert.Equal(t, 20240104, bundle.Daily[2].TradeDate)
+	assert.Equal(t, 3, bundle.DailyCount)
+
+	// 没有日K线时daily为空数组
+	export.EachDaily = func(fn func([]model.DailyData) error) error { return nil }
+	buf.Reset()
+	require.NoError(t, writeStockExport(&buf, export))
+	assert.Contains(t, buf.String(), `"daily":[],"daily_count":0}`)
+
+	// 读取日K线出错时返回错误
+	export.EachDaily = func(fn func([]model.DailyData) error) error { return errors.New("db down") }
+	assert.Error(t, writeStockExport(&bytes.Buffer{}, export))
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"stock/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshFundamentals 异步从数据源刷新单只股票的业绩报表和股东户数，返回任务ID
+// 定时任务受每日配额限制，单只股票可能很久才轮到一次，需要时可通过该接口立即刷新
+// 两类数据分别同步，其中一类失败不影响另一类，任一失败时任务标记为失败
+func (h *Handler) RefreshFundamentals(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	if h.performanceService == nil || h.shareholderService == nil {
+		Error(c, CodeDataSourceUnavailable, "业绩报表数据源不可用")
+		return
+	}
+
+	h.logger.Infof("API: Starting async refresh of fundamentals: %s", tsCode)
+
+	task, err := h.taskService.CreateTask(model.TaskTypeRefreshFundamentals, map[string]interface{}{
+		"source":    "api_request",
+		"stockCode": tsCode,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to create fundamentals refresh task: %v", err)
+		Error(c, CodeInternalError, "创建刷新任务失败")
+		return
+	}
+
+	h.taskService.StartTask(task.ID, func(ctx context.Context, task *model.Task, updateProgress func(int, string)) error {
+		var errs []error
+
+		updateProgress(10, "开始同步业绩报表")
+		if err := h.performanceService.SyncPerformanceReports(ctx, tsCode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync performance reports: %w", err))
+		}
+
+		updateProgress(50, "开始同步股东户数")
+		if err := h.shareholderService.SyncShareholderCounts(ctx, tsCode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync shareholder counts: %w", err))
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+		updateProgress(100, "业绩报表和股东户数同步完成")
+		return nil
+	})
+
+	Success(c, gin.H{
+		"task_id": task.ID,
+		"message": "业绩报表和股东户数刷新任务已启动",
+		"status":  "running",
+		"stock":   tsCode,
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// fakeFundamentalsCollector 只实现业绩报表和股东户数采集的数据源，记录被请求的股票代码
+type fakeFundamentalsCollector struct {
+	collector.DataCollector
+	requested chan string
+}
+
+func (f *fakeFundamentalsCollector) GetPerformanceReports(tsCode string) ([]model.PerformanceReport, error) {
+	f.requested <- "performance:" + tsCode
+	return []model.PerformanceReport{{TsCode: tsCode, ReportDate: 20250630, EPS: 1.2}}, nil
+}
+
+func (f *fakeFundamentalsCollector) GetShareholderCounts(tsCode string) ([]model.ShareholderCount, error) {
+	f.requested <- "shareholder:" + tsCode
+	return []model.ShareholderCount{{TsCode: tsCode, EndDate: 20250630, HolderNum: 480000}}, nil
+}
+
+func TestHandler_RefreshFundamentals(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	fake := &fakeFundamentalsCollector{requested: make(chan string, 2)}
+	h := &Handler{
+		logger:             logrus.New(),
+		taskService:        service.NewTaskService(db, logrus.New()),
+		performanceService: service.NewPerformanceService(repository.NewPerformance(db), repository.NewStock(db), fake),
+		shareholderService: service.NewShareholderService(repository.NewShareholder(db), fake),
+	}
+
+	status, resp := performRequest(t, h.RefreshFundamentals, http.MethodPost, "/stocks/600519.sh/fundamentals/refresh", "",
+		gin.Params{{Key: "code", Value: "600519.sh"}})
+	require.Equal(t, http.StatusOK, status)
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.NotEmpty(t, data["task_id"])
+	assert.Equal(t, "600519.SH", data["stock"])
+
+	// 任务在后台依次同步业绩报表和股东户数
+	var requested []string
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-fake.requested:
+			requested = append(requested, r)
+		case <-time.After(time.Second):
+			t.Fatal("refresh task did not fetch fundamentals")
+		}
+	}
+	assert.Equal(t, []string{"performance:600519.SH", "shareholder:600519.SH"}, requested)
+}
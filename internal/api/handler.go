@@ -10,7 +10,9 @@ import (
 
 	"stock/internal/collector"
 	"stock/internal/model"
+	"stock/internal/repository"
 	"stock/internal/service"
+	"stock/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -19,24 +21,72 @@ import (
 
 // Handler API处理器
 type Handler struct {
-	collectorManager *collector.CollectorManager
-	logger           *logrus.Logger
-	klineService     *service.KLineService
-	stockService     *service.StockService
-	taskService      *service.TaskService
-	db               *gorm.DB
+	collectorManager    *collector.CollectorManager
+	logger              *logrus.Logger
+	klineService        *service.KLineService
+	stockService        *service.StockService
+	taskService         *service.TaskService
+	indexService        *service.IndexService
+	scoreService        *service.StockScoreService
+	selectionService    *service.SelectionService
+	dataQualityService  *service.DataQualityService
+	strategyRegistry    *service.StrategyRegistry
+	indicatorService    *service.IndicatorService
+	jobRunService       *service.JobRunService
+	auditService        *service.AuditService
+	marketMetricService *service.MarketMetricService
+	performanceService  *service.PerformanceService
+	shareholderService  *service.ShareholderService
+	sourceCheckService  *service.SourceCheckService
+	stockListCache      *stockListCache
+	signalsCache        *utils.TTLCache[string, []model.StockIndicatorSignal]
+	realtimeSnapshots   realtimeSnapshotReader
+	metricCache         *utils.TTLCache[metricKey, *metricSnapshot]
+	db                  *gorm.DB
 }
 
 // NewHandler 创建新的API处理器
 func NewHandler(collectorManager *collector.CollectorManager, logger *logrus.Logger, db *gorm.DB) *Handler {
 	taskService := service.NewTaskService(db, logger)
+
+	// 指数数据由支持指数采集的数据源提供，业绩报表和股东户数由东方财富数据中心提供
+	var indexCollector collector.IndexCollector
+	var performanceService *service.PerformanceService
+	var shareholderService *service.ShareholderService
+	var sourceCheckService *service.SourceCheckService
+	if c, ok := collectorManager.LookupCollector("eastmoney"); ok {
+		indexCollector, _ = c.(collector.IndexCollector)
+		performanceService = service.GetPerformanceService(repository.NewPerformance(db), repository.NewStock(db), c)
+		shareholderService = service.GetShareholderService(repository.NewShareholder(db), c)
+		shareholderService.SetSharesRepository(repository.NewStockShares(db))
+		sourceCheckService = newSourceCheckService(collectorManager, db, c)
+	}
+
 	return &Handler{
-		collectorManager: collectorManager,
-		logger:           logger,
-		klineService:     service.NewKLineService(db, logger, collectorManager),
-		stockService:     service.NewStockService(db, logger, collectorManager),
-		taskService:      taskService,
-		db:               db,
+		collectorManager:    collectorManager,
+		logger:              logger,
+		klineService:        service.NewKLineService(db, logger, collectorManager),
+		stockService:        service.NewStockService(db, logger, collectorManager),
+		taskService:         taskService,
+		indexService:        service.NewIndexService(repository.NewIndex(db), repository.NewIndexDaily(db), indexCollector),
+		scoreService:        service.GetStockScoreService(db),
+		selectionService:    service.GetSelectionService(db),
+		dataQualityService:  service.GetDataQualityService(db),
+		strategyRegistry:    service.GetStrategyRegistry(db),
+		indicatorService:    service.GetIndicatorService(db),
+		jobRunService:       service.GetJobRunService(db),
+		auditService:        service.GetAuditService(db),
+		marketMetricService: service.GetMarketMetricService(db),
+		performanceService:  performanceService,
+		shareholderService:  shareholderService,
+		sourceCheckService:  sourceCheckService,
+		stockListCache: newStockListCache(stockListCacheTTL, func() ([]model.Stock, error) {
+			return collectorManager.GetStockListFromSource("eastmoney")
+		}),
+		signalsCache:      utils.NewTTLCache[string, []model.StockIndicatorSignal](recentSignalsCacheTTL),
+		realtimeSnapshots: repository.NewRealtimeSnapshot(db),
+		metricCache:       utils.NewTTLCache[metricKey, *metricSnapshot](metricDistributionCacheTTL),
+		db:                db,
 	}
 }
 
@@ -50,15 +100,18 @@ type Response struct {
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
-		Code:    0,
-		Message: "success",
+		Code:    CodeSuccess,
+		Message: CodeMessage(CodeSuccess),
 		Data:    data,
 	})
 }
 
-// Error 错误响应
+// Error 错误响应，HTTP状态码由业务错误码决定，业务错误码放在响应体中
 func Error(c *gin.Context, code int, message string) {
-	c.JSON(http.StatusOK, Response{
+	if message == "" {
+		message = CodeMessage(code)
+	}
+	c.JSON(HTTPStatus(code), Response{
 		Code:    code,
 		Message: message,
 		Data:    nil,
@@ -83,11 +136,11 @@ func (h *Handler) GetStockList(c *gin.Context) {
 		size = 20
 	}
 
-	// 从东方财富获取股票列表
-	stocks, err := h.collectorManager.GetStockListFromSource("eastmoney")
+	// 从东方财富获取股票列表，缓存有效期内直接使用缓存
+	stocks, fetchedAt, err := h.stockListCache.Get()
 	if err != nil {
 		h.logger.Errorf("Failed to get stock list: %v", err)
-		Error(c, 1001, "获取股票列表失败")
+		Error(c, CodeInternalError, "获取股票列表失败")
 		return
 	}
 
@@ -98,10 +151,11 @@ func (h *Handler) GetStockList(c *gin.Context) {
 
 	if start >= total {
 		Success(c, gin.H{
-			"stocks": []interface{}{},
-			"total":  total,
-			"page":   page,
-			"size":   size,
+			"stocks":     []interface{}{},
+			"total":      total,
+			"page":       page,
+			"size":       size,
+			"fetched_at": fetchedAt,
 		})
 		return
 	}
@@ -113,10 +167,28 @@ func (h *Handler) GetStockList(c *gin.Context) {
 	pagedStocks := stocks[start:end]
 
 	Success(c, gin.H{
-		"stocks": pagedStocks,
-		"total":  total,
-		"page":   page,
-		"size":   size,
+		"stocks":     pagedStocks,
+		"total":      total,
+		"page":       page,
+		"size":       size,
+		"fetched_at": fetchedAt,
+	})
+}
+
+// RefreshStockList 强制从数据源重新获取股票列表并更新缓存
+func (h *Handler) RefreshStockList(c *gin.Context) {
+	h.logger.Info("API: Refreshing stock list cache")
+
+	stocks, fetchedAt, err := h.stockListCache.Refresh()
+	if err != nil {
+		h.logger.Errorf("Failed to refresh stock list: %v", err)
+		Error(c, CodeDataSourceError, "刷新股票列表失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"total":      len(stocks),
+		"fetched_at": fetchedAt,
 	})
 }
 
@@ -124,14 +196,14 @@ func (h *Handler) GetStockList(c *gin.Context) {
 func (h *Handler) GetStockDetail(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
@@ -141,53 +213,111 @@ func (h *Handler) GetStockDetail(c *gin.Context) {
 	collector, err := h.collectorManager.GetCollector("eastmoney")
 	if err != nil {
 		h.logger.Errorf("Failed to get EastMoney collector: %v", err)
-		Error(c, 1004, "数据源不可用")
+		Error(c, CodeDataSourceUnavailable, "数据源不可用")
 		return
 	}
 
 	stock, err := collector.GetStockDetail(tsCode)
 	if err != nil {
 		h.logger.Errorf("Failed to get stock detail: %v", err)
-		Error(c, 1004, "获取股票详情失败")
+		Error(c, CodeDataSourceError, "获取股票详情失败")
 		return
 	}
 
+	// 附带每晚评估的数据质量评分，查询失败不影响返回股票详情
+	if quality, err := h.dataQualityService.GetDataQuality(tsCode); err != nil {
+		h.logger.Warnf("Failed to get data quality for %s: %v", tsCode, err)
+	} else {
+		stock.DataQuality = quality
+	}
+
 	Success(c, stock)
 }
 
+// maxKLineRangeDays 按日期区间查询K线时允许的最大自然日跨度
+const maxKLineRangeDays = 3650
+
+// parseKLineDateRange 解析K线查询的时间范围
+// start/end（YYYY-MM-DD）任一存在时按日期区间查询：缺省end为今天，缺省start为end往前days天；
+// 都不存在时回退为最近days天。days无效时使用30天，与原有行为一致
+func parseKLineDateRange(startStr, endStr, daysStr string, now time.Time) (time.Time, time.Time, int, error) {
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 || days > 1000 {
+		days = 30
+	}
+
+	if startStr == "" && endStr == "" {
+		return now.AddDate(0, 0, -days), now, days, nil
+	}
+
+	endDate := now
+	if endStr != "" {
+		if endDate, err = time.ParseInLocation("2006-01-02", endStr, now.Location()); err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("end日期格式错误，应为YYYY-MM-DD")
+		}
+	}
+
+	startDate := endDate.AddDate(0, 0, -days)
+	if startStr != "" {
+		if startDate, err = time.ParseInLocation("2006-01-02", startStr, now.Location()); err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("start日期格式错误，应为YYYY-MM-DD")
+		}
+	}
+
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("start日期不能晚于end日期")
+	}
+
+	span := int(endDate.Sub(startDate).Hours() / 24)
+	if span > maxKLineRangeDays {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("查询区间不能超过%d天", maxKLineRangeDays)
+	}
+
+	return startDate, endDate, span, nil
+}
+
 // GetKLineData 获取K线数据（只从数据库查询，不刷新）
+// 请求头Accept为application/x-ndjson时逐行返回K线，stream=true时流式写出统一响应，两种方式的K线均按交易日期升序
 func (h *Handler) GetKLineData(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
-	// 获取查询参数
-	daysStr := c.DefaultQuery("days", "30")
-	days, err := strconv.Atoi(daysStr)
-	if err != nil || days < 1 || days > 1000 {
-		days = 30
+	// 获取查询参数，start/end优先于days
+	startDate, endDate, days, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.Query("days"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
 	}
 
-	h.logger.Infof("API: Getting K-line data from database for %s (last %d days)", tsCode, days)
+	h.logger.Infof("API: Getting K-line data from database for %s (%s ~ %s)", tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
-	// 计算时间范围
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
+	// 大区间查询可流式返回，按批读取并写出，避免同时在内存中保留全部K线和序列化结果
+	if format := negotiateKLineStream(c); format != klineStreamNone {
+		h.streamKLineData(c, format, klineStream{
+			TsCode: tsCode,
+			Days:   days,
+			Start:  startDate.Format("2006-01-02"),
+			End:    endDate.Format("2006-01-02"),
+		}, startDate, endDate)
+		return
+	}
 
 	// 只从数据库获取K线数据
 	klineData, err := h.klineService.GetKLineData(tsCode, startDate, endDate)
 	if err != nil {
 		h.logger.Errorf("Failed to get K-line data from database: %v", err)
-		Error(c, 1005, "获取K线数据失败")
+		Error(c, CodeInternalError, "获取K线数据失败")
 		return
 	}
 
@@ -202,18 +332,50 @@ func (h *Handler) GetKLineData(c *gin.Context) {
 	})
 }
 
+// DetectPriceAnomalies 检查K线数据中超过涨跌幅限制的价格跳变，存在时需要刷新K线数据重新复权
+func (h *Handler) DetectPriceAnomalies(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	h.logger.Infof("API: Detecting price anomalies for %s", tsCode)
+
+	anomalies, err := h.klineService.DetectPriceAnomalies(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to detect price anomalies: %v", err)
+		Error(c, CodeInternalError, "检查K线数据失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"code":          tsCode,
+		"count":         len(anomalies),
+		"anomalies":     anomalies,
+		"needs_refetch": len(anomalies) > 0,
+	})
+}
+
 // RefreshKLineData 刷新K线数据（从API获取并保存到数据库）
 func (h *Handler) RefreshKLineData(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
@@ -234,7 +396,7 @@ func (h *Handler) RefreshKLineData(c *gin.Context) {
 	klineData, err := h.klineService.RefreshKLineData(tsCode, startDate, endDate)
 	if err != nil {
 		h.logger.Errorf("Failed to refresh K-line data: %v", err)
-		Error(c, 1005, "刷新K线数据失败")
+		Error(c, CodeDataSourceError, "刷新K线数据失败")
 		return
 	}
 
@@ -254,14 +416,14 @@ func (h *Handler) RefreshKLineData(c *gin.Context) {
 func (h *Handler) GetKLineDataRange(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
@@ -270,7 +432,7 @@ func (h *Handler) GetKLineDataRange(c *gin.Context) {
 	startDate, endDate, count, err := h.klineService.GetDataRange(tsCode)
 	if err != nil {
 		h.logger.Errorf("Failed to get data range: %v", err)
-		Error(c, 1005, "获取数据范围失败")
+		Error(c, CodeInternalError, "获取数据范围失败")
 		return
 	}
 
@@ -296,14 +458,14 @@ func (h *Handler) GetKLineDataRange(c *gin.Context) {
 func (h *Handler) CheckKLineDataFreshness(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
@@ -312,7 +474,7 @@ func (h *Handler) CheckKLineDataFreshness(c *gin.Context) {
 	freshness, err := h.klineService.CheckDataFreshness(tsCode)
 	if err != nil {
 		h.logger.Errorf("Failed to check data freshness: %v", err)
-		Error(c, 1005, "检查数据新鲜度失败")
+		Error(c, CodeInternalError, "检查数据新鲜度失败")
 		return
 	}
 
@@ -320,25 +482,68 @@ func (h *Handler) CheckKLineDataFreshness(c *gin.Context) {
 }
 
 // GetRealtimeData 获取实时数据
+// source=snapshot时返回最后一次同步保存的行情快照，不请求数据源，默认live实时请求数据源
 func (h *Handler) GetRealtimeData(c *gin.Context) {
 	codesParam := c.Query("codes")
 	if codesParam == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	h.respondRealtimeData(c, strings.Split(codesParam, ","))
+}
+
+// maxBatchRealtimeCodes 批量实时行情单次请求的最大股票数量
+const maxBatchRealtimeCodes = 500
+
+// GetBatchRealtimeData 批量获取实时数据，股票代码通过请求体传递，适合代码较多的场景
+func (h *Handler) GetBatchRealtimeData(c *gin.Context) {
+	var req struct {
+		Codes []string `json:"codes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, CodeInvalidParam, "请求参数错误")
+		return
+	}
+
+	if len(req.Codes) == 0 {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	if len(req.Codes) > maxBatchRealtimeCodes {
+		Error(c, CodeInvalidParam, fmt.Sprintf("单次最多查询 %d 只股票", maxBatchRealtimeCodes))
 		return
 	}
 
-	// 解析股票代码列表
-	codes := strings.Split(codesParam, ",")
+	h.respondRealtimeData(c, req.Codes)
+}
+
+// respondRealtimeData 规范化股票代码并返回对应的实时数据
+func (h *Handler) respondRealtimeData(c *gin.Context, codes []string) {
+	// 解析股票代码列表，去除重复代码
 	var tsCodes []string
+	seen := make(map[string]bool, len(codes))
 	for _, code := range codes {
 		code = strings.TrimSpace(strings.ToUpper(code))
-		if code != "" && strings.Contains(code, ".") {
+		if code != "" && strings.Contains(code, ".") && !seen[code] {
+			seen[code] = true
 			tsCodes = append(tsCodes, code)
 		}
 	}
 
 	if len(tsCodes) == 0 {
-		Error(c, 1003, "没有有效的股票代码")
+		Error(c, CodeInvalidTsCode, "没有有效的股票代码")
+		return
+	}
+
+	switch c.DefaultQuery("source", realtimeSourceLive) {
+	case realtimeSourceSnapshot:
+		h.respondRealtimeSnapshot(c, tsCodes)
+		return
+	case realtimeSourceLive:
+	default:
+		Error(c, CodeInvalidParam, "source参数错误，可选值：live、snapshot")
 		return
 	}
 
@@ -348,20 +553,21 @@ func (h *Handler) GetRealtimeData(c *gin.Context) {
 	collector, err := h.collectorManager.GetCollector("eastmoney")
 	if err != nil {
 		h.logger.Errorf("Failed to get EastMoney collector: %v", err)
-		Error(c, 1007, "数据源不可用")
+		Error(c, CodeDataSourceUnavailable, "数据源不可用")
 		return
 	}
 
 	realtimeData, err := collector.GetRealtimeData(tsCodes)
 	if err != nil {
 		h.logger.Errorf("Failed to get realtime data: %v", err)
-		Error(c, 1007, "获取实时数据失败")
+		Error(c, CodeDataSourceError, "获取实时数据失败")
 		return
 	}
 
 	Success(c, gin.H{
 		"codes":    tsCodes,
 		"count":    len(realtimeData),
+		"source":   realtimeSourceLive,
 		"realtime": realtimeData,
 	})
 }
@@ -390,7 +596,7 @@ func (h *Handler) GetStockStats(c *gin.Context) {
 	stats, err := h.stockService.GetStockStats()
 	if err != nil {
 		h.logger.Errorf("Failed to get stock stats: %v", err)
-		Error(c, 1006, "获取股票统计信息失败")
+		Error(c, CodeInternalError, "获取股票统计信息失败")
 		return
 	}
 
@@ -412,7 +618,7 @@ func (h *Handler) SearchStocks(c *gin.Context) {
 	stocks, err := h.stockService.SearchStocks(keyword, limit)
 	if err != nil {
 		h.logger.Errorf("Failed to search stocks: %v", err)
-		Error(c, 1007, "搜索股票失败")
+		Error(c, CodeInternalError, "搜索股票失败")
 		return
 	}
 
@@ -435,7 +641,7 @@ func (h *Handler) SyncAllStocksAsync(c *gin.Context) {
 	})
 	if err != nil {
 		h.logger.Errorf("Failed to create sync task: %v", err)
-		Error(c, 1005, "创建同步任务失败")
+		Error(c, CodeInternalError, "创建同步任务失败")
 		return
 	}
 
@@ -455,14 +661,14 @@ func (h *Handler) SyncAllStocksAsync(c *gin.Context) {
 func (h *Handler) GetTaskStatus(c *gin.Context) {
 	taskID := c.Param("taskId")
 	if taskID == "" {
-		Error(c, 1001, "任务ID不能为空")
+		Error(c, CodeInvalidParam, "任务ID不能为空")
 		return
 	}
 
 	task, err := h.taskService.GetTask(taskID)
 	if err != nil {
 		h.logger.Errorf("Failed to get task %s: %v", taskID, err)
-		Error(c, 1004, "任务不存在")
+		Error(c, CodeNotFound, "任务不存在")
 		return
 	}
 
@@ -487,7 +693,7 @@ func (h *Handler) ListTasks(c *gin.Context) {
 	tasks, total, err := h.taskService.ListTasks(limit, offset, status)
 	if err != nil {
 		h.logger.Errorf("Failed to list tasks: %v", err)
-		Error(c, 1005, "获取任务列表失败")
+		Error(c, CodeInternalError, "获取任务列表失败")
 		return
 	}
 
@@ -503,14 +709,14 @@ func (h *Handler) ListTasks(c *gin.Context) {
 func (h *Handler) CancelTask(c *gin.Context) {
 	taskID := c.Param("taskId")
 	if taskID == "" {
-		Error(c, 1001, "任务ID不能为空")
+		Error(c, CodeInvalidParam, "任务ID不能为空")
 		return
 	}
 
 	err := h.taskService.CancelTask(taskID)
 	if err != nil {
 		h.logger.Errorf("Failed to cancel task %s: %v", taskID, err)
-		Error(c, 1005, "取消任务失败")
+		Error(c, CodeInternalError, "取消任务失败")
 		return
 	}
 
@@ -520,18 +726,34 @@ func (h *Handler) CancelTask(c *gin.Context) {
 	})
 }
 
+// CancelAllTasks 取消所有执行中和等待中的任务，用于数据库维护或发布前停止后台工作
+func (h *Handler) CancelAllTasks(c *gin.Context) {
+	cancelled, err := h.taskService.CancelAllTasks(model.TaskCancelReasonMaintenance)
+	if err != nil {
+		h.logger.Errorf("Failed to cancel all tasks: %v", err)
+		Error(c, CodeInternalError, "批量取消任务失败")
+		return
+	}
+
+	h.logger.Infof("API: Cancelled %d tasks for maintenance", cancelled)
+	Success(c, gin.H{
+		"cancelled": cancelled,
+		"reason":    model.TaskCancelReasonMaintenance,
+	})
+}
+
 // SyncSingleStockAsync 异步刷新单只股票的日K数据
 func (h *Handler) SyncSingleStockAsync(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, 1002, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode := strings.ToUpper(code)
 	if !strings.Contains(tsCode, ".") {
-		Error(c, 1003, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
 		return
 	}
 
@@ -544,7 +766,7 @@ func (h *Handler) SyncSingleStockAsync(c *gin.Context) {
 	})
 	if err != nil {
 		h.logger.Errorf("Failed to create sync task: %v", err)
-		Error(c, 1005, "创建同步任务失败")
+		Error(c, CodeInternalError, "创建同步任务失败")
 		return
 	}
 
@@ -581,7 +803,7 @@ func (h *Handler) SyncSingleStockAsync(c *gin.Context) {
 func (h *Handler) GetPerformanceReports(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -604,7 +826,7 @@ func (h *Handler) GetPerformanceReports(c *gin.Context) {
 	var reports []model.PerformanceReport
 	if err := h.db.Where("ts_code = ?", tsCode).Order("report_date DESC").Find(&reports).Error; err != nil {
 		h.logger.Errorf("Failed to query performance reports from database: %v", err)
-		Error(c, http.StatusInternalServerError, "查询业绩报表数据失败")
+		Error(c, CodeInternalError, "查询业绩报表数据失败")
 		return
 	}
 
@@ -613,14 +835,14 @@ func (h *Handler) GetPerformanceReports(c *gin.Context) {
 		collector, err := h.collectorManager.GetCollector("eastmoney")
 		if err != nil {
 			h.logger.Errorf("Failed to get collector: %v", err)
-			Error(c, http.StatusInternalServerError, "获取数据采集器失败")
+			Error(c, CodeDataSourceUnavailable, "获取数据采集器失败")
 			return
 		}
 
 		reports, err = collector.GetPerformanceReports(tsCode)
 		if err != nil {
 			h.logger.Errorf("Failed to get performance reports from collector: %v", err)
-			Error(c, http.StatusInternalServerError, "获取业绩报表数据失败")
+			Error(c, CodeDataSourceError, "获取业绩报表数据失败")
 			return
 		}
 
@@ -647,7 +869,7 @@ func (h *Handler) GetPerformanceReports(c *gin.Context) {
 func (h *Handler) GetLatestPerformanceReport(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -674,14 +896,14 @@ func (h *Handler) GetLatestPerformanceReport(c *gin.Context) {
 			collector, err := h.collectorManager.GetCollector("eastmoney")
 			if err != nil {
 				h.logger.Errorf("Failed to get collector: %v", err)
-				Error(c, http.StatusInternalServerError, "获取数据采集器失败")
+				Error(c, CodeDataSourceUnavailable, "获取数据采集器失败")
 				return
 			}
 
 			latestReport, err := collector.GetLatestPerformanceReport(tsCode)
 			if err != nil {
 				h.logger.Errorf("Failed to get latest performance report from collector: %v", err)
-				Error(c, http.StatusInternalServerError, "获取最新业绩报表数据失败")
+				Error(c, CodeDataSourceError, "获取最新业绩报表数据失败")
 				return
 			}
 
@@ -695,7 +917,7 @@ func (h *Handler) GetLatestPerformanceReport(c *gin.Context) {
 			report = *latestReport
 		} else {
 			h.logger.Errorf("Failed to query latest performance report from database: %v", err)
-			Error(c, http.StatusInternalServerError, "查询最新业绩报表数据失败")
+			Error(c, CodeInternalError, "查询最新业绩报表数据失败")
 			return
 		}
 	}
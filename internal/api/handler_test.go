@@ -0,0 +1,77 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKLineDateRange_ExplicitRange(t *testing.T) {
+	now := time.Date(2025, 9, 10, 15, 0, 0, 0, time.Local)
+
+	start, end, days, err := parseKLineDateRange("2020-01-01", "2020-06-30", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2020, 6, 30, 0, 0, 0, 0, time.Local), end)
+	assert.Equal(t, 181, days)
+
+	// 同时给出days时以start/end为准
+	start2, end2, _, err := parseKLineDateRange("2020-01-01", "2020-06-30", "5", now)
+	require.NoError(t, err)
+	assert.Equal(t, start, start2)
+	assert.Equal(t, end, end2)
+
+	// 只给start时end为当前时间
+	start, end, _, err = parseKLineDateRange("2025-01-01", "", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, now, end)
+
+	// 只给end时start为end往前days天
+	start, end, days, err = parseKLineDateRange("", "2020-06-30", "10", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 6, 20, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2020, 6, 30, 0, 0, 0, 0, time.Local), end)
+	assert.Equal(t, 10, days)
+}
+
+func TestParseKLineDateRange_DaysFallback(t *testing.T) {
+	now := time.Date(2025, 9, 10, 15, 0, 0, 0, time.Local)
+
+	start, end, days, err := parseKLineDateRange("", "", "60", now)
+	require.NoError(t, err)
+	assert.Equal(t, 60, days)
+	assert.Equal(t, now, end)
+	assert.Equal(t, now.AddDate(0, 0, -60), start)
+
+	// days缺省或无效时使用30天
+	for _, daysStr := range []string{"", "abc", "0", "1001"} {
+		start, _, days, err = parseKLineDateRange("", "", daysStr, now)
+		require.NoError(t, err)
+		assert.Equal(t, 30, days, daysStr)
+		assert.Equal(t, now.AddDate(0, 0, -30), start, daysStr)
+	}
+}
+
+func TestParseKLineDateRange_Invalid(t *testing.T) {
+	now := time.Date(2025, 9, 10, 15, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name  string
+		start string
+		end   string
+	}{
+		{"开始日期格式错误", "2020/01/01", "2020-06-30"},
+		{"结束日期格式错误", "2020-01-01", "20200630"},
+		{"日期不存在", "2020-02-30", ""},
+		{"开始晚于结束", "2020-06-30", "2020-01-01"},
+		{"区间超过上限", "2000-01-01", "2020-01-01"},
+	}
+
+	for _, tc := range cases {
+		_, _, _, err := parseKLineDateRange(tc.start, tc.end, "", now)
+		assert.Error(t, err, tc.name)
+	}
+}
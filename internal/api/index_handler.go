@@ -0,0 +1,69 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetIndexList 获取跟踪的指数列表
+func (h *Handler) GetIndexList(c *gin.Context) {
+	h.logger.Info("API: Getting index list")
+
+	indices, err := h.indexService.GetAllIndices()
+	if err != nil {
+		h.logger.Errorf("Failed to get index list: %v", err)
+		Error(c, CodeInternalError, "获取指数列表失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count":   len(indices),
+		"indices": indices,
+	})
+}
+
+// GetIndexKLine 获取指数日K线数据，数据库中没有数据时从数据源拉取
+func (h *Handler) GetIndexKLine(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "指数代码不能为空")
+		return
+	}
+
+	// 指数代码与股票代码存在重叠，必须携带交易所后缀
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "指数代码格式错误，应为：000001.SH 或 399006.SZ")
+		return
+	}
+
+	daysStr := c.DefaultQuery("days", "30")
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 || days > 1000 {
+		days = 30
+	}
+
+	h.logger.Infof("API: Getting index K-line data for %s (last %d days)", tsCode, days)
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	klineData, err := h.indexService.GetIndexDaily(tsCode, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get index K-line data: %v", err)
+		Error(c, CodeDataSourceError, "获取指数K线数据失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"code":  tsCode,
+		"days":  days,
+		"count": len(klineData),
+		"kline": klineData,
+		"start": startDate.Format("2006-01-02"),
+		"end":   endDate.Format("2006-01-02"),
+	})
+}
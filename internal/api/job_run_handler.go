@@ -0,0 +1,44 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 任务运行记录查询返回数量的默认值和上限
+const (
+	defaultJobRunsLimit = 100
+	maxJobRunsLimit     = 1000
+)
+
+// GetJobRuns 获取定时采集任务的运行记录，按开始时间倒序
+// job为任务类型（如weekly_kline），为空时返回所有任务；limit限制返回数量
+func (h *Handler) GetJobRuns(c *gin.Context) {
+	job := c.Query("job")
+
+	limit := defaultJobRunsLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxJobRunsLimit {
+			Error(c, CodeInvalidParam, "limit应为1到1000之间的整数")
+			return
+		}
+	}
+
+	h.logger.Infof("API: Getting job runs of %q", job)
+
+	runs, err := h.jobRunService.List(job, limit)
+	if err != nil {
+		h.logger.Errorf("Failed to get job runs: %v", err)
+		Error(c, CodeInternalError, "获取任务运行记录失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"job":   job,
+		"count": len(runs),
+		"runs":  runs,
+	})
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// klineStreamBatchSize 流式返回K线时每批从数据库读取并写出的条数
+const klineStreamBatchSize = 1000
+
+// ndjsonContentType NDJSON响应的内容类型，每行一个JSON对象
+const ndjsonContentType = "application/x-ndjson"
+
+// klineStreamFormat K线流式响应格式
+type klineStreamFormat int
+
+const (
+	klineStreamNone   klineStreamFormat = iota // 不流式返回，一次性序列化统一响应
+	klineStreamJSON                            // 统一响应格式，kline数组分批写出
+	klineStreamNDJSON                          // 每行一根K线，不带统一响应外层
+)
+
+// negotiateKLineStream 根据请求选择K线响应格式：Accept为application/x-ndjson时返回NDJSON，stream=true时流式写出统一响应
+func negotiateKLineStream(c *gin.Context) klineStreamFormat {
+	if strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		return klineStreamNDJSON
+	}
+	if c.Query("stream") == "true" {
+		return klineStreamJSON
+	}
+	return klineStreamNone
+}
+
+// klineStream 流式K线响应的内容，K线通过eachDaily分批读取
+type klineStream struct {
+	TsCode    string
+	Days      int
+	Start     string
+	End       string
+	EachDaily func(fn func([]model.DailyData) error) error
+}
+
+// flushWriter 写出缓冲的响应，使客户端逐批收到数据
+func flushWriter(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeKLineJSONStream 以统一响应格式写出K线，结构与非流式响应一致，但kline按交易日期升序，count在kline之后：
+//
+//	{"code":0,"message":"success","data":{"code":"600519.SH","days":30,"start":"...","end":"...",
+//	  "source":"database","kline":[...],"count":242}}
+//
+// K线每批编码后立即写出并刷新，不同时在内存中保留全部K线和序列化结果；写出过程中出错时响应不完整
+func writeKLineJSONStream(w io.Writer, stream klineStream) error {
+	header, err := json.Marshal(struct {
+		Code   string `json:"code"`
+		Days   int    `json:"days"`
+		Start  string `json:"start"`
+		End    string `json:"end"`
+		Source string `json:"source"`
+	}{stream.TsCode, stream.Days, stream.Start, stream.End, "database"})
+	if err != nil {
+		return fmt.Errorf("failed to encode kline header: %w", err)
+	}
+
+	// 去掉头部对象的右括号，后续追加kline数组和count
+	if _, err := fmt.Fprintf(w, `{"code":%d,"message":%q,"data":%s,"kline":[`,
+		CodeSuccess, CodeMessage(CodeSuccess), header[:len(header)-1]); err != nil {
+		return err
+	}
+	flushWriter(w)
+
+	count := 0
+	err = stream.EachDaily(func(batch []model.DailyData) error {
+		for _, bar := range batch {
+			value, err := json.Marshal(bar)
+			if err != nil {
+				return fmt.Errorf("failed to encode daily data %d: %w", bar.TradeDate, err)
+			}
+			if count > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(value); err != nil {
+				return err
+			}
+			count++
+		}
+		flushWriter(w)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `],"count":%d}}`, count)
+	return err
+}
+
+// writeKLineNDJSON 以NDJSON写出K线，每行一根，按交易日期升序，每批写完后刷新
+func writeKLineNDJSON(w io.Writer, eachDaily func(fn func([]model.DailyData) error) error) error {
+	encoder := json.NewEncoder(w)
+	return eachDaily(func(batch []model.DailyData) error {
+		for _, bar := range batch {
+			if err := encoder.Encode(bar); err != nil {
+				return fmt.Errorf("failed to write daily data %d: %w", bar.TradeDate, err)
+			}
+		}
+		flushWriter(w)
+		return nil
+	})
+}
+
+// streamKLineData 按批从数据库读取日期区间内的日K线并流式写出
+func (h *Handler) streamKLineData(c *gin.Context, format klineStreamFormat, stream klineStream, startDate, endDate time.Time) {
+	start := startDate.Year()*10000 + int(startDate.Month())*100 + startDate.Day()
+	end := endDate.Year()*10000 + int(endDate.Month())*100 + endDate.Day()
+	dailyRepo := repository.NewDailyData(h.db)
+	stream.EachDaily = func(fn func([]model.DailyData) error) error {
+		return dailyRepo.EachDailyDataBatchInRange(stream.TsCode, start, end, klineStreamBatchSize, fn)
+	}
+
+	var err error
+	if format == klineStreamNDJSON {
+		c.Header("Content-Type", ndjsonContentType+"; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = writeKLineNDJSON(c.Writer, stream.EachDaily)
+	} else {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = writeKLineJSONStream(c.Writer, stream)
+	}
+
+	// 响应头已写出，出错时只能记录日志，客户端收到不完整的响应
+	if err != nil {
+		h.logger.Errorf("Failed to stream K-line data for %s: %v", stream.TsCode, err)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// twoBatchDaily 分两批返回日K线
+func twoBatchDaily(fn func([]model.DailyData) error) error {
+	if err := fn([]model.DailyData{
+		{TsCode: "600519.SH", TradeDate: 20240102, Close: 1685.01},
+		{TsCode: "600519.SH", TradeDate: 20240103, Close: 1694},
+	}); err != nil {
+		return err
+	}
+	return fn([]model.DailyData{{TsCode: "600519.SH", TradeDate: 20240104, Close: 1669.5}})
+}
+
+func TestWriteKLineJSONStream(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeKLineJSONStream(&buf, klineStream{
+		TsCode: "600519.SH", Days: 30, Start: "2024-01-01", End: "2024-01-31", EachDaily: twoBatchDaily,
+	}))
+
+	var resp struct {
+		Code int `json:"code"`
+		Data struct {
+			Code   string            `json:"code"`
+			Days   int               `json:"days"`
+			Start  string            `json:"start"`
+			Source string            `json:"source"`
+			Count  int               `json:"count"`
+			KLine  []model.DailyData `json:"kline"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp), buf.String())
+	assert.Equal(t, CodeSuccess, resp.Code)
+	assert.Equal(t, "600519.SH", resp.Data.Code)
+	assert.Equal(t, 30, resp.Data.Days)
+	assert.Equal(t, "2024-01-01", resp.Data.Start)
+	assert.Equal(t, "database", resp.Data.Source)
+	assert.Equal(t, 3, resp.Data.Count)
+	require.Len(t, resp.Data.KLine, 3)
+	assert.Equal(t, 20240102, resp.Data.KLine[0].TradeDate)
+	assert.Equal(t, 1669.5, resp.Data.KLine[2].Close)
+}
+
+func TestWriteKLineJSONStream_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeKLineJSONStream(&buf, klineStream{
+		TsCode: "600519.SH", EachDaily: func(func([]model.DailyData) error) error { return nil },
+	}))
+	var resp Response
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp), buf.String())
+	assert.Equal(t, map[string]interface{}{
+		"code": "600519.SH", "days": float64(0), "start": "", "end": "", "source": "database",
+		"kline": []interface{}{}, "count": float64(0),
+	}, resp.Data)
+}
+
+func TestWriteKLineNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeKLineNDJSON(&buf, twoBatchDaily))
+
+	var bars []model.DailyData
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var bar model.DailyData
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &bar))
+		bars = append(bars, bar)
+	}
+	require.Len(t, bars, 3)
+	assert.Equal(t, []int{20240102, 20240103, 20240104}, []int{bars[0].TradeDate, bars[1].TradeDate, bars[2].TradeDate})
+}
+
+func TestNegotiateKLineStream(t *testing.T) {
+	cases := []struct {
+		target string
+		accept string
+		want   klineStreamFormat
+	}{
+		{"/kline", "", klineStreamNone},
+		{"/kline", "application/json", klineStreamNone},
+		{"/kline", "application/x-ndjson", klineStreamNDJSON},
+		{"/kline?stream=true", "", klineStreamJSON},
+		{"/kline?stream=true", "application/x-ndjson", klineStreamNDJSON},
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, tc.target, nil)
+		if tc.accept != "" {
+			c.Request.Header.Set("Accept", tc.accept)
+		}
+		assert.Equal(t, tc.want, negotiateKLineStream(c), tc.target+" "+tc.accept)
+	}
+}
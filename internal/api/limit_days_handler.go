@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTradeDate 解析交易日期参数，支持YYYYMMDD和YYYY-MM-DD两种格式，为空时取今天
+func parseTradeDate(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+
+	layout := "20060102"
+	if strings.Contains(value, "-") {
+		layout = "2006-01-02"
+	}
+	date, err := time.ParseInLocation(layout, value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date日期格式错误，应为YYYYMMDD或YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// GetLimitDays 获取股票在日期区间内的涨停、跌停交易日
+// 日期区间参数与K线查询接口一致，涨跌幅限制按板块确定：主板10%、ST股票5%、创业板和科创板20%、北交所30%
+func (h *Handler) GetLimitDays(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	startDate, endDate, _, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.Query("days"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting limit days for %s (%s ~ %s)", tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+
+	days, err := h.klineService.GetLimitDays(tsCode, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get limit days: %v", err)
+		Error(c, CodeInternalError, "获取涨跌停数据失败")
+		return
+	}
+
+	upCount, downCount := 0, 0
+	for _, day := range days {
+		if day.Type == model.LimitUp {
+			upCount++
+		} else {
+			downCount++
+		}
+	}
+
+	Success(c, gin.H{
+		"code":             tsCode,
+		"start":            startDate.Format("2006-01-02"),
+		"end":              endDate.Format("2006-01-02"),
+		"limit_up_count":   upCount,
+		"limit_down_count": downCount,
+		"days":             days,
+	})
+}
+
+// GetMarketLimitUp 获取全市场在指定交易日涨停的股票
+func (h *Handler) GetMarketLimitUp(c *gin.Context) {
+	date, err := parseTradeDate(c.Query("date"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting market limit-up stocks on %s", date.Format("2006-01-02"))
+
+	stocks, err := h.klineService.GetMarketLimitDays(date, model.LimitUp)
+	if err != nil {
+		h.logger.Errorf("Failed to get market limit-up stocks: %v", err)
+		Error(c, CodeInternalError, "获取涨停股票失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"date":   date.Format("2006-01-02"),
+		"count":  len(stocks),
+		"stocks": stocks,
+	})
+}
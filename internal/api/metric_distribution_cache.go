@@ -0,0 +1,42 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"stock/internal/model"
+)
+
+// metricDistributionCacheTTL 全市场指标分布缓存的有效期
+// 分布需要读取全市场最新一期的业绩报表或股东户数，这些数据每天最多更新一次，分布和个股百分位共用同一份数据；
+// 缓存键包含计算日期，日期变化后按新键重新计算，有效期只用于清理前一天的缓存项
+const metricDistributionCacheTTL = 24 * time.Hour
+
+// metricKey 全市场指标分布的缓存键，按(日期, 指标)缓存
+type metricKey struct {
+	date   int // 计算日期，YYYYMMDD格式
+	metric model.MarketMetric
+}
+
+// metricSnapshot 某一天某个指标的全市场数据
+type metricSnapshot struct {
+	date         int                      // 计算日期，YYYYMMDD格式
+	values       map[string]float64       // 各股票的指标值，键为股票代码
+	sorted       []float64                // 升序排列的指标值
+	distribution model.MetricDistribution // 指标的分布
+}
+
+// newMetricSnapshot 根据各股票的指标值生成全市场数据
+func newMetricSnapshot(metric model.MarketMetric, date int, values map[string]float64) *metricSnapshot {
+	sorted := make([]float64, 0, len(values))
+	for _, value := range values {
+		sorted = append(sorted, value)
+	}
+	sort.Float64s(sorted)
+	return &metricSnapshot{
+		date:         date,
+		values:       values,
+		sorted:       sorted,
+		distribution: model.NewMetricDistribution(metric, sorted),
+	}
+}
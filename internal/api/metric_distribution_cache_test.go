@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetricSnapshot(t *testing.T) {
+	snapshot := newMetricSnapshot(model.MetricROE, 20250930, map[string]float64{"000001.SZ": 9.5, "600519.SH": 30.1, "000002.SZ": -2})
+	assert.Equal(t, 20250930, snapshot.date)
+	assert.Equal(t, []float64{-2, 9.5, 30.1}, snapshot.sorted)
+	assert.Equal(t, 9.5, snapshot.distribution.Median)
+}
+
+func TestMetricDistributionHandlers_Cached(t *testing.T) {
+	h := &Handler{logger: logrus.New(), metricCache: utils.NewTTLCache[metricKey, *metricSnapshot](metricDistributionCacheTTL)}
+	today := utils.TradeDateOf(utils.AppNow())
+	h.metricCache.Set(metricKey{date: today, metric: model.MetricROE}, newMetricSnapshot(model.MetricROE, today, map[string]float64{
+		"000001.SZ": 9.5, "600519.SH": 30.1, "000002.SZ": -2, "601318.SH": 12,
+	}))
+
+	status, resp := performRequest(t, h.GetMetricDistribution, http.MethodGet, "/market/distribution?metric=roe", "", nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, CodeSuccess, resp.Code)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, float64(today), data["date"])
+	distribution := data["distribution"].(map[string]interface{})
+	assert.Equal(t, "roe", distribution["metric"])
+	assert.Equal(t, float64(4), distribution["count"])
+	assert.Equal(t, -2.0, distribution["min"])
+	assert.Equal(t, 30.1, distribution["max"])
+
+	params := gin.Params{{Key: "code", Value: "600519.sh"}}
+	status, resp = performRequest(t, h.GetStockPercentile, http.MethodGet, "/stocks/600519.sh/percentile?metric=roe", "", params)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, CodeSuccess, resp.Code)
+	data = resp.Data.(map[string]interface{})
+	assert.Equal(t, "600519.SH", data["code"])
+	assert.Equal(t, 30.1, data["value"])
+	assert.Equal(t, 87.5, data["percentile"])
+	assert.Equal(t, float64(4), data["count"])
+
+	// 没有指标数据的股票
+	params = gin.Params{{Key: "code", Value: "300750.SZ"}}
+	_, resp = performRequest(t, h.GetStockPercentile, http.MethodGet, "/stocks/300750.SZ/percentile?metric=roe", "", params)
+	assert.Equal(t, CodeNotFound, resp.Code)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"strings"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadMetricSnapshot 获取指标的全市场数据，当天已计算过时直接返回缓存，同一指标的并发请求只计算一次
+func (h *Handler) loadMetricSnapshot(metric model.MarketMetric) (*metricSnapshot, error) {
+	date := utils.TradeDateOf(utils.AppNow())
+	return h.metricCache.GetOrCompute(metricKey{date: date, metric: metric}, func() (*metricSnapshot, error) {
+		values, err := h.marketMetricService.GetLatestValues(metric)
+		if err != nil {
+			return nil, err
+		}
+		return newMetricSnapshot(metric, date, values), nil
+	})
+}
+
+// GetMetricDistribution 获取指标在全市场的分布（最小值、10/25/50/75/90分位数、最大值）
+// metric为指标名称，业绩类指标取各股票最新一期业绩报表，holder_num取最新一期股东户数；分布按天缓存
+func (h *Handler) GetMetricDistribution(c *gin.Context) {
+	metric, err := model.ParseMarketMetric(c.Query("metric"))
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting market distribution of %s", metric)
+
+	snapshot, err := h.loadMetricSnapshot(metric)
+	if err != nil {
+		h.logger.Errorf("Failed to get market values of %s: %v", metric, err)
+		Error(c, CodeInternalError, "获取全市场指标数据失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"date":         snapshot.date,
+		"distribution": snapshot.distribution,
+	})
+}
+
+// GetStockPercentile 获取股票的指标在全市场的百分位排名，percentile越高指标值越靠前
+func (h *Handler) GetStockPercentile(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	metric, err := model.ParseMarketMetric(c.Query("metric"))
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting %s percentile for %s", metric, tsCode)
+
+	snapshot, err := h.loadMetricSnapshot(metric)
+	if err != nil {
+		h.logger.Errorf("Failed to get market values of %s: %v", metric, err)
+		Error(c, CodeInternalError, "获取全市场指标数据失败")
+		return
+	}
+
+	value, ok := snapshot.values[tsCode]
+	if !ok {
+		Error(c, CodeNotFound, "该股票没有"+string(metric)+"指标数据")
+		return
+	}
+
+	Success(c, gin.H{
+		"code":       tsCode,
+		"metric":     metric,
+		"date":       snapshot.date,
+		"value":      value,
+		"percentile": model.PercentileRank(snapshot.sorted, value),
+		"count":      len(snapshot.sorted),
+	})
+}
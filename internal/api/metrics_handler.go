@@ -0,0 +1,20 @@
+package api
+
+import (
+	"stock/internal/collector"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpstreamStatsHandler 返回采集器上游请求统计，按请求次数降序，每个接口包含请求次数、失败率、耗时p50/p95和响应字节数
+// stats为nil（未开启upstream_stats）时enabled为false；circuits为各采集器的熔断状态，未开启circuit_breaker时为空
+func UpstreamStatsHandler(stats *collector.RequestStats, breakers map[string]*collector.CircuitBreaker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		circuits := collector.CircuitSnapshot(breakers)
+		if stats == nil {
+			Success(c, gin.H{"enabled": false, "endpoints": []collector.EndpointStats{}, "circuits": circuits})
+			return
+		}
+		Success(c, gin.H{"enabled": true, "endpoints": stats.Snapshot(), "circuits": circuits})
+	}
+}
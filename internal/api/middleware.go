@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader 传递访问令牌的备用请求头
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware 访问令牌鉴权中间件
+// 支持 Authorization: Bearer <token> 和 X-API-Key: <token> 两种方式，token为空时不做校验
+func AuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		provided := extractToken(c)
+		if provided == "" {
+			Error(c, CodeUnauthorized, "缺少访问令牌")
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			Error(c, CodeUnauthorized, "访问令牌无效")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// extractToken 从请求头中提取访问令牌
+func extractToken(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		scheme, value, found := strings.Cut(auth, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value)
+		}
+		return ""
+	}
+	return strings.TrimSpace(c.GetHeader(APIKeyHeader))
+}
+
+// maxAuditBodySize 审计记录保存的请求体最大字节数，超出部分截断
+const maxAuditBodySize = 4096
+
+// AuditMiddleware 审计中间件，对修改数据的请求（GET、HEAD、OPTIONS以外的方法）在处理完成后写入审计记录
+// 记录路由、股票代码、路径和查询参数、请求体及是否成功，鉴权失败的请求同样记录；skipPaths为不修改数据的POST接口路由
+// audit为nil时不做记录
+func AuditMiddleware(audit *service.AuditService, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if audit == nil || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// 读取请求体后放回，处理函数仍能正常绑定参数
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+
+		params := map[string]interface{}{}
+		if len(c.Params) > 0 {
+			path := make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				path[p.Key] = p.Value
+			}
+			params["path"] = path
+		}
+		if query := c.Request.URL.Query(); len(query) > 0 {
+			params["query"] = query
+		}
+		if len(body) > 0 {
+			if len(body) > maxAuditBodySize {
+				body = body[:maxAuditBodySize]
+			}
+			params["body"] = string(body)
+		}
+
+		var opErr error
+		if status := c.Writer.Status(); status >= http.StatusBadRequest {
+			opErr = fmt.Errorf("HTTP %d", status)
+		}
+
+		action := c.Request.Method + " " + c.FullPath()
+		tsCode := strings.ToUpper(c.Param("code"))
+		if _, err := audit.Record(model.AuditSourceAPI, action, tsCode, c.ClientIP(), params, opErr); err != nil {
+			logger.Errorf("保存审计记录失败: %v", err)
+		}
+	}
+}
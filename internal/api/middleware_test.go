@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAuthToken = "test-token"
+
+// newTestRouter 创建注册了全部路由的测试路由器
+func newTestRouter(token string) *gin.Engine {
+	router := gin.New()
+	RegisterRoutes(router, &Handler{logger: logrus.New()}, token)
+	return router
+}
+
+// serve 发起请求并返回HTTP状态码和解析后的响应体
+func serve(t *testing.T, router *gin.Engine, method, target string, headers map[string]string) (int, Response) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestAuthMiddleware_ProtectedRoutes(t *testing.T) {
+	router := newTestRouter(testAuthToken)
+
+	protected := []struct {
+		method string
+		target string
+	}{
+		{http.MethodPost, "/api/v1/stocks/sync"},
+		{http.MethodPost, "/api/v1/stocks/refresh"},
+		{http.MethodPost, "/api/v1/stocks/000001.SZ/sync"},
+		{http.MethodPost, "/api/v1/stocks/000001.SZ/kline/refresh"},
+		{http.MethodPost, "/api/v1/stocks/000001.SZ/fundamentals/refresh"},
+		{http.MethodPost, "/api/v1/tasks/task-1/cancel"},
+		{http.MethodPost, "/api/v1/watchlists"},
+		{http.MethodPost, "/api/v1/watchlists/1/sync"},
+		{http.MethodPost, "/api/v1/groups"},
+		{http.MethodPut, "/api/v1/groups/1"},
+		{http.MethodDelete, "/api/v1/groups/1"},
+		{http.MethodPost, "/api/v1/groups/1/constituents"},
+		{http.MethodPost, "/api/v1/groups/1/sync"},
+		{http.MethodPost, "/api/v1/admin/stocks/sync"},
+		{http.MethodPost, "/api/v1/admin/tasks/cancel-all"},
+		{http.MethodGet, "/api/v1/admin/job-runs"},
+		{http.MethodGet, "/api/v1/admin/audit-logs"},
+		{http.MethodGet, "/api/v1/admin/stocks/priority"},
+		{http.MethodPut, "/api/v1/admin/stocks/priority"},
+	}
+
+	for _, route := range protected {
+		status, resp := serve(t, router, route.method, route.target, nil)
+		assert.Equal(t, http.StatusUnauthorized, status, "%s %s", route.method, route.target)
+		assert.Equal(t, CodeUnauthorized, resp.Code)
+
+		status, resp = serve(t, router, route.method, route.target, map[string]string{
+			"Authorization": "Bearer wrong-token",
+		})
+		assert.Equal(t, http.StatusUnauthorized, status, "%s %s", route.method, route.target)
+		assert.Equal(t, CodeUnauthorized, resp.Code)
+	}
+}
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	router := newTestRouter(testAuthToken)
+
+	// 通过鉴权后进入处理函数，由处理函数返回参数校验错误
+	headers := []map[string]string{
+		{"Authorization": "Bearer " + testAuthToken},
+		{"Authorization": "bearer " + testAuthToken},
+		{APIKeyHeader: testAuthToken},
+	}
+	for _, h := range headers {
+		status, resp := serve(t, router, http.MethodPost, "/api/v1/stocks/000001/sync", h)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, CodeInvalidTsCode, resp.Code)
+	}
+}
+
+func TestAuthMiddleware_PublicRoutes(t *testing.T) {
+	router := newTestRouter(testAuthToken)
+
+	public := []string{
+		"/api/v1/stocks/000001",
+		"/api/v1/stocks/000001/kline",
+		"/api/v1/stocks/000001/kline/range",
+		"/api/v1/realtime",
+		"/api/v1/index/000300/kline",
+	}
+
+	for _, target := range public {
+		status, resp := serve(t, router, http.MethodGet, target, nil)
+		assert.NotEqual(t, http.StatusUnauthorized, status, target)
+		assert.NotEqual(t, CodeUnauthorized, resp.Code, target)
+	}
+}
+
+func TestAuthMiddleware_Disabled(t *testing.T) {
+	router := newTestRouter("")
+
+	status, resp := serve(t, router, http.MethodPost, "/api/v1/stocks/000001/sync", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, CodeInvalidTsCode, resp.Code)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNorthbound 获取股票北向资金（沪深港通）持股历史，按持股日期升序
+// 日期区间参数与K线查询接口一致；shares_change为区间内持股数量的变化，正数表示北向资金净增持
+func (h *Handler) GetNorthbound(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	startDate, endDate, _, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.Query("days"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting northbound holdings for %s (%s ~ %s)", tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+
+	start := startDate.Year()*10000 + int(startDate.Month())*100 + startDate.Day()
+	end := endDate.Year()*10000 + int(endDate.Month())*100 + endDate.Day()
+	holdings, err := repository.NewNorthbound(h.db).GetByTsCode(tsCode, start, end)
+	if err != nil {
+		h.logger.Errorf("Failed to get northbound holdings from database: %v", err)
+		Error(c, CodeInternalError, "获取北向持股数据失败")
+		return
+	}
+
+	var sharesChange int64
+	if len(holdings) > 1 {
+		sharesChange = holdings[len(holdings)-1].HoldShares - holdings[0].HoldShares
+	}
+
+	Success(c, gin.H{
+		"code":          tsCode,
+		"start":         startDate.Format("2006-01-02"),
+		"end":           endDate.Format("2006-01-02"),
+		"count":         len(holdings),
+		"shares_change": sharesChange,
+		"holdings":      holdings,
+	})
+}
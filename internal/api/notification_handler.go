@@ -1,7 +1,6 @@
 package api
 
 import (
-	"net/http"
 	"time"
 
 	"stock/internal/model"
@@ -32,7 +31,7 @@ func (h *NotificationHandler) SendTextMessage(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, http.StatusBadRequest, "参数错误")
+		Error(c, CodeInvalidParam, "参数错误")
 		return
 	}
 
@@ -49,13 +48,13 @@ func (h *NotificationHandler) SendTextMessage(c *gin.Context) {
 		}
 
 		if err := h.service.SendToBotType(ctx, botType, message); err != nil {
-			Error(c, http.StatusInternalServerError, "发送消息失败")
+			Error(c, CodeInternalError, "发送消息失败")
 			return
 		}
 	} else {
 		// 发送到所有机器人
 		if err := h.service.SendTextMessage(ctx, req.Content, req.AtMobiles, req.AtAll); err != nil {
-			Error(c, http.StatusInternalServerError, "发送消息失败")
+			Error(c, CodeInternalError, "发送消息失败")
 			return
 		}
 	}
@@ -72,14 +71,14 @@ func (h *NotificationHandler) SendMarkdownMessage(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, http.StatusBadRequest, "参数错误")
+		Error(c, CodeInvalidParam, "参数错误")
 		return
 	}
 
 	ctx := c.Request.Context()
 
 	if err := h.service.SendMarkdownMessage(ctx, req.Title, req.Content); err != nil {
-		Error(c, http.StatusInternalServerError, "发送Markdown消息失败")
+		Error(c, CodeInternalError, "发送Markdown消息失败")
 		return
 	}
 
@@ -97,7 +96,7 @@ func (h *NotificationHandler) SendStockAlert(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, http.StatusBadRequest, "参数错误")
+		Error(c, CodeInvalidParam, "参数错误")
 		return
 	}
 
@@ -117,7 +116,7 @@ func (h *NotificationHandler) SendStockAlert(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	if err := h.service.SendStockAlert(ctx, template); err != nil {
-		Error(c, http.StatusInternalServerError, "发送股票提醒失败")
+		Error(c, CodeInternalError, "发送股票提醒失败")
 		return
 	}
 
@@ -134,7 +133,7 @@ func (h *NotificationHandler) SendSystemNotification(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		Error(c, http.StatusBadRequest, "参数错误")
+		Error(c, CodeInvalidParam, "参数错误")
 		return
 	}
 
@@ -152,7 +151,7 @@ func (h *NotificationHandler) SendSystemNotification(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	if err := h.service.SendSystemNotification(ctx, template); err != nil {
-		Error(c, http.StatusInternalServerError, "发送系统通知失败")
+		Error(c, CodeInternalError, "发送系统通知失败")
 		return
 	}
 
@@ -176,7 +175,7 @@ func (h *NotificationHandler) GetBotStatus(c *gin.Context) {
 func (h *NotificationHandler) SendHealthCheck(c *gin.Context) {
 	ctx := c.Request.Context()
 	if err := h.service.SendHealthCheck(ctx); err != nil {
-		Error(c, http.StatusInternalServerError, "发送健康检查失败")
+		Error(c, CodeInternalError, "发送健康检查失败")
 		return
 	}
 
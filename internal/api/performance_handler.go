@@ -1,17 +1,26 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"stock/internal/model"
+	"stock/internal/repository"
 	"stock/internal/service"
 	"stock/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 业绩排行接口的返回数量
+const (
+	defaultTopPerformersLimit = 10  // 默认返回前10名
+	maxTopPerformersLimit     = 100 // 单次最多返回100名
+)
+
 // PerformanceHandler 业绩报表API处理器
 type PerformanceHandler struct {
 	service *service.PerformanceService
@@ -38,7 +47,7 @@ func NewPerformanceHandler(service *service.PerformanceService) *PerformanceHand
 func (h *PerformanceHandler) GetPerformanceReports(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -47,7 +56,7 @@ func (h *PerformanceHandler) GetPerformanceReports(c *gin.Context) {
 
 	reports, err := h.service.GetPerformanceReports(c.Request.Context(), tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取业绩报表数据失败")
+		Error(c, CodeInternalError, "获取业绩报表数据失败")
 		return
 	}
 
@@ -68,7 +77,7 @@ func (h *PerformanceHandler) GetPerformanceReports(c *gin.Context) {
 func (h *PerformanceHandler) GetLatestPerformanceReport(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -77,12 +86,12 @@ func (h *PerformanceHandler) GetLatestPerformanceReport(c *gin.Context) {
 
 	report, err := h.service.GetLatestPerformanceReport(c.Request.Context(), tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取最新业绩报表数据失败")
+		Error(c, CodeInternalError, "获取最新业绩报表数据失败")
 		return
 	}
 
 	if report == nil {
-		Error(c, http.StatusNotFound, "未找到业绩报表数据")
+		Error(c, CodeNotFound, "未找到业绩报表数据")
 		return
 	}
 
@@ -103,7 +112,7 @@ func (h *PerformanceHandler) GetLatestPerformanceReport(c *gin.Context) {
 func (h *PerformanceHandler) SyncPerformanceReports(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -112,7 +121,7 @@ func (h *PerformanceHandler) SyncPerformanceReports(c *gin.Context) {
 
 	err := h.service.SyncPerformanceReports(c.Request.Context(), tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "同步业绩报表数据失败")
+		Error(c, CodeInternalError, "同步业绩报表数据失败")
 		return
 	}
 
@@ -131,7 +140,7 @@ func (h *PerformanceHandler) SyncPerformanceReports(c *gin.Context) {
 func (h *PerformanceHandler) SyncAllPerformanceReports(c *gin.Context) {
 	err := h.service.SyncAllStocksPerformanceReports(c.Request.Context())
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "同步所有业绩报表数据失败")
+		Error(c, CodeInternalError, "同步所有业绩报表数据失败")
 		return
 	}
 
@@ -154,7 +163,7 @@ func (h *PerformanceHandler) SyncAllPerformanceReports(c *gin.Context) {
 func (h *PerformanceHandler) GetPerformanceReportsByDateRange(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -162,19 +171,19 @@ func (h *PerformanceHandler) GetPerformanceReportsByDateRange(c *gin.Context) {
 	endDateStr := c.Query("end_date")
 
 	if startDateStr == "" || endDateStr == "" {
-		Error(c, http.StatusBadRequest, "开始日期和结束日期不能为空")
+		Error(c, CodeInvalidParam, "开始日期和结束日期不能为空")
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		Error(c, http.StatusBadRequest, "开始日期格式错误")
+		Error(c, CodeInvalidParam, "开始日期格式错误")
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		Error(c, http.StatusBadRequest, "结束日期格式错误")
+		Error(c, CodeInvalidParam, "结束日期格式错误")
 		return
 	}
 
@@ -183,7 +192,7 @@ func (h *PerformanceHandler) GetPerformanceReportsByDateRange(c *gin.Context) {
 
 	reports, err := h.service.GetPerformanceReportsByDateRange(c.Request.Context(), tsCode, startDate, endDate)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取业绩报表数据失败")
+		Error(c, CodeInternalError, "获取业绩报表数据失败")
 		return
 	}
 
@@ -197,27 +206,43 @@ func (h *PerformanceHandler) GetPerformanceReportsByDateRange(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param limit query int false "返回数量限制" default(10)
-// @Param order_by query string false "排序字段" default(eps) Enums(eps,roe,roa,gross_margin,dividend_yield,revenue,net_profit)
+// @Param order_by query string false "排序字段" default(eps) Enums(eps,weight_eps,roe,bvps,revenue,revenue_yoy,net_profit,net_profit_yoy,gross_margin,dividend_yield)
+// @Param order query string false "排序方向" default(desc) Enums(asc,desc)
 // @Success 200 {object} Response{data=[]model.PerformanceReport}
 // @Failure 400 {object} Response
 // @Failure 500 {object} Response
 // @Router /api/v1/performance/top-performers [get]
 func (h *PerformanceHandler) GetTopPerformers(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	orderBy := c.DefaultQuery("order_by", "eps")
+	limitStr := c.DefaultQuery("limit", strconv.Itoa(defaultTopPerformersLimit))
+	orderBy := c.DefaultQuery("order_by", repository.DefaultTopPerformersOrder)
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		limit = 10
+		limit = defaultTopPerformersLimit
+	}
+
+	if limit > maxTopPerformersLimit {
+		limit = maxTopPerformersLimit // 限制最大返回数量
 	}
 
-	if limit > 100 {
-		limit = 100 // 限制最大返回数量
+	if err := repository.ValidateTopPerformersOrder(orderBy); err != nil {
+		Error(c, CodeInvalidParam, fmt.Sprintf("order_by参数错误，可选值：%s", strings.Join(repository.TopPerformerOrderFields(), "、")))
+		return
+	}
+
+	asc := false
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		asc = true
+	case "desc":
+	default:
+		Error(c, CodeInvalidParam, "order参数错误，可选值：asc、desc")
+		return
 	}
 
-	reports, err := h.service.GetTopPerformers(c.Request.Context(), limit, orderBy)
+	reports, err := h.service.GetTopPerformers(c.Request.Context(), limit, orderBy, asc)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取业绩排行数据失败")
+		Error(c, CodeInternalError, "获取业绩排行数据失败")
 		return
 	}
 
@@ -236,7 +261,7 @@ func (h *PerformanceHandler) GetTopPerformers(c *gin.Context) {
 func (h *PerformanceHandler) GetPerformanceStatistics(c *gin.Context) {
 	stats, err := h.service.GetStatistics(c.Request.Context())
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取统计信息失败")
+		Error(c, CodeInternalError, "获取统计信息失败")
 		return
 	}
 
@@ -257,18 +282,18 @@ func (h *PerformanceHandler) GetPerformanceStatistics(c *gin.Context) {
 func (h *PerformanceHandler) CreatePerformanceReport(c *gin.Context) {
 	var report model.PerformanceReport
 	if err := c.ShouldBindJSON(&report); err != nil {
-		Error(c, http.StatusBadRequest, "请求参数错误")
+		Error(c, CodeInvalidParam, "请求参数错误")
 		return
 	}
 
 	err := h.service.CreatePerformanceReport(c.Request.Context(), &report)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "创建业绩报表记录失败")
+		Error(c, CodeInternalError, "创建业绩报表记录失败")
 		return
 	}
 
 	c.JSON(http.StatusCreated, Response{
-		Code:    0,
+		Code:    CodeSuccess,
 		Message: "创建成功",
 		Data:    report,
 	})
@@ -291,14 +316,14 @@ func (h *PerformanceHandler) UpdatePerformanceReport(c *gin.Context) {
 
 	var report model.PerformanceReport
 	if err := c.ShouldBindJSON(&report); err != nil {
-		Error(c, http.StatusBadRequest, "请求参数错误")
+		Error(c, CodeInvalidParam, "请求参数错误")
 		return
 	}
 
 	report.TsCode = code
 	err := h.service.UpdatePerformanceReport(c.Request.Context(), &report)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "更新业绩报表记录失败")
+		Error(c, CodeInternalError, "更新业绩报表记录失败")
 		return
 	}
 
@@ -319,7 +344,7 @@ func (h *PerformanceHandler) UpdatePerformanceReport(c *gin.Context) {
 func (h *PerformanceHandler) DeletePerformanceReports(c *gin.Context) {
 	code := c.Param("code")
 	if code == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -328,7 +353,7 @@ func (h *PerformanceHandler) DeletePerformanceReports(c *gin.Context) {
 
 	err := h.service.DeletePerformanceReports(c.Request.Context(), tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "删除业绩报表数据失败")
+		Error(c, CodeInternalError, "删除业绩报表数据失败")
 		return
 	}
 
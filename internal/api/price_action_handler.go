@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minPriceActionBars 计算连涨连跌和跳空至少需要的K线数量
+const minPriceActionBars = 2
+
+// PriceActionPoint 单个交易日的连涨连跌和跳空
+type PriceActionPoint struct {
+	TradeDate int                    `json:"trade_date"` // 交易日期，YYYYMMDD格式
+	Close     float64                `json:"close"`      // 收盘价
+	Streak    int                    `json:"streak"`     // 连涨天数为正、连跌天数为负，收平为0
+	Gap       indicator.GapDirection `json:"gap"`        // 相对前一日的跳空方向，up或down，未跳空为空
+}
+
+// PriceActionResult 连涨连跌和跳空计算结果
+type PriceActionResult struct {
+	TsCode   string             `json:"ts_code"`   // 股票代码
+	Bars     int                `json:"bars"`      // 参与计算的K线数量
+	Streak   int                `json:"streak"`    // 截至最新交易日的连涨连跌天数
+	GapUps   int                `json:"gap_ups"`   // 区间内向上跳空次数
+	GapDowns int                `json:"gap_downs"` // 区间内向下跳空次数
+	Points   []PriceActionPoint `json:"points"`    // 按交易日期升序排列的序列
+}
+
+// analyzePriceAction 按交易日期升序计算连涨连跌天数和跳空，K线不足2根时返回错误
+func analyzePriceAction(tsCode string, data []model.DailyData) (*PriceActionResult, error) {
+	if len(data) < minPriceActionBars {
+		return nil, &indicator.InsufficientHistoryError{Indicator: "price_action", Need: minPriceActionBars, Have: len(data)}
+	}
+
+	sorted := make([]model.DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TradeDate < sorted[j].TradeDate
+	})
+
+	highs := make([]float64, len(sorted))
+	lows := make([]float64, len(sorted))
+	closes := make([]float64, len(sorted))
+	for i, d := range sorted {
+		highs[i], lows[i], closes[i] = d.High, d.Low, d.Close
+	}
+
+	streaks := indicator.Streaks(closes)
+	gaps := indicator.Gaps(highs, lows)
+
+	result := &PriceActionResult{
+		TsCode: tsCode,
+		Bars:   len(sorted),
+		Streak: streaks[len(streaks)-1],
+		Points: make([]PriceActionPoint, len(sorted)),
+	}
+	for i, d := range sorted {
+		result.Points[i] = PriceActionPoint{TradeDate: d.TradeDate, Close: d.Close, Streak: streaks[i], Gap: gaps[i]}
+		switch gaps[i] {
+		case indicator.GapUp:
+			result.GapUps++
+		case indicator.GapDown:
+			result.GapDowns++
+		}
+	}
+	return result, nil
+}
+
+// GetPriceAction 获取连涨连跌天数和向上、向下跳空
+func (h *Handler) GetPriceAction(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	bars, err := strconv.Atoi(c.DefaultQuery("bars", strconv.Itoa(defaultSignalBars)))
+	if err != nil || bars < minPriceActionBars || bars > maxSignalBars {
+		Error(c, CodeInvalidParam, fmt.Sprintf("bars参数错误，应为%d-%d之间的整数", minPriceActionBars, maxSignalBars))
+		return
+	}
+
+	h.logger.Infof("API: Getting price action for %s, bars: %d", tsCode, bars)
+
+	// 取最近bars根日K线，跳过已标记的问题数据区间
+	data, err := repository.NewDailyData(h.db).GetAnalysisDailyData(tsCode, time.Time{}, time.Time{}, bars)
+	if err != nil {
+		h.logger.Errorf("Failed to get daily data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(data) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	result, err := analyzePriceAction(tsCode, data)
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	Success(c, result)
+}
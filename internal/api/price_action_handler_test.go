@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+
+	"stock/internal/indicator"
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzePriceAction(t *testing.T) {
+	// 按交易日期倒序传入，与数据库查询结果一致
+	data := []model.DailyData{
+		{TradeDate: 20240108, High: 10.9, Low: 10.5, Close: 10.6},
+		{TradeDate: 20240105, High: 11.0, Low: 10.7, Close: 10.8},
+		{TradeDate: 20240104, High: 11.5, Low: 11.1, Close: 11.2},
+		{TradeDate: 20240103, High: 11.0, Low: 10.6, Close: 10.9},
+		{TradeDate: 20240102, High: 10.5, Low: 10.0, Close: 10.2},
+	}
+
+	result, err := analyzePriceAction("000001.SZ", data)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Bars)
+	assert.Equal(t, -2, result.Streak)
+	assert.Equal(t, 2, result.GapUps)
+	assert.Equal(t, 1, result.GapDowns)
+
+	require.Len(t, result.Points, 5)
+	assert.Equal(t, PriceActionPoint{TradeDate: 20240102, Close: 10.2}, result.Points[0])
+	assert.Equal(t, PriceActionPoint{TradeDate: 20240103, Close: 10.9, Streak: 1, Gap: indicator.GapUp}, result.Points[1])
+	assert.Equal(t, PriceActionPoint{TradeDate: 20240104, Close: 11.2, Streak: 2, Gap: indicator.GapUp}, result.Points[2])
+	assert.Equal(t, PriceActionPoint{TradeDate: 20240105, Close: 10.8, Streak: -1, Gap: indicator.GapDown}, result.Points[3])
+	assert.Equal(t, PriceActionPoint{TradeDate: 20240108, Close: 10.6, Streak: -2}, result.Points[4])
+
+	_, err = analyzePriceAction("000001.SZ", data[:1])
+	assert.EqualError(t, err, "insufficient history: need 2 bars, have 1")
+}
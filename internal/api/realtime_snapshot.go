@@ -0,0 +1,36 @@
+package api
+
+import (
+	"stock/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 实时行情的数据来源
+const (
+	realtimeSourceLive     = "live"     // 请求数据源获取实时行情
+	realtimeSourceSnapshot = "snapshot" // 返回最后一次同步保存的行情快照
+)
+
+// realtimeSnapshotReader 实时行情快照的读取接口
+type realtimeSnapshotReader interface {
+	GetByTsCodes(tsCodes []string) ([]model.RealtimeSnapshot, error)
+}
+
+// respondRealtimeSnapshot 返回指定股票最后一次同步保存的行情快照，不请求数据源
+// 快照由worker同步实时行情时写入（需开启worker.realtime_snapshot），没有快照的股票不在结果中
+func (h *Handler) respondRealtimeSnapshot(c *gin.Context, tsCodes []string) {
+	snapshots, err := h.realtimeSnapshots.GetByTsCodes(tsCodes)
+	if err != nil {
+		h.logger.Errorf("Failed to get realtime snapshots: %v", err)
+		Error(c, CodeInternalError, "获取实时行情快照失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"codes":    tsCodes,
+		"count":    len(snapshots),
+		"source":   realtimeSourceSnapshot,
+		"realtime": snapshots,
+	})
+}
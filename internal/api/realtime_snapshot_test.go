@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySnapshotStore 内存实现的实时行情快照存储
+type memorySnapshotStore struct {
+	snapshots map[string]model.RealtimeSnapshot
+	requested []string
+}
+
+func (s *memorySnapshotStore) GetByTsCodes(tsCodes []string) ([]model.RealtimeSnapshot, error) {
+	s.requested = tsCodes
+	var result []model.RealtimeSnapshot
+	for _, tsCode := range tsCodes {
+		if snapshot, ok := s.snapshots[tsCode]; ok {
+			result = append(result, snapshot)
+		}
+	}
+	return result, nil
+}
+
+func TestGetRealtimeData_ServesStoredSnapshot(t *testing.T) {
+	fetchedAt := time.Date(2025, 9, 10, 15, 0, 5, 0, time.UTC)
+	store := &memorySnapshotStore{snapshots: map[string]model.RealtimeSnapshot{
+		"600519.SH": model.NewRealtimeSnapshot(model.DailyData{
+			TsCode: "600519.SH", TradeDate: 20250910, Open: 1480, High: 1495.5, Low: 1475, Close: 1490.2, Volume: 3200000,
+		}, fetchedAt),
+	}}
+	// 未配置数据源，快照查询不能请求数据源
+	h := &Handler{logger: logrus.New(), realtimeSnapshots: store}
+
+	status, resp := performRequest(t, h.GetRealtimeData, http.MethodGet,
+		"/realtime?codes=600519.sh,000001.SZ&source=snapshot", "", nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, CodeSuccess, resp.Code)
+	assert.Equal(t, []string{"600519.SH", "000001.SZ"}, store.requested)
+
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, "snapshot", data["source"])
+	assert.EqualValues(t, 1, data["count"])
+	realtime := data["realtime"].([]interface{})
+	require.Len(t, realtime, 1)
+	snapshot := realtime[0].(map[string]interface{})
+	assert.Equal(t, "600519.SH", snapshot["ts_code"])
+	assert.Equal(t, 1490.2, snapshot["close"])
+	assert.Equal(t, fetchedAt.Format(time.RFC3339), snapshot["fetched_at"])
+}
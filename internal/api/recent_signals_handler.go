@@ -0,0 +1,91 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 全市场信号查询返回数量的默认值和上限
+const (
+	defaultRecentSignalsLimit = 100
+	maxRecentSignalsLimit     = 1000
+)
+
+// recentSignalsCacheTTL 全市场信号查询缓存的有效期
+// 全市场信号需要读取当日和上一交易日的全部指标，同一日期和类型的重复请求直接返回缓存
+const recentSignalsCacheTTL = 10 * time.Minute
+
+// recentSignalsKey 生成缓存键，信号类型按请求顺序参与，顺序不同时结果中信号的顺序也不同
+func recentSignalsKey(tradeDate int, signals []model.IndicatorSignal) string {
+	types := make([]string, len(signals))
+	for i, signal := range signals {
+		types[i] = string(signal)
+	}
+	return strconv.Itoa(tradeDate) + "|" + strings.Join(types, ",")
+}
+
+// GetRecentSignals 获取全市场在指定交易日出现指定技术指标信号的股票
+// date为交易日期（YYYYMMDD或YYYY-MM-DD），为空时使用已保存指标的最新交易日；
+// type为逗号分隔的信号类型，默认golden_cross，出现任一信号的股票都会返回；limit限制返回数量
+func (h *Handler) GetRecentSignals(c *gin.Context) {
+	signals, err := model.ParseIndicatorSignals(c.DefaultQuery("type", string(model.SignalGoldenCross)))
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	limit := defaultRecentSignalsLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxRecentSignalsLimit {
+			Error(c, CodeInvalidParam, "limit应为1到1000之间的整数")
+			return
+		}
+	}
+
+	var tradeDate int
+	if value := c.Query("date"); value != "" {
+		date, err := parseTradeDate(value, time.Now())
+		if err != nil {
+			Error(c, CodeInvalidParam, err.Error())
+			return
+		}
+		tradeDate = date.Year()*10000 + int(date.Month())*100 + date.Day()
+	} else {
+		tradeDate, err = h.indicatorService.GetLatestIndicatorDate()
+		if err != nil {
+			h.logger.Errorf("Failed to get latest indicator date: %v", err)
+			Error(c, CodeInternalError, "获取最新指标日期失败")
+			return
+		}
+	}
+
+	h.logger.Infof("API: Getting recent signals %v on %d", signals, tradeDate)
+
+	stocks, err := h.signalsCache.GetOrCompute(recentSignalsKey(tradeDate, signals), func() ([]model.StockIndicatorSignal, error) {
+		return h.indicatorService.GetMarketSignals(tradeDate, signals)
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to get market signals: %v", err)
+		Error(c, CodeInternalError, "获取技术指标信号失败")
+		return
+	}
+
+	total := len(stocks)
+	if total > limit {
+		stocks = stocks[:limit]
+	}
+
+	Success(c, gin.H{
+		"date":   tradeDate,
+		"types":  signals,
+		"total":  total,
+		"count":  len(stocks),
+		"stocks": stocks,
+	})
+}
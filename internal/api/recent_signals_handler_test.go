@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentSignalsKey(t *testing.T) {
+	golden := []model.IndicatorSignal{model.SignalGoldenCross}
+	both := []model.IndicatorSignal{model.SignalGoldenCross, model.SignalDeathCross}
+
+	assert.Equal(t, "20250930|golden_cross", recentSignalsKey(20250930, golden))
+	// 日期或信号类型不同时分别缓存
+	assert.NotEqual(t, recentSignalsKey(20250930, golden), recentSignalsKey(20250929, golden))
+	assert.NotEqual(t, recentSignalsKey(20250930, golden), recentSignalsKey(20250930, both))
+}
+
+func TestGetRecentSignals_Cached(t *testing.T) {
+	golden := []model.IndicatorSignal{model.SignalGoldenCross}
+	h := &Handler{logger: logrus.New(), signalsCache: utils.NewTTLCache[string, []model.StockIndicatorSignal](time.Minute)}
+	h.signalsCache.Set(recentSignalsKey(20250930, golden), []model.StockIndicatorSignal{
+		{TsCode: "000001.SZ", Name: "平安银行", TradeDate: 20250930, Signals: golden},
+		{TsCode: "600519.SH", Name: "贵州茅台", TradeDate: 20250930, Signals: golden},
+	})
+
+	status, resp := performRequest(t, h.GetRecentSignals, http.MethodGet,
+		"/signals/recent?date=2025-09-30&type=golden_cross&limit=1", "", nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, CodeSuccess, resp.Code)
+
+	data, ok := resp.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(20250930), data["date"])
+	assert.Equal(t, float64(2), data["total"])
+	assert.Equal(t, float64(1), data["count"])
+	stocks, ok := data["stocks"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, stocks, 1)
+	assert.Equal(t, "000001.SZ", stocks[0].(map[string]interface{})["ts_code"])
+}
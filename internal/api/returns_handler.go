@@ -0,0 +1,108 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBenchmark 默认的收益对比基准：沪深300
+const defaultBenchmark = "000300.SH"
+
+// maxReturnWindow 收益统计窗口的最大交易日数
+const maxReturnWindow = 1000
+
+// parseReturnWindows 解析逗号分隔的收益统计窗口，为空时使用默认窗口
+func parseReturnWindows(param string) ([]int, bool) {
+	if param == "" {
+		return indicator.DefaultReturnWindows, true
+	}
+
+	seen := make(map[int]bool)
+	windows := make([]int, 0)
+	for _, s := range strings.Split(param, ",") {
+		window, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || window < 1 || window > maxReturnWindow {
+			return nil, false
+		}
+		if !seen[window] {
+			seen[window] = true
+			windows = append(windows, window)
+		}
+	}
+	return windows, true
+}
+
+// GetRelativeReturns 获取个股相对基准指数的区间收益和超额收益
+func (h *Handler) GetRelativeReturns(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	benchmark := strings.ToUpper(c.DefaultQuery("benchmark", defaultBenchmark))
+	if !strings.Contains(benchmark, ".") {
+		Error(c, CodeInvalidParam, "基准指数代码格式错误，应为：000300.SH 或 399006.SZ")
+		return
+	}
+
+	windows, ok := parseReturnWindows(c.Query("windows"))
+	if !ok {
+		Error(c, CodeInvalidParam, "windows参数格式错误，应为逗号分隔的1-1000之间的交易日数")
+		return
+	}
+
+	maxWindow := 0
+	for _, w := range windows {
+		if w > maxWindow {
+			maxWindow = w
+		}
+	}
+
+	// 按约250个交易日/年换算自然日，并预留节假日和停牌的余量
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -(maxWindow*3/2 + 30))
+
+	h.logger.Infof("API: Getting relative returns for %s against %s, windows: %v", tsCode, benchmark, windows)
+
+	stockData, err := h.klineService.GetKLineData(tsCode, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get K-line data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(stockData) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	benchmarkData, err := h.indexService.GetIndexDaily(benchmark, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get benchmark index data: %v", err)
+		Error(c, CodeDataSourceError, "获取基准指数数据失败")
+		return
+	}
+	if len(benchmarkData) == 0 {
+		Error(c, CodeNotFound, "没有该基准指数的日线数据")
+		return
+	}
+
+	Success(c, gin.H{
+		"code":      tsCode,
+		"benchmark": benchmark,
+		"windows":   windows,
+		"returns":   indicator.RelativeReturns(stockData, benchmarkData, windows),
+	})
+}
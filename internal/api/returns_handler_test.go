@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"stock/internal/indicator"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReturnWindows(t *testing.T) {
+	windows, ok := parseReturnWindows("")
+	assert.True(t, ok)
+	assert.Equal(t, indicator.DefaultReturnWindows, windows)
+
+	windows, ok = parseReturnWindows("20, 5,20")
+	assert.True(t, ok)
+	assert.Equal(t, []int{20, 5}, windows)
+
+	for _, param := range []string{"abc", "0", "1001", "5,"} {
+		_, ok := parseReturnWindows(param)
+		assert.False(t, ok, param)
+	}
+}
+
+func TestGetRelativeReturns_Routed(t *testing.T) {
+	router := newTestRouter("")
+
+	tests := []struct {
+		name   string
+		target string
+		code   int
+	}{
+		{"代码格式错误", "/api/v1/stocks/600519/relative-returns", CodeInvalidTsCode},
+		{"基准格式错误", "/api/v1/stocks/600519.SH/relative-returns?benchmark=000300", CodeInvalidParam},
+		{"窗口参数错误", "/api/v1/stocks/600519.SH/relative-returns?windows=5,abc", CodeInvalidParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(t, router, http.MethodGet, tt.target, nil)
+			assert.Equal(t, tt.code, resp.Code)
+			assert.Equal(t, HTTPStatus(tt.code), status)
+		})
+	}
+}
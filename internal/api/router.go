@@ -0,0 +1,146 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册API路由
+// 只读的股票/指数/K线/任务查询接口对外公开；同步触发、任务取消和管理类接口需要通过authToken鉴权，authToken为空时不做校验
+// 修改数据的请求都会写入审计记录
+func RegisterRoutes(router gin.IRouter, h *Handler, authToken string) {
+	auth := AuthMiddleware(authToken)
+
+	v1 := router.Group("/api/v1", AuditMiddleware(h.auditService, "/api/v1/realtime/batch"))
+	{
+		// 股票相关接口
+		stocks := v1.Group("/stocks")
+		{
+			stocks.GET("/", h.GetStockList)                                       // 获取股票列表
+			stocks.GET("/search", h.SearchStocks)                                 // 搜索股票
+			stocks.GET("/stats", h.GetStockStats)                                 // 获取股票统计信息
+			stocks.GET("/identity-changes", h.GetStockIdentityChanges)            // 获取股票更名、代码复用记录
+			stocks.GET("/batch", h.GetBatchLatestBars)                            // 批量获取最新日K线
+			stocks.GET("/:code", h.GetStockDetail)                                // 获取股票详情
+			stocks.GET("/:code/kline", h.GetKLineData)                            // 获取K线数据
+			stocks.GET("/:code/kline/range", h.GetKLineDataRange)                 // 获取K线数据范围
+			stocks.GET("/:code/kline/freshness", h.CheckKLineDataFreshness)       // 检查K线数据新鲜度
+			stocks.GET("/:code/kline/anomalies", h.DetectPriceAnomalies)          // 检查K线数据复权异常
+			stocks.GET("/:code/kline/metrics", h.GetDailyMetrics)                 // 获取每日换手率和振幅
+			stocks.GET("/:code/trading-days", h.GetTradingDays)                   // 获取有日K线数据的交易日期
+			stocks.GET("/:code/limit-days", h.GetLimitDays)                       // 获取涨停、跌停交易日
+			stocks.GET("/:code/export.json", h.ExportStockData)                   // 导出股票完整数据包
+			stocks.GET("/:code/northbound", h.GetNorthbound)                      // 获取北向资金持股历史
+			stocks.GET("/:code/performance", h.GetPerformanceReports)             // 获取业绩报表数据
+			stocks.GET("/:code/performance/latest", h.GetLatestPerformanceReport) // 获取最新业绩报表数据
+			stocks.GET("/:code/valuation", h.GetStockValuation)                   // 获取市盈率TTM和市净率
+			stocks.GET("/:code/score", h.GetStockScore)                           // 获取综合评分
+			stocks.GET("/:code/percentile", h.GetStockPercentile)                 // 获取指标在全市场的百分位排名
+			stocks.GET("/:code/crossovers", h.GetMACrossovers)                    // 获取均线金叉、死叉记录
+			stocks.GET("/:code/relative-returns", h.GetRelativeReturns)           // 获取相对基准指数的区间收益和超额收益
+
+			stocks.POST("/sync", auth, h.SyncAllStocksAsync)                        // 异步同步全量股票
+			stocks.POST("/refresh", auth, h.RefreshStockList)                       // 刷新股票列表缓存
+			stocks.POST("/:code/sync", auth, h.SyncSingleStockAsync)                // 异步同步单只股票
+			stocks.POST("/:code/kline/refresh", auth, h.RefreshKLineData)           // 从数据源刷新K线数据
+			stocks.POST("/:code/fundamentals/refresh", auth, h.RefreshFundamentals) // 异步刷新业绩报表和股东户数
+		}
+
+		// 指数相关接口
+		index := v1.Group("/index")
+		{
+			index.GET("", h.GetIndexList)              // 获取指数列表
+			index.GET("/:code/kline", h.GetIndexKLine) // 获取指数K线数据
+		}
+
+		// 指标分析接口
+		analysis := v1.Group("/analysis")
+		{
+			analysis.GET("/signals/:code", h.GetComplexSignals)   // 获取复杂指标信号
+			analysis.GET("/volatility/:code", h.GetVolatility)    // 获取ATR和收益率波动率
+			analysis.GET("/price-action/:code", h.GetPriceAction) // 获取连涨连跌天数和跳空
+			analysis.GET("/scores/ranking", h.GetScoreRanking)    // 获取综合评分排名
+		}
+
+		// 市场行情接口
+		market := v1.Group("/market")
+		{
+			market.GET("/limit-up", h.GetMarketLimitUp)          // 获取全市场指定交易日涨停的股票
+			market.GET("/distribution", h.GetMetricDistribution) // 获取指标在全市场的分布
+		}
+
+		// 技术指标信号接口
+		signals := v1.Group("/signals")
+		{
+			signals.GET("/recent", h.GetRecentSignals) // 获取全市场指定交易日出现信号的股票
+		}
+
+		// 选股接口
+		v1.GET("/selection/strategies", h.GetSelectionStrategies)         // 获取已注册的选股策略
+		v1.GET("/screener/fundamental", h.ScreenFundamental)              // 基本面选股
+		v1.GET("/screener/valuation", h.ScreenValuation)                  // 按市盈率TTM、市净率选股
+		v1.GET("/screener/results/:id/explain", h.ExplainSelectionResult) // 选股结果分因子解释
+
+		// 实时数据接口
+		v1.GET("/realtime", h.GetRealtimeData)             // 获取实时数据
+		v1.POST("/realtime/batch", h.GetBatchRealtimeData) // 批量获取实时数据
+
+		// 业绩披露接口
+		v1.GET("/earnings", h.GetEarningsAnnouncements) // 获取区间内披露业绩报表的股票
+
+		// 收益率相关性接口
+		v1.GET("/correlation", h.GetReturnCorrelation) // 计算两只股票日收益率的相关系数
+
+		// 自选股接口
+		watchlists := v1.Group("/watchlists")
+		{
+			watchlists.GET("", h.GetWatchlists)                 // 获取自选股列表
+			watchlists.POST("", auth, h.CreateWatchlist)        // 创建自选股列表
+			watchlists.POST("/:id/sync", auth, h.SyncWatchlist) // 立即同步自选股列表中的股票
+		}
+
+		// 股票分组接口，对指数成分股、板块或自定义组合批量操作
+		groups := v1.Group("/groups")
+		{
+			groups.GET("", h.GetStockGroups)                                        // 获取股票分组
+			groups.POST("", auth, h.CreateStockGroup)                               // 创建股票分组
+			groups.GET("/:id", h.GetStockGroup)                                     // 获取单个股票分组
+			groups.PUT("/:id", auth, h.UpdateStockGroup)                            // 更新股票分组
+			groups.DELETE("/:id", auth, h.DeleteStockGroup)                         // 删除股票分组
+			groups.POST("/:id/constituents", auth, h.RefreshStockGroupConstituents) // 重新加载指数分组的成分股
+			groups.POST("/:id/sync", auth, h.SyncStockGroup)                        // 立即同步分组中的股票
+			groups.GET("/:id/screen", h.ScreenStockGroup)                           // 分组内基本面选股
+			groups.GET("/:id/export", h.ExportStockGroup)                           // 导出分组中股票的数据
+		}
+
+		// 数据排除区间接口，被排除的问题数据不参与指标计算和回测
+		exclusions := v1.Group("/data-exclusions")
+		{
+			exclusions.GET("", h.ListDataExclusions)               // 获取排除区间
+			exclusions.POST("", auth, h.CreateDataExclusion)       // 创建排除区间
+			exclusions.PUT("/:id", auth, h.UpdateDataExclusion)    // 更新排除区间
+			exclusions.DELETE("/:id", auth, h.DeleteDataExclusion) // 删除排除区间
+		}
+
+		// 异步任务接口
+		tasks := v1.Group("/tasks")
+		{
+			tasks.GET("", h.ListTasks)                        // 获取任务列表
+			tasks.GET("/:taskId", h.GetTaskStatus)            // 获取任务状态
+			tasks.POST("/:taskId/cancel", auth, h.CancelTask) // 取消任务
+		}
+
+		// 管理接口，全部需要鉴权
+		admin := v1.Group("/admin", auth)
+		{
+			admin.POST("/stocks/sync", h.SyncAllStocks)                              // 同步股票列表
+			admin.POST("/tasks/cancel-all", h.CancelAllTasks)                        // 取消所有执行中和等待中的任务
+			admin.GET("/collectors/:name/session", h.GetCollectorSession)            // 查看采集器当前的User-Agent和Cookie
+			admin.POST("/collectors/:name/session/rotate", h.RotateCollectorSession) // 强制采集器更换User-Agent和Cookie
+			admin.GET("/stocks/priority", h.GetPriorityStocks)                       // 获取优先同步的股票
+			admin.PUT("/stocks/priority", h.SetStockPriority)                        // 设置股票是否优先同步
+			admin.GET("/job-runs", h.GetJobRuns)                                     // 定时采集任务的运行记录
+			admin.GET("/audit-logs", h.GetAuditLogs)                                 // 修改数据的操作的审计记录
+			admin.GET("/source-discrepancies", h.CheckSourceDiscrepancies)           // 核对东方财富和同花顺最近日K线的差异
+		}
+	}
+}
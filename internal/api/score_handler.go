@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"stock/internal/model"
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 综合评分排名接口的返回数量
+const (
+	defaultScoreRankingLimit = 50  // 默认返回前50名
+	maxScoreRankingLimit     = 500 // 单次最多返回500名
+)
+
+// ScoreRankingResult 综合评分排名结果
+type ScoreRankingResult struct {
+	TradeDate int                `json:"trade_date"` // 评分日期，YYYYMMDD格式，尚无评分时为0
+	Total     int                `json:"total"`      // 返回的股票数量
+	Items     []model.StockScore `json:"items"`      // 按综合评分降序排列
+}
+
+// GetStockScore 获取股票最新的综合评分（技术面、基本面、筹码集中度加权）
+func (h *Handler) GetStockScore(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	h.logger.Infof("API: Getting composite score for %s", tsCode)
+
+	score, err := h.scoreService.GetLatestScore(tsCode)
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientScoreData) {
+			Error(c, CodeNotFound, "数据不足，无法计算该股票的综合评分")
+			return
+		}
+		h.logger.Errorf("Failed to get composite score: %v", err)
+		Error(c, CodeInternalError, "获取综合评分失败")
+		return
+	}
+
+	Success(c, score)
+}
+
+// GetScoreRanking 获取最新评分日期综合评分最高的股票
+func (h *Handler) GetScoreRanking(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultScoreRankingLimit)))
+	if err != nil || limit < 1 || limit > maxScoreRankingLimit {
+		Error(c, CodeInvalidParam, fmt.Sprintf("limit参数错误，应为1-%d之间的整数", maxScoreRankingLimit))
+		return
+	}
+
+	h.logger.Infof("API: Getting composite score ranking, limit: %d", limit)
+
+	tradeDate, scores, err := h.scoreService.GetRanking(limit)
+	if err != nil {
+		h.logger.Errorf("Failed to get composite score ranking: %v", err)
+		Error(c, CodeInternalError, "获取综合评分排名失败")
+		return
+	}
+
+	Success(c, ScoreRankingResult{
+		TradeDate: tradeDate,
+		Total:     len(scores),
+		Items:     scores,
+	})
+}
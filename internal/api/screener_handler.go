@@ -0,0 +1,170 @@
+package api
+
+import (
+	"sort"
+	"strconv"
+
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FundamentalScreenResult 基本面选股结果
+type FundamentalScreenResult struct {
+	TsCode       string  `json:"ts_code"`        // 股票代码
+	Name         string  `json:"name"`           // 股票简称
+	ReportDate   int     `json:"report_date"`    // 报告期，YYYYMMDD格式
+	EPS          float64 `json:"eps"`            // 每股收益，单位：元
+	ROE          float64 `json:"roe"`            // 净资产收益率（每股收益/每股净资产），单位：%
+	RevenueYoY   float64 `json:"revenue_yoy"`    // 营业总收入增长，单位：%
+	NetProfitYoY float64 `json:"net_profit_yoy"` // 净利润增长，单位：%
+	GrossMargin  float64 `json:"gross_margin"`   // 销售毛利率，单位：%
+}
+
+// fundamentalFilter 基本面选股条件，为nil的阈值不参与筛选
+type fundamentalFilter struct {
+	MinEPS        *float64
+	MinROE        *float64
+	MinRevenueYoY *float64
+	SortBy        string // 排序字段：eps、roe、revenue_yoy
+	Asc           bool   // 是否升序，默认降序
+	Limit         int    // 返回数量上限，<=0表示不限制
+}
+
+// fundamentalSortFields 支持的排序字段
+var fundamentalSortFields = map[string]func(r FundamentalScreenResult) float64{
+	"eps":         func(r FundamentalScreenResult) float64 { return r.EPS },
+	"roe":         func(r FundamentalScreenResult) float64 { return r.ROE },
+	"revenue_yoy": func(r FundamentalScreenResult) float64 { return r.RevenueYoY },
+}
+
+// maxScreenLimit 基本面选股单次返回的最大数量
+const maxScreenLimit = 500
+
+// screenFundamentals 按阈值筛选最新业绩报表并排序
+// ROE由每股收益/每股净资产计算，每股净资产无效时ROE未知，设置了min_roe时该股票不入选
+func screenFundamentals(rows []repository.PerformanceWithStock, filter fundamentalFilter) []FundamentalScreenResult {
+	results := make([]FundamentalScreenResult, 0)
+	for _, row := range rows {
+		roe, hasROE := row.ROE()
+		result := FundamentalScreenResult{
+			TsCode:       row.TsCode,
+			Name:         row.Name,
+			ReportDate:   row.ReportDate,
+			EPS:          row.EPS,
+			RevenueYoY:   row.RevenueYoY,
+			NetProfitYoY: row.NetProfitYoY,
+			GrossMargin:  row.GrossMargin,
+			ROE:          roe,
+		}
+
+		if filter.MinEPS != nil && result.EPS < *filter.MinEPS {
+			continue
+		}
+		if filter.MinROE != nil && (!hasROE || result.ROE < *filter.MinROE) {
+			continue
+		}
+		if filter.MinRevenueYoY != nil && result.RevenueYoY < *filter.MinRevenueYoY {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	metric, ok := fundamentalSortFields[filter.SortBy]
+	if !ok {
+		metric = fundamentalSortFields["eps"]
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if filter.Asc {
+			return metric(results[i]) < metric(results[j])
+		}
+		return metric(results[i]) > metric(results[j])
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results
+}
+
+// parseOptionalFloat 解析可选的浮点数查询参数，参数不存在时返回nil
+func parseOptionalFloat(c *gin.Context, name string) (*float64, bool) {
+	s, exists := c.GetQuery(name)
+	if !exists || s == "" {
+		return nil, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &v, true
+}
+
+// parseFundamentalFilter 解析基本面选股的查询参数，失败时已写出错误响应
+func parseFundamentalFilter(c *gin.Context) (fundamentalFilter, bool) {
+	var filter fundamentalFilter
+	for name, target := range map[string]**float64{
+		"min_eps":         &filter.MinEPS,
+		"min_roe":         &filter.MinROE,
+		"min_revenue_yoy": &filter.MinRevenueYoY,
+	} {
+		v, ok := parseOptionalFloat(c, name)
+		if !ok {
+			Error(c, CodeInvalidParam, name+"参数格式错误，应为数字")
+			return filter, false
+		}
+		*target = v
+	}
+
+	if _, exists := c.GetQuery("max_pe"); exists {
+		Error(c, CodeInvalidParam, "基本面选股不支持按市盈率筛选，请使用 /api/v1/screener/valuation")
+		return filter, false
+	}
+
+	filter.SortBy = c.DefaultQuery("sort_by", "eps")
+	if _, ok := fundamentalSortFields[filter.SortBy]; !ok {
+		Error(c, CodeInvalidParam, "sort_by参数错误，可选值：eps、roe、revenue_yoy")
+		return filter, false
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		filter.Asc = true
+	case "desc":
+	default:
+		Error(c, CodeInvalidParam, "order参数错误，可选值：asc、desc")
+		return filter, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > maxScreenLimit {
+		Error(c, CodeInvalidParam, "limit参数错误，应为1-500之间的整数")
+		return filter, false
+	}
+	filter.Limit = limit
+	return filter, true
+}
+
+// ScreenFundamental 基本面选股，按最新一期业绩报表的多个指标阈值筛选股票
+func (h *Handler) ScreenFundamental(c *gin.Context) {
+	filter, ok := parseFundamentalFilter(c)
+	if !ok {
+		return
+	}
+
+	h.logger.Infof("API: Screening fundamentals, sort by %s, limit %d", filter.SortBy, filter.Limit)
+
+	rows, err := repository.NewPerformance(h.db).GetLatestReportsWithStock()
+	if err != nil {
+		h.logger.Errorf("Failed to get latest performance reports: %v", err)
+		Error(c, CodeInternalError, "查询业绩报表数据失败")
+		return
+	}
+
+	results := screenFundamentals(rows, filter)
+	Success(c, gin.H{
+		"count":   len(results),
+		"sort_by": filter.SortBy,
+		"stocks":  results,
+	})
+}
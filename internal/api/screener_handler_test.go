@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScreenRow(tsCode, name string, eps, bvps, revenueYoY float64) repository.PerformanceWithStock {
+	return repository.PerformanceWithStock{
+		PerformanceReport: model.PerformanceReport{
+			TsCode: tsCode, ReportDate: 20250630, EPS: eps, BVPS: bvps, RevenueYoY: revenueYoY,
+		},
+		Name: name,
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestScreenFundamentals_CombinedThresholds(t *testing.T) {
+	rows := []repository.PerformanceWithStock{
+		newScreenRow("600519.SH", "贵州茅台", 33.0, 200.0, 9.1), // ROE 16.5%
+		newScreenRow("000858.SZ", "五粮液", 5.0, 40.0, 4.2),    // ROE 12.5%
+		newScreenRow("300750.SZ", "宁德时代", 6.5, 50.0, 22.0),  // ROE 13%
+		newScreenRow("000001.SZ", "平安银行", 1.2, 20.0, -10.0), // ROE和营收增长不达标
+		newScreenRow("688981.SH", "中芯国际", 0.6, 0, 30.0),     // 每股净资产缺失，ROE未知
+	}
+
+	// EPS>=1、ROE>=12%、营收增长>=5%
+	results := screenFundamentals(rows, fundamentalFilter{
+		MinEPS: floatPtr(1), MinROE: floatPtr(12), MinRevenueYoY: floatPtr(5), SortBy: "eps",
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, "600519.SH", results[0].TsCode)
+	assert.Equal(t, "贵州茅台", results[0].Name)
+	assert.InDelta(t, 16.5, results[0].ROE, 1e-9)
+	assert.Equal(t, "300750.SZ", results[1].TsCode)
+
+	// 按营收增长升序
+	results = screenFundamentals(rows, fundamentalFilter{
+		MinEPS: floatPtr(1), MinROE: floatPtr(12), MinRevenueYoY: floatPtr(5), SortBy: "revenue_yoy", Asc: true,
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, "600519.SH", results[0].TsCode)
+
+	// 只设置营收增长阈值，ROE未知的股票仍可入选，按ROE降序且限制数量
+	results = screenFundamentals(rows, fundamentalFilter{MinRevenueYoY: floatPtr(5), SortBy: "roe", Limit: 2})
+	require.Len(t, results, 2)
+	assert.Equal(t, "600519.SH", results[0].TsCode)
+	assert.Equal(t, "300750.SZ", results[1].TsCode)
+
+	// 设置ROE阈值时ROE未知的股票被排除
+	results = screenFundamentals(rows, fundamentalFilter{MinROE: floatPtr(0), SortBy: "roe"})
+	for _, r := range results {
+		assert.NotEqual(t, "688981.SH", r.TsCode)
+	}
+
+	// 不设置任何条件时返回全部
+	assert.Len(t, screenFundamentals(rows, fundamentalFilter{}), len(rows))
+}
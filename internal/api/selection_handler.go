@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSelectionStrategies 获取已注册的选股策略列表
+func (h *Handler) GetSelectionStrategies(c *gin.Context) {
+	strategies := h.strategyRegistry.List()
+	Success(c, gin.H{
+		"count":      len(strategies),
+		"strategies": strategies,
+	})
+}
+
+// ExplainSelectionResult 获取选股结果的分因子解释，返回各因子对总分的贡献
+func (h *Handler) ExplainSelectionResult(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		Error(c, CodeInvalidParam, "选股结果ID格式错误")
+		return
+	}
+
+	h.logger.Infof("API: Explaining selection result %d", id)
+
+	explanation, err := h.selectionService.ExplainSelectionResult(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrSelectionResultNotFound) {
+			Error(c, CodeNotFound, "选股结果不存在")
+			return
+		}
+		h.logger.Errorf("Failed to explain selection result: %v", err)
+		Error(c, CodeInternalError, "获取选股结果解释失败")
+		return
+	}
+
+	Success(c, explanation)
+}
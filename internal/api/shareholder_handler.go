@@ -1,11 +1,9 @@
 package api
 
 import (
-	"net/http"
 	"strconv"
 	"time"
 
-	"stock/internal/model"
 	"stock/internal/service"
 	"stock/internal/utils"
 
@@ -36,16 +34,16 @@ func NewShareholderHandler(service *service.ShareholderService) *ShareholderHand
 func (h *ShareholderHandler) GetShareholderCounts(c *gin.Context) {
 	tsCode := c.Param("ts_code")
 	if tsCode == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode = utils.ConvertToTsCode(tsCode)
 
-	counts, err := h.service.GetShareholderCounts(c.Request.Context(), tsCode)
+	counts, err := h.service.GetShareholderCounts(tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取股东户数数据失败")
+		Error(c, CodeInternalError, "获取股东户数数据失败")
 		return
 	}
 
@@ -64,7 +62,7 @@ func (h *ShareholderHandler) GetShareholderCounts(c *gin.Context) {
 func (h *ShareholderHandler) GetLatestShareholderCount(c *gin.Context) {
 	tsCode := c.Param("ts_code")
 	if tsCode == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -73,7 +71,7 @@ func (h *ShareholderHandler) GetLatestShareholderCount(c *gin.Context) {
 
 	count, err := h.service.GetLatestShareholderCount(tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取最新股东户数数据失败")
+		Error(c, CodeInternalError, "获取最新股东户数数据失败")
 		return
 	}
 
@@ -94,7 +92,7 @@ func (h *ShareholderHandler) GetLatestShareholderCount(c *gin.Context) {
 func (h *ShareholderHandler) GetShareholderCountsByDateRange(c *gin.Context) {
 	tsCode := c.Param("ts_code")
 	if tsCode == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -102,28 +100,26 @@ func (h *ShareholderHandler) GetShareholderCountsByDateRange(c *gin.Context) {
 	endDateStr := c.Query("end_date")
 
 	if startDateStr == "" || endDateStr == "" {
-		Error(c, http.StatusBadRequest, "开始日期和结束日期不能为空")
+		Error(c, CodeInvalidParam, "开始日期和结束日期不能为空")
 		return
 	}
 
-	startDate, err := time.Parse("2006-01-02", startDateStr)
-	if err != nil {
-		Error(c, http.StatusBadRequest, "开始日期格式错误")
+	if _, err := time.Parse("2006-01-02", startDateStr); err != nil {
+		Error(c, CodeInvalidParam, "开始日期格式错误")
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		Error(c, http.StatusBadRequest, "结束日期格式错误")
+	if _, err := time.Parse("2006-01-02", endDateStr); err != nil {
+		Error(c, CodeInvalidParam, "结束日期格式错误")
 		return
 	}
 
 	// 转换股票代码格式
 	tsCode = utils.ConvertToTsCode(tsCode)
 
-	counts, err := h.service.GetShareholderCountsByDateRange(c.Request.Context(), tsCode, startDate, endDate)
+	counts, err := h.service.GetShareholderCountsByDateRange(tsCode, startDateStr, endDateStr)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取股东户数数据失败")
+		Error(c, CodeInternalError, "获取股东户数数据失败")
 		return
 	}
 
@@ -142,7 +138,7 @@ func (h *ShareholderHandler) GetShareholderCountsByDateRange(c *gin.Context) {
 func (h *ShareholderHandler) SyncShareholderCounts(c *gin.Context) {
 	tsCode := c.Param("ts_code")
 	if tsCode == "" {
-		Error(c, http.StatusBadRequest, "股票代码不能为空")
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
 		return
 	}
 
@@ -151,7 +147,7 @@ func (h *ShareholderHandler) SyncShareholderCounts(c *gin.Context) {
 
 	err := h.service.SyncShareholderCounts(c.Request.Context(), tsCode)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "同步股东户数数据失败")
+		Error(c, CodeInternalError, "同步股东户数数据失败")
 		return
 	}
 
@@ -167,9 +163,9 @@ func (h *ShareholderHandler) SyncShareholderCounts(c *gin.Context) {
 // @Success 200 {object} Response{data=string}
 // @Router /api/v1/shareholder/sync-all [post]
 func (h *ShareholderHandler) SyncAllStocksShareholderCounts(c *gin.Context) {
-	err := h.service.SyncAllStocksShareholderCounts(c.Request.Context())
+	err := h.service.SyncAllStocksShareholderCounts()
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "同步所有股票股东户数数据失败")
+		Error(c, CodeInternalError, "同步所有股票股东户数数据失败")
 		return
 	}
 
@@ -185,9 +181,9 @@ func (h *ShareholderHandler) SyncAllStocksShareholderCounts(c *gin.Context) {
 // @Success 200 {object} Response{data=map[string]interface{}}
 // @Router /api/v1/shareholder/statistics [get]
 func (h *ShareholderHandler) GetStatistics(c *gin.Context) {
-	stats, err := h.service.GetStatistics(c.Request.Context())
+	stats, err := h.service.GetStatistics()
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取统计信息失败")
+		Error(c, CodeInternalError, "获取统计信息失败")
 		return
 	}
 
@@ -211,12 +207,19 @@ func (h *ShareholderHandler) GetTopByHolderNum(c *gin.Context) {
 		limit = 10
 	}
 
-	order := c.DefaultQuery("order", "desc")
-	ascending := order == "asc"
+	ascending := false
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		ascending = true
+	case "desc":
+	default:
+		Error(c, CodeInvalidParam, "order参数错误，可选值：asc、desc")
+		return
+	}
 
-	counts, err := h.service.GetTopByHolderNum(c.Request.Context(), limit, ascending)
+	counts, err := h.service.GetTopByHolderNum(limit, ascending)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取股东户数排行榜失败")
+		Error(c, CodeInternalError, "获取股东户数排行榜失败")
 		return
 	}
 
@@ -240,12 +243,19 @@ func (h *ShareholderHandler) GetTopByAvgMarketCap(c *gin.Context) {
 		limit = 10
 	}
 
-	order := c.DefaultQuery("order", "desc")
-	ascending := order == "asc"
+	ascending := false
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		ascending = true
+	case "desc":
+	default:
+		Error(c, CodeInvalidParam, "order参数错误，可选值：asc、desc")
+		return
+	}
 
-	counts, err := h.service.GetTopByAvgMarketCap(c.Request.Context(), limit, ascending)
+	counts, err := h.service.GetTopByAvgMarketCap(limit, ascending)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取户均市值排行榜失败")
+		Error(c, CodeInternalError, "获取户均市值排行榜失败")
 		return
 	}
 
@@ -275,12 +285,16 @@ func (h *ShareholderHandler) GetRecentChanges(c *gin.Context) {
 		days = 30
 	}
 
-	counts, err := h.service.GetRecentChanges(c.Request.Context(), limit, days)
+	counts, err := h.service.GetRecentChanges(days)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取股东户数变化数据失败")
+		Error(c, CodeInternalError, "获取股东户数变化数据失败")
 		return
 	}
 
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+
 	Success(c, counts)
 }
 
@@ -301,19 +315,19 @@ func (h *ShareholderHandler) GetShareholderCountsWithPagination(c *gin.Context)
 	endDateStr := c.Query("end_date")
 
 	if startDateStr == "" || endDateStr == "" {
-		Error(c, http.StatusBadRequest, "开始日期和结束日期不能为空")
+		Error(c, CodeInvalidParam, "开始日期和结束日期不能为空")
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
-		Error(c, http.StatusBadRequest, "开始日期格式错误")
+		Error(c, CodeInvalidParam, "开始日期格式错误")
 		return
 	}
 
 	endDate, err := time.Parse("2006-01-02", endDateStr)
 	if err != nil {
-		Error(c, http.StatusBadRequest, "结束日期格式错误")
+		Error(c, CodeInvalidParam, "结束日期格式错误")
 		return
 	}
 
@@ -329,9 +343,9 @@ func (h *ShareholderHandler) GetShareholderCountsWithPagination(c *gin.Context)
 		pageSize = 20
 	}
 
-	counts, total, err := h.service.GetShareholderCountsWithPagination(c.Request.Context(), startDate, endDate, page, pageSize)
+	counts, total, err := h.service.GetShareholderCountsWithPagination(startDate, endDate, page, pageSize)
 	if err != nil {
-		Error(c, http.StatusInternalServerError, "获取股东户数数据失败")
+		Error(c, CodeInternalError, "获取股东户数数据失败")
 		return
 	}
 
@@ -345,88 +359,3 @@ func (h *ShareholderHandler) GetShareholderCountsWithPagination(c *gin.Context)
 
 	Success(c, result)
 }
-
-// CreateShareholderCount 创建股东户数记录
-// @Summary 创建股东户数记录
-// @Description 创建新的股东户数记录
-// @Tags 股东户数
-// @Accept json
-// @Produce json
-// @Param shareholder_count body model.ShareholderCount true "股东户数数据"
-// @Success 200 {object} Response{data=string}
-// @Router /api/v1/shareholder [post]
-func (h *ShareholderHandler) CreateShareholderCount(c *gin.Context) {
-	var count model.ShareholderCount
-	if err := c.ShouldBindJSON(&count); err != nil {
-		Error(c, http.StatusBadRequest, "请求参数错误")
-		return
-	}
-
-	err := h.service.CreateShareholderCount(c.Request.Context(), &count)
-	if err != nil {
-		Error(c, http.StatusInternalServerError, "创建股东户数记录失败")
-		return
-	}
-
-	Success(c, gin.H{"message": "创建成功"})
-}
-
-// UpdateShareholderCount 更新股东户数记录
-// @Summary 更新股东户数记录
-// @Description 更新股东户数记录
-// @Tags 股东户数
-// @Accept json
-// @Produce json
-// @Param id path int true "记录ID"
-// @Param shareholder_count body model.ShareholderCount true "股东户数数据"
-// @Success 200 {object} Response{data=string}
-// @Router /api/v1/shareholder/{id} [put]
-func (h *ShareholderHandler) UpdateShareholderCount(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		Error(c, http.StatusBadRequest, "无效的记录ID")
-		return
-	}
-
-	var count model.ShareholderCount
-	if err := c.ShouldBindJSON(&count); err != nil {
-		Error(c, http.StatusBadRequest, "请求参数错误")
-		return
-	}
-
-	count.ID = uint(id)
-	err = h.service.UpdateShareholderCount(c.Request.Context(), &count)
-	if err != nil {
-		Error(c, http.StatusInternalServerError, "更新股东户数记录失败")
-		return
-	}
-
-	Success(c, gin.H{"message": "更新成功"})
-}
-
-// DeleteShareholderCount 删除股东户数记录
-// @Summary 删除股东户数记录
-// @Description 删除股东户数记录
-// @Tags 股东户数
-// @Accept json
-// @Produce json
-// @Param id path int true "记录ID"
-// @Success 200 {object} Response{data=string}
-// @Router /api/v1/shareholder/{id} [delete]
-func (h *ShareholderHandler) DeleteShareholderCount(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		Error(c, http.StatusBadRequest, "无效的记录ID")
-		return
-	}
-
-	err = h.service.DeleteShareholderCount(c.Request.Context(), uint(id))
-	if err != nil {
-		Error(c, http.StatusInternalServerError, "删除股东户数记录失败")
-		return
-	}
-
-	Success(c, gin.H{"message": "删除成功"})
-}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 复杂指标信号接口使用的K线数量
+const (
+	defaultSignalBars = 250  // 默认使用最近250个交易日
+	maxSignalBars     = 5000 // 单次最多使用的交易日数
+)
+
+// ComplexSignalsResult 复杂指标信号计算结果
+type ComplexSignalsResult struct {
+	TsCode    string                   `json:"ts_code"`    // 股票代码
+	Bars      int                      `json:"bars"`       // 参与计算的K线数量
+	StartDate int                      `json:"start_date"` // 首根K线交易日期，YYYYMMDD格式
+	EndDate   int                      `json:"end_date"`   // 末根K线交易日期，YYYYMMDD格式
+	Stats     map[string]interface{}   `json:"stats"`      // 各信号出现次数及最近出现日期
+	Signals   indicator.ComplexSignals `json:"signals"`    // 各信号出现的交易日期列表
+}
+
+// analyzeComplexSignals 按交易日期升序计算复杂指标信号，K线数量不足时返回*indicator.InsufficientHistoryError
+func analyzeComplexSignals(tsCode string, data []model.DailyData) (*ComplexSignalsResult, error) {
+	if err := indicator.CheckHistory(indicator.IndicatorComplex, len(data)); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]model.DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TradeDate < sorted[j].TradeDate
+	})
+
+	result := indicator.CalculateComplexIndicator(sorted)
+	return &ComplexSignalsResult{
+		TsCode:    tsCode,
+		Bars:      len(sorted),
+		StartDate: sorted[0].TradeDate,
+		EndDate:   sorted[len(sorted)-1].TradeDate,
+		Stats:     result.GetSignalStats(),
+		Signals:   result.Signals,
+	}, nil
+}
+
+// GetComplexSignals 获取复杂指标（极底、绝底、见涨、金叉等）信号及其出现日期
+func (h *Handler) GetComplexSignals(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	bars, err := strconv.Atoi(c.DefaultQuery("bars", strconv.Itoa(defaultSignalBars)))
+	if err != nil || bars < indicator.MinComplexIndicatorBars || bars > maxSignalBars {
+		Error(c, CodeInvalidParam, fmt.Sprintf("bars参数错误，应为%d-%d之间的整数", indicator.MinComplexIndicatorBars, maxSignalBars))
+		return
+	}
+
+	h.logger.Infof("API: Getting complex indicator signals for %s, bars: %d", tsCode, bars)
+
+	// 取最近bars根日K线，跳过已标记的问题数据区间
+	data, err := repository.NewDailyData(h.db).GetAnalysisDailyData(tsCode, time.Time{}, time.Time{}, bars)
+	if err != nil {
+		h.logger.Errorf("Failed to get daily data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(data) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	// 上市不久或停牌较多的股票K线数量可能不足
+	result, err := analyzeComplexSignals(tsCode, data)
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	Success(c, result)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWaveDailyData 生成按交易日期降序排列的周期波动K线，与数据库查询顺序一致
+func newWaveDailyData(n int) []model.DailyData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	data := make([]model.DailyData, n)
+	for i := 0; i < n; i++ {
+		price := 10 + 2*math.Sin(float64(i)*2*math.Pi/30)
+		date := start.AddDate(0, 0, i)
+		data[n-1-i] = model.DailyData{
+			TsCode:    "000001.SZ",
+			TradeDate: date.Year()*10000 + int(date.Month())*100 + date.Day(),
+			Open:      price - 0.05,
+			High:      price + 0.2,
+			Low:       price - 0.2,
+			Close:     price,
+			Volume:    100000,
+		}
+	}
+	return data
+}
+
+func TestAnalyzeComplexSignals(t *testing.T) {
+	data := newWaveDailyData(120)
+
+	result, err := analyzeComplexSignals("000001.SZ", data)
+	require.NoError(t, err)
+	assert.Equal(t, 120, result.Bars)
+	assert.Equal(t, 20240101, result.StartDate)
+	assert.Equal(t, data[0].TradeDate, result.EndDate)
+
+	// 周期波动中MACD和KDJ会多次同时金叉
+	goldenCross, ok := result.Stats["golden_cross_count"].(int)
+	require.True(t, ok)
+	assert.Greater(t, goldenCross, 0)
+
+	signals := result.Signals
+	require.Len(t, signals.GoldenCross, goldenCross)
+	for _, date := range signals.GoldenCross {
+		assert.GreaterOrEqual(t, date, result.StartDate)
+		assert.LessOrEqual(t, date, result.EndDate)
+	}
+}
+
+func TestAnalyzeComplexSignals_InsufficientData(t *testing.T) {
+	_, err := analyzeComplexSignals("000001.SZ", newWaveDailyData(37))
+	require.Error(t, err)
+	assert.EqualError(t, err, "insufficient history: need 38 bars, have 37")
+
+	var historyErr *indicator.InsufficientHistoryError
+	require.ErrorAs(t, err, &historyErr)
+	assert.Equal(t, indicator.MinComplexIndicatorBars, historyErr.Need)
+	assert.Equal(t, 37, historyErr.Have)
+}
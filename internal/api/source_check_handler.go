@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+	"stock/internal/repository"
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// newSourceCheckService 创建以东方财富为主、同花顺为对比的跨数据源核对服务
+// 同花顺未注册到采集器管理器时由采集器工厂创建，创建失败时返回nil
+func newSourceCheckService(collectorManager *collector.CollectorManager, db *gorm.DB, eastMoney collector.DataCollector) *service.SourceCheckService {
+	tongHuaShun, ok := collectorManager.LookupCollector("tonghuashun")
+	if !ok {
+		var err error
+		tongHuaShun, err = collector.GetCollectorFactory(logger.GetGlobalLogger()).CreateCollector(collector.CollectorTypeTongHuaShun)
+		if err != nil {
+			logger.Warnf("Source discrepancy check disabled: %v", err)
+			return nil
+		}
+	}
+	return service.GetSourceCheckService(repository.NewStock(db), eastMoney, tongHuaShun)
+}
+
+// CheckSourceDiscrepancies 核对两个数据源最近的日K线，返回各字段超过容差的差异汇总和明细
+// codes为逗号分隔的股票代码，为空时随机抽取sample只活跃股票；days为核对的自然日数，tolerance为相对差异容差
+// 每只股票对两个数据源各请求一次，同步执行，sample不宜过大
+func (h *Handler) CheckSourceDiscrepancies(c *gin.Context) {
+	if h.sourceCheckService == nil {
+		Error(c, CodeDataSourceUnavailable, "跨数据源核对需要东方财富和同花顺两个数据源")
+		return
+	}
+
+	var opts service.SourceCheckOptions
+	if raw := c.Query("codes"); raw != "" {
+		codes, code, msg := parseBatchCodes(raw)
+		if code != CodeSuccess {
+			Error(c, code, msg)
+			return
+		}
+		opts.TsCodes = codes
+	}
+	if value := c.Query("sample"); value != "" {
+		sample, err := strconv.Atoi(value)
+		if err != nil || sample < 1 || sample > service.MaxSourceCheckSample {
+			Error(c, CodeInvalidParam, fmt.Sprintf("sample应为1到%d之间的整数", service.MaxSourceCheckSample))
+			return
+		}
+		opts.Sample = sample
+	}
+	if value := c.Query("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 366 {
+			Error(c, CodeInvalidParam, "days应为1到366之间的整数")
+			return
+		}
+		opts.Days = days
+	}
+	if value := c.Query("tolerance"); value != "" {
+		tolerance, err := strconv.ParseFloat(value, 64)
+		if err != nil || tolerance <= 0 || tolerance >= 1 {
+			Error(c, CodeInvalidParam, "tolerance应为0到1之间的小数")
+			return
+		}
+		opts.Tolerance = tolerance
+	}
+
+	h.logger.Infof("API: Checking source discrepancies, codes=%d, sample=%d", len(opts.TsCodes), opts.Sample)
+
+	report, err := h.sourceCheckService.Check(c.Request.Context(), opts)
+	if err != nil {
+		h.logger.Errorf("Failed to check source discrepancies: %v", err)
+		Error(c, CodeInternalError, "跨数据源核对失败")
+		return
+	}
+	Success(c, report)
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStockGroupStocks 单个股票分组最多包含的股票数，需容纳中证500等宽基指数的全部成分股
+const maxStockGroupStocks = 1000
+
+// stockGroupRequest 创建或更新股票分组的请求体
+type stockGroupRequest struct {
+	Name        string   `json:"name"`        // 分组名称
+	Kind        string   `json:"kind"`        // 分组类型：index、sector、custom，缺省为custom
+	Source      string   `json:"source"`      // 成分股来源，指数分组为指数代码
+	Description string   `json:"description"` // 分组说明
+	Codes       []string `json:"codes"`       // 股票代码，指数分组为空时从数据源加载成分股
+}
+
+// StockGroupSyncResult 股票分组同步结果
+type StockGroupSyncResult struct {
+	GroupID    uint              `json:"group_id"`    // 分组ID
+	Name       string            `json:"name"`        // 分组名称
+	Total      int               `json:"total"`       // 股票总数
+	Success    int               `json:"success"`     // 同步成功数
+	Failed     int               `json:"failed"`      // 同步失败数
+	DurationMs int64             `json:"duration_ms"` // 总耗时，单位：毫秒
+	Results    []StockSyncResult `json:"results"`     // 每只股票的同步结果，与分组顺序一致
+}
+
+// bindStockGroup 解析并校验请求体，填充到group中，失败时已写出错误响应
+// 返回是否需要从数据源加载指数成分股（指数分组且未指定股票代码）
+func bindStockGroup(c *gin.Context, group *model.StockGroup) (loadConstituents bool, ok bool) {
+	var req stockGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, CodeInvalidParam, "请求参数错误")
+		return false, false
+	}
+
+	group.Name = req.Name
+	group.Kind = model.StockGroupKind(req.Kind)
+	group.Source = req.Source
+	group.Description = req.Description
+	group.Normalize()
+	if err := group.Validate(); err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return false, false
+	}
+
+	group.SetCodes(req.Codes)
+	codes := group.Codes()
+	if len(codes) == 0 {
+		if group.Kind == model.StockGroupKindIndex {
+			return true, true
+		}
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return false, false
+	}
+	if len(codes) > maxStockGroupStocks {
+		Error(c, CodeInvalidParam, fmt.Sprintf("股票分组最多包含%d只股票", maxStockGroupStocks))
+		return false, false
+	}
+	for _, code := range codes {
+		if !strings.Contains(code, ".") {
+			Error(c, CodeInvalidTsCode, fmt.Sprintf("股票代码格式错误：%s，应为：000001.SZ 或 600000.SH", code))
+			return false, false
+		}
+	}
+	return false, true
+}
+
+// parseStockGroupID 解析路径中的分组ID，失败时已写出错误响应
+func parseStockGroupID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		Error(c, CodeInvalidParam, "股票分组ID错误")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getStockGroup 按路径中的ID获取股票分组，失败或不存在时已写出错误响应
+func (h *Handler) getStockGroup(c *gin.Context) (*model.StockGroup, bool) {
+	id, ok := parseStockGroupID(c)
+	if !ok {
+		return nil, false
+	}
+
+	group, err := repository.NewStockGroup(h.db).GetByID(id)
+	if err != nil {
+		h.logger.Errorf("Failed to get stock group: %v", err)
+		Error(c, CodeInternalError, "获取股票分组失败")
+		return nil, false
+	}
+	if group == nil {
+		Error(c, CodeNotFound, "股票分组不存在")
+		return nil, false
+	}
+	return group, true
+}
+
+// loadIndexConstituents 从东方财富加载指数分组的成分股，覆盖分组中原有的股票代码
+func (h *Handler) loadIndexConstituents(group *model.StockGroup) error {
+	c, ok := h.collectorManager.LookupCollector("eastmoney")
+	if !ok {
+		return fmt.Errorf("eastmoney collector is not available")
+	}
+	constituents, ok := c.(collector.IndexConstituentCollector)
+	if !ok {
+		return fmt.Errorf("collector %s does not support index constituents", c.GetName())
+	}
+
+	codes, err := constituents.GetIndexConstituents(group.Source)
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return fmt.Errorf("no constituents returned for index %s", group.Source)
+	}
+	group.SetCodes(codes)
+	return nil
+}
+
+// syncStockGroup 逐只同步分组中的股票并汇总结果
+func syncStockGroup(ctx context.Context, group *model.StockGroup, syncFn func(ctx context.Context, tsCode string) error) StockGroupSyncResult {
+	start := time.Now()
+	results := syncStocksConcurrently(ctx, group.Codes(), watchlistSyncConcurrency, watchlistSyncTimeout, syncFn)
+
+	summary := StockGroupSyncResult{
+		GroupID: group.ID,
+		Name:    group.Name,
+		Total:   len(results),
+		Results: results,
+	}
+	for _, r := range results {
+		if r.Success {
+			summary.Success++
+		} else {
+			summary.Failed++
+		}
+	}
+	summary.DurationMs = time.Since(start).Milliseconds()
+	return summary
+}
+
+// filterRowsByCodes 只保留股票代码在codes中的业绩报表
+func filterRowsByCodes(rows []repository.PerformanceWithStock, codes []string) []repository.PerformanceWithStock {
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		wanted[code] = true
+	}
+
+	filtered := make([]repository.PerformanceWithStock, 0, len(codes))
+	for _, row := range rows {
+		if wanted[row.TsCode] {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// CreateStockGroup 创建股票分组，指数分组未指定股票代码时从数据源加载成分股
+func (h *Handler) CreateStockGroup(c *gin.Context) {
+	group := &model.StockGroup{}
+	loadConstituents, ok := bindStockGroup(c, group)
+	if !ok {
+		return
+	}
+	if loadConstituents {
+		if err := h.loadIndexConstituents(group); err != nil {
+			h.logger.Errorf("Failed to load constituents of %s: %v", group.Source, err)
+			Error(c, CodeDataSourceError, "加载指数成分股失败")
+			return
+		}
+	}
+
+	if err := repository.NewStockGroup(h.db).Create(group); err != nil {
+		h.logger.Errorf("Failed to create stock group: %v", err)
+		Error(c, CodeInternalError, "创建股票分组失败")
+		return
+	}
+
+	h.logger.Infof("API: Created stock group %s (%s, %d stocks)", group.Name, group.Kind, len(group.Codes()))
+	Success(c, group)
+}
+
+// GetStockGroups 获取股票分组，可按kind过滤
+func (h *Handler) GetStockGroups(c *gin.Context) {
+	kind := model.StockGroupKind(strings.ToLower(c.Query("kind")))
+	switch kind {
+	case "", model.StockGroupKindIndex, model.StockGroupKindSector, model.StockGroupKindCustom:
+	default:
+		Error(c, CodeInvalidParam, "kind参数错误，可选值：index、sector、custom")
+		return
+	}
+
+	groups, err := repository.NewStockGroup(h.db).List(kind)
+	if err != nil {
+		h.logger.Errorf("Failed to get stock groups: %v", err)
+		Error(c, CodeInternalError, "获取股票分组失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count":  len(groups),
+		"groups": groups,
+	})
+}
+
+// GetStockGroup 获取单个股票分组
+func (h *Handler) GetStockGroup(c *gin.Context) {
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+	Success(c, group)
+}
+
+// UpdateStockGroup 更新股票分组，指数分组未指定股票代码时重新加载成分股
+func (h *Handler) UpdateStockGroup(c *gin.Context) {
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+
+	loadConstituents, ok := bindStockGroup(c, group)
+	if !ok {
+		return
+	}
+	if loadConstituents {
+		if err := h.loadIndexConstituents(group); err != nil {
+			h.logger.Errorf("Failed to load constituents of %s: %v", group.Source, err)
+			Error(c, CodeDataSourceError, "加载指数成分股失败")
+			return
+		}
+	}
+
+	if err := repository.NewStockGroup(h.db).Update(group); err != nil {
+		h.logger.Errorf("Failed to update stock group: %v", err)
+		Error(c, CodeInternalError, "更新股票分组失败")
+		return
+	}
+
+	h.logger.Infof("API: Updated stock group %d", group.ID)
+	Success(c, group)
+}
+
+// DeleteStockGroup 删除股票分组，不影响分组中股票的数据
+func (h *Handler) DeleteStockGroup(c *gin.Context) {
+	id, ok := parseStockGroupID(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := repository.NewStockGroup(h.db).Delete(id)
+	if err != nil {
+		h.logger.Errorf("Failed to delete stock group: %v", err)
+		Error(c, CodeInternalError, "删除股票分组失败")
+		return
+	}
+	if deleted == 0 {
+		Error(c, CodeNotFound, "股票分组不存在")
+		return
+	}
+
+	h.logger.Infof("API: Deleted stock group %d", id)
+	Success(c, gin.H{"id": id})
+}
+
+// RefreshStockGroupConstituents 从数据源重新加载指数分组的成分股，用于指数定期调整成分后更新分组
+func (h *Handler) RefreshStockGroupConstituents(c *gin.Context) {
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+	if group.Kind != model.StockGroupKindIndex {
+		Error(c, CodeInvalidParam, "只有指数分组支持加载成分股")
+		return
+	}
+
+	previous := len(group.Codes())
+	if err := h.loadIndexConstituents(group); err != nil {
+		h.logger.Errorf("Failed to load constituents of %s: %v", group.Source, err)
+		Error(c, CodeDataSourceError, "加载指数成分股失败")
+		return
+	}
+	if err := repository.NewStockGroup(h.db).Update(group); err != nil {
+		h.logger.Errorf("Failed to update stock group: %v", err)
+		Error(c, CodeInternalError, "更新股票分组失败")
+		return
+	}
+
+	h.logger.Infof("API: Refreshed constituents of stock group %s: %d -> %d", group.Name, previous, len(group.Codes()))
+	Success(c, group)
+}
+
+// SyncStockGroup 立即同步分组中的所有股票，同步完成后返回每只股票的结果
+func (h *Handler) SyncStockGroup(c *gin.Context) {
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+
+	h.logger.Infof("API: Syncing stock group %s (%d stocks)", group.Name, len(group.Codes()))
+	summary := syncStockGroup(c.Request.Context(), group, h.syncStockNow)
+
+	h.logger.Infof("Stock group %s synced: %d succeeded, %d failed", group.Name, summary.Success, summary.Failed)
+	Success(c, summary)
+}
+
+// ScreenStockGroup 在分组内做基本面选股，查询参数与基本面选股接口相同
+func (h *Handler) ScreenStockGroup(c *gin.Context) {
+	filter, ok := parseFundamentalFilter(c)
+	if !ok {
+		return
+	}
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+
+	h.logger.Infof("API: Screening fundamentals within stock group %s, sort by %s, limit %d",
+		group.Name, filter.SortBy, filter.Limit)
+
+	rows, err := repository.NewPerformance(h.db).GetLatestReportsWithStock()
+	if err != nil {
+		h.logger.Errorf("Failed to get latest performance reports: %v", err)
+		Error(c, CodeInternalError, "查询业绩报表数据失败")
+		return
+	}
+
+	results := screenFundamentals(filterRowsByCodes(rows, group.Codes()), filter)
+	Success(c, gin.H{
+		"group_id": group.ID,
+		"name":     group.Name,
+		"count":    len(results),
+		"sort_by":  filter.SortBy,
+		"stocks":   results,
+	})
+}
+
+// ExportStockGroup 导出分组中股票的基础信息和最新一期业绩报表，响应直接为JSON文件而非统一响应格式
+func (h *Handler) ExportStockGroup(c *gin.Context) {
+	group, ok := h.getStockGroup(c)
+	if !ok {
+		return
+	}
+
+	codes := group.Codes()
+	h.logger.Infof("API: Exporting stock group %s (%d stocks)", group.Name, len(codes))
+
+	stocks, err := repository.NewStock(h.db).GetStocksByTsCodes(codes)
+	if err != nil {
+		h.logger.Errorf("Failed to get stocks from database: %v", err)
+		Error(c, CodeInternalError, "获取股票信息失败")
+		return
+	}
+
+	rows, err := repository.NewPerformance(h.db).GetLatestReportsWithStock()
+	if err != nil {
+		h.logger.Errorf("Failed to get latest performance reports: %v", err)
+		Error(c, CodeInternalError, "查询业绩报表数据失败")
+		return
+	}
+
+	body, err := json.Marshal(gin.H{
+		"group":       group,
+		"exported_at": time.Now(),
+		"stocks":      stocks,
+		"performance": filterRowsByCodes(rows, codes),
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to encode stock group export: %v", err)
+		Error(c, CodeInternalError, "导出股票分组失败")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="stock-group-%d.json"`, group.ID))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncStockGroup(t *testing.T) {
+	group := &model.StockGroup{ID: 3, Name: "沪深300", Kind: model.StockGroupKindIndex, Source: "000300.SH"}
+	group.SetCodes([]string{"600519.SH", "000001.SZ", "300750.SZ", "601318.SH"})
+
+	var mu sync.Mutex
+	synced := make(map[string]bool)
+	summary := syncStockGroup(context.Background(), group, func(ctx context.Context, tsCode string) error {
+		mu.Lock()
+		synced[tsCode] = true
+		mu.Unlock()
+		if tsCode == "300750.SZ" {
+			return errors.New("fetch performance reports: rate limited")
+		}
+		return nil
+	})
+
+	// 分组中每只股票都同步一次，单只失败不影响其他股票
+	assert.Len(t, synced, 4)
+	assert.Equal(t, uint(3), summary.GroupID)
+	assert.Equal(t, "沪深300", summary.Name)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, 3, summary.Success)
+	assert.Equal(t, 1, summary.Failed)
+	require.Len(t, summary.Results, 4)
+	for i, code := range group.Codes() {
+		assert.Equal(t, code, summary.Results[i].TsCode)
+	}
+	assert.False(t, summary.Results[2].Success)
+	assert.Equal(t, "fetch performance reports: rate limited", summary.Results[2].Error)
+}
+
+func TestSyncStockGroup_Cancelled(t *testing.T) {
+	group := &model.StockGroup{ID: 1, Name: "银行"}
+	group.SetCodes([]string{"000001.SZ", "600036.SH"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary := syncStockGroup(ctx, group, func(ctx context.Context, tsCode string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+
+	assert.Equal(t, 2, summary.Total)
+	assert.Equal(t, 0, summary.Success)
+	assert.Equal(t, 2, summary.Failed)
+}
+
+func TestFilterRowsByCodes(t *testing.T) {
+	rows := []repository.PerformanceWithStock{
+		{PerformanceReport: model.PerformanceReport{TsCode: "600519.SH", EPS: 30}},
+		{PerformanceReport: model.PerformanceReport{TsCode: "000001.SZ", EPS: 1.2}},
+		{PerformanceReport: model.PerformanceReport{TsCode: "300750.SZ", EPS: 8}},
+	}
+
+	filtered := filterRowsByCodes(rows, []string{"300750.SZ", "600519.SH", "688981.SH"})
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "600519.SH", filtered[0].TsCode)
+	assert.Equal(t, "300750.SZ", filtered[1].TsCode)
+
+	assert.Empty(t, filterRowsByCodes(rows, nil))
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 身份变更记录接口的返回数量
+const (
+	defaultIdentityChangeLimit = 100
+	maxIdentityChangeLimit     = 1000
+)
+
+// GetStockIdentityChanges 获取股票更名、代码复用等身份变更记录
+// 可按股票代码过滤，needs_review=true时只返回K线历史待复核的记录
+func (h *Handler) GetStockIdentityChanges(c *gin.Context) {
+	tsCode := strings.ToUpper(c.Query("code"))
+	if tsCode != "" && !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultIdentityChangeLimit)))
+	if err != nil || limit < 1 || limit > maxIdentityChangeLimit {
+		Error(c, CodeInvalidParam, fmt.Sprintf("limit参数错误，应为1-%d之间的整数", maxIdentityChangeLimit))
+		return
+	}
+
+	onlyNeedsReview := c.Query("needs_review") == "true"
+
+	changes, err := repository.NewStockIdentityChange(h.db).List(tsCode, onlyNeedsReview, limit)
+	if err != nil {
+		h.logger.Errorf("Failed to get stock identity changes: %v", err)
+		Error(c, CodeInternalError, "获取股票身份变更记录失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"total": len(changes),
+		"items": changes,
+	})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// stockListCacheTTL 股票列表缓存的有效期
+const stockListCacheTTL = time.Hour
+
+// stockListCacheKey 股票列表在缓存中的键，整个列表作为一个缓存项
+const stockListCacheKey = "stocks"
+
+// stockListCache 股票列表内存缓存
+// 数据源的股票列表需要分页抓取和解析，耗时较长，缓存有效期内的请求直接返回缓存；
+// 缓存过期时并发请求只会触发一次抓取
+type stockListCache struct {
+	cache *utils.TTLCache[string, stockListSnapshot]
+	fetch func() ([]model.Stock, error)
+	now   func() time.Time
+}
+
+// stockListSnapshot 缓存的股票列表及其抓取时间
+type stockListSnapshot struct {
+	stocks    []model.Stock
+	fetchedAt time.Time
+}
+
+// newStockListCache 创建股票列表缓存
+func newStockListCache(ttl time.Duration, fetch func() ([]model.Stock, error)) *stockListCache {
+	return &stockListCache{
+		cache: utils.NewTTLCache[string, stockListSnapshot](ttl),
+		fetch: fetch,
+		now:   time.Now,
+	}
+}
+
+// Get 获取股票列表，缓存过期或为空时从数据源重新抓取，返回列表及其抓取时间
+func (c *stockListCache) Get() ([]model.Stock, time.Time, error) {
+	snapshot, err := c.cache.GetOrCompute(stockListCacheKey, c.load)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return snapshot.stocks, snapshot.fetchedAt, nil
+}
+
+// Refresh 强制从数据源重新抓取股票列表，抓取失败时保留原有缓存
+func (c *stockListCache) Refresh() ([]model.Stock, time.Time, error) {
+	snapshot, err := c.load()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	c.cache.Set(stockListCacheKey, snapshot)
+	return snapshot.stocks, snapshot.fetchedAt, nil
+}
+
+// load 从数据源抓取股票列表
+func (c *stockListCache) load() (stockListSnapshot, error) {
+	stocks, err := c.fetch()
+	if err != nil {
+		return stockListSnapshot{}, err
+	}
+	if stocks == nil {
+		stocks = []model.Stock{}
+	}
+	return stockListSnapshot{stocks: stocks, fetchedAt: c.now()}, nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingCache 创建使用可控时钟的缓存，返回抓取次数计数器
+func newCountingCache(stocks []model.Stock, now *time.Time) (*stockListCache, *int) {
+	calls := 0
+	cache := newStockListCache(time.Hour, func() ([]model.Stock, error) {
+		calls++
+		return stocks, nil
+	})
+	setStockListClock(cache, func() time.Time { return *now })
+	return cache, &calls
+}
+
+// setStockListClock 替换缓存的抓取时间和过期判断使用的时钟
+func setStockListClock(cache *stockListCache, now func() time.Time) {
+	cache.now = now
+	cache.cache.SetClock(now)
+}
+
+func TestStockListCache_TTL(t *testing.T) {
+	now := time.Date(2025, 9, 10, 10, 0, 0, 0, time.Local)
+	cache, calls := newCountingCache([]model.Stock{{TsCode: "000001.SZ"}}, &now)
+
+	stocks, fetchedAt, err := cache.Get()
+	require.NoError(t, err)
+	assert.Len(t, stocks, 1)
+	assert.Equal(t, now, fetchedAt)
+	assert.Equal(t, 1, *calls)
+
+	// 有效期内不再请求数据源
+	now = now.Add(59 * time.Minute)
+	_, fetchedAt, err = cache.Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, now.Add(-59*time.Minute), fetchedAt)
+
+	// 过期后重新抓取
+	now = now.Add(time.Minute)
+	_, _, err = cache.Get()
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+
+	// 强制刷新忽略有效期
+	_, _, err = cache.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestStockListCache_FetchErrorKeepsCache(t *testing.T) {
+	now := time.Date(2025, 9, 10, 10, 0, 0, 0, time.Local)
+	fail := false
+	cache := newStockListCache(time.Hour, func() ([]model.Stock, error) {
+		if fail {
+			return nil, errors.New("upstream down")
+		}
+		return []model.Stock{{TsCode: "600000.SH"}}, nil
+	})
+	setStockListClock(cache, func() time.Time { return now })
+
+	_, _, err := cache.Get()
+	require.NoError(t, err)
+
+	// 刷新失败时原缓存仍可用
+	fail = true
+	_, _, err = cache.Refresh()
+	assert.Error(t, err)
+
+	stocks, _, err := cache.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "600000.SH", stocks[0].TsCode)
+}
+
+func TestHandler_GetStockListUsesCache(t *testing.T) {
+	now := time.Date(2025, 9, 10, 10, 0, 0, 0, time.Local)
+	cache, calls := newCountingCache([]model.Stock{{TsCode: "000001.SZ"}, {TsCode: "600000.SH"}}, &now)
+	h := &Handler{logger: logrus.New(), stockListCache: cache}
+
+	for i := 0; i < 2; i++ {
+		status, resp := performRequest(t, h.GetStockList, http.MethodGet, "/api/v1/stocks/?page=1&size=1", "", nil)
+		require.Equal(t, http.StatusOK, status)
+		data := resp.Data.(map[string]interface{})
+		assert.EqualValues(t, 2, data["total"])
+		assert.Len(t, data["stocks"], 1)
+	}
+	assert.Equal(t, 1, *calls, "第二次请求应命中缓存")
+
+	status, resp := performRequest(t, h.RefreshStockList, http.MethodPost, "/api/v1/stocks/refresh", "", nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.EqualValues(t, 2, resp.Data.(map[string]interface{})["total"])
+	assert.Equal(t, 2, *calls)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stockPriorityRequest 设置股票是否优先同步的请求体
+type stockPriorityRequest struct {
+	TsCodes  []string `json:"ts_codes"` // 股票代码，如600519.SH
+	Priority *bool    `json:"priority"` // true为优先同步，false为取消优先
+}
+
+// GetPriorityStocks 获取优先同步的股票，优先股票不受定时任务每日配额限制
+func (h *Handler) GetPriorityStocks(c *gin.Context) {
+	h.logger.Info("API: Getting priority stocks")
+
+	stocks, err := repository.NewStock(h.db).GetPriorityStocks()
+	if err != nil {
+		h.logger.Errorf("Failed to get priority stocks: %v", err)
+		Error(c, CodeInternalError, "获取优先同步股票失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count":  len(stocks),
+		"stocks": stocks,
+	})
+}
+
+// SetStockPriority 设置股票是否优先同步，返回实际更新的股票数
+func (h *Handler) SetStockPriority(c *gin.Context) {
+	var req stockPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, CodeInvalidParam, "请求参数错误")
+		return
+	}
+	if req.Priority == nil {
+		Error(c, CodeInvalidParam, "priority不能为空")
+		return
+	}
+	if len(req.TsCodes) == 0 {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	tsCodes := make([]string, len(req.TsCodes))
+	for i, code := range req.TsCodes {
+		tsCodes[i] = strings.ToUpper(strings.TrimSpace(code))
+		if !strings.Contains(tsCodes[i], ".") {
+			Error(c, CodeInvalidTsCode, fmt.Sprintf("股票代码格式错误：%s，应为：000001.SZ 或 600000.SH", code))
+			return
+		}
+	}
+
+	h.logger.Infof("API: Setting priority=%v for stocks %v", *req.Priority, tsCodes)
+
+	updated, err := repository.NewStock(h.db).SetStockPriority(tsCodes, *req.Priority)
+	if err != nil {
+		h.logger.Errorf("Failed to set stock priority: %v", err)
+		Error(c, CodeInternalError, "设置优先同步失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"priority": *req.Priority,
+		"updated":  updated,
+	})
+}
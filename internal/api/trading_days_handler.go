@@ -0,0 +1,49 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTradingDays 获取股票在日期区间内有日K线数据的交易日期（YYYYMMDD格式）
+// 日期区间参数与K线查询接口一致，前端结合交易日历标记缺失数据的交易日
+func (h *Handler) GetTradingDays(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	startDate, endDate, _, err := parseKLineDateRange(c.Query("start"), c.Query("end"), c.Query("days"), time.Now())
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	h.logger.Infof("API: Getting trading days for %s (%s ~ %s)", tsCode,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+
+	dates, err := h.klineService.GetTradingDays(tsCode, startDate, endDate)
+	if err != nil {
+		h.logger.Errorf("Failed to get trading days: %v", err)
+		Error(c, CodeInternalError, "获取交易日期失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"code":  tsCode,
+		"start": startDate.Format("2006-01-02"),
+		"end":   endDate.Format("2006-01-02"),
+		"count": len(dates),
+		"dates": dates,
+	})
+}
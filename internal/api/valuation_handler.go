@@ -0,0 +1,111 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"stock/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStockValuation 获取个股估值，市盈率TTM=最新收盘价/滚动四季度每股收益，市净率=最新收盘价/最新一期每股净资产
+// 亏损（滚动每股收益<=0）时不返回市盈率，pe_status为loss
+func (h *Handler) GetStockValuation(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	h.logger.Infof("API: Getting valuation for %s", tsCode)
+
+	valuation, err := service.GetValuationService(h.db).GetValuation(tsCode)
+	if err != nil {
+		h.logger.Errorf("Failed to get valuation for %s: %v", tsCode, err)
+		Error(c, CodeInternalError, "计算估值失败")
+		return
+	}
+	if valuation == nil {
+		Error(c, CodeNotFound, "该股票没有业绩报表数据")
+		return
+	}
+
+	Success(c, valuation)
+}
+
+// parseValuationFilter 解析估值选股的查询参数，失败时已写出错误响应
+func parseValuationFilter(c *gin.Context) (service.ValuationFilter, bool) {
+	var filter service.ValuationFilter
+	for name, target := range map[string]**float64{
+		"max_pe": &filter.MaxPE,
+		"max_pb": &filter.MaxPB,
+	} {
+		v, ok := parseOptionalFloat(c, name)
+		if !ok {
+			Error(c, CodeInvalidParam, name+"参数格式错误，应为数字")
+			return filter, false
+		}
+		*target = v
+	}
+
+	filter.SortBy = c.DefaultQuery("sort_by", "pe")
+	valid := false
+	for _, field := range service.ValuationSortFields {
+		if filter.SortBy == field {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		Error(c, CodeInvalidParam, "sort_by参数错误，可选值："+strings.Join(service.ValuationSortFields, "、"))
+		return filter, false
+	}
+
+	// 估值越低越便宜，默认升序
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+		filter.Asc = true
+	case "desc":
+	default:
+		Error(c, CodeInvalidParam, "order参数错误，可选值：asc、desc")
+		return filter, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > maxScreenLimit {
+		Error(c, CodeInvalidParam, "limit参数错误，应为1-500之间的整数")
+		return filter, false
+	}
+	filter.Limit = limit
+	return filter, true
+}
+
+// ScreenValuation 估值选股，按市盈率TTM或市净率排行，排序指标无法计算（如亏损股票的市盈率）的股票不参与排行
+func (h *Handler) ScreenValuation(c *gin.Context) {
+	filter, ok := parseValuationFilter(c)
+	if !ok {
+		return
+	}
+
+	h.logger.Infof("API: Screening valuations, sort by %s, limit %d", filter.SortBy, filter.Limit)
+
+	results, err := service.GetValuationService(h.db).ScreenValuations(filter)
+	if err != nil {
+		h.logger.Errorf("Failed to screen valuations: %v", err)
+		Error(c, CodeInternalError, "计算估值失败")
+		return
+	}
+
+	Success(c, gin.H{
+		"count":   len(results),
+		"sort_by": filter.SortBy,
+		"stocks":  results,
+	})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 波动率指标接口的默认参数和取值范围
+const (
+	defaultATRPeriod        = 14  // 默认ATR周期
+	defaultVolatilityWindow = 20  // 默认收益率波动率窗口
+	maxVolatilityPeriod     = 250 // ATR周期和波动率窗口的最大值
+	tradingDaysPerYear      = 250 // 年化波动率使用的年交易日数
+)
+
+// VolatilityPoint 单个交易日的波动率指标
+type VolatilityPoint struct {
+	TradeDate  int     `json:"trade_date"` // 交易日期，YYYYMMDD格式
+	TrueRange  float64 `json:"true_range"` // 真实波幅
+	ATR        float64 `json:"atr"`        // 平均真实波幅，不足周期时为0
+	Volatility float64 `json:"volatility"` // 日收益率滚动标准差，不足窗口时为0
+	Annualized float64 `json:"annualized"` // 年化波动率
+}
+
+// VolatilityResult 波动率指标计算结果
+type VolatilityResult struct {
+	TsCode string            `json:"ts_code"` // 股票代码
+	Period int               `json:"period"`  // ATR周期
+	Window int               `json:"window"`  // 收益率波动率窗口
+	Bars   int               `json:"bars"`    // 参与计算的K线数量
+	Points []VolatilityPoint `json:"points"`  // 按交易日期升序排列的指标序列
+}
+
+// analyzeVolatility 按交易日期升序计算ATR和收益率滚动波动率，K线数量不足以得到任何有效值时返回错误
+func analyzeVolatility(tsCode string, data []model.DailyData, period, window int) (*VolatilityResult, error) {
+	need := max(period, window+1)
+	if len(data) < need {
+		return nil, &indicator.InsufficientHistoryError{Indicator: "volatility", Need: need, Have: len(data)}
+	}
+
+	sorted := make([]model.DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TradeDate < sorted[j].TradeDate
+	})
+
+	highs := make([]float64, len(sorted))
+	lows := make([]float64, len(sorted))
+	closes := make([]float64, len(sorted))
+	for i, d := range sorted {
+		highs[i], lows[i], closes[i] = d.High, d.Low, d.Close
+	}
+
+	tr := indicator.TrueRange(highs, lows, closes)
+	atr := indicator.ATR(highs, lows, closes, period)
+	volatility := indicator.ReturnVolatility(closes, window)
+
+	points := make([]VolatilityPoint, len(sorted))
+	for i, d := range sorted {
+		points[i] = VolatilityPoint{
+			TradeDate:  d.TradeDate,
+			TrueRange:  tr[i],
+			ATR:        atr[i],
+			Volatility: volatility[i],
+			Annualized: volatility[i] * math.Sqrt(tradingDaysPerYear),
+		}
+	}
+
+	return &VolatilityResult{
+		TsCode: tsCode,
+		Period: period,
+		Window: window,
+		Bars:   len(sorted),
+		Points: points,
+	}, nil
+}
+
+// parseVolatilityParam 解析ATR周期或波动率窗口参数，为空时使用默认值
+func parseVolatilityParam(value string, defaultValue int) (int, bool) {
+	if value == "" {
+		return defaultValue, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 2 || n > maxVolatilityPeriod {
+		return 0, false
+	}
+	return n, true
+}
+
+// GetVolatility 获取ATR（平均真实波幅）和日收益率滚动波动率
+func (h *Handler) GetVolatility(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+
+	// 转换股票代码格式
+	tsCode := strings.ToUpper(code)
+	if !strings.Contains(tsCode, ".") {
+		Error(c, CodeInvalidTsCode, "股票代码格式错误，应为：000001.SZ 或 600000.SH")
+		return
+	}
+
+	period, ok := parseVolatilityParam(c.Query("period"), defaultATRPeriod)
+	if !ok {
+		Error(c, CodeInvalidParam, fmt.Sprintf("period参数错误，应为2-%d之间的整数", maxVolatilityPeriod))
+		return
+	}
+	window, ok := parseVolatilityParam(c.Query("window"), defaultVolatilityWindow)
+	if !ok {
+		Error(c, CodeInvalidParam, fmt.Sprintf("window参数错误，应为2-%d之间的整数", maxVolatilityPeriod))
+		return
+	}
+
+	bars, err := strconv.Atoi(c.DefaultQuery("bars", strconv.Itoa(defaultSignalBars)))
+	if err != nil || bars < 1 || bars > maxSignalBars {
+		Error(c, CodeInvalidParam, fmt.Sprintf("bars参数错误，应为1-%d之间的整数", maxSignalBars))
+		return
+	}
+
+	h.logger.Infof("API: Getting volatility for %s, period: %d, window: %d, bars: %d", tsCode, period, window, bars)
+
+	// 取最近bars根日K线，跳过已标记的问题数据区间
+	data, err := repository.NewDailyData(h.db).GetAnalysisDailyData(tsCode, time.Time{}, time.Time{}, bars)
+	if err != nil {
+		h.logger.Errorf("Failed to get daily data from database: %v", err)
+		Error(c, CodeInternalError, "获取K线数据失败")
+		return
+	}
+	if len(data) == 0 {
+		Error(c, CodeNotFound, "数据库中没有该股票的K线数据")
+		return
+	}
+
+	result, err := analyzeVolatility(tsCode, data, period, window)
+	if err != nil {
+		Error(c, CodeInvalidParam, err.Error())
+		return
+	}
+
+	Success(c, result)
+}
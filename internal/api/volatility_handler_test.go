@@ -0,0 +1,30 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeVolatility(t *testing.T) {
+	data := newWaveDailyData(60)
+
+	result, err := analyzeVolatility("000001.SZ", data, 14, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 60, result.Bars)
+	require.Len(t, result.Points, 60)
+
+	// 按交易日期升序输出，不足周期和窗口的位置为0
+	assert.Equal(t, 20240101, result.Points[0].TradeDate)
+	assert.Equal(t, data[0].TradeDate, result.Points[59].TradeDate)
+	assert.Zero(t, result.Points[12].ATR)
+	assert.Greater(t, result.Points[13].ATR, 0.0)
+	assert.Zero(t, result.Points[19].Volatility)
+	assert.Greater(t, result.Points[20].Volatility, 0.0)
+	assert.Greater(t, result.Points[20].Annualized, result.Points[20].Volatility)
+
+	// 波动率窗口需要window+1根K线
+	_, err = analyzeVolatility("000001.SZ", data[:20], 14, 20)
+	assert.EqualError(t, err, "insufficient history: need 21 bars, have 20")
+}
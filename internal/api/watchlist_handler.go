@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/service"
+	"stock/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 自选股立即同步的参数
+const (
+	watchlistSyncConcurrency = 5               // 同时同步的股票数，避免触发数据源限流
+	watchlistSyncTimeout     = 2 * time.Minute // 单只股票的同步超时时间
+	watchlistSyncKLineDays   = 365             // 刷新最近一年的日K线
+	maxWatchlistStocks       = 200             // 单个自选股列表最多包含的股票数
+)
+
+// StockSyncResult 单只股票的同步结果
+type StockSyncResult struct {
+	TsCode     string `json:"ts_code"`         // 股票代码
+	Success    bool   `json:"success"`         // 是否同步成功
+	Error      string `json:"error,omitempty"` // 失败原因
+	DurationMs int64  `json:"duration_ms"`     // 同步耗时，单位：毫秒
+}
+
+// WatchlistSyncResult 自选股列表同步结果
+type WatchlistSyncResult struct {
+	WatchlistID uint              `json:"watchlist_id"` // 自选股列表ID
+	Name        string            `json:"name"`         // 自选股列表名称
+	Total       int               `json:"total"`        // 股票总数
+	Success     int               `json:"success"`      // 同步成功数
+	Failed      int               `json:"failed"`       // 同步失败数
+	DurationMs  int64             `json:"duration_ms"`  // 总耗时，单位：毫秒
+	Results     []StockSyncResult `json:"results"`      // 每只股票的同步结果，与列表顺序一致
+}
+
+// syncStocksConcurrently 使用并发执行器逐只同步股票，返回与codes顺序一致的同步结果
+func syncStocksConcurrently(ctx context.Context, codes []string, concurrency int, timeout time.Duration,
+	syncFn func(ctx context.Context, tsCode string) error) []StockSyncResult {
+	tasks := make([]utils.Task, len(codes))
+	for i, code := range codes {
+		tsCode := code
+		tasks[i] = &utils.SimpleTask{
+			ID:          tsCode,
+			Description: "同步自选股 " + tsCode,
+			Func: func(ctx context.Context) error {
+				return syncFn(ctx, tsCode)
+			},
+		}
+	}
+
+	taskResults, _ := utils.NewConcurrentExecutor(concurrency, timeout).ExecuteBatch(ctx, tasks)
+
+	results := make([]StockSyncResult, len(codes))
+	for i, code := range codes {
+		results[i] = StockSyncResult{TsCode: code}
+		if i >= len(taskResults) || taskResults[i] == nil {
+			results[i].Error = "同步未执行"
+			continue
+		}
+		tr := taskResults[i]
+		results[i].Success = tr.Success
+		results[i].DurationMs = tr.Duration.Milliseconds()
+		if tr.Error != nil {
+			results[i].Error = tr.Error.Error()
+		}
+	}
+	return results
+}
+
+// syncStockNow 立即同步单只股票：刷新日K线、最新业绩报表，并重新计算综合评分
+func (h *Handler) syncStockNow(ctx context.Context, tsCode string) error {
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -watchlistSyncKLineDays)
+	if _, err := h.klineService.RefreshKLineData(tsCode, startDate, endDate); err != nil {
+		return fmt.Errorf("refresh kline: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	reports, err := h.collectorManager.GetPerformanceReportsFromSource("eastmoney", tsCode)
+	if err != nil {
+		return fmt.Errorf("fetch performance reports: %w", err)
+	}
+	if len(reports) > 0 {
+		if err := repository.NewPerformance(h.db).UpsertBatch(reports); err != nil {
+			return fmt.Errorf("save performance reports: %w", err)
+		}
+	}
+
+	// 新股数据不足无法评分不视为同步失败
+	if _, err := h.scoreService.ScoreAndSave(tsCode); err != nil && !errors.Is(err, service.ErrInsufficientScoreData) {
+		return fmt.Errorf("compute score: %w", err)
+	}
+	return nil
+}
+
+// CreateWatchlist 创建自选股列表
+func (h *Handler) CreateWatchlist(c *gin.Context) {
+	var req struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Codes       []string `json:"codes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Error(c, CodeInvalidParam, "请求参数错误")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		Error(c, CodeInvalidParam, "自选股列表名称不能为空")
+		return
+	}
+
+	watchlist := &model.Watchlist{Name: name, Description: req.Description}
+	watchlist.SetCodes(req.Codes)
+	codes := watchlist.Codes()
+	if len(codes) == 0 {
+		Error(c, CodeEmptyTsCode, "股票代码不能为空")
+		return
+	}
+	if len(codes) > maxWatchlistStocks {
+		Error(c, CodeInvalidParam, fmt.Sprintf("自选股列表最多包含%d只股票", maxWatchlistStocks))
+		return
+	}
+	for _, code := range codes {
+		if !strings.Contains(code, ".") {
+			Error(c, CodeInvalidTsCode, fmt.Sprintf("股票代码格式错误：%s，应为：000001.SZ 或 600000.SH", code))
+			return
+		}
+	}
+
+	if err := repository.NewWatchlist(h.db).Create(watchlist); err != nil {
+		h.logger.Errorf("Failed to create watchlist: %v", err)
+		Error(c, CodeInternalError, "创建自选股列表失败")
+		return
+	}
+
+	Success(c, watchlist)
+}
+
+// GetWatchlists 获取所有自选股列表
+func (h *Handler) GetWatchlists(c *gin.Context) {
+	watchlists, err := repository.NewWatchlist(h.db).GetAll()
+	if err != nil {
+		h.logger.Errorf("Failed to get watchlists: %v", err)
+		Error(c, CodeInternalError, "获取自选股列表失败")
+		return
+	}
+
+	Success(c, watchlists)
+}
+
+// SyncWatchlist 立即同步自选股列表中的所有股票，同步完成后返回每只股票的结果
+func (h *Handler) SyncWatchlist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		Error(c, CodeInvalidParam, "自选股列表ID错误")
+		return
+	}
+
+	watchlist, err := repository.NewWatchlist(h.db).GetByID(uint(id))
+	if err != nil {
+		h.logger.Errorf("Failed to get watchlist: %v", err)
+		Error(c, CodeInternalError, "获取自选股列表失败")
+		return
+	}
+	if watchlist == nil {
+		Error(c, CodeNotFound, "自选股列表不存在")
+		return
+	}
+
+	codes := watchlist.Codes()
+	h.logger.Infof("API: Syncing watchlist %s (%d stocks)", watchlist.Name, len(codes))
+
+	start := time.Now()
+	results := syncStocksConcurrently(c.Request.Context(), codes, watchlistSyncConcurrency, watchlistSyncTimeout, h.syncStockNow)
+
+	summary := WatchlistSyncResult{
+		WatchlistID: watchlist.ID,
+		Name:        watchlist.Name,
+		Total:       len(results),
+		DurationMs:  time.Since(start).Milliseconds(),
+		Results:     results,
+	}
+	for _, r := range results {
+		if r.Success {
+			summary.Success++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	h.logger.Infof("Watchlist %s synced: %d succeeded, %d failed", watchlist.Name, summary.Success, summary.Failed)
+	Success(c, summary)
+}
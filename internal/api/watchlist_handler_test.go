@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncStocksConcurrently(t *testing.T) {
+	watchlist := &model.Watchlist{Name: "核心持仓"}
+	watchlist.SetCodes([]string{"600519.sh", "000001.SZ", "300750.SZ", "600519.SH"})
+
+	var calls int32
+	results := syncStocksConcurrently(context.Background(), watchlist.Codes(), 2, time.Second,
+		func(ctx context.Context, tsCode string) error {
+			atomic.AddInt32(&calls, 1)
+			if tsCode == "000001.SZ" {
+				return errors.New("refresh kline: data source unavailable")
+			}
+			return nil
+		})
+
+	// 重复代码只同步一次，结果与列表顺序一致
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	require.Len(t, results, 3)
+	assert.Equal(t, "600519.SH", results[0].TsCode)
+	assert.True(t, results[0].Success)
+	assert.Empty(t, results[0].Error)
+
+	assert.Equal(t, "000001.SZ", results[1].TsCode)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "refresh kline: data source unavailable", results[1].Error)
+
+	assert.Equal(t, "300750.SZ", results[2].TsCode)
+	assert.True(t, results[2].Success)
+}
+
+func TestSyncStocksConcurrently_Timeout(t *testing.T) {
+	results := syncStocksConcurrently(context.Background(), []string{"600519.SH"}, 1, 10*time.Millisecond,
+		func(ctx context.Context, tsCode string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "deadline exceeded")
+}
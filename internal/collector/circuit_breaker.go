@@ -0,0 +1,249 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"stock/internal/logger"
+)
+
+// 熔断器的默认参数
+const (
+	defaultCircuitFailureThreshold = 5                // 窗口内连续失败多少次后熔断
+	defaultCircuitWindow           = time.Minute      // 统计连续失败的时间窗口
+	defaultCircuitCooldown         = 30 * time.Second // 熔断后等待多久放行一次试探请求
+)
+
+// ErrCircuitOpen 采集器处于熔断状态，请求未发出直接失败
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig 采集器熔断配置
+type CircuitBreakerConfig struct {
+	Enabled          bool              `mapstructure:"enabled"`           // 是否为采集器开启熔断
+	FailureThreshold int               `mapstructure:"failure_threshold"` // 窗口内连续失败多少次后熔断，<=0时使用5
+	Window           time.Duration     `mapstructure:"window"`            // 统计连续失败的时间窗口，首次失败超过窗口后重新计数，<=0时使用1m
+	Cooldown         time.Duration     `mapstructure:"cooldown"`          // 熔断持续时间，之后放行一次试探请求，<=0时使用30s
+	Failover         map[string]string `mapstructure:"failover"`          // 熔断时改用的采集器，键为采集器名称，如eastmoney: tonghuashun
+}
+
+// CircuitState 熔断器状态
+type CircuitState string
+
+// 熔断器状态
+const (
+	CircuitClosed   CircuitState = "closed"    // 正常放行请求
+	CircuitOpen     CircuitState = "open"      // 熔断中，请求直接失败
+	CircuitHalfOpen CircuitState = "half_open" // 熔断时间已过，放行一次试探请求，成功后恢复，失败后重新熔断
+)
+
+// CircuitStats 单个采集器的熔断状态
+type CircuitStats struct {
+	Collector string       `json:"collector"`          // 采集器名称
+	State     CircuitState `json:"state"`              // 当前状态
+	Failures  int          `json:"failures"`           // 当前窗口内的连续失败次数
+	Opens     int64        `json:"opens"`              // 自启动以来熔断的次数
+	Rejected  int64        `json:"rejected"`           // 熔断期间直接拒绝的请求数
+	RetryAt   *time.Time   `json:"retry_at,omitempty"` // 熔断中时下次放行试探请求的时间
+}
+
+// CircuitBreaker 采集器熔断器：窗口内连续失败达到阈值后熔断，熔断期间请求直接失败，不再请求已失效或正在封禁的上游
+// 冷却时间过后进入半开状态，只放行一次试探请求，成功则恢复，失败则重新熔断；并发安全
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state        CircuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool // 半开状态下是否已放行试探请求
+	opens        int64
+	rejected     int64
+}
+
+// NewCircuitBreaker 创建采集器熔断器，配置中未设置的参数使用默认值
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	b := &CircuitBreaker{
+		name:      name,
+		threshold: config.FailureThreshold,
+		window:    config.Window,
+		cooldown:  config.Cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+	if b.threshold <= 0 {
+		b.threshold = defaultCircuitFailureThreshold
+	}
+	if b.window <= 0 {
+		b.window = defaultCircuitWindow
+	}
+	if b.cooldown <= 0 {
+		b.cooldown = defaultCircuitCooldown
+	}
+	return b
+}
+
+// Allow 判断是否放行请求，熔断中返回ErrCircuitOpen；放行后须调用Record记录结果
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case CircuitOpen:
+		b.rejected++
+		return fmt.Errorf("%s: %w, retry after %s", b.name, ErrCircuitOpen, b.openedAt.Add(b.cooldown).Format(time.TimeOnly))
+	case CircuitHalfOpen:
+		if b.probing {
+			b.rejected++
+			return fmt.Errorf("%s: %w, waiting for probe request", b.name, ErrCircuitOpen)
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+	}
+	return nil
+}
+
+// Record 记录一次放行请求的结果
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+		if failed {
+			b.open(now)
+			return
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+		logger.Infof("Circuit breaker for %s closed, upstream recovered", b.name)
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.window {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+	if b.state == CircuitClosed && b.failures >= b.threshold {
+		b.open(now)
+	}
+}
+
+// open 进入熔断状态
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.failures = 0
+	b.opens++
+	logger.Warnf("Circuit breaker for %s opened, requests fail fast for %v", b.name, b.cooldown)
+}
+
+// currentState 熔断时间已过时按半开状态处理，调用方须持有锁
+func (b *CircuitBreaker) currentState() CircuitState {
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// State 获取当前状态
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// Snapshot 获取当前的熔断状态
+func (b *CircuitBreaker) Snapshot() CircuitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := CircuitStats{
+		Collector: b.name,
+		State:     b.currentState(),
+		Failures:  b.failures,
+		Opens:     b.opens,
+		Rejected:  b.rejected,
+	}
+	if stats.State == CircuitOpen {
+		retryAt := b.openedAt.Add(b.cooldown)
+		stats.RetryAt = &retryAt
+	}
+	return stats
+}
+
+// CircuitSnapshot 获取一组熔断器的状态，按采集器名称排序
+func CircuitSnapshot(breakers map[string]*CircuitBreaker) []CircuitStats {
+	result := make([]CircuitStats, 0, len(breakers))
+	for _, breaker := range breakers {
+		result = append(result, breaker.Snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Collector < result[j].Collector })
+	return result
+}
+
+// circuitTransport 经过熔断器的http.RoundTripper，网络错误、5xx以及403、429（上游封禁或限流）计为失败
+type circuitTransport struct {
+	next    http.RoundTripper
+	breaker *CircuitBreaker
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *circuitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.breaker.Record(true)
+		return nil, err
+	}
+	t.breaker.Record(resp.StatusCode >= 500 || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests)
+	return resp, nil
+}
+
+// installCircuitBreaker 为HTTP客户端开启熔断，breaker为nil时恢复原有的Transport
+func installCircuitBreaker(client *http.Client, breaker *CircuitBreaker) {
+	next := client.Transport
+	if existing, ok := next.(*circuitTransport); ok {
+		next = existing.next
+	}
+	if breaker == nil {
+		client.Transport = next
+		return
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &circuitTransport{next: next, breaker: breaker}
+}
+
+// SetCircuitBreaker 开启熔断，breaker为nil时关闭，应在开始采集前调用
+func (e *EastMoneyCollector) SetCircuitBreaker(breaker *CircuitBreaker) {
+	installCircuitBreaker(e.client, breaker)
+}
+
+// SetCircuitBreaker 开启熔断，breaker为nil时关闭，应在开始采集前调用
+func (t *TongHuaShunCollector) SetCircuitBreaker(breaker *CircuitBreaker) {
+	installCircuitBreaker(t.client, breaker)
+}
+
+// SetCircuitBreaker 开启熔断，breaker为nil时关闭，应在开始采集前调用
+func (h *HTTPCollector) SetCircuitBreaker(breaker *CircuitBreaker) {
+	installCircuitBreaker(h.client, breaker)
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCircuitBreaker 创建使用可控时钟的熔断器
+func newTestCircuitBreaker(threshold int, window, cooldown time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	b := NewCircuitBreaker("eastmoney", CircuitBreakerConfig{FailureThreshold: threshold, Window: window, Cooldown: cooldown})
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	b, now := newTestCircuitBreaker(3, time.Minute, 30*time.Second)
+
+	// 连续失败未达到阈值时保持闭合，成功后重新计数
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(true)
+	}
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Zero(t, b.Snapshot().Failures)
+
+	// 连续失败达到阈值后熔断，请求直接失败
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(true)
+	}
+	assert.Equal(t, CircuitOpen, b.State())
+	err := b.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	stats := b.Snapshot()
+	assert.Equal(t, int64(1), stats.Opens)
+	assert.Equal(t, int64(1), stats.Rejected)
+	require.NotNil(t, stats.RetryAt)
+	assert.Equal(t, now.Add(30*time.Second), *stats.RetryAt)
+
+	// 冷却后半开，只放行一次试探请求，试探失败重新熔断
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen, "试探请求未返回前不放行其他请求")
+	b.Record(true)
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.Equal(t, int64(2), b.Snapshot().Opens)
+
+	// 再次冷却后试探成功，恢复闭合
+	*now = now.Add(30 * time.Second)
+	require.NoError(t, b.Allow())
+	b.Record(false)
+	assert.Equal(t, CircuitClosed, b.State())
+	require.NoError(t, b.Allow())
+	assert.Nil(t, b.Snapshot().RetryAt)
+}
+
+func TestCircuitBreaker_FailuresOutsideWindow(t *testing.T) {
+	b, now := newTestCircuitBreaker(3, time.Minute, 30*time.Second)
+
+	// 首次失败超过窗口后重新计数，零星失败不会熔断
+	b.Record(true)
+	b.Record(true)
+	*now = now.Add(2 * time.Minute)
+	b.Record(true)
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Equal(t, 1, b.Snapshot().Failures)
+
+	b.Record(true)
+	b.Record(true)
+	assert.Equal(t, CircuitOpen, b.State())
+}
+
+func TestCircuitBreaker_Transport(t *testing.T) {
+	requests := 0
+	status := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	b, now := newTestCircuitBreaker(2, time.Minute, time.Minute)
+	client := &http.Client{}
+	installCircuitBreaker(client, b)
+	installCircuitBreaker(client, b) // 重复开启不会重复包装
+
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	// 上游封禁返回403，两次后熔断，之后不再请求上游
+	require.NoError(t, get())
+	require.NoError(t, get())
+	err := get()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, requests)
+
+	// 冷却后放行试探请求，上游恢复后闭合
+	status = http.StatusOK
+	*now = now.Add(time.Minute)
+	require.NoError(t, get())
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	installCircuitBreaker(client, nil)
+	assert.Equal(t, http.DefaultTransport, client.Transport)
+}
@@ -42,7 +42,10 @@ var (
 
 // CollectorFactory 采集器工厂
 type CollectorFactory struct {
-	logger *logger.Logger
+	logger       *logger.Logger
+	requestStats *RequestStats // 上游请求统计，未开启时为nil
+
+	circuitBreakers map[string]*CircuitBreaker // 各采集器的熔断器，键为采集器名称，未开启时为nil
 }
 
 // GetCollectorFactory 获取采集器工厂单例
@@ -220,6 +223,148 @@ func (f *CollectorFactory) ResetCollectors() {
 	f.logger.Info("All collector instances have been reset")
 }
 
+// ApplyHeaderOverrides 按采集器名称设置自定义请求头、Cookie和请求头伪装配置，名称为eastmoney、tonghuashun、tushare、akshare
+// 伪装配置名称无效时记录警告并保持随机生成的User-Agent
+func (f *CollectorFactory) ApplyHeaderOverrides(overrides map[string]HeaderOverride) {
+	for name, override := range overrides {
+		var target interface {
+			SetHeaderOverride(HeaderOverride)
+			SetHeaderProfile(string) error
+		}
+		switch CollectorType(name) {
+		case CollectorTypeEastMoney:
+			target = f.GetEastMoneyCollector()
+		case CollectorTypeTongHuaShun:
+			target = f.GetTongHuaShunCollector()
+		case CollectorTypeTushare:
+			target = f.GetTushareCollector()
+		case CollectorTypeAKShare:
+			target = f.GetAKShareCollector()
+		default:
+			f.logger.Warnf("Ignoring header override for unknown collector: %s", name)
+			continue
+		}
+
+		target.SetHeaderOverride(override)
+		if setter, ok := target.(interface{ SetKLineFallbackURL(string) }); ok {
+			setter.SetKLineFallbackURL(override.KLineFallbackURL)
+		}
+		if err := target.SetHeaderProfile(override.Profile); err != nil {
+			f.logger.Warnf("Ignoring header profile for %s collector: %v", name, err)
+		}
+		f.logger.Infof("Applied header override to %s collector: %d headers, cookie set: %v, profile: %q",
+			name, len(override.Headers), override.Cookie != "", override.Profile)
+	}
+}
+
+// ApplyResponseCache 按配置为所有采集器启用共享的响应缓存，未启用时不做任何修改
+func (f *CollectorFactory) ApplyResponseCache(config ResponseCacheConfig) {
+	if !config.Enabled {
+		return
+	}
+
+	cache := NewResponseCache(config)
+	f.GetEastMoneyCollector().SetResponseCache(cache)
+	f.GetTongHuaShunCollector().SetResponseCache(cache)
+	f.GetTushareCollector().SetResponseCache(cache)
+	f.GetAKShareCollector().SetResponseCache(cache)
+	f.logger.Warnf("Collector response cache enabled: ttl=%s, dir=%q, repeated requests are served from cache", config.TTL, config.Dir)
+}
+
+// ApplyRequestStats 按配置为所有采集器开启共享的上游请求统计，未启用时不做任何修改
+func (f *CollectorFactory) ApplyRequestStats(config RequestStatsConfig) {
+	if !config.Enabled {
+		return
+	}
+
+	stats := NewRequestStats(config.SampleSize)
+	f.GetEastMoneyCollector().SetRequestStats(stats)
+	f.GetTongHuaShunCollector().SetRequestStats(stats)
+	f.GetTushareCollector().SetRequestStats(stats)
+	f.GetAKShareCollector().SetRequestStats(stats)
+	f.requestStats = stats
+	f.logger.Infof("Upstream request stats enabled, per-request logs are written in debug mode")
+}
+
+// RequestStats 获取上游请求统计，未开启时返回nil
+func (f *CollectorFactory) RequestStats() *RequestStats {
+	return f.requestStats
+}
+
+// ApplyCircuitBreaker 按配置为每个采集器开启独立的熔断器，未启用时不做任何修改
+func (f *CollectorFactory) ApplyCircuitBreaker(config CircuitBreakerConfig) {
+	if !config.Enabled {
+		return
+	}
+
+	eastMoney := f.GetEastMoneyCollector()
+	tongHuaShun := f.GetTongHuaShunCollector()
+	tushare := f.GetTushareCollector()
+	akshare := f.GetAKShareCollector()
+	breakers := map[string]*CircuitBreaker{
+		eastMoney.GetName():   NewCircuitBreaker(eastMoney.GetName(), config),
+		tongHuaShun.GetName(): NewCircuitBreaker(tongHuaShun.GetName(), config),
+		tushare.GetName():     NewCircuitBreaker(tushare.GetName(), config),
+		akshare.GetName():     NewCircuitBreaker(akshare.GetName(), config),
+	}
+	eastMoney.SetCircuitBreaker(breakers[eastMoney.GetName()])
+	tongHuaShun.SetCircuitBreaker(breakers[tongHuaShun.GetName()])
+	tushare.SetCircuitBreaker(breakers[tushare.GetName()])
+	akshare.SetCircuitBreaker(breakers[akshare.GetName()])
+	f.circuitBreakers = breakers
+	f.logger.Infof("Collector circuit breakers enabled")
+}
+
+// CircuitBreakers 获取各采集器的熔断器，键为采集器名称，未开启时返回nil
+func (f *CollectorFactory) CircuitBreakers() map[string]*CircuitBreaker {
+	return f.circuitBreakers
+}
+
+// ApplyFailover 为采集器管理器设置熔断器和熔断时改用的采集器，故障切换涉及的采集器未注册时自动注册
+// 未开启熔断时不做任何修改，应在ApplyCircuitBreaker之后调用
+func (f *CollectorFactory) ApplyFailover(m *CollectorManager, config CircuitBreakerConfig) error {
+	if !config.Enabled || f.circuitBreakers == nil {
+		return nil
+	}
+
+	for primary, fallback := range config.Failover {
+		for _, name := range []string{primary, fallback} {
+			if _, exists := m.LookupCollector(name); exists {
+				continue
+			}
+			collector, err := f.CreateCollector(CollectorType(name))
+			if err != nil {
+				return fmt.Errorf("failed to create failover collector %s: %w", name, err)
+			}
+			if err := m.RegisterCollector(collector); err != nil {
+				return err
+			}
+		}
+	}
+	m.SetCircuitBreakers(f.circuitBreakers, config.Failover)
+	return nil
+}
+
+// NewFailoverManager 创建按熔断配置故障切换的采集器管理器，供直接使用工厂采集器的任务使用，未开启熔断时返回nil
+func (f *CollectorFactory) NewFailoverManager(config CircuitBreakerConfig) (*CollectorManager, error) {
+	if !config.Enabled || f.circuitBreakers == nil {
+		return nil, nil
+	}
+	m := NewCollectorManager(f.logger)
+	if err := f.ApplyFailover(m, config); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ApplyDebug 设置所有采集器的调试模式，开启后JSON解析错误中附带请求URL和响应片段
+func (f *CollectorFactory) ApplyDebug(enabled bool) {
+	f.GetEastMoneyCollector().SetDebug(enabled)
+	f.GetTongHuaShunCollector().SetDebug(enabled)
+	f.GetTushareCollector().SetDebug(enabled)
+	f.GetAKShareCollector().SetDebug(enabled)
+}
+
 // GetSupportedCollectors 获取支持的采集器类型列表
 func (f *CollectorFactory) GetSupportedCollectors() []CollectorType {
 	return []CollectorType{
@@ -2,7 +2,6 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +10,7 @@ import (
 	"stock/internal/utils"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"stock/internal/logger"
@@ -40,13 +40,20 @@ type KLineResponse struct {
 	RC   int `json:"rc"`
 	RT   int `json:"rt"`
 	Data struct {
-		Code   string   `json:"code"`
-		Market int      `json:"market"`
-		Name   string   `json:"name"`
-		Klines []string `json:"klines"`
+		Code    string   `json:"code"`
+		Market  int      `json:"market"`
+		Name    string   `json:"name"`
+		Dktotal int      `json:"dktotal"` // 该证券的K线总数，不受请求的日期范围影响
+		Klines  []string `json:"klines"`
 	} `json:"data"`
 }
 
+// mayHaveHistory 响应中没有K线，但证券可能仍有历史数据：证券ID未被识别（data为空），或接口报告的K线总数大于0
+// 部分退市或特殊证券在标准接口返回空，可以通过备用接口或另一市场的证券ID取回历史数据
+func (r *KLineResponse) mayHaveHistory() bool {
+	return len(r.Data.Klines) == 0 && (r.Data.Code == "" || r.Data.Dktotal > 0)
+}
+
 // EastMoneyCollector 东方财富数据采集器
 type EastMoneyCollector struct {
 	BaseCollector
@@ -59,6 +66,10 @@ type EastMoneyCollector struct {
 	currentUA      string
 	currentCookie  string
 	lastUpdateTime time.Time
+
+	stockListConcurrency int // 分页抓取股票列表的并发数，<=1时逐页串行抓取
+
+	klineFallbackURL string // 标准K线接口返回空时使用的备用K线接口，为空时使用eastMoneyKLineFallbackURL
 }
 
 // newEastMoneyCollector 创建东方财富采集器
@@ -133,6 +144,11 @@ func (e *EastMoneyCollector) makeRequest(url, refer string) (*http.Response, err
 
 // makeRequestWithContext 发送HTTP请求（带限流和上下文）
 func (e *EastMoneyCollector) makeRequestWithContext(ctx context.Context, url, refer string) (*http.Response, error) {
+	// 命中响应缓存时不访问上游，也不占用限流配额
+	if resp := e.cachedResponse(url); resp != nil {
+		return resp, nil
+	}
+
 	// 应用限流
 	if err := e.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait failed: %v", err)
@@ -163,6 +179,7 @@ func (e *EastMoneyCollector) makeRequestWithContext(ctx context.Context, url, re
 	req.Header.Set("sec-ch-ua", e.userAgentGen.GenerateSecChUa(e.currentUA))
 	req.Header.Set("sec-ch-ua-platform", e.getPlatformFromUA(e.currentUA))
 	req.Header.Set("Referer", refer)
+	e.applyHeaderOverride(req)
 
 	e.logger.Debugf("Making rate-limited request with random UA: %s", url)
 
@@ -177,7 +194,7 @@ func (e *EastMoneyCollector) makeRequestWithContext(ctx context.Context, url, re
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	return resp, nil
+	return e.cacheResponse(url, resp)
 }
 
 // SetRateLimit 动态设置限流速率
@@ -217,33 +234,45 @@ type EastMoneyStockListResponse struct {
 	FULL   int    `json:"full"`
 	DLMKTS string `json:"dlmkts"`
 	Data   struct {
-		Total int `json:"total"`
-		Diff  []struct {
-			F1   interface{} `json:"f1"`   // 未知字段
-			F2   interface{} `json:"f2"`   // 最新价
-			F3   interface{} `json:"f3"`   // 涨跌幅
-			F12  string      `json:"f12"`  // 股票代码
-			F13  int         `json:"f13"`  // 市场标识 0=深市 1=沪市
-			F14  string      `json:"f14"`  // 股票名称
-			F62  interface{} `json:"f62"`  // 主力净流入
-			F66  interface{} `json:"f66"`  // 超大单净流入
-			F69  interface{} `json:"f69"`  // 超大单净流入占比
-			F72  interface{} `json:"f72"`  // 大单净流入
-			F75  interface{} `json:"f75"`  // 大单净流入占比
-			F78  interface{} `json:"f78"`  // 中单净流入
-			F81  interface{} `json:"f81"`  // 中单净流入占比
-			F84  interface{} `json:"f84"`  // 小单净流入
-			F87  interface{} `json:"f87"`  // 小单净流入占比
-			F124 interface{} `json:"f124"` // 更新时间戳
-			F184 interface{} `json:"f184"` // 主力净流入占比
-			F204 interface{} `json:"f204"` // 5日主力净流入
-			F205 interface{} `json:"f205"` // 10日主力净流入
-		} `json:"diff"`
+		Total int                      `json:"total"`
+		Diff  []EastMoneyStockListItem `json:"diff"`
 	} `json:"data"`
 }
 
+// EastMoneyStockListItem 东方财富股票列表中的单只股票，除基础信息外还包含当日资金流向
+type EastMoneyStockListItem struct {
+	F1   interface{} `json:"f1"`   // 未知字段
+	F2   interface{} `json:"f2"`   // 最新价
+	F3   interface{} `json:"f3"`   // 涨跌幅
+	F12  string      `json:"f12"`  // 股票代码
+	F13  int         `json:"f13"`  // 市场标识 0=深市 1=沪市
+	F14  string      `json:"f14"`  // 股票名称
+	F26  interface{} `json:"f26"`  // 上市日期，YYYYMMDD格式
+	F62  interface{} `json:"f62"`  // 主力净流入
+	F66  interface{} `json:"f66"`  // 超大单净流入
+	F69  interface{} `json:"f69"`  // 超大单净流入占比
+	F72  interface{} `json:"f72"`  // 大单净流入
+	F75  interface{} `json:"f75"`  // 大单净流入占比
+	F78  interface{} `json:"f78"`  // 中单净流入
+	F81  interface{} `json:"f81"`  // 中单净流入占比
+	F84  interface{} `json:"f84"`  // 小单净流入
+	F87  interface{} `json:"f87"`  // 小单净流入占比
+	F124 interface{} `json:"f124"` // 更新时间戳
+	F184 interface{} `json:"f184"` // 主力净流入占比
+	F204 interface{} `json:"f204"` // 5日主力净流入
+	F205 interface{} `json:"f205"` // 10日主力净流入
+}
+
+// aShareListFilter 行情中心列表接口的市场筛选参数 - 所有A股
+const aShareListFilter = "m:0+t:6+f:!2,m:0+t:13+f:!2,m:0+t:80+f:!2,m:1+t:2+f:!2,m:1+t:23+f:!2,m:0+t:7+f:!2,m:1+t:3+f:!2"
+
 // fetchStockListPage 获取股票列表分页数据
 func (e *EastMoneyCollector) fetchStockListPage(page, pageSize int) (*EastMoneyStockListResponse, error) {
+	return e.fetchListPage(aShareListFilter, page, pageSize)
+}
+
+// fetchListPage 按筛选参数获取行情中心列表的分页数据，filter为市场或板块筛选条件，如：b:BK0500
+func (e *EastMoneyCollector) fetchListPage(filter string, page, pageSize int) (*EastMoneyStockListResponse, error) {
 	// 构建请求URL
 	baseURL := "https://push2.eastmoney.com/api/qt/clist/get"
 	params := url.Values{}
@@ -259,11 +288,11 @@ func (e *EastMoneyCollector) fetchStockListPage(page, pageSize int) (*EastMoneyS
 	params.Set("invt", "2")
 	params.Set("ut", "8dec03ba335b81bf4ebdf7b29ec27d15")
 
-	// 市场筛选参数 - 所有A股
-	params.Set("fs", "m:0+t:6+f:!2,m:0+t:13+f:!2,m:0+t:80+f:!2,m:1+t:2+f:!2,m:1+t:23+f:!2,m:0+t:7+f:!2,m:1+t:3+f:!2")
+	// 市场或板块筛选参数
+	params.Set("fs", filter)
 
 	// 返回字段
-	params.Set("fields", "f12,f14,f2,f3,f62,f184,f66,f69,f72,f75,f78,f81,f84,f87,f204,f205,f124,f1,f13")
+	params.Set("fields", "f12,f14,f2,f3,f62,f184,f66,f69,f72,f75,f78,f81,f84,f87,f204,f205,f124,f1,f13,f26")
 
 	requestURL := baseURL + "?" + params.Encode()
 
@@ -291,8 +320,8 @@ func (e *EastMoneyCollector) fetchStockListPage(page, pageSize int) (*EastMoneyS
 	jsonStr := matches[1]
 
 	var result EastMoneyStockListResponse
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	if err := e.parseJSON(requestURL, []byte(jsonStr), &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
@@ -320,6 +349,19 @@ func parseFloat(v interface{}) float64 {
 	}
 }
 
+// parseListDate 解析东方财富返回的上市日期（YYYYMMDD格式的数字或字符串）
+func parseListDate(v interface{}) (time.Time, bool) {
+	dateInt := int(parseFloat(v))
+	if dateInt < 19000101 || dateInt > 99991231 {
+		return time.Time{}, false
+	}
+	listDate, err := time.ParseInLocation("20060102", strconv.Itoa(dateInt), utils.AppLocation())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return listDate, true
+}
+
 // parseTimeString 安全地将字符串转换为时间，支持多种时间格式
 // 返回解析后的时间和是否成功的标志
 func parseTimeString(timeStr string) (time.Time, bool) {
@@ -340,7 +382,7 @@ func parseTimeString(timeStr string) (time.Time, bool) {
 	}
 
 	for _, format := range formats {
-		if parsedTime, err := time.ParseInLocation(format, timeStr, time.Local); err == nil {
+		if parsedTime, err := time.ParseInLocation(format, timeStr, utils.AppLocation()); err == nil {
 			return parsedTime, true
 		}
 	}
@@ -369,11 +411,62 @@ func parseTimeToInt(timeStr string) (int, bool) {
 
 // GetStockList 获取股票列表
 func (e *EastMoneyCollector) GetStockList() ([]model.Stock, error) {
+	items, err := e.fetchAllStockListItems()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	allStocks := make([]model.Stock, 0, len(items))
+	for _, item := range items {
+		allStocks = append(allStocks, stockListItemToStock(item, now))
+	}
+
+	e.logger.Infof("Total fetched %d stocks from EastMoney", len(allStocks))
+	return allStocks, nil
+}
+
+// GetStockListWithFlows 获取股票列表及每只股票当日的资金流向
+// 与GetStockList使用同一个接口，一次抓取同时得到股票基础信息和主力、超大单、大单、中单、小单的净流入
+func (e *EastMoneyCollector) GetStockListWithFlows() ([]model.StockWithFundFlow, error) {
+	items, err := e.fetchAllStockListItems()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]model.StockWithFundFlow, 0, len(items))
+	for _, item := range items {
+		stock := stockListItemToStock(item, now)
+		result = append(result, model.StockWithFundFlow{
+			Stock:    stock,
+			FundFlow: stockListItemToFundFlow(item, stock.TsCode, now),
+		})
+	}
+
+	e.logger.Infof("Total fetched %d stocks with fund flows from EastMoney", len(result))
+	return result, nil
+}
+
+// stockListPageSize 股票列表每页条数，每次只获取50条，避免API限制
+const stockListPageSize = 50
+
+// SetStockListConcurrency 设置分页抓取股票列表的并发数，<=1时逐页串行抓取，应在开始采集前调用
+func (e *EastMoneyCollector) SetStockListConcurrency(n int) {
+	e.stockListConcurrency = n
+}
+
+// fetchAllStockListItems 分页抓取全部股票列表，配置了并发数时按首页返回的总数并发抓取其余页
+func (e *EastMoneyCollector) fetchAllStockListItems() ([]EastMoneyStockListItem, error) {
 	e.logger.Info("Fetching stock list from EastMoney...")
 
-	var allStocks []model.Stock
+	if e.stockListConcurrency > 1 {
+		return e.fetchStockListPagesConcurrently(e.fetchStockListPage, stockListPageSize, e.stockListConcurrency)
+	}
+
+	var allItems []EastMoneyStockListItem
 	page := 1
-	pageSize := 50 // 每次只获取50条，避免API限制
+	pageSize := stockListPageSize
 
 	for {
 		e.logger.Infof("Fetching page %d...", page)
@@ -396,59 +489,7 @@ func (e *EastMoneyCollector) GetStockList() ([]model.Stock, error) {
 			e.logger.Infof("API reports total stocks: %d", response.Data.Total)
 		}
 
-		// 转换数据格式
-		for _, item := range response.Data.Diff {
-			// 确定市场
-			var market string
-			switch item.F13 {
-			case 0:
-				market = "SZ" // 深市
-			case 1:
-				market = "SH" // 沪市
-			default:
-				market = "UNKNOWN"
-			}
-
-			// 构建TsCode
-			tsCode := fmt.Sprintf("%s.%s", item.F12, market)
-
-			stock := model.Stock{
-				TsCode:    tsCode,
-				Symbol:    item.F12,
-				Name:      item.F14,
-				Market:    market,
-				IsActive:  true,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-
-			// 根据股票代码判断板块和地区
-			if len(item.F12) >= 3 {
-				switch {
-				case strings.HasPrefix(item.F12, "000"), strings.HasPrefix(item.F12, "001"), strings.HasPrefix(item.F12, "002"):
-					stock.Industry = "主板" // 深市主板/中小板
-					stock.Area = "深圳"
-				case strings.HasPrefix(item.F12, "300"):
-					stock.Industry = "创业板"
-					stock.Area = "深圳"
-				case strings.HasPrefix(item.F12, "600"), strings.HasPrefix(item.F12, "601"), strings.HasPrefix(item.F12, "603"), strings.HasPrefix(item.F12, "605"):
-					stock.Industry = "主板" // 沪市主板
-					stock.Area = "上海"
-				case strings.HasPrefix(item.F12, "688"):
-					stock.Industry = "科创板"
-					stock.Area = "上海"
-				case strings.HasPrefix(item.F12, "8"), strings.HasPrefix(item.F12, "4"):
-					stock.Industry = "北交所"
-					stock.Area = "北京"
-				default:
-					stock.Industry = "其他"
-					stock.Area = "未知"
-				}
-			}
-
-			allStocks = append(allStocks, stock)
-		}
-
+		allItems = append(allItems, response.Data.Diff...)
 		e.logger.Infof("Fetched %d stocks from page %d", len(response.Data.Diff), page)
 
 		// 如果返回的数据少于页面大小，说明已经是最后一页
@@ -462,10 +503,167 @@ func (e *EastMoneyCollector) GetStockList() ([]model.Stock, error) {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	e.logger.Infof("Total fetched %d stocks from EastMoney", len(allStocks))
-	return allStocks, nil
+	return allItems, nil
+}
+
+// stockListTotalPages 按股票总数和每页条数计算总页数
+func stockListTotalPages(total, pageSize int) int {
+	if total <= 0 || pageSize <= 0 {
+		return 0
+	}
+	return (total + pageSize - 1) / pageSize
+}
+
+// fetchStockListPagesConcurrently 先抓取首页得到股票总数，再以不超过concurrency的并发抓取其余页
+// 请求仍经过采集器的限流器，结果按页码顺序拼接；任一页失败时返回错误
+func (e *EastMoneyCollector) fetchStockListPagesConcurrently(
+	fetch func(page, pageSize int) (*EastMoneyStockListResponse, error), pageSize, concurrency int,
+) ([]EastMoneyStockListItem, error) {
+	first, err := fetch(1, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page 1: %v", err)
+	}
+	if first.RC != 0 {
+		return nil, fmt.Errorf("API error: rc=%d", first.RC)
+	}
+
+	totalPages := stockListTotalPages(first.Data.Total, pageSize)
+	e.logger.Infof("API reports total stocks: %d, fetching %d pages with concurrency %d",
+		first.Data.Total, totalPages, concurrency)
+
+	pages := make([][]EastMoneyStockListItem, max(totalPages, 1))
+	pages[0] = first.Data.Diff
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for page := 2; page <= totalPages; page++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(page int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			response, err := fetch(page, pageSize)
+			if err == nil && response.RC != 0 {
+				err = fmt.Errorf("API error: rc=%d", response.RC)
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to fetch page %d: %v", page, err)
+				}
+				mu.Unlock()
+				return
+			}
+			pages[page-1] = response.Data.Diff
+		}(page)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var allItems []EastMoneyStockListItem
+	for _, items := range pages {
+		allItems = append(allItems, items...)
+	}
+	return allItems, nil
+}
+
+// stockListItemToStock 将股票列表中的单只股票转换为股票基础信息
+func stockListItemToStock(item EastMoneyStockListItem, now time.Time) model.Stock {
+	// 确定市场
+	var market string
+	switch item.F13 {
+	case 0:
+		market = "SZ" // 深市
+	case 1:
+		market = "SH" // 沪市
+	default:
+		market = "UNKNOWN"
+	}
+
+	// 构建TsCode
+	tsCode := fmt.Sprintf("%s.%s", item.F12, market)
+
+	stock := model.Stock{
+		TsCode:    tsCode,
+		Symbol:    item.F12,
+		Name:      item.F14,
+		Market:    market,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// 上市日期，未上市或接口未返回时为"-"或0
+	if listDate, ok := parseListDate(item.F26); ok {
+		stock.ListDate = &listDate
+	}
+
+	// 根据股票代码判断板块和地区
+	if len(item.F12) >= 3 {
+		switch {
+		case strings.HasPrefix(item.F12, "000"), strings.HasPrefix(item.F12, "001"), strings.HasPrefix(item.F12, "002"):
+			stock.Industry = "主板" // 深市主板/中小板
+			stock.Area = "深圳"
+		case strings.HasPrefix(item.F12, "300"):
+			stock.Industry = "创业板"
+			stock.Area = "深圳"
+		case strings.HasPrefix(item.F12, "600"), strings.HasPrefix(item.F12, "601"), strings.HasPrefix(item.F12, "603"), strings.HasPrefix(item.F12, "605"):
+			stock.Industry = "主板" // 沪市主板
+			stock.Area = "上海"
+		case strings.HasPrefix(item.F12, "688"):
+			stock.Industry = "科创板"
+			stock.Area = "上海"
+		case strings.HasPrefix(item.F12, "8"), strings.HasPrefix(item.F12, "4"):
+			stock.Industry = "北交所"
+			stock.Area = "北京"
+		default:
+			stock.Industry = "其他"
+			stock.Area = "未知"
+		}
+	}
+
+	return stock
 }
 
+// stockListItemToFundFlow 将股票列表中的资金流向字段转换为当日资金流向
+// 交易日期取行情更新时间戳所在日期，接口未返回时使用now；停牌股票各字段为"-"，解析为0
+func stockListItemToFundFlow(item EastMoneyStockListItem, tsCode string, now time.Time) model.StockFundFlow {
+	tradeTime := now
+	if ts := int64(parseFloat(item.F124)); ts > 0 {
+		tradeTime = time.Unix(ts, 0).In(now.Location())
+	}
+
+	return model.StockFundFlow{
+		TsCode:                 tsCode,
+		TradeDate:              tradeTime.Year()*10000 + int(tradeTime.Month())*100 + tradeTime.Day(),
+		Close:                  parseFloat(item.F2),
+		ChangePct:              parseFloat(item.F3),
+		MainNetInflow:          parseFloat(item.F62),
+		MainNetInflowPct:       parseFloat(item.F184),
+		SuperLargeNetInflow:    parseFloat(item.F66),
+		SuperLargeNetInflowPct: parseFloat(item.F69),
+		LargeNetInflow:         parseFloat(item.F72),
+		LargeNetInflowPct:      parseFloat(item.F75),
+		MediumNetInflow:        parseFloat(item.F78),
+		MediumNetInflowPct:     parseFloat(item.F81),
+		SmallNetInflow:         parseFloat(item.F84),
+		SmallNetInflowPct:      parseFloat(item.F87),
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+}
+
+// eastMoneyStockDetailURL 东方财富个股行情接口，返回股票名称、停牌状态、股本和上市日期
+var eastMoneyStockDetailURL = "https://push2.eastmoney.com/api/qt/stock/get"
+
 // GetStockDetail 获取股票详情
 func (e *EastMoneyCollector) GetStockDetail(tsCode string) (*model.Stock, error) {
 	e.logger.Infof("Fetching stock detail for %s from EastMoney", tsCode)
@@ -480,7 +678,6 @@ func (e *EastMoneyCollector) GetStockDetail(tsCode string) (*model.Stock, error)
 	market := parts[1]
 
 	// 构建请求URL - 获取股票基本信息
-	baseURL := "https://push2.eastmoney.com/api/qt/stock/get"
 	params := url.Values{}
 	params.Set("ut", "b2884a393a59ad64002292a3e90d46a5")
 	params.Set("invt", "2")
@@ -500,10 +697,10 @@ func (e *EastMoneyCollector) GetStockDetail(tsCode string) (*model.Stock, error)
 	}
 	params.Set("secid", secid)
 
-	// 返回字段 - 使用你提供的字段列表
-	params.Set("fields", "f57,f58,f107,f43,f169,f170,f171,f47,f48,f60,f46,f44,f45,f168,f50,f162,f177,f803")
+	// 返回字段 - 使用你提供的字段列表，f189为上市日期
+	params.Set("fields", "f57,f58,f107,f43,f169,f170,f171,f47,f48,f60,f46,f44,f45,f168,f50,f162,f84,f177,f189,f803")
 
-	requestURL := baseURL + "?" + params.Encode()
+	requestURL := eastMoneyStockDetailURL + "?" + params.Encode()
 
 	resp, err := e.makeRequest(requestURL, refer)
 	if err != nil {
@@ -541,29 +738,31 @@ func (e *EastMoneyCollector) GetStockDetail(tsCode string) (*model.Stock, error)
 		RC   int `json:"rc"`
 		RT   int `json:"rt"`
 		Data struct {
-			F43  float64 `json:"f43"`  // 最新价
-			F44  float64 `json:"f44"`  // 最高价
-			F45  float64 `json:"f45"`  // 最低价
-			F46  float64 `json:"f46"`  // 今开
-			F47  int64   `json:"f47"`  // 成交量
-			F48  float64 `json:"f48"`  // 成交额
-			F50  float64 `json:"f50"`  // 量比
-			F57  string  `json:"f57"`  // 股票代码
-			F58  string  `json:"f58"`  // 股票名称
-			F60  float64 `json:"f60"`  // 昨收
-			F107 int     `json:"f107"` // 停牌状态
-			F162 float64 `json:"f162"` // 涨跌幅
-			F168 float64 `json:"f168"` // 换手率
-			F169 float64 `json:"f169"` // 涨跌额
-			F170 float64 `json:"f170"` // 市盈率动
-			F171 float64 `json:"f171"` // 市净率
-			F177 int     `json:"f177"` // 流通股本
-			F803 string  `json:"f803"` // 板块
+			F43  float64     `json:"f43"`  // 最新价
+			F44  float64     `json:"f44"`  // 最高价
+			F45  float64     `json:"f45"`  // 最低价
+			F46  float64     `json:"f46"`  // 今开
+			F47  int64       `json:"f47"`  // 成交量
+			F48  float64     `json:"f48"`  // 成交额
+			F50  float64     `json:"f50"`  // 量比
+			F57  string      `json:"f57"`  // 股票代码
+			F58  string      `json:"f58"`  // 股票名称
+			F60  float64     `json:"f60"`  // 昨收
+			F84  float64     `json:"f84"`  // 总股本，单位：股
+			F107 int         `json:"f107"` // 停牌状态
+			F162 float64     `json:"f162"` // 涨跌幅
+			F168 float64     `json:"f168"` // 换手率
+			F169 float64     `json:"f169"` // 涨跌额
+			F170 float64     `json:"f170"` // 市盈率动
+			F171 float64     `json:"f171"` // 市净率
+			F177 float64     `json:"f177"` // 流通股本，单位：股
+			F189 interface{} `json:"f189"` // 上市日期，YYYYMMDD，未上市或已摘牌时为"-"
+			F803 string      `json:"f803"` // 板块
 		} `json:"data"`
 	}
 
-	if err := json.Unmarshal([]byte(jsonData), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	if err := e.parseJSON(requestURL, []byte(jsonData), &response); err != nil {
+		return nil, err
 	}
 
 	if response.RC != 0 {
@@ -571,11 +770,22 @@ func (e *EastMoneyCollector) GetStockDetail(tsCode string) (*model.Stock, error)
 	}
 
 	stock := &model.Stock{
-		TsCode:   tsCode,
-		Symbol:   symbol,
-		Name:     response.Data.F58,
-		Market:   market,
-		IsActive: true,
+		TsCode:      tsCode,
+		Symbol:      symbol,
+		Name:        response.Data.F58,
+		Market:      market,
+		FloatShares: int64(response.Data.F177),
+		TotalShares: int64(response.Data.F84),
+	}
+	if listDate, ok := parseListDate(response.Data.F189); ok {
+		stock.ListDate = &listDate
+	}
+	if response.Data.F57 == "" {
+		// 行情接口不再返回该代码，说明已从交易所摘牌
+		stock.SetStatus(model.StockStatusDelisted)
+	} else {
+		// f107为0表示正常交易，非0表示停牌
+		stock.SetStatus(model.ClassifyStockStatus(response.Data.F58, response.Data.F107 != 0))
 	}
 
 	// 根据股票代码判断板块和地区
@@ -607,20 +817,117 @@ func (e *EastMoneyCollector) GetStockData(tsCode string, startDate, endDate time
 	return e.GetDailyKLine(tsCode, startDate, endDate)
 }
 
-// GetRealtimeData 获取实时数据
+// eastMoneyQuoteURL 东方财富批量行情接口
+var eastMoneyQuoteURL = "https://push2.eastmoney.com/api/qt/ulist.np/get"
+
+// eastMoneyKLineURL 东方财富历史K线接口，股票和指数共用
+var eastMoneyKLineURL = "https://push2his.eastmoney.com/api/qt/stock/kline/get"
+
+// eastMoneyKLineFallbackURL 备用K线接口，部分退市或特殊证券在历史K线接口返回空时仍能从该接口取到历史数据
+var eastMoneyKLineFallbackURL = "https://push2.eastmoney.com/api/qt/stock/kline/get"
+
+// realtimeBatchSize 批量行情接口单次请求的最大股票数量
+const realtimeBatchSize = 100
+
+// EastMoneyQuoteResponse 东方财富批量行情响应结构
+type EastMoneyQuoteResponse struct {
+	RC   int `json:"rc"`
+	Data *struct {
+		Total int `json:"total"`
+		Diff  []struct {
+			F2  interface{} `json:"f2"`  // 最新价
+			F5  interface{} `json:"f5"`  // 成交量，单位：手
+			F6  interface{} `json:"f6"`  // 成交额，单位：元
+			F12 string      `json:"f12"` // 股票代码
+			F13 int         `json:"f13"` // 市场标识 0=深市 1=沪市
+			F15 interface{} `json:"f15"` // 最高价
+			F16 interface{} `json:"f16"` // 最低价
+			F17 interface{} `json:"f17"` // 开盘价
+		} `json:"diff"`
+	} `json:"data"`
+}
+
+// GetRealtimeData 获取实时数据，按请求的股票代码分批调用批量行情接口
 func (e *EastMoneyCollector) GetRealtimeData(tsCodes []string) ([]model.DailyData, error) {
+	return e.GetRealtimeDataWithContext(context.Background(), tsCodes)
+}
+
+// GetRealtimeDataWithContext 获取实时数据，ctx取消或超时时中止请求
+func (e *EastMoneyCollector) GetRealtimeDataWithContext(ctx context.Context, tsCodes []string) ([]model.DailyData, error) {
 	e.logger.Infof("Fetching realtime data for %d stocks from EastMoney", len(tsCodes))
 
-	// 东方财富的实时数据可以通过股票列表API获取
-	// 这里简化处理，获取前50只股票的实时数据
-	response, err := e.fetchStockListPage(1, 50)
+	// 构建secids，跳过无法识别的股票代码
+	secids := make([]string, 0, len(tsCodes))
+	for _, tsCode := range tsCodes {
+		symbol, market, err := e.parseStockCode(tsCode)
+		if err != nil {
+			e.logger.Warnf("Skip invalid tsCode %s: %v", tsCode, err)
+			continue
+		}
+		secid := e.buildSecID(symbol, market)
+		if secid == "" {
+			e.logger.Warnf("Skip unsupported market for tsCode %s", tsCode)
+			continue
+		}
+		secids = append(secids, secid)
+	}
+
+	var realtimeData []model.DailyData
+	for i := 0; i < len(secids); i += realtimeBatchSize {
+		end := i + realtimeBatchSize
+		if end > len(secids) {
+			end = len(secids)
+		}
+
+		batch, err := e.fetchRealtimeQuotes(ctx, secids[i:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch realtime quotes: %v", err)
+		}
+		realtimeData = append(realtimeData, batch...)
+	}
+
+	e.logger.Infof("Fetched realtime data for %d stocks", len(realtimeData))
+	return realtimeData, nil
+}
+
+// fetchRealtimeQuotes 调用批量行情接口获取指定secid的实时行情
+func (e *EastMoneyCollector) fetchRealtimeQuotes(ctx context.Context, secids []string) ([]model.DailyData, error) {
+	params := url.Values{}
+	params.Set("fltt", "2")
+	params.Set("invt", "2")
+	params.Set("ut", "bd1d9ddb04089700cf9c27f6f7426281")
+	params.Set("fields", "f2,f5,f6,f12,f13,f15,f16,f17")
+	params.Set("secids", strings.Join(secids, ","))
+
+	requestURL := eastMoneyQuoteURL + "?" + params.Encode()
+	resp, err := e.makeRequestWithContext(ctx, requestURL, "https://quote.eastmoney.com/")
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response EastMoneyQuoteResponse
+	if err := e.parseJSON(requestURL, body, &response); err != nil {
+		return nil, err
+	}
+
+	if response.RC != 0 {
+		return nil, fmt.Errorf("API error: rc=%d", response.RC)
+	}
+
+	if response.Data == nil {
+		return nil, nil
+	}
 
-	var realtimeData []model.DailyData
 	now := time.Now()
+	nowDateInt := now.Year()*10000 + int(now.Month())*100 + now.Day()
 
+	realtimeData := make([]model.DailyData, 0, len(response.Data.Diff))
 	for _, item := range response.Data.Diff {
 		var market string
 		switch item.F13 {
@@ -632,35 +939,20 @@ func (e *EastMoneyCollector) GetRealtimeData(tsCodes []string) ([]model.DailyDat
 			continue
 		}
 
-		tsCode := fmt.Sprintf("%s.%s", item.F12, market)
-
-		// 检查是否在请求的股票列表中
-		found := false
-		for _, requestedCode := range tsCodes {
-			if requestedCode == tsCode {
-				found = true
-				break
-			}
-		}
-
-		if !found && len(tsCodes) > 0 {
-			continue
-		}
-
-		// 转换当前日期为YYYYMMDD格式的int
-		nowDateInt := now.Year()*10000 + int(now.Month())*100 + now.Day()
-
-		data := model.DailyData{
-			TsCode:    tsCode,
+		realtimeData = append(realtimeData, model.DailyData{
+			TsCode:    fmt.Sprintf("%s.%s", item.F12, market),
 			TradeDate: nowDateInt,
-			Close:     parseFloat(item.F2), // 最新价作为收盘价
+			Open:      model.RoundPrice(parseFloat(item.F17)),
+			High:      model.RoundPrice(parseFloat(item.F15)),
+			Low:       model.RoundPrice(parseFloat(item.F16)),
+			Close:     model.RoundPrice(parseFloat(item.F2)), // 最新价作为收盘价
+			Volume:    int64(parseFloat(item.F5)) * 100,
+			Amount:    model.RoundAmount(parseFloat(item.F6)),
 			CreatedAt: now,
-			// 其他字段暂时无法从该API获取
-		}
-		realtimeData = append(realtimeData, data)
+			UpdatedAt: now,
+		})
 	}
 
-	e.logger.Infof("Fetched realtime data for %d stocks", len(realtimeData))
 	return realtimeData, nil
 }
 
@@ -702,6 +994,11 @@ func isValidTsCode(tsCode string) bool {
 
 // GetPerformanceReports 获取业绩报表数据
 func (e *EastMoneyCollector) GetPerformanceReports(tsCode string) ([]model.PerformanceReport, error) {
+	return e.GetPerformanceReportsWithContext(context.Background(), tsCode)
+}
+
+// GetPerformanceReportsWithContext 获取业绩报表数据，ctx取消或超时时中止请求
+func (e *EastMoneyCollector) GetPerformanceReportsWithContext(ctx context.Context, tsCode string) ([]model.PerformanceReport, error) {
 	e.logger.Infof("Fetching performance reports for %s from EastMoney", tsCode)
 
 	// 验证股票代码格式
@@ -727,7 +1024,7 @@ func (e *EastMoneyCollector) GetPerformanceReports(tsCode string) ([]model.Perfo
 	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
 
 	// 发送请求
-	resp, err := e.makePerformanceRequest(requestURL, stockCode)
+	resp, err := e.makePerformanceRequest(ctx, requestURL, stockCode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch performance reports: %w", err)
 	}
@@ -760,8 +1057,8 @@ func (e *EastMoneyCollector) GetPerformanceReports(tsCode string) ([]model.Perfo
 		Message string `json:"message"`
 	}
 
-	if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	if err := e.parseJSON(requestURL, []byte(jsonStr), &response); err != nil {
+		return nil, err
 	}
 
 	if !response.Success {
@@ -805,14 +1102,18 @@ func (e *EastMoneyCollector) GetLatestPerformanceReport(tsCode string) (*model.P
 	return &latest, nil
 }
 
-// makePerformanceRequest 发送业绩报表请求
-func (e *EastMoneyCollector) makePerformanceRequest(url, stockCode string) (*http.Response, error) {
+// makePerformanceRequest 发送业绩报表请求（带上下文）
+func (e *EastMoneyCollector) makePerformanceRequest(ctx context.Context, url, stockCode string) (*http.Response, error) {
+	if resp := e.cachedResponse(url); resp != nil {
+		return resp, nil
+	}
+
 	// 检查是否需要更新User-Agent和Cookie
 	if time.Since(e.lastUpdateTime) > 1*time.Minute {
 		e.updateUserAgentAndCookie()
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -832,6 +1133,7 @@ func (e *EastMoneyCollector) makePerformanceRequest(url, stockCode string) (*htt
 	req.Header.Set("sec-ch-ua", e.userAgentGen.GenerateSecChUa(e.currentUA))
 	req.Header.Set("sec-ch-ua-mobile", "?0")
 	req.Header.Set("sec-ch-ua-platform", e.getPlatformFromUA(e.currentUA))
+	e.applyHeaderOverride(req)
 
 	e.logger.Debugf("Making performance request with random UA: %s", url)
 
@@ -846,7 +1148,7 @@ func (e *EastMoneyCollector) makePerformanceRequest(url, stockCode string) (*htt
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	return resp, nil
+	return e.cacheResponse(url, resp)
 }
 
 // convertToPerformanceReport 转换业绩报表数据
@@ -911,6 +1213,11 @@ func limitGrowthRate(value float64) float64 {
 
 // GetShareholderCounts 获取股东户数数据
 func (e *EastMoneyCollector) GetShareholderCounts(tsCode string) ([]model.ShareholderCount, error) {
+	return e.GetShareholderCountsWithContext(context.Background(), tsCode)
+}
+
+// GetShareholderCountsWithContext 获取股东户数数据，ctx取消或超时时中止请求
+func (e *EastMoneyCollector) GetShareholderCountsWithContext(ctx context.Context, tsCode string) ([]model.ShareholderCount, error) {
 	e.logger.Infof("Fetching shareholder counts for %s from EastMoney", tsCode)
 
 	// 验证股票代码格式
@@ -940,7 +1247,7 @@ func (e *EastMoneyCollector) GetShareholderCounts(tsCode string) ([]model.Shareh
 	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
 
 	// 发送请求
-	resp, err := e.makeRequest(requestURL, fmt.Sprintf("https://data.eastmoney.com/gdhs/detail/%s.html", stockCode))
+	resp, err := e.makeRequestWithContext(ctx, requestURL, fmt.Sprintf("https://data.eastmoney.com/gdhs/detail/%s.html", stockCode))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch shareholder counts: %w", err)
 	}
@@ -973,8 +1280,8 @@ func (e *EastMoneyCollector) GetShareholderCounts(tsCode string) ([]model.Shareh
 		Message string `json:"message"`
 	}
 
-	if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	if err := e.parseJSON(requestURL, []byte(jsonStr), &response); err != nil {
+		return nil, err
 	}
 
 	if !response.Success {
@@ -1020,7 +1327,7 @@ func (e *EastMoneyCollector) GetLatestShareholderCount(tsCode string) (*model.Sh
 
 // GetDailyKLine 获取日K线数据
 func (e *EastMoneyCollector) GetDailyKLine(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
-	klines, err := e.fetchKLineRawData(tsCode, startDate, KLineTypeDaily)
+	klines, err := e.fetchKLineRawData(tsCode, startDate, time.Time{}, KLineTypeDaily)
 	if err != nil {
 		return nil, err
 	}
@@ -1033,6 +1340,9 @@ func (e *EastMoneyCollector) GetDailyKLine(tsCode string, startDate, endDate tim
 				result = append(result, *data)
 			}
 		} else {
+			if strictErr := utils.StrictViolation(fmt.Sprintf("failed to parse daily K-line of %s: %v", tsCode, err)); strictErr != nil {
+				return nil, strictErr
+			}
 			e.logger.Warnf("Failed to parse daily K-line data: %v", err)
 		}
 	}
@@ -1041,9 +1351,35 @@ func (e *EastMoneyCollector) GetDailyKLine(tsCode string, startDate, endDate tim
 	return result, nil
 }
 
+// GetDailyKLineChunked 按自然年分段获取日K线数据，每段请求只返回该年的K线，用于采集上市时间很长的股票的全量历史
+func (e *EastMoneyCollector) GetDailyKLineChunked(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
+	return FetchKLineChunks(SplitYearChunks(startDate, endDate), func(chunk KLineChunk) ([]model.DailyData, error) {
+		klines, err := e.fetchKLineRawData(tsCode, chunk.Start, chunk.End, KLineTypeDaily)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]model.DailyData, 0, len(klines))
+		for _, kline := range klines {
+			data, err := e.parser.ParseToDaily(tsCode, kline)
+			if err != nil {
+				if strictErr := utils.StrictViolation(fmt.Sprintf("failed to parse daily K-line of %s: %v", tsCode, err)); strictErr != nil {
+					return nil, strictErr
+				}
+				e.logger.Warnf("Failed to parse daily K-line data: %v", err)
+				continue
+			}
+			if e.isInDateRange(data.TradeDate, chunk.Start, chunk.End) {
+				result = append(result, *data)
+			}
+		}
+		return result, nil
+	})
+}
+
 // GetWeeklyKLine 获取周K线数据
 func (e *EastMoneyCollector) GetWeeklyKLine(tsCode string, startDate, endDate time.Time) ([]model.WeeklyData, error) {
-	klines, err := e.fetchKLineRawData(tsCode, startDate, KLineTypeWeekly)
+	klines, err := e.fetchKLineRawData(tsCode, startDate, time.Time{}, KLineTypeWeekly)
 	if err != nil {
 		return nil, err
 	}
@@ -1056,6 +1392,9 @@ func (e *EastMoneyCollector) GetWeeklyKLine(tsCode string, startDate, endDate ti
 				result = append(result, *data)
 			}
 		} else {
+			if strictErr := utils.StrictViolation(fmt.Sprintf("failed to parse weekly K-line of %s: %v", tsCode, err)); strictErr != nil {
+				return nil, strictErr
+			}
 			e.logger.Warnf("Failed to parse weekly K-line data: %v", err)
 		}
 	}
@@ -1066,7 +1405,7 @@ func (e *EastMoneyCollector) GetWeeklyKLine(tsCode string, startDate, endDate ti
 
 // GetMonthlyKLine 获取月K线数据
 func (e *EastMoneyCollector) GetMonthlyKLine(tsCode string, startDate, endDate time.Time) ([]model.MonthlyData, error) {
-	klines, err := e.fetchKLineRawData(tsCode, startDate, KLineTypeMonthly)
+	klines, err := e.fetchKLineRawData(tsCode, startDate, time.Time{}, KLineTypeMonthly)
 	if err != nil {
 		return nil, err
 	}
@@ -1079,6 +1418,9 @@ func (e *EastMoneyCollector) GetMonthlyKLine(tsCode string, startDate, endDate t
 				result = append(result, *data)
 			}
 		} else {
+			if strictErr := utils.StrictViolation(fmt.Sprintf("failed to parse monthly K-line of %s: %v", tsCode, err)); strictErr != nil {
+				return nil, strictErr
+			}
 			e.logger.Warnf("Failed to parse monthly K-line data: %v", err)
 		}
 	}
@@ -1089,7 +1431,7 @@ func (e *EastMoneyCollector) GetMonthlyKLine(tsCode string, startDate, endDate t
 
 // GetYearlyKLine 获取年K线数据
 func (e *EastMoneyCollector) GetYearlyKLine(tsCode string, startDate, endDate time.Time) ([]model.YearlyData, error) {
-	klines, err := e.fetchKLineRawData(tsCode, startDate, KLineTypeYearly)
+	klines, err := e.fetchKLineRawData(tsCode, startDate, time.Time{}, KLineTypeYearly)
 	if err != nil {
 		return nil, err
 	}
@@ -1102,6 +1444,9 @@ func (e *EastMoneyCollector) GetYearlyKLine(tsCode string, startDate, endDate ti
 				result = append(result, *data)
 			}
 		} else {
+			if strictErr := utils.StrictViolation(fmt.Sprintf("failed to parse yearly K-line of %s: %v", tsCode, err)); strictErr != nil {
+				return nil, strictErr
+			}
 			e.logger.Warnf("Failed to parse yearly K-line data: %v", err)
 		}
 	}
@@ -1110,19 +1455,28 @@ func (e *EastMoneyCollector) GetYearlyKLine(tsCode string, startDate, endDate ti
 	return result, nil
 }
 
-// fetchKLineRawData 获取原始K线数据
-func (e *EastMoneyCollector) fetchKLineRawData(tsCode string, startDate time.Time, klineType KLineType) (
+// fetchKLineRawData 获取原始K线数据，endDate为零值时不限制结束日期
+// 标准接口没有返回K线但证券可能仍有历史数据时，依次尝试备用接口和另一市场的证券ID，都没有数据时返回空
+func (e *EastMoneyCollector) fetchKLineRawData(tsCode string, startDate, endDate time.Time, klineType KLineType) (
 	[]string, error) {
 	e.logger.Debugf("Fetching K-line data for %s, type: %s", tsCode, klineType)
 
-	// 构建请求URL
-	requestURL, err := e.buildKLineURL(tsCode, startDate.Format("20060102"), klineType)
+	symbol, market, err := e.parseStockCode(tsCode)
 	if err != nil {
 		return nil, fmt.Errorf("build URL failed: %w", err)
 	}
+	secid := e.buildSecID(symbol, market)
+	if secid == "" {
+		return nil, fmt.Errorf("build URL failed: unsupported market: %s", market)
+	}
+	begin := startDate.Format("20060102")
+	var end string
+	if !endDate.IsZero() {
+		end = endDate.Format("20060102")
+	}
 
 	// 发送请求并解析响应
-	response, err := e.sendKLineRequest(requestURL, "https://quote.eastmoney.com")
+	response, err := e.sendKLineRequest(buildKLineURLWithBase(eastMoneyKLineURL, secid, begin, end, klineType), "https://quote.eastmoney.com")
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -1130,21 +1484,64 @@ func (e *EastMoneyCollector) fetchKLineRawData(tsCode string, startDate time.Tim
 	if response.RC != 0 {
 		return nil, fmt.Errorf("API error: rc=%d", response.RC)
 	}
+	if !response.mayHaveHistory() {
+		return response.Data.Klines, nil
+	}
+
+	for _, fallback := range e.klineFallbacks(secid) {
+		fallbackResp, err := e.sendKLineRequest(buildKLineURLWithBase(fallback.baseURL, fallback.secid, begin, end, klineType),
+			"https://quote.eastmoney.com")
+		if err != nil {
+			e.logger.Warnf("Fallback K-line request for %s (secid %s) failed: %v", tsCode, fallback.secid, err)
+			continue
+		}
+		if fallbackResp.RC == 0 && len(fallbackResp.Data.Klines) > 0 {
+			e.logger.Infof("Recovered %d K-lines for %s from fallback %s (secid %s)",
+				len(fallbackResp.Data.Klines), tsCode, fallback.baseURL, fallback.secid)
+			return fallbackResp.Data.Klines, nil
+		}
+	}
 
 	return response.Data.Klines, nil
 }
 
-// buildKLineURL 构建K线请求URL
-func (e *EastMoneyCollector) buildKLineURL(tsCode, startDate, klineType string) (string, error) {
-	symbol, market, err := e.parseStockCode(tsCode)
-	if err != nil {
-		return "", err
+// klineFallback 标准K线接口返回空时的一次备用请求
+type klineFallback struct {
+	baseURL string
+	secid   string
+}
+
+// klineFallbacks 标准K线接口返回空时依次尝试的备用请求：备用接口加原证券ID，标准接口加另一市场的证券ID
+func (e *EastMoneyCollector) klineFallbacks(secid string) []klineFallback {
+	fallbackURL := e.klineFallbackURL
+	if fallbackURL == "" {
+		fallbackURL = eastMoneyKLineFallbackURL
 	}
 
-	// 构建secid
-	secid := e.buildSecID(symbol, market)
-	if secid == "" {
-		return "", fmt.Errorf("unsupported market: %s", market)
+	fallbacks := []klineFallback{{baseURL: fallbackURL, secid: secid}}
+	if market, code, ok := strings.Cut(secid, "."); ok {
+		alternate := map[string]string{"0": "1", "1": "0"}[market]
+		if alternate != "" {
+			fallbacks = append(fallbacks, klineFallback{baseURL: eastMoneyKLineURL, secid: alternate + "." + code})
+		}
+	}
+	return fallbacks
+}
+
+// SetKLineFallbackURL 设置标准K线接口返回空时使用的备用K线接口，为空时使用默认的备用接口
+func (e *EastMoneyCollector) SetKLineFallbackURL(fallbackURL string) {
+	e.klineFallbackURL = fallbackURL
+}
+
+// buildKLineURLBySecID 根据证券ID构建K线数据请求URL，股票和指数共用
+func (e *EastMoneyCollector) buildKLineURLBySecID(secid, startDate, klineType string) string {
+	return buildKLineURLWithBase(eastMoneyKLineURL, secid, startDate, "", klineType)
+}
+
+// buildKLineURLWithBase 根据K线接口地址和证券ID构建K线数据请求URL，endDate为空时不限制结束日期
+func buildKLineURLWithBase(baseURL, secid, startDate, endDate, klineType string) string {
+	if endDate == "" {
+		endDate = "20500101"
 	}
 
 	// 构建请求参数
@@ -1152,7 +1549,7 @@ func (e *EastMoneyCollector) buildKLineURL(tsCode, startDate, klineType string)
 		"fields1": {"f1,f2,f3,f4,f5,f6,f7,f8,f9,f10,f11,f12,f13"},
 		"fields2": {"f51,f52,f53,f54,f55,f56,f57,f58,f59,f60,f61"},
 		"beg":     {startDate},
-		"end":     {"20500101"},
+		"end":     {endDate},
 		"ut":      {"fa5fd1943c7b386f172d6893dbfba10b"},
 		"rtntype": {"6"},
 		"secid":   {secid},
@@ -1161,7 +1558,7 @@ func (e *EastMoneyCollector) buildKLineURL(tsCode, startDate, klineType string)
 		"cb":      {fmt.Sprintf("jsonp%d", time.Now().UnixMilli())},
 	}
 
-	return "https://push2his.eastmoney.com/api/qt/stock/kline/get?" + params.Encode(), nil
+	return baseURL + "?" + params.Encode()
 }
 
 // buildSecID 构建证券ID
@@ -1196,8 +1593,8 @@ func (e *EastMoneyCollector) sendKLineRequest(requestURL, refer string) (*KLineR
 	}
 
 	var response KLineResponse
-	if err := json.Unmarshal([]byte(jsonData), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	if err := e.parseJSON(requestURL, []byte(jsonData), &response); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
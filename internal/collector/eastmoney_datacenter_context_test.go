@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTransport 请求一直挂起直到请求的上下文结束，模拟上游无响应
+type blockingTransport struct {
+	started chan *http.Request
+}
+
+func (b *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.started <- req
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func newBlockingEastMoneyCollector() (*EastMoneyCollector, *blockingTransport) {
+	transport := &blockingTransport{started: make(chan *http.Request, 1)}
+	c := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	// 客户端超时远大于测试时长，请求只能被上下文中止
+	c.client = &http.Client{Transport: transport, Timeout: time.Minute}
+	return c, transport
+}
+
+func TestEastMoneyCollector_DatacenterRequestsCancel(t *testing.T) {
+	calls := map[string]func(c *EastMoneyCollector, ctx context.Context) error{
+		"performance": func(c *EastMoneyCollector, ctx context.Context) error {
+			_, err := c.GetPerformanceReportsWithContext(ctx, "600519.SH")
+			return err
+		},
+		"shareholder": func(c *EastMoneyCollector, ctx context.Context) error {
+			_, err := c.GetShareholderCountsWithContext(ctx, "600519.SH")
+			return err
+		},
+		"northbound": func(c *EastMoneyCollector, ctx context.Context) error {
+			_, err := c.GetNorthboundHoldingsWithContext(ctx, "600519.SH")
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			c, transport := newBlockingEastMoneyCollector()
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error, 1)
+			go func() { done <- call(c, ctx) }()
+
+			// 请求发出后取消，进行中的请求立即中止
+			select {
+			case req := <-transport.started:
+				assert.Contains(t, req.URL.Host, "datacenter")
+			case <-time.After(5 * time.Second):
+				t.Fatal("request was not sent")
+			}
+			cancel()
+
+			select {
+			case err := <-done:
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, context.Canceled), err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("request was not cancelled")
+			}
+		})
+	}
+}
+
+func TestEastMoneyCollector_DatacenterRequestDeadline(t *testing.T) {
+	c, _ := newBlockingEastMoneyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetPerformanceReportsWithContext(ctx, "600519.SH")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
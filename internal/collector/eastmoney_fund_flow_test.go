@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stockListWithFlowsJSON 东方财富股票列表接口返回的样例，第二只为停牌股票，资金流向字段为"-"
+const stockListWithFlowsJSON = `{
+	"rc": 0,
+	"data": {
+		"total": 2,
+		"diff": [
+			{"f2": 10.52, "f3": 2.34, "f12": "000001", "f13": 0, "f14": "平安银行", "f26": 19910403,
+			 "f62": 123456789.0, "f66": 98765432.0, "f69": 5.67, "f72": 24691357.0, "f75": 1.42,
+			 "f78": -45678901.0, "f81": -2.63, "f84": -77777888.0, "f87": -4.47,
+			 "f124": 1718002800, "f184": 7.09, "f204": 1000.0, "f205": 2000.0},
+			{"f2": "-", "f3": "-", "f12": "600001", "f13": 1, "f14": "停牌股", "f26": "-",
+			 "f62": "-", "f66": "-", "f69": "-", "f72": "-", "f75": "-",
+			 "f78": "-", "f81": "-", "f84": "-", "f87": "-", "f124": null, "f184": "-"}
+		]
+	}
+}`
+
+func TestStockListItemToFundFlow(t *testing.T) {
+	var response EastMoneyStockListResponse
+	require.NoError(t, json.Unmarshal([]byte(stockListWithFlowsJSON), &response))
+	require.Len(t, response.Data.Diff, 2)
+
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2024, 6, 11, 20, 0, 0, 0, loc)
+
+	item := response.Data.Diff[0]
+	stock := stockListItemToStock(item, now)
+	assert.Equal(t, "000001.SZ", stock.TsCode)
+	assert.Equal(t, "平安银行", stock.Name)
+	require.NotNil(t, stock.ListDate)
+
+	flow := stockListItemToFundFlow(item, stock.TsCode, now)
+	assert.Equal(t, "000001.SZ", flow.TsCode)
+	// 1718002800 为北京时间 2024-06-10 15:00:00
+	assert.Equal(t, 20240610, flow.TradeDate)
+	assert.Equal(t, 10.52, flow.Close)
+	assert.Equal(t, 2.34, flow.ChangePct)
+	assert.Equal(t, 123456789.0, flow.MainNetInflow)
+	assert.Equal(t, 7.09, flow.MainNetInflowPct)
+	assert.Equal(t, 98765432.0, flow.SuperLargeNetInflow)
+	assert.Equal(t, 5.67, flow.SuperLargeNetInflowPct)
+	assert.Equal(t, 24691357.0, flow.LargeNetInflow)
+	assert.Equal(t, 1.42, flow.LargeNetInflowPct)
+	assert.Equal(t, -45678901.0, flow.MediumNetInflow)
+	assert.Equal(t, -2.63, flow.MediumNetInflowPct)
+	assert.Equal(t, -77777888.0, flow.SmallNetInflow)
+	assert.Equal(t, -4.47, flow.SmallNetInflowPct)
+	// 主力净流入为超大单与大单之和
+	assert.InDelta(t, flow.SuperLargeNetInflow+flow.LargeNetInflow, flow.MainNetInflow, 0.01)
+
+	suspended := response.Data.Diff[1]
+	stock = stockListItemToStock(suspended, now)
+	assert.Equal(t, "600001.SH", stock.TsCode)
+	assert.Nil(t, stock.ListDate)
+
+	// 停牌股票字段为"-"时解析为0，没有更新时间戳时交易日期取now
+	flow = stockListItemToFundFlow(suspended, stock.TsCode, now)
+	assert.Equal(t, 20240611, flow.TradeDate)
+	assert.Zero(t, flow.Close)
+	assert.Zero(t, flow.MainNetInflow)
+	assert.Zero(t, flow.MainNetInflowPct)
+	assert.Zero(t, flow.SmallNetInflow)
+}
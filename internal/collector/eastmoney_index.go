@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+)
+
+// IndexCollector 指数数据采集接口
+type IndexCollector interface {
+	GetIndexDailyKLine(tsCode string, startDate, endDate time.Time) ([]model.IndexDaily, error)
+}
+
+// IndexConstituentCollector 指数成分股采集接口
+type IndexConstituentCollector interface {
+	// GetIndexConstituents 获取指数的成分股代码
+	GetIndexConstituents(indexCode string) ([]string, error)
+}
+
+// indexConstituentBoards 常用指数在东方财富行情中心对应的成分股板块代码
+var indexConstituentBoards = map[string]string{
+	"000016.SH": "BK0611", // 上证50
+	"000300.SH": "BK0500", // 沪深300
+	"000905.SH": "BK0701", // 中证500
+}
+
+// ConstituentIndices 获取支持加载成分股的指数代码，按代码排序
+func ConstituentIndices() []string {
+	codes := make([]string, 0, len(indexConstituentBoards))
+	for code := range indexConstituentBoards {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// buildIndexSecID 构建指数的证券ID
+// 指数代码与股票代码存在重叠（如000001.SH为上证指数，000001.SZ为平安银行），必须按指数代码的交易所后缀确定市场，
+// 不能像股票那样根据代码前缀推断；中证指数公司独立发布的指数（如931xxx.CSI）使用市场号2
+func buildIndexSecID(tsCode string) (string, error) {
+	parts := strings.Split(tsCode, ".")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid index code format: %s", tsCode)
+	}
+
+	symbol, market := parts[0], strings.ToUpper(parts[1])
+	switch market {
+	case "SH":
+		return "1." + symbol, nil
+	case "SZ":
+		return "0." + symbol, nil
+	case "CSI":
+		return "2." + symbol, nil
+	default:
+		return "", fmt.Errorf("unsupported index market: %s", market)
+	}
+}
+
+// GetIndexDailyKLine 获取指数日K线数据
+func (e *EastMoneyCollector) GetIndexDailyKLine(tsCode string, startDate, endDate time.Time) ([]model.IndexDaily, error) {
+	secid, err := buildIndexSecID(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("build secid failed: %w", err)
+	}
+
+	requestURL := e.buildKLineURLBySecID(secid, startDate.Format("20060102"), KLineTypeDaily)
+	response, err := e.sendKLineRequest(requestURL, "https://quote.eastmoney.com")
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if response.RC != 0 {
+		return nil, fmt.Errorf("API error: rc=%d", response.RC)
+	}
+
+	klines := response.Data.Klines
+	result := make([]model.IndexDaily, 0, len(klines))
+	for _, kline := range klines {
+		data, err := e.parser.ParseToIndexDaily(tsCode, kline)
+		if err != nil {
+			e.logger.Warnf("Failed to parse index K-line data: %v", err)
+			continue
+		}
+		if e.isInDateRange(data.TradeDate, startDate, endDate) {
+			result = append(result, *data)
+		}
+	}
+
+	e.logger.Infof("Fetched %d index daily K-line records for %s (filtered from %d total)", len(result), tsCode, len(klines))
+	return result, nil
+}
+
+// GetIndexConstituents 获取指数的成分股代码，按行情中心返回的顺序排列
+// 只支持ConstituentIndices中的常用指数，其他指数返回错误
+func (e *EastMoneyCollector) GetIndexConstituents(indexCode string) ([]string, error) {
+	indexCode = strings.ToUpper(indexCode)
+	board, ok := indexConstituentBoards[indexCode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported constituent index: %s, supported: %s",
+			indexCode, strings.Join(ConstituentIndices(), ", "))
+	}
+
+	fetch := func(page, pageSize int) (*EastMoneyStockListResponse, error) {
+		return e.fetchListPage("b:"+board, page, pageSize)
+	}
+	items, err := e.fetchStockListPagesConcurrently(fetch, stockListPageSize, max(e.stockListConcurrency, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch constituents of %s: %w", indexCode, err)
+	}
+
+	now := time.Now()
+	codes := make([]string, 0, len(items))
+	for _, item := range items {
+		codes = append(codes, stockListItemToStock(item, now).TsCode)
+	}
+
+	e.logger.Infof("Fetched %d constituents of index %s", len(codes), indexCode)
+	return codes, nil
+}
@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// indexKLineBody 上证指数日K线接口的JSONP响应样例
+const indexKLineBody = `jsonp1700000000000({"rc":0,"rt":17,"data":{"code":"000001","market":1,"name":"上证指数","klines":[` +
+	`"2024-01-02,2974.93,2962.28,2976.27,2962.28,321978843,365253768192.00,0.76,-0.43,-12.65,0.00",` +
+	`"2024-01-03,2955.57,2967.25,2970.37,2954.56,296497647,334913882112.00,0.53,0.17,4.97,0.00",` +
+	`"2024-01-04,2964.85,2954.35,2968.06,2951.14,301936154,317416325120.00,0.57,-0.43,-12.90,0.00",` +
+	`"bad-line"]}})`
+
+func TestBuildIndexSecID(t *testing.T) {
+	cases := map[string]string{
+		"000001.SH":  "1.000001", // 上证指数，与平安银行000001.SZ代码相同
+		"000300.SH":  "1.000300",
+		"399006.SZ":  "0.399006",
+		"931071.CSI": "2.931071",
+		"000905.sh":  "1.000905",
+	}
+	for tsCode, want := range cases {
+		secid, err := buildIndexSecID(tsCode)
+		require.NoError(t, err, tsCode)
+		assert.Equal(t, want, secid, tsCode)
+	}
+
+	for _, tsCode := range []string{"000001", "000001.BJ", "a.b.c"} {
+		_, err := buildIndexSecID(tsCode)
+		assert.Error(t, err, tsCode)
+	}
+}
+
+func TestKLineParser_ParseToIndexDaily(t *testing.T) {
+	parser := NewKLineParser()
+
+	data, err := parser.ParseToIndexDaily("000001.SH",
+		"2024-01-02,2974.93,2962.28,2976.27,2962.28,321978843,365253768192.00,0.76,-0.43,-12.65,0.00")
+	require.NoError(t, err)
+	assert.Equal(t, "000001.SH", data.TsCode)
+	assert.Equal(t, 20240102, data.TradeDate)
+	assert.Equal(t, 2974.93, data.Open)
+	assert.Equal(t, 2962.28, data.Close)
+	assert.Equal(t, 2976.27, data.High)
+	assert.Equal(t, 2962.28, data.Low)
+	assert.Equal(t, int64(321978843), data.Volume, "index volume stays in lots")
+	assert.Equal(t, 365253768192.00, data.Amount)
+
+	_, err = parser.ParseToIndexDaily("000001.SH", "2024-01-02,2974.93")
+	assert.Error(t, err)
+}
+
+func TestEastMoneyCollector_GetIndexDailyKLine(t *testing.T) {
+	var secid string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secid = r.URL.Query().Get("secid")
+		fmt.Fprint(w, indexKLineBody)
+	}))
+	defer server.Close()
+
+	originalURL := eastMoneyKLineURL
+	eastMoneyKLineURL = server.URL
+	defer func() { eastMoneyKLineURL = originalURL }()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.Local)
+	data, err := collector.GetIndexDailyKLine("000001.SH", start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.000001", secid)
+	require.Len(t, data, 2, "out-of-range and malformed lines are dropped")
+	assert.Equal(t, 20240102, data[0].TradeDate)
+	assert.Equal(t, 20240103, data[1].TradeDate)
+	assert.Equal(t, 2967.25, data[1].Close)
+	for _, d := range data {
+		assert.Equal(t, "000001.SH", d.TsCode)
+	}
+}
+
+func TestEastMoneyCollector_GetIndexConstituents_Unsupported(t *testing.T) {
+	assert.Equal(t, []string{"000016.SH", "000300.SH", "000905.SH"}, ConstituentIndices())
+
+	// 不支持的指数不发起请求，错误中列出支持的指数
+	_, err := (&EastMoneyCollector{}).GetIndexConstituents("399006.SZ")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "399006.SZ")
+	assert.Contains(t, err.Error(), "000300.SH")
+}
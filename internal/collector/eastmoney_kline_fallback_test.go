@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// delistedEmptyKLineBody 退市股票在标准接口的响应：接口报告有历史K线，但没有返回K线
+const delistedEmptyKLineBody = `jsonp1700000000000({"rc":0,"rt":17,"data":{"code":"600087","market":1,"name":"退市长油","dktotal":2,"klines":[]}})`
+
+// delistedKLineBody 退市股票在备用接口的响应
+const delistedKLineBody = `jsonp1700000000000({"rc":0,"rt":17,"data":{"code":"600087","market":1,"name":"退市长油","dktotal":2,"klines":[` +
+	`"2014-06-04,1.50,1.52,1.55,1.48,1000000,150000000.00,4.70,1.33,0.02,1.00",` +
+	`"2014-06-05,1.52,1.49,1.53,1.47,800000,120000000.00,3.95,-1.97,-0.03,0.80"]}})`
+
+// recordingKLineServer 按固定响应返回K线，并记录收到的证券ID
+func recordingKLineServer(t *testing.T, body string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var secids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		secids = append(secids, r.URL.Query().Get("secid"))
+		mu.Unlock()
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), secids...)
+	}
+}
+
+func useKLineURLs(t *testing.T, primary, fallback string) {
+	originalURL, originalFallback := eastMoneyKLineURL, eastMoneyKLineFallbackURL
+	eastMoneyKLineURL, eastMoneyKLineFallbackURL = primary, fallback
+	t.Cleanup(func() { eastMoneyKLineURL, eastMoneyKLineFallbackURL = originalURL, originalFallback })
+}
+
+func TestEastMoneyCollector_KLineFallbackOnEmpty(t *testing.T) {
+	primary, primaryCalls := recordingKLineServer(t, delistedEmptyKLineBody)
+	fallback, fallbackCalls := recordingKLineServer(t, delistedKLineBody)
+	useKLineURLs(t, primary.URL, fallback.URL)
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	start := time.Date(2014, 6, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2014, 6, 30, 0, 0, 0, 0, time.Local)
+	data, err := collector.GetDailyKLine("600087.SH", start, end)
+	require.NoError(t, err)
+
+	// 标准接口返回空后从备用接口取回历史数据
+	assert.Equal(t, []string{"1.600087"}, primaryCalls())
+	assert.Equal(t, []string{"1.600087"}, fallbackCalls())
+	require.Len(t, data, 2)
+	assert.Equal(t, 20140604, data[0].TradeDate)
+	assert.Equal(t, 1.49, data[1].Close)
+}
+
+func TestEastMoneyCollector_KLineFallbackTriesAlternateSecID(t *testing.T) {
+	// 标准接口对原证券ID返回空、对另一市场的证券ID返回数据，备用接口也返回空
+	var mu sync.Mutex
+	var secids []string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secid := r.URL.Query().Get("secid")
+		mu.Lock()
+		secids = append(secids, secid)
+		mu.Unlock()
+		if secid == "0.600087" {
+			fmt.Fprint(w, delistedKLineBody)
+			return
+		}
+		fmt.Fprint(w, `jsonp1({"rc":0,"rt":17,"data":null})`)
+	}))
+	t.Cleanup(primary.Close)
+	fallback, _ := recordingKLineServer(t, `jsonp1({"rc":0,"rt":17,"data":null})`)
+	useKLineURLs(t, primary.URL, fallback.URL)
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	data, err := collector.GetDailyKLine("600087.SH", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.600087", "0.600087"}, secids)
+	require.Len(t, data, 2)
+	assert.Equal(t, "600087.SH", data[0].TsCode)
+}
+
+func TestEastMoneyCollector_KLineNoFallbackWithoutHistory(t *testing.T) {
+	// 接口报告没有任何K线（如新股上市前），不请求备用接口
+	primary, _ := recordingKLineServer(t, `jsonp1({"rc":0,"rt":17,"data":{"code":"603999","market":1,"name":"新股","dktotal":0,"klines":[]}})`)
+	fallback, fallbackCalls := recordingKLineServer(t, delistedKLineBody)
+	useKLineURLs(t, primary.URL, fallback.URL)
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	data, err := collector.GetDailyKLine("603999.SH", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	assert.Empty(t, fallbackCalls())
+}
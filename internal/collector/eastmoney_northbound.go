@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"stock/internal/model"
+)
+
+// NorthboundCollector 北向资金（沪深港通）持股数据采集接口
+type NorthboundCollector interface {
+	GetNorthboundHoldings(tsCode string) ([]model.NorthboundHolding, error)
+	GetNorthboundHoldingsWithContext(ctx context.Context, tsCode string) ([]model.NorthboundHolding, error)
+}
+
+// 数据中心接口查询结果为空时返回的错误码，非沪深港通标的股票没有北向持股数据
+const datacenterCodeEmptyResult = 9201
+
+// northboundPageSize 单次请求的北向持股记录数，每日同步只需要最近的记录，首次同步约覆盖两年历史
+const northboundPageSize = 500
+
+// GetNorthboundHoldings 获取个股北向资金持股历史，按持股日期降序；非沪深港通标的返回空结果
+func (e *EastMoneyCollector) GetNorthboundHoldings(tsCode string) ([]model.NorthboundHolding, error) {
+	return e.GetNorthboundHoldingsWithContext(context.Background(), tsCode)
+}
+
+// GetNorthboundHoldingsWithContext 获取个股北向资金持股历史，ctx取消或超时时中止请求
+func (e *EastMoneyCollector) GetNorthboundHoldingsWithContext(ctx context.Context, tsCode string) ([]model.NorthboundHolding, error) {
+	e.logger.Infof("Fetching northbound holdings for %s from EastMoney", tsCode)
+
+	// 验证股票代码格式
+	if !isValidTsCode(tsCode) {
+		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
+	}
+
+	// 提取股票代码（去掉交易所后缀）
+	stockCode := strings.Split(tsCode, ".")[0]
+
+	// 构建北向持股API URL
+	baseURL := "https://datacenter-web.eastmoney.com/api/data/v1/get"
+	params := url.Values{}
+	params.Set("callback", fmt.Sprintf("jQuery1123014159649525581786_%d", time.Now().UnixMilli()))
+	params.Set("sortColumns", "TRADE_DATE")
+	params.Set("sortTypes", "-1")
+	params.Set("pageSize", fmt.Sprintf("%d", northboundPageSize))
+	params.Set("pageNumber", "1")
+	params.Set("reportName", "RPT_MUTUAL_HOLDSTOCKNORTH_STA")
+	params.Set("columns", "SECURITY_CODE,SECURITY_NAME,TRADE_DATE,CLOSE_PRICE,CHANGE_RATE,HOLD_SHARES,HOLD_MARKET_CAP,FREE_SHARES_RATIO,TOTAL_SHARES_RATIO,ADD_SHARES_REPAIR,HOLD_MARKETCAP_CHG1")
+	params.Set("filter", fmt.Sprintf("(SECURITY_CODE=\"%s\")", stockCode))
+	params.Set("source", "WEB")
+	params.Set("client", "WEB")
+
+	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+
+	// 发送请求
+	resp, err := e.makeRequestWithContext(ctx, requestURL, fmt.Sprintf("https://data.eastmoney.com/hsgtcg/StockHdStatistics/%s.html", stockCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch northbound holdings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	holdings, err := e.parseNorthboundResponse(tsCode, requestURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.Infof("Fetched %d northbound holding records for %s", len(holdings), tsCode)
+	return holdings, nil
+}
+
+// parseNorthboundResponse 解析北向持股接口的JSONP响应，跳过缺少持股日期的记录
+func (e *EastMoneyCollector) parseNorthboundResponse(tsCode, requestURL string, body []byte) ([]model.NorthboundHolding, error) {
+	bodyStr := string(body)
+
+	// 提取JSON部分（去掉JSONP包装）
+	start := strings.Index(bodyStr, "(") + 1
+	end := strings.LastIndex(bodyStr, ")")
+	if start <= 0 || end <= start {
+		return nil, fmt.Errorf("invalid JSONP response format")
+	}
+
+	var response struct {
+		Result *struct {
+			Data []map[string]interface{} `json:"data"`
+		} `json:"result"`
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	}
+
+	if err := e.parseJSON(requestURL, []byte(bodyStr[start:end]), &response); err != nil {
+		return nil, err
+	}
+
+	if !response.Success {
+		if response.Code == datacenterCodeEmptyResult {
+			return []model.NorthboundHolding{}, nil
+		}
+		return nil, fmt.Errorf("API returned error: %s", response.Message)
+	}
+	if response.Result == nil {
+		return []model.NorthboundHolding{}, nil
+	}
+
+	holdings := make([]model.NorthboundHolding, 0, len(response.Result.Data))
+	for _, item := range response.Result.Data {
+		tradeDateStr, _ := item["TRADE_DATE"].(string)
+		tradeDate, ok := parseTimeToInt(tradeDateStr)
+		if !ok {
+			e.logger.Warnf("Skipping northbound holding of %s with invalid trade date: %v", tsCode, item["TRADE_DATE"])
+			continue
+		}
+
+		holdings = append(holdings, model.NorthboundHolding{
+			TsCode:           tsCode,
+			TradeDate:        tradeDate,
+			Close:            parseFloat(item["CLOSE_PRICE"]),
+			ChangePct:        parseFloat(item["CHANGE_RATE"]),
+			HoldShares:       int64(parseFloat(item["HOLD_SHARES"])),
+			HoldMarketCap:    parseFloat(item["HOLD_MARKET_CAP"]),
+			FreeSharesRatio:  parseFloat(item["FREE_SHARES_RATIO"]),
+			TotalSharesRatio: parseFloat(item["TOTAL_SHARES_RATIO"]),
+			ShareChange:      int64(parseFloat(item["ADD_SHARES_REPAIR"])),
+			MarketCapChange:  parseFloat(item["HOLD_MARKETCAP_CHG1"]),
+		})
+	}
+	return holdings, nil
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// northboundJSONP 东方财富北向持股接口返回的样例（贵州茅台），第三条记录持股日期缺失，应跳过
+const northboundJSONP = `jQuery1123014159649525581786_1718100000000({"version":"a1b2c3","result":{"pages":12,"data":[` +
+	`{"SECURITY_CODE":"600519","SECURITY_NAME":"贵州茅台","TRADE_DATE":"2024-06-07 00:00:00","CLOSE_PRICE":1621.5,"CHANGE_RATE":-0.61,` +
+	`"HOLD_SHARES":87546213,"HOLD_MARKET_CAP":141956184379.5,"FREE_SHARES_RATIO":6.97,"TOTAL_SHARES_RATIO":6.97,` +
+	`"ADD_SHARES_REPAIR":-312055,"HOLD_MARKETCAP_CHG1":-1374375032.3},` +
+	`{"SECURITY_CODE":"600519","SECURITY_NAME":"贵州茅台","TRADE_DATE":"2024-06-06 00:00:00","CLOSE_PRICE":1631.5,"CHANGE_RATE":0.4,` +
+	`"HOLD_SHARES":87858268,"HOLD_MARKET_CAP":143340564242,"FREE_SHARES_RATIO":6.99,"TOTAL_SHARES_RATIO":6.99,` +
+	`"ADD_SHARES_REPAIR":"-","HOLD_MARKETCAP_CHG1":null},` +
+	`{"SECURITY_CODE":"600519","SECURITY_NAME":"贵州茅台","TRADE_DATE":null,"HOLD_SHARES":0}` +
+	`],"count":2400},"success":true,"message":"ok","code":0});`
+
+func newNorthboundTestCollector() *EastMoneyCollector {
+	return newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+}
+
+func TestParseNorthboundResponse(t *testing.T) {
+	holdings, err := newNorthboundTestCollector().parseNorthboundResponse("600519.SH", "", []byte(northboundJSONP))
+	require.NoError(t, err)
+	require.Len(t, holdings, 2)
+
+	latest := holdings[0]
+	assert.Equal(t, "600519.SH", latest.TsCode)
+	assert.Equal(t, 20240607, latest.TradeDate)
+	assert.Equal(t, 1621.5, latest.Close)
+	assert.Equal(t, -0.61, latest.ChangePct)
+	assert.Equal(t, int64(87546213), latest.HoldShares)
+	assert.Equal(t, 141956184379.5, latest.HoldMarketCap)
+	assert.Equal(t, 6.97, latest.FreeSharesRatio)
+	assert.Equal(t, 6.97, latest.TotalSharesRatio)
+	assert.Equal(t, int64(-312055), latest.ShareChange)
+	assert.Equal(t, -1374375032.3, latest.MarketCapChange)
+
+	// 持股变化为"-"或null时记为0
+	assert.Equal(t, 20240606, holdings[1].TradeDate)
+	assert.Equal(t, int64(0), holdings[1].ShareChange)
+	assert.Equal(t, 0.0, holdings[1].MarketCapChange)
+}
+
+func TestParseNorthboundResponse_NotConnectStock(t *testing.T) {
+	// 非沪深港通标的，数据中心返回"返回数据为空"
+	body := `jQuery1123014159649525581786_1718100000000({"version":null,"result":null,"success":false,"message":"返回数据为空","code":9201});`
+	holdings, err := newNorthboundTestCollector().parseNorthboundResponse("688999.SH", "", []byte(body))
+	require.NoError(t, err)
+	assert.Empty(t, holdings)
+}
+
+func TestParseNorthboundResponse_Errors(t *testing.T) {
+	c := newNorthboundTestCollector()
+
+	body := `jQuery1123014159649525581786_1718100000000({"version":null,"result":null,"success":false,"message":"参数错误","code":9501});`
+	_, err := c.parseNorthboundResponse("600519.SH", "", []byte(body))
+	assert.ErrorContains(t, err, "参数错误")
+
+	_, err = c.parseNorthboundResponse("600519.SH", "", []byte(`<html>blocked</html>`))
+	assert.ErrorContains(t, err, "invalid JSONP")
+
+	_, err = c.parseNorthboundResponse("600519.SH", "", []byte(`jQuery({"result":`+"\x00"+`})`))
+	assert.ErrorContains(t, err, "failed to parse JSON")
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQuoteServer 模拟东方财富批量行情接口，按请求的secids逐个返回行情
+func newQuoteServer(t *testing.T, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		secids := strings.Split(r.URL.Query().Get("secids"), ",")
+		assert.LessOrEqual(t, len(secids), realtimeBatchSize)
+
+		diff := make([]map[string]interface{}, 0, len(secids))
+		for _, secid := range secids {
+			parts := strings.Split(secid, ".")
+			require.Len(t, parts, 2)
+			market := 0
+			if parts[0] == "1" {
+				market = 1
+			}
+			diff = append(diff, map[string]interface{}{
+				"f2": 10.5, "f5": 1200, "f6": 1260000.0,
+				"f12": parts[1], "f13": market,
+				"f15": 10.8, "f16": 10.1, "f17": 10.2,
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"rc":   0,
+			"data": map[string]interface{}{"total": len(diff), "diff": diff},
+		})
+	}))
+}
+
+func TestEastMoneyCollector_GetRealtimeData_RequestedCodes(t *testing.T) {
+	var requests int32
+	server := newQuoteServer(t, &requests)
+	defer server.Close()
+
+	originalURL := eastMoneyQuoteURL
+	eastMoneyQuoteURL = server.URL
+	defer func() { eastMoneyQuoteURL = originalURL }()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	// 请求超出单批上限的股票，且包含不在列表前50名的代码
+	var tsCodes []string
+	for i := 0; i < 150; i++ {
+		tsCodes = append(tsCodes, fmt.Sprintf("%06d.SZ", 300000+i))
+	}
+	tsCodes = append(tsCodes, "600519.SH", "688981.SH", "invalid")
+
+	data, err := collector.GetRealtimeData(tsCodes)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	require.Len(t, data, 152)
+
+	returned := make(map[string]bool, len(data))
+	for _, d := range data {
+		returned[d.TsCode] = true
+		assert.Equal(t, 10.2, d.Open)
+		assert.Equal(t, 10.8, d.High)
+		assert.Equal(t, 10.1, d.Low)
+		assert.Equal(t, 10.5, d.Close)
+		assert.Equal(t, int64(120000), d.Volume)
+		assert.Equal(t, 1260000.0, d.Amount)
+	}
+	for _, tsCode := range tsCodes[:152] {
+		assert.True(t, returned[tsCode], "missing %s", tsCode)
+	}
+}
@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEastMoneyCollector_GetStockDetail_ListDate(t *testing.T) {
+	bodies := map[string]string{
+		"1.600000": `{"rc":0,"rt":4,"data":{"f57":"600000","f58":"浦发银行","f84":29352178996,"f107":0,"f177":29352178996,"f189":19991110}}`,
+		"0.301589": `{"rc":0,"rt":4,"data":{"f57":"301589","f58":"N诺瓦","f84":0,"f107":0,"f177":0,"f189":"-"}}`,
+	}
+	var fields []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = strings.Split(r.URL.Query().Get("fields"), ",")
+		fmt.Fprintf(w, "jQuery112309283015113892927_1700000000000(%s)", bodies[r.URL.Query().Get("secid")])
+	}))
+	defer server.Close()
+
+	originalURL := eastMoneyStockDetailURL
+	eastMoneyStockDetailURL = server.URL
+	defer func() { eastMoneyStockDetailURL = originalURL }()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	stock, err := collector.GetStockDetail("600000.SH")
+	require.NoError(t, err)
+	assert.Contains(t, fields, "f189")
+	require.NotNil(t, stock.ListDate)
+	assert.Equal(t, "1999-11-10", stock.ListDate.Format(time.DateOnly))
+	assert.Equal(t, "浦发银行", stock.Name)
+	assert.Equal(t, int64(29352178996), stock.TotalShares)
+
+	// 新股上市前接口返回"-"，上市日期保持未知
+	stock, err = collector.GetStockDetail("301589.SZ")
+	require.NoError(t, err)
+	assert.Nil(t, stock.ListDate)
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockListTotalPages(t *testing.T) {
+	assert.Equal(t, 0, stockListTotalPages(0, 50))
+	assert.Equal(t, 1, stockListTotalPages(1, 50))
+	assert.Equal(t, 1, stockListTotalPages(50, 50))
+	assert.Equal(t, 2, stockListTotalPages(51, 50))
+	assert.Equal(t, 103, stockListTotalPages(5123, 50))
+}
+
+// mockStockListPages 模拟分页接口：共total只股票，记录请求的页码和最大并发数
+type mockStockListPages struct {
+	total     int
+	failPage  int
+	mu        sync.Mutex
+	requested []int
+	inFlight  int32
+	maxFlight int32
+}
+
+func (m *mockStockListPages) fetch(page, pageSize int) (*EastMoneyStockListResponse, error) {
+	flight := atomic.AddInt32(&m.inFlight, 1)
+	defer atomic.AddInt32(&m.inFlight, -1)
+	for {
+		current := atomic.LoadInt32(&m.maxFlight)
+		if flight <= current || atomic.CompareAndSwapInt32(&m.maxFlight, current, flight) {
+			break
+		}
+	}
+
+	m.mu.Lock()
+	m.requested = append(m.requested, page)
+	m.mu.Unlock()
+
+	if page == m.failPage {
+		return nil, errors.New("connection reset")
+	}
+
+	response := &EastMoneyStockListResponse{}
+	response.Data.Total = m.total
+	for i := (page - 1) * pageSize; i < page*pageSize && i < m.total; i++ {
+		response.Data.Diff = append(response.Data.Diff, EastMoneyStockListItem{F12: fmt.Sprintf("%06d", i), F13: 0})
+	}
+	return response, nil
+}
+
+func TestFetchStockListPagesConcurrently(t *testing.T) {
+	collector := newEastMoneyCollector(logger.GetGlobalLogger())
+
+	t.Run("fetches every page in order", func(t *testing.T) {
+		mock := &mockStockListPages{total: 523}
+		items, err := collector.fetchStockListPagesConcurrently(mock.fetch, 50, 4)
+		require.NoError(t, err)
+
+		// 11页全部抓取一次，结果按页码顺序拼接
+		require.Len(t, items, 523)
+		for i, item := range items {
+			assert.Equal(t, fmt.Sprintf("%06d", i), item.F12)
+		}
+		assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, mock.requested)
+		assert.LessOrEqual(t, mock.maxFlight, int32(4))
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		mock := &mockStockListPages{total: 20}
+		items, err := collector.fetchStockListPagesConcurrently(mock.fetch, 50, 4)
+		require.NoError(t, err)
+		assert.Len(t, items, 20)
+		assert.Equal(t, []int{1}, mock.requested)
+	})
+
+	t.Run("page failure", func(t *testing.T) {
+		mock := &mockStockListPages{total: 300, failPage: 3}
+		_, err := collector.fetchStockListPagesConcurrently(mock.fetch, 50, 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "page 3")
+	})
+}
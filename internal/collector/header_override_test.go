@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEastMoneyCollector_HeaderOverride(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	// 未配置时使用默认请求头和随机Cookie
+	resp, err := collector.makeRequest(server.URL, "https://data.eastmoney.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "https://data.eastmoney.com/", received.Get("Referer"))
+	assert.Equal(t, collector.GetCurrentCookie(), received.Get("Cookie"))
+
+	// 配置文件中的请求头名称为小写，同名时覆盖默认值
+	collector.SetHeaderOverride(HeaderOverride{
+		Headers: map[string]string{
+			"x-custom-token":  "abc123",
+			"accept-language": "en-US",
+			"referer":         "https://quote.eastmoney.com/",
+		},
+		Cookie: "qgqp_b_id=ops-supplied; st_si=1",
+	})
+
+	resp, err = collector.makeRequest(server.URL, "https://data.eastmoney.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "abc123", received.Get("X-Custom-Token"))
+	assert.Equal(t, "en-US", received.Get("Accept-Language"))
+	assert.Equal(t, "https://quote.eastmoney.com/", received.Get("Referer"))
+	assert.Equal(t, "qgqp_b_id=ops-supplied; st_si=1", received.Get("Cookie"))
+	assert.Len(t, received.Values("Cookie"), 1)
+
+	// 未覆盖的默认请求头保持不变
+	assert.Equal(t, "keep-alive", received.Get("Connection"))
+	assert.NotEmpty(t, received.Get("User-Agent"))
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// 请求头伪装配置名称
+const (
+	HeaderProfileDesktopChrome = "desktop-chrome" // macOS桌面版Chrome
+	HeaderProfileDesktopEdge   = "desktop-edge"   // Windows桌面版Edge
+	HeaderProfileMobileChrome  = "mobile-chrome"  // Android手机版Chrome
+	HeaderProfileMobileSafari  = "mobile-safari"  // iPhone手机版Safari
+)
+
+// HeaderProfile 请求头伪装配置
+// 一组相互一致的User-Agent和客户端提示（sec-ch-ua系列）请求头，避免随机生成的组合出现浏览器与平台对不上的情况；
+// Safari不支持客户端提示，SecChUa为空时请求中不携带sec-ch-ua系列请求头
+type HeaderProfile struct {
+	Name            string `json:"name"`
+	UserAgent       string `json:"user_agent"`
+	SecChUa         string `json:"sec_ch_ua"`
+	SecChUaMobile   string `json:"sec_ch_ua_mobile"`
+	SecChUaPlatform string `json:"sec_ch_ua_platform"`
+}
+
+// headerProfiles 内置的请求头伪装配置
+var headerProfiles = map[string]HeaderProfile{
+	HeaderProfileDesktopChrome: {
+		Name:            HeaderProfileDesktopChrome,
+		UserAgent:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36",
+		SecChUa:         `"Not;A=Brand";v="99", "Google Chrome";v="139", "Chromium";v="139"`,
+		SecChUaMobile:   "?0",
+		SecChUaPlatform: `"macOS"`,
+	},
+	HeaderProfileDesktopEdge: {
+		Name:            HeaderProfileDesktopEdge,
+		UserAgent:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36 Edg/139.0.0.0",
+		SecChUa:         `"Not;A=Brand";v="99", "Microsoft Edge";v="139", "Chromium";v="139"`,
+		SecChUaMobile:   "?0",
+		SecChUaPlatform: `"Windows"`,
+	},
+	HeaderProfileMobileChrome: {
+		Name:            HeaderProfileMobileChrome,
+		UserAgent:       "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Mobile Safari/537.36",
+		SecChUa:         `"Not;A=Brand";v="99", "Google Chrome";v="139", "Chromium";v="139"`,
+		SecChUaMobile:   "?1",
+		SecChUaPlatform: `"Android"`,
+	},
+	HeaderProfileMobileSafari: {
+		Name:      HeaderProfileMobileSafari,
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+	},
+}
+
+// GetHeaderProfile 按名称获取请求头伪装配置
+func GetHeaderProfile(name string) (HeaderProfile, error) {
+	profile, ok := headerProfiles[name]
+	if !ok {
+		return HeaderProfile{}, fmt.Errorf("unknown header profile %q, supported: %v", name, HeaderProfileNames())
+	}
+	return profile, nil
+}
+
+// HeaderProfileNames 获取所有内置请求头伪装配置的名称，按名称排序
+func HeaderProfileNames() []string {
+	names := make([]string, 0, len(headerProfiles))
+	for name := range headerProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply 将配置中的User-Agent和客户端提示请求头写入请求，覆盖请求中已有的同名请求头
+func (p HeaderProfile) Apply(req *http.Request) {
+	req.Header.Set("User-Agent", p.UserAgent)
+	if p.SecChUa == "" {
+		req.Header.Del("sec-ch-ua")
+		req.Header.Del("sec-ch-ua-mobile")
+		req.Header.Del("sec-ch-ua-platform")
+		return
+	}
+	req.Header.Set("sec-ch-ua", p.SecChUa)
+	req.Header.Set("sec-ch-ua-mobile", p.SecChUaMobile)
+	req.Header.Set("sec-ch-ua-platform", p.SecChUaPlatform)
+}
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderProfiles_SelfConsistent(t *testing.T) {
+	platforms := map[string]string{
+		`"macOS"`:   "Macintosh",
+		`"Windows"`: "Windows",
+		`"Android"`: "Android",
+	}
+
+	for _, name := range HeaderProfileNames() {
+		t.Run(name, func(t *testing.T) {
+			profile, err := GetHeaderProfile(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, profile.Name)
+			require.NotEmpty(t, profile.UserAgent)
+
+			// 移动端配置的User-Agent带Mobile标识，客户端提示与之一致
+			mobile := strings.HasPrefix(name, "mobile-")
+			assert.Equal(t, mobile, strings.Contains(profile.UserAgent, "Mobile"))
+
+			if profile.SecChUa == "" {
+				// 只有Safari不发送客户端提示
+				assert.NotContains(t, profile.UserAgent, "Chrome/")
+				assert.Empty(t, profile.SecChUaMobile)
+				assert.Empty(t, profile.SecChUaPlatform)
+				return
+			}
+
+			if mobile {
+				assert.Equal(t, "?1", profile.SecChUaMobile)
+			} else {
+				assert.Equal(t, "?0", profile.SecChUaMobile)
+			}
+			require.Contains(t, platforms, profile.SecChUaPlatform)
+			assert.Contains(t, profile.UserAgent, platforms[profile.SecChUaPlatform])
+
+			// sec-ch-ua中的浏览器及主版本号与User-Agent一致
+			gen := NewUserAgentGenerator()
+			version := gen.extractChromeVersion(profile.UserAgent)
+			assert.Contains(t, profile.SecChUa, `"Chromium";v="`+version+`"`)
+			if strings.Contains(profile.UserAgent, "Edg/") {
+				assert.Contains(t, profile.SecChUa, `"Microsoft Edge";v="`+version+`"`)
+			} else {
+				assert.Contains(t, profile.SecChUa, `"Google Chrome";v="`+version+`"`)
+			}
+		})
+	}
+
+	_, err := GetHeaderProfile("desktop-netscape")
+	assert.Error(t, err)
+}
+
+func TestEastMoneyCollector_HeaderProfile(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+
+	require.NoError(t, collector.SetHeaderProfile(HeaderProfileMobileChrome))
+	resp, err := collector.makeRequest(server.URL, "https://data.eastmoney.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	profile, _ := GetHeaderProfile(HeaderProfileMobileChrome)
+	assert.Equal(t, profile.UserAgent, received.Get("User-Agent"))
+	assert.Equal(t, profile.SecChUa, received.Get("sec-ch-ua"))
+	assert.Equal(t, "?1", received.Get("sec-ch-ua-mobile"))
+	assert.Equal(t, `"Android"`, received.Get("sec-ch-ua-platform"))
+
+	// Safari配置去掉默认请求头中的客户端提示
+	require.NoError(t, collector.SetHeaderProfile(HeaderProfileMobileSafari))
+	resp, err = collector.makeRequest(server.URL, "https://data.eastmoney.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Contains(t, received.Get("User-Agent"), "iPhone")
+	assert.Empty(t, received.Values("sec-ch-ua"))
+	assert.Empty(t, received.Values("sec-ch-ua-mobile"))
+	assert.Empty(t, received.Values("sec-ch-ua-platform"))
+
+	// 无效名称不改变当前配置，名称为空时恢复随机User-Agent
+	assert.Error(t, collector.SetHeaderProfile("unknown"))
+	assert.Equal(t, HeaderProfileMobileSafari, collector.Config.Profile)
+	require.NoError(t, collector.SetHeaderProfile(""))
+	resp, err = collector.makeRequest(server.URL, "https://data.eastmoney.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, collector.GetCurrentUserAgent(), received.Get("User-Agent"))
+}
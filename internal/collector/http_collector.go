@@ -21,7 +21,7 @@ type HTTPCollector struct {
 
 // NewHTTPCollector 创建HTTP采集器
 func NewHTTPCollector(config CollectorConfig, logger *logger.Logger) *HTTPCollector {
-	return &HTTPCollector{
+	collector := &HTTPCollector{
 		BaseCollector: BaseCollector{
 			Config:    config,
 			Connected: false,
@@ -31,6 +31,10 @@ func NewHTTPCollector(config CollectorConfig, logger *logger.Logger) *HTTPCollec
 		},
 		logger: logger,
 	}
+	if err := collector.SetHeaderProfile(config.Profile); err != nil {
+		logger.Warnf("Ignoring header profile for %s collector: %v", config.Name, err)
+	}
+	return collector
 }
 
 // Connect 连接数据源
@@ -85,6 +89,14 @@ func (h *HTTPCollector) makeRequest(method, url string, body io.Reader) (*http.R
 		return nil, fmt.Errorf("collector not connected")
 	}
 
+	// 只缓存不带请求体的GET请求
+	cacheable := method == http.MethodGet && body == nil
+	if cacheable {
+		if resp := h.cachedResponse(url); resp != nil {
+			return resp, nil
+		}
+	}
+
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
@@ -104,6 +116,7 @@ func (h *HTTPCollector) makeRequest(method, url string, body io.Reader) (*http.R
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	h.applyHeaderOverride(req)
 
 	h.logger.Debugf("Making request: %s %s", method, url)
 
@@ -118,6 +131,9 @@ func (h *HTTPCollector) makeRequest(method, url string, body io.Reader) (*http.R
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
+	if cacheable {
+		return h.cacheResponse(url, resp)
+	}
 	return resp, nil
 }
 
@@ -1,6 +1,8 @@
 package collector
 
 import (
+	"context"
+	"net/http"
 	"stock/internal/model"
 	"time"
 )
@@ -71,6 +73,33 @@ type DataCollector interface {
 	GetName() string
 }
 
+// DatacenterContextCollector 支持上下文的数据中心采集接口，调用方可为单次请求设置超时，批量任务取消时中止进行中的请求
+type DatacenterContextCollector interface {
+	// GetPerformanceReportsWithContext 获取业绩报表数据
+	GetPerformanceReportsWithContext(ctx context.Context, tsCode string) ([]model.PerformanceReport, error)
+
+	// GetShareholderCountsWithContext 获取股东户数数据
+	GetShareholderCountsWithContext(ctx context.Context, tsCode string) ([]model.ShareholderCount, error)
+}
+
+// RealtimeContextCollector 支持上下文的实时行情采集接口，分批同步时单个批次超时或任务取消会中止进行中的请求
+type RealtimeContextCollector interface {
+	// GetRealtimeDataWithContext 获取实时数据
+	GetRealtimeDataWithContext(ctx context.Context, tsCodes []string) ([]model.DailyData, error)
+}
+
+// SessionCollector 使用随机User-Agent和Cookie伪装浏览器会话的采集器，上游返回反爬验证页时可查看并强制更换会话
+type SessionCollector interface {
+	// GetCurrentUserAgent 获取当前使用的User-Agent
+	GetCurrentUserAgent() string
+
+	// GetCurrentCookie 获取当前使用的Cookie
+	GetCurrentCookie() string
+
+	// ForceUpdateUserAgentAndCookie 强制更新User-Agent和Cookie
+	ForceUpdateUserAgentAndCookie()
+}
+
 // CollectorConfig 采集器配置
 type CollectorConfig struct {
 	Name      string            `json:"name"`
@@ -80,12 +109,64 @@ type CollectorConfig struct {
 	Headers   map[string]string `json:"headers"`
 	Timeout   time.Duration     `json:"timeout"`
 	RateLimit int               `json:"rate_limit"` // 每秒请求数限制
+	Profile   string            `json:"profile"`    // 请求头伪装配置名称，为空时使用随机生成的User-Agent
+}
+
+// HeaderOverride 运维配置的自定义请求头和Cookie
+// 合并到采集器发出的每个请求中，覆盖代码中的默认值和随机生成的值，上游反爬策略变化时无需改代码重新发布
+type HeaderOverride struct {
+	Headers map[string]string `mapstructure:"headers"` // 额外的请求头，与默认请求头同名时覆盖默认值
+	Cookie  string            `mapstructure:"cookie"`  // Cookie字符串，非空时替换随机生成的Cookie
+	Profile string            `mapstructure:"profile"` // 请求头伪装配置名称（desktop-chrome、mobile-safari等），为空时使用随机生成的User-Agent
+
+	// KLineFallbackURL 标准K线接口返回空时使用的备用K线接口，目前只对东方财富采集器生效，为空时使用默认的备用接口
+	KLineFallbackURL string `mapstructure:"kline_fallback_url"`
 }
 
 // BaseCollector 基础采集器
 type BaseCollector struct {
 	Config    CollectorConfig
 	Connected bool
+	override  HeaderOverride
+	profile   *HeaderProfile
+	cache     *ResponseCache
+	debug     bool
+}
+
+// SetHeaderOverride 设置自定义请求头和Cookie，应在开始采集前调用
+func (b *BaseCollector) SetHeaderOverride(override HeaderOverride) {
+	b.override = override
+}
+
+// SetHeaderProfile 设置请求头伪装配置，名称为空时恢复随机生成的User-Agent，应在开始采集前调用
+func (b *BaseCollector) SetHeaderProfile(name string) error {
+	if name == "" {
+		b.Config.Profile = ""
+		b.profile = nil
+		return nil
+	}
+
+	profile, err := GetHeaderProfile(name)
+	if err != nil {
+		return err
+	}
+	b.Config.Profile = name
+	b.profile = &profile
+	return nil
+}
+
+// applyHeaderOverride 将请求头伪装配置、自定义请求头和Cookie合并到请求中，需在设置完默认请求头之后调用
+// 伪装配置先于自定义请求头生效，运维仍可单独覆盖其中的某个请求头
+func (b *BaseCollector) applyHeaderOverride(req *http.Request) {
+	if b.profile != nil {
+		b.profile.Apply(req)
+	}
+	for key, value := range b.override.Headers {
+		req.Header.Set(key, value)
+	}
+	if b.override.Cookie != "" {
+		req.Header.Set("Cookie", b.override.Cookie)
+	}
 }
 
 // GetName 获取采集器名称
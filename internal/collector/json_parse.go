@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonSnippetLen 调试模式下JSON解析错误中保留的响应开头和结尾的字符数
+const jsonSnippetLen = 200
+
+// SetDebug 设置调试模式，开启后JSON解析错误中附带请求URL和截断的响应片段，应在开始采集前调用
+func (b *BaseCollector) SetDebug(enabled bool) {
+	b.debug = enabled
+}
+
+// parseJSON 解析JSON响应，失败时返回的错误在调试模式下附带请求URL和响应片段，便于排查上游格式变化；
+// 非调试模式下不输出响应内容，避免大段数据写入日志
+func (b *BaseCollector) parseJSON(requestURL string, data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		if !b.debug {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return fmt.Errorf("failed to parse JSON from %s: %w, payload(%d bytes): %s",
+			requestURL, err, len(data), jsonSnippet(data))
+	}
+	return nil
+}
+
+// jsonSnippet 截取响应的开头和结尾各jsonSnippetLen个字符，按字符截断不破坏中文
+func jsonSnippet(data []byte) string {
+	runes := []rune(string(data))
+	if len(runes) <= 2*jsonSnippetLen {
+		return string(runes)
+	}
+	return string(runes[:jsonSnippetLen]) + " ... " + string(runes[len(runes)-jsonSnippetLen:])
+}
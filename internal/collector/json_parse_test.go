@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON_ErrorContext(t *testing.T) {
+	const requestURL = "https://push2.eastmoney.com/api/qt/stock/get?secid=0.000001"
+	payload := []byte(`{"rc":0,"data":{"f57":"000001","f58":"平安银行",`)
+
+	var v map[string]interface{}
+
+	// 非调试模式不输出请求URL和响应内容
+	b := &BaseCollector{}
+	err := b.parseJSON(requestURL, payload, &v)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), requestURL)
+	assert.NotContains(t, err.Error(), "平安银行")
+
+	// 调试模式附带请求URL和完整的短响应
+	b.SetDebug(true)
+	err = b.parseJSON(requestURL, payload, &v)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), requestURL)
+	assert.Contains(t, err.Error(), string(payload))
+
+	require.NoError(t, b.parseJSON(requestURL, []byte(`{"rc":0}`), &v))
+}
+
+func TestParseJSON_Truncated(t *testing.T) {
+	head := `{"data":"` + strings.Repeat("头", jsonSnippetLen)
+	middle := strings.Repeat("x", 1000)
+	tail := strings.Repeat("尾", jsonSnippetLen) + `",`
+	payload := []byte(head + middle + tail)
+
+	b := &BaseCollector{}
+	b.SetDebug(true)
+	var v map[string]interface{}
+	err := b.parseJSON("https://d.10jqka.com.cn/v6/line/hs_000001/01/all.js", payload, &v)
+	require.Error(t, err)
+
+	// 只保留开头和结尾各jsonSnippetLen个字符，中文不被截断
+	msg := err.Error()
+	assert.Contains(t, msg, string([]rune(head)[:jsonSnippetLen])+" ... ")
+	assert.Contains(t, msg, " ... "+string([]rune(tail)[len([]rune(tail))-jsonSnippetLen:]))
+	assert.NotContains(t, msg, middle)
+	assert.Contains(t, msg, "payload(")
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stock/internal/model"
+)
+
+// ChunkedDailyKLineCollector 支持按自然年分段采集日K线的采集器
+// 上市三十年以上的股票全量历史数据量大，一次请求的响应要整体读入内存解析，分段采集可限制单次请求的内存占用，
+// 中途失败时已采集的各段仍可保存，下次从失败的区间继续
+type ChunkedDailyKLineCollector interface {
+	// GetDailyKLineChunked 按自然年分段获取日K线数据，某一段失败时返回之前各段的数据和*ChunkedFetchError
+	GetDailyKLineChunked(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error)
+}
+
+// KLineChunk 分段采集的一个时间区间，包含首尾两个时刻
+type KLineChunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ChunkedFetchError 分段采集在某一段失败，返回的数据为之前各段已采集的K线，从ResumeFrom开始重新采集即可继续
+type ChunkedFetchError struct {
+	ResumeFrom time.Time // 失败区间的起始日期
+	Err        error
+}
+
+// Error 实现error接口
+func (e *ChunkedFetchError) Error() string {
+	return fmt.Sprintf("chunk starting %s failed: %v", e.ResumeFrom.Format("2006-01-02"), e.Err)
+}
+
+// Unwrap 返回失败区间的原始错误
+func (e *ChunkedFetchError) Unwrap() error {
+	return e.Err
+}
+
+// SplitYearChunks 将[startDate, endDate]按自然年拆分为区间，首尾区间分别从startDate开始、到endDate结束
+// startDate晚于endDate时返回空
+func SplitYearChunks(startDate, endDate time.Time) []KLineChunk {
+	var chunks []KLineChunk
+	for start := startDate; !start.After(endDate); {
+		// 区间首尾相接，每段到下一年零点之前结束
+		next := time.Date(start.Year()+1, time.January, 1, 0, 0, 0, 0, start.Location())
+		end := next.Add(-time.Nanosecond)
+		if end.After(endDate) {
+			end = endDate
+		}
+		chunks = append(chunks, KLineChunk{Start: start, End: end})
+		start = next
+	}
+	return chunks
+}
+
+// FetchKLineChunks 按顺序逐段采集K线，合并后按交易日期升序返回，相邻区间重复的交易日期以后采集的为准
+// 某一段失败时停止采集，返回之前各段合并的数据和*ChunkedFetchError
+func FetchKLineChunks[T model.TradeDated](chunks []KLineChunk, fetch func(chunk KLineChunk) ([]T, error)) ([]T, error) {
+	byDate := make(map[int]T)
+	var fetchErr error
+	for _, chunk := range chunks {
+		data, err := fetch(chunk)
+		if err != nil {
+			fetchErr = &ChunkedFetchError{ResumeFrom: chunk.Start, Err: err}
+			break
+		}
+		for _, item := range data {
+			byDate[item.GetTradeDate()] = item
+		}
+	}
+
+	result := make([]T, 0, len(byDate))
+	for _, item := range byDate {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetTradeDate() < result[j].GetTradeDate() })
+	return result, fetchErr
+}
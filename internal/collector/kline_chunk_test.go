@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticDailySeries 生成[from, to]区间内每个工作日一根的日K线
+func syntheticDailySeries(from, to time.Time) []model.DailyData {
+	var series []model.DailyData
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		price := float64(d.YearDay()%50) + 10
+		series = append(series, model.DailyData{
+			TsCode:    "600000.SH",
+			TradeDate: utils.TradeDateOf(d),
+			Open:      price,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price + 0.5,
+			Volume:    int64(d.Year()*1000 + d.YearDay()),
+		})
+	}
+	return series
+}
+
+// filterSeries 模拟上游按日期区间返回K线
+func filterSeries(series []model.DailyData, start, end time.Time) []model.DailyData {
+	var result []model.DailyData
+	for _, bar := range series {
+		date, _ := utils.ParseTradeDate(bar.TradeDate)
+		if !date.Before(start) && !date.After(end) {
+			result = append(result, bar)
+		}
+	}
+	return result
+}
+
+func TestSplitYearChunks(t *testing.T) {
+	loc := utils.AppLocation()
+	start := time.Date(2022, 6, 15, 0, 0, 0, 0, loc)
+	end := time.Date(2024, 3, 1, 15, 0, 0, 0, loc)
+
+	chunks := SplitYearChunks(start, end)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, start, chunks[0].Start)
+	assert.Equal(t, "20221231", chunks[0].End.Format("20060102"))
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, loc), chunks[1].Start)
+	assert.Equal(t, "20231231", chunks[1].End.Format("20060102"))
+	assert.Equal(t, end, chunks[2].End)
+
+	assert.Empty(t, SplitYearChunks(end, start))
+}
+
+func TestFetchKLineChunks_MatchesSingleFetch(t *testing.T) {
+	loc := utils.AppLocation()
+	series := syntheticDailySeries(time.Date(1991, 4, 3, 0, 0, 0, 0, loc), time.Date(2025, 9, 30, 0, 0, 0, 0, loc))
+	start := time.Date(1990, 12, 19, 0, 0, 0, 0, loc)
+	end := time.Date(2025, 9, 30, 16, 0, 0, 0, loc)
+
+	single := filterSeries(series, start, end)
+
+	requests := 0
+	chunked, err := FetchKLineChunks(SplitYearChunks(start, end), func(chunk KLineChunk) ([]model.DailyData, error) {
+		requests++
+		return filterSeries(series, chunk.Start, chunk.End), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 36, requests)
+	assert.Equal(t, single, chunked)
+}
+
+func TestFetchKLineChunks_PartialOnFailure(t *testing.T) {
+	loc := utils.AppLocation()
+	series := syntheticDailySeries(time.Date(2020, 1, 1, 0, 0, 0, 0, loc), time.Date(2023, 12, 31, 0, 0, 0, 0, loc))
+	upstreamErr := errors.New("HTTP 502")
+
+	data, err := FetchKLineChunks(SplitYearChunks(time.Date(2020, 1, 1, 0, 0, 0, 0, loc), time.Date(2023, 12, 31, 0, 0, 0, 0, loc)),
+		func(chunk KLineChunk) ([]model.DailyData, error) {
+			if chunk.Start.Year() == 2022 {
+				return nil, upstreamErr
+			}
+			return filterSeries(series, chunk.Start, chunk.End), nil
+		})
+
+	var chunkErr *ChunkedFetchError
+	require.ErrorAs(t, err, &chunkErr)
+	assert.ErrorIs(t, err, upstreamErr)
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, loc), chunkErr.ResumeFrom)
+
+	// 失败之前的两年已合并返回，失败之后的年份不再请求
+	require.NotEmpty(t, data)
+	assert.Equal(t, series[0].TradeDate, data[0].TradeDate)
+	assert.Less(t, data[len(data)-1].TradeDate, 20220101)
+}
+
+func TestTongHuaShunCollector_YearKLineMatchesAllJS(t *testing.T) {
+	// 同一组K线分别以all.js（价格为分，开高收为相对最低价的差值）和按年文件的格式返回
+	all := `quotebridge_v6_line_hs_600000_01_all({"sortYear":[[2023,2],[2024,2]],` +
+		`"price":"1230,4,28,15,1240,5,20,10,1250,0,10,5,1262,3,8,6","volumn":"100,200,300,400","dates":"1228,1229,0102,0103"})`
+	years := map[int]string{
+		2023: `quotebridge_v6_line_hs_600000_01_2023({"data":"20231228,12.34,12.58,12.30,12.45,100,1245.00,0.01,,,0;20231229,12.45,12.60,12.40,12.50,200,2500.00,0.01,,,0"})`,
+		2024: `quotebridge_v6_line_hs_600000_01_2024({"data":"20240102,12.50,12.60,12.50,12.55,300,3765.00,0.01,,,0;20240103,12.65,12.70,12.62,12.68,400,5072.00,0.01,,,0"})`,
+	}
+
+	collector := &TongHuaShunCollector{}
+	raw, err := collector.parseKLineResponse("600000.SH", "hs_600000", THSKLineTypeDaily, "", all, time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	loc := utils.AppLocation()
+	chunked, err := FetchKLineChunks(SplitYearChunks(time.Date(2023, 12, 1, 0, 0, 0, 0, loc), time.Date(2024, 1, 31, 0, 0, 0, 0, loc)),
+		func(chunk KLineChunk) ([]model.DailyData, error) {
+			return collector.parseYearKLineResponse("600000.SH", "hs_600000", chunk.Start.Year(), "", years[chunk.Start.Year()])
+		})
+	require.NoError(t, err)
+
+	require.Len(t, chunked, len(raw))
+	for i, bar := range chunked {
+		assert.Equal(t, raw[i].TradeDate, bar.TradeDate)
+		assert.Equal(t, []float64{raw[i].Open, raw[i].High, raw[i].Low, raw[i].Close}, []float64{bar.Open, bar.High, bar.Low, bar.Close}, "bar %d", bar.TradeDate)
+		assert.Equal(t, raw[i].Volume, bar.Volume)
+	}
+	// 按年文件带有成交额，all.js没有
+	assert.Equal(t, 1245.0, chunked[0].Amount)
+}
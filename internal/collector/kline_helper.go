@@ -32,12 +32,12 @@ func (p *KLineParser) ParseToDaily(tsCode, kline string) (*model.DailyData, erro
 	return &model.DailyData{
 		TsCode:    tsCode,
 		TradeDate: tradeDateInt,
-		Open:      p.parseFloat(fields[1]),
-		High:      p.parseFloat(fields[3]),
-		Low:       p.parseFloat(fields[4]),
-		Close:     p.parseFloat(fields[2]),
+		Open:      p.parsePrice(fields[1]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
+		Close:     p.parsePrice(fields[2]),
 		Volume:    p.parseInt64(fields[5]) * 100,
-		Amount:    p.parseFloat(fields[6]),
+		Amount:    p.parseAmount(fields[6]),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
@@ -58,12 +58,12 @@ func (p *KLineParser) ParseToWeekly(tsCode, kline string) (*model.WeeklyData, er
 	return &model.WeeklyData{
 		TsCode:    tsCode,
 		TradeDate: tradeDateInt,
-		Open:      p.parseFloat(fields[1]),
-		Close:     p.parseFloat(fields[2]),
-		High:      p.parseFloat(fields[3]),
-		Low:       p.parseFloat(fields[4]),
+		Open:      p.parsePrice(fields[1]),
+		Close:     p.parsePrice(fields[2]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
 		Volume:    p.parseInt64(fields[5]),
-		Amount:    p.parseFloat(fields[6]),
+		Amount:    p.parseAmount(fields[6]),
 		CreatedAt: time.Now(),
 	}, nil
 }
@@ -83,12 +83,12 @@ func (p *KLineParser) ParseToMonthly(tsCode, kline string) (*model.MonthlyData,
 	return &model.MonthlyData{
 		TsCode:    tsCode,
 		TradeDate: tradeDateInt,
-		Open:      p.parseFloat(fields[1]),
-		Close:     p.parseFloat(fields[2]),
-		High:      p.parseFloat(fields[3]),
-		Low:       p.parseFloat(fields[4]),
+		Open:      p.parsePrice(fields[1]),
+		Close:     p.parsePrice(fields[2]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
 		Volume:    p.parseInt64(fields[5]),
-		Amount:    p.parseFloat(fields[6]),
+		Amount:    p.parseAmount(fields[6]),
 		CreatedAt: time.Now(),
 	}, nil
 }
@@ -108,12 +108,12 @@ func (p *KLineParser) ParseToQuarterly(tsCode, kline string) (*model.QuarterlyDa
 	return &model.QuarterlyData{
 		TsCode:    tsCode,
 		TradeDate: tradeDateInt,
-		Open:      p.parseFloat(fields[1]),
-		Close:     p.parseFloat(fields[2]),
-		High:      p.parseFloat(fields[3]),
-		Low:       p.parseFloat(fields[4]),
+		Open:      p.parsePrice(fields[1]),
+		Close:     p.parsePrice(fields[2]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
 		Volume:    p.parseInt64(fields[5]),
-		Amount:    p.parseFloat(fields[6]),
+		Amount:    p.parseAmount(fields[6]),
 		CreatedAt: time.Now(),
 	}, nil
 }
@@ -133,16 +133,42 @@ func (p *KLineParser) ParseToYearly(tsCode, kline string) (*model.YearlyData, er
 	return &model.YearlyData{
 		TsCode:    tsCode,
 		TradeDate: tradeDateInt,
-		Open:      p.parseFloat(fields[1]),
-		Close:     p.parseFloat(fields[2]),
-		High:      p.parseFloat(fields[3]),
-		Low:       p.parseFloat(fields[4]),
+		Open:      p.parsePrice(fields[1]),
+		Close:     p.parsePrice(fields[2]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
 		Volume:    p.parseInt64(fields[5]),
-		Amount:    p.parseFloat(fields[6]),
+		Amount:    p.parseAmount(fields[6]),
 		CreatedAt: time.Now(),
 	}, nil
 }
 
+// ParseToIndexDaily 解析为指数日K线数据，指数成交量保持接口返回的手为单位
+func (p *KLineParser) ParseToIndexDaily(tsCode, kline string) (*model.IndexDaily, error) {
+	fields := strings.Split(kline, ",")
+	if len(fields) < 7 {
+		return nil, fmt.Errorf("invalid kline data format: %s", kline)
+	}
+
+	tradeDateInt, err := p.parseTradeDate(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.IndexDaily{
+		TsCode:    tsCode,
+		TradeDate: tradeDateInt,
+		Open:      p.parsePrice(fields[1]),
+		High:      p.parsePrice(fields[3]),
+		Low:       p.parsePrice(fields[4]),
+		Close:     p.parsePrice(fields[2]),
+		Volume:    p.parseInt64(fields[5]),
+		Amount:    p.parseAmount(fields[6]),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
 // parseTradeDate 解析交易日期
 func (p *KLineParser) parseTradeDate(dateStr string) (int, error) {
 	tradeDate, err := time.Parse("2006-01-02", dateStr)
@@ -164,6 +190,16 @@ func (p *KLineParser) parseFloat(s string) float64 {
 	return f
 }
 
+// parsePrice 解析价格并按标准精度取整
+func (p *KLineParser) parsePrice(s string) float64 {
+	return model.RoundPrice(p.parseFloat(s))
+}
+
+// parseAmount 解析成交额并按标准精度取整
+func (p *KLineParser) parseAmount(s string) float64 {
+	return model.RoundAmount(p.parseFloat(s))
+}
+
 // parseInt64 安全解析64位整数
 func (p *KLineParser) parseInt64(s string) int64 {
 	if s == "" || s == "-" {
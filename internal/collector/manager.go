@@ -9,43 +9,168 @@ import (
 	"stock/internal/model"
 )
 
+// 采集器的连接方式
+const (
+	ConnectAtStartup = "startup"   // 启动时连接，失败只记录警告，首次使用时重新连接
+	ConnectOnDemand  = "on_demand" // 启动时不连接，首次使用时再连接
+)
+
+// 采集器连接的默认重试参数
+const (
+	defaultConnectAttempts   = 3           // 每次连接最多尝试的次数
+	defaultConnectRetryDelay = time.Second // 首次重试前的等待时间，之后每次翻倍
+)
+
 // CollectorManager 采集器管理器
 type CollectorManager struct {
 	collectors map[string]DataCollector
 	logger     *logger.Logger
 	mu         sync.RWMutex
+
+	connectAttempts   int
+	connectRetryDelay time.Duration
+
+	breakers map[string]*CircuitBreaker // 各采集器的熔断器，键为采集器名称
+	failover map[string]string          // 熔断时改用的采集器，键为采集器名称
 }
 
 // NewCollectorManager 创建采集器管理器
 func NewCollectorManager(logger *logger.Logger) *CollectorManager {
 	return &CollectorManager{
-		collectors: make(map[string]DataCollector),
-		logger:     logger,
+		collectors:        make(map[string]DataCollector),
+		logger:            logger,
+		connectAttempts:   defaultConnectAttempts,
+		connectRetryDelay: defaultConnectRetryDelay,
 	}
 }
 
-// RegisterCollector 注册采集器
-func (m *CollectorManager) RegisterCollector(name string, collector DataCollector) {
+// RegisterCollector 注册采集器，以采集器的GetName()作为名称，保证日志和故障切换中引用的名称与采集器自身一致
+// 名称为空时返回错误，同名采集器重复注册时后注册的覆盖先注册的
+func (m *CollectorManager) RegisterCollector(collector DataCollector) error {
+	name := collector.GetName()
+	if name == "" {
+		return fmt.Errorf("collector name cannot be empty")
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if _, exists := m.collectors[name]; exists {
+		m.logger.Warnf("Collector %s already registered, replacing it", name)
+	}
 	m.collectors[name] = collector
 	m.logger.Infof("Registered collector: %s", name)
+	return nil
 }
 
-// GetCollector 获取采集器
-func (m *CollectorManager) GetCollector(name string) (DataCollector, error) {
+// SetCircuitBreakers 设置各采集器的熔断器和熔断时改用的采集器
+// 设置后GetCollector在采集器熔断时返回改用的采集器，改用的采集器也熔断时仍返回原采集器，由其直接返回熔断错误
+func (m *CollectorManager) SetCircuitBreakers(breakers map[string]*CircuitBreaker, failover map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.breakers = breakers
+	m.failover = failover
+}
+
+// CircuitStats 获取各采集器的熔断状态，未设置熔断器时返回空列表
+func (m *CollectorManager) CircuitStats() []CircuitStats {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	return CircuitSnapshot(m.breakers)
+}
+
+// circuitOpen 采集器是否处于熔断状态，半开状态视为未熔断以放行试探请求
+func (m *CollectorManager) circuitOpen(name string) bool {
+	breaker, ok := m.breakers[name]
+	return ok && breaker.State() == CircuitOpen
+}
+
+// GetCollector 获取采集器，采集器熔断时改用配置的备用采集器
+// 采集器尚未连接（启动时连接失败或按需连接）时先重试连接
+func (m *CollectorManager) GetCollector(name string) (DataCollector, error) {
+	m.mu.RLock()
+	fallback, hasFallback := m.failover[name]
+	routed := hasFallback && m.circuitOpen(name) && !m.circuitOpen(fallback)
+	m.mu.RUnlock()
+	if routed {
+		collector, err := m.getCollector(fallback)
+		if err == nil {
+			m.logger.Debugf("Collector %s circuit is open, routing to %s", name, fallback)
+			return collector, nil
+		}
+		m.logger.Warnf("Collector %s circuit is open, failover to %s failed: %v", name, fallback, err)
+	}
+	return m.getCollector(name)
+}
 
+// getCollector 获取指定的采集器，不经过故障切换
+func (m *CollectorManager) getCollector(name string) (DataCollector, error) {
+	m.mu.RLock()
 	collector, exists := m.collectors[name]
+	m.mu.RUnlock()
 	if !exists {
 		return nil, fmt.Errorf("collector not found: %s", name)
 	}
 
+	if err := m.ensureConnected(name, collector); err != nil {
+		return nil, err
+	}
 	return collector, nil
 }
 
+// LookupCollector 获取已注册的采集器，不触发连接，用于启动阶段组装依赖
+func (m *CollectorManager) LookupCollector(name string) (DataCollector, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collector, exists := m.collectors[name]
+	return collector, exists
+}
+
+// ConnectOnStartup 启动时连接所有采集器，连接失败只记录警告而不中断启动，首次使用时会重新连接
+// 数据源短暂不可用不应导致整个进程启动失败
+func (m *CollectorManager) ConnectOnStartup() {
+	m.mu.RLock()
+	collectors := make(map[string]DataCollector, len(m.collectors))
+	for name, collector := range m.collectors {
+		collectors[name] = collector
+	}
+	m.mu.RUnlock()
+
+	for name, collector := range collectors {
+		if err := m.ensureConnected(name, collector); err != nil {
+			m.logger.Warnf("Collector %s is unavailable at startup, will reconnect on first use: %v", name, err)
+		}
+	}
+}
+
+// ensureConnected 采集器未连接时按退避间隔重试连接
+func (m *CollectorManager) ensureConnected(name string, collector DataCollector) error {
+	if collector.IsConnected() {
+		return nil
+	}
+
+	attempts := m.connectAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := m.connectRetryDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = collector.Connect(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			m.logger.Warnf("Failed to connect collector %s (attempt %d/%d), retrying in %v: %v", name, attempt, attempts, delay, err)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("failed to connect collector %s after %d attempts: %w", name, attempts, err)
+}
+
 // GetAvailableCollectors 获取可用的采集器列表
 func (m *CollectorManager) GetAvailableCollectors() []string {
 	m.mu.RLock()
@@ -99,7 +224,7 @@ func (m *CollectorManager) DisconnectAll() {
 
 // GetStockListFromSource 从指定数据源获取股票列表
 func (m *CollectorManager) GetStockListFromSource(sourceName string) ([]model.Stock, error) {
-	collector, err := m.GetCollector(sourceName)
+	collector, err := m.getCollector(sourceName)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +234,7 @@ func (m *CollectorManager) GetStockListFromSource(sourceName string) ([]model.St
 
 // GetStockDataFromSource 从指定数据源获取股票数据
 func (m *CollectorManager) GetStockDataFromSource(sourceName, tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
-	collector, err := m.GetCollector(sourceName)
+	collector, err := m.getCollector(sourceName)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +244,7 @@ func (m *CollectorManager) GetStockDataFromSource(sourceName, tsCode string, sta
 
 // GetRealtimeDataFromSource 从指定数据源获取实时数据
 func (m *CollectorManager) GetRealtimeDataFromSource(sourceName string, tsCodes []string) ([]model.DailyData, error) {
-	collector, err := m.GetCollector(sourceName)
+	collector, err := m.getCollector(sourceName)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +254,7 @@ func (m *CollectorManager) GetRealtimeDataFromSource(sourceName string, tsCodes
 
 // GetPerformanceReportsFromSource 从指定数据源获取业绩报表数据
 func (m *CollectorManager) GetPerformanceReportsFromSource(sourceName, tsCode string) ([]model.PerformanceReport, error) {
-	collector, err := m.GetCollector(sourceName)
+	collector, err := m.getCollector(sourceName)
 	if err != nil {
 		return nil, err
 	}
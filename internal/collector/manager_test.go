@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyCollector 前failures次连接失败的采集器
+type flakyCollector struct {
+	DataCollector
+	name      string
+	failures  int
+	attempts  int
+	connected bool
+}
+
+func (f *flakyCollector) Connect() error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("upstream unreachable")
+	}
+	f.connected = true
+	return nil
+}
+
+func (f *flakyCollector) IsConnected() bool {
+	return f.connected
+}
+
+func (f *flakyCollector) GetName() string {
+	return f.name
+}
+
+func newTestCollectorManager(attempts int) *CollectorManager {
+	m := NewCollectorManager(logger.GetGlobalLogger())
+	m.connectAttempts = attempts
+	m.connectRetryDelay = 0
+	return m
+}
+
+func TestCollectorManager_ConnectOnStartupToleratesFailure(t *testing.T) {
+	m := newTestCollectorManager(2)
+	flaky := &flakyCollector{name: "eastmoney", failures: 3}
+	require.NoError(t, m.RegisterCollector(flaky))
+
+	// 启动时连接失败不中断启动
+	m.ConnectOnStartup()
+	assert.False(t, flaky.IsConnected())
+	assert.Equal(t, 2, flaky.attempts)
+	assert.Empty(t, m.GetAvailableCollectors())
+
+	// 首次使用时重新连接
+	c, err := m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, flaky, c)
+	assert.True(t, flaky.IsConnected())
+	assert.Equal(t, 4, flaky.attempts)
+
+	// 已连接后不再重复连接
+	_, err = m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Equal(t, 4, flaky.attempts)
+}
+
+func TestCollectorManager_GetCollectorRetriesConnect(t *testing.T) {
+	m := newTestCollectorManager(3)
+	flaky := &flakyCollector{name: "tonghuashun", failures: 2}
+	require.NoError(t, m.RegisterCollector(flaky))
+
+	// 按需连接：第3次尝试成功
+	_, err := m.GetCollector("tonghuashun")
+	require.NoError(t, err)
+	assert.Equal(t, 3, flaky.attempts)
+
+	down := &flakyCollector{name: "down", failures: 10}
+	require.NoError(t, m.RegisterCollector(down))
+	_, err = m.GetCollector("down")
+	assert.Error(t, err)
+	assert.Equal(t, 3, down.attempts)
+
+	// 查找采集器不触发连接
+	c, ok := m.LookupCollector("down")
+	assert.True(t, ok)
+	assert.Same(t, down, c)
+	assert.Equal(t, 3, down.attempts)
+
+	_, err = m.GetCollector("missing")
+	assert.Error(t, err)
+}
+
+func TestCollectorManager_RegisterUsesCollectorName(t *testing.T) {
+	m := newTestCollectorManager(1)
+	factory := GetCollectorFactory(logger.GetGlobalLogger())
+
+	// 注册名称与采集器自身的名称一致，日志和故障切换引用同一个名称
+	for _, c := range []DataCollector{factory.GetEastMoneyCollector(), newTongHuaShunCollector(logger.GetGlobalLogger())} {
+		require.NoError(t, m.RegisterCollector(c))
+		found, ok := m.LookupCollector(c.GetName())
+		require.True(t, ok, c.GetName())
+		assert.Same(t, c, found)
+	}
+	_, ok := m.LookupCollector("eastmoney")
+	assert.True(t, ok)
+	_, ok = m.LookupCollector("tonghuashun")
+	assert.True(t, ok)
+
+	// 名称为空的采集器不能注册
+	assert.Error(t, m.RegisterCollector(&flakyCollector{}))
+}
+
+func TestCollectorManager_FailoverOnOpenCircuit(t *testing.T) {
+	m := newTestCollectorManager(1)
+	eastMoney := &flakyCollector{name: "eastmoney", connected: true}
+	tongHuaShun := &flakyCollector{name: "tonghuashun", connected: true}
+	require.NoError(t, m.RegisterCollector(eastMoney))
+	require.NoError(t, m.RegisterCollector(tongHuaShun))
+
+	eastMoneyBreaker, now := newTestCircuitBreaker(1, time.Minute, time.Minute)
+	tongHuaShunBreaker := NewCircuitBreaker("tonghuashun", CircuitBreakerConfig{FailureThreshold: 1})
+	m.SetCircuitBreakers(map[string]*CircuitBreaker{"eastmoney": eastMoneyBreaker, "tonghuashun": tongHuaShunBreaker},
+		map[string]string{"eastmoney": "tonghuashun"})
+
+	c, err := m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+
+	// 东方财富熔断后改用同花顺
+	eastMoneyBreaker.Record(true)
+	c, err = m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, tongHuaShun, c)
+
+	// 指定数据源的调用不切换
+	c, err = m.getCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+
+	// 冷却后半开，恢复使用东方财富以放行试探请求
+	*now = now.Add(time.Minute)
+	c, err = m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+
+	// 试探失败重新熔断；同花顺也熔断时仍返回东方财富，由其直接返回熔断错误
+	require.NoError(t, eastMoneyBreaker.Allow())
+	eastMoneyBreaker.Record(true)
+	tongHuaShunBreaker.Record(true)
+	c, err = m.GetCollector("eastmoney")
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+
+	stats := m.CircuitStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "eastmoney", stats[0].Collector)
+	assert.Equal(t, CircuitOpen, stats[0].State)
+	assert.Equal(t, int64(2), stats[0].Opens)
+	assert.Equal(t, CircuitOpen, stats[1].State)
+}
+
+func TestCollectorFactory_ApplyFailover(t *testing.T) {
+	breaker := NewCircuitBreaker("tonghuashun", CircuitBreakerConfig{FailureThreshold: 1})
+	factory := &CollectorFactory{circuitBreakers: map[string]*CircuitBreaker{"tonghuashun": breaker}}
+	config := CircuitBreakerConfig{Enabled: true, Failover: map[string]string{"tonghuashun": "eastmoney"}}
+
+	m := newTestCollectorManager(1)
+	eastMoney := &flakyCollector{name: "eastmoney", connected: true}
+	tongHuaShun := &flakyCollector{name: "tonghuashun", connected: true}
+	require.NoError(t, m.RegisterCollector(eastMoney))
+	require.NoError(t, m.RegisterCollector(tongHuaShun))
+	breaker.Record(true)
+
+	// 未开启熔断时不设置故障切换
+	require.NoError(t, factory.ApplyFailover(m, CircuitBreakerConfig{Failover: config.Failover}))
+	c, err := m.GetCollector("tonghuashun")
+	require.NoError(t, err)
+	assert.Same(t, tongHuaShun, c)
+
+	// 开启后同花顺熔断时改用东方财富
+	require.NoError(t, factory.ApplyFailover(m, config))
+	c, err = m.GetCollector("tonghuashun")
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+
+	failover, err := (&CollectorFactory{}).NewFailoverManager(config)
+	require.NoError(t, err)
+	assert.Nil(t, failover, "未应用熔断器时不创建故障切换管理器")
+}
@@ -0,0 +1,34 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 同一根K线分别从东方财富和同花顺解析，写入的价格应完全一致
+func TestKLinePrecision_CrossSourceConsistency(t *testing.T) {
+	// 东方财富：日期,开盘,收盘,最高,最低,成交量(手),成交额，价格带多余的尾数
+	emBar, err := NewKLineParser().ParseToDaily("000001.SZ", "2025-06-30,12.3400,12.4500001,12.58,12.2999999,1000,12345678.905")
+	require.NoError(t, err)
+
+	// 同花顺：价格以分为单位，依次为最低价及开盘、最高、收盘价相对最低价的差值
+	response := `quotebridge_v6_line_hs_000001_01_all({"sortYear":[[2025,1]],"price":"1230,4,28,15","volumn":"100000","dates":"0630"})`
+	thsBars, err := (&TongHuaShunCollector{}).parseKLineResponse("000001.SZ", "hs_000001", "01", "", response, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, thsBars, 1)
+	thsBar := thsBars[0]
+
+	assert.Equal(t, emBar.TradeDate, thsBar.TradeDate)
+	assert.Equal(t, emBar.Open, thsBar.Open)
+	assert.Equal(t, emBar.High, thsBar.High)
+	assert.Equal(t, emBar.Low, thsBar.Low)
+	assert.Equal(t, emBar.Close, thsBar.Close)
+
+	assert.Equal(t, 12.34, emBar.Open)
+	assert.Equal(t, 12.45, emBar.Close)
+	assert.Equal(t, 12.3, emBar.Low)
+	assert.Equal(t, 12345678.91, emBar.Amount)
+}
@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock/internal/logger"
+)
+
+// defaultRequestStatsSampleSize 每个上游接口保留用于计算耗时分位数的最近请求数
+const defaultRequestStatsSampleSize = 1000
+
+// RequestStatsConfig 上游请求统计配置
+type RequestStatsConfig struct {
+	Enabled    bool `mapstructure:"enabled"`     // 是否统计采集器发出的上游请求
+	SampleSize int  `mapstructure:"sample_size"` // 每个接口保留最近多少次请求的耗时计算分位数，<=0时使用1000
+}
+
+// EndpointStats 单个上游接口的请求统计
+type EndpointStats struct {
+	Collector string  `json:"collector"`  // 采集器名称
+	Endpoint  string  `json:"endpoint"`   // 接口路径模式，路径中含股票代码、年份等长数字的段替换为*
+	Count     int64   `json:"count"`      // 请求次数
+	Errors    int64   `json:"errors"`     // 失败次数，网络错误和非2xx响应都计为失败
+	ErrorRate float64 `json:"error_rate"` // 失败比例，0-1
+	P50Ms     float64 `json:"p50_ms"`     // 最近请求耗时的中位数，单位：毫秒
+	P95Ms     float64 `json:"p95_ms"`     // 最近请求耗时的95分位数，单位：毫秒
+	Bytes     int64   `json:"bytes"`      // 累计响应体字节数
+}
+
+// endpointRecord 单个接口的累计计数和最近请求的耗时
+type endpointRecord struct {
+	count     int64
+	errors    int64
+	bytes     int64
+	durations []time.Duration // 环形缓冲，最多sampleSize个
+	next      int
+}
+
+// RequestStats 按采集器和接口路径模式汇总上游请求的次数、失败率、耗时和流量
+// 用于发现慢接口和失败率高的接口，作为调整限流的依据；可被多个采集器共享，并发安全
+type RequestStats struct {
+	mu         sync.Mutex
+	sampleSize int
+	endpoints  map[[2]string]*endpointRecord
+}
+
+// NewRequestStats 创建上游请求统计，sampleSize<=0时使用默认值
+func NewRequestStats(sampleSize int) *RequestStats {
+	if sampleSize <= 0 {
+		sampleSize = defaultRequestStatsSampleSize
+	}
+	return &RequestStats{
+		sampleSize: sampleSize,
+		endpoints:  make(map[[2]string]*endpointRecord),
+	}
+}
+
+// Record 记录一次上游请求的结果
+func (s *RequestStats) Record(collectorName, endpoint string, failed bool, duration time.Duration, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{collectorName, endpoint}
+	record, ok := s.endpoints[key]
+	if !ok {
+		record = &endpointRecord{}
+		s.endpoints[key] = record
+	}
+	record.count++
+	if failed {
+		record.errors++
+	}
+	record.bytes += bytes
+	if len(record.durations) < s.sampleSize {
+		record.durations = append(record.durations, duration)
+	} else {
+		record.durations[record.next] = duration
+		record.next = (record.next + 1) % s.sampleSize
+	}
+}
+
+// Snapshot 获取当前各接口的统计，按请求次数降序
+func (s *RequestStats) Snapshot() []EndpointStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]EndpointStats, 0, len(s.endpoints))
+	for key, record := range s.endpoints {
+		sorted := make([]time.Duration, len(record.durations))
+		copy(sorted, record.durations)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		stats := EndpointStats{
+			Collector: key[0],
+			Endpoint:  key[1],
+			Count:     record.count,
+			Errors:    record.errors,
+			P50Ms:     durationPercentileMs(sorted, 0.5),
+			P95Ms:     durationPercentileMs(sorted, 0.95),
+			Bytes:     record.bytes,
+		}
+		if record.count > 0 {
+			stats.ErrorRate = float64(record.errors) / float64(record.count)
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Collector+result[i].Endpoint < result[j].Collector+result[j].Endpoint
+	})
+	return result
+}
+
+// durationPercentileMs 按最近邻排名法取已排序耗时的分位数，单位：毫秒
+func durationPercentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return float64(sorted[index].Microseconds()) / 1000
+}
+
+// numericSegment 含3位以上连续数字的路径段，如股票代码hs_600000、年份文件2023.js；v6、K线类型01等短编号保留
+var numericSegment = regexp.MustCompile(`\d{3,}`)
+
+// endpointPattern 由请求URL生成接口路径模式：主机名加路径，忽略查询参数，含股票代码、年份等长数字的路径段替换为*
+// 同一接口对不同股票的请求归为一类，如 d.10jqka.com.cn/v6/line/*/01/all.js
+func endpointPattern(u *url.URL) string {
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		if numericSegment.MatchString(segment) {
+			segments[i] = "*"
+		}
+	}
+	return u.Host + strings.Join(segments, "/")
+}
+
+// statsTransport 统计上游请求的http.RoundTripper，耗时计算到响应体读完或关闭
+type statsTransport struct {
+	next      http.RoundTripper
+	collector string
+	stats     *RequestStats
+	base      *BaseCollector // 读取调试开关，开启时逐条记录请求日志
+}
+
+// newStatsTransport 包装采集器HTTP客户端的Transport，next为nil时使用http.DefaultTransport
+func newStatsTransport(next http.RoundTripper, collectorName string, stats *RequestStats, base *BaseCollector) http.RoundTripper {
+	if existing, ok := next.(*statsTransport); ok {
+		next = existing.next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &statsTransport{next: next, collector: collectorName, stats: stats, base: base}
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	endpoint := endpointPattern(req.URL)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.record(req, endpoint, 0, true, time.Since(start), 0)
+		return nil, err
+	}
+
+	failed := resp.StatusCode < 200 || resp.StatusCode >= 300
+	resp.Body = &statsBody{ReadCloser: resp.Body, done: func(bytes int64) {
+		t.record(req, endpoint, resp.StatusCode, failed, time.Since(start), bytes)
+	}}
+	return resp, nil
+}
+
+// record 写入统计，调试模式下同时输出单条请求日志
+func (t *statsTransport) record(req *http.Request, endpoint string, status int, failed bool, duration time.Duration, bytes int64) {
+	t.stats.Record(t.collector, endpoint, failed, duration, bytes)
+	if t.base != nil && t.base.debug {
+		logger.Infof("Upstream request %s %s %s: status=%d, duration=%v, bytes=%d",
+			t.collector, req.Method, endpoint, status, duration, bytes)
+	}
+}
+
+// statsBody 统计响应体字节数，读到EOF或关闭时记录一次
+type statsBody struct {
+	io.ReadCloser
+	bytes int64
+	once  sync.Once
+	done  func(bytes int64)
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.bytes) })
+	}
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.bytes) })
+	return err
+}
+
+// installRequestStats 为HTTP客户端开启上游请求统计，stats为nil时恢复原有的Transport
+func installRequestStats(client *http.Client, collectorName string, stats *RequestStats, base *BaseCollector) {
+	if stats == nil {
+		if existing, ok := client.Transport.(*statsTransport); ok {
+			client.Transport = existing.next
+		}
+		return
+	}
+	client.Transport = newStatsTransport(client.Transport, collectorName, stats, base)
+}
+
+// SetRequestStats 开启上游请求统计，stats为nil时关闭，应在开始采集前调用
+func (e *EastMoneyCollector) SetRequestStats(stats *RequestStats) {
+	installRequestStats(e.client, e.Config.Name, stats, &e.BaseCollector)
+}
+
+// SetRequestStats 开启上游请求统计，stats为nil时关闭，应在开始采集前调用
+func (t *TongHuaShunCollector) SetRequestStats(stats *RequestStats) {
+	installRequestStats(t.client, t.Config.Name, stats, &t.BaseCollector)
+}
+
+// SetRequestStats 开启上游请求统计，stats为nil时关闭，应在开始采集前调用
+func (h *HTTPCollector) SetRequestStats(stats *RequestStats) {
+	installRequestStats(h.client, h.Config.Name, stats, &h.BaseCollector)
+}
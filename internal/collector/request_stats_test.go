@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestStats_RecordsUpstreamRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v6/line/hs_600000/01/missing.js" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "quotebridge({})")
+	}))
+	defer server.Close()
+
+	stats := NewRequestStats(0)
+	client := &http.Client{Timeout: 5 * time.Second}
+	installRequestStats(client, "tonghuashun", stats, &BaseCollector{debug: true})
+
+	for _, path := range []string{"/v6/line/hs_600000/01/all.js", "/v6/line/hs_000001/01/all.js", "/v6/line/hs_600000/01/missing.js"} {
+		resp, err := client.Get(server.URL + path + "?t=1")
+		require.NoError(t, err)
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	host, _ := url.Parse(server.URL)
+	snapshot := stats.Snapshot()
+	require.Len(t, snapshot, 2)
+	// 不同股票的同一接口归为一类
+	assert.Equal(t, EndpointStats{Collector: "tonghuashun", Endpoint: host.Host + "/v6/line/*/01/all.js", Count: 2, Bytes: 30},
+		EndpointStats{Collector: snapshot[0].Collector, Endpoint: snapshot[0].Endpoint, Count: snapshot[0].Count, Bytes: snapshot[0].Bytes})
+	assert.Greater(t, snapshot[0].P95Ms, 0.0)
+	assert.GreaterOrEqual(t, snapshot[0].P95Ms, snapshot[0].P50Ms)
+	assert.Zero(t, snapshot[0].ErrorRate)
+
+	// 非2xx响应计为失败
+	assert.Equal(t, host.Host+"/v6/line/*/01/missing.js", snapshot[1].Endpoint)
+	assert.Equal(t, int64(1), snapshot[1].Errors)
+	assert.Equal(t, 1.0, snapshot[1].ErrorRate)
+
+	// 关闭后不再统计
+	installRequestStats(client, "tonghuashun", nil, nil)
+	resp, err := client.Get(server.URL + "/v6/line/hs_600000/01/all.js")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int64(2), stats.Snapshot()[0].Count)
+}
+
+func TestRequestStats_Percentiles(t *testing.T) {
+	stats := NewRequestStats(100)
+	for i := 1; i <= 200; i++ {
+		stats.Record("eastmoney", "push2his.eastmoney.com/api/qt/stock/kline/get", i%50 == 0, time.Duration(i)*time.Millisecond, 10)
+	}
+
+	snapshot := stats.Snapshot()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, int64(200), snapshot[0].Count)
+	assert.Equal(t, int64(4), snapshot[0].Errors)
+	assert.Equal(t, 0.02, snapshot[0].ErrorRate)
+	// 分位数只按最近100次请求（101-200ms）计算
+	assert.Equal(t, 150.0, snapshot[0].P50Ms)
+	assert.Equal(t, 195.0, snapshot[0].P95Ms)
+	assert.Equal(t, int64(2000), snapshot[0].Bytes)
+}
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"stock/internal/utils"
+)
+
+// ResponseCacheConfig 采集响应缓存配置
+// 开发和测试时重复请求同一URL直接使用缓存，减少对上游的访问，生产环境应保持关闭
+type ResponseCacheConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // 是否启用响应缓存
+	TTL     time.Duration `mapstructure:"ttl"`     // 缓存有效期，<=0时永不过期
+	Dir     string        `mapstructure:"dir"`     // 磁盘缓存目录，为空时只缓存在内存中
+}
+
+// ResponseCache 按请求URL缓存上游成功响应的响应体
+// 内存缓存优先，配置了磁盘目录时同时写入文件，进程重启后按文件修改时间判断是否过期；
+// 可通过Set预先写入缓存项，使测试不依赖上游
+type ResponseCache struct {
+	ttl    time.Duration
+	dir    string
+	memory *utils.TTLCache[string, []byte]
+	now    func() time.Time
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(config ResponseCacheConfig) *ResponseCache {
+	return &ResponseCache{
+		ttl:    config.TTL,
+		dir:    config.Dir,
+		memory: utils.NewTTLCache[string, []byte](config.TTL),
+		now:    time.Now,
+	}
+}
+
+// Get 获取URL对应的未过期响应体，内存未命中时读取磁盘缓存
+func (c *ResponseCache) Get(url string) ([]byte, bool) {
+	if body, ok := c.memory.Get(url); ok {
+		return body, true
+	}
+	if c.dir == "" {
+		return nil, false
+	}
+
+	path := c.path(url)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.ttl > 0 && !c.now().Before(info.ModTime().Add(c.ttl)) {
+		return nil, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	c.memory.Set(url, body)
+	return body, true
+}
+
+// Set 写入URL对应的响应体，磁盘写入失败时只保留内存缓存
+func (c *ResponseCache) Set(url string, body []byte) {
+	c.memory.Set(url, body)
+	if c.dir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.path(url), body, 0o644)
+}
+
+// path 获取URL对应的磁盘缓存文件路径
+func (c *ResponseCache) path(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".cache")
+}
+
+// SetResponseCache 设置响应缓存，为nil时关闭缓存，应在开始采集前调用
+func (b *BaseCollector) SetResponseCache(cache *ResponseCache) {
+	b.cache = cache
+}
+
+// cachedResponse 从缓存构造URL对应的响应，未启用缓存或未命中时返回nil
+func (b *BaseCollector) cachedResponse(url string) *http.Response {
+	if b.cache == nil {
+		return nil
+	}
+	body, ok := b.cache.Get(url)
+	if !ok {
+		return nil
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"X-Collector-Cache": []string{"hit"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+// cacheResponse 读取成功响应的响应体写入缓存，并返回可重新读取响应体的响应，未启用缓存时原样返回
+func (b *BaseCollector) cacheResponse(url string, resp *http.Response) (*http.Response, error) {
+	if b.cache == nil {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	b.cache.Set(url, body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
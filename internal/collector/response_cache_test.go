@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readCachedBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestEastMoneyCollector_ResponseCache(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		_, _ = fmt.Fprintf(w, "%s#%d", r.URL.Path, n)
+	}))
+	defer server.Close()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+	collector.SetResponseCache(NewResponseCache(ResponseCacheConfig{Enabled: true, TTL: time.Minute}))
+	defer collector.SetResponseCache(nil)
+
+	resp, err := collector.makeRequest(server.URL+"/kline", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/kline#1", readCachedBody(t, resp))
+
+	// 相同URL直接使用缓存，不再访问上游
+	resp, err = collector.makeRequest(server.URL+"/kline", "")
+	require.NoError(t, err)
+	assert.Equal(t, "hit", resp.Header.Get("X-Collector-Cache"))
+	assert.Equal(t, "/kline#1", readCachedBody(t, resp))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	// 不同URL正常请求
+	resp, err = collector.makeRequest(server.URL+"/stocks", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/stocks#2", readCachedBody(t, resp))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+
+	// 关闭缓存后每次都访问上游
+	collector.SetResponseCache(nil)
+	resp, err = collector.makeRequest(server.URL+"/kline", "")
+	require.NoError(t, err)
+	assert.Equal(t, "/kline#3", readCachedBody(t, resp))
+}
+
+func TestResponseCache_ErrorResponseNotCached(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	collector := newEastMoneyCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.SetRateLimit(100)
+	collector.SetResponseCache(NewResponseCache(ResponseCacheConfig{Enabled: true, TTL: time.Minute}))
+
+	for i := 0; i < 2; i++ {
+		_, err := collector.makeRequest(server.URL, "")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestResponseCache_PreSeededDiskEntries(t *testing.T) {
+	dir := t.TempDir()
+	const url = "https://push2his.eastmoney.com/api/qt/stock/kline/get?secid=1.600519"
+
+	// 预先写入的磁盘缓存可被新的缓存实例读取
+	NewResponseCache(ResponseCacheConfig{Enabled: true, TTL: time.Hour, Dir: dir}).Set(url, []byte("seeded"))
+
+	cache := NewResponseCache(ResponseCacheConfig{Enabled: true, TTL: time.Hour, Dir: dir})
+	body, ok := cache.Get(url)
+	require.True(t, ok)
+	assert.Equal(t, "seeded", string(body))
+
+	// 磁盘缓存按文件修改时间判断过期
+	expired := NewResponseCache(ResponseCacheConfig{Enabled: true, TTL: time.Hour, Dir: dir})
+	expired.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, ok = expired.Get(url)
+	assert.False(t, ok)
+
+	_, ok = cache.Get(url + "&fqt=1")
+	assert.False(t, ok)
+}
@@ -2,7 +2,7 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +26,9 @@ const (
 	THSKLineTypeYearly    = "81" // 年K线
 )
 
+// errTHSKLineNotFound 同花顺K线文件不存在，按年采集时表示该年没有K线（上市之前或长期停牌）
+var errTHSKLineNotFound = errors.New("K-line file not found")
+
 // TongHuaShunCollector 同花顺数据采集器
 type TongHuaShunCollector struct {
 	BaseCollector
@@ -107,6 +110,11 @@ func (t *TongHuaShunCollector) makeRequest(url, refer string) (*http.Response, e
 
 // makeRequestWithContext 发送HTTP请求（带限流和上下文）
 func (t *TongHuaShunCollector) makeRequestWithContext(ctx context.Context, url, refer string) (*http.Response, error) {
+	// 命中响应缓存时不访问上游，也不占用限流配额
+	if resp := t.cachedResponse(url); resp != nil {
+		return resp, nil
+	}
+
 	// 应用限流
 	if err := t.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait failed: %v", err)
@@ -137,6 +145,7 @@ func (t *TongHuaShunCollector) makeRequestWithContext(ctx context.Context, url,
 	req.Header.Set("sec-ch-ua", t.userAgentGen.GenerateSecChUa(t.currentUA))
 	req.Header.Set("sec-ch-ua-platform", t.getPlatformFromUA(t.currentUA))
 	req.Header.Set("Referer", refer)
+	t.applyHeaderOverride(req)
 
 	t.logger.Debugf("Making rate-limited request to TongHuaShun: %s", url)
 
@@ -150,7 +159,7 @@ func (t *TongHuaShunCollector) makeRequestWithContext(ctx context.Context, url,
 		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	return resp, nil
+	return t.cacheResponse(url, resp)
 }
 
 // GetStockList 获取股票列表
@@ -222,6 +231,7 @@ func (t *TongHuaShunCollector) getStockListPage(page int) ([]model.Stock, bool,
 
 	req.Header.Set("Cookie", cookieValue)
 	req.Header.Set("Hexin-V", hexinV)
+	t.applyHeaderOverride(req)
 
 	// 发送请求
 	resp, err := t.client.Do(req)
@@ -524,6 +534,112 @@ func (t *TongHuaShunCollector) GetDailyKLine(tsCode string, startDate, endDate t
 	return append(dailyData, *today), nil
 }
 
+// GetDailyKLineChunked 按自然年分段获取日K线数据，每段请求同花顺按年提供的K线文件，不下载包含全部历史的all.js
+// 区间包含今天时与GetDailyKLine一样补充当日数据，补充失败时从当年重新采集
+func (t *TongHuaShunCollector) GetDailyKLineChunked(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
+	t.logger.Infof("TongHuaShun GetDailyKLineChunked for %s", tsCode)
+
+	now := utils.AppNow()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return FetchKLineChunks(SplitYearChunks(startDate, endDate), func(chunk KLineChunk) ([]model.DailyData, error) {
+		data, err := t.getYearDailyKLine(tsCode, chunk.Start.Year())
+		if err != nil {
+			return nil, err
+		}
+		data = t.filterDataByDateRange(data, chunk.Start, chunk.End)
+
+		if chunk.End.Before(todayStart) {
+			return data, nil
+		}
+		today, _, err := t.GetTodayData(tsCode)
+		if err != nil {
+			return nil, err
+		}
+		return append(data, *today), nil
+	})
+}
+
+// getYearDailyKLine 获取某一年的日K线，该年没有K线时返回空
+func (t *TongHuaShunCollector) getYearDailyKLine(tsCode string, year int) ([]model.DailyData, error) {
+	symbol, market, err := t.parseStockCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
+	}
+	thsCode := t.buildTHSStockCode(symbol, market)
+	if thsCode == "" {
+		return nil, fmt.Errorf("unsupported market for TongHuaShun: %s", market)
+	}
+
+	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/%d.js", thsCode, THSKLineTypeDaily, year)
+	resp, err := t.makeKLineRequest(requestURL)
+	if errors.Is(err, errTHSKLineNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %d daily K-line data: %w", year, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	data, err := t.parseYearKLineResponse(tsCode, thsCode, year, requestURL, string(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %d daily K-line response: %w", year, err)
+	}
+	return data, nil
+}
+
+// parseYearKLineResponse 解析同花顺按年提供的日K线
+// 示例格式: quotebridge_v6_line_hs_000001_01_2024({"data":"20240102,9.39,9.42,9.21,9.21,117014239,1085781552.00,0.603,,,0;..."})
+// 每根K线依次为交易日期、开盘价、最高价、最低价、收盘价、成交量（股）、成交额（元）
+func (t *TongHuaShunCollector) parseYearKLineResponse(tsCode, thsCode string, year int, requestURL, res string) ([]model.DailyData, error) {
+	res = strings.TrimPrefix(res, fmt.Sprintf("quotebridge_v6_line_%s_%s_%d(", thsCode, THSKLineTypeDaily, year))
+	res = strings.TrimSuffix(res, ")")
+
+	var response struct {
+		Data string `json:"data"`
+	}
+	if err := t.parseJSON(requestURL, []byte(res), &response); err != nil {
+		return nil, err
+	}
+	if response.Data == "" {
+		return nil, nil
+	}
+
+	rows := strings.Split(response.Data, ";")
+	result := make([]model.DailyData, 0, len(rows))
+	for _, row := range rows {
+		fields := strings.Split(row, ",")
+		tradeDate, err := strconv.Atoi(fields[0])
+		if len(fields) < 7 || err != nil || !model.ValidTradeDate(tradeDate) {
+			msg := fmt.Sprintf("TongHuaShun %d daily kline for %s has invalid row: %q", year, tsCode, row)
+			if strictErr := utils.StrictViolation(msg); strictErr != nil {
+				return nil, strictErr
+			}
+			logger.Warnf("Skipping %s", msg)
+			continue
+		}
+
+		now := time.Now()
+		result = append(result, model.DailyData{
+			TsCode:    tsCode,
+			TradeDate: tradeDate,
+			Open:      model.RoundPrice(t.parseFloat(fields[1])),
+			High:      model.RoundPrice(t.parseFloat(fields[2])),
+			Low:       model.RoundPrice(t.parseFloat(fields[3])),
+			Close:     model.RoundPrice(t.parseFloat(fields[4])),
+			Volume:    t.parseInt64(fields[5]),
+			Amount:    model.RoundAmount(t.parseFloat(fields[6])),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return result, nil
+}
+
 // GetWeeklyKLine 获取周K线数据
 func (t *TongHuaShunCollector) GetWeeklyKLine(tsCode string, startDate, endDate time.Time) ([]model.WeeklyData, error) {
 	t.logger.Infof("TongHuaShun GetWeeklyKLine for %s", tsCode)
@@ -685,9 +801,44 @@ func (t *TongHuaShunCollector) GetRealtimeData(tsCodes []string) ([]model.DailyD
 	return []model.DailyData{}, fmt.Errorf("TongHuaShun GetRealtimeData not implemented yet")
 }
 
-// GetTodayData 获取当日数据
-func (t *TongHuaShunCollector) GetTodayData(tsCode string) (*model.DailyData, string, error) {
-	t.logger.Infof("TongHuaShun GetTodayData for %s", tsCode)
+// THSPeriod 同花顺当期K线数据的周期
+type THSPeriod string
+
+const (
+	THSPeriodDay     THSPeriod = "day"     // 当日
+	THSPeriodWeek    THSPeriod = "week"    // 本周
+	THSPeriodMonth   THSPeriod = "month"   // 本月
+	THSPeriodQuarter THSPeriod = "quarter" // 本季
+	THSPeriodYear    THSPeriod = "year"    // 本年
+)
+
+// thsPeriodKLineTypes 各周期对应的同花顺K线类型
+var thsPeriodKLineTypes = map[THSPeriod]string{
+	THSPeriodDay:     THSKLineTypeDaily,
+	THSPeriodWeek:    THSKLineTypeWeekly,
+	THSPeriodMonth:   THSKLineTypeMonthly,
+	THSPeriodQuarter: THSKLineTypeQuarterly,
+	THSPeriodYear:    THSKLineTypeYearly,
+}
+
+// periodKLineType 获取周期对应的同花顺K线类型
+func periodKLineType(period THSPeriod) (string, error) {
+	klineType, ok := thsPeriodKLineTypes[period]
+	if !ok {
+		return "", fmt.Errorf("unsupported period for TongHuaShun: %s", period)
+	}
+	return klineType, nil
+}
+
+// GetCurrentPeriodData 获取当期（当日、本周、本月、本季或本年）截至最新交易日的K线数据和股票名称
+// 各周期的接口返回格式相同，统一按日K线结构返回，TradeDate为最新交易日
+func (t *TongHuaShunCollector) GetCurrentPeriodData(tsCode string, period THSPeriod) (*model.DailyData, string, error) {
+	t.logger.Infof("TongHuaShun GetCurrentPeriodData(%s) for %s", period, tsCode)
+
+	klineType, err := periodKLineType(period)
+	if err != nil {
+		return nil, "", err
+	}
 
 	// 解析股票代码
 	symbol, market, err := t.parseStockCode(tsCode)
@@ -702,12 +853,12 @@ func (t *TongHuaShunCollector) GetTodayData(tsCode string) (*model.DailyData, st
 	}
 
 	// 构建请求URL - 基于提供的curl命令
-	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/defer/today.js", thsCode, THSKLineTypeDaily)
+	requestURL := fmt.Sprintf("%s/v6/line/%s/%s/defer/today.js", t.Config.BaseURL, thsCode, klineType)
 
 	// 发送请求
 	resp, err := t.makeTodayDataRequest(requestURL)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to fetch today data: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch %s data: %w", period, err)
 	}
 	defer resp.Body.Close()
 
@@ -718,16 +869,25 @@ func (t *TongHuaShunCollector) GetTodayData(tsCode string) (*model.DailyData, st
 	}
 
 	// 解析响应数据
-	todayData, name, err := t.parseTodayDataResponse(tsCode, thsCode, string(body))
+	data, name, err := t.parseTodayDataResponse(tsCode, thsCode, requestURL, string(body))
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to parse today data response: %w", err)
+		return nil, "", fmt.Errorf("failed to parse %s data response: %w", period, err)
 	}
 
-	return todayData, name, nil
+	return data, name, nil
+}
+
+// GetTodayData 获取当日数据
+func (t *TongHuaShunCollector) GetTodayData(tsCode string) (*model.DailyData, string, error) {
+	return t.GetCurrentPeriodData(tsCode, THSPeriodDay)
 }
 
 // makeTodayDataRequest 发送当日数据请求
 func (t *TongHuaShunCollector) makeTodayDataRequest(url string) (*http.Response, error) {
+	if resp := t.cachedResponse(url); resp != nil {
+		return resp, nil
+	}
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -759,6 +919,7 @@ func (t *TongHuaShunCollector) makeTodayDataRequest(url string) (*http.Response,
 		timestamp, timestamp, timestamp, timestamp-1, timestamp, GenerateWencaiToken())
 
 	req.Header.Set("Cookie", cookieValue)
+	t.applyHeaderOverride(req)
 
 	// 应用限流
 	if err := t.limiter.Wait(context.Background()); err != nil {
@@ -777,11 +938,11 @@ func (t *TongHuaShunCollector) makeTodayDataRequest(url string) (*http.Response,
 		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	return resp, nil
+	return t.cacheResponse(url, resp)
 }
 
 // parseTodayDataResponse 解析当日数据响应
-func (t *TongHuaShunCollector) parseTodayDataResponse(tsCode, thsCode, res string) (*model.DailyData, string, error) {
+func (t *TongHuaShunCollector) parseTodayDataResponse(tsCode, thsCode, requestURL, res string) (*model.DailyData, string, error) {
 	// 同花顺返回的是JavaScript格式，需要提取数据部分
 	// 实际格式: quotebridge_v6_line_hs_601899_11_defer_today({"hs_601899": {...}})
 
@@ -811,8 +972,8 @@ func (t *TongHuaShunCollector) parseTodayDataResponse(tsCode, thsCode, res strin
 
 	// 解析JSON - 实际格式是包含股票代码作为key的对象
 	var response map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
-		return nil, "", fmt.Errorf("failed to parse JSON: %w", err)
+	if err := t.parseJSON(requestURL, []byte(jsonStr), &response); err != nil {
+		return nil, "", err
 	}
 
 	// 获取股票数据（key是thsCode，如"hs_601899"）
@@ -870,6 +1031,7 @@ func (t *TongHuaShunCollector) parseTodayDataResponse(tsCode, thsCode, res strin
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	todayData.NormalizePrecision()
 
 	return todayData, t.getStringValue(dataMap, "name"), nil
 }
@@ -904,268 +1066,46 @@ func (t *TongHuaShunCollector) getStringValue(dataMap map[string]interface{}, ke
 	}
 }
 
+// 各周期K线模型与日K线的字段完全一致，当期数据直接做类型转换
+
 // GetThisWeekData 获取本周数据
 func (t *TongHuaShunCollector) GetThisWeekData(tsCode string) (*model.WeeklyData, error) {
-	t.logger.Infof("TongHuaShun GetThisWeekData for %s", tsCode)
-
-	// 解析股票代码
-	symbol, market, err := t.parseStockCode(tsCode)
+	data, _, err := t.GetCurrentPeriodData(tsCode, THSPeriodWeek)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
-	}
-
-	// 构建同花顺股票代码格式
-	thsCode := t.buildTHSStockCode(symbol, market)
-	if thsCode == "" {
-		return nil, fmt.Errorf("unsupported market for TongHuaShun: %s", market)
-	}
-
-	// 构建请求URL - 使用周K线类型
-	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/defer/today.js", thsCode, THSKLineTypeWeekly)
-
-	// 发送请求
-	resp, err := t.makeTodayDataRequest(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch this week data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// 解析响应数据
-	weekData, err := t.parseThisWeekDataResponse(tsCode, thsCode, string(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse this week data response: %w", err)
+		return nil, err
 	}
-
-	return weekData, nil
+	weekData := model.WeeklyData(*data)
+	return &weekData, nil
 }
 
 // GetThisMonthData 获取本月数据
 func (t *TongHuaShunCollector) GetThisMonthData(tsCode string) (*model.MonthlyData, error) {
-	t.logger.Infof("TongHuaShun GetThisMonthData for %s", tsCode)
-
-	// 解析股票代码
-	symbol, market, err := t.parseStockCode(tsCode)
+	data, _, err := t.GetCurrentPeriodData(tsCode, THSPeriodMonth)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
-	}
-
-	// 构建同花顺股票代码格式
-	thsCode := t.buildTHSStockCode(symbol, market)
-	if thsCode == "" {
-		return nil, fmt.Errorf("unsupported market for TongHuaShun: %s", market)
-	}
-
-	// 构建请求URL - 使用月K线类型
-	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/defer/today.js", thsCode, THSKLineTypeMonthly)
-
-	// 发送请求
-	resp, err := t.makeTodayDataRequest(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch this month data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// 解析响应数据
-	monthData, err := t.parseThisMonthDataResponse(tsCode, thsCode, string(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse this month data response: %w", err)
+		return nil, err
 	}
-
-	return monthData, nil
+	monthData := model.MonthlyData(*data)
+	return &monthData, nil
 }
 
 // GetThisQuarterData 获取本季数据
 func (t *TongHuaShunCollector) GetThisQuarterData(tsCode string) (*model.QuarterlyData, error) {
-	t.logger.Infof("TongHuaShun GetThisQuarterData for %s", tsCode)
-
-	// 解析股票代码
-	symbol, market, err := t.parseStockCode(tsCode)
+	data, _, err := t.GetCurrentPeriodData(tsCode, THSPeriodQuarter)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
-	}
-
-	// 构建同花顺股票代码格式
-	thsCode := t.buildTHSStockCode(symbol, market)
-	if thsCode == "" {
-		return nil, fmt.Errorf("unsupported market for TongHuaShun: %s", market)
-	}
-
-	// 构建请求URL - 使用季K线类型
-	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/defer/today.js", thsCode, THSKLineTypeQuarterly)
-
-	// 发送请求
-	resp, err := t.makeTodayDataRequest(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch this quarter data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// 解析响应数据
-	quarterData, err := t.parseThisQuarterDataResponse(tsCode, thsCode, string(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse this quarter data response: %w", err)
+		return nil, err
 	}
-
-	return quarterData, nil
+	quarterData := model.QuarterlyData(*data)
+	return &quarterData, nil
 }
 
 // GetThisYearData 获取本年数据
 func (t *TongHuaShunCollector) GetThisYearData(tsCode string) (*model.YearlyData, error) {
-	t.logger.Infof("TongHuaShun GetThisYearData for %s", tsCode)
-
-	// 解析股票代码
-	symbol, market, err := t.parseStockCode(tsCode)
-	if err != nil {
-		return nil, fmt.Errorf("invalid tsCode format: %s", tsCode)
-	}
-
-	// 构建同花顺股票代码格式
-	thsCode := t.buildTHSStockCode(symbol, market)
-	if thsCode == "" {
-		return nil, fmt.Errorf("unsupported market for TongHuaShun: %s", market)
-	}
-
-	// 构建请求URL - 使用年K线类型
-	requestURL := fmt.Sprintf("https://d.10jqka.com.cn/v6/line/%s/%s/defer/today.js", thsCode, THSKLineTypeYearly)
-
-	// 发送请求
-	resp, err := t.makeTodayDataRequest(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch this year data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// 解析响应数据
-	yearData, err := t.parseThisYearDataResponse(tsCode, thsCode, string(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse this year data response: %w", err)
-	}
-
-	return yearData, nil
-}
-
-// parseThisWeekDataResponse 解析本周数据响应
-func (t *TongHuaShunCollector) parseThisWeekDataResponse(tsCode, thsCode, res string) (*model.WeeklyData, error) {
-	// 使用通用解析方法
-	dailyData, _, err := t.parseTodayDataResponse(tsCode, thsCode, res)
-	if err != nil {
-		return nil, err
-	}
-
-	// 转换为周K线数据格式
-	weekData := &model.WeeklyData{
-		TsCode:    dailyData.TsCode,
-		TradeDate: dailyData.TradeDate,
-		Open:      dailyData.Open,
-		High:      dailyData.High,
-		Low:       dailyData.Low,
-		Close:     dailyData.Close,
-		Volume:    dailyData.Volume,
-		Amount:    dailyData.Amount,
-		CreatedAt: dailyData.CreatedAt,
-		UpdatedAt: dailyData.UpdatedAt,
-	}
-
-	return weekData, nil
-}
-
-// parseThisMonthDataResponse 解析本月数据响应
-func (t *TongHuaShunCollector) parseThisMonthDataResponse(tsCode, thsCode, res string) (*model.MonthlyData, error) {
-	// 使用通用解析方法
-	dailyData, _, err := t.parseTodayDataResponse(tsCode, thsCode, res)
+	data, _, err := t.GetCurrentPeriodData(tsCode, THSPeriodYear)
 	if err != nil {
 		return nil, err
 	}
-
-	// 转换为月K线数据格式
-	monthData := &model.MonthlyData{
-		TsCode:    dailyData.TsCode,
-		TradeDate: dailyData.TradeDate,
-		Open:      dailyData.Open,
-		High:      dailyData.High,
-		Low:       dailyData.Low,
-		Close:     dailyData.Close,
-		Volume:    dailyData.Volume,
-		Amount:    dailyData.Amount,
-		CreatedAt: dailyData.CreatedAt,
-		UpdatedAt: dailyData.UpdatedAt,
-	}
-
-	return monthData, nil
-}
-
-// parseThisQuarterDataResponse 解析本季数据响应
-func (t *TongHuaShunCollector) parseThisQuarterDataResponse(tsCode, thsCode, res string) (*model.QuarterlyData, error) {
-	// 使用通用解析方法
-	dailyData, _, err := t.parseTodayDataResponse(tsCode, thsCode, res)
-	if err != nil {
-		return nil, err
-	}
-
-	// 转换为季K线数据格式
-	quarterData := &model.QuarterlyData{
-		TsCode:    dailyData.TsCode,
-		TradeDate: dailyData.TradeDate,
-		Open:      dailyData.Open,
-		High:      dailyData.High,
-		Low:       dailyData.Low,
-		Close:     dailyData.Close,
-		Volume:    dailyData.Volume,
-		Amount:    dailyData.Amount,
-		CreatedAt: dailyData.CreatedAt,
-		UpdatedAt: dailyData.UpdatedAt,
-	}
-
-	return quarterData, nil
-}
-
-// parseThisYearDataResponse 解析本年数据响应
-func (t *TongHuaShunCollector) parseThisYearDataResponse(tsCode, thsCode, res string) (*model.YearlyData, error) {
-	// 使用通用解析方法
-	dailyData, _, err := t.parseTodayDataResponse(tsCode, thsCode, res)
-	if err != nil {
-		return nil, err
-	}
-
-	// 转换为年K线数据格式
-	yearData := &model.YearlyData{
-		TsCode:    dailyData.TsCode,
-		TradeDate: dailyData.TradeDate,
-		Open:      dailyData.Open,
-		High:      dailyData.High,
-		Low:       dailyData.Low,
-		Close:     dailyData.Close,
-		Volume:    dailyData.Volume,
-		Amount:    dailyData.Amount,
-		CreatedAt: dailyData.CreatedAt,
-		UpdatedAt: dailyData.UpdatedAt,
-	}
-
-	return yearData, nil
+	yearData := model.YearlyData(*data)
+	return &yearData, nil
 }
 
 // GetPerformanceReports 获取业绩报表数据 - 空实现
@@ -1346,7 +1286,7 @@ func (t *TongHuaShunCollector) getKLineData(tsCode, klineType string, startDate,
 	}
 
 	// 解析响应数据
-	klineData, err := t.parseKLineResponse(tsCode, thsCode, klineType, string(body), startDate, endDate)
+	klineData, err := t.parseKLineResponse(tsCode, thsCode, klineType, requestURL, string(body), startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse K-line response: %w", err)
 	}
@@ -1356,6 +1296,10 @@ func (t *TongHuaShunCollector) getKLineData(tsCode, klineType string, startDate,
 
 // makeKLineRequest 发送K线数据请求
 func (t *TongHuaShunCollector) makeKLineRequest(url string) (*http.Response, error) {
+	if resp := t.cachedResponse(url); resp != nil {
+		return resp, nil
+	}
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -1367,6 +1311,7 @@ func (t *TongHuaShunCollector) makeKLineRequest(url string) (*http.Response, err
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36")
 	req.Header.Set("sec-ch-ua", `"Not;A=Brand";v="99", "Google Chrome";v="139", "Chromium";v="139"`)
 	req.Header.Set("sec-ch-ua-mobile", "?0")
+	t.applyHeaderOverride(req)
 
 	// 应用限流
 	if err := t.limiter.Wait(context.Background()); err != nil {
@@ -1380,16 +1325,20 @@ func (t *TongHuaShunCollector) makeKLineRequest(url string) (*http.Response, err
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: HTTP %d", errTHSKLineNotFound, resp.StatusCode)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		defer resp.Body.Close()
 		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	return resp, nil
+	return t.cacheResponse(url, resp)
 }
 
 // parseKLineResponse 解析同花顺K线响应数据
-func (t *TongHuaShunCollector) parseKLineResponse(tsCode, thsCode, klineType, res string, startDate, endDate time.Time) ([]THSKLineData, error) {
+func (t *TongHuaShunCollector) parseKLineResponse(tsCode, thsCode, klineType, requestURL, res string, startDate, endDate time.Time) ([]THSKLineData, error) {
 	// 同花顺返回的是JavaScript格式，需要提取数据部分
 	// 示例格式: quotebridge_v6_line_hs_001208_01_all({"data":"20240101,10.5,10.8,10.2,10.6,1000000;..."})
 
@@ -1408,20 +1357,36 @@ func (t *TongHuaShunCollector) parseKLineResponse(tsCode, thsCode, klineType, re
 		Dates    string  `json:"dates"`
 	}
 
-	if err := json.Unmarshal([]byte(res), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	if err := t.parseJSON(requestURL, []byte(res), &response); err != nil {
+		return nil, err
 	}
 
 	prices := strings.Split(response.Price, ",")
 	volumes := strings.Split(response.Volume, ",")
 	dates := strings.Split(response.Dates, ",")
 
+	// 各年份的K线数之和应与日期、成交量条数一致，价格每根K线4个值；不一致时按最短的截断
+	total := 0
+	for _, arr := range response.SortYear {
+		if len(arr) >= 2 {
+			total += arr[1]
+		}
+	}
+	if total != len(dates) || len(dates) != len(volumes) || len(prices) != len(dates)*4 {
+		msg := fmt.Sprintf("TongHuaShun %s kline for %s has mismatched record counts: %d by year, %d dates, %d volumes, %d prices",
+			klineType, tsCode, total, len(dates), len(volumes), len(prices))
+		if strictErr := utils.StrictViolation(msg); strictErr != nil {
+			return nil, strictErr
+		}
+		logger.Warnf("%s", msg)
+	}
+
 	// 解析K线数据
 	var klineData = make([]THSKLineData, 0, len(dates))
 	var index int
 	for _, arr := range response.SortYear {
 		year, num := arr[0], arr[1]
-		for num > 0 && len(dates) > index && len(prices) > index*4 && len(volumes) > index {
+		for num > 0 && len(dates) > index && len(prices) >= index*4+4 && len(volumes) > index {
 			var data = THSKLineData{
 				TsCode:    tsCode,
 				Volume:    0,
@@ -1430,10 +1395,19 @@ func (t *TongHuaShunCollector) parseKLineResponse(tsCode, thsCode, klineType, re
 				UpdatedAt: time.Now(),
 			}
 
-			td, err := time.ParseInLocation("20060102", fmt.Sprintf("%d%s", year, dates[index]), time.Local)
-			if err != nil {
-				return nil, err
+			// 日期由年份和MMDD拼接而成，拼接结果不是真实日期时跳过该K线，不影响其他K线
+			tradeDate, err := strconv.Atoi(fmt.Sprintf("%d%s", year, dates[index]))
+			if err != nil || !model.ValidTradeDate(tradeDate) {
+				msg := fmt.Sprintf("TongHuaShun %s kline for %s has invalid date: year %d, date %q", klineType, tsCode, year, dates[index])
+				if strictErr := utils.StrictViolation(msg); strictErr != nil {
+					return nil, strictErr
+				}
+				logger.Warnf("Skipping %s", msg)
+				num--
+				index++
+				continue
 			}
+			td := time.Date(tradeDate/10000, time.Month(tradeDate/100%100), tradeDate%100, 0, 0, 0, 0, utils.AppLocation())
 
 			// 检查日期范围
 			if !startDate.IsZero() && td.Before(startDate) {
@@ -1447,17 +1421,18 @@ func (t *TongHuaShunCollector) parseKLineResponse(tsCode, thsCode, klineType, re
 				continue
 			}
 
-			data.TradeDate, _ = strconv.Atoi(fmt.Sprintf("%d%s", year, dates[index]))
+			data.TradeDate = tradeDate
 			low, _ := strconv.Atoi(prices[index*4])
 			open, _ := strconv.Atoi(prices[index*4+1])
 			high, _ := strconv.Atoi(prices[index*4+2])
 			over, _ := strconv.Atoi(prices[index*4+3])
 			volume, _ := strconv.ParseInt(volumes[index], 10, 64)
 
-			data.Low = float64(low) / 100
-			data.Open = float64(low+open) / 100
-			data.High = float64(low+high) / 100
-			data.Close = float64(low+over) / 100
+			// 价格以分为单位，开盘、最高、收盘价为相对最低价的差值
+			data.Low = model.RoundPrice(float64(low) / 100)
+			data.Open = model.RoundPrice(float64(low+open) / 100)
+			data.High = model.RoundPrice(float64(low+high) / 100)
+			data.Close = model.RoundPrice(float64(low+over) / 100)
 			data.Volume = volume
 
 			klineData = append(klineData, data)
@@ -1,10 +1,12 @@
 package collector
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"stock/internal/logger"
+	"stock/internal/utils"
 )
 
 func TestTongHuaShunCollector_Basic(t *testing.T) {
@@ -360,3 +362,49 @@ func TestTongHuaShunCollector_GetDailyKLine_Implementation(t *testing.T) {
 		})
 	}
 }
+
+func TestTongHuaShunCollector_ParseKLineSkipsInvalidDates(t *testing.T) {
+	// 第二根K线的日期1332拼接后为20251332，不是真实日期
+	response := `quotebridge_v6_line_hs_000001_01_all({"sortYear":[[2025,3]],"price":"1230,4,28,15,1240,5,20,10,1250,0,10,5","volumn":"100,200,300","dates":"0627,1332,0630"})`
+
+	bars, err := (&TongHuaShunCollector{}).parseKLineResponse("000001.SZ", "hs_000001", "01", "", response, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected invalid date to be skipped, got error: %v", err)
+	}
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 bars, got %d", len(bars))
+	}
+	if bars[0].TradeDate != 20250627 || bars[1].TradeDate != 20250630 {
+		t.Errorf("Unexpected trade dates: %d, %d", bars[0].TradeDate, bars[1].TradeDate)
+	}
+	// 跳过的K线不应使后续K线的价格错位
+	if bars[1].Low != 12.5 || bars[1].Volume != 300 {
+		t.Errorf("Expected third bar's price and volume, got low %v volume %d", bars[1].Low, bars[1].Volume)
+	}
+}
+
+func TestTongHuaShunCollector_ParseKLineStrictMode(t *testing.T) {
+	t.Cleanup(func() { utils.SetStrictMode(false) })
+	// sortYear声明3根K线，但日期和成交量只有2条
+	mismatched := `quotebridge_v6_line_hs_000001_01_all({"sortYear":[[2025,3]],"price":"1230,4,28,15,1240,5,20,10","volumn":"100,200","dates":"0627,0630"})`
+	invalidDate := `quotebridge_v6_line_hs_000001_01_all({"sortYear":[[2025,2]],"price":"1230,4,28,15,1240,5,20,10","volumn":"100,200","dates":"0627,1332"})`
+
+	for name, response := range map[string]string{"mismatched counts": mismatched, "invalid date": invalidDate} {
+		t.Run(name, func(t *testing.T) {
+			utils.SetStrictMode(false)
+			bars, err := (&TongHuaShunCollector{}).parseKLineResponse("000001.SZ", "hs_000001", "01", "", response, time.Time{}, time.Time{})
+			if err != nil {
+				t.Fatalf("Expected lenient mode to keep parsing, got error: %v", err)
+			}
+			if len(bars) == 0 {
+				t.Fatal("Expected parsed bars in lenient mode")
+			}
+
+			utils.SetStrictMode(true)
+			_, err = (&TongHuaShunCollector{}).parseKLineResponse("000001.SZ", "hs_000001", "01", "", response, time.Time{}, time.Time{})
+			if !errors.Is(err, utils.ErrDataInconsistency) {
+				t.Fatalf("Expected data inconsistency error in strict mode, got: %v", err)
+			}
+		})
+	}
+}
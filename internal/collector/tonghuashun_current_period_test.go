@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodKLineType(t *testing.T) {
+	cases := map[THSPeriod]string{
+		THSPeriodDay:     "01",
+		THSPeriodWeek:    "11",
+		THSPeriodMonth:   "21",
+		THSPeriodQuarter: "91",
+		THSPeriodYear:    "81",
+	}
+	for period, want := range cases {
+		got, err := periodKLineType(period)
+		require.NoError(t, err, "period=%s", period)
+		assert.Equal(t, want, got, "period=%s", period)
+	}
+
+	_, err := periodKLineType("hour")
+	assert.Error(t, err)
+}
+
+func TestTongHuaShunCollector_CurrentPeriodWrappers(t *testing.T) {
+	// 按请求的K线类型返回不同的收盘价，验证各周期请求了对应的K线类型
+	closes := map[string]string{"01": "10.20", "11": "10.50", "21": "11.00", "91": "12.00", "81": "13.00"}
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /v6/line/hs_600000/{klineType}/defer/today.js
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) != 7 {
+			http.NotFound(w, r)
+			return
+		}
+		klineType := parts[4]
+		requested = append(requested, klineType)
+		_, _ = fmt.Fprintf(w, `quotebridge_v6_line_hs_600000_%s_defer_today({"hs_600000":{"1":"20251010","7":"10.00","8":"13.50","9":"9.80","11":"%s","13":12345,"19":"125919.00","name":"浦发银行"}})`,
+			klineType, closes[klineType])
+	}))
+	defer server.Close()
+
+	collector := newTongHuaShunCollector(logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}))
+	collector.Config.BaseURL = server.URL
+
+	today, name, err := collector.GetTodayData("600000.SH")
+	require.NoError(t, err)
+	assert.Equal(t, "浦发银行", name)
+	assert.Equal(t, 10.2, today.Close)
+
+	week, err := collector.GetThisWeekData("600000.SH")
+	require.NoError(t, err)
+	assert.Equal(t, 10.5, week.Close)
+
+	month, err := collector.GetThisMonthData("600000.SH")
+	require.NoError(t, err)
+	assert.Equal(t, 11.0, month.Close)
+
+	quarter, err := collector.GetThisQuarterData("600000.SH")
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, quarter.Close)
+
+	year, err := collector.GetThisYearData("600000.SH")
+	require.NoError(t, err)
+	assert.Equal(t, "600000.SH", year.TsCode)
+	assert.Equal(t, 20251010, year.TradeDate)
+	assert.Equal(t, 13.0, year.Close)
+	assert.Equal(t, int64(12345), year.Volume)
+	assert.Equal(t, 125919.0, year.Amount)
+
+	assert.Equal(t, []string{"01", "11", "21", "91", "81"}, requested)
+}
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"strings"
 	"time"
 
+	"stock/internal/collector"
+	"stock/internal/indicator"
 	"stock/internal/logger"
 	"stock/internal/notification"
+	"stock/internal/utils"
 
 	"github.com/spf13/viper"
 )
@@ -17,10 +21,27 @@ type Config struct {
 	Redis     RedisConfig         `mapstructure:"redis"`
 	Log       logger.LogConfig    `mapstructure:"log"`
 	JWT       JWTConfig           `mapstructure:"jwt"`
+	Auth      AuthConfig          `mapstructure:"auth"`
 	RateLimit RateLimitConfig     `mapstructure:"rate_limit"`
 	Metrics   MetricsConfig       `mapstructure:"metrics"`
 	CORS      CORSConfig          `mapstructure:"cors"`
 	Notify    notification.Config `mapstructure:"notify"`
+	Worker    WorkerConfig        `mapstructure:"worker"`
+	Task      TaskConfig          `mapstructure:"task"`
+	Score     ScoreConfig         `mapstructure:"score"`
+
+	// Collectors 各采集器的自定义请求头和Cookie，键为采集器名称（eastmoney、tonghuashun等）
+	Collectors map[string]collector.HeaderOverride `mapstructure:"collectors"`
+
+	// CollectorCache 采集响应缓存，用于开发和测试，生产环境保持关闭
+	CollectorCache collector.ResponseCacheConfig `mapstructure:"collector_cache"`
+
+	// UpstreamStats 采集器上游请求统计，按接口汇总次数、失败率和耗时分位数，通过metrics.path查看
+	UpstreamStats collector.RequestStatsConfig `mapstructure:"upstream_stats"`
+
+	// CircuitBreaker 采集器熔断，上游连续失败时暂停请求并改用备用采集器，熔断状态通过metrics.path查看
+	// 故障切换对Web接口和worker、cli的K线、股票列表、实时行情同步生效，只有东方财富提供的数据熔断时直接失败
+	CircuitBreaker collector.CircuitBreakerConfig `mapstructure:"circuit_breaker"`
 }
 
 // AppConfig 应用配置
@@ -30,6 +51,18 @@ type AppConfig struct {
 	Env     string `mapstructure:"env"`
 	Port    int    `mapstructure:"port"`
 	Debug   bool   `mapstructure:"debug"`
+
+	// Timezone 交易日期和收盘时刻使用的时区（IANA名称），不依赖服务器的本地时区
+	Timezone string `mapstructure:"timezone"`
+
+	// StrictMode 严格模式：非法K线、解析告警、记录数不匹配和数据缺口都中止同步并发送通知，默认宽松模式只记录日志后继续
+	StrictMode bool `mapstructure:"strict_mode"`
+
+	// SkipUnchangedUpsert 写入K线时跳过与已有记录的开高低收、成交量和成交额完全相同的K线，updated_at只在数据实际变化时更新
+	SkipUnchangedUpsert bool `mapstructure:"skip_unchanged_upsert"`
+
+	// CollectorConnect 采集器连接方式：startup启动时连接（失败不中断启动），on_demand首次使用时再连接
+	CollectorConnect string `mapstructure:"collector_connect"`
 }
 
 // ServerConfig 服务器配置
@@ -52,6 +85,10 @@ type DatabaseConfig struct {
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+
+	// AutoMigrateOnStartup Web服务启动时是否自动迁移表结构，未配置时生产环境（app.env为production）不迁移、其他环境迁移
+	// 大表迁移可能锁表并拖慢启动，生产环境应通过 cli -cmd=migrate 显式执行
+	AutoMigrateOnStartup bool `mapstructure:"auto_migrate_on_startup"`
 }
 
 // RedisConfig Redis配置
@@ -71,6 +108,92 @@ type JWTConfig struct {
 	Issuer      string `mapstructure:"issuer"`
 }
 
+// WorkerConfig 定时任务配置
+type WorkerConfig struct {
+	CollectSinceListDate bool `mapstructure:"collect_since_list_date"` // 全量同步时从上市日期开始采集，跳过上市前的区间
+	RealtimeBatchSize    int  `mapstructure:"realtime_batch_size"`     // 全量同步实时行情时每批请求的股票数量
+	RealtimeConcurrency  int  `mapstructure:"realtime_concurrency"`    // 全量同步实时行情时并发请求的批次数
+	RealtimeSnapshot     bool `mapstructure:"realtime_snapshot"`       // 同步实时行情时保存最新行情快照，供实时行情接口source=snapshot查询
+	StockListConcurrency int  `mapstructure:"stock_list_concurrency"`  // 分页抓取股票列表的并发数，<=1时逐页串行抓取
+	TestLimit            int  `mapstructure:"test_limit"`              // 每个采集任务最多处理的股票数量，用于测试环境，<=0表示不限制
+
+	// MarketCloseTime 收盘后当日K线可用的时刻，HH:MM格式
+	MarketCloseTime string `mapstructure:"market_close_time"`
+
+	// DataFinalAfter 当日K线成为最终数据的时刻，HH:MM格式，不能早于MarketCloseTime，为空时与MarketCloseTime相同
+	// 复权收盘价和最终成交量在15:00收盘后逐步定型，有时到晚上才稳定；此后更新过的当日K线视为最终数据不再重复采集，之前更新的仍为临时数据
+	DataFinalAfter string `mapstructure:"data_final_after"`
+
+	// SyncVerifyThreshold 日K线批量同步后，股票池中有当日K线的股票占比低于该比例（0-1）时发送高优先级告警，<=0表示不检查
+	// 用于发现上游封禁、鉴权变化等导致的大面积静默失败，单只股票的失败计数可能低估这类问题
+	SyncVerifyThreshold float64 `mapstructure:"sync_verify_threshold"`
+
+	// FormingBarMaxAge 数据库中当期周/月/年K线的有效期，更新时间在有效期内且之后没有经过收盘时跳过同花顺当期K线请求，<=0表示每次都重新采集
+	FormingBarMaxAge time.Duration `mapstructure:"forming_bar_max_age"`
+
+	// DBWriteConcurrency 同步K线时最多同时写数据库的任务数，与K线采集并发（kline.concurrency）分开限制，<=0表示不单独限制
+	// 采集可以高并发，写入集中在分表上容易耗尽连接池并产生锁竞争，应小于采集并发和数据库连接池大小
+	DBWriteConcurrency int `mapstructure:"db_write_concurrency"`
+
+	// DBBackoffMinConcurrency 采集任务返回MySQL连接数耗尽（Too many connections）时并发减半，最低降到该值，写入恢复后逐档恢复，<=0表示不退避
+	DBBackoffMinConcurrency int `mapstructure:"db_backoff_min_concurrency"`
+
+	// ChunkedHistoryFetch 跨年的日K线同步按自然年分段采集，每次请求只返回一年的K线
+	// 上市三十年以上的股票全量采集时响应很大（同花顺all.js包含全部历史），分段后限制单次请求的内存占用，中途失败时已采集的年份会先保存
+	ChunkedHistoryFetch bool `mapstructure:"chunked_history_fetch"`
+
+	// LocalKLineAggregation 周K、月K、年K线优先由已保存的日K线聚合，不再单独请求数据源，大幅减少上游请求
+	// 聚合结果依赖日K线是否完整，开启后应保证日K线任务先于周期K线任务执行
+	LocalKLineAggregation bool `mapstructure:"local_kline_aggregation"`
+
+	// 各类采集任务的并发和限流，业绩报表、股东人数和北向持股使用的数据中心接口比K线接口更容易被封禁
+	KLine       JobLimitConfig `mapstructure:"kline"`       // K线采集任务
+	Performance JobLimitConfig `mapstructure:"performance"` // 业绩报表采集任务
+	Shareholder JobLimitConfig `mapstructure:"shareholder"` // 股东人数采集任务
+	Northbound  JobLimitConfig `mapstructure:"northbound"`  // 北向持股采集任务
+
+	Retention KLineRetentionConfig `mapstructure:"retention"` // 各周期K线的保留年限
+}
+
+// KLineRetentionConfig 各周期K线的保留年限，维护任务定期删除早于保留期的K线，<=0表示永久保留
+type KLineRetentionConfig struct {
+	DailyYears   int `mapstructure:"daily_years"`   // 日K线保留年限
+	WeeklyYears  int `mapstructure:"weekly_years"`  // 周K线保留年限
+	MonthlyYears int `mapstructure:"monthly_years"` // 月K线保留年限
+	YearlyYears  int `mapstructure:"yearly_years"`  // 年K线保留年限
+}
+
+// JobLimitConfig 单类采集任务的并发和限流配置
+type JobLimitConfig struct {
+	Concurrency int     `mapstructure:"concurrency"` // 最大并发数
+	RateLimit   float64 `mapstructure:"rate_limit"`  // 每秒最多启动的采集数，<=0表示只受采集器自身限流控制
+}
+
+// StockCap 按TestLimit计算采集任务实际处理的股票数量，未配置TestLimit时返回total
+func (c WorkerConfig) StockCap(total int) int {
+	if c.TestLimit > 0 && c.TestLimit < total {
+		return c.TestLimit
+	}
+	return total
+}
+
+// TaskConfig 异步任务配置
+type TaskConfig struct {
+	RetentionDays   int           `mapstructure:"retention_days"`   // 已完成和失败任务的保留天数，<=0表示不清理
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // 过期任务的清理间隔
+}
+
+// ScoreConfig 股票综合评分配置
+type ScoreConfig struct {
+	Weights indicator.ScoreWeights `mapstructure:"weights"` // 技术面、基本面、筹码集中度的权重，按比例生效
+}
+
+// AuthConfig 接口鉴权配置
+type AuthConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"`
+}
+
 // RateLimitConfig 限流配置
 type RateLimitConfig struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -95,6 +218,9 @@ type CORSConfig struct {
 	MaxAge           int      `mapstructure:"max_age"`
 }
 
+// EnvProduction 生产环境的app.env取值
+const EnvProduction = "production"
+
 // Load 加载配置
 func Load() (*Config, error) {
 	viper.SetConfigName("app")
@@ -102,8 +228,11 @@ func Load() (*Config, error) {
 	viper.AddConfigPath("./configs")
 	viper.AddConfigPath(".")
 
-	// 设置环境变量前缀
+	// 环境变量覆盖配置文件：优先级为 环境变量 > 配置文件 > 默认值
+	// 变量名为STOCK_加上大写的配置路径，层级之间用下划线连接，如database.password对应STOCK_DATABASE_PASSWORD
+	// 只有设置了默认值的配置项才会从环境变量读取，数据库密码、通知webhook等敏感配置都有默认值，可以不写入配置文件
 	viper.SetEnvPrefix("STOCK")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	// 设置默认值
@@ -117,6 +246,9 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if !viper.IsSet("database.auto_migrate_on_startup") {
+		config.Database.AutoMigrateOnStartup = config.App.Env != EnvProduction
+	}
 
 	return &config, nil
 }
@@ -128,6 +260,10 @@ func setDefaults() {
 	viper.SetDefault("app.env", "development")
 	viper.SetDefault("app.port", 8080)
 	viper.SetDefault("app.debug", true)
+	viper.SetDefault("app.timezone", utils.DefaultAppTimezone)
+	viper.SetDefault("app.strict_mode", false)
+	viper.SetDefault("app.skip_unchanged_upsert", false)
+	viper.SetDefault("app.collector_connect", collector.ConnectAtStartup)
 
 	// Server defaults
 	viper.SetDefault("server.read_timeout", "30s")
@@ -146,6 +282,8 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 500)
 	viper.SetDefault("database.max_idle_conns", 100)
 	viper.SetDefault("database.conn_max_lifetime", "300s")
+	// 不设置默认值，未配置时按app.env决定；嵌套配置项需单独绑定环境变量
+	_ = viper.BindEnv("database.auto_migrate_on_startup", "STOCK_DATABASE_AUTO_MIGRATE_ON_STARTUP")
 
 	// Redis defaults
 	viper.SetDefault("redis.host", "localhost")
@@ -169,6 +307,10 @@ func setDefaults() {
 	viper.SetDefault("jwt.expire_hours", 24)
 	viper.SetDefault("jwt.issuer", "stock")
 
+	// Auth defaults
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.token", "")
+
 	// Rate limit defaults
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.rps", 100)
@@ -187,10 +329,68 @@ func setDefaults() {
 	viper.SetDefault("cors.allow_credentials", true)
 	viper.SetDefault("cors.max_age", 86400)
 
+	// Worker defaults
+	viper.SetDefault("worker.collect_since_list_date", true)
+	viper.SetDefault("worker.realtime_batch_size", 100)
+	viper.SetDefault("worker.realtime_concurrency", 4)
+	viper.SetDefault("worker.realtime_snapshot", false)
+	viper.SetDefault("worker.stock_list_concurrency", 1)
+	viper.SetDefault("worker.test_limit", 0)
+	viper.SetDefault("worker.market_close_time", utils.DefaultMarketCloseTime)
+	viper.SetDefault("worker.data_final_after", utils.DefaultDataFinalAfter)
+	viper.SetDefault("worker.forming_bar_max_age", time.Hour)
+	viper.SetDefault("worker.sync_verify_threshold", 0.9)
+	// 除STOCK_WORKER_TEST_LIMIT外额外绑定不带前缀的WORKER_TEST_LIMIT，便于测试部署临时覆盖
+	_ = viper.BindEnv("worker.test_limit", "STOCK_WORKER_TEST_LIMIT", "WORKER_TEST_LIMIT")
+	viper.SetDefault("worker.kline.concurrency", 100)
+	viper.SetDefault("worker.db_write_concurrency", 20)
+	viper.SetDefault("worker.db_backoff_min_concurrency", 5)
+	viper.SetDefault("worker.chunked_history_fetch", false)
+	viper.SetDefault("worker.local_kline_aggregation", false)
+	viper.SetDefault("worker.kline.rate_limit", 0)
+	viper.SetDefault("worker.performance.concurrency", 20)
+	viper.SetDefault("worker.performance.rate_limit", 5)
+	viper.SetDefault("worker.shareholder.concurrency", 20)
+	viper.SetDefault("worker.shareholder.rate_limit", 5)
+	viper.SetDefault("worker.northbound.concurrency", 20)
+	viper.SetDefault("worker.northbound.rate_limit", 5)
+	viper.SetDefault("worker.retention.daily_years", 10)
+	viper.SetDefault("worker.retention.weekly_years", 20)
+	viper.SetDefault("worker.retention.monthly_years", 0)
+	viper.SetDefault("worker.retention.yearly_years", 0)
+
+	// Collector cache defaults
+	viper.SetDefault("collector_cache.enabled", false)
+	viper.SetDefault("collector_cache.ttl", "10m")
+	viper.SetDefault("collector_cache.dir", "")
+
+	// Upstream stats defaults
+	viper.SetDefault("upstream_stats.enabled", false)
+	viper.SetDefault("upstream_stats.sample_size", 1000)
+
+	// Circuit breaker defaults
+	viper.SetDefault("circuit_breaker.enabled", false)
+	viper.SetDefault("circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("circuit_breaker.window", "1m")
+	viper.SetDefault("circuit_breaker.cooldown", "30s")
+	viper.SetDefault("circuit_breaker.failover", map[string]string{"eastmoney": "tonghuashun", "tonghuashun": "eastmoney"})
+
+	// Task defaults
+	viper.SetDefault("task.retention_days", 30)
+	viper.SetDefault("task.cleanup_interval", "24h")
+
+	// Score defaults
+	viper.SetDefault("score.weights.technical", indicator.DefaultScoreWeights.Technical)
+	viper.SetDefault("score.weights.fundamental", indicator.DefaultScoreWeights.Fundamental)
+	viper.SetDefault("score.weights.shareholder", indicator.DefaultScoreWeights.Shareholder)
+
 	// Notify defaults
 	viper.SetDefault("notify.dingtalk.enabled", false)
 	viper.SetDefault("notify.dingtalk.webhook", "")
 	viper.SetDefault("notify.dingtalk.secret", "")
 	viper.SetDefault("notify.wework.enabled", false)
 	viper.SetDefault("notify.wework.webhook", "")
+	viper.SetDefault("notify.retry.enabled", false)
+	viper.SetDefault("notify.retry.interval", "1m")
+	viper.SetDefault("notify.retry.max_age", "24h")
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFromYAML 在临时目录写入configs/app.yaml后加载配置
+func loadFromYAML(t *testing.T, content string) *Config {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configs", "app.yaml"), []byte(content), 0o644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+		viper.Reset()
+	})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestLoad_WorkerJobLimits(t *testing.T) {
+	cfg := loadFromYAML(t, `
+worker:
+  performance:
+    concurrency: 10
+    rate_limit: 2.5
+  shareholder:
+    concurrency: 5
+`)
+
+	assert.Equal(t, JobLimitConfig{Concurrency: 10, RateLimit: 2.5}, cfg.Worker.Performance)
+	// 未配置的字段使用默认值
+	assert.Equal(t, JobLimitConfig{Concurrency: 5, RateLimit: 5}, cfg.Worker.Shareholder)
+	assert.Equal(t, JobLimitConfig{Concurrency: 100, RateLimit: 0}, cfg.Worker.KLine)
+}
+
+func TestLoad_WorkerTestLimit(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  name: test
+`)
+		// 默认不限制
+		assert.Equal(t, 0, cfg.Worker.TestLimit)
+		assert.Equal(t, 5000, cfg.Worker.StockCap(5000))
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+worker:
+  test_limit: 20
+`)
+		assert.Equal(t, 20, cfg.Worker.TestLimit)
+		assert.Equal(t, 20, cfg.Worker.StockCap(5000))
+	})
+
+	t.Run("env overrides yaml", func(t *testing.T) {
+		t.Setenv("WORKER_TEST_LIMIT", "5")
+		cfg := loadFromYAML(t, `
+worker:
+  test_limit: 20
+`)
+		assert.Equal(t, 5, cfg.Worker.TestLimit)
+		assert.Equal(t, 5, cfg.Worker.StockCap(5000))
+		assert.Equal(t, 3, cfg.Worker.StockCap(3))
+	})
+}
+
+func TestLoad_AutoMigrateOnStartup(t *testing.T) {
+	t.Run("development default", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  env: development
+`)
+		assert.True(t, cfg.Database.AutoMigrateOnStartup)
+	})
+
+	t.Run("production default", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  env: production
+`)
+		assert.False(t, cfg.Database.AutoMigrateOnStartup)
+	})
+
+	t.Run("explicit yaml", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  env: production
+database:
+  auto_migrate_on_startup: true
+`)
+		assert.True(t, cfg.Database.AutoMigrateOnStartup)
+	})
+
+	t.Run("env overrides yaml", func(t *testing.T) {
+		t.Setenv("STOCK_DATABASE_AUTO_MIGRATE_ON_STARTUP", "false")
+		cfg := loadFromYAML(t, `
+database:
+  auto_migrate_on_startup: true
+`)
+		assert.False(t, cfg.Database.AutoMigrateOnStartup)
+	})
+}
+
+func TestLoad_AppStrictMode(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  name: test
+`)
+		assert.False(t, cfg.App.StrictMode)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  strict_mode: true
+`)
+		assert.True(t, cfg.App.StrictMode)
+	})
+}
+
+func TestLoad_AppTimezone(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  name: test
+`)
+		assert.Equal(t, "Asia/Shanghai", cfg.App.Timezone)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cfg := loadFromYAML(t, `
+app:
+  timezone: UTC
+`)
+		assert.Equal(t, "UTC", cfg.App.Timezone)
+	})
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	t.Run("env overrides yaml", func(t *testing.T) {
+		t.Setenv("STOCK_DATABASE_PASSWORD", "from-env")
+		t.Setenv("STOCK_NOTIFY_DINGTALK_WEBHOOK", "https://oapi.dingtalk.com/robot/send?access_token=env")
+		t.Setenv("STOCK_NOTIFY_WEWORK_WEBHOOK", "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=env")
+		t.Setenv("STOCK_WORKER_KLINE_CONCURRENCY", "8")
+		cfg := loadFromYAML(t, `
+database:
+  user: reader
+  password: from-file
+notify:
+  dingtalk:
+    enabled: true
+    webhook: https://oapi.dingtalk.com/robot/send?access_token=file
+worker:
+  kline:
+    concurrency: 50
+`)
+		assert.Equal(t, "from-env", cfg.Database.Password)
+		assert.Equal(t, "reader", cfg.Database.User)
+		require.NotNil(t, cfg.Notify.DingTalk)
+		assert.True(t, cfg.Notify.DingTalk.Enabled)
+		assert.Equal(t, "https://oapi.dingtalk.com/robot/send?access_token=env", cfg.Notify.DingTalk.Webhook)
+		require.NotNil(t, cfg.Notify.WeWork)
+		assert.Equal(t, "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=env", cfg.Notify.WeWork.Webhook)
+		assert.Equal(t, 8, cfg.Worker.KLine.Concurrency)
+	})
+
+	t.Run("env overrides defaults", func(t *testing.T) {
+		t.Setenv("STOCK_DATABASE_HOST", "db.internal")
+		cfg := loadFromYAML(t, `
+app:
+  name: test
+`)
+		assert.Equal(t, "db.internal", cfg.Database.Host)
+		assert.Equal(t, 3306, cfg.Database.Port)
+	})
+}
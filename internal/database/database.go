@@ -69,22 +69,7 @@ func NewDatabase(cfg *config.DatabaseConfig, log *logger.Logger) (*Database, err
 func (d *Database) AutoMigrate() error {
 	d.logger.Info("Starting database migration...")
 
-	// 按依赖顺序迁移模型，避免外键约束问题
-	models := []interface{}{
-		&model.Stock{},              // 基础表，无外键依赖
-		&model.DailyData{},          // 依赖Stock
-		&model.WeeklyData{},         // 依赖Stock
-		&model.MonthlyData{},        // 依赖Stock
-		&model.YearlyData{},         // 依赖Stock
-		&model.PerformanceReport{},  // 依赖Stock
-		&model.ShareholderCount{},   // 依赖Stock
-		&model.TechnicalIndicator{}, // 依赖Stock
-		&model.Strategy{},           // 独立表
-		&model.Portfolio{},          // 独立表
-		&model.StrategyResult{},     // 依赖Strategy和Stock
-		&model.PortfolioStock{},     // 依赖Portfolio和Stock
-		&model.BacktestResult{},     // 依赖Strategy
-	}
+	models := Models()
 
 	for _, model := range models {
 		if err := d.DB.AutoMigrate(model); err != nil {
@@ -97,6 +82,41 @@ func (d *Database) AutoMigrate() error {
 	return nil
 }
 
+// Models 需要迁移的模型，按依赖顺序排列，避免外键约束问题
+func Models() []interface{} {
+	return []interface{}{
+		&model.Stock{},               // 基础表，无外键依赖
+		&model.DailyData{},           // 依赖Stock
+		&model.WeeklyData{},          // 依赖Stock
+		&model.MonthlyData{},         // 依赖Stock
+		&model.YearlyData{},          // 依赖Stock
+		&model.PerformanceReport{},   // 依赖Stock
+		&model.ShareholderCount{},    // 依赖Stock
+		&model.StockShares{},         // 依赖Stock
+		&model.TechnicalIndicator{},  // 依赖Stock
+		&model.StockDataQuality{},    // 依赖Stock
+		&model.StockFundFlow{},       // 依赖Stock
+		&model.NorthboundHolding{},   // 依赖Stock
+		&model.Index{},               // 独立表
+		&model.IndexDaily{},          // 依赖Index
+		&model.Strategy{},            // 独立表
+		&model.Portfolio{},           // 独立表
+		&model.StrategyResult{},      // 依赖Strategy和Stock
+		&model.PortfolioStock{},      // 依赖Portfolio和Stock
+		&model.BacktestResult{},      // 依赖Strategy
+		&model.PendingNotification{}, // 独立表
+		&model.JobRun{},              // 独立表
+		&model.StockScore{},          // 依赖Stock
+		&model.Watchlist{},           // 独立表
+		&model.StockIdentityChange{}, // 依赖Stock
+		&model.SelectionResult{},     // 依赖Stock
+		&model.DataExclusion{},       // 依赖Stock
+		&model.RealtimeSnapshot{},    // 依赖Stock
+		&model.StockGroup{},          // 独立表
+		&model.AuditLog{},            // 独立表
+	}
+}
+
 // Close 关闭数据库连接
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
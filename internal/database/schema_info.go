@@ -0,0 +1,160 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// TableInfo 数据表的行数及K线表的交易日期范围
+type TableInfo struct {
+	Name         string `json:"name"`           // 表名
+	Exists       bool   `json:"exists"`         // 表是否存在，分表尚无数据时可能未创建
+	Rows         int64  `json:"rows"`           // 行数
+	KLine        bool   `json:"kline"`          // 是否为K线表
+	MinTradeDate int    `json:"min_trade_date"` // K线表最早交易日期，YYYYMMDD格式，无数据时为0
+	MaxTradeDate int    `json:"max_trade_date"` // K线表最新交易日期，YYYYMMDD格式，无数据时为0
+}
+
+// schemaTable 需要统计的数据表
+type schemaTable struct {
+	name  string
+	kline bool
+}
+
+// tableInspector 数据表统计查询
+type tableInspector interface {
+	HasTable(table string) bool
+	CountRows(table string) (int64, error)
+	TradeDateRange(table string) (int, int, error)
+}
+
+// gormInspector 基于GORM的数据表统计查询
+type gormInspector struct {
+	db *gorm.DB
+}
+
+// HasTable 判断表是否存在
+func (i gormInspector) HasTable(table string) bool {
+	return i.db.Migrator().HasTable(table)
+}
+
+// CountRows 统计表的行数
+func (i gormInspector) CountRows(table string) (int64, error) {
+	var count int64
+	err := i.db.Table(table).Count(&count).Error
+	return count, err
+}
+
+// TradeDateRange 获取表中最早和最新的交易日期
+func (i gormInspector) TradeDateRange(table string) (int, int, error) {
+	var minDate, maxDate int
+	err := i.db.Table(table).
+		Select("COALESCE(MIN(trade_date), 0), COALESCE(MAX(trade_date), 0)").
+		Row().Scan(&minDate, &maxDate)
+	return minDate, maxDate, err
+}
+
+// SchemaInfo 获取系统使用的所有数据表（含全部K线分表）的行数，以及K线表的交易日期范围
+// 用于迁移或导入数据后快速检查数据状态
+func (d *Database) SchemaInfo() ([]TableInfo, error) {
+	tables, err := schemaTables(d.DB)
+	if err != nil {
+		return nil, err
+	}
+	return collectSchemaInfo(gormInspector{db: d.DB}, tables)
+}
+
+// schemaTables 根据迁移模型列出需要统计的数据表
+// 按股票代码分表的K线模型展开为全部分表，按周期分表的技术指标模型展开为各周期的表
+func schemaTables(db *gorm.DB) ([]schemaTable, error) {
+	shardPrefixes := map[string]string{
+		new(model.DailyData).TableName():   model.DailyDataTablePrefix,
+		new(model.WeeklyData).TableName():  model.WeeklyDataTablePrefix,
+		new(model.MonthlyData).TableName(): model.MonthlyDataTablePrefix,
+	}
+
+	var tables []schemaTable
+	for _, m := range Models() {
+		if _, ok := m.(*model.TechnicalIndicator); ok {
+			for _, indicator := range []*model.TechnicalIndicator{
+				model.NewDailyTechnicalIndicator(),
+				model.NewWeeklyTechnicalIndicator(),
+				model.NewMonthlyTechnicalIndicator(),
+				model.NewYearlyTechnicalIndicator(),
+			} {
+				tables = append(tables, schemaTable{name: indicator.TableName()})
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %v", m, err)
+		}
+
+		if prefix, ok := shardPrefixes[stmt.Schema.Table]; ok {
+			for _, name := range model.KLineShardTables(prefix) {
+				tables = append(tables, schemaTable{name: name, kline: true})
+			}
+			continue
+		}
+
+		_, kline := m.(model.TradeDated)
+		tables = append(tables, schemaTable{name: stmt.Schema.Table, kline: kline})
+	}
+	return tables, nil
+}
+
+// collectSchemaInfo 统计各数据表，不存在的表只记录表名
+func collectSchemaInfo(inspector tableInspector, tables []schemaTable) ([]TableInfo, error) {
+	infos := make([]TableInfo, 0, len(tables))
+	for _, table := range tables {
+		info := TableInfo{Name: table.name, KLine: table.kline}
+		if !inspector.HasTable(table.name) {
+			infos = append(infos, info)
+			continue
+		}
+		info.Exists = true
+
+		rows, err := inspector.CountRows(table.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %v", table.name, err)
+		}
+		info.Rows = rows
+
+		if table.kline && rows > 0 {
+			info.MinTradeDate, info.MaxTradeDate, err = inspector.TradeDateRange(table.name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get trade date range of %s: %v", table.name, err)
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// FormatSchemaInfo 将数据表统计格式化为文本表格
+func FormatSchemaInfo(infos []TableInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-32s %12s %14s %14s\n", "TABLE", "ROWS", "MIN_TRADE_DATE", "MAX_TRADE_DATE")
+
+	var total int64
+	for _, info := range infos {
+		rows, minDate, maxDate := "missing", "-", "-"
+		if info.Exists {
+			rows = fmt.Sprintf("%d", info.Rows)
+			total += info.Rows
+		}
+		if info.KLine && info.Rows > 0 {
+			minDate, maxDate = fmt.Sprintf("%d", info.MinTradeDate), fmt.Sprintf("%d", info.MaxTradeDate)
+		}
+		fmt.Fprintf(&b, "%-32s %12s %14s %14s\n", info.Name, rows, minDate, maxDate)
+	}
+
+	fmt.Fprintf(&b, "%-32s %12d\n", "TOTAL", total)
+	return b.String()
+}
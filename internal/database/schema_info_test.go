@@ -0,0 +1,102 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// fakeTable 模拟的数据表内容
+type fakeTable struct {
+	rows             int64
+	minDate, maxDate int
+}
+
+// fakeInspector 模拟数据库，只有tables中的表存在
+type fakeInspector struct {
+	tables map[string]fakeTable
+}
+
+func (f fakeInspector) HasTable(table string) bool {
+	_, ok := f.tables[table]
+	return ok
+}
+
+func (f fakeInspector) CountRows(table string) (int64, error) {
+	return f.tables[table].rows, nil
+}
+
+func (f fakeInspector) TradeDateRange(table string) (int, int, error) {
+	t := f.tables[table]
+	if t.rows == 0 {
+		return 0, 0, errors.New("trade date range queried on empty table")
+	}
+	return t.minDate, t.maxDate, nil
+}
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestSchemaTables_ExpandsShards(t *testing.T) {
+	tables, err := schemaTables(newDryRunDB(t))
+	require.NoError(t, err)
+
+	byName := make(map[string]schemaTable)
+	for _, table := range tables {
+		_, duplicated := byName[table.name]
+		assert.False(t, duplicated, "duplicated table %s", table.name)
+		byName[table.name] = table
+	}
+
+	for _, prefix := range []string{model.DailyDataTablePrefix, model.WeeklyDataTablePrefix, model.MonthlyDataTablePrefix} {
+		for _, name := range model.KLineShardTables(prefix) {
+			require.Contains(t, byName, name)
+			assert.True(t, byName[name].kline, name)
+		}
+	}
+	assert.True(t, byName["yearly_data"].kline)
+	assert.True(t, byName["index_daily"].kline)
+	assert.False(t, byName["stocks"].kline)
+	assert.Contains(t, byName, "weekly_technical_indicators")
+	assert.Len(t, model.KLineShardTables(model.DailyDataTablePrefix), len(model.KLineShardPrefixes)+1)
+}
+
+func TestCollectSchemaInfo(t *testing.T) {
+	inspector := fakeInspector{tables: map[string]fakeTable{
+		"stocks":         {rows: 5321},
+		"daily_data_600": {rows: 1200, minDate: 20200102, maxDate: 20250630},
+		"daily_data_000": {rows: 0},
+	}}
+	tables := []schemaTable{
+		{name: "stocks"},
+		{name: "daily_data_000", kline: true},
+		{name: "daily_data_600", kline: true},
+		{name: "daily_data_688", kline: true},
+	}
+
+	infos, err := collectSchemaInfo(inspector, tables)
+	require.NoError(t, err)
+	require.Len(t, infos, 4)
+
+	assert.Equal(t, TableInfo{Name: "stocks", Exists: true, Rows: 5321}, infos[0])
+	assert.Equal(t, TableInfo{Name: "daily_data_000", Exists: true, KLine: true}, infos[1])
+	assert.Equal(t, TableInfo{Name: "daily_data_600", Exists: true, Rows: 1200, KLine: true, MinTradeDate: 20200102, MaxTradeDate: 20250630}, infos[2])
+	assert.Equal(t, TableInfo{Name: "daily_data_688", KLine: true}, infos[3])
+
+	output := FormatSchemaInfo(infos)
+	assert.Contains(t, output, "20250630")
+	assert.Contains(t, output, "missing")
+	assert.Contains(t, output, "6521")
+}
@@ -0,0 +1,94 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+)
+
+// ReturnCorrelation 两只股票日收益率的相关性
+type ReturnCorrelation struct {
+	Coefficient float64 `json:"coefficient"`  // 皮尔逊相关系数，-1到1之间
+	OverlapDays int     `json:"overlap_days"` // 参与计算的共同交易日数，日收益率个数为OverlapDays-1
+	StartDate   int     `json:"start_date"`   // 首个共同交易日，YYYYMMDD格式
+	EndDate     int     `json:"end_date"`     // 最后一个共同交易日，YYYYMMDD格式
+}
+
+// CorrelateReturns 计算两只股票最近window个日收益率的皮尔逊相关系数
+// 输入数据无需排序，只使用两只股票都有数据的交易日（任一只停牌的日期被跳过，收益率按相邻共同交易日计算），
+// 共同历史不足window个收益率时使用全部共同历史；少于2个收益率、收盘价无效或任一只收益率没有波动时返回false
+func CorrelateReturns[A IndStock, B IndStock](a []A, b []B, window int) (ReturnCorrelation, bool) {
+	closesB := make(map[int]float64, len(b))
+	for _, bar := range b {
+		_, _, _, c := bar.Get4Price()
+		closesB[bar.GetTradeDate()] = c
+	}
+
+	aligned := make([]alignedClose, 0, len(a))
+	for _, bar := range a {
+		cb, ok := closesB[bar.GetTradeDate()]
+		if !ok {
+			continue
+		}
+		_, _, _, ca := bar.Get4Price()
+		aligned = append(aligned, alignedClose{tradeDate: bar.GetTradeDate(), stockClose: ca, benchmarkClose: cb})
+	}
+	sort.Slice(aligned, func(i, j int) bool { return aligned[i].tradeDate < aligned[j].tradeDate })
+
+	if window > 0 && len(aligned) > window+1 {
+		aligned = aligned[len(aligned)-window-1:]
+	}
+	if len(aligned) < 3 {
+		return ReturnCorrelation{}, false
+	}
+
+	returnsA := make([]float64, 0, len(aligned)-1)
+	returnsB := make([]float64, 0, len(aligned)-1)
+	for i := 1; i < len(aligned); i++ {
+		ra, ok := PeriodReturn(aligned[i-1].stockClose, aligned[i].stockClose)
+		if !ok {
+			return ReturnCorrelation{}, false
+		}
+		rb, ok := PeriodReturn(aligned[i-1].benchmarkClose, aligned[i].benchmarkClose)
+		if !ok {
+			return ReturnCorrelation{}, false
+		}
+		returnsA = append(returnsA, ra)
+		returnsB = append(returnsB, rb)
+	}
+
+	coefficient, ok := pearson(returnsA, returnsB)
+	if !ok {
+		return ReturnCorrelation{}, false
+	}
+	return ReturnCorrelation{
+		Coefficient: coefficient,
+		OverlapDays: len(aligned),
+		StartDate:   aligned[0].tradeDate,
+		EndDate:     aligned[len(aligned)-1].tradeDate,
+	}, true
+}
+
+// pearson 计算两组等长数据的皮尔逊相关系数，任一组没有波动时返回false
+func pearson(x, y []float64) (float64, bool) {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+
+	// 浮点误差可能使结果略超出[-1, 1]
+	return math.Max(-1, math.Min(1, cov/math.Sqrt(varX*varY))), true
+}
@@ -0,0 +1,84 @@
+package indicator
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dailyBars 按交易日期和收盘价构造日K线
+func dailyBars(tsCode string, dates []int, closes []float64) []model.DailyData {
+	bars := make([]model.DailyData, len(dates))
+	for i := range dates {
+		bars[i] = model.DailyData{TsCode: tsCode, TradeDate: dates[i], Close: closes[i]}
+	}
+	return bars
+}
+
+func TestCorrelateReturns_PerfectlyCorrelated(t *testing.T) {
+	dates := []int{20240102, 20240103, 20240104, 20240105, 20240108, 20240109}
+	a := dailyBars("000001.SZ", dates, []float64{10, 11, 10.5, 12, 11.8, 12.5})
+	// b的每日收益率与a相同（价格为a的3倍），输入乱序
+	b := dailyBars("600036.SH", dates, []float64{30, 33, 31.5, 36, 35.4, 37.5})
+	b[0], b[5] = b[5], b[0]
+
+	result, ok := CorrelateReturns(a, b, 120)
+	require.True(t, ok)
+	assert.InDelta(t, 1.0, result.Coefficient, 1e-9)
+	assert.Equal(t, 6, result.OverlapDays)
+	assert.Equal(t, 20240102, result.StartDate)
+	assert.Equal(t, 20240109, result.EndDate)
+}
+
+func TestCorrelateReturns_AntiCorrelated(t *testing.T) {
+	dates := []int{20240102, 20240103, 20240104, 20240105, 20240108}
+	// a每日涨跌+10%、-10%交替，b方向相反
+	a := dailyBars("000001.SZ", dates, []float64{100, 110, 99, 108.9, 98.01})
+	b := dailyBars("600036.SH", dates, []float64{100, 90, 99, 89.1, 98.01})
+
+	result, ok := CorrelateReturns(a, b, 120)
+	require.True(t, ok)
+	assert.InDelta(t, -1.0, result.Coefficient, 1e-9)
+	assert.Equal(t, 5, result.OverlapDays)
+}
+
+func TestCorrelateReturns_MismatchedHistories(t *testing.T) {
+	// a上市较晚且20240105停牌，b在20240110之后没有数据，只使用共同交易日
+	a := dailyBars("301000.SZ", []int{20240103, 20240104, 20240108, 20240109, 20240110, 20240111},
+		[]float64{20, 22, 21, 23, 24, 30})
+	b := dailyBars("600036.SH", []int{20240102, 20240103, 20240104, 20240105, 20240108, 20240109, 20240110},
+		[]float64{9, 10, 11, 11.2, 10.5, 11.5, 12})
+
+	result, ok := CorrelateReturns(a, b, 120)
+	require.True(t, ok)
+	assert.Equal(t, 5, result.OverlapDays)
+	assert.Equal(t, 20240103, result.StartDate)
+	assert.Equal(t, 20240110, result.EndDate)
+	assert.InDelta(t, 1.0, result.Coefficient, 1e-9, "common-date returns move in lockstep")
+
+	// 窗口只取最近的收益率
+	result, ok = CorrelateReturns(a, b, 2)
+	require.True(t, ok)
+	assert.Equal(t, 3, result.OverlapDays)
+	assert.Equal(t, 20240108, result.StartDate)
+}
+
+func TestCorrelateReturns_Insufficient(t *testing.T) {
+	dates := []int{20240102, 20240103, 20240104}
+	a := dailyBars("000001.SZ", dates, []float64{10, 11, 12})
+
+	// 共同交易日不足
+	_, ok := CorrelateReturns(a, dailyBars("600036.SH", []int{20240103, 20240104}, []float64{10, 11}), 120)
+	assert.False(t, ok)
+
+	// 收益率没有波动时相关系数无定义
+	_, ok = CorrelateReturns(a, dailyBars("600036.SH", dates, []float64{10, 10, 10}), 120)
+	assert.False(t, ok)
+
+	// 收盘价无效
+	_, ok = CorrelateReturns(a, dailyBars("600036.SH", dates, []float64{0, 10, 11}), 120)
+	assert.False(t, ok)
+}
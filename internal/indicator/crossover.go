@@ -0,0 +1,61 @@
+package indicator
+
+import "sort"
+
+// CrossoverType 均线交叉类型
+type CrossoverType string
+
+const (
+	GoldenCross CrossoverType = "golden" // 金叉：快线上穿慢线
+	DeathCross  CrossoverType = "death"  // 死叉：快线下穿慢线
+)
+
+// Crossover 一次均线交叉
+type Crossover struct {
+	TradeDate int           `json:"trade_date"` // 交叉发生的交易日期，YYYYMMDD格式
+	Type      CrossoverType `json:"type"`       // 交叉类型
+	Close     float64       `json:"close"`      // 当日收盘价
+	FastMA    float64       `json:"fast_ma"`    // 当日快线均值
+	SlowMA    float64       `json:"slow_ma"`    // 当日慢线均值
+}
+
+// MACrossovers 计算收盘价快慢均线的交叉记录，按交易日期升序返回
+// 输入数据无需排序；均线只在数据满一个完整周期后参与判断，快线由<=慢线变为>慢线为金叉，由>=慢线变为<慢线为死叉。
+// fast、slow需大于0且fast<slow，否则返回nil
+func MACrossovers[S IndStock](data []S, fast, slow int) []Crossover {
+	if fast <= 0 || slow <= fast {
+		return nil
+	}
+
+	sorted := make([]S, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetTradeDate() < sorted[j].GetTradeDate() })
+
+	closes := make([]float64, len(sorted))
+	for i, s := range sorted {
+		_, _, _, closes[i] = s.Get4Price()
+	}
+
+	fastMA, slowMA := calculateMa(closes, fast), calculateMa(closes, slow)
+	result := make([]Crossover, 0)
+	for i := slow; i < len(sorted); i++ {
+		var crossType CrossoverType
+		switch {
+		case fastMA[i-1] <= slowMA[i-1] && fastMA[i] > slowMA[i]:
+			crossType = GoldenCross
+		case fastMA[i-1] >= slowMA[i-1] && fastMA[i] < slowMA[i]:
+			crossType = DeathCross
+		default:
+			continue
+		}
+
+		result = append(result, Crossover{
+			TradeDate: sorted[i].GetTradeDate(),
+			Type:      crossType,
+			Close:     closes[i],
+			FastMA:    fastMA[i],
+			SlowMA:    slowMA[i],
+		})
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package indicator
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMACrossovers(t *testing.T) {
+	// 横盘后拉升出现金叉，随后急跌出现死叉；输入乱序
+	closes := []float64{10, 10, 10, 10, 12, 14, 8, 6}
+	data := make([]model.DailyData, 0, len(closes))
+	for i := len(closes) - 1; i >= 0; i-- {
+		data = append(data, model.DailyData{TsCode: "600519.SH", TradeDate: 20240101 + i, Close: closes[i]})
+	}
+
+	crossovers := MACrossovers(data, 2, 3)
+	require.Len(t, crossovers, 2)
+
+	assert.Equal(t, 20240105, crossovers[0].TradeDate)
+	assert.Equal(t, GoldenCross, crossovers[0].Type)
+	assert.Equal(t, 12.0, crossovers[0].Close)
+	assert.InDelta(t, 11.0, crossovers[0].FastMA, 1e-9)
+	assert.InDelta(t, 32.0/3, crossovers[0].SlowMA, 1e-9)
+
+	assert.Equal(t, 20240107, crossovers[1].TradeDate)
+	assert.Equal(t, DeathCross, crossovers[1].Type)
+	assert.Equal(t, 8.0, crossovers[1].Close)
+	assert.InDelta(t, 11.0, crossovers[1].FastMA, 1e-9)
+	assert.InDelta(t, 34.0/3, crossovers[1].SlowMA, 1e-9)
+}
+
+func TestMACrossovers_InvalidPeriods(t *testing.T) {
+	data := []model.DailyData{{TradeDate: 20240101, Close: 10}, {TradeDate: 20240102, Close: 11}}
+	assert.Nil(t, MACrossovers(data, 0, 3))
+	assert.Nil(t, MACrossovers(data, 5, 5))
+	assert.Nil(t, MACrossovers(data, 20, 5))
+	// 数据不足一个慢线周期时没有交叉
+	assert.Empty(t, MACrossovers(data, 2, 3))
+}
@@ -75,7 +75,7 @@ type DynamicInfo struct {
 
 // CalculateSupportResistance 计算支撑阻力趋势指标
 func CalculateSupportResistance(data []model.DailyData, dynaInfo *DynamicInfo) *SupportResistanceResult {
-	if len(data) < 55 { // 需要至少55个数据点
+	if len(data) < MinSupportResistanceBars { // 需要至少55个数据点
 		return nil
 	}
 
@@ -0,0 +1,53 @@
+package indicator
+
+// GapDirection 跳空方向
+type GapDirection string
+
+// 跳空方向，向上跳空为当日最低价高于前一日最高价，向下跳空为当日最高价低于前一日最低价
+const (
+	GapNone GapDirection = ""     // 未跳空
+	GapUp   GapDirection = "up"   // 向上跳空
+	GapDown GapDirection = "down" // 向下跳空
+)
+
+// Streaks 计算每根K线处的连涨连跌天数：连续收涨n天为n，连续收跌n天为-n，收平或首根K线为0
+// 收盘价与前一日相同时连涨连跌中断
+func Streaks(closes []float64) []int {
+	result := make([]int, len(closes))
+	for i := 1; i < len(closes); i++ {
+		switch {
+		case closes[i] > closes[i-1]:
+			result[i] = max(result[i-1], 0) + 1
+		case closes[i] < closes[i-1]:
+			result[i] = min(result[i-1], 0) - 1
+		}
+	}
+	return result
+}
+
+// CurrentStreak 计算截至最后一根K线的连涨连跌天数，正数为连涨、负数为连跌，K线不足2根时为0
+func CurrentStreak(closes []float64) int {
+	if len(closes) < 2 {
+		return 0
+	}
+	streaks := Streaks(closes)
+	return streaks[len(streaks)-1]
+}
+
+// Gaps 检测每根K线相对前一日的跳空方向，首根K线为GapNone；输入长度不一致时返回nil
+func Gaps(highs, lows []float64) []GapDirection {
+	if len(highs) != len(lows) {
+		return nil
+	}
+
+	result := make([]GapDirection, len(highs))
+	for i := 1; i < len(highs); i++ {
+		switch {
+		case lows[i] > highs[i-1]:
+			result[i] = GapUp
+		case highs[i] < lows[i-1]:
+			result[i] = GapDown
+		}
+	}
+	return result
+}
@@ -0,0 +1,29 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreaks(t *testing.T) {
+	// 涨涨涨、平、跌跌、涨
+	closes := []float64{10, 10.5, 10.8, 11, 11, 10.6, 10.2, 10.4}
+	assert.Equal(t, []int{0, 1, 2, 3, 0, -1, -2, 1}, Streaks(closes))
+	assert.Equal(t, 1, CurrentStreak(closes))
+
+	assert.Equal(t, -2, CurrentStreak([]float64{11, 10.5, 10}))
+	assert.Equal(t, 0, CurrentStreak([]float64{10, 10}))
+	assert.Equal(t, 0, CurrentStreak([]float64{10}))
+	assert.Empty(t, Streaks(nil))
+}
+
+func TestGaps(t *testing.T) {
+	highs := []float64{10.5, 11.2, 11.5, 10.6, 10.8, 10.9}
+	lows := []float64{10.0, 10.6, 11.0, 10.2, 10.4, 10.6}
+	// 第2根最低价10.6高于前一日最高价10.5，向上跳空；第4根最高价10.6低于前一日最低价11.0，向下跳空；
+	// 第3、5、6根与前一日的价格区间重叠，不算跳空
+	assert.Equal(t, []GapDirection{GapNone, GapUp, GapNone, GapDown, GapNone, GapNone}, Gaps(highs, lows))
+
+	assert.Nil(t, Gaps([]float64{1, 2}, []float64{1}))
+}
@@ -29,25 +29,28 @@ type ComplexIndicatorResult struct {
 	RiskCoefficient []float64 `json:"risk_coefficient"`
 
 	// 信号点
-	Signals struct {
-		ExtremeBottom  []int `json:"extreme_bottom"`  // 极底 √
-		Rise           []int `json:"rise"`            // 升 √
-		Top            []int `json:"top"`             // 顶 ×
-		Down           []int `json:"down"`            // 下 ×
-		BuildPosition  []int `json:"build_position"`  // 建仓 ？
-		Escape         []int `json:"escape"`          // 逃 ？
-		Bottom         []int `json:"bottom"`          // 见底 √
-		AbsoluteBottom []int `json:"absolute_bottom"` // 绝底 √
-		SeeRise        []int `json:"see_rise"`        // 见涨 √
-		MustRise       []int `json:"must_rise"`       // 必涨 ×
-		BottomFishing  []int `json:"bottom_fishing"`  // 抄底 缺失
-		GoldenCross    []int `json:"golden_cross"`    // 金叉 √
-	} `json:"signals"`
+	Signals ComplexSignals `json:"signals"`
 }
 
-// CalculateComplexIndicator 计算复杂指标
+// ComplexSignals 复杂指标各信号出现的交易日期
+type ComplexSignals struct {
+	ExtremeBottom  []int `json:"extreme_bottom"`  // 极底 √
+	Rise           []int `json:"rise"`            // 升 √
+	Top            []int `json:"top"`             // 顶 ×
+	Down           []int `json:"down"`            // 下 ×
+	BuildPosition  []int `json:"build_position"`  // 建仓 ？
+	Escape         []int `json:"escape"`          // 逃 ？
+	Bottom         []int `json:"bottom"`          // 见底 √
+	AbsoluteBottom []int `json:"absolute_bottom"` // 绝底 √
+	SeeRise        []int `json:"see_rise"`        // 见涨 √
+	MustRise       []int `json:"must_rise"`       // 必涨 ×
+	BottomFishing  []int `json:"bottom_fishing"`  // 抄底 缺失
+	GoldenCross    []int `json:"golden_cross"`    // 金叉 √
+}
+
+// CalculateComplexIndicator 计算复杂指标，data需按交易日期升序排列，数据不足MinComplexIndicatorBars时返回nil
 func CalculateComplexIndicator(data []model.DailyData) *ComplexIndicatorResult {
-	if len(data) < 38 { // 需要足够的数据计算各种指标
+	if len(data) < MinComplexIndicatorBars { // 需要足够的数据计算各种指标
 		return nil
 	}
 
@@ -187,17 +190,15 @@ func calculateRSIAndADX(result *ComplexIndicatorResult, highs, lows, closes []fl
 
 	// ADX计算 (简化版)
 	result.ADX = make([]float64, n)
+	trueRange := TrueRange(highs, lows, closes)
 	for i := range result.ADX {
 		if i < 14 {
 			result.ADX[i] = 0
 		} else {
-			// 简化的ADX计算
+			// 简化的ADX计算：最近14个真实波幅的平均值
 			tr := 0.0
 			for j := i - 13; j <= i; j++ {
-				tr1 := highs[j] - lows[j]
-				tr2 := math.Abs(highs[j] - closes[j-1])
-				tr3 := math.Abs(lows[j] - closes[j-1])
-				tr += math.Max(tr1, math.Max(tr2, tr3))
+				tr += trueRange[j]
 			}
 			result.ADX[i] = tr / 14
 		}
@@ -203,7 +203,7 @@ func ABS(data []float64) []float64 {
 
 // RedThree 计算主要指标
 func RedThree(stocks []model.DailyData) *IndicatorResult {
-	if len(stocks) < 33 { // 需要至少33个数据点来计算VAR4
+	if len(stocks) < MinRedThreeBars { // 需要至少33个数据点来计算VAR4
 		return nil
 	}
 
@@ -0,0 +1,60 @@
+package indicator
+
+import "fmt"
+
+// 各指标计算所需的最少K线数量，数据不足时对应的计算函数返回nil
+const (
+	MinRedThreeBars          = 33 // RedThree 计算VAR4需要33根
+	MinComplexIndicatorBars  = 38 // CalculateComplexIndicator 计算各种均线和信号需要38根
+	MinSupportResistanceBars = 55 // CalculateSupportResistance 计算55周期价格范围需要55根
+	MinTimeControlBars       = 58 // CalculateTimeControlIndicator 计算MA(58)需要58根
+	MinTechnicalScoreBars    = 60 // TechnicalScore 计算MA60需要60根
+)
+
+// 指标名称，用于查询最少K线数量和提示数据不足
+const (
+	IndicatorRedThree          = "red_three"
+	IndicatorComplex           = "complex"
+	IndicatorSupportResistance = "support_resistance"
+	IndicatorTimeControl       = "time_control"
+	IndicatorTechnicalScore    = "technical_score"
+)
+
+// minBars 指标名称到最少K线数量的映射
+var minBars = map[string]int{
+	IndicatorRedThree:          MinRedThreeBars,
+	IndicatorComplex:           MinComplexIndicatorBars,
+	IndicatorSupportResistance: MinSupportResistanceBars,
+	IndicatorTimeControl:       MinTimeControlBars,
+	IndicatorTechnicalScore:    MinTechnicalScoreBars,
+}
+
+// InsufficientHistoryError K线数量不足以计算指标
+type InsufficientHistoryError struct {
+	Indicator string // 指标名称
+	Need      int    // 所需的最少K线数量
+	Have      int    // 实际的K线数量
+}
+
+// Error 实现error接口
+func (e *InsufficientHistoryError) Error() string {
+	return fmt.Sprintf("insufficient history: need %d bars, have %d", e.Need, e.Have)
+}
+
+// MinBars 获取指标计算所需的最少K线数量，未知指标返回false
+func MinBars(indicator string) (int, bool) {
+	n, ok := minBars[indicator]
+	return n, ok
+}
+
+// CheckHistory 检查K线数量是否满足指标计算要求，不足时返回*InsufficientHistoryError
+func CheckHistory(indicator string, have int) error {
+	need, ok := minBars[indicator]
+	if !ok {
+		return fmt.Errorf("unknown indicator: %s", indicator)
+	}
+	if have < need {
+		return &InsufficientHistoryError{Indicator: indicator, Need: need, Have: have}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package indicator
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHistory(t *testing.T) {
+	assert.NoError(t, CheckHistory(IndicatorSupportResistance, MinSupportResistanceBars))
+
+	err := CheckHistory(IndicatorSupportResistance, 20)
+	require.Error(t, err)
+	assert.EqualError(t, err, "insufficient history: need 55 bars, have 20")
+
+	var historyErr *InsufficientHistoryError
+	require.ErrorAs(t, err, &historyErr)
+	assert.Equal(t, IndicatorSupportResistance, historyErr.Indicator)
+
+	err = CheckHistory("unknown", 100)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, historyErr)
+	assert.Contains(t, err.Error(), "unknown indicator")
+}
+
+// 计算函数恰好在最少K线数量处开始返回结果
+func TestMinBars_MatchCalculators(t *testing.T) {
+	bars := func(n int) []model.DailyData {
+		data := make([]model.DailyData, n)
+		for i := range data {
+			price := 10 + float64(i%7)
+			data[i] = model.DailyData{TradeDate: 20240101 + i, Open: price, High: price + 1, Low: price - 1, Close: price, Volume: 1000}
+		}
+		return data
+	}
+	dynaInfo := &DynamicInfo{Open: 10, High: 11, Low: 9, Close: 10}
+
+	cases := []struct {
+		indicator string
+		calculate func([]model.DailyData) bool
+	}{
+		{IndicatorRedThree, func(d []model.DailyData) bool { return RedThree(d) != nil }},
+		{IndicatorComplex, func(d []model.DailyData) bool { return CalculateComplexIndicator(d) != nil }},
+		{IndicatorSupportResistance, func(d []model.DailyData) bool { return CalculateSupportResistance(d, dynaInfo) != nil }},
+		{IndicatorTimeControl, func(d []model.DailyData) bool { return CalculateTimeControlIndicator(d) != nil }},
+		{IndicatorTechnicalScore, func(d []model.DailyData) bool { _, ok := TechnicalScore(d); return ok }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.indicator, func(t *testing.T) {
+			need, ok := MinBars(tc.indicator)
+			require.True(t, ok)
+			assert.False(t, tc.calculate(bars(need-1)))
+			assert.True(t, tc.calculate(bars(need)))
+		})
+	}
+}
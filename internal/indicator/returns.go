@@ -0,0 +1,80 @@
+package indicator
+
+import "sort"
+
+// DefaultReturnWindows 默认的收益率统计窗口（交易日）：周、月、季、半年、年
+var DefaultReturnWindows = []int{5, 20, 60, 120, 250}
+
+// RelativeReturn 相对基准的区间收益
+type RelativeReturn struct {
+	Window          int     `json:"window"`           // 统计窗口，单位：交易日
+	StartDate       int     `json:"start_date"`       // 区间起点交易日期，YYYYMMDD格式
+	EndDate         int     `json:"end_date"`         // 区间终点交易日期，YYYYMMDD格式
+	StockReturn     float64 `json:"stock_return"`     // 个股区间收益率，单位：%
+	BenchmarkReturn float64 `json:"benchmark_return"` // 基准区间收益率，单位：%
+	ExcessReturn    float64 `json:"excess_return"`    // 超额收益（个股收益率-基准收益率），单位：%
+}
+
+// alignedClose 个股与基准在同一交易日的收盘价
+type alignedClose struct {
+	tradeDate      int
+	stockClose     float64
+	benchmarkClose float64
+}
+
+// PeriodReturn 计算区间收益率（%），起点价格无效时返回false
+func PeriodReturn(startClose, endClose float64) (float64, bool) {
+	if startClose <= 0 {
+		return 0, false
+	}
+	return (endClose/startClose - 1) * 100, true
+}
+
+// RelativeReturns 计算个股在各窗口内相对基准的超额收益
+// 输入数据无需排序，只使用个股和基准都有数据的交易日（个股停牌日被跳过），
+// 窗口N表示最近一个共同交易日与往前第N个共同交易日之间的收益；数据不足的窗口不返回
+func RelativeReturns[S IndStock, B IndStock](stock []S, benchmark []B, windows []int) []RelativeReturn {
+	benchmarkCloses := make(map[int]float64, len(benchmark))
+	for _, b := range benchmark {
+		_, _, _, c := b.Get4Price()
+		benchmarkCloses[b.GetTradeDate()] = c
+	}
+
+	aligned := make([]alignedClose, 0, len(stock))
+	for _, s := range stock {
+		bc, ok := benchmarkCloses[s.GetTradeDate()]
+		if !ok {
+			continue
+		}
+		_, _, _, sc := s.Get4Price()
+		aligned = append(aligned, alignedClose{tradeDate: s.GetTradeDate(), stockClose: sc, benchmarkClose: bc})
+	}
+	sort.Slice(aligned, func(i, j int) bool { return aligned[i].tradeDate < aligned[j].tradeDate })
+
+	result := make([]RelativeReturn, 0, len(windows))
+	for _, window := range windows {
+		if window <= 0 || len(aligned) <= window {
+			continue
+		}
+
+		start, end := aligned[len(aligned)-1-window], aligned[len(aligned)-1]
+		stockReturn, ok := PeriodReturn(start.stockClose, end.stockClose)
+		if !ok {
+			continue
+		}
+		benchmarkReturn, ok := PeriodReturn(start.benchmarkClose, end.benchmarkClose)
+		if !ok {
+			continue
+		}
+
+		result = append(result, RelativeReturn{
+			Window:          window,
+			StartDate:       start.tradeDate,
+			EndDate:         end.tradeDate,
+			StockReturn:     stockReturn,
+			BenchmarkReturn: benchmarkReturn,
+			ExcessReturn:    stockReturn - benchmarkReturn,
+		})
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package indicator
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodReturn(t *testing.T) {
+	r, ok := PeriodReturn(10, 12)
+	assert.True(t, ok)
+	assert.InDelta(t, 20.0, r, 1e-9)
+
+	r, ok = PeriodReturn(10, 9)
+	assert.True(t, ok)
+	assert.InDelta(t, -10.0, r, 1e-9)
+
+	_, ok = PeriodReturn(0, 9)
+	assert.False(t, ok)
+}
+
+func TestRelativeReturns(t *testing.T) {
+	// 个股乱序输入，20240104停牌无数据；基准多出一个非共同交易日
+	stock := []model.DailyData{
+		{TsCode: "600519.SH", TradeDate: 20240108, Close: 13.2},
+		{TsCode: "600519.SH", TradeDate: 20240102, Close: 10},
+		{TsCode: "600519.SH", TradeDate: 20240103, Close: 11},
+		{TsCode: "600519.SH", TradeDate: 20240105, Close: 12},
+	}
+	benchmark := []model.IndexDaily{
+		{TsCode: "000300.SH", TradeDate: 20240102, Close: 4000},
+		{TsCode: "000300.SH", TradeDate: 20240103, Close: 4040},
+		{TsCode: "000300.SH", TradeDate: 20240104, Close: 3900},
+		{TsCode: "000300.SH", TradeDate: 20240105, Close: 4100},
+		{TsCode: "000300.SH", TradeDate: 20240108, Close: 4200},
+	}
+
+	result := RelativeReturns(stock, benchmark, []int{1, 3, 5})
+	require.Len(t, result, 2, "window 5 exceeds the aligned history and is skipped")
+
+	// 1日窗口：个股 12->13.2 = +10%，基准 4100->4200 ≈ +2.439%
+	assert.Equal(t, 1, result[0].Window)
+	assert.Equal(t, 20240105, result[0].StartDate)
+	assert.Equal(t, 20240108, result[0].EndDate)
+	assert.InDelta(t, 10.0, result[0].StockReturn, 1e-9)
+	assert.InDelta(t, 100.0/41, result[0].BenchmarkReturn, 1e-9)
+	assert.InDelta(t, 10.0-100.0/41, result[0].ExcessReturn, 1e-9)
+
+	// 3日窗口跳过停牌日：个股 10->13.2 = +32%，基准 4000->4200 = +5%，超额 +27%
+	assert.Equal(t, 3, result[1].Window)
+	assert.Equal(t, 20240102, result[1].StartDate)
+	assert.InDelta(t, 32.0, result[1].StockReturn, 1e-9)
+	assert.InDelta(t, 5.0, result[1].BenchmarkReturn, 1e-9)
+	assert.InDelta(t, 27.0, result[1].ExcessReturn, 1e-9)
+}
+
+func TestRelativeReturns_Underperform(t *testing.T) {
+	stock := []model.DailyData{
+		{TradeDate: 20240102, Close: 20},
+		{TradeDate: 20240103, Close: 19},
+	}
+	benchmark := []model.IndexDaily{
+		{TradeDate: 20240102, Close: 1000},
+		{TradeDate: 20240103, Close: 1010},
+	}
+
+	result := RelativeReturns(stock, benchmark, []int{1})
+	require.Len(t, result, 1)
+	assert.InDelta(t, -5.0, result[0].StockReturn, 1e-9)
+	assert.InDelta(t, 1.0, result[0].BenchmarkReturn, 1e-9)
+	assert.InDelta(t, -6.0, result[0].ExcessReturn, 1e-9)
+}
+
+func TestRelativeReturns_NoOverlap(t *testing.T) {
+	stock := []model.DailyData{{TradeDate: 20240102, Close: 10}, {TradeDate: 20240103, Close: 11}}
+	benchmark := []model.IndexDaily{{TradeDate: 20240104, Close: 4000}}
+
+	assert.Empty(t, RelativeReturns(stock, benchmark, DefaultReturnWindows))
+}
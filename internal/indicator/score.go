@@ -0,0 +1,193 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"stock/internal/model"
+)
+
+// ScoreWeights 综合评分中各维度的权重，权重按比例生效，无需加总为1
+type ScoreWeights struct {
+	Technical   float64 `json:"technical" mapstructure:"technical"`     // 技术面权重
+	Fundamental float64 `json:"fundamental" mapstructure:"fundamental"` // 基本面权重
+	Shareholder float64 `json:"shareholder" mapstructure:"shareholder"` // 筹码集中度权重
+}
+
+// DefaultScoreWeights 默认评分权重：技术面40%、基本面40%、筹码集中度20%
+var DefaultScoreWeights = ScoreWeights{Technical: 0.4, Fundamental: 0.4, Shareholder: 0.2}
+
+// ScoreComponents 各维度评分，取值0-100，为nil表示数据不足无法评分
+type ScoreComponents struct {
+	Technical   *float64 `json:"technical"`
+	Fundamental *float64 `json:"fundamental"`
+	Shareholder *float64 `json:"shareholder"`
+}
+
+// techScoreSignalLookback 技术面评分统计看多信号的最近交易日数
+const techScoreSignalLookback = 5
+
+// CompositeScore 按权重合成综合评分
+// 数据不足的维度不参与计算，其余维度按权重重新归一化；所有维度都缺失或有效权重为0时返回false
+func CompositeScore(components ScoreComponents, weights ScoreWeights) (float64, bool) {
+	total, weightSum := 0.0, 0.0
+	for _, part := range []struct {
+		score  *float64
+		weight float64
+	}{
+		{components.Technical, weights.Technical},
+		{components.Fundamental, weights.Fundamental},
+		{components.Shareholder, weights.Shareholder},
+	} {
+		if part.score == nil || part.weight <= 0 {
+			continue
+		}
+		total += *part.score * part.weight
+		weightSum += part.weight
+	}
+
+	if weightSum == 0 {
+		return 0, false
+	}
+	return roundScore(total / weightSum), true
+}
+
+// TechnicalScore 技术面评分，data需按交易日期升序排列
+// 收盘价站上MA20、站上MA60、MACD多头（DIF>DEA）、最近5个交易日出现看多信号（金叉、见底、绝底、极底、见涨、向上跳空）各占25分
+func TechnicalScore(data []model.DailyData) (float64, bool) {
+	n := len(data)
+	if n < MinTechnicalScoreBars {
+		return 0, false
+	}
+
+	closes := make([]float64, n)
+	for i, d := range data {
+		closes[i] = d.Close
+	}
+
+	score := 0.0
+	last := closes[n-1]
+	if ma20 := MA(closes, 20); last > ma20[n-1] {
+		score += 25
+	}
+	if ma60 := MA(closes, 60); last > ma60[n-1] {
+		score += 25
+	}
+
+	dif := make([]float64, n)
+	ema12, ema26 := EMA(closes, 12), EMA(closes, 26)
+	for i := range dif {
+		dif[i] = ema12[i] - ema26[i]
+	}
+	if dea := EMA(dif, 9); dif[n-1] > dea[n-1] {
+		score += 25
+	}
+
+	if bullishSignalSince(data, n-techScoreSignalLookback) {
+		score += 25
+	}
+
+	return score, true
+}
+
+// bullishSignalSince 判断从第from根K线起是否出现过看多信号：复杂指标的看多信号或向上跳空
+func bullishSignalSince(data []model.DailyData, from int) bool {
+	highs := make([]float64, len(data))
+	lows := make([]float64, len(data))
+	for i, d := range data {
+		highs[i], lows[i] = d.High, d.Low
+	}
+	for _, gap := range Gaps(highs, lows)[from:] {
+		if gap == GapUp {
+			return true
+		}
+	}
+
+	if result := CalculateComplexIndicator(data); result != nil {
+		since := data[from].GetTradeDate()
+		for _, dates := range [][]int{
+			result.Signals.GoldenCross,
+			result.Signals.Bottom,
+			result.Signals.AbsoluteBottom,
+			result.Signals.ExtremeBottom,
+			result.Signals.SeeRise,
+		} {
+			if len(dates) > 0 && dates[len(dates)-1] >= since {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FundamentalScore 基本面评分，使用最新一期业绩报表
+// ROE（每股收益/每股净资产）占30分，20%及以上满分；每股收益同比增长占35分，-20%为0分、40%及以上满分；
+// 营业收入增长占35分，-10%为0分、30%及以上满分。缺少上年同期报表时每股收益增长使用净利润增长代替
+func FundamentalScore(reports []model.PerformanceReport) (float64, bool) {
+	if len(reports) == 0 {
+		return 0, false
+	}
+
+	sorted := make([]model.PerformanceReport, len(reports))
+	copy(sorted, reports)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ReportDate > sorted[j].ReportDate
+	})
+	latest := sorted[0]
+
+	score := 0.0
+	if roe, ok := latest.ROE(); ok {
+		score += 30 * linearScore(roe, 0, 20)
+	}
+
+	epsGrowth := latest.NetProfitYoY
+	for _, report := range sorted[1:] {
+		if report.ReportDate == latest.ReportDate-10000 {
+			if report.EPS != 0 {
+				epsGrowth = (latest.EPS - report.EPS) / math.Abs(report.EPS) * 100
+			}
+			break
+		}
+	}
+	score += 35 * linearScore(epsGrowth, -20, 40)
+	score += 35 * linearScore(latest.RevenueYoY, -10, 30)
+
+	return roundScore(score), true
+}
+
+// ShareholderScore 筹码集中度评分，按最近两期股东户数的变化计算
+// 股东户数减少表示筹码趋于集中：减少20%及以上为100分，增加20%及以上为0分，不变为50分
+func ShareholderScore(counts []*model.ShareholderCount) (float64, bool) {
+	valid := make([]*model.ShareholderCount, 0, len(counts))
+	for _, count := range counts {
+		if count != nil && count.HolderNum > 0 {
+			valid = append(valid, count)
+		}
+	}
+	if len(valid) < 2 {
+		return 0, false
+	}
+
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].EndDate > valid[j].EndDate
+	})
+
+	change := float64(valid[0].HolderNum-valid[1].HolderNum) / float64(valid[1].HolderNum) * 100
+	return roundScore(100 * linearScore(-change, -20, 20)), true
+}
+
+// linearScore 将value按[low, high]线性映射到[0, 1]，超出区间时取边界值
+func linearScore(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	}
+	if value >= high {
+		return 1
+	}
+	return (value - low) / (high - low)
+}
+
+// roundScore 评分保留两位小数
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,158 @@
+package indicator
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scorePtr(v float64) *float64 {
+	return &v
+}
+
+// scoreTradeDate 返回2024-01-01之后第i天的交易日期，YYYYMMDD格式
+func scoreTradeDate(i int) int {
+	date, _ := strconv.Atoi(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i).Format("20060102"))
+	return date
+}
+
+func TestCompositeScore_Weighting(t *testing.T) {
+	components := ScoreComponents{Technical: scorePtr(80), Fundamental: scorePtr(60), Shareholder: scorePtr(20)}
+
+	// 默认权重：80*0.4 + 60*0.4 + 20*0.2 = 60
+	score, ok := CompositeScore(components, DefaultScoreWeights)
+	require.True(t, ok)
+	assert.InDelta(t, 60.0, score, 1e-9)
+
+	// 权重按比例生效，无需加总为1
+	score, ok = CompositeScore(components, ScoreWeights{Technical: 2, Fundamental: 2, Shareholder: 1})
+	require.True(t, ok)
+	assert.InDelta(t, 60.0, score, 1e-9)
+
+	// 权重为0的维度不参与计算
+	score, ok = CompositeScore(components, ScoreWeights{Technical: 1})
+	require.True(t, ok)
+	assert.InDelta(t, 80.0, score, 1e-9)
+
+	_, ok = CompositeScore(components, ScoreWeights{})
+	assert.False(t, ok)
+}
+
+func TestCompositeScore_MissingData(t *testing.T) {
+	// 缺失的维度不参与计算，其余维度按权重重新归一化：(80*0.4 + 20*0.2) / 0.6 = 60
+	score, ok := CompositeScore(ScoreComponents{Technical: scorePtr(80), Shareholder: scorePtr(20)}, DefaultScoreWeights)
+	require.True(t, ok)
+	assert.InDelta(t, 60.0, score, 1e-9)
+
+	// 唯一有数据的维度权重为0时无法评分
+	_, ok = CompositeScore(ScoreComponents{Shareholder: scorePtr(20)}, ScoreWeights{Technical: 1, Fundamental: 1})
+	assert.False(t, ok)
+
+	_, ok = CompositeScore(ScoreComponents{}, DefaultScoreWeights)
+	assert.False(t, ok)
+}
+
+func TestTechnicalScore(t *testing.T) {
+	_, ok := TechnicalScore(make([]model.DailyData, MinTechnicalScoreBars-1))
+	assert.False(t, ok)
+
+	// 持续上涨：站上MA20、MA60且MACD多头
+	data := make([]model.DailyData, 120)
+	for i := range data {
+		close := 10 + float64(i)*0.1
+		data[i] = model.DailyData{TradeDate: scoreTradeDate(i), Open: close, High: close, Low: close, Close: close}
+	}
+	score, ok := TechnicalScore(data)
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, score, 75.0)
+
+	// 持续下跌：均线和MACD均不得分
+	for i := range data {
+		close := 30 - float64(i)*0.1
+		data[i].Open, data[i].High, data[i].Low, data[i].Close = close, close, close, close
+	}
+	score, ok = TechnicalScore(data)
+	require.True(t, ok)
+	assert.LessOrEqual(t, score, 25.0)
+}
+
+func TestTechnicalScore_GapUpIsBullish(t *testing.T) {
+	// 缓慢上涨、相邻K线价格区间重叠，最后一根K线高开，两组数据只有最后一根的最低价是否高于前一日最高价不同
+	bars := func(gapUp bool) []model.DailyData {
+		data := make([]model.DailyData, 120)
+		for i := range data {
+			close := 10 + float64(i)*0.01
+			data[i] = model.DailyData{TradeDate: scoreTradeDate(i), Open: close, High: close + 0.05, Low: close - 0.05, Close: close}
+		}
+		prev := data[len(data)-2]
+		close := prev.High + 0.02
+		last := model.DailyData{TradeDate: data[len(data)-1].TradeDate, Open: close, High: close + 0.05, Low: prev.High - 0.03, Close: close}
+		if gapUp {
+			last.Low = prev.High + 0.005
+		}
+		data[len(data)-1] = last
+		return data
+	}
+
+	withGap, ok := TechnicalScore(bars(true))
+	require.True(t, ok)
+	withoutGap, ok := TechnicalScore(bars(false))
+	require.True(t, ok)
+	assert.Equal(t, 100.0, withGap)
+	assert.Equal(t, 75.0, withoutGap)
+}
+
+func TestFundamentalScore(t *testing.T) {
+	_, ok := FundamentalScore(nil)
+	assert.False(t, ok)
+
+	// ROE 20%满分30；每股收益同比增长40%满分35；营收增长10%得17.5
+	reports := []model.PerformanceReport{
+		{ReportDate: 20240630, EPS: 1.0, BVPS: 5},
+		{ReportDate: 20250630, EPS: 1.4, BVPS: 7, RevenueYoY: 10, NetProfitYoY: -50},
+	}
+	score, ok := FundamentalScore(reports)
+	require.True(t, ok)
+	assert.InDelta(t, 82.5, score, 1e-9)
+
+	// 缺少上年同期报表时使用净利润增长代替：-50%为0分
+	score, ok = FundamentalScore(reports[1:])
+	require.True(t, ok)
+	assert.InDelta(t, 47.5, score, 1e-9)
+
+	// 每股净资产缺失时ROE不得分
+	score, ok = FundamentalScore([]model.PerformanceReport{{ReportDate: 20250630, EPS: 1.4, NetProfitYoY: 10, RevenueYoY: 30}})
+	require.True(t, ok)
+	assert.InDelta(t, 52.5, score, 1e-9)
+}
+
+func TestShareholderScore(t *testing.T) {
+	_, ok := ShareholderScore(nil)
+	assert.False(t, ok)
+
+	// 只有一期有效数据时无法评分
+	_, ok = ShareholderScore([]*model.ShareholderCount{{EndDate: 20250630, HolderNum: 1000}, {EndDate: 20250331}, nil})
+	assert.False(t, ok)
+
+	// 股东户数减少10%：75分
+	score, ok := ShareholderScore([]*model.ShareholderCount{
+		{EndDate: 20250331, HolderNum: 1000},
+		{EndDate: 20250630, HolderNum: 900},
+		{EndDate: 20241231, HolderNum: 5000},
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 75.0, score, 1e-9)
+
+	// 股东户数增加超过20%：0分
+	score, ok = ShareholderScore([]*model.ShareholderCount{
+		{EndDate: 20250630, HolderNum: 1500},
+		{EndDate: 20250331, HolderNum: 1000},
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 0.0, score, 1e-9)
+}
@@ -42,7 +42,7 @@ type StickData struct {
 
 // 计算时间控制指标
 func CalculateTimeControlIndicator(data []model.DailyData) *TimeControlIndicatorResult {
-	if len(data) < 58 { // 需要至少58个数据点来计算MA(58)
+	if len(data) < MinTimeControlBars { // 需要至少58个数据点来计算MA(58)
 		return nil
 	}
 
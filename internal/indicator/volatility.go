@@ -0,0 +1,81 @@
+package indicator
+
+import "math"
+
+// TrueRange 计算真实波幅TR：当日最高价减最低价、最高价与昨收之差、最低价与昨收之差三者绝对值的最大值
+// 首根K线没有昨收，取最高价减最低价；输入长度不一致时返回nil
+func TrueRange(highs, lows, closes []float64) []float64 {
+	if len(highs) != len(lows) || len(highs) != len(closes) {
+		return nil
+	}
+
+	result := make([]float64, len(highs))
+	for i := range highs {
+		result[i] = highs[i] - lows[i]
+		if i == 0 {
+			continue
+		}
+		result[i] = math.Max(result[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+	return result
+}
+
+// ATR 计算平均真实波幅，按Wilder平滑：第period根K线取前period个TR的平均值，
+// 此后ATR = (前一日ATR*(period-1) + 当日TR) / period；不足period根的位置为0，参数无效时返回nil
+func ATR(highs, lows, closes []float64, period int) []float64 {
+	tr := TrueRange(highs, lows, closes)
+	if tr == nil || period <= 0 {
+		return nil
+	}
+
+	result := make([]float64, len(tr))
+	if len(tr) < period {
+		return result
+	}
+
+	sum := 0.0
+	for i := 0; i < period; i++ {
+		sum += tr[i]
+	}
+	result[period-1] = sum / float64(period)
+	for i := period; i < len(tr); i++ {
+		result[i] = (result[i-1]*float64(period-1) + tr[i]) / float64(period)
+	}
+	return result
+}
+
+// ReturnVolatility 计算日收益率的滚动波动率，即最近window个日收益率的样本标准差
+// 第i根K线的日收益率为closes[i]/closes[i-1]-1，不足window个收益率的位置为0，参数无效时返回nil；
+// 乘以sqrt(250)可换算为年化波动率
+func ReturnVolatility(closes []float64, window int) []float64 {
+	if window < 2 {
+		return nil
+	}
+
+	result := make([]float64, len(closes))
+	if len(closes) <= window {
+		return result
+	}
+
+	returns := make([]float64, len(closes))
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] != 0 {
+			returns[i] = closes[i]/closes[i-1] - 1
+		}
+	}
+
+	for i := window; i < len(closes); i++ {
+		mean := 0.0
+		for j := i - window + 1; j <= i; j++ {
+			mean += returns[j]
+		}
+		mean /= float64(window)
+
+		variance := 0.0
+		for j := i - window + 1; j <= i; j++ {
+			variance += (returns[j] - mean) * (returns[j] - mean)
+		}
+		result[i] = math.Sqrt(variance / float64(window-1))
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrueRange(t *testing.T) {
+	highs := []float64{10, 11, 12, 11.5, 12.5}
+	lows := []float64{9, 10, 10.5, 10, 11}
+	closes := []float64{9.5, 10.8, 11, 10.2, 12.2}
+
+	// 首根取最高价减最低价，之后取三者最大值：跳空高开时最高价与昨收之差最大
+	tr := TrueRange(highs, lows, closes)
+	assert.InDeltaSlice(t, []float64{1, 1.5, 1.5, 1.5, 2.3}, tr, 1e-9)
+
+	assert.Nil(t, TrueRange(highs, lows[:4], closes))
+}
+
+func TestATR(t *testing.T) {
+	highs := []float64{10, 11, 12, 11.5, 12.5}
+	lows := []float64{9, 10, 10.5, 10, 11}
+	closes := []float64{9.5, 10.8, 11, 10.2, 12.2}
+
+	atr := ATR(highs, lows, closes, 3)
+	require.Len(t, atr, 5)
+
+	// ATR[2] = (1+1.5+1.5)/3；之后按Wilder平滑：ATR[3] = (4/3*2+1.5)/3，ATR[4] = (25/18*2+2.3)/3
+	assert.Equal(t, 0.0, atr[0])
+	assert.Equal(t, 0.0, atr[1])
+	assert.InDelta(t, 4.0/3, atr[2], 1e-9)
+	assert.InDelta(t, 25.0/18, atr[3], 1e-9)
+	assert.InDelta(t, 91.4/54, atr[4], 1e-9)
+
+	// 数据不足period根时全部为0
+	assert.Equal(t, []float64{0, 0}, ATR(highs[:2], lows[:2], closes[:2], 3))
+	assert.Nil(t, ATR(highs, lows, closes, 0))
+}
+
+func TestReturnVolatility(t *testing.T) {
+	// 日收益率依次为10%、-10%、5%
+	closes := []float64{10, 11, 9.9, 10.395}
+
+	vol := ReturnVolatility(closes, 2)
+	require.Len(t, vol, 4)
+	assert.Equal(t, 0.0, vol[0])
+	assert.Equal(t, 0.0, vol[1])
+	assert.InDelta(t, math.Sqrt(0.02), vol[2], 1e-9)
+	assert.InDelta(t, math.Sqrt(0.01125), vol[3], 1e-9)
+
+	// 价格不变时波动率为0
+	flat := ReturnVolatility([]float64{10, 10, 10, 10}, 2)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0}, flat, 1e-12)
+
+	assert.Nil(t, ReturnVolatility(closes, 1))
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// AuditSource 审计记录的操作来源
+type AuditSource string
+
+const (
+	AuditSourceCron AuditSource = "cron" // worker定时任务
+	AuditSourceAPI  AuditSource = "api"  // Web接口
+	AuditSourceCLI  AuditSource = "cli"  // 命令行工具
+)
+
+// AuditLog 修改数据的操作的审计记录
+// 记录同步任务、手动刷新、排除区间和股票状态变更等操作由谁在何时以什么参数触发，用于排查数据为何发生变化
+type AuditLog struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Source    AuditSource `json:"source" gorm:"size:10;not null;index:idx_audit_source_created,priority:1"` // 操作来源：cron、api、cli
+	Action    string      `json:"action" gorm:"size:100;not null;index"`                                    // 操作，如任务类型、命令名或"POST /api/v1/stocks/:code/sync"
+	TsCode    string      `json:"ts_code,omitempty" gorm:"size:20;index"`                                   // 涉及的股票代码，批量操作为空
+	Actor     string      `json:"actor" gorm:"size:100"`                                                    // 操作者，接口请求为客户端IP，定时任务为worker，命令行为系统用户
+	Params    string      `json:"params,omitempty" gorm:"type:text"`                                        // 操作参数，JSON格式
+	Success   bool        `json:"success"`                                                                  // 是否成功
+	Error     string      `json:"error,omitempty" gorm:"type:text"`                                         // 失败原因
+	CreatedAt time.Time   `json:"created_at" gorm:"type:datetime(3);index:idx_audit_source_created,priority:2"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,37 @@
+package model
+
+import "fmt"
+
+// OHLCBar 带开高低收价格的K线
+type OHLCBar interface {
+	TradeDated
+	Get4Price() (float64, float64, float64, float64)
+}
+
+// ValidateBar 检查K线价格是否自洽：价格均为正数，最高价不低于开盘价、收盘价和最低价，最低价不高于开盘价和收盘价
+func ValidateBar(bar OHLCBar) error {
+	high, low, open, closePrice := bar.Get4Price()
+	switch {
+	case high <= 0 || low <= 0 || open <= 0 || closePrice <= 0:
+		return fmt.Errorf("bar %d has non-positive price: open %.3f high %.3f low %.3f close %.3f",
+			bar.GetTradeDate(), open, high, low, closePrice)
+	case high < low || high < open || high < closePrice:
+		return fmt.Errorf("bar %d high %.3f is below open %.3f, low %.3f or close %.3f",
+			bar.GetTradeDate(), high, open, low, closePrice)
+	case low > open || low > closePrice:
+		return fmt.Errorf("bar %d low %.3f is above open %.3f or close %.3f",
+			bar.GetTradeDate(), low, open, closePrice)
+	}
+	return nil
+}
+
+// FindInvalidBars 检查一组K线，返回所有不自洽的K线的错误
+func FindInvalidBars[T OHLCBar](data []T) []error {
+	var invalid []error
+	for _, bar := range data {
+		if err := ValidateBar(bar); err != nil {
+			invalid = append(invalid, err)
+		}
+	}
+	return invalid
+}
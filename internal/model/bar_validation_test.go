@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBar(t *testing.T) {
+	assert.NoError(t, ValidateBar(DailyData{TradeDate: 20250929, Open: 10.2, High: 10.8, Low: 10.1, Close: 10.5}))
+	// 一字涨停四价相同
+	assert.NoError(t, ValidateBar(DailyData{TradeDate: 20250929, Open: 11, High: 11, Low: 11, Close: 11}))
+
+	bad := []DailyData{
+		{TradeDate: 20250929, Open: 10.2, High: 10.8, Low: 0, Close: 10.5},     // 最低价为0
+		{TradeDate: 20250929, Open: 10.2, High: 10.4, Low: 10.1, Close: 10.5},  // 最高价低于收盘价
+		{TradeDate: 20250929, Open: 10.2, High: 10.8, Low: 10.3, Close: 10.5},  // 最低价高于开盘价
+		{TradeDate: 20250929, Open: 10.2, High: 10.1, Low: 10.8, Close: 10.15}, // 高低价颠倒
+	}
+	for _, bar := range bad {
+		assert.Error(t, ValidateBar(bar), "%+v", bar)
+	}
+}
+
+func TestFindInvalidBars(t *testing.T) {
+	data := []WeeklyData{
+		{TradeDate: 20250926, Open: 10, High: 10.8, Low: 9.9, Close: 10.5},
+		{TradeDate: 20251010, Open: 10.5, High: 10.4, Low: 10.1, Close: 10.3},
+	}
+	invalid := FindInvalidBars(data)
+	if assert.Len(t, invalid, 1) {
+		assert.Contains(t, invalid[0].Error(), "20251010")
+	}
+	assert.Empty(t, FindInvalidBars(data[:1]))
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// DailyMetric 由日K线计算的单日换手率和振幅
+type DailyMetric struct {
+	TradeDate    int     `json:"trade_date"`    // 交易日期，YYYYMMDD格式
+	TurnoverRate float64 `json:"turnover_rate"` // 换手率，单位：%，成交量/流通股本，流通股本未知时为0
+	Amplitude    float64 `json:"amplitude"`     // 振幅，单位：%，(最高价-最低价)/前收盘价，没有前收盘价时为0
+}
+
+// TurnoverRate 计算换手率（%），成交量和流通股本单位均为股，流通股本未知（<=0）时返回0
+func TurnoverRate(volume, floatShares int64) float64 {
+	if floatShares <= 0 || volume <= 0 {
+		return 0
+	}
+	return roundPercent(float64(volume) / float64(floatShares) * 100)
+}
+
+// Amplitude 计算振幅（%），前收盘价无效（<=0）时返回0
+func Amplitude(high, low, prevClose float64) float64 {
+	if prevClose <= 0 || high < low {
+		return 0
+	}
+	return roundPercent((high - low) / prevClose * 100)
+}
+
+// roundPercent 百分比保留2位小数，与行情软件显示的精度一致
+func roundPercent(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ComputeDailyMetrics 计算每个交易日的换手率和振幅，结果按交易日期升序
+// data可为任意顺序；prev为区间第一个交易日之前的最后一根K线，用作首日的前收盘价，可为nil；
+// 流通股本使用当前值，区间内发生过送转或解禁时早期的换手率为近似值
+func ComputeDailyMetrics(data []DailyData, prev *DailyData, floatShares int64) []DailyMetric {
+	return ComputeDailyMetricsWithShares(data, prev, nil, floatShares)
+}
+
+// ComputeDailyMetricsWithShares 同ComputeDailyMetrics，换手率按每个交易日当时的流通股本计算
+// 股本变动记录没有覆盖的交易日使用floatShares
+func ComputeDailyMetricsWithShares(data []DailyData, prev *DailyData, shares SharesTimeline, floatShares int64) []DailyMetric {
+	sorted := make([]DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeDate < sorted[j].TradeDate })
+
+	var prevClose float64
+	if prev != nil {
+		prevClose = prev.Close
+	}
+
+	metrics := make([]DailyMetric, 0, len(sorted))
+	for _, bar := range sorted {
+		metrics = append(metrics, DailyMetric{
+			TradeDate:    bar.TradeDate,
+			TurnoverRate: TurnoverRate(bar.Volume, shares.FloatSharesAt(bar.TradeDate, floatShares)),
+			Amplitude:    Amplitude(bar.High, bar.Low, prevClose),
+		})
+		prevClose = bar.Close
+	}
+	return metrics
+}
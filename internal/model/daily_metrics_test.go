@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnoverRate(t *testing.T) {
+	// 成交1256万股，流通股本12.56亿股，换手率1%
+	assert.Equal(t, 1.0, TurnoverRate(12_560_000, 1_256_000_000))
+	assert.Equal(t, 3.33, TurnoverRate(1_000_000, 30_000_000))
+	assert.Equal(t, 0.0, TurnoverRate(1_000_000, 0))
+	assert.Equal(t, 0.0, TurnoverRate(0, 30_000_000))
+}
+
+func TestAmplitude(t *testing.T) {
+	// 最高10.8、最低10.2，前收盘10，振幅6%
+	assert.Equal(t, 6.0, Amplitude(10.8, 10.2, 10))
+	assert.Equal(t, 4.55, Amplitude(11.5, 11, 11))
+	assert.Equal(t, 0.0, Amplitude(10.8, 10.2, 0))
+}
+
+func TestComputeDailyMetrics(t *testing.T) {
+	// 按数据库查询顺序降序传入
+	data := []DailyData{
+		{TradeDate: 20250930, High: 11.2, Low: 10.6, Close: 11, Volume: 2_000_000},
+		{TradeDate: 20250929, High: 10.8, Low: 10.2, Close: 10.5, Volume: 1_000_000},
+	}
+	prev := &DailyData{TradeDate: 20250926, Close: 10}
+
+	metrics := ComputeDailyMetrics(data, prev, 100_000_000)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, DailyMetric{TradeDate: 20250929, TurnoverRate: 1, Amplitude: 6}, metrics[0])
+	// 第二天以前一天的收盘价10.5为基准
+	assert.Equal(t, DailyMetric{TradeDate: 20250930, TurnoverRate: 2, Amplitude: 5.71}, metrics[1])
+
+	// 没有区间之前的K线时首日振幅为0
+	metrics = ComputeDailyMetrics(data, nil, 0)
+	assert.Equal(t, 0.0, metrics[0].Amplitude)
+	assert.Equal(t, 0.0, metrics[0].TurnoverRate)
+	assert.Equal(t, 5.71, metrics[1].Amplitude)
+}
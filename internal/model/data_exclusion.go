@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DataExclusion 已知有问题的K线数据区间，例如漏掉除权除息后未复权的时间段
+// 被排除的数据保留在库中，指标计算和回测加载数据时跳过该区间
+type DataExclusion struct {
+	ID        uint                     `json:"id" gorm:"primaryKey"`
+	TsCode    string                   `json:"ts_code" gorm:"size:20;not null;index"` // 股票代码
+	Period    TechnicalIndicatorPeriod `json:"period" gorm:"size:10;not null"`        // K线周期：daily、weekly、monthly、yearly
+	StartDate int                      `json:"start_date" gorm:"not null"`            // 排除区间起始交易日期（含），YYYYMMDD格式
+	EndDate   int                      `json:"end_date" gorm:"not null"`              // 排除区间结束交易日期（含），YYYYMMDD格式
+	Reason    string                   `json:"reason" gorm:"size:200"`                // 排除原因
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (DataExclusion) TableName() string {
+	return "data_exclusions"
+}
+
+// Normalize 统一股票代码为大写，周期为空时默认为日K线
+func (e *DataExclusion) Normalize() {
+	e.TsCode = strings.ToUpper(strings.TrimSpace(e.TsCode))
+	if e.Period == "" {
+		e.Period = TechnicalIndicatorPeriodDaily
+	}
+}
+
+// Validate 校验排除区间：股票代码必填，周期有效，起止日期为合法的YYYYMMDD且起始不晚于结束
+func (e *DataExclusion) Validate() error {
+	if e.TsCode == "" || !strings.Contains(e.TsCode, ".") {
+		return fmt.Errorf("股票代码格式错误，应为：000001.SZ 或 600000.SH")
+	}
+	switch e.Period {
+	case TechnicalIndicatorPeriodDaily, TechnicalIndicatorPeriodWeekly,
+		TechnicalIndicatorPeriodMonthly, TechnicalIndicatorPeriodYearly:
+	default:
+		return fmt.Errorf("period参数错误，应为daily、weekly、monthly或yearly")
+	}
+	for _, date := range []int{e.StartDate, e.EndDate} {
+		if _, err := time.Parse("20060102", fmt.Sprintf("%08d", date)); err != nil || date < 19000101 {
+			return fmt.Errorf("日期格式错误，应为YYYYMMDD")
+		}
+	}
+	if e.StartDate > e.EndDate {
+		return fmt.Errorf("start_date不能晚于end_date")
+	}
+	return nil
+}
+
+// Covers 判断交易日期（YYYYMMDD格式）是否在排除区间内
+func (e DataExclusion) Covers(tradeDate int) bool {
+	return tradeDate >= e.StartDate && tradeDate <= e.EndDate
+}
+
+// FilterExcluded 去掉交易日期落在任一排除区间内的数据，保留原有顺序
+func FilterExcluded[T any](items []T, exclusions []DataExclusion, tradeDate func(T) int) []T {
+	if len(exclusions) == 0 {
+		return items
+	}
+
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		date := tradeDate(item)
+		excluded := false
+		for _, exclusion := range exclusions {
+			if exclusion.Covers(date) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExclusion_Validate(t *testing.T) {
+	valid := DataExclusion{TsCode: " 600519.sh ", StartDate: 20240102, EndDate: 20240110}
+	valid.Normalize()
+	assert.Equal(t, "600519.SH", valid.TsCode)
+	assert.Equal(t, TechnicalIndicatorPeriodDaily, valid.Period)
+	assert.NoError(t, valid.Validate())
+
+	cases := map[string]DataExclusion{
+		"invalid code":   {TsCode: "600519", Period: "daily", StartDate: 20240102, EndDate: 20240110},
+		"invalid period": {TsCode: "600519.SH", Period: "hourly", StartDate: 20240102, EndDate: 20240110},
+		"invalid date":   {TsCode: "600519.SH", Period: "daily", StartDate: 20240230, EndDate: 20240310},
+		"missing date":   {TsCode: "600519.SH", Period: "daily", StartDate: 20240102},
+		"reversed range": {TsCode: "600519.SH", Period: "weekly", StartDate: 20240110, EndDate: 20240102},
+	}
+	for name, exclusion := range cases {
+		assert.Error(t, exclusion.Validate(), name)
+	}
+}
+
+func TestFilterExcluded(t *testing.T) {
+	series := make([]DailyData, 0, 10)
+	for date := 20240102; date <= 20240111; date++ {
+		series = append(series, DailyData{TsCode: "600519.SH", TradeDate: date})
+	}
+	dates := func(data []DailyData) []int {
+		result := make([]int, 0, len(data))
+		for _, d := range data {
+			result = append(result, d.TradeDate)
+		}
+		return result
+	}
+	tradeDate := func(d DailyData) int { return d.TradeDate }
+
+	// 排除区间首尾均包含在内，多个区间同时生效
+	exclusions := []DataExclusion{
+		{StartDate: 20240104, EndDate: 20240106},
+		{StartDate: 20240110, EndDate: 20240110},
+	}
+	filtered := FilterExcluded(series, exclusions, tradeDate)
+	assert.Equal(t, []int{20240102, 20240103, 20240107, 20240108, 20240109, 20240111}, dates(filtered))
+
+	// 没有排除区间时原样返回
+	assert.Len(t, FilterExcluded(series, nil, tradeDate), 10)
+}
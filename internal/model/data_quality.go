@@ -0,0 +1,141 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 数据质量评分的扣分规则
+const (
+	maxHolidayWeekdays     = 6    // 最长休市假期包含的工作日数（春节），相邻K线之间缺失的工作日超过该值视为数据缺口
+	dataQualityGapPenalty  = 10.0 // 每个数据缺口扣分
+	dataQualityGapMax      = 40.0 // 数据缺口最多扣分
+	dataQualityAnomalyCost = 15.0 // 每个复权异常扣分
+	dataQualityAnomalyMax  = 45.0 // 复权异常最多扣分
+	dataQualityStaleCost   = 5.0  // 每落后一个交易日扣分
+	dataQualityStaleMax    = 40.0 // 数据滞后最多扣分
+	marketCloseHour        = 15   // 收盘时间（时）
+	marketCloseMinute      = 30   // 收盘后数据可用时间（分）
+)
+
+// StockDataQuality 股票日K线数据质量评分
+// 综合数据缺口、复权异常和数据滞后情况，取值0-100，没有日K线数据时为0；每晚同步后重新计算
+type StockDataQuality struct {
+	TsCode      string    `json:"ts_code" gorm:"column:ts_code;size:20;not null;primaryKey"`  // 股票代码，主键
+	Score       float64   `json:"score" gorm:"column:score;type:decimal(5,2);not null;index"` // 数据质量评分
+	Bars        int       `json:"bars" gorm:"column:bars"`                                    // 日K线数量
+	Gaps        int       `json:"gaps" gorm:"column:gaps"`                                    // 数据缺口数量
+	Anomalies   int       `json:"anomalies" gorm:"column:anomalies"`                          // 超过涨跌幅限制的价格跳变数量
+	StaleDays   int       `json:"stale_days" gorm:"column:stale_days"`                        // 最新K线落后的交易日数，停牌股票不计
+	LastBarDate int       `json:"last_bar_date" gorm:"column:last_bar_date"`                  // 最新K线交易日期，YYYYMMDD格式，无数据时为0
+	EvaluatedAt time.Time `json:"evaluated_at" gorm:"column:evaluated_at;type:datetime(3)"`   // 评估时间
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;type:datetime(3)"`       // 记录创建时间
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;type:datetime(3)"`       // 记录更新时间
+}
+
+// TableName 指定表名
+func (StockDataQuality) TableName() string {
+	return "stock_data_quality"
+}
+
+// DailyGap 相邻两根日K线之间的数据缺口
+type DailyGap struct {
+	From           int `json:"from"`            // 缺口前最后一个有数据的交易日期，YYYYMMDD格式
+	To             int `json:"to"`              // 缺口后第一个有数据的交易日期，YYYYMMDD格式
+	MissingWeekday int `json:"missing_weekday"` // 缺失的工作日数
+}
+
+// FindDailyGaps 找出相邻日K线之间缺失工作日超过最长休市假期的缺口，输入数据无需排序
+// 没有交易日历时按工作日估算，长期停牌同样会被识别为缺口
+func FindDailyGaps(data []DailyData) []DailyGap {
+	dates := make([]int, 0, len(data))
+	for _, d := range data {
+		dates = append(dates, d.TradeDate)
+	}
+	sort.Ints(dates)
+
+	gaps := make([]DailyGap, 0)
+	for i := 1; i < len(dates); i++ {
+		missing := weekdaysBetween(tradeDateToTime(dates[i-1]), tradeDateToTime(dates[i]))
+		if missing > maxHolidayWeekdays {
+			gaps = append(gaps, DailyGap{From: dates[i-1], To: dates[i], MissingWeekday: missing})
+		}
+	}
+	return gaps
+}
+
+// StaleTradingDays 计算最新K线相对now应有的最新交易日落后的交易日数
+// 收盘（15:30）前当日数据尚未生成，应有的最新交易日为上一个工作日；节假日按工作日估算
+func StaleTradingDays(lastBarDate int, now time.Time) int {
+	if lastBarDate == 0 {
+		return 0
+	}
+
+	expected := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if now.Hour() < marketCloseHour || (now.Hour() == marketCloseHour && now.Minute() < marketCloseMinute) {
+		expected = expected.AddDate(0, 0, -1)
+	}
+	for isWeekend(expected) {
+		expected = expected.AddDate(0, 0, -1)
+	}
+
+	last := tradeDateToTime(lastBarDate)
+	if !expected.After(last) {
+		return 0
+	}
+	return weekdaysBetween(last, expected) + 1
+}
+
+// EvaluateDataQuality 根据日K线评估股票的数据质量
+// 满分100：每个缺口扣10分（最多40分），每个复权异常扣15分（最多45分），每落后一个交易日扣5分（最多40分）；
+// 停牌股票不计数据滞后，没有日K线时评分为0
+func EvaluateDataQuality(stock Stock, data []DailyData, now time.Time) StockDataQuality {
+	quality := StockDataQuality{TsCode: stock.TsCode, Bars: len(data), EvaluatedAt: now}
+	if len(data) == 0 {
+		return quality
+	}
+
+	for _, d := range data {
+		if d.TradeDate > quality.LastBarDate {
+			quality.LastBarDate = d.TradeDate
+		}
+	}
+	quality.Gaps = len(FindDailyGaps(data))
+	quality.Anomalies = len(FindPriceAnomalies(stock, data))
+	if stock.GetStatus() != StockStatusSuspended {
+		quality.StaleDays = StaleTradingDays(quality.LastBarDate, now)
+	}
+	quality.Score = DataQualityScore(quality.Gaps, quality.Anomalies, quality.StaleDays)
+	return quality
+}
+
+// DataQualityScore 按缺口数、复权异常数和落后交易日数计算数据质量评分
+func DataQualityScore(gaps, anomalies, staleDays int) float64 {
+	score := 100.0
+	score -= math.Min(float64(gaps)*dataQualityGapPenalty, dataQualityGapMax)
+	score -= math.Min(float64(anomalies)*dataQualityAnomalyCost, dataQualityAnomalyMax)
+	score -= math.Min(float64(staleDays)*dataQualityStaleCost, dataQualityStaleMax)
+	return math.Max(score, 0)
+}
+
+// tradeDateToTime 将YYYYMMDD格式的交易日期转换为UTC零点时间
+func tradeDateToTime(tradeDate int) time.Time {
+	return time.Date(tradeDate/10000, time.Month(tradeDate/100%100), tradeDate%100, 0, 0, 0, 0, time.UTC)
+}
+
+// weekdaysBetween 统计from和to之间（均不含）的工作日数
+func weekdaysBetween(from, to time.Time) int {
+	count := 0
+	for d := from.AddDate(0, 0, 1); d.Before(to); d = d.AddDate(0, 0, 1) {
+		if !isWeekend(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// isWeekend 判断是否为周末
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weekdayBars 从start开始按工作日生成n根日K线，收盘价统一为10
+func weekdayBars(tsCode string, start time.Time, n int) []DailyData {
+	data := make([]DailyData, 0, n)
+	for d := start; len(data) < n; d = d.AddDate(0, 0, 1) {
+		if isWeekend(d) {
+			continue
+		}
+		data = append(data, DailyData{TsCode: tsCode, TradeDate: d.Year()*10000 + int(d.Month())*100 + d.Day(), Close: 10})
+	}
+	return data
+}
+
+func TestDataQualityScore(t *testing.T) {
+	assert.Equal(t, 100.0, DataQualityScore(0, 0, 0))
+	assert.Equal(t, 90.0, DataQualityScore(1, 0, 0))
+	assert.Equal(t, 60.0, DataQualityScore(10, 0, 0)) // 缺口最多扣40分
+	assert.Equal(t, 70.0, DataQualityScore(0, 2, 0))
+	assert.Equal(t, 55.0, DataQualityScore(0, 5, 0)) // 复权异常最多扣45分
+	assert.Equal(t, 85.0, DataQualityScore(0, 0, 3))
+	assert.Equal(t, 60.0, DataQualityScore(0, 0, 100))
+	assert.Equal(t, 0.0, DataQualityScore(10, 10, 100))
+}
+
+func TestFindDailyGaps(t *testing.T) {
+	// 春节休市（2024-02-09至2024-02-16，6个工作日）不是缺口
+	data := []DailyData{{TradeDate: 20240208}, {TradeDate: 20240219}, {TradeDate: 20240220}}
+	assert.Empty(t, FindDailyGaps(data))
+
+	// 缺失两周以上的数据为缺口，输入乱序
+	data = []DailyData{{TradeDate: 20240320}, {TradeDate: 20240301}, {TradeDate: 20240304}}
+	gaps := FindDailyGaps(data)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, DailyGap{From: 20240304, To: 20240320, MissingWeekday: 11}, gaps[0])
+}
+
+func TestStaleTradingDays(t *testing.T) {
+	// 周三收盘后，最新数据为周三时不滞后
+	wednesdayEvening := time.Date(2024, 3, 6, 18, 0, 0, 0, time.Local)
+	assert.Equal(t, 0, StaleTradingDays(20240306, wednesdayEvening))
+	assert.Equal(t, 1, StaleTradingDays(20240305, wednesdayEvening))
+
+	// 收盘前当日数据尚未生成
+	wednesdayMorning := time.Date(2024, 3, 6, 10, 0, 0, 0, time.Local)
+	assert.Equal(t, 0, StaleTradingDays(20240305, wednesdayMorning))
+
+	// 周末时应有的最新交易日为周五
+	sunday := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, 0, StaleTradingDays(20240308, sunday))
+	assert.Equal(t, 5, StaleTradingDays(20240301, sunday))
+
+	assert.Equal(t, 0, StaleTradingDays(0, sunday))
+}
+
+func TestEvaluateDataQuality(t *testing.T) {
+	now := time.Date(2024, 3, 29, 18, 0, 0, 0, time.Local) // 周五收盘后
+	stock := Stock{TsCode: "600519.SH", Name: "贵州茅台"}
+
+	t.Run("clean and fresh", func(t *testing.T) {
+		data := weekdayBars(stock.TsCode, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), 20)
+		quality := EvaluateDataQuality(stock, data, now)
+		assert.Equal(t, 100.0, quality.Score)
+		assert.Equal(t, 20, quality.Bars)
+		assert.Equal(t, 20240329, quality.LastBarDate)
+		assert.Zero(t, quality.Gaps+quality.Anomalies+quality.StaleDays)
+	})
+
+	t.Run("gap anomaly and staleness", func(t *testing.T) {
+		data := weekdayBars(stock.TsCode, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 10)
+		data = append(data, weekdayBars(stock.TsCode, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 15)...)
+		data[len(data)-1].Close = 5 // 未复权的跳变
+		quality := EvaluateDataQuality(stock, data, now)
+		assert.Equal(t, 1, quality.Gaps)
+		assert.Equal(t, 1, quality.Anomalies)
+		assert.Equal(t, 20240321, quality.LastBarDate)
+		assert.Equal(t, 6, quality.StaleDays)
+		assert.Equal(t, 100.0-10-15-30, quality.Score)
+	})
+
+	t.Run("suspended stock is not stale", func(t *testing.T) {
+		suspended := stock
+		suspended.SetStatus(StockStatusSuspended)
+		data := weekdayBars(stock.TsCode, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 5)
+		quality := EvaluateDataQuality(suspended, data, now)
+		assert.Equal(t, 0, quality.StaleDays)
+		assert.Equal(t, 100.0, quality.Score)
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		quality := EvaluateDataQuality(stock, nil, now)
+		assert.Equal(t, 0.0, quality.Score)
+		assert.Equal(t, 0, quality.Bars)
+	})
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultEarningsSurpriseThreshold 业绩大幅变动的默认阈值，每股收益或营业收入同比变动的绝对值达到该百分比时标记
+const DefaultEarningsSurpriseThreshold = 50.0
+
+// EarningsAnnouncement 一只股票在指定区间内披露的业绩报表
+type EarningsAnnouncement struct {
+	TsCode                 string     `json:"ts_code"`                  // 股票代码
+	Name                   string     `json:"name"`                     // 股票简称
+	ReportDate             int        `json:"report_date"`              // 报告期，YYYYMMDD格式
+	FirstAnnouncementDate  *time.Time `json:"first_announcement_date"`  // 首次公告日期
+	LatestAnnouncementDate *time.Time `json:"latest_announcement_date"` // 最新公告日期
+	EPS                    float64    `json:"eps"`                      // 每股收益，单位：元
+	EPSYoY                 *float64   `json:"eps_yoy"`                  // 每股收益同比变动，单位：%，缺少上年同期报表或上年同期每股收益为0时为nil
+	RevenueYoY             float64    `json:"revenue_yoy"`              // 营业总收入同比增长，单位：%
+	NetProfitYoY           float64    `json:"net_profit_yoy"`           // 净利润同比增长，单位：%
+	Surprise               bool       `json:"surprise"`                 // 每股收益或营业收入同比变动幅度是否达到阈值
+}
+
+// NewEarningsAnnouncement 由披露的报表和上年同期报表构造业绩披露记录，prior为nil表示没有上年同期报表
+// 每股收益同比变动按上年同期每股收益的绝对值计算，上年亏损本年盈利时为正；变动幅度达到threshold（单位：%）时标记Surprise
+func NewEarningsAnnouncement(name string, report PerformanceReport, prior *PerformanceReport, threshold float64) EarningsAnnouncement {
+	announcement := EarningsAnnouncement{
+		TsCode:                 report.TsCode,
+		Name:                   name,
+		ReportDate:             report.ReportDate,
+		FirstAnnouncementDate:  report.FirstAnnouncementDate,
+		LatestAnnouncementDate: report.LatestAnnouncementDate,
+		EPS:                    report.EPS,
+		RevenueYoY:             report.RevenueYoY,
+		NetProfitYoY:           report.NetProfitYoY,
+	}
+	if prior != nil && prior.EPS != 0 {
+		yoy := math.Round((report.EPS-prior.EPS)/math.Abs(prior.EPS)*10000) / 100
+		announcement.EPSYoY = &yoy
+	}
+
+	announcement.Surprise = math.Abs(report.RevenueYoY) >= threshold ||
+		(announcement.EPSYoY != nil && math.Abs(*announcement.EPSYoY) >= threshold)
+	return announcement
+}
+
+// PriorYearReportDate 上年同期的报告期
+func PriorYearReportDate(reportDate int) int {
+	return reportDate - 10000
+}
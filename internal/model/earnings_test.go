@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEarningsAnnouncement(t *testing.T) {
+	report := PerformanceReport{TsCode: "600519.SH", ReportDate: 20250630, EPS: 1.5, RevenueYoY: 12}
+
+	// 每股收益同比增长50%，达到阈值
+	a := NewEarningsAnnouncement("贵州茅台", report, &PerformanceReport{ReportDate: 20240630, EPS: 1}, DefaultEarningsSurpriseThreshold)
+	require.NotNil(t, a.EPSYoY)
+	assert.Equal(t, 50.0, *a.EPSYoY)
+	assert.True(t, a.Surprise)
+
+	// 上年同期亏损，按绝对值计算扭亏的变动幅度
+	a = NewEarningsAnnouncement("贵州茅台", report, &PerformanceReport{ReportDate: 20240630, EPS: -0.5}, DefaultEarningsSurpriseThreshold)
+	assert.Equal(t, 400.0, *a.EPSYoY)
+
+	// 没有上年同期报表时只按营业收入判断
+	a = NewEarningsAnnouncement("贵州茅台", report, nil, DefaultEarningsSurpriseThreshold)
+	assert.Nil(t, a.EPSYoY)
+	assert.False(t, a.Surprise)
+
+	report.RevenueYoY = -60
+	a = NewEarningsAnnouncement("贵州茅台", report, nil, DefaultEarningsSurpriseThreshold)
+	assert.True(t, a.Surprise)
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// StockFundFlow 个股每日资金流向
+// 按单笔成交金额划分：超大单（>=100万元）和大单（20万-100万元）合计为主力，中单为4万-20万元，小单为<4万元；
+// 净流入为买入成交额减卖出成交额，占比为净流入占当日成交额的百分比
+type StockFundFlow struct {
+	TsCode                 string    `json:"ts_code" gorm:"column:ts_code;size:20;not null;primaryKey"`                              // 股票代码，联合主键1
+	TradeDate              int       `json:"trade_date" gorm:"column:trade_date;not null;primaryKey;index"`                          // 交易日期，YYYYMMDD格式，联合主键2
+	Close                  float64   `json:"close" gorm:"column:close;type:decimal(10,3)"`                                           // 收盘价（盘中为最新价），单位：元
+	ChangePct              float64   `json:"change_pct" gorm:"column:change_pct;type:decimal(10,2)"`                                 // 涨跌幅，单位：%
+	MainNetInflow          float64   `json:"main_net_inflow" gorm:"column:main_net_inflow;type:decimal(20,2)"`                       // 主力净流入，单位：元
+	MainNetInflowPct       float64   `json:"main_net_inflow_pct" gorm:"column:main_net_inflow_pct;type:decimal(10,2)"`               // 主力净流入占比，单位：%
+	SuperLargeNetInflow    float64   `json:"super_large_net_inflow" gorm:"column:super_large_net_inflow;type:decimal(20,2)"`         // 超大单净流入，单位：元
+	SuperLargeNetInflowPct float64   `json:"super_large_net_inflow_pct" gorm:"column:super_large_net_inflow_pct;type:decimal(10,2)"` // 超大单净流入占比，单位：%
+	LargeNetInflow         float64   `json:"large_net_inflow" gorm:"column:large_net_inflow;type:decimal(20,2)"`                     // 大单净流入，单位：元
+	LargeNetInflowPct      float64   `json:"large_net_inflow_pct" gorm:"column:large_net_inflow_pct;type:decimal(10,2)"`             // 大单净流入占比，单位：%
+	MediumNetInflow        float64   `json:"medium_net_inflow" gorm:"column:medium_net_inflow;type:decimal(20,2)"`                   // 中单净流入，单位：元
+	MediumNetInflowPct     float64   `json:"medium_net_inflow_pct" gorm:"column:medium_net_inflow_pct;type:decimal(10,2)"`           // 中单净流入占比，单位：%
+	SmallNetInflow         float64   `json:"small_net_inflow" gorm:"column:small_net_inflow;type:decimal(20,2)"`                     // 小单净流入，单位：元
+	SmallNetInflowPct      float64   `json:"small_net_inflow_pct" gorm:"column:small_net_inflow_pct;type:decimal(10,2)"`             // 小单净流入占比，单位：%
+	CreatedAt              time.Time `json:"created_at" gorm:"column:created_at;type:datetime(3)"`                                   // 记录创建时间
+	UpdatedAt              time.Time `json:"updated_at" gorm:"column:updated_at;type:datetime(3)"`                                   // 记录更新时间
+}
+
+// TableName 指定表名
+func (StockFundFlow) TableName() string {
+	return "stock_fund_flows"
+}
+
+// GetTradeDate 获取交易日期
+func (f StockFundFlow) GetTradeDate() int {
+	return f.TradeDate
+}
+
+// StockWithFundFlow 股票基础信息及当日资金流向
+type StockWithFundFlow struct {
+	Stock    Stock         `json:"stock"`
+	FundFlow StockFundFlow `json:"fund_flow"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Index 指数基础信息模型
+type Index struct {
+	TsCode    string    `json:"ts_code" gorm:"primaryKey;size:20;not null"` // 指数代码，如：000001.SH（上证指数）、399006.SZ（创业板指），主键
+	Symbol    string    `json:"symbol" gorm:"size:10;not null"`             // 指数代码，不含交易所后缀
+	Name      string    `json:"name" gorm:"size:100;not null"`              // 指数简称，如：上证指数、沪深300
+	Market    string    `json:"market" gorm:"size:10"`                      // 发布市场，SH=上交所、SZ=深交所、CSI=中证指数公司
+	IsActive  bool      `json:"is_active" gorm:"default:true"`              // 是否需要持续采集
+	CreatedAt time.Time `json:"created_at"`                                 // 记录创建时间
+	UpdatedAt time.Time `json:"updated_at"`                                 // 记录更新时间
+}
+
+// TableName 指定表名
+func (Index) TableName() string {
+	return "indices"
+}
+
+// DefaultIndices 默认跟踪的核心指数
+var DefaultIndices = []Index{
+	{TsCode: "000001.SH", Symbol: "000001", Name: "上证指数", Market: "SH", IsActive: true},
+	{TsCode: "399001.SZ", Symbol: "399001", Name: "深证成指", Market: "SZ", IsActive: true},
+	{TsCode: "399006.SZ", Symbol: "399006", Name: "创业板指", Market: "SZ", IsActive: true},
+	{TsCode: "000016.SH", Symbol: "000016", Name: "上证50", Market: "SH", IsActive: true},
+	{TsCode: "000300.SH", Symbol: "000300", Name: "沪深300", Market: "SH", IsActive: true},
+	{TsCode: "000905.SH", Symbol: "000905", Name: "中证500", Market: "SH", IsActive: true},
+	{TsCode: "000688.SH", Symbol: "000688", Name: "科创50", Market: "SH", IsActive: true},
+}
+
+// IndexDaily 指数日线数据模型，所有指数共用一张表，不按代码前缀分表（指数代码与股票代码存在重叠，如000001.SH）
+type IndexDaily struct {
+	TsCode    string    `json:"ts_code" gorm:"size:20;not null;primaryKey"` // 指数代码，如：000300.SH、399006.SZ，联合主键1
+	TradeDate int       `json:"trade_date" gorm:"not null;primaryKey"`      // 交易日期，YYYYMMDD格式，如：20250910，联合主键2
+	Open      float64   `json:"open" gorm:"type:decimal(12,3)"`             // 开盘点位
+	High      float64   `json:"high" gorm:"type:decimal(12,3)"`             // 最高点位
+	Low       float64   `json:"low" gorm:"type:decimal(12,3)"`              // 最低点位
+	Close     float64   `json:"close" gorm:"type:decimal(12,3)"`            // 收盘点位
+	Volume    int64     `json:"volume"`                                     // 成交量，单位：手
+	Amount    float64   `json:"amount" gorm:"type:decimal(20,2)"`           // 成交额，单位：元
+	CreatedAt time.Time `json:"created_at"`                                 // 记录创建时间戳
+	UpdatedAt time.Time `json:"updated_at"`                                 // 记录更新时间戳
+}
+
+// TableName 指定表名
+func (IndexDaily) TableName() string {
+	return "index_daily"
+}
+
+// Get4Price 获取最高、最低、开盘、收盘点位
+func (d IndexDaily) Get4Price() (float64, float64, float64, float64) {
+	return d.High, d.Low, d.Open, d.Close
+}
+
+// GetSymbol 获取指数代码
+func (d IndexDaily) GetSymbol() string {
+	return strings.Split(d.TsCode, ".")[0]
+}
+
+// GetTradeDate 获取交易日期
+func (d IndexDaily) GetTradeDate() int {
+	return d.TradeDate
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IndicatorSignal 由已保存的技术指标判断的信号类型
+type IndicatorSignal string
+
+// 技术指标信号，交叉类信号比较当日与上一交易日的指标，超买超卖信号只看当日
+const (
+	SignalGoldenCross    IndicatorSignal = "golden_cross"     // MACD金叉：DIF上穿DEA
+	SignalDeathCross     IndicatorSignal = "death_cross"      // MACD死叉：DIF下穿DEA
+	SignalMAGoldenCross  IndicatorSignal = "ma_golden_cross"  // 均线金叉：MA5上穿MA20
+	SignalMADeathCross   IndicatorSignal = "ma_death_cross"   // 均线死叉：MA5下穿MA20
+	SignalKDJGoldenCross IndicatorSignal = "kdj_golden_cross" // KDJ金叉：K上穿D
+	SignalKDJDeathCross  IndicatorSignal = "kdj_death_cross"  // KDJ死叉：K下穿D
+	SignalRSIOversold    IndicatorSignal = "rsi_oversold"     // RSI6低于30，超卖
+	SignalRSIOverbought  IndicatorSignal = "rsi_overbought"   // RSI6高于70，超买
+)
+
+// RSI超买超卖的阈值
+const (
+	rsiOversoldLevel   = 30.0
+	rsiOverboughtLevel = 70.0
+)
+
+// IndicatorSignals 所有支持的技术指标信号
+var IndicatorSignals = []IndicatorSignal{
+	SignalGoldenCross, SignalDeathCross,
+	SignalMAGoldenCross, SignalMADeathCross,
+	SignalKDJGoldenCross, SignalKDJDeathCross,
+	SignalRSIOversold, SignalRSIOverbought,
+}
+
+// ParseIndicatorSignals 解析逗号分隔的信号类型列表，去除重复，未知类型时返回错误
+func ParseIndicatorSignals(value string) ([]IndicatorSignal, error) {
+	signals := make([]IndicatorSignal, 0)
+	seen := make(map[IndicatorSignal]bool)
+	for _, part := range strings.Split(value, ",") {
+		signal := IndicatorSignal(strings.ToLower(strings.TrimSpace(part)))
+		if signal == "" || seen[signal] {
+			continue
+		}
+		if !signal.Valid() {
+			return nil, fmt.Errorf("unknown signal type: %s", signal)
+		}
+		seen[signal] = true
+		signals = append(signals, signal)
+	}
+	if len(signals) == 0 {
+		return nil, fmt.Errorf("signal type cannot be empty")
+	}
+	return signals, nil
+}
+
+// Valid 判断是否为支持的信号类型
+func (s IndicatorSignal) Valid() bool {
+	for _, signal := range IndicatorSignals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect 判断当日指标是否出现该信号，prev为上一交易日的指标，为nil时交叉类信号不成立
+// 指标尚未计算（均线预热期为0）时不视为交叉
+func (s IndicatorSignal) Detect(prev, cur *TechnicalIndicator) bool {
+	if cur == nil {
+		return false
+	}
+
+	crossUp := func(prevFast, prevSlow, fast, slow float64) bool {
+		return prevFast <= prevSlow && fast > slow
+	}
+	crossDown := func(prevFast, prevSlow, fast, slow float64) bool {
+		return prevFast >= prevSlow && fast < slow
+	}
+	maReady := prev != nil && prev.Ma5 > 0 && prev.Ma20 > 0 && cur.Ma5 > 0 && cur.Ma20 > 0
+
+	switch s {
+	case SignalGoldenCross:
+		return prev != nil && crossUp(prev.MacdDif, prev.MacdDea, cur.MacdDif, cur.MacdDea)
+	case SignalDeathCross:
+		return prev != nil && crossDown(prev.MacdDif, prev.MacdDea, cur.MacdDif, cur.MacdDea)
+	case SignalMAGoldenCross:
+		return maReady && crossUp(prev.Ma5, prev.Ma20, cur.Ma5, cur.Ma20)
+	case SignalMADeathCross:
+		return maReady && crossDown(prev.Ma5, prev.Ma20, cur.Ma5, cur.Ma20)
+	case SignalKDJGoldenCross:
+		return prev != nil && crossUp(prev.KdjK, prev.KdjD, cur.KdjK, cur.KdjD)
+	case SignalKDJDeathCross:
+		return prev != nil && crossDown(prev.KdjK, prev.KdjD, cur.KdjK, cur.KdjD)
+	case SignalRSIOversold:
+		return cur.Rsi6 > 0 && cur.Rsi6 < rsiOversoldLevel
+	case SignalRSIOverbought:
+		return cur.Rsi6 > rsiOverboughtLevel
+	default:
+		return false
+	}
+}
+
+// StockIndicatorSignal 某只股票在某个交易日出现的技术指标信号
+type StockIndicatorSignal struct {
+	TsCode     string              `json:"ts_code"`    // 股票代码
+	Name       string              `json:"name"`       // 股票名称
+	TradeDate  int                 `json:"trade_date"` // 交易日期，YYYYMMDD格式
+	Signals    []IndicatorSignal   `json:"signals"`    // 出现的信号，按请求的信号顺序
+	Indicators *TechnicalIndicator `json:"indicators"` // 当日的技术指标
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIndicatorSignals(t *testing.T) {
+	signals, err := ParseIndicatorSignals(" Golden_Cross,rsi_oversold,golden_cross ")
+	require.NoError(t, err)
+	assert.Equal(t, []IndicatorSignal{SignalGoldenCross, SignalRSIOversold}, signals)
+
+	_, err = ParseIndicatorSignals("golden_cross,unknown")
+	assert.EqualError(t, err, "unknown signal type: unknown")
+
+	_, err = ParseIndicatorSignals(" , ")
+	assert.Error(t, err)
+}
+
+func TestIndicatorSignal_Detect(t *testing.T) {
+	prev := &TechnicalIndicator{MacdDif: -0.1, MacdDea: 0, Ma5: 9.8, Ma20: 10, KdjK: 50, KdjD: 40}
+	cur := &TechnicalIndicator{MacdDif: 0.1, MacdDea: 0, Ma5: 10.2, Ma20: 10, KdjK: 30, KdjD: 40, Rsi6: 20}
+
+	assert.True(t, SignalGoldenCross.Detect(prev, cur))
+	assert.False(t, SignalDeathCross.Detect(prev, cur))
+	assert.True(t, SignalMAGoldenCross.Detect(prev, cur))
+	assert.True(t, SignalKDJDeathCross.Detect(prev, cur))
+	assert.False(t, SignalKDJGoldenCross.Detect(prev, cur))
+	assert.True(t, SignalRSIOversold.Detect(prev, cur))
+	assert.False(t, SignalRSIOverbought.Detect(prev, cur))
+
+	// 没有上一交易日指标时交叉信号不成立，超买超卖只看当日
+	assert.False(t, SignalGoldenCross.Detect(nil, cur))
+	assert.True(t, SignalRSIOversold.Detect(nil, cur))
+
+	// 均线尚未计算时不视为交叉
+	assert.False(t, SignalMAGoldenCross.Detect(&TechnicalIndicator{Ma5: 0, Ma20: 0}, cur))
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// JobRun 定时采集任务的一次运行记录
+// 每次collect任务结束时写入，用于统计同步的可靠性，并与上游数据源的故障时间对照
+type JobRun struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Job               string    `json:"job" gorm:"size:50;not null;index:idx_job_started,priority:1"`        // 任务类型，如weekly_kline
+	StartedAt         time.Time `json:"started_at" gorm:"type:datetime(3);index:idx_job_started,priority:2"` // 开始时间
+	FinishedAt        time.Time `json:"finished_at" gorm:"type:datetime(3)"`                                 // 结束时间
+	Total             int       `json:"total"`                                                               // 任务总数
+	Success           int       `json:"success"`                                                             // 成功数
+	Failed            int       `json:"failed"`                                                              // 失败数
+	DurationMs        int64     `json:"duration_ms"`                                                         // 总耗时，单位：毫秒
+	AverageDurationMs int64     `json:"average_duration_ms"`                                                 // 单个任务的平均耗时，单位：毫秒
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (JobRun) TableName() string {
+	return "job_runs"
+}
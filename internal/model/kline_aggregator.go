@@ -0,0 +1,56 @@
+package model
+
+import "sort"
+
+// KLineAggregator 由已保存的日K线聚合周K、月K、季K、年K线，分桶规则见KLineBucket
+// 开盘价取期内第一天的开盘价，收盘价取最后一天的收盘价，最高价、最低价取期内极值，成交量、成交额求和；
+// 交易日期为期内最后一个交易日，与数据源返回的周期K线一致
+type KLineAggregator struct {
+	bucket KLineBucket
+}
+
+// NewKLineAggregator 创建指定分桶方式的K线聚合器
+func NewKLineAggregator(bucket KLineBucket) *KLineAggregator {
+	return &KLineAggregator{bucket: bucket}
+}
+
+// Aggregate 将日K线按期聚合，返回按交易日期升序的周期K线；输入可以是任意顺序，交易日期不合法的日K线被忽略
+// 期内日K线不完整（如只传入了后半周）时按传入的部分聚合，调用方应从一期的第一天开始读取日K线
+func (a *KLineAggregator) Aggregate(daily []DailyData) []DailyData {
+	sorted := make([]DailyData, 0, len(daily))
+	for _, d := range daily {
+		if a.bucket.Key(d.TradeDate) != 0 {
+			sorted = append(sorted, d)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeDate < sorted[j].TradeDate })
+
+	var result []DailyData
+	currentKey := 0
+	for _, d := range sorted {
+		key := a.bucket.Key(d.TradeDate)
+		if key != currentKey {
+			currentKey = key
+			result = append(result, DailyData{
+				TsCode:    d.TsCode,
+				TradeDate: d.TradeDate,
+				Open:      d.Open,
+				High:      d.High,
+				Low:       d.Low,
+				Close:     d.Close,
+				Volume:    d.Volume,
+				Amount:    d.Amount,
+			})
+			continue
+		}
+
+		bar := &result[len(result)-1]
+		bar.TradeDate = d.TradeDate
+		bar.Close = d.Close
+		bar.High = max(bar.High, d.High)
+		bar.Low = min(bar.Low, d.Low)
+		bar.Volume += d.Volume
+		bar.Amount = RoundAmount(bar.Amount + d.Amount)
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstreamDaily 同花顺按年文件返回的600000.SH日K线（2023-12-28至2024-01-05），元旦休市
+var upstreamDaily = []DailyData{
+	{TsCode: "600000.SH", TradeDate: 20231228, Open: 6.58, High: 6.69, Low: 6.56, Close: 6.66, Volume: 45120300, Amount: 299512345.67},
+	{TsCode: "600000.SH", TradeDate: 20231229, Open: 6.66, High: 6.70, Low: 6.60, Close: 6.63, Volume: 33871200, Amount: 224687654.32},
+	{TsCode: "600000.SH", TradeDate: 20240102, Open: 6.62, High: 6.64, Low: 6.53, Close: 6.55, Volume: 40012500, Amount: 263008765.43},
+	{TsCode: "600000.SH", TradeDate: 20240103, Open: 6.55, High: 6.60, Low: 6.52, Close: 6.58, Volume: 28764300, Amount: 188901234.56},
+	{TsCode: "600000.SH", TradeDate: 20240104, Open: 6.58, High: 6.62, Low: 6.55, Close: 6.57, Volume: 25698700, Amount: 169002345.67},
+	{TsCode: "600000.SH", TradeDate: 20240105, Open: 6.57, High: 6.75, Low: 6.56, Close: 6.71, Volume: 56789100, Amount: 379456789.01},
+}
+
+func TestKLineAggregator_MatchesUpstreamWeekly(t *testing.T) {
+	// 同一区间数据源返回的周K线：周四、周五两天的2023年第52周，和周二至周五的2024年第1周
+	upstream := []DailyData{
+		{TsCode: "600000.SH", TradeDate: 20231229, Open: 6.58, High: 6.70, Low: 6.56, Close: 6.63, Volume: 78991500, Amount: 524199999.99},
+		{TsCode: "600000.SH", TradeDate: 20240105, Open: 6.62, High: 6.75, Low: 6.52, Close: 6.71, Volume: 151264600, Amount: 1000369134.67},
+	}
+
+	// 输入顺序不影响结果
+	shuffled := []DailyData{upstreamDaily[3], upstreamDaily[0], upstreamDaily[5], upstreamDaily[1], upstreamDaily[4], upstreamDaily[2]}
+	assert.Equal(t, upstream, NewKLineAggregator(KLineBucketWeek).Aggregate(shuffled))
+}
+
+func TestKLineAggregator_MatchesUpstreamMonthlyAndYearly(t *testing.T) {
+	monthly := NewKLineAggregator(KLineBucketMonth).Aggregate(upstreamDaily)
+	require.Len(t, monthly, 2)
+	assert.Equal(t, DailyData{TsCode: "600000.SH", TradeDate: 20231229, Open: 6.58, High: 6.70, Low: 6.56, Close: 6.63,
+		Volume: 78991500, Amount: 524199999.99}, monthly[0])
+	assert.Equal(t, DailyData{TsCode: "600000.SH", TradeDate: 20240105, Open: 6.62, High: 6.75, Low: 6.52, Close: 6.71,
+		Volume: 151264600, Amount: 1000369134.67}, monthly[1])
+
+	// 跨年时季K、年K线与月K线在同一处断开
+	assert.Equal(t, monthly, NewKLineAggregator(KLineBucketQuarter).Aggregate(upstreamDaily))
+	assert.Equal(t, monthly, NewKLineAggregator(KLineBucketYear).Aggregate(upstreamDaily))
+}
+
+func TestKLineAggregator_ISOWeekAcrossYears(t *testing.T) {
+	daily := []DailyData{
+		{TsCode: "000001.SZ", TradeDate: 20251231, Open: 11.2, High: 11.5, Low: 11.1, Close: 11.4, Volume: 100, Amount: 1140},
+		{TsCode: "000001.SZ", TradeDate: 20260102, Open: 11.4, High: 11.8, Low: 11.3, Close: 11.7, Volume: 200, Amount: 2340},
+		{TsCode: "000001.SZ", TradeDate: 20260199, Open: 1, High: 1, Low: 1, Close: 1}, // 非法日期被忽略
+	}
+
+	weekly := NewKLineAggregator(KLineBucketWeek).Aggregate(daily)
+	require.Len(t, weekly, 1)
+	assert.Equal(t, 20260102, weekly[0].TradeDate)
+	assert.Equal(t, []float64{11.2, 11.8, 11.1, 11.7}, []float64{weekly[0].Open, weekly[0].High, weekly[0].Low, weekly[0].Close})
+	assert.Equal(t, int64(300), weekly[0].Volume)
+	assert.Equal(t, 3480.0, weekly[0].Amount)
+
+	assert.Len(t, NewKLineAggregator(KLineBucketMonth).Aggregate(daily), 2)
+	assert.Empty(t, NewKLineAggregator(KLineBucketWeek).Aggregate(nil))
+}
+
+func TestKLineBucket_StartDate(t *testing.T) {
+	assert.Equal(t, 20251229, KLineBucketWeek.StartDate(20260102))
+	assert.Equal(t, 20251229, KLineBucketWeek.StartDate(20251229))
+	assert.Equal(t, 20240101, KLineBucketWeek.StartDate(20240107))
+	assert.Equal(t, 20240201, KLineBucketMonth.StartDate(20240229))
+	assert.Equal(t, 20240701, KLineBucketQuarter.StartDate(20240930))
+	assert.Equal(t, 20240101, KLineBucketYear.StartDate(20241231))
+	assert.Equal(t, 0, KLineBucketWeek.StartDate(20240230))
+	assert.Equal(t, 0, KLineBucket("day").StartDate(20240102))
+}
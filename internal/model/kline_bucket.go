@@ -0,0 +1,85 @@
+package model
+
+// KLineBucket 周期K线的分桶方式
+//
+// 分桶约定：
+//   - 周期K线的交易日期为该期内最后一个有成交的交易日，尚在形成中的当期K线的交易日期随最新交易日变化；
+//   - 分桶只依据YYYYMMDD格式的交易日期计算，与服务器时区无关，“当期”由应用时区的今天日期决定；
+//   - 周按ISO周划分（周一至周日），跨月、跨年的一周属于同一期，例如2025-12-31和2026-01-02同属2026年第1周；
+//     月、季、年按自然月、自然季度、自然年划分，跨月的一周在月K线中分属两期；
+//   - 一只股票在同一期内最多保存一根K线，当期K线的交易日期变化时需删除旧日期的记录，否则同一期出现两根K线。
+type KLineBucket string
+
+// 支持的分桶方式
+const (
+	KLineBucketWeek    KLineBucket = "week"    // ISO周
+	KLineBucketMonth   KLineBucket = "month"   // 自然月
+	KLineBucketQuarter KLineBucket = "quarter" // 自然季度
+	KLineBucketYear    KLineBucket = "year"    // 自然年
+)
+
+// Key 获取交易日期所属的分桶编号，同一期的日期编号相同；周为ISO年*100+周数，月为年*100+月，季度为年*10+季度，年为年份
+// 日期不合法或分桶方式未知时返回0
+func (b KLineBucket) Key(tradeDate int) int {
+	if !ValidTradeDate(tradeDate) {
+		return 0
+	}
+	year, month := tradeDate/10000, tradeDate/100%100
+	switch b {
+	case KLineBucketWeek:
+		isoYear, week := tradeDateToTime(tradeDate).ISOWeek()
+		return isoYear*100 + week
+	case KLineBucketMonth:
+		return year*100 + month
+	case KLineBucketQuarter:
+		return year*10 + (month-1)/3 + 1
+	case KLineBucketYear:
+		return year
+	default:
+		return 0
+	}
+}
+
+// Same 判断两个交易日期是否属于同一期，任一日期不合法时返回false
+func (b KLineBucket) Same(date1, date2 int) bool {
+	key := b.Key(date1)
+	return key != 0 && key == b.Key(date2)
+}
+
+// IsForming 判断交易日期为barDate的K线是否为today所在的当期、仍在形成中的K线
+// today为应用时区的今天日期；barDate晚于today（数据异常）时不视为当期
+func (b KLineBucket) IsForming(barDate, today int) bool {
+	return barDate <= today && b.Same(barDate, today)
+}
+
+// ReplaceForming 判断数据源返回的当期K线（交易日期barDate）能否替换数据库中最新的当期K线（交易日期latestDate）
+// 返回的K线不属于today所在的当期（如新一期开盘前数据源仍返回上一期）或早于已保存的K线时不替换，保留原有K线；
+// 替换且交易日期变化时deleteStale为true，需删除latestDate的记录，避免同一期出现两根K线
+func (b KLineBucket) ReplaceForming(latestDate, barDate, today int) (replace, deleteStale bool) {
+	if barDate < latestDate || !b.IsForming(barDate, today) {
+		return false, false
+	}
+	return true, barDate != latestDate
+}
+
+// StartDate 获取交易日期所属一期的第一天（自然日，不一定是交易日），YYYYMMDD格式；周为该ISO周的周一
+// 日期不合法或分桶方式未知时返回0
+func (b KLineBucket) StartDate(tradeDate int) int {
+	if b.Key(tradeDate) == 0 {
+		return 0
+	}
+	year, month := tradeDate/10000, tradeDate/100%100
+	switch b {
+	case KLineBucketWeek:
+		t := tradeDateToTime(tradeDate)
+		offset := (int(t.Weekday()) + 6) % 7 // 周一为0
+		monday := t.AddDate(0, 0, -offset)
+		return monday.Year()*10000 + int(monday.Month())*100 + monday.Day()
+	case KLineBucketMonth:
+		return year*10000 + month*100 + 1
+	case KLineBucketQuarter:
+		return year*10000 + ((month-1)/3*3+1)*100 + 1
+	default:
+		return year*10000 + 101
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKLineBucket_Key(t *testing.T) {
+	cases := []struct {
+		bucket KLineBucket
+		date   int
+		want   int
+	}{
+		// 跨月的一周：2025-09-29（周一）至2025-10-03同属第40周
+		{KLineBucketWeek, 20250930, 202540},
+		{KLineBucketWeek, 20251003, 202540},
+		// 跨年的一周：2025-12-29（周一）属于2026年第1周
+		{KLineBucketWeek, 20251229, 202601},
+		{KLineBucketWeek, 20260102, 202601},
+		// 2021-01-01（周五）属于2020年第53周
+		{KLineBucketWeek, 20210101, 202053},
+		{KLineBucketMonth, 20250930, 202509},
+		{KLineBucketMonth, 20251001, 202510},
+		{KLineBucketQuarter, 20250331, 20251},
+		{KLineBucketQuarter, 20250401, 20252},
+		{KLineBucketQuarter, 20251231, 20254},
+		{KLineBucketYear, 20251231, 2025},
+		{KLineBucketYear, 20260102, 2026},
+		// 日期不合法或分桶方式未知
+		{KLineBucketMonth, 20251332, 0},
+		{KLineBucket("day"), 20250930, 0},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, tc.bucket.Key(tc.date), "%s %d", tc.bucket, tc.date)
+	}
+}
+
+func TestKLineBucket_IsFormingAtBoundaries(t *testing.T) {
+	cases := []struct {
+		name    string
+		bucket  KLineBucket
+		barDate int
+		today   int
+		want    bool
+	}{
+		{"周K线跨月仍为本周", KLineBucketWeek, 20250930, 20251003, true},
+		{"周K线跨年仍为本周", KLineBucketWeek, 20251231, 20260102, true},
+		{"上周五的周K线在周一已定型", KLineBucketWeek, 20251003, 20251006, false},
+		{"跨月的一周在月K线中分属两期", KLineBucketMonth, 20250930, 20251003, false},
+		{"月末当天的月K线仍为本月", KLineBucketMonth, 20250930, 20250930, true},
+		{"季末的季K线在下季度已定型", KLineBucketQuarter, 20250930, 20251009, false},
+		{"同季度不同月仍为本季", KLineBucketQuarter, 20251031, 20251205, true},
+		{"年末的年K线在新年已定型", KLineBucketYear, 20251231, 20260105, false},
+		{"K线日期晚于今天不视为当期", KLineBucketWeek, 20251003, 20251001, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.bucket.IsForming(tc.barDate, tc.today))
+		})
+	}
+}
+
+func TestKLineBucket_ReplaceForming(t *testing.T) {
+	cases := []struct {
+		name        string
+		bucket      KLineBucket
+		latestDate  int
+		barDate     int
+		today       int
+		replace     bool
+		deleteStale bool
+	}{
+		// 同一天重复执行：交易日期不变，覆盖更新即可
+		{"同日重复执行", KLineBucketWeek, 20251231, 20251231, 20251231, true, false},
+		// 跨年的一周中交易日期前移，需删除旧日期的记录，否则本周出现两根K线
+		{"跨年的一周交易日期前移", KLineBucketWeek, 20251231, 20260102, 20260102, true, true},
+		{"跨月的本月交易日期前移", KLineBucketMonth, 20251030, 20251031, 20251031, true, true},
+		// 新一期开盘前数据源仍返回上一期，保留原有K线，不制造缺口或重复
+		{"数据源仍返回上一期", KLineBucketMonth, 20251103, 20251031, 20251103, false, false},
+		{"数据源返回的K线早于已保存的", KLineBucketWeek, 20251002, 20251001, 20251002, false, false},
+		{"年K线跨年", KLineBucketYear, 20260105, 20251231, 20260105, false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			replace, deleteStale := tc.bucket.ReplaceForming(tc.latestDate, tc.barDate, tc.today)
+			assert.Equal(t, tc.replace, replace)
+			assert.Equal(t, tc.deleteStale, deleteStale)
+		})
+	}
+}
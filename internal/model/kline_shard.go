@@ -0,0 +1,42 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// K线分表的表名前缀，分表名为前缀加股票代码前三位，如：daily_data_600
+const (
+	DailyDataTablePrefix   = "daily_data"
+	WeeklyDataTablePrefix  = "weekly_data"
+	MonthlyDataTablePrefix = "monthly_data"
+)
+
+// KLineShardPrefixes 单独分表的股票代码前三位，其余代码的K线写入_other分表
+var KLineShardPrefixes = []string{"000", "001", "002", "300", "301", "600", "601", "603", "605", "688"}
+
+// klineShardOther 未单独分表的股票代码所在分表的后缀
+const klineShardOther = "other"
+
+// KLineShardTables 获取指定前缀的所有K线分表名
+func KLineShardTables(tablePrefix string) []string {
+	tables := make([]string, 0, len(KLineShardPrefixes)+1)
+	for _, prefix := range KLineShardPrefixes {
+		tables = append(tables, fmt.Sprintf("%s_%s", tablePrefix, prefix))
+	}
+	return append(tables, fmt.Sprintf("%s_%s", tablePrefix, klineShardOther))
+}
+
+// klineShardTable 根据股票代码前三位获取K线分表名
+func klineShardTable(tablePrefix, tsCode string) string {
+	// 处理带后缀的情况，如 "000001.SZ"
+	code := strings.Split(tsCode, ".")[0]
+	if len(code) >= 3 {
+		for _, prefix := range KLineShardPrefixes {
+			if code[:3] == prefix {
+				return fmt.Sprintf("%s_%s", tablePrefix, prefix)
+			}
+		}
+	}
+	return fmt.Sprintf("%s_%s", tablePrefix, klineShardOther)
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MarketMetric 可统计全市场分布的指标
+type MarketMetric string
+
+// 支持的指标，业绩类指标取各股票最新一期业绩报表，股东户数取最新一期股东户数
+const (
+	MetricROE           MarketMetric = "roe"            // 净资产收益率（每股收益/每股净资产），单位：%
+	MetricEPS           MarketMetric = "eps"            // 每股收益，单位：元
+	MetricBVPS          MarketMetric = "bvps"           // 每股净资产，单位：元
+	MetricRevenueYoY    MarketMetric = "revenue_yoy"    // 营业总收入同比增长，单位：%
+	MetricNetProfitYoY  MarketMetric = "net_profit_yoy" // 净利润同比增长，单位：%
+	MetricGrossMargin   MarketMetric = "gross_margin"   // 销售毛利率，单位：%
+	MetricDividendYield MarketMetric = "dividend_yield" // 股息率，单位：%
+	MetricHolderNum     MarketMetric = "holder_num"     // 股东户数，单位：户
+)
+
+// MarketMetrics 所有支持的指标
+var MarketMetrics = []MarketMetric{
+	MetricROE, MetricEPS, MetricBVPS, MetricRevenueYoY, MetricNetProfitYoY,
+	MetricGrossMargin, MetricDividendYield, MetricHolderNum,
+}
+
+// ParseMarketMetric 解析指标名称，不支持时返回列出可选指标的错误
+func ParseMarketMetric(value string) (MarketMetric, error) {
+	metric := MarketMetric(strings.ToLower(strings.TrimSpace(value)))
+	for _, m := range MarketMetrics {
+		if metric == m {
+			return metric, nil
+		}
+	}
+
+	names := make([]string, len(MarketMetrics))
+	for i, m := range MarketMetrics {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unsupported metric %q, valid options: %s", value, strings.Join(names, ", "))
+}
+
+// FromPerformance 从业绩报表取指标值，非业绩类指标或指标值未知（每股净资产无效时的ROE）时返回false
+func (m MarketMetric) FromPerformance(report PerformanceReport) (float64, bool) {
+	switch m {
+	case MetricROE:
+		return report.ROE()
+	case MetricEPS:
+		return report.EPS, true
+	case MetricBVPS:
+		return report.BVPS, true
+	case MetricRevenueYoY:
+		return report.RevenueYoY, true
+	case MetricNetProfitYoY:
+		return report.NetProfitYoY, true
+	case MetricGrossMargin:
+		return report.GrossMargin, true
+	case MetricDividendYield:
+		return report.DividendYield, true
+	default:
+		return 0, false
+	}
+}
+
+// MetricDistribution 指标在全市场的分布，分位数按线性插值计算
+type MetricDistribution struct {
+	Metric MarketMetric `json:"metric"` // 指标名称
+	Count  int          `json:"count"`  // 参与统计的股票数
+	Min    float64      `json:"min"`    // 最小值
+	P10    float64      `json:"p10"`    // 10%分位数
+	P25    float64      `json:"p25"`    // 25%分位数
+	Median float64      `json:"median"` // 中位数
+	P75    float64      `json:"p75"`    // 75%分位数
+	P90    float64      `json:"p90"`    // 90%分位数
+	Max    float64      `json:"max"`    // 最大值
+}
+
+// NewMetricDistribution 计算指标的分布，sorted为升序排列的指标值，为空时各分位数为0
+func NewMetricDistribution(metric MarketMetric, sorted []float64) MetricDistribution {
+	return MetricDistribution{
+		Metric: metric,
+		Count:  len(sorted),
+		Min:    Quantile(sorted, 0),
+		P10:    Quantile(sorted, 0.1),
+		P25:    Quantile(sorted, 0.25),
+		Median: Quantile(sorted, 0.5),
+		P75:    Quantile(sorted, 0.75),
+		P90:    Quantile(sorted, 0.9),
+		Max:    Quantile(sorted, 1),
+	}
+}
+
+// Quantile 计算升序数据的q分位数（0~1），在相邻两个值之间线性插值，数据为空时返回0
+func Quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// PercentileRank 计算value在升序数据中的百分位排名（0~100）：低于value的比例加上等于value的比例的一半，保留两位小数
+// 排名越高说明指标值在全市场越靠前；数据为空时返回0
+func PercentileRank(sorted []float64, value float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	below := sort.SearchFloat64s(sorted, value)
+	notAbove := sort.Search(len(sorted), func(i int) bool { return sorted[i] > value })
+	equal := notAbove - below
+	return roundPercent((float64(below) + float64(equal)/2) / float64(len(sorted)) * 100)
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	assert.Equal(t, 1.0, Quantile(sorted, 0))
+	assert.Equal(t, 2.0, Quantile(sorted, 0.1))
+	assert.Equal(t, 6.0, Quantile(sorted, 0.5))
+	assert.Equal(t, 11.0, Quantile(sorted, 1))
+
+	// 分位点落在两个值之间时线性插值
+	assert.InDelta(t, 2.5, Quantile([]float64{1, 2, 3, 4}, 0.5), 1e-9)
+	assert.InDelta(t, 1.75, Quantile([]float64{1, 2, 3, 4}, 0.25), 1e-9)
+
+	assert.Equal(t, 7.0, Quantile([]float64{7}, 0.9))
+	assert.Equal(t, 0.0, Quantile(nil, 0.5))
+}
+
+func TestNewMetricDistribution(t *testing.T) {
+	sorted := []float64{-5, 0, 2, 4, 6, 8, 10, 12, 14, 16, 30}
+	d := NewMetricDistribution(MetricROE, sorted)
+	assert.Equal(t, MetricDistribution{
+		Metric: MetricROE, Count: 11,
+		Min: -5, P10: 0, P25: 3, Median: 8, P75: 13, P90: 16, Max: 30,
+	}, d)
+
+	empty := NewMetricDistribution(MetricEPS, nil)
+	assert.Equal(t, 0, empty.Count)
+	assert.Equal(t, 0.0, empty.Median)
+}
+
+func TestPercentileRank(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, 5.0, PercentileRank(sorted, 1))
+	assert.Equal(t, 45.0, PercentileRank(sorted, 5))
+	assert.Equal(t, 95.0, PercentileRank(sorted, 10))
+	// 不在数据中的值按低于它的比例计算
+	assert.Equal(t, 50.0, PercentileRank(sorted, 5.5))
+	assert.Equal(t, 0.0, PercentileRank(sorted, 0))
+	assert.Equal(t, 100.0, PercentileRank(sorted, 11))
+
+	// 相同的值取中间排名
+	assert.Equal(t, 50.0, PercentileRank([]float64{3, 3, 3, 3}, 3))
+	assert.Equal(t, 66.67, PercentileRank([]float64{1, 2, 2}, 2))
+	assert.Equal(t, 0.0, PercentileRank(nil, 1))
+}
+
+func TestParseMarketMetric(t *testing.T) {
+	metric, err := ParseMarketMetric(" ROE ")
+	require.NoError(t, err)
+	assert.Equal(t, MetricROE, metric)
+
+	_, err = ParseMarketMetric("pe")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "holder_num")
+	_, err = ParseMarketMetric("")
+	assert.Error(t, err)
+}
+
+func TestMarketMetric_FromPerformance(t *testing.T) {
+	report := PerformanceReport{EPS: 1.5, BVPS: 10, GrossMargin: 35.2}
+	roe, ok := MetricROE.FromPerformance(report)
+	require.True(t, ok)
+	assert.InDelta(t, 15.0, roe, 1e-9)
+
+	margin, ok := MetricGrossMargin.FromPerformance(report)
+	require.True(t, ok)
+	assert.Equal(t, 35.2, margin)
+
+	// 每股净资产无效时ROE未知
+	_, ok = MetricROE.FromPerformance(PerformanceReport{EPS: 1.5})
+	assert.False(t, ok)
+	// 股东户数不来自业绩报表
+	_, ok = MetricHolderNum.FromPerformance(report)
+	assert.False(t, ok)
+}
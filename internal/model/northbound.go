@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// NorthboundHolding 个股北向资金（沪深港通）持股
+// 持股数据来自港交所每日披露的中央结算系统持股记录，数据日期为持股日期，通常在次一交易日公布；
+// 持股变化为相对上一披露日的变化，正数为北向资金增持
+type NorthboundHolding struct {
+	TsCode           string    `json:"ts_code" gorm:"column:ts_code;size:20;not null;primaryKey"`              // 股票代码，联合主键1
+	TradeDate        int       `json:"trade_date" gorm:"column:trade_date;not null;primaryKey;index"`          // 持股日期，YYYYMMDD格式，联合主键2
+	Close            float64   `json:"close" gorm:"column:close;type:decimal(10,3)"`                           // 当日收盘价，单位：元
+	ChangePct        float64   `json:"change_pct" gorm:"column:change_pct;type:decimal(10,2)"`                 // 当日涨跌幅，单位：%
+	HoldShares       int64     `json:"hold_shares" gorm:"column:hold_shares"`                                  // 持股数量，单位：股
+	HoldMarketCap    float64   `json:"hold_market_cap" gorm:"column:hold_market_cap;type:decimal(20,2)"`       // 持股市值，单位：元
+	FreeSharesRatio  float64   `json:"free_shares_ratio" gorm:"column:free_shares_ratio;type:decimal(10,4)"`   // 持股占流通股比例，单位：%
+	TotalSharesRatio float64   `json:"total_shares_ratio" gorm:"column:total_shares_ratio;type:decimal(10,4)"` // 持股占总股本比例，单位：%
+	ShareChange      int64     `json:"share_change" gorm:"column:share_change"`                                // 持股数量变化，单位：股
+	MarketCapChange  float64   `json:"market_cap_change" gorm:"column:market_cap_change;type:decimal(20,2)"`   // 持股市值变化，单位：元
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;type:datetime(3)"`                   // 记录创建时间
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at;type:datetime(3)"`                   // 记录更新时间
+}
+
+// TableName 指定表名
+func (NorthboundHolding) TableName() string {
+	return "northbound_holdings"
+}
+
+// GetTradeDate 获取持股日期
+func (h NorthboundHolding) GetTradeDate() int {
+	return h.TradeDate
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// PendingNotification 待重发的通知消息
+// 机器人发送失败的消息按机器人持久化到该表，由后台重试循环在恢复后补发，超过最长保留时间的消息直接丢弃
+type PendingNotification struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BotType   string    `json:"bot_type" gorm:"size:20;not null;index"` // 目标机器人类型
+	MsgType   string    `json:"msg_type" gorm:"size:20;not null"`       // 消息类型：text、markdown
+	Title     string    `json:"title" gorm:"size:200"`                  // Markdown消息标题
+	Content   string    `json:"content" gorm:"type:text"`               // 消息内容
+	AtMobiles string    `json:"at_mobiles" gorm:"size:500"`             // @的手机号，逗号分隔
+	AtAll     bool      `json:"at_all"`                                 // 是否@所有人
+	Attempts  int       `json:"attempts" gorm:"default:0"`              // 已重试次数
+	LastError string    `json:"last_error" gorm:"size:500"`             // 最近一次发送失败的原因
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PendingNotification) TableName() string {
+	return "pending_notifications"
+}
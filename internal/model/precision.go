@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// 价格和金额的标准精度，与K线表字段decimal(10,3)、decimal(20,2)一致
+// 各数据源返回的精度不同（同花顺K线为整数分，东方财富为字符串小数），采集器在解析时统一按标准精度取整，
+// 保证不同数据源的同一根K线写入相同的值
+const (
+	PricePrecision  = 3 // 价格保留3位小数，单位：元
+	AmountPrecision = 2 // 成交额保留2位小数，单位：元
+)
+
+var (
+	priceScale  = math.Pow10(PricePrecision)
+	amountScale = math.Pow10(AmountPrecision)
+)
+
+// RoundPrice 价格按标准精度四舍五入
+func RoundPrice(v float64) float64 {
+	return math.Round(v*priceScale) / priceScale
+}
+
+// RoundAmount 成交额按标准精度四舍五入
+func RoundAmount(v float64) float64 {
+	return math.Round(v*amountScale) / amountScale
+}
+
+// NormalizePrecision 将日K线的价格和成交额按标准精度取整
+func (d *DailyData) NormalizePrecision() {
+	d.Open = RoundPrice(d.Open)
+	d.High = RoundPrice(d.High)
+	d.Low = RoundPrice(d.Low)
+	d.Close = RoundPrice(d.Close)
+	d.Amount = RoundAmount(d.Amount)
+}
+
+// klineJSON K线序列化使用的结构，与DailyData字段相同但没有MarshalJSON方法，避免递归
+type klineJSON DailyData
+
+// marshalKLine 价格和成交额按标准精度取整后序列化
+// 数据库中为定点小数，读出为float64后直接序列化可能得到10.600000000000001这样的值，取整后输出与库中一致
+func marshalKLine(d DailyData) ([]byte, error) {
+	d.NormalizePrecision()
+	return json.Marshal(klineJSON(d))
+}
+
+// MarshalJSON 序列化时价格保留3位小数、成交额保留2位小数
+func (d DailyData) MarshalJSON() ([]byte, error) {
+	return marshalKLine(d)
+}
+
+// MarshalJSON 序列化时价格保留3位小数、成交额保留2位小数
+func (w WeeklyData) MarshalJSON() ([]byte, error) {
+	return marshalKLine(DailyData(w))
+}
+
+// MarshalJSON 序列化时价格保留3位小数、成交额保留2位小数
+func (m MonthlyData) MarshalJSON() ([]byte, error) {
+	return marshalKLine(DailyData(m))
+}
+
+// MarshalJSON 序列化时价格保留3位小数、成交额保留2位小数
+func (q QuarterlyData) MarshalJSON() ([]byte, error) {
+	return marshalKLine(DailyData(q))
+}
+
+// MarshalJSON 序列化时价格保留3位小数、成交额保留2位小数
+func (y YearlyData) MarshalJSON() ([]byte, error) {
+	return marshalKLine(DailyData(y))
+}
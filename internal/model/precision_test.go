@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyData_NormalizePrecision(t *testing.T) {
+	bar := DailyData{Open: 1.23449, High: 12.3456, Low: 0.1 + 0.2, Close: 10.0, Amount: 123456.784}
+	bar.NormalizePrecision()
+
+	assert.Equal(t, 1.234, bar.Open)
+	assert.Equal(t, 12.346, bar.High)
+	assert.Equal(t, 0.3, bar.Low)
+	assert.Equal(t, 10.0, bar.Close)
+	assert.Equal(t, 123456.78, bar.Amount)
+}
+
+func TestKLine_MarshalJSONRoundsPrecision(t *testing.T) {
+	// 运行时计算的0.1+0.2直接序列化为0.30000000000000004
+	a, b := 0.1, 0.2
+	noisy := a + b
+	raw, err := json.Marshal(noisy)
+	require.NoError(t, err)
+	require.Equal(t, "0.30000000000000004", string(raw))
+
+	bar := DailyData{TsCode: "600000.SH", TradeDate: 20250910, Open: 10.5 + noisy, High: 11, Low: noisy, Close: 10.6, Volume: 100, Amount: noisy * 3}
+	data, err := json.Marshal(bar)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"open":10.8,"high":11,"low":0.3,"close":10.6,"volume":100,"amount":0.9`)
+	assert.Equal(t, 10.5+noisy, bar.Open, "不修改原值")
+
+	// 各周期K线和嵌套在响应中的K线同样取整
+	data, err = json.Marshal(map[string]interface{}{"kline": []WeeklyData{WeeklyData(bar)}, "yearly": &YearlyData{Low: noisy}})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"low":0.3,"close":10.6`)
+	assert.NotContains(t, string(data), "0.30000000000000004")
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// 各板块的日涨跌幅限制比例
+const (
+	MainBoardPriceLimit = 0.10 // 主板
+	STPriceLimit        = 0.05 // 主板风险警示（ST、*ST）股票
+	GrowthPriceLimit    = 0.20 // 创业板、科创板
+	BeijingPriceLimit   = 0.30 // 北交所
+)
+
+const (
+	// chiNextReformDate 创业板注册制改革首日，此后涨跌幅限制由10%放宽至20%
+	chiNextReformDate = 20200824
+	// priceAnomalyTolerance 判定价格异常时在涨跌幅限制之上预留的余量，吸收价格精度和四舍五入误差
+	priceAnomalyTolerance = 0.01
+	// listingNoLimitDays 新股上市后不设涨跌幅限制的交易日数（创业板、科创板前5日，主板首日），统一按5日跳过
+	listingNoLimitDays = 5
+)
+
+// PriceLimitRatio 获取股票在指定交易日适用的日涨跌幅限制比例
+// 按代码判断板块：北交所30%，科创板20%，创业板注册制改革后20%、此前10%，主板10%、其中ST股票5%
+func PriceLimitRatio(tsCode, name string, tradeDate int) float64 {
+	symbol, market, _ := strings.Cut(strings.ToUpper(tsCode), ".")
+	switch {
+	case market == "BJ":
+		return BeijingPriceLimit
+	case market == "SH" && strings.HasPrefix(symbol, "688"), market == "SH" && strings.HasPrefix(symbol, "689"):
+		return GrowthPriceLimit
+	case market == "SZ" && strings.HasPrefix(symbol, "30"):
+		if tradeDate >= chiNextReformDate {
+			return GrowthPriceLimit
+		}
+		return MainBoardPriceLimit
+	case strings.Contains(strings.ToUpper(name), "ST"):
+		return STPriceLimit
+	default:
+		return MainBoardPriceLimit
+	}
+}
+
+// PriceAnomaly 超出涨跌幅限制的日间价格跳变，通常是除权除息后历史数据未重新复权导致
+type PriceAnomaly struct {
+	TradeDate     int     `json:"trade_date"`      // 发生跳变的交易日期，YYYYMMDD格式
+	PrevTradeDate int     `json:"prev_trade_date"` // 上一交易日期，YYYYMMDD格式
+	PrevClose     float64 `json:"prev_close"`      // 上一交易日收盘价
+	Close         float64 `json:"close"`           // 当日收盘价
+	ChangePct     float64 `json:"change_pct"`      // 收盘价涨跌幅，单位：%
+	LimitPct      float64 `json:"limit_pct"`       // 当日适用的涨跌幅限制，单位：%
+}
+
+// FindPriceAnomalies 找出日K线中收盘价涨跌幅超过板块涨跌幅限制（含1%余量）的交易日
+// 真实行情的涨跌幅不会超过限制，超出的跳变视为复权数据失真；新股上市初期不设涨跌幅限制，
+// 上市日期已知且数据覆盖上市首日时跳过最初5个交易日。输入数据无需排序，结果按交易日期升序返回
+func FindPriceAnomalies(stock Stock, data []DailyData) []PriceAnomaly {
+	sorted := make([]DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeDate < sorted[j].TradeDate })
+
+	skipUntil := listingSkipUntil(stock, sorted)
+	anomalies := make([]PriceAnomaly, 0)
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		if i < skipUntil || prev.Close <= 0 || cur.Close <= 0 {
+			continue
+		}
+
+		limit := PriceLimitRatio(stock.TsCode, stock.Name, cur.TradeDate)
+		change := cur.Close/prev.Close - 1
+		if math.Abs(change) <= limit+priceAnomalyTolerance {
+			continue
+		}
+
+		anomalies = append(anomalies, PriceAnomaly{
+			TradeDate:     cur.TradeDate,
+			PrevTradeDate: prev.TradeDate,
+			PrevClose:     prev.Close,
+			Close:         cur.Close,
+			ChangePct:     math.Round(change*10000) / 100,
+			LimitPct:      limit * 100,
+		})
+	}
+	return anomalies
+}
+
+// listingSkipUntil 上市日期已知且升序数据覆盖上市首日时，返回上市初期不设涨跌幅限制的K线之后的第一个下标，否则返回0
+func listingSkipUntil(stock Stock, sorted []DailyData) int {
+	if stock.ListDate == nil || stock.ListDate.IsZero() {
+		return 0
+	}
+	listDate := stock.ListDate.Year()*10000 + int(stock.ListDate.Month())*100 + stock.ListDate.Day()
+	for i, d := range sorted {
+		if d.TradeDate >= listDate {
+			return i + listingNoLimitDays
+		}
+	}
+	return 0
+}
+
+// LimitType 涨跌停类型
+type LimitType string
+
+const (
+	LimitUp   LimitType = "limit_up"   // 涨停
+	LimitDown LimitType = "limit_down" // 跌停
+)
+
+// LimitDay 收盘价封于涨停价或跌停价的交易日
+type LimitDay struct {
+	TradeDate      int       `json:"trade_date"`       // 交易日期，YYYYMMDD格式
+	Type           LimitType `json:"type"`             // 涨停或跌停
+	PrevClose      float64   `json:"prev_close"`       // 上一交易日收盘价
+	Close          float64   `json:"close"`            // 当日收盘价
+	LimitUpPrice   float64   `json:"limit_up_price"`   // 当日涨停价
+	LimitDownPrice float64   `json:"limit_down_price"` // 当日跌停价
+	ChangePct      float64   `json:"change_pct"`       // 收盘价涨跌幅，单位：%
+	LimitPct       float64   `json:"limit_pct"`        // 当日适用的涨跌幅限制，单位：%
+}
+
+// StockLimitDay 全市场涨跌停查询中单只股票的涨跌停记录
+type StockLimitDay struct {
+	TsCode string `json:"ts_code"` // 股票代码
+	Name   string `json:"name"`    // 股票名称
+	LimitDay
+}
+
+// LimitPrices 按上一交易日收盘价和涨跌幅限制比例计算涨停价和跌停价，与交易所规则一致四舍五入到分
+func LimitPrices(prevClose, ratio float64) (up, down float64) {
+	up = math.Round(prevClose*(1+ratio)*100) / 100
+	down = math.Round(prevClose*(1-ratio)*100) / 100
+	return up, down
+}
+
+// DetectLimitDay 判断当日K线是否收于涨停价或跌停价：收盘价四舍五入到分后与涨停价（跌停价）相等
+// 板块涨跌幅限制由PriceLimitRatio确定，ST按股票当前名称判断；上市初期不设涨跌幅限制的交易日由调用方排除
+func DetectLimitDay(stock Stock, prevClose float64, bar DailyData) (LimitDay, bool) {
+	if prevClose <= 0 || bar.Close <= 0 {
+		return LimitDay{}, false
+	}
+
+	ratio := PriceLimitRatio(stock.TsCode, stock.Name, bar.TradeDate)
+	up, down := LimitPrices(prevClose, ratio)
+	closeCents := math.Round(bar.Close * 100)
+
+	day := LimitDay{
+		TradeDate:      bar.TradeDate,
+		PrevClose:      prevClose,
+		Close:          bar.Close,
+		LimitUpPrice:   up,
+		LimitDownPrice: down,
+		ChangePct:      math.Round((bar.Close/prevClose-1)*10000) / 100,
+		LimitPct:       ratio * 100,
+	}
+	switch closeCents {
+	case math.Round(up * 100):
+		day.Type = LimitUp
+	case math.Round(down * 100):
+		day.Type = LimitDown
+	default:
+		return LimitDay{}, false
+	}
+	return day, true
+}
+
+// FindLimitDays 找出日K线中收于涨停价或跌停价的交易日，上一交易日收盘价取前一根K线
+// 上市日期已知且数据覆盖上市首日时跳过最初5个交易日。输入数据无需排序，结果按交易日期升序返回
+func FindLimitDays(stock Stock, data []DailyData) []LimitDay {
+	sorted := make([]DailyData, len(data))
+	copy(sorted, data)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeDate < sorted[j].TradeDate })
+
+	skipUntil := listingSkipUntil(stock, sorted)
+	days := make([]LimitDay, 0)
+	for i := 1; i < len(sorted); i++ {
+		if i < skipUntil {
+			continue
+		}
+		if day, ok := DetectLimitDay(stock, sorted[i-1].Close, sorted[i]); ok {
+			days = append(days, day)
+		}
+	}
+	return days
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceLimitRatio(t *testing.T) {
+	assert.Equal(t, MainBoardPriceLimit, PriceLimitRatio("600519.SH", "贵州茅台", 20240102))
+	assert.Equal(t, MainBoardPriceLimit, PriceLimitRatio("000001.SZ", "平安银行", 20240102))
+	assert.Equal(t, STPriceLimit, PriceLimitRatio("600001.SH", "*ST邯钢", 20240102))
+	assert.Equal(t, GrowthPriceLimit, PriceLimitRatio("688981.SH", "中芯国际", 20240102))
+	assert.Equal(t, GrowthPriceLimit, PriceLimitRatio("300750.SZ", "宁德时代", 20240102))
+	// 创业板注册制改革前为10%
+	assert.Equal(t, MainBoardPriceLimit, PriceLimitRatio("300750.SZ", "宁德时代", 20200821))
+	// 创业板ST股票同样为20%
+	assert.Equal(t, GrowthPriceLimit, PriceLimitRatio("300001.SZ", "ST特锐", 20240102))
+	assert.Equal(t, BeijingPriceLimit, PriceLimitRatio("830799.BJ", "艾融软件", 20240102))
+}
+
+func TestFindPriceAnomalies(t *testing.T) {
+	bars := func(tsCode string, closes ...float64) []DailyData {
+		data := make([]DailyData, 0, len(closes))
+		for i, c := range closes {
+			data = append(data, DailyData{TsCode: tsCode, TradeDate: 20240102 + i, Close: c})
+		}
+		return data
+	}
+
+	t.Run("normal move", func(t *testing.T) {
+		stock := Stock{TsCode: "600519.SH", Name: "贵州茅台"}
+		assert.Empty(t, FindPriceAnomalies(stock, bars(stock.TsCode, 100, 103, 98.5, 101)))
+	})
+
+	t.Run("limit up is a genuine move", func(t *testing.T) {
+		stock := Stock{TsCode: "600519.SH", Name: "贵州茅台"}
+		assert.Empty(t, FindPriceAnomalies(stock, bars(stock.TsCode, 10, 11, 12.1, 10.89)))
+
+		growth := Stock{TsCode: "300750.SZ", Name: "宁德时代"}
+		assert.Empty(t, FindPriceAnomalies(growth, bars(growth.TsCode, 10, 12, 9.6)))
+	})
+
+	t.Run("adjustment sized gap", func(t *testing.T) {
+		stock := Stock{TsCode: "600519.SH", Name: "贵州茅台"}
+		// 10送5后未重新复权，收盘价从30跳到20
+		data := bars(stock.TsCode, 30, 30.3, 20, 20.2)
+		anomalies := FindPriceAnomalies(stock, data)
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, PriceAnomaly{
+			TradeDate:     20240104,
+			PrevTradeDate: 20240103,
+			PrevClose:     30.3,
+			Close:         20,
+			ChangePct:     -33.99,
+			LimitPct:      10,
+		}, anomalies[0])
+
+		// 同样幅度的跳变对北交所股票也超出30%的限制，输入乱序不影响结果
+		bj := Stock{TsCode: "830799.BJ"}
+		data = bars(bj.TsCode, 30, 30.3, 20, 20.2)
+		data[0], data[3] = data[3], data[0]
+		anomalies = FindPriceAnomalies(bj, data)
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, 20240104, anomalies[0].TradeDate)
+	})
+
+	t.Run("ST gap above 5 percent", func(t *testing.T) {
+		stock := Stock{TsCode: "600001.SH", Name: "ST邯钢"}
+		assert.Empty(t, FindPriceAnomalies(stock, bars(stock.TsCode, 10, 10.5)))
+		assert.Len(t, FindPriceAnomalies(stock, bars(stock.TsCode, 10, 10.8)), 1)
+	})
+
+	t.Run("listing days have no limit", func(t *testing.T) {
+		listDate := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+		stock := Stock{TsCode: "688001.SH", Name: "华兴源创", ListDate: &listDate}
+		// 上市首日之后的前几日大幅波动不视为异常，第6个交易日起恢复检查
+		data := bars(stock.TsCode, 50, 80, 60, 90, 70, 72, 40)
+		anomalies := FindPriceAnomalies(stock, data)
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, 20240108, anomalies[0].TradeDate)
+	})
+}
+
+func TestLimitPrices(t *testing.T) {
+	up, down := LimitPrices(10, MainBoardPriceLimit)
+	assert.Equal(t, 11.0, up)
+	assert.Equal(t, 9.0, down)
+
+	// 四舍五入到分：12.35*1.05=12.9675，12.35*0.95=11.7325
+	up, down = LimitPrices(12.35, STPriceLimit)
+	assert.Equal(t, 12.97, up)
+	assert.Equal(t, 11.73, down)
+}
+
+func TestFindLimitDays(t *testing.T) {
+	bars := func(tsCode string, closes ...float64) []DailyData {
+		data := make([]DailyData, 0, len(closes))
+		for i, c := range closes {
+			data = append(data, DailyData{TsCode: tsCode, TradeDate: 20240102 + i, Close: c})
+		}
+		return data
+	}
+
+	t.Run("main board 10 percent", func(t *testing.T) {
+		stock := Stock{TsCode: "600519.SH", Name: "贵州茅台"}
+		// 11为涨停，10.89未封板，9.8为跌停
+		days := FindLimitDays(stock, bars(stock.TsCode, 10, 11, 10.89, 9.8))
+		require.Len(t, days, 2)
+		assert.Equal(t, LimitDay{
+			TradeDate:      20240103,
+			Type:           LimitUp,
+			PrevClose:      10,
+			Close:          11,
+			LimitUpPrice:   11,
+			LimitDownPrice: 9,
+			ChangePct:      10,
+			LimitPct:       10,
+		}, days[0])
+		assert.Equal(t, LimitDown, days[1].Type)
+		assert.Equal(t, 20240105, days[1].TradeDate)
+
+		// 5%的涨幅对主板不是涨停
+		assert.Empty(t, FindLimitDays(stock, bars(stock.TsCode, 10, 10.5)))
+	})
+
+	t.Run("ST 5 percent", func(t *testing.T) {
+		stock := Stock{TsCode: "600001.SH", Name: "*ST邯钢"}
+		days := FindLimitDays(stock, bars(stock.TsCode, 10, 10.5, 9.98))
+		require.Len(t, days, 2)
+		assert.Equal(t, LimitUp, days[0].Type)
+		assert.Equal(t, 5.0, days[0].LimitPct)
+		assert.Equal(t, LimitDown, days[1].Type)
+	})
+
+	t.Run("ChiNext 20 percent after reform", func(t *testing.T) {
+		stock := Stock{TsCode: "300750.SZ", Name: "宁德时代"}
+		days := FindLimitDays(stock, bars(stock.TsCode, 10, 12, 11))
+		require.Len(t, days, 1)
+		assert.Equal(t, LimitUp, days[0].Type)
+		assert.Equal(t, 20.0, days[0].LimitPct)
+
+		// 10%的涨幅对注册制后的创业板不是涨停
+		assert.Empty(t, FindLimitDays(stock, bars(stock.TsCode, 10, 11)))
+
+		// 注册制改革前仍为10%
+		before := []DailyData{
+			{TsCode: stock.TsCode, TradeDate: 20200820, Close: 10},
+			{TsCode: stock.TsCode, TradeDate: 20200821, Close: 11},
+		}
+		days = FindLimitDays(stock, before)
+		require.Len(t, days, 1)
+		assert.Equal(t, 10.0, days[0].LimitPct)
+	})
+
+	t.Run("STAR market 20 percent", func(t *testing.T) {
+		stock := Stock{TsCode: "688981.SH", Name: "中芯国际"}
+		days := FindLimitDays(stock, bars(stock.TsCode, 50, 40))
+		require.Len(t, days, 1)
+		assert.Equal(t, LimitDown, days[0].Type)
+		assert.Equal(t, 40.0, days[0].LimitDownPrice)
+	})
+
+	t.Run("Beijing 30 percent", func(t *testing.T) {
+		stock := Stock{TsCode: "830799.BJ", Name: "艾融软件"}
+		days := FindLimitDays(stock, bars(stock.TsCode, 10, 13, 12))
+		require.Len(t, days, 1)
+		assert.Equal(t, LimitUp, days[0].Type)
+		assert.Equal(t, 13.0, days[0].LimitUpPrice)
+	})
+
+	t.Run("listing days have no limit", func(t *testing.T) {
+		listDate := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+		stock := Stock{TsCode: "688001.SH", Name: "华兴源创", ListDate: &listDate}
+		// 上市初期恰好上涨20%不视为涨停，第6个交易日起恢复判断
+		data := bars(stock.TsCode, 50, 60, 60, 60, 60, 72)
+		days := FindLimitDays(stock, data)
+		require.Len(t, days, 1)
+		assert.Equal(t, 20240107, days[0].TradeDate)
+	})
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// RealtimeSnapshot 个股最近一次采集的实时行情，每只股票只保留一条
+// 收盘后或数据源不可用时可直接返回最后一次采集的行情，不必再请求数据源
+type RealtimeSnapshot struct {
+	TsCode    string    `json:"ts_code" gorm:"column:ts_code;size:20;not null;primaryKey"` // 股票代码，如：000001.SZ
+	TradeDate int       `json:"trade_date" gorm:"column:trade_date;not null"`              // 行情所属交易日期，YYYYMMDD格式
+	Open      float64   `json:"open" gorm:"column:open;type:decimal(10,3)"`                // 开盘价，单位：元
+	High      float64   `json:"high" gorm:"column:high;type:decimal(10,3)"`                // 最高价，单位：元
+	Low       float64   `json:"low" gorm:"column:low;type:decimal(10,3)"`                  // 最低价，单位：元
+	Close     float64   `json:"close" gorm:"column:close;type:decimal(10,3)"`              // 最新价，单位：元
+	Volume    int64     `json:"volume" gorm:"column:volume"`                               // 成交量，单位：股
+	Amount    float64   `json:"amount" gorm:"column:amount;type:decimal(20,2)"`            // 成交额，单位：元
+	FetchedAt time.Time `json:"fetched_at" gorm:"column:fetched_at;type:datetime(3)"`      // 采集时间
+}
+
+// TableName 指定表名
+func (RealtimeSnapshot) TableName() string {
+	return "realtime_snapshots"
+}
+
+// NewRealtimeSnapshot 由实时行情生成快照
+func NewRealtimeSnapshot(data DailyData, fetchedAt time.Time) RealtimeSnapshot {
+	return RealtimeSnapshot{
+		TsCode:    data.TsCode,
+		TradeDate: data.TradeDate,
+		Open:      data.Open,
+		High:      data.High,
+		Low:       data.Low,
+		Close:     data.Close,
+		Volume:    data.Volume,
+		Amount:    data.Amount,
+		FetchedAt: fetchedAt,
+	}
+}
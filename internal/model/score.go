@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// StockScore 股票每日综合评分
+// 综合评分由技术面、基本面和筹码集中度按权重合成，取值0-100；数据不足的维度记为空，不参与合成
+type StockScore struct {
+	TsCode           string    `json:"ts_code" gorm:"column:ts_code;size:20;not null;primaryKey"`           // 股票代码，联合主键1
+	TradeDate        int       `json:"trade_date" gorm:"column:trade_date;not null;primaryKey;index"`       // 评分日期，YYYYMMDD格式，联合主键2
+	Score            float64   `json:"score" gorm:"column:score;type:decimal(5,2);not null;index"`          // 综合评分
+	TechnicalScore   *float64  `json:"technical_score" gorm:"column:technical_score;type:decimal(5,2)"`     // 技术面评分
+	FundamentalScore *float64  `json:"fundamental_score" gorm:"column:fundamental_score;type:decimal(5,2)"` // 基本面评分
+	ShareholderScore *float64  `json:"shareholder_score" gorm:"column:shareholder_score;type:decimal(5,2)"` // 筹码集中度评分
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;type:datetime(3)"`                // 记录创建时间
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at;type:datetime(3)"`                // 记录更新时间
+}
+
+// TableName 指定表名
+func (StockScore) TableName() string {
+	return "stock_scores"
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// factorSumTolerance 因子得分合计与总分比较时允许的误差，吸收评分保留4位小数带来的舍入
+const factorSumTolerance = 0.01
+
+// FactorScores 选股结果的分因子得分，键为因子名称（如macd_signal、rsi、eps_growth），值为该因子对总分的贡献
+type FactorScores map[string]float64
+
+// Value 实现driver.Valuer接口
+func (f FactorScores) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan 实现sql.Scanner接口
+func (f *FactorScores) Scan(value interface{}) error {
+	if value == nil {
+		*f = make(FactorScores)
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into FactorScores", value)
+	}
+
+	return json.Unmarshal(bytes, f)
+}
+
+// Total 各因子得分合计
+func (f FactorScores) Total() float64 {
+	total := 0.0
+	for _, score := range f {
+		total += score
+	}
+	return total
+}
+
+// NewSelectionResult 根据分因子得分创建选股结果，选股评分取各因子得分合计
+func NewSelectionResult(strategyName, tsCode string, selectionDate time.Time, factors FactorScores, reason string) SelectionResult {
+	return SelectionResult{
+		StrategyName:  strategyName,
+		TsCode:        tsCode,
+		SelectionDate: selectionDate,
+		Score:         math.Round(factors.Total()*10000) / 10000,
+		Reason:        reason,
+		Factors:       factors,
+	}
+}
+
+// FactorContribution 单个因子对选股总分的贡献
+type FactorContribution struct {
+	Factor string  `json:"factor"` // 因子名称
+	Score  float64 `json:"score"`  // 因子得分，负数表示扣分
+}
+
+// SelectionExplanation 选股结果的分因子解释
+type SelectionExplanation struct {
+	ID            uint                 `json:"id"`             // 选股结果ID
+	StrategyName  string               `json:"strategy_name"`  // 选股策略名称
+	TsCode        string               `json:"ts_code"`        // 股票代码
+	SelectionDate string               `json:"selection_date"` // 选股日期，YYYY-MM-DD格式
+	Score         float64              `json:"score"`          // 选股总分
+	Reason        string               `json:"reason"`         // 选股理由
+	Factors       []FactorContribution `json:"factors"`        // 按贡献绝对值降序排列的因子得分
+	FactorSum     float64              `json:"factor_sum"`     // 因子得分合计
+	Consistent    bool                 `json:"consistent"`     // 因子得分合计是否与总分一致，旧记录未保存因子时为false
+}
+
+// Explain 将选股结果分解为各因子的贡献，便于查看入选原因
+func (r *SelectionResult) Explain() SelectionExplanation {
+	factors := make([]FactorContribution, 0, len(r.Factors))
+	for name, score := range r.Factors {
+		factors = append(factors, FactorContribution{Factor: name, Score: score})
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		ai, aj := math.Abs(factors[i].Score), math.Abs(factors[j].Score)
+		if ai != aj {
+			return ai > aj
+		}
+		return factors[i].Factor < factors[j].Factor
+	})
+
+	sum := r.Factors.Total()
+	explanation := SelectionExplanation{
+		ID:           r.ID,
+		StrategyName: r.StrategyName,
+		TsCode:       r.TsCode,
+		Score:        r.Score,
+		Reason:       r.Reason,
+		Factors:      factors,
+		FactorSum:    math.Round(sum*10000) / 10000,
+		Consistent:   len(factors) > 0 && math.Abs(sum-r.Score) <= factorSumTolerance,
+	}
+	if !r.SelectionDate.IsZero() {
+		explanation.SelectionDate = r.SelectionDate.Format("2006-01-02")
+	}
+	return explanation
+}
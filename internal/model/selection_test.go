@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectionResult_FactorSumEqualsScore(t *testing.T) {
+	factors := FactorScores{"macd_signal": 20, "rsi": 15, "eps_growth": 25, "volume": 12.35, "valuation": -4.2}
+	result := NewSelectionResult("combined", "600519.SH", time.Date(2025, 9, 30, 0, 0, 0, 0, time.Local), factors, "技术面和基本面共振")
+	result.ID = 7
+
+	assert.InDelta(t, 68.15, result.Score, 1e-9)
+
+	explanation := result.Explain()
+	assert.Equal(t, uint(7), explanation.ID)
+	assert.Equal(t, "2025-09-30", explanation.SelectionDate)
+	assert.InDelta(t, explanation.Score, explanation.FactorSum, 1e-9)
+	assert.True(t, explanation.Consistent)
+
+	sum := 0.0
+	for _, f := range explanation.Factors {
+		sum += f.Score
+	}
+	assert.InDelta(t, result.Score, sum, 1e-9)
+
+	// 按贡献绝对值降序，扣分因子同样参与排序
+	require.Len(t, explanation.Factors, 5)
+	assert.Equal(t, "eps_growth", explanation.Factors[0].Factor)
+	assert.Equal(t, "valuation", explanation.Factors[4].Factor)
+	assert.Equal(t, -4.2, explanation.Factors[4].Score)
+}
+
+func TestSelectionResult_ExplainWithoutFactors(t *testing.T) {
+	// 旧记录只有文字理由，没有分因子得分
+	result := SelectionResult{StrategyName: "technical", TsCode: "000001.SZ", Score: 85.6, Reason: "技术指标良好"}
+
+	explanation := result.Explain()
+	assert.Empty(t, explanation.Factors)
+	assert.False(t, explanation.Consistent)
+	assert.Equal(t, "", explanation.SelectionDate)
+}
+
+func TestFactorScores_ValueScan(t *testing.T) {
+	factors := FactorScores{"rsi": 15, "macd_signal": 20}
+	value, err := factors.Value()
+	require.NoError(t, err)
+
+	var scanned FactorScores
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, factors, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Empty(t, scanned)
+	assert.Error(t, scanned.Scan(42))
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// maxDiscrepancyDetails 跨数据源核对报告中最多保留的差异明细条数，汇总不受限制
+const maxDiscrepancyDetails = 200
+
+// 跨数据源核对的K线字段
+const (
+	DiscrepancyFieldOpen   = "open"
+	DiscrepancyFieldHigh   = "high"
+	DiscrepancyFieldLow    = "low"
+	DiscrepancyFieldClose  = "close"
+	DiscrepancyFieldVolume = "volume"
+	DiscrepancyFieldAmount = "amount"
+)
+
+// discrepancyFields 核对的字段及取值方式，按报告中的顺序排列
+var discrepancyFields = []struct {
+	name  string
+	value func(DailyData) float64
+}{
+	{DiscrepancyFieldOpen, func(d DailyData) float64 { return d.Open }},
+	{DiscrepancyFieldHigh, func(d DailyData) float64 { return d.High }},
+	{DiscrepancyFieldLow, func(d DailyData) float64 { return d.Low }},
+	{DiscrepancyFieldClose, func(d DailyData) float64 { return d.Close }},
+	{DiscrepancyFieldVolume, func(d DailyData) float64 { return float64(d.Volume) }},
+	{DiscrepancyFieldAmount, func(d DailyData) float64 { return d.Amount }},
+}
+
+// SourceDiscrepancy 同一只股票同一交易日两个数据源的K线在某个字段上的差异
+type SourceDiscrepancy struct {
+	TsCode    string  `json:"ts_code"`    // 股票代码
+	TradeDate int     `json:"trade_date"` // 交易日期，YYYYMMDD格式
+	Field     string  `json:"field"`      // 字段：open、high、low、close、volume、amount
+	Primary   float64 `json:"primary"`    // 主数据源的值
+	Secondary float64 `json:"secondary"`  // 对比数据源的值
+	RelDiff   float64 `json:"rel_diff"`   // 相对差异，|primary-secondary|/max(|primary|,|secondary|)
+}
+
+// DiscrepancyFieldSummary 单个字段的差异汇总
+type DiscrepancyFieldSummary struct {
+	Field      string  `json:"field"`        // 字段名
+	Compared   int     `json:"compared"`     // 两个数据源都有值的K线数，任一方为0（如同花顺all.js没有成交额）时不比较
+	Mismatched int     `json:"mismatched"`   // 相对差异超过容差的K线数
+	Stocks     int     `json:"stocks"`       // 存在差异的股票数
+	MaxRelDiff float64 `json:"max_rel_diff"` // 最大相对差异
+}
+
+// SourceDiscrepancyReport 跨数据源K线核对报告
+// 同一字段在大量股票上持续出现差异通常是解析问题（如同花顺差值解码）或复权方式不同，个别股票偶发差异多为数据源更正
+type SourceDiscrepancyReport struct {
+	Primary       string                    `json:"primary"`       // 主数据源名称
+	Secondary     string                    `json:"secondary"`     // 对比数据源名称
+	Tolerance     float64                   `json:"tolerance"`     // 相对差异容差，超过时计为差异
+	Stocks        int                       `json:"stocks"`        // 完成核对的股票数
+	Failed        map[string]string         `json:"failed"`        // 获取失败的股票及原因
+	MatchedBars   int                       `json:"matched_bars"`  // 两个数据源都有的K线数
+	MissingBars   int                       `json:"missing_bars"`  // 只有一个数据源有的K线数
+	Fields        []DiscrepancyFieldSummary `json:"fields"`        // 各字段的差异汇总
+	Discrepancies []SourceDiscrepancy       `json:"discrepancies"` // 差异明细，按相对差异降序，最多200条
+	Truncated     bool                      `json:"truncated"`     // 差异明细是否被截断
+}
+
+// NewSourceDiscrepancyReport 创建空的跨数据源核对报告
+func NewSourceDiscrepancyReport(primary, secondary string, tolerance float64) *SourceDiscrepancyReport {
+	fields := make([]DiscrepancyFieldSummary, len(discrepancyFields))
+	for i, field := range discrepancyFields {
+		fields[i].Field = field.name
+	}
+	return &SourceDiscrepancyReport{
+		Primary:       primary,
+		Secondary:     secondary,
+		Tolerance:     tolerance,
+		Failed:        make(map[string]string),
+		Fields:        fields,
+		Discrepancies: []SourceDiscrepancy{},
+	}
+}
+
+// AddFailure 记录获取失败的股票
+func (r *SourceDiscrepancyReport) AddFailure(tsCode string, err error) {
+	r.Failed[tsCode] = err.Error()
+}
+
+// AddStock 按交易日期对齐一只股票两个数据源的K线并累计差异
+func (r *SourceDiscrepancyReport) AddStock(tsCode string, primary, secondary []DailyData) {
+	r.Stocks++
+
+	secondaryByDate := make(map[int]DailyData, len(secondary))
+	for _, bar := range secondary {
+		secondaryByDate[bar.TradeDate] = bar
+	}
+
+	mismatchedFields := make([]bool, len(discrepancyFields))
+	for _, a := range primary {
+		b, ok := secondaryByDate[a.TradeDate]
+		if !ok {
+			r.MissingBars++
+			continue
+		}
+		delete(secondaryByDate, a.TradeDate)
+		r.MatchedBars++
+
+		for i, field := range discrepancyFields {
+			va, vb := field.value(a), field.value(b)
+			if va == 0 || vb == 0 {
+				continue
+			}
+			summary := &r.Fields[i]
+			summary.Compared++
+			diff := relativeDiff(va, vb)
+			if diff <= r.Tolerance {
+				continue
+			}
+			summary.Mismatched++
+			summary.MaxRelDiff = math.Max(summary.MaxRelDiff, diff)
+			mismatchedFields[i] = true
+			r.addDetail(SourceDiscrepancy{TsCode: tsCode, TradeDate: a.TradeDate, Field: field.name, Primary: va, Secondary: vb, RelDiff: diff})
+		}
+	}
+	r.MissingBars += len(secondaryByDate)
+
+	for i, mismatched := range mismatchedFields {
+		if mismatched {
+			r.Fields[i].Stocks++
+		}
+	}
+}
+
+// addDetail 保留相对差异最大的明细
+func (r *SourceDiscrepancyReport) addDetail(d SourceDiscrepancy) {
+	r.Discrepancies = append(r.Discrepancies, d)
+	sort.SliceStable(r.Discrepancies, func(i, j int) bool { return r.Discrepancies[i].RelDiff > r.Discrepancies[j].RelDiff })
+	if len(r.Discrepancies) > maxDiscrepancyDetails {
+		r.Discrepancies = r.Discrepancies[:maxDiscrepancyDetails]
+		r.Truncated = true
+	}
+}
+
+// HasDiscrepancies 是否存在超过容差的差异
+func (r *SourceDiscrepancyReport) HasDiscrepancies() bool {
+	for _, field := range r.Fields {
+		if field.Mismatched > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeDiff 两个非零值的相对差异
+func relativeDiff(a, b float64) float64 {
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+}
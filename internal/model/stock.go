@@ -1,23 +1,30 @@
 package model
 
 import (
-	"fmt"
 	"strings"
 	"time"
 )
 
 // Stock 股票基础信息模型 - A股市场
 type Stock struct {
-	TsCode    string     `json:"ts_code" gorm:"primaryKey;size:20;not null"` // Tushare股票代码，如：000001.SZ、600000.SH，主键
-	Symbol    string     `json:"symbol" gorm:"size:10;not null"`             // 股票代码，如：000001、600000（不含交易所后缀）
-	Name      string     `json:"name" gorm:"size:100;not null"`              // 股票简称，如：平安银行、浦发银行
-	Area      string     `json:"area" gorm:"size:50"`                        // 所在地区，如：深圳、上海、北京
-	Industry  string     `json:"industry" gorm:"size:100"`                   // 所属行业，如：银行、房地产开发、软件开发
-	Market    string     `json:"market" gorm:"size:10"`                      // 交易市场，SZ=深交所、SH=上交所、BJ=北交所
-	ListDate  *time.Time `json:"list_date"`                                  // 上市日期，首次公开发行日期
-	IsActive  bool       `json:"is_active" gorm:"default:true"`              // 是否活跃交易，false表示停牌、退市等
-	CreatedAt time.Time  `json:"created_at"`                                 // 记录创建时间
-	UpdatedAt time.Time  `json:"updated_at"`                                 // 记录更新时间
+	TsCode   string      `json:"ts_code" gorm:"primaryKey;size:20;not null"` // Tushare股票代码，如：000001.SZ、600000.SH，主键
+	Symbol   string      `json:"symbol" gorm:"size:10;not null"`             // 股票代码，如：000001、600000（不含交易所后缀）
+	Name     string      `json:"name" gorm:"size:100;not null"`              // 股票简称，如：平安银行、浦发银行
+	Area     string      `json:"area" gorm:"size:50"`                        // 所在地区，如：深圳、上海、北京
+	Industry string      `json:"industry" gorm:"size:100"`                   // 所属行业，如：银行、房地产开发、软件开发
+	Market   string      `json:"market" gorm:"size:10"`                      // 交易市场，SZ=深交所、SH=上交所、BJ=北交所
+	ListDate *time.Time  `json:"list_date"`                                  // 上市日期，首次公开发行日期
+	IsActive bool        `json:"is_active" gorm:"default:true"`              // 是否持续采集，false表示已退市
+	Status   StockStatus `json:"status" gorm:"size:20;index"`                // 上市状态：listed、suspended、delisted，为空表示尚未核实
+	Priority bool        `json:"priority" gorm:"default:false;index"`        // 是否优先同步，true表示不受每日采集配额限制（如指数成分股、自选股）
+	// FloatShares 流通股本，单位：股，来自股票详情，用于计算换手率，0表示未知
+	FloatShares int64 `json:"float_shares" gorm:"default:0"`
+	// TotalShares 总股本，单位：股，来自股票详情，用于计算市值，0表示未知；历史变动保存在stock_shares表
+	TotalShares int64 `json:"total_shares" gorm:"default:0"`
+	// DataQuality 日K线数据质量评分，只在股票详情中返回，不随股票信息保存
+	DataQuality *StockDataQuality `json:"data_quality,omitempty" gorm:"-"`
+	CreatedAt   time.Time         `json:"created_at"` // 记录创建时间
+	UpdatedAt   time.Time         `json:"updated_at"` // 记录更新时间
 }
 
 // TableName 指定表名
@@ -25,6 +32,17 @@ func (Stock) TableName() string {
 	return "stocks"
 }
 
+// ClampStartDate 将采集起始日期限制在上市日期之后，上市日期未知时原样返回
+func (s *Stock) ClampStartDate(startDate time.Time) time.Time {
+	if s.ListDate == nil || s.ListDate.IsZero() {
+		return startDate
+	}
+	if startDate.Before(*s.ListDate) {
+		return *s.ListDate
+	}
+	return startDate
+}
+
 // DailyData 日线数据模型 - A股K线数据
 type DailyData struct {
 	TsCode    string    `json:"ts_code" gorm:"size:20;not null;primaryKey"` // 股票代码，如：000001.SZ，联合主键1
@@ -56,23 +74,7 @@ func (d DailyData) GetTradeDate() int {
 
 // TableName 指定表名 - 根据股票代码动态选择表名
 func (d DailyData) TableName() string {
-	// 提取股票代码的前三位数字
-	var prefix string
-	if len(d.TsCode) >= 3 {
-		// 处理带后缀的情况，如 "000001.SZ"
-		code := strings.Split(d.TsCode, ".")[0]
-		if len(code) >= 3 {
-			prefix = code[:3]
-		}
-	}
-
-	// 根据前三位确定表名
-	switch prefix {
-	case "000", "001", "002", "300", "301", "600", "601", "603", "605", "688":
-		return fmt.Sprintf("daily_data_%s", prefix)
-	default:
-		return "daily_data_other"
-	}
+	return klineShardTable(DailyDataTablePrefix, d.TsCode)
 }
 
 // getExchange 根据股票代码获取交易所类型
@@ -137,23 +139,7 @@ func (w WeeklyData) GetTradeDate() int {
 
 // TableName 指定表名 - 根据股票代码动态选择表名
 func (w WeeklyData) TableName() string {
-	// 提取股票代码的前三位数字
-	var prefix string
-	if len(w.TsCode) >= 3 {
-		// 处理带后缀的情况，如 "000001.SZ"
-		code := strings.Split(w.TsCode, ".")[0]
-		if len(code) >= 3 {
-			prefix = code[:3]
-		}
-	}
-
-	// 根据前三位确定表名
-	switch prefix {
-	case "000", "001", "002", "300", "301", "600", "601", "603", "605", "688":
-		return fmt.Sprintf("weekly_data_%s", prefix)
-	default:
-		return "weekly_data_other"
-	}
+	return klineShardTable(WeeklyDataTablePrefix, w.TsCode)
 }
 
 // MonthlyData 月K线数据模型 - A股月K线行情数据
@@ -187,23 +173,7 @@ func (m MonthlyData) GetTradeDate() int {
 
 // TableName 指定表名 - 根据股票代码动态选择表名
 func (m MonthlyData) TableName() string {
-	// 提取股票代码的前三位数字
-	var prefix string
-	if len(m.TsCode) >= 3 {
-		// 处理带后缀的情况，如 "000001.SZ"
-		code := strings.Split(m.TsCode, ".")[0]
-		if len(code) >= 3 {
-			prefix = code[:3]
-		}
-	}
-
-	// 根据前三位确定表名
-	switch prefix {
-	case "000", "001", "002", "300", "301", "600", "601", "603", "605", "688":
-		return fmt.Sprintf("monthly_data_%s", prefix)
-	default:
-		return "monthly_data_other"
-	}
+	return klineShardTable(MonthlyDataTablePrefix, m.TsCode)
 }
 
 // QuarterlyData 季K线数据模型 - A股季K线行情数据
@@ -336,13 +306,14 @@ func NewYearlyTechnicalIndicator() *TechnicalIndicator {
 
 // SelectionResult 选股结果模型 - A股选股策略执行结果
 type SelectionResult struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`                        // 主键ID，数据库自增
-	StrategyName  string    `json:"strategy_name" gorm:"size:50;not null;index"` // 选股策略名称，如：technical、fundamental、combined
-	TsCode        string    `json:"ts_code" gorm:"size:20;not null;index"`       // 股票代码，如：000001.SZ
-	SelectionDate time.Time `json:"selection_date" gorm:"not null;index"`        // 选股日期，策略执行日期
-	Score         float64   `json:"score" gorm:"type:decimal(8,4)"`              // 选股评分，0-100分，分数越高越优质
-	Reason        string    `json:"reason" gorm:"type:text"`                     // 选股理由，详细说明为什么选中该股票
-	CreatedAt     time.Time `json:"created_at"`                                  // 记录创建时间
+	ID            uint         `json:"id" gorm:"primaryKey"`                        // 主键ID，数据库自增
+	StrategyName  string       `json:"strategy_name" gorm:"size:50;not null;index"` // 选股策略名称，如：technical、fundamental、combined
+	TsCode        string       `json:"ts_code" gorm:"size:20;not null;index"`       // 股票代码，如：000001.SZ
+	SelectionDate time.Time    `json:"selection_date" gorm:"not null;index"`        // 选股日期，策略执行日期
+	Score         float64      `json:"score" gorm:"type:decimal(8,4)"`              // 选股评分，0-100分，分数越高越优质
+	Reason        string       `json:"reason" gorm:"type:text"`                     // 选股理由，详细说明为什么选中该股票
+	Factors       FactorScores `json:"factors" gorm:"type:json"`                    // 分因子得分，各因子得分合计等于选股评分
+	CreatedAt     time.Time    `json:"created_at"`                                  // 记录创建时间
 
 	// 关联股票信息
 	Stock Stock `json:"stock" gorm:"foreignKey:TsCode;references:TsCode"` // 关联的股票基础信息
@@ -394,6 +365,14 @@ func (PerformanceReport) TableName() string {
 	return "performance_reports"
 }
 
+// ROE 净资产收益率，单位：%，由每股收益/每股净资产计算，每股净资产<=0时无法计算，返回false
+func (r *PerformanceReport) ROE() (float64, bool) {
+	if r.BVPS <= 0 {
+		return 0, false
+	}
+	return r.EPS / r.BVPS * 100, true
+}
+
 // BacktestResult 回测结果模型 - A股策略回测数据
 type BacktestResult struct {
 	ID             uint      `json:"id" gorm:"primaryKey"`                      // 主键ID，数据库自增
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StockGroupKind 股票分组类型
+type StockGroupKind string
+
+const (
+	StockGroupKindIndex  StockGroupKind = "index"  // 指数成分股，成分股可按指数代码从数据源加载
+	StockGroupKindSector StockGroupKind = "sector" // 行业或概念板块
+	StockGroupKindCustom StockGroupKind = "custom" // 自定义组合
+)
+
+// StockGroup 股票分组，用于对一组股票（指数成分股、板块、自定义组合）做批量同步、选股和导出
+type StockGroup struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"size:50;not null;uniqueIndex"` // 分组名称
+	Kind        StockGroupKind `json:"kind" gorm:"size:20;not null;index"`       // 分组类型：index、sector、custom
+	Source      string         `json:"source" gorm:"size:50"`                    // 成分股来源，指数分组为指数代码，如：000300.SH
+	Description string         `json:"description" gorm:"size:200"`              // 分组说明
+	TsCodes     string         `json:"ts_codes" gorm:"type:text"`                // 股票代码，逗号分隔
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StockGroup) TableName() string {
+	return "stock_groups"
+}
+
+// Codes 获取分组中的股票代码
+func (g *StockGroup) Codes() []string {
+	if g.TsCodes == "" {
+		return []string{}
+	}
+	return strings.Split(g.TsCodes, ",")
+}
+
+// SetCodes 设置分组中的股票代码，统一转为大写并去除空值和重复项，保留原有顺序
+func (g *StockGroup) SetCodes(codes []string) {
+	g.TsCodes = strings.Join(normalizeTsCodes(codes), ",")
+}
+
+// Normalize 统一分组类型和来源的格式，类型为空时视为自定义组合
+func (g *StockGroup) Normalize() {
+	g.Name = strings.TrimSpace(g.Name)
+	g.Kind = StockGroupKind(strings.ToLower(strings.TrimSpace(string(g.Kind))))
+	if g.Kind == "" {
+		g.Kind = StockGroupKindCustom
+	}
+	g.Source = strings.TrimSpace(g.Source)
+	if g.Kind == StockGroupKindIndex {
+		g.Source = strings.ToUpper(g.Source)
+	}
+}
+
+// Validate 校验分组名称、类型和来源，指数分组必须指定带交易所后缀的指数代码
+func (g *StockGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("分组名称不能为空")
+	}
+	switch g.Kind {
+	case StockGroupKindIndex:
+		if !strings.Contains(g.Source, ".") {
+			return fmt.Errorf("指数分组的source应为指数代码，如：000300.SH")
+		}
+	case StockGroupKindSector, StockGroupKindCustom:
+	default:
+		return fmt.Errorf("kind参数错误，可选值：index、sector、custom")
+	}
+	return nil
+}
+
+// normalizeTsCodes 统一股票代码为大写并去除空值和重复项，保留原有顺序
+func normalizeTsCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockGroup_NormalizeAndValidate(t *testing.T) {
+	group := StockGroup{Name: " 沪深300 ", Kind: "Index", Source: "000300.sh"}
+	group.Normalize()
+	assert.Equal(t, "沪深300", group.Name)
+	assert.Equal(t, StockGroupKindIndex, group.Kind)
+	assert.Equal(t, "000300.SH", group.Source)
+	assert.NoError(t, group.Validate())
+
+	// 类型为空视为自定义组合，不要求来源
+	custom := StockGroup{Name: "核心持仓"}
+	custom.Normalize()
+	assert.Equal(t, StockGroupKindCustom, custom.Kind)
+	assert.NoError(t, custom.Validate())
+
+	for _, invalid := range []StockGroup{
+		{Kind: StockGroupKindCustom},
+		{Name: "基金", Kind: "fund"},
+		{Name: "沪深300", Kind: StockGroupKindIndex, Source: "000300"},
+	} {
+		assert.Error(t, invalid.Validate(), invalid.Name)
+	}
+
+	group.SetCodes([]string{"600519.sh", " ", "000001.SZ", "600519.SH"})
+	assert.Equal(t, []string{"600519.SH", "000001.SZ"}, group.Codes())
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 股票身份变更类型
+const (
+	IdentityChangeRename      = "rename"      // 更名
+	IdentityChangeReactivated = "reactivated" // 长期不活跃后重新活跃
+)
+
+// ReactivationInactiveDays 股票不活跃超过该天数后重新活跃，视为代码可能被复用
+const ReactivationInactiveDays = 180
+
+// nameMarkers 股票简称中表示交易状态的前缀，变化时不视为更名
+var nameMarkers = []string{"*ST", "ST", "XD", "XR", "DR", "PT", "S*", "N", "C", "S"}
+
+// StockIdentityChange 股票身份变更记录
+// A股代码在退市后偶尔会被复用，借壳上市等情况也会导致简称变化，同一代码下可能混入不同公司的K线历史
+type StockIdentityChange struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	TsCode       string     `json:"ts_code" gorm:"size:20;not null;index"`   // 股票代码
+	ChangeType   string     `json:"change_type" gorm:"size:20;not null"`     // 变更类型：rename、reactivated
+	OldName      string     `json:"old_name" gorm:"size:100"`                // 变更前简称
+	NewName      string     `json:"new_name" gorm:"size:100"`                // 变更后简称
+	InactiveDays int        `json:"inactive_days"`                           // 重新活跃前的不活跃天数
+	NeedsReview  bool       `json:"needs_review" gorm:"default:false;index"` // K线历史是否需要人工复核
+	ReviewedAt   *time.Time `json:"reviewed_at"`                             // 复核时间
+	CreatedAt    time.Time  `json:"created_at" gorm:"index"`                 // 发现时间
+}
+
+// TableName 指定表名
+func (StockIdentityChange) TableName() string {
+	return "stock_identity_changes"
+}
+
+// DetectIdentityChanges 比较已保存的股票信息与同步获取的股票信息，返回身份变更记录
+// 简称仅交易状态前缀（ST、XD等）变化时不视为更名；新旧简称没有任何相同字符时视为可能换了公司，标记需要复核；
+// 股票不活跃超过ReactivationInactiveDays天后重新活跃同样标记需要复核。previous为nil表示新股票，不产生记录
+func DetectIdentityChanges(previous *Stock, current Stock, now time.Time) []StockIdentityChange {
+	if previous == nil {
+		return nil
+	}
+
+	var changes []StockIdentityChange
+	oldName, newName := normalizeStockName(previous.Name), normalizeStockName(current.Name)
+	if oldName != "" && newName != "" && oldName != newName {
+		changes = append(changes, StockIdentityChange{
+			TsCode:      current.TsCode,
+			ChangeType:  IdentityChangeRename,
+			OldName:     previous.Name,
+			NewName:     current.Name,
+			NeedsReview: !strings.ContainsAny(oldName, newName),
+		})
+	}
+
+	if !previous.IsActive && current.IsActive && !previous.UpdatedAt.IsZero() {
+		inactiveDays := int(now.Sub(previous.UpdatedAt).Hours() / 24)
+		if inactiveDays >= ReactivationInactiveDays {
+			changes = append(changes, StockIdentityChange{
+				TsCode:       current.TsCode,
+				ChangeType:   IdentityChangeReactivated,
+				OldName:      previous.Name,
+				NewName:      current.Name,
+				InactiveDays: inactiveDays,
+				NeedsReview:  true,
+			})
+		}
+	}
+
+	return changes
+}
+
+// normalizeStockName 去除股票简称中的空白和交易状态前缀
+func normalizeStockName(name string) string {
+	name = strings.Join(strings.Fields(name), "")
+	for _, marker := range nameMarkers {
+		if strings.HasPrefix(name, marker) && len(name) > len(marker) {
+			return name[len(marker):]
+		}
+	}
+	return name
+}
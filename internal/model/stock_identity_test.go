@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectIdentityChanges_RenameOnResync(t *testing.T) {
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local)
+	previous := &Stock{TsCode: "000001.SZ", Name: "深发展A", IsActive: true, UpdatedAt: now.AddDate(0, 0, -1)}
+
+	// 重新同步时简称完全不同，可能已换了公司
+	changes := DetectIdentityChanges(previous, Stock{TsCode: "000001.SZ", Name: "平安银行", IsActive: true}, now)
+	require.Len(t, changes, 1)
+	assert.Equal(t, IdentityChangeRename, changes[0].ChangeType)
+	assert.Equal(t, "深发展A", changes[0].OldName)
+	assert.Equal(t, "平安银行", changes[0].NewName)
+	assert.True(t, changes[0].NeedsReview)
+
+	// 简称部分变化只记录不复核
+	previous.Name = "万科A"
+	changes = DetectIdentityChanges(previous, Stock{TsCode: "000001.SZ", Name: "万科企业", IsActive: true}, now)
+	require.Len(t, changes, 1)
+	assert.False(t, changes[0].NeedsReview)
+}
+
+func TestDetectIdentityChanges_TradingMarkers(t *testing.T) {
+	now := time.Now()
+	previous := &Stock{TsCode: "600001.SH", Name: "邯郸钢铁", IsActive: true, UpdatedAt: now}
+
+	for _, name := range []string{"ST邯郸钢铁", "*ST邯郸钢铁", "XD邯郸钢铁", "邯郸 钢铁"} {
+		assert.Empty(t, DetectIdentityChanges(previous, Stock{TsCode: "600001.SH", Name: name, IsActive: true}, now), name)
+	}
+
+	// 新股票和简称为空时不产生记录
+	assert.Empty(t, DetectIdentityChanges(nil, Stock{TsCode: "600001.SH", Name: "邯郸钢铁"}, now))
+	assert.Empty(t, DetectIdentityChanges(previous, Stock{TsCode: "600001.SH", IsActive: true}, now))
+}
+
+func TestDetectIdentityChanges_Reactivated(t *testing.T) {
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local)
+	previous := &Stock{TsCode: "600087.SH", Name: "退市长油", IsActive: false, UpdatedAt: now.AddDate(-2, 0, 0)}
+
+	changes := DetectIdentityChanges(previous, Stock{TsCode: "600087.SH", Name: "长航油运", IsActive: true}, now)
+	require.Len(t, changes, 2)
+	assert.Equal(t, IdentityChangeReactivated, changes[1].ChangeType)
+	assert.True(t, changes[1].NeedsReview)
+	assert.GreaterOrEqual(t, changes[1].InactiveDays, 700)
+
+	// 短暂停牌后复牌不视为代码复用
+	previous.Name = "长航油运"
+	previous.UpdatedAt = now.AddDate(0, 0, -30)
+	assert.Empty(t, DetectIdentityChanges(previous, Stock{TsCode: "600087.SH", Name: "长航油运", IsActive: true}, now))
+}
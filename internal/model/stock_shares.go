@@ -0,0 +1,105 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// 股本记录来源
+const (
+	SharesSourceDetail      = "detail"      // 东方财富股票详情（f84总股本、f85流通股本）
+	SharesSourceShareholder = "shareholder" // 股东户数数据中的总股本，只有总股本
+)
+
+// StockShares 股本变动记录，总股本和流通股本随增发、送转、解禁变化，每次变化保存一条
+// 计算历史换手率、市值时按交易日期取当时的股本，而不是当前值
+type StockShares struct {
+	TsCode      string    `json:"ts_code" gorm:"size:20;not null;primaryKey"` // 股票代码，联合主键1
+	ChangeDate  int       `json:"change_date" gorm:"not null;primaryKey"`     // 股本生效（观测到变化）日期，YYYYMMDD格式，联合主键2
+	TotalShares int64     `json:"total_shares"`                               // 总股本，单位：股，0表示未知
+	FloatShares int64     `json:"float_shares"`                               // 流通股本，单位：股，0表示未知
+	Source      string    `json:"source" gorm:"size:20"`                      // 来源：detail、shareholder
+	CreatedAt   time.Time `json:"created_at"`                                 // 记录创建时间
+}
+
+// TableName 指定表名
+func (StockShares) TableName() string {
+	return "stock_shares"
+}
+
+// SharesRecord 由股票详情中的总股本和流通股本生成股本记录
+func (s *Stock) SharesRecord(changeDate int) StockShares {
+	return StockShares{
+		TsCode:      s.TsCode,
+		ChangeDate:  changeDate,
+		TotalShares: s.TotalShares,
+		FloatShares: s.FloatShares,
+		Source:      SharesSourceDetail,
+	}
+}
+
+// SameShares 判断两条记录的股本是否相同，未知（0）的一方不参与比较
+func (s StockShares) SameShares(other StockShares) bool {
+	if s.TotalShares > 0 && other.TotalShares > 0 && s.TotalShares != other.TotalShares {
+		return false
+	}
+	if s.FloatShares > 0 && other.FloatShares > 0 && s.FloatShares != other.FloatShares {
+		return false
+	}
+	return true
+}
+
+// SharesTimeline 按生效日期升序排列的股本变动记录，用于查询某个交易日的股本
+type SharesTimeline []StockShares
+
+// NewSharesTimeline 由任意顺序的股本记录创建时间线
+func NewSharesTimeline(records []StockShares) SharesTimeline {
+	timeline := make(SharesTimeline, len(records))
+	copy(timeline, records)
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i].ChangeDate < timeline[j].ChangeDate })
+	return timeline
+}
+
+// FloatSharesAt 获取交易日的流通股本，取生效日期不晚于该日的最后一条有流通股本的记录
+// 交易日早于所有记录时使用最早的记录（只在开始记录股本后才有历史），没有记录时返回fallback
+func (t SharesTimeline) FloatSharesAt(tradeDate int, fallback int64) int64 {
+	return t.sharesAt(tradeDate, fallback, func(s StockShares) int64 { return s.FloatShares })
+}
+
+// TotalSharesAt 获取交易日的总股本，规则同FloatSharesAt
+func (t SharesTimeline) TotalSharesAt(tradeDate int, fallback int64) int64 {
+	return t.sharesAt(tradeDate, fallback, func(s StockShares) int64 { return s.TotalShares })
+}
+
+func (t SharesTimeline) sharesAt(tradeDate int, fallback int64, value func(StockShares) int64) int64 {
+	var earliest, result int64
+	for _, record := range t {
+		v := value(record)
+		if v <= 0 {
+			continue
+		}
+		if earliest == 0 {
+			earliest = v
+		}
+		if record.ChangeDate > tradeDate {
+			break
+		}
+		result = v
+	}
+	switch {
+	case result > 0:
+		return result
+	case earliest > 0:
+		return earliest
+	default:
+		return fallback
+	}
+}
+
+// MarketCap 计算市值（元），收盘价或股本未知时返回0
+func MarketCap(close float64, shares int64) float64 {
+	if close <= 0 || shares <= 0 {
+		return 0
+	}
+	return RoundAmount(close * float64(shares))
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharesTimeline_FloatSharesAt(t *testing.T) {
+	timeline := NewSharesTimeline([]StockShares{
+		{ChangeDate: 20250601, TotalShares: 2000, FloatShares: 1500},
+		{ChangeDate: 20250101, TotalShares: 1000, FloatShares: 1000},
+		{ChangeDate: 20250301, TotalShares: 1200}, // 只有总股本
+	})
+
+	assert.Equal(t, int64(1000), timeline.FloatSharesAt(20240601, 0), "早于所有记录时使用最早的记录")
+	assert.Equal(t, int64(1000), timeline.FloatSharesAt(20250401, 0), "跳过没有流通股本的记录")
+	assert.Equal(t, int64(1200), timeline.TotalSharesAt(20250401, 0))
+	assert.Equal(t, int64(1500), timeline.FloatSharesAt(20250601, 0))
+	assert.Equal(t, int64(800), SharesTimeline(nil).FloatSharesAt(20250601, 800))
+}
+
+func TestComputeDailyMetricsWithShares(t *testing.T) {
+	// 解禁后流通股本翻倍，同样的成交量换手率减半
+	shares := NewSharesTimeline([]StockShares{
+		{ChangeDate: 20250101, FloatShares: 1000000},
+		{ChangeDate: 20250610, FloatShares: 2000000},
+	})
+	data := []DailyData{
+		{TradeDate: 20250609, High: 10, Low: 9, Close: 10, Volume: 50000},
+		{TradeDate: 20250610, High: 10, Low: 9, Close: 10, Volume: 50000},
+	}
+
+	metrics := ComputeDailyMetricsWithShares(data, nil, shares, 3000000)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, 5.0, metrics[0].TurnoverRate)
+	assert.Equal(t, 2.5, metrics[1].TurnoverRate)
+
+	// 没有股本变动记录时使用当前流通股本
+	metrics = ComputeDailyMetrics(data, nil, 2000000)
+	assert.Equal(t, 2.5, metrics[0].TurnoverRate)
+}
+
+func TestValuation_SetMarketCap(t *testing.T) {
+	valuation := Valuation{Close: 10.5}
+	valuation.SetMarketCap(0)
+	assert.Nil(t, valuation.MarketCap)
+
+	valuation.SetMarketCap(29352000000)
+	require.NotNil(t, valuation.MarketCap)
+	assert.Equal(t, 308196000000.0, *valuation.MarketCap)
+}
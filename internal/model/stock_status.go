@@ -0,0 +1,41 @@
+package model
+
+import "strings"
+
+// StockStatus 股票上市状态
+type StockStatus string
+
+const (
+	StockStatusListed    StockStatus = "listed"    // 正常上市交易
+	StockStatusSuspended StockStatus = "suspended" // 停牌，仍在上市，复牌后继续采集
+	StockStatusDelisted  StockStatus = "delisted"  // 已退市，不再采集
+)
+
+// ClassifyStockStatus 根据股票简称和数据源的停牌标志判断上市状态
+// 简称以PT开头或带"退"字（如"退市海润"、"*ST长生退"）表示已退市；停牌只影响交易，不视为退市
+func ClassifyStockStatus(name string, suspended bool) StockStatus {
+	if strings.HasPrefix(name, "PT") || strings.Contains(name, "退") {
+		return StockStatusDelisted
+	}
+	if suspended {
+		return StockStatusSuspended
+	}
+	return StockStatusListed
+}
+
+// SetStatus 设置上市状态，并同步是否活跃：停牌股票仍需采集，只有退市股票标记为非活跃
+func (s *Stock) SetStatus(status StockStatus) {
+	s.Status = status
+	s.IsActive = status != StockStatusDelisted
+}
+
+// GetStatus 获取上市状态，尚未核实状态的股票按是否活跃推断
+func (s *Stock) GetStatus() StockStatus {
+	if s.Status != "" {
+		return s.Status
+	}
+	if s.IsActive {
+		return StockStatusListed
+	}
+	return StockStatusDelisted
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyStockStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		stockName string
+		suspended bool
+		expected  StockStatus
+	}{
+		{"正常交易", "平安银行", false, StockStatusListed},
+		{"ST股票正常交易", "*ST海润", false, StockStatusListed},
+		{"长期停牌仍在上市", "平安银行", true, StockStatusSuspended},
+		{"PT股票已退市", "PT水仙", false, StockStatusDelisted},
+		{"退市整理期", "退市海润", false, StockStatusDelisted},
+		{"简称带退字", "*ST长生退", true, StockStatusDelisted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyStockStatus(tt.stockName, tt.suspended))
+		})
+	}
+}
+
+func TestStock_SetStatus(t *testing.T) {
+	var stock Stock
+
+	// 停牌股票仍需继续采集
+	stock.SetStatus(StockStatusSuspended)
+	assert.Equal(t, StockStatusSuspended, stock.GetStatus())
+	assert.True(t, stock.IsActive)
+
+	stock.SetStatus(StockStatusDelisted)
+	assert.Equal(t, StockStatusDelisted, stock.GetStatus())
+	assert.False(t, stock.IsActive)
+
+	stock.SetStatus(StockStatusListed)
+	assert.True(t, stock.IsActive)
+}
+
+func TestStock_GetStatusUnverified(t *testing.T) {
+	// 尚未核实上市状态的旧记录按是否活跃推断
+	assert.Equal(t, StockStatusListed, (&Stock{IsActive: true}).GetStatus())
+	assert.Equal(t, StockStatusDelisted, (&Stock{IsActive: false}).GetStatus())
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStock_ClampStartDate(t *testing.T) {
+	defaultStart := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	listDate := time.Date(2021, 6, 18, 0, 0, 0, 0, time.UTC)
+
+	// 已知上市日期：起始日期被限制到上市日期
+	stock := &Stock{TsCode: "001208.SZ", ListDate: &listDate}
+	assert.Equal(t, listDate, stock.ClampStartDate(defaultStart))
+
+	// 起始日期晚于上市日期：保持不变
+	later := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, later, stock.ClampStartDate(later))
+
+	// 上市日期未知：保持不变
+	unknown := &Stock{TsCode: "000001.SZ"}
+	assert.Equal(t, defaultStart, unknown.ClampStartDate(defaultStart))
+
+	zero := time.Time{}
+	zeroDate := &Stock{TsCode: "000002.SZ", ListDate: &zero}
+	assert.Equal(t, defaultStart, zeroDate.ClampStartDate(defaultStart))
+}
+
+func TestPerformanceReport_ROE(t *testing.T) {
+	roe, ok := (&PerformanceReport{EPS: 2, BVPS: 10}).ROE()
+	assert.True(t, ok)
+	assert.InDelta(t, 20.0, roe, 1e-9)
+
+	// 每股净资产无效时ROE未知
+	for _, bvps := range []float64{0, -5} {
+		_, ok := (&PerformanceReport{EPS: 2, BVPS: bvps}).ROE()
+		assert.False(t, ok)
+	}
+}
@@ -46,14 +46,19 @@ const (
 	TaskStatusRunning   TaskStatus = "running"   // 执行中
 	TaskStatusCompleted TaskStatus = "completed" // 已完成
 	TaskStatusFailed    TaskStatus = "failed"    // 失败
+	TaskStatusCancelled TaskStatus = "cancelled" // 已取消
 )
 
+// TaskCancelReasonMaintenance 维护窗口前批量取消任务时记录的原因
+const TaskCancelReasonMaintenance = "cancelled: maintenance"
+
 // TaskType 任务类型
 type TaskType string
 
 const (
-	TaskTypeSyncAllStocks   TaskType = "sync_all_stocks"   // 同步全量股票
-	TaskTypeSyncSingleStock TaskType = "sync_single_stock" // 刷新单只股票日K数据
+	TaskTypeSyncAllStocks       TaskType = "sync_all_stocks"      // 同步全量股票
+	TaskTypeSyncSingleStock     TaskType = "sync_single_stock"    // 刷新单只股票日K数据
+	TaskTypeRefreshFundamentals TaskType = "refresh_fundamentals" // 刷新单只股票业绩报表和股东户数
 )
 
 // Task 异步任务
@@ -72,6 +77,35 @@ type Task struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// FinishedTaskStatuses 已结束的任务状态，保留期清理只删除这些状态的任务
+var FinishedTaskStatuses = []TaskStatus{TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled}
+
+// UnfinishedTaskStatuses 未结束的任务状态，批量取消时处理这些状态的任务
+var UnfinishedTaskStatuses = []TaskStatus{TaskStatusPending, TaskStatusRunning}
+
+// IsFinished 任务是否已结束（完成、失败或取消）
+func (t *Task) IsFinished() bool {
+	for _, status := range FinishedTaskStatuses {
+		if t.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// FinishedAt 任务结束时间，未记录完成时间时使用最后更新时间
+func (t *Task) FinishedAt() time.Time {
+	if t.CompletedAt != nil && !t.CompletedAt.IsZero() {
+		return *t.CompletedAt
+	}
+	return t.UpdatedAt
+}
+
+// IsExpired 任务是否已结束且结束时间早于cutoff，等待中和执行中的任务无论多久都不过期
+func (t *Task) IsExpired(cutoff time.Time) bool {
+	return t.IsFinished() && t.FinishedAt().Before(cutoff)
+}
+
 // TaskSummary 任务摘要（用于列表显示）
 type TaskSummary struct {
 	ID        string     `json:"id"`
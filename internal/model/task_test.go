@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTask_IsExpired(t *testing.T) {
+	now := time.Date(2025, 9, 10, 12, 0, 0, 0, time.Local)
+	cutoff := now.AddDate(0, 0, -30)
+	old := now.AddDate(0, 0, -45)
+	recent := now.AddDate(0, 0, -3)
+
+	tasks := []Task{
+		{ID: "old-completed", Status: TaskStatusCompleted, CompletedAt: &old, UpdatedAt: old},
+		{ID: "old-failed", Status: TaskStatusFailed, UpdatedAt: old}, // 未记录完成时间时按更新时间判断
+		{ID: "old-pending", Status: TaskStatusPending, CreatedAt: old, UpdatedAt: old},
+		{ID: "old-running", Status: TaskStatusRunning, CreatedAt: old, UpdatedAt: old},
+		{ID: "recent-completed", Status: TaskStatusCompleted, CompletedAt: &recent, UpdatedAt: recent},
+		{ID: "recent-failed", Status: TaskStatusFailed, CreatedAt: old, CompletedAt: &recent, UpdatedAt: recent},
+		{ID: "old-cancelled", Status: TaskStatusCancelled, CompletedAt: &old, UpdatedAt: old},
+	}
+
+	var expired []string
+	for i := range tasks {
+		if tasks[i].IsExpired(cutoff) {
+			expired = append(expired, tasks[i].ID)
+		}
+	}
+
+	// 只有早于保留期的已结束任务会被清理，等待中和执行中的任务无论多久都保留
+	assert.Equal(t, []string{"old-completed", "old-failed", "old-cancelled"}, expired)
+}
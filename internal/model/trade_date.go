@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// 交易日期的合法年份范围，超出范围的日期视为解析错误
+const (
+	minTradeDateYear = 1990 // A股市场开市年份
+	maxTradeDateYear = 2100
+)
+
+// TradeDated 带交易日期的K线数据
+type TradeDated interface {
+	GetTradeDate() int
+}
+
+// ValidTradeDate 判断YYYYMMDD格式的交易日期是否为真实存在的日历日期
+// 采集器从字符串拼接日期时可能产生20251332、20250230等不存在的日期，这类数据写入后会导致下游解析日期失败
+func ValidTradeDate(date int) bool {
+	year, month, day := date/10000, date/100%100, date%100
+	if year < minTradeDateYear || year > maxTradeDateYear || month < 1 || month > 12 || day < 1 {
+		return false
+	}
+
+	// time.Date会将超出当月天数的日期顺延到下个月，顺延后月份或日期变化说明日期不存在
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t.Month() == time.Month(month) && t.Day() == day
+}
+
+// FilterValidTradeDates 过滤交易日期不合法的K线数据，返回合法的数据和被过滤的交易日期
+func FilterValidTradeDates[T TradeDated](data []T) ([]T, []int) {
+	var invalid []int
+	for _, item := range data {
+		if !ValidTradeDate(item.GetTradeDate()) {
+			invalid = append(invalid, item.GetTradeDate())
+		}
+	}
+	if len(invalid) == 0 {
+		return data, nil
+	}
+
+	valid := make([]T, 0, len(data)-len(invalid))
+	for _, item := range data {
+		if ValidTradeDate(item.GetTradeDate()) {
+			valid = append(valid, item)
+		}
+	}
+	return valid, invalid
+}
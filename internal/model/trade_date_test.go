@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidTradeDate(t *testing.T) {
+	valid := []int{20250630, 20240229, 20000229, 19901219, 20251231, 20250101}
+	for _, date := range valid {
+		assert.True(t, ValidTradeDate(date), "%d should be valid", date)
+	}
+
+	invalid := []int{
+		20251332, // 月份13
+		20250001, // 月份0
+		20250100, // 日期0
+		20250132, // 日期32
+		20250230, // 2月30日
+		20250229, // 平年2月29日
+		19000229, // 整百年非闰年
+		20250431, // 4月只有30天
+		2025063,  // 位数不足
+		202506301,
+		19891231, // 早于A股开市
+		0,
+		-20250630,
+	}
+	for _, date := range invalid {
+		assert.False(t, ValidTradeDate(date), "%d should be invalid", date)
+	}
+}
+
+func TestFilterValidTradeDates(t *testing.T) {
+	data := []DailyData{
+		{TsCode: "000001.SZ", TradeDate: 20250627},
+		{TsCode: "000001.SZ", TradeDate: 20251332},
+		{TsCode: "000001.SZ", TradeDate: 20250630},
+	}
+
+	valid, invalid := FilterValidTradeDates(data)
+	assert.Len(t, valid, 2)
+	assert.Equal(t, 20250627, valid[0].TradeDate)
+	assert.Equal(t, 20250630, valid[1].TradeDate)
+	assert.Equal(t, []int{20251332}, invalid)
+
+	// 全部合法时原样返回
+	valid, invalid = FilterValidTradeDates(data[:1])
+	assert.Len(t, valid, 1)
+	assert.Empty(t, invalid)
+}
@@ -0,0 +1,107 @@
+package model
+
+import "math"
+
+// PEStatus 市盈率的计算状态
+type PEStatus string
+
+const (
+	PEStatusOK          PEStatus = "ok"          // 正常计算
+	PEStatusLoss        PEStatus = "loss"        // 滚动每股收益<=0（亏损或盈亏平衡），市盈率无意义
+	PEStatusUnavailable PEStatus = "unavailable" // 缺少收盘价或计算滚动每股收益所需的历史报表
+)
+
+// Valuation 个股估值，由最新一根日K线的收盘价和业绩报表计算
+type Valuation struct {
+	TsCode     string   `json:"ts_code"`     // 股票代码
+	Name       string   `json:"name"`        // 股票简称
+	TradeDate  int      `json:"trade_date"`  // 收盘价对应的交易日期，YYYYMMDD格式，没有K线时为0
+	Close      float64  `json:"close"`       // 收盘价，单位：元
+	ReportDate int      `json:"report_date"` // 最新报告期，YYYYMMDD格式
+	TTMEPS     *float64 `json:"ttm_eps"`     // 滚动四季度每股收益，单位：元，历史报表不足时为nil
+	BVPS       float64  `json:"bvps"`        // 最新一期每股净资产，单位：元
+	PE         *float64 `json:"pe"`          // 市盈率TTM（收盘价/滚动每股收益），无法计算时为nil
+	PEStatus   PEStatus `json:"pe_status"`   // 市盈率的计算状态，PE为nil时说明原因
+	PB         *float64 `json:"pb"`          // 市净率（收盘价/每股净资产），每股净资产<=0或缺少收盘价时为nil
+	MarketCap  *float64 `json:"market_cap"`  // 总市值（收盘价*总股本），单位：元，总股本未知或缺少收盘价时为nil
+}
+
+// TTMEPS 由一只股票的业绩报表计算滚动四季度每股收益，reports可为任意顺序
+// A股报表的每股收益为年初至报告期末的累计值，最新一期为年报时直接使用，
+// 否则为：本期累计 + 上年年报 - 上年同期累计，缺少上年年报或上年同期报表时返回false
+func TTMEPS(reports []PerformanceReport) (ttm float64, reportDate int, ok bool) {
+	if len(reports) == 0 {
+		return 0, 0, false
+	}
+
+	byDate := make(map[int]float64, len(reports))
+	for _, report := range reports {
+		byDate[report.ReportDate] = report.EPS
+		if report.ReportDate > reportDate {
+			reportDate = report.ReportDate
+		}
+	}
+
+	year, monthDay := reportDate/10000, reportDate%10000
+	if monthDay == 1231 {
+		return byDate[reportDate], reportDate, true
+	}
+
+	lastAnnual, hasAnnual := byDate[(year-1)*10000+1231]
+	lastSamePeriod, hasSamePeriod := byDate[(year-1)*10000+monthDay]
+	if !hasAnnual || !hasSamePeriod {
+		return 0, reportDate, false
+	}
+	return byDate[reportDate] + lastAnnual - lastSamePeriod, reportDate, true
+}
+
+// roundRatio 估值倍数保留2位小数，与行情软件显示的精度一致
+func roundRatio(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// NewValuation 由最新日K线和业绩报表计算估值，latest为nil表示没有K线数据
+// 滚动每股收益<=0时市盈率无意义，PE为nil且PEStatus为loss，不返回负的市盈率
+func NewValuation(tsCode, name string, latest *DailyData, reports []PerformanceReport) Valuation {
+	valuation := Valuation{TsCode: tsCode, Name: name, PEStatus: PEStatusUnavailable}
+
+	ttm, reportDate, hasTTM := TTMEPS(reports)
+	valuation.ReportDate = reportDate
+	for _, report := range reports {
+		if report.ReportDate == reportDate {
+			valuation.BVPS = report.BVPS
+			break
+		}
+	}
+	if hasTTM {
+		valuation.TTMEPS = &ttm
+	}
+
+	if latest == nil || latest.Close <= 0 {
+		return valuation
+	}
+	valuation.TradeDate = latest.TradeDate
+	valuation.Close = latest.Close
+
+	if hasTTM {
+		if ttm <= 0 {
+			valuation.PEStatus = PEStatusLoss
+		} else {
+			pe := roundRatio(latest.Close / ttm)
+			valuation.PE = &pe
+			valuation.PEStatus = PEStatusOK
+		}
+	}
+	if valuation.BVPS > 0 {
+		pb := roundRatio(latest.Close / valuation.BVPS)
+		valuation.PB = &pb
+	}
+	return valuation
+}
+
+// SetMarketCap 按总股本计算总市值，总股本未知（<=0）或缺少收盘价时不设置
+func (v *Valuation) SetMarketCap(totalShares int64) {
+	if marketCap := MarketCap(v.Close, totalShares); marketCap > 0 {
+		v.MarketCap = &marketCap
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTMEPS(t *testing.T) {
+	// 最新一期为年报时直接使用
+	ttm, reportDate, ok := TTMEPS([]PerformanceReport{
+		{ReportDate: 20240930, EPS: 0.9},
+		{ReportDate: 20241231, EPS: 1.2},
+	})
+	require.True(t, ok)
+	assert.Equal(t, 20241231, reportDate)
+	assert.InDelta(t, 1.2, ttm, 1e-9)
+
+	// 2025年中报累计0.8 + 2024年报1.2 - 2024年中报累计0.5 = 1.5
+	ttm, reportDate, ok = TTMEPS([]PerformanceReport{
+		{ReportDate: 20250630, EPS: 0.8},
+		{ReportDate: 20250331, EPS: 0.3},
+		{ReportDate: 20241231, EPS: 1.2},
+		{ReportDate: 20240630, EPS: 0.5},
+	})
+	require.True(t, ok)
+	assert.Equal(t, 20250630, reportDate)
+	assert.InDelta(t, 1.5, ttm, 1e-9)
+
+	// 缺少上年同期报表
+	_, reportDate, ok = TTMEPS([]PerformanceReport{
+		{ReportDate: 20250630, EPS: 0.8},
+		{ReportDate: 20241231, EPS: 1.2},
+	})
+	assert.False(t, ok)
+	assert.Equal(t, 20250630, reportDate)
+
+	_, _, ok = TTMEPS(nil)
+	assert.False(t, ok)
+}
+
+func TestNewValuation(t *testing.T) {
+	latest := &DailyData{TsCode: "000001.SZ", TradeDate: 20250815, Close: 15}
+	reports := []PerformanceReport{
+		{ReportDate: 20250630, EPS: 0.8, BVPS: 10},
+		{ReportDate: 20241231, EPS: 1.2},
+		{ReportDate: 20240630, EPS: 0.5},
+	}
+
+	valuation := NewValuation("000001.SZ", "平安银行", latest, reports)
+	assert.Equal(t, 20250815, valuation.TradeDate)
+	assert.Equal(t, 20250630, valuation.ReportDate)
+	require.NotNil(t, valuation.TTMEPS)
+	assert.InDelta(t, 1.5, *valuation.TTMEPS, 1e-9)
+	require.NotNil(t, valuation.PE)
+	assert.Equal(t, 10.0, *valuation.PE)
+	assert.Equal(t, PEStatusOK, valuation.PEStatus)
+	require.NotNil(t, valuation.PB)
+	assert.Equal(t, 1.5, *valuation.PB)
+}
+
+func TestNewValuation_NegativeEPS(t *testing.T) {
+	latest := &DailyData{TradeDate: 20250815, Close: 5}
+
+	// 滚动每股收益为负：市盈率无意义，市净率照常计算
+	valuation := NewValuation("000002.SZ", "", latest, []PerformanceReport{
+		{ReportDate: 20241231, EPS: -0.35, BVPS: 4},
+	})
+	require.NotNil(t, valuation.TTMEPS)
+	assert.InDelta(t, -0.35, *valuation.TTMEPS, 1e-9)
+	assert.Nil(t, valuation.PE)
+	assert.Equal(t, PEStatusLoss, valuation.PEStatus)
+	require.NotNil(t, valuation.PB)
+	assert.Equal(t, 1.25, *valuation.PB)
+
+	// 盈亏平衡同样不计算市盈率
+	valuation = NewValuation("000002.SZ", "", latest, []PerformanceReport{{ReportDate: 20241231, EPS: 0, BVPS: 4}})
+	assert.Nil(t, valuation.PE)
+	assert.Equal(t, PEStatusLoss, valuation.PEStatus)
+}
+
+func TestNewValuation_Unavailable(t *testing.T) {
+	reports := []PerformanceReport{{ReportDate: 20241231, EPS: 1, BVPS: -2}}
+
+	// 没有K线数据
+	valuation := NewValuation("000003.SZ", "", nil, reports)
+	assert.Nil(t, valuation.PE)
+	assert.Nil(t, valuation.PB)
+	assert.Equal(t, PEStatusUnavailable, valuation.PEStatus)
+	require.NotNil(t, valuation.TTMEPS)
+
+	// 每股净资产为负时不计算市净率
+	valuation = NewValuation("000003.SZ", "", &DailyData{TradeDate: 20250815, Close: 8}, reports)
+	require.NotNil(t, valuation.PE)
+	assert.Equal(t, 8.0, *valuation.PE)
+	assert.Nil(t, valuation.PB)
+
+	// 历史报表不足
+	valuation = NewValuation("000003.SZ", "", &DailyData{TradeDate: 20250815, Close: 8}, []PerformanceReport{{ReportDate: 20250331, EPS: 0.2, BVPS: 5}})
+	assert.Nil(t, valuation.TTMEPS)
+	assert.Nil(t, valuation.PE)
+	assert.Equal(t, PEStatusUnavailable, valuation.PEStatus)
+	require.NotNil(t, valuation.PB)
+	assert.Equal(t, 1.6, *valuation.PB)
+}
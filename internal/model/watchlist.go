@@ -0,0 +1,34 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Watchlist 自选股列表
+type Watchlist struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:50;not null;uniqueIndex"` // 列表名称
+	Description string    `json:"description" gorm:"size:200"`              // 列表说明
+	TsCodes     string    `json:"ts_codes" gorm:"type:text"`                // 股票代码，逗号分隔
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Watchlist) TableName() string {
+	return "watchlists"
+}
+
+// Codes 获取列表中的股票代码
+func (w *Watchlist) Codes() []string {
+	if w.TsCodes == "" {
+		return []string{}
+	}
+	return strings.Split(w.TsCodes, ",")
+}
+
+// SetCodes 设置列表中的股票代码，统一转为大写并去除空值和重复项，保留原有顺序
+func (w *Watchlist) SetCodes(codes []string) {
+	w.TsCodes = strings.Join(normalizeTsCodes(codes), ",")
+}
@@ -0,0 +1,156 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 各机器人单条消息内容的默认字节上限
+const (
+	DefaultDingTalkMaxBytes = 20000 // 钉钉文本和Markdown消息上限为20000字节
+	DefaultWeWorkMaxBytes   = 2048  // 企微文本消息上限为2048字节（Markdown为4096字节），取较小值
+)
+
+// maxMessageChunks 单条消息最多拆分的条数，超出部分截断为“...及其余N行未显示”
+const maxMessageChunks = 5
+
+// fenceReserve 为代码块跨条拆分时补充的起止标记预留的字节数
+const fenceReserve = len("```\n") + len("\n```")
+
+// messageChunk 拆分后的单条消息，inFence表示该条开头处于代码块中
+type messageChunk struct {
+	lines   []string
+	inFence bool
+}
+
+// isFence 判断该行是否为Markdown代码块的起止标记
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "```")
+}
+
+// render 生成该条消息的内容，处于代码块中的开头和结尾分别补全代码块标记，避免Markdown被截断在元素中间
+func (c messageChunk) render() string {
+	var sb strings.Builder
+	if c.inFence {
+		sb.WriteString("```\n")
+	}
+	inFence := c.inFence
+	for i, line := range c.lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line)
+		if isFence(line) {
+			inFence = !inFence
+		}
+	}
+	if inFence {
+		sb.WriteString("\n```")
+	}
+	return sb.String()
+}
+
+// endsInFence 判断该条消息结尾是否仍处于代码块中
+func (c messageChunk) endsInFence() bool {
+	inFence := c.inFence
+	for _, line := range c.lines {
+		if isFence(line) {
+			inFence = !inFence
+		}
+	}
+	return inFence
+}
+
+// splitLongLine 将超过上限的单行按字符边界切分，避免截断多字节字符
+func splitLongLine(line string, maxBytes int) []string {
+	var parts []string
+	for len(line) > maxBytes {
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = maxBytes
+		}
+		parts = append(parts, line[:cut])
+		line = line[cut:]
+	}
+	return append(parts, line)
+}
+
+// SplitMessage 按字节上限拆分消息内容
+// 优先在换行处拆分，保证列表项、标题、表格行等Markdown元素不被拆到两条消息中；代码块跨条时自动补全起止标记；
+// 超长的单行按字符边界切分。拆分条数超过maxChunks时，末条替换为“...及其余N行未显示”的提示。
+// maxBytes<=0表示不限制，maxChunks<=0表示不限制条数
+func SplitMessage(content string, maxBytes, maxChunks int) []string {
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return []string{content}
+	}
+
+	budget := maxBytes - fenceReserve
+	if budget <= 0 {
+		budget = maxBytes
+	}
+
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		lines = append(lines, splitLongLine(line, budget)...)
+	}
+
+	var chunks []messageChunk
+	current := messageChunk{}
+	size := 0
+	for _, line := range lines {
+		need := len(line)
+		if len(current.lines) > 0 {
+			need++ // 换行符
+		}
+		if len(current.lines) > 0 && size+need > budget {
+			chunks = append(chunks, current)
+			current = messageChunk{inFence: current.endsInFence()}
+			size, need = 0, len(line)
+		}
+		current.lines = append(current.lines, line)
+		size += need
+	}
+	chunks = append(chunks, current)
+
+	if maxChunks > 0 && len(chunks) > maxChunks {
+		chunks = truncateChunks(chunks, maxChunks, maxBytes)
+	}
+
+	result := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		result[i] = chunk.render()
+	}
+	return result
+}
+
+// truncateChunks 只保留前maxChunks条，在最后一条末尾追加被省略的行数提示
+func truncateChunks(chunks []messageChunk, maxChunks, maxBytes int) []messageChunk {
+	omitted := 0
+	for _, chunk := range chunks[maxChunks:] {
+		omitted += len(chunk.lines)
+	}
+
+	kept := chunks[:maxChunks]
+	last := kept[maxChunks-1]
+	for {
+		suffix := fmt.Sprintf("...及其余 %d 行未显示", omitted)
+		// 提示行追加在代码块之外
+		candidate := last.render() + "\n" + suffix
+		if len(candidate) <= maxBytes || len(last.lines) <= 1 {
+			last.lines = append(append([]string{}, last.lines...), suffix)
+			if last.endsInFence() {
+				// 提示行不能留在代码块内，先关闭代码块
+				last.lines = append(last.lines[:len(last.lines)-1], "```", suffix)
+			}
+			break
+		}
+		last.lines = last.lines[:len(last.lines)-1]
+		omitted++
+	}
+	kept[maxChunks-1] = last
+	return kept
+}
@@ -4,25 +4,54 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config 通知配置
 type Config struct {
 	DingTalk *DingTalkConfig `mapstructure:"dingtalk"`
 	WeWork   *WeWorkConfig   `mapstructure:"wework"`
+	Retry    *RetryConfig    `mapstructure:"retry"`
+	Events   *EventsConfig   `mapstructure:"events"`
+
+	// Templates 采集任务通知模板文件，键为任务类型（daily_kline、performance等），值为text/template模板文件路径
+	Templates map[string]string `mapstructure:"templates"`
 }
 
 // DingTalkConfig 钉钉机器人配置
 type DingTalkConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Webhook string `mapstructure:"webhook"`
-	Secret  string `mapstructure:"secret"`
+	Enabled         bool   `mapstructure:"enabled"`
+	Webhook         string `mapstructure:"webhook"`
+	Secret          string `mapstructure:"secret"`
+	MaxMessageBytes int    `mapstructure:"max_message_bytes"` // 单条消息字节上限，超出时拆分发送，0表示使用默认值
 }
 
 // WeWorkConfig 企微机器人配置
 type WeWorkConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Webhook string `mapstructure:"webhook"`
+	Enabled         bool   `mapstructure:"enabled"`
+	Webhook         string `mapstructure:"webhook"`
+	MaxMessageBytes int    `mapstructure:"max_message_bytes"` // 单条消息字节上限，超出时拆分发送，0表示使用默认值
+}
+
+// RetryConfig 发送失败消息的持久化重试配置
+type RetryConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`  // 是否将发送失败的消息写入pending_notifications表并在恢复后补发
+	Interval time.Duration `mapstructure:"interval"` // 重试间隔，0表示使用默认值
+	MaxAge   time.Duration `mapstructure:"max_age"`  // 最长保留时间，超过后丢弃不再补发，0表示使用默认值
+}
+
+// EventsConfig 任务完成和失败事件的接收端配置，与机器人通知分开
+type EventsConfig struct {
+	Bus     bool                `mapstructure:"bus"`     // 是否开启进程内事件总线，供其他组件订阅
+	Webhook *EventWebhookConfig `mapstructure:"webhook"` // HTTP Webhook接收端
+}
+
+// EventWebhookConfig 任务事件的HTTP Webhook配置
+type EventWebhookConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	URL     string            `mapstructure:"url"`     // 接收事件的地址，事件以JSON POST
+	Headers map[string]string `mapstructure:"headers"` // 额外的请求头，如鉴权Token
+	Timeout time.Duration     `mapstructure:"timeout"` // 请求超时，0表示使用默认值
 }
 
 // LoadConfigFromEnv 从环境变量加载配置
@@ -75,6 +104,17 @@ func MergeWithFileConfig(envConfig, fileConfig *Config) *Config {
 		merged.WeWork = fileConfig.WeWork
 	}
 
+	// 合并重试配置
+	if envConfig.Retry != nil {
+		merged.Retry = envConfig.Retry
+	} else {
+		merged.Retry = fileConfig.Retry
+	}
+
+	// 通知模板和事件接收端只能通过配置文件设置
+	merged.Templates = fileConfig.Templates
+	merged.Events = fileConfig.Events
+
 	return merged
 }
 
@@ -105,6 +145,10 @@ func ValidateConfig(config *Config) error {
 		return fmt.Errorf("至少需要启用一个机器人")
 	}
 
+	if events := config.Events; events != nil && events.Webhook != nil && events.Webhook.Enabled && events.Webhook.URL == "" {
+		return fmt.Errorf("事件Webhook已启用但URL为空")
+	}
+
 	return nil
 }
 
@@ -128,16 +172,32 @@ func MaskSensitiveInfo(config *Config) *Config {
 
 	if config.DingTalk != nil {
 		masked.DingTalk = &DingTalkConfig{
-			Enabled: config.DingTalk.Enabled,
-			Webhook: maskWebhook(config.DingTalk.Webhook),
-			Secret:  maskSecret(config.DingTalk.Secret),
+			Enabled:         config.DingTalk.Enabled,
+			Webhook:         maskWebhook(config.DingTalk.Webhook),
+			Secret:          maskSecret(config.DingTalk.Secret),
+			MaxMessageBytes: config.DingTalk.MaxMessageBytes,
 		}
 	}
 
 	if config.WeWork != nil {
 		masked.WeWork = &WeWorkConfig{
-			Enabled: config.WeWork.Enabled,
-			Webhook: maskWebhook(config.WeWork.Webhook),
+			Enabled:         config.WeWork.Enabled,
+			Webhook:         maskWebhook(config.WeWork.Webhook),
+			MaxMessageBytes: config.WeWork.MaxMessageBytes,
+		}
+	}
+
+	masked.Retry = config.Retry
+	masked.Templates = config.Templates
+
+	if config.Events != nil {
+		masked.Events = &EventsConfig{Bus: config.Events.Bus}
+		if webhook := config.Events.Webhook; webhook != nil {
+			masked.Events.Webhook = &EventWebhookConfig{
+				Enabled: webhook.Enabled,
+				URL:     maskWebhook(webhook.URL),
+				Timeout: webhook.Timeout,
+			}
 		}
 	}
 
@@ -0,0 +1,204 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JobEventType 任务事件类型
+type JobEventType string
+
+const (
+	JobEventCompleted JobEventType = "job.completed" // 任务执行完成，可能有部分股票失败
+	JobEventFailed    JobEventType = "job.failed"    // 任务中止或未能执行
+)
+
+// JobEvent 任务完成或失败事件，与机器人通知分开发布，供外部系统和进程内的其他组件订阅
+type JobEvent struct {
+	Type       JobEventType `json:"type"`            // 事件类型
+	Job        string       `json:"job"`             // 任务名称，采集任务为任务类型，如：daily_kline
+	Total      int          `json:"total"`           // 总数
+	Success    int          `json:"success"`         // 成功数
+	Failed     int          `json:"failed"`          // 失败数
+	DurationMs int64        `json:"duration_ms"`     // 总耗时，单位：毫秒
+	Error      string       `json:"error,omitempty"` // 失败原因
+	StartedAt  time.Time    `json:"started_at"`      // 任务开始时间
+	OccurredAt time.Time    `json:"occurred_at"`     // 事件发生时间
+}
+
+// NewJobCompletedEvent 根据采集任务统计创建任务完成事件
+func NewJobCompletedEvent(summary JobSummary, startedAt time.Time) JobEvent {
+	return JobEvent{
+		Type:       JobEventCompleted,
+		Job:        summary.Job,
+		Total:      summary.Total,
+		Success:    summary.Success,
+		Failed:     summary.Failed,
+		DurationMs: summary.Duration.Milliseconds(),
+		StartedAt:  startedAt,
+		OccurredAt: time.Now(),
+	}
+}
+
+// NewJobFailedEvent 创建任务失败事件
+func NewJobFailedEvent(job string, err error) JobEvent {
+	event := JobEvent{Type: JobEventFailed, Job: job, OccurredAt: time.Now()}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// EventSink 任务事件的接收端
+type EventSink interface {
+	// Name 获取接收端名称，用于日志
+	Name() string
+
+	// Publish 发布事件
+	Publish(ctx context.Context, event JobEvent) error
+}
+
+// WebhookSink 将任务事件以JSON POST到HTTP地址的接收端，2xx以外的响应视为失败
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink 创建HTTP Webhook接收端，timeout<=0时使用10秒
+func NewWebhookSink(url string, headers map[string]string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookSink{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 获取接收端名称
+func (w *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish 发布事件
+func (w *WebhookSink) Publish(ctx context.Context, event JobEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EventBus 进程内的任务事件总线，订阅者在发布事件的协程中依次同步调用，处理耗时的订阅者应自行异步处理
+type EventBus struct {
+	mutex       sync.RWMutex
+	nextID      int
+	subscribers map[int]func(event JobEvent)
+}
+
+// NewEventBus 创建进程内事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]func(event JobEvent))}
+}
+
+// Subscribe 订阅任务事件，返回取消订阅的函数
+func (b *EventBus) Subscribe(handler func(event JobEvent)) (unsubscribe func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = handler
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Name 获取接收端名称
+func (b *EventBus) Name() string {
+	return "bus"
+}
+
+// Publish 将事件分发给所有订阅者
+func (b *EventBus) Publish(ctx context.Context, event JobEvent) error {
+	b.mutex.RLock()
+	handlers := make([]func(event JobEvent), 0, len(b.subscribers))
+	for _, handler := range b.subscribers {
+		handlers = append(handlers, handler)
+	}
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return nil
+}
+
+// RegisterSink 注册任务事件接收端
+func (m *Manager) RegisterSink(sink EventSink) error {
+	if sink == nil {
+		return fmt.Errorf("sink cannot be nil")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sinks = append(m.sinks, sink)
+	if bus, ok := sink.(*EventBus); ok && m.bus == nil {
+		m.bus = bus
+	}
+	m.logger.Infof("Registered %s event sink successfully", sink.Name())
+	return nil
+}
+
+// EventBus 获取已注册的进程内事件总线，未开启时返回nil
+func (m *Manager) EventBus() *EventBus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.bus
+}
+
+// PublishJobEvent 将任务事件发布到所有接收端，单个接收端失败不影响其他接收端，返回合并后的错误
+func (m *Manager) PublishJobEvent(ctx context.Context, event JobEvent) error {
+	m.mutex.RLock()
+	sinks := make([]EventSink, len(m.sinks))
+	copy(sinks, m.sinks)
+	m.mutex.RUnlock()
+
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			m.logger.Errorf("Failed to publish %s event of %s to %s sink: %v", event.Type, event.Job, sink.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink 记录收到的事件，err不为nil时发布失败
+type recordingSink struct {
+	events []JobEvent
+	err    error
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Publish(ctx context.Context, event JobEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestManager_PublishJobEvent(t *testing.T) {
+	manager := NewManager(logger.GetGlobalLogger())
+	failing := &recordingSink{err: errors.New("connection refused")}
+	sink := &recordingSink{}
+	require.NoError(t, manager.RegisterSink(failing))
+	require.NoError(t, manager.RegisterSink(sink))
+	assert.Error(t, manager.RegisterSink(nil))
+
+	startedAt := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	event := NewJobCompletedEvent(JobSummary{
+		Job: JobDailyKLine, Total: 5000, Success: 4998, Failed: 2, Duration: 90 * time.Second,
+	}, startedAt)
+
+	// 单个接收端失败不影响其他接收端，错误中带接收端名称
+	err := manager.PublishJobEvent(context.Background(), event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recording: connection refused")
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, JobEventCompleted, sink.events[0].Type)
+	assert.Equal(t, JobDailyKLine, sink.events[0].Job)
+	assert.Equal(t, 4998, sink.events[0].Success)
+	assert.Equal(t, 2, sink.events[0].Failed)
+	assert.Equal(t, int64(90000), sink.events[0].DurationMs)
+	assert.Equal(t, startedAt, sink.events[0].StartedAt)
+
+	// 未注册接收端时不发布
+	assert.NoError(t, NewManager(logger.GetGlobalLogger()).PublishJobEvent(context.Background(), event))
+}
+
+func TestEventBus_Subscribe(t *testing.T) {
+	manager := NewManager(logger.GetGlobalLogger())
+	assert.Nil(t, manager.EventBus())
+	require.NoError(t, manager.RegisterSink(NewEventBus()))
+	bus := manager.EventBus()
+	require.NotNil(t, bus)
+
+	var received []JobEvent
+	unsubscribe := bus.Subscribe(func(event JobEvent) {
+		received = append(received, event)
+	})
+
+	failed := NewJobFailedEvent("周K线数据采集", errors.New("data inconsistency"))
+	require.NoError(t, manager.PublishJobEvent(context.Background(), failed))
+	require.Len(t, received, 1)
+	assert.Equal(t, JobEventFailed, received[0].Type)
+	assert.Equal(t, "data inconsistency", received[0].Error)
+
+	// 取消订阅后不再收到事件
+	unsubscribe()
+	require.NoError(t, manager.PublishJobEvent(context.Background(), failed))
+	assert.Len(t, received, 1)
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	var got JobEvent
+	var token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("Authorization")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got.Job == "rejected" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, map[string]string{"Authorization": "Bearer secret"}, time.Second)
+	event := NewJobCompletedEvent(JobSummary{Job: JobPerformance, Total: 10, Success: 10}, time.Now())
+	require.NoError(t, sink.Publish(context.Background(), event))
+	assert.Equal(t, "Bearer secret", token)
+	assert.Equal(t, JobEventCompleted, got.Type)
+	assert.Equal(t, JobPerformance, got.Job)
+	assert.Equal(t, 10, got.Success)
+
+	err := sink.Publish(context.Background(), NewJobFailedEvent("rejected", nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestFactory_CreateManagerWithEventSinks(t *testing.T) {
+	manager, err := NewFactory(logger.GetGlobalLogger()).CreateManager(&Config{
+		WeWork: &WeWorkConfig{Enabled: true, Webhook: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test"},
+		Events: &EventsConfig{Bus: true, Webhook: &EventWebhookConfig{Enabled: true, URL: "http://127.0.0.1:1/hooks"}},
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, manager.EventBus())
+	assert.Len(t, manager.sinks, 2)
+
+	_, err = NewFactory(logger.GetGlobalLogger()).CreateManager(&Config{
+		WeWork: &WeWorkConfig{Enabled: true, Webhook: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test"},
+		Events: &EventsConfig{Webhook: &EventWebhookConfig{Enabled: true}},
+	})
+	assert.Error(t, err)
+}
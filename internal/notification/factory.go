@@ -36,12 +36,19 @@ func (f *Factory) CreateManager(config *Config) (*Manager, error) {
 
 	manager := NewManager(f.logger)
 
+	templates, err := LoadJobTemplates(config.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	manager.SetJobTemplates(templates)
+
 	// 创建钉钉机器人
 	if config.DingTalk != nil && config.DingTalk.Enabled && config.DingTalk.Webhook != "" {
 		dingTalkBot := NewDingTalkBot(config.DingTalk.Webhook, config.DingTalk.Secret)
 		if err := manager.RegisterBot(BotTypeDingTalk, dingTalkBot); err != nil {
 			return nil, fmt.Errorf("failed to register dingtalk bot: %v", err)
 		}
+		manager.SetMessageLimit(BotTypeDingTalk, config.DingTalk.MaxMessageBytes)
 		f.logger.Infof("DingTalk bot registered successfully")
 	}
 
@@ -51,9 +58,24 @@ func (f *Factory) CreateManager(config *Config) (*Manager, error) {
 		if err := manager.RegisterBot(BotTypeWeWork, weWorkBot); err != nil {
 			return nil, fmt.Errorf("failed to register wework bot: %v", err)
 		}
+		manager.SetMessageLimit(BotTypeWeWork, config.WeWork.MaxMessageBytes)
 		f.logger.Infof("WeWork bot registered successfully")
 	}
 
+	// 注册任务事件接收端
+	if events := config.Events; events != nil {
+		if events.Bus {
+			if err := manager.RegisterSink(NewEventBus()); err != nil {
+				return nil, fmt.Errorf("failed to register event bus: %v", err)
+			}
+		}
+		if webhook := events.Webhook; webhook != nil && webhook.Enabled && webhook.URL != "" {
+			if err := manager.RegisterSink(NewWebhookSink(webhook.URL, webhook.Headers, webhook.Timeout)); err != nil {
+				return nil, fmt.Errorf("failed to register event webhook: %v", err)
+			}
+		}
+	}
+
 	return manager, nil
 }
 
@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 采集任务类型，作为通知模板的名称
+const (
+	JobDailyKLine   = "daily_kline"   // 日K线采集
+	JobWeeklyKLine  = "weekly_kline"  // 周K线采集
+	JobMonthlyKLine = "monthly_kline" // 月K线采集
+	JobYearlyKLine  = "yearly_kline"  // 年K线采集
+	JobPerformance  = "performance"   // 业绩报表采集
+	JobShareholder  = "shareholder"   // 股东人数采集
+	JobNorthbound   = "northbound"    // 北向持股采集
+)
+
+// JobSummary 采集任务完成后的统计，模板中可用的变量：
+// {{.Job}} 任务类型，{{.Total}} 总数，{{.Success}} 成功数，{{.Failed}} 失败数，
+// {{.Duration}} 总耗时，{{.AverageDuration}} 平均耗时
+type JobSummary struct {
+	Job             string
+	Total           int
+	Success         int
+	Failed          int
+	Duration        time.Duration
+	AverageDuration time.Duration
+}
+
+// defaultJobTemplates 各采集任务的默认通知模板，未配置模板文件时使用
+var defaultJobTemplates = map[string]string{
+	JobDailyKLine:   "📊 日K线数据采集完成\n" + jobSummaryBody,
+	JobWeeklyKLine:  "📊 周K线数据采集完成\n" + jobSummaryBody,
+	JobMonthlyKLine: "📊 月K线数据采集完成\n" + jobSummaryBody,
+	JobYearlyKLine:  "📊 年K线数据采集完成\n" + jobSummaryBody,
+	JobPerformance:  "📈 业绩报表采集完成\n" + jobSummaryBody,
+	JobShareholder:  "👥 股东人数采集完成\n" + jobSummaryBody,
+	JobNorthbound:   "🧭 北向持股采集完成\n" + jobSummaryBody,
+}
+
+// jobSummaryBody 默认模板中的统计部分
+const jobSummaryBody = "总数: {{.Total}}\n成功: {{.Success}}\n失败: {{.Failed}}\n总耗时: {{.Duration}}\n平均耗时: {{.AverageDuration}}"
+
+// JobTemplates 采集任务通知模板，键为任务类型
+type JobTemplates struct {
+	templates map[string]*template.Template
+}
+
+// LoadJobTemplates 加载采集任务通知模板，files的键为任务类型、值为text/template模板文件路径
+// 配置了文件的任务使用文件中的模板，其余任务使用默认模板；任务类型未知或模板无法解析时返回错误
+func LoadJobTemplates(files map[string]string) (*JobTemplates, error) {
+	t := &JobTemplates{templates: make(map[string]*template.Template, len(defaultJobTemplates))}
+	for job, text := range defaultJobTemplates {
+		tmpl, err := template.New(job).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse default template %s: %w", job, err)
+		}
+		t.templates[job] = tmpl
+	}
+
+	for job, path := range files {
+		if _, ok := defaultJobTemplates[job]; !ok {
+			return nil, fmt.Errorf("unknown notification template %q, valid options: %s", job, strings.Join(JobTemplateNames(), ", "))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", job, err)
+		}
+		tmpl, err := template.New(job).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s from %s: %w", job, path, err)
+		}
+		t.templates[job] = tmpl
+	}
+	return t, nil
+}
+
+// JobTemplateNames 支持自定义模板的任务类型，按名称排序
+func JobTemplateNames() []string {
+	names := make([]string, 0, len(defaultJobTemplates))
+	for name := range defaultJobTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render 按任务类型渲染通知内容
+func (t *JobTemplates) Render(summary JobSummary) (string, error) {
+	tmpl, ok := t.templates[summary.Job]
+	if !ok {
+		return "", fmt.Errorf("no notification template for job %q", summary.Job)
+	}
+
+	var content strings.Builder
+	if err := tmpl.Execute(&content, summary); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", summary.Job, err)
+	}
+	return content.String(), nil
+}
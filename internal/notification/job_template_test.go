@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stock/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleJobSummary(job string) JobSummary {
+	return JobSummary{
+		Job:             job,
+		Total:           5000,
+		Success:         4990,
+		Failed:          10,
+		Duration:        12*time.Minute + 30*time.Second,
+		AverageDuration: 150 * time.Millisecond,
+	}
+}
+
+func TestJobTemplates_DefaultTemplate(t *testing.T) {
+	templates, err := LoadJobTemplates(nil)
+	require.NoError(t, err)
+
+	content, err := templates.Render(sampleJobSummary(JobDailyKLine))
+	require.NoError(t, err)
+	assert.Equal(t, "📊 日K线数据采集完成\n总数: 5000\n成功: 4990\n失败: 10\n总耗时: 12m30s\n平均耗时: 150ms", content)
+
+	for _, job := range JobTemplateNames() {
+		_, err := templates.Render(sampleJobSummary(job))
+		assert.NoError(t, err, job)
+	}
+	_, err = templates.Render(sampleJobSummary("unknown"))
+	assert.Error(t, err)
+}
+
+func TestJobTemplates_CustomTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daily.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"[{{.Job}}] Daily bars done: {{.Success}}/{{.Total}}"+
+			"{{if gt .Failed 0}}, {{.Failed}} failed{{end}} in {{.Duration}}"), 0o644))
+
+	templates, err := LoadJobTemplates(map[string]string{JobDailyKLine: path})
+	require.NoError(t, err)
+
+	content, err := templates.Render(sampleJobSummary(JobDailyKLine))
+	require.NoError(t, err)
+	assert.Equal(t, "[daily_kline] Daily bars done: 4990/5000, 10 failed in 12m30s", content)
+
+	// 未配置文件的任务仍使用默认模板
+	content, err = templates.Render(sampleJobSummary(JobPerformance))
+	require.NoError(t, err)
+	assert.Contains(t, content, "业绩报表采集完成")
+
+	// 发送时按任务类型渲染
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &RecordingBot{botType: BotTypeWeWork}
+	require.NoError(t, manager.RegisterBot(BotTypeWeWork, bot))
+	manager.SetJobTemplates(templates)
+	require.NoError(t, manager.SendJobSummary(context.Background(), sampleJobSummary(JobDailyKLine)))
+	require.Len(t, bot.messages, 1)
+	assert.Equal(t, "[daily_kline] Daily bars done: 4990/5000, 10 failed in 12m30s", bot.messages[0].Content)
+}
+
+func TestJobTemplates_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadJobTemplates(map[string]string{"hourly_kline": filepath.Join(dir, "x.tmpl")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), JobDailyKLine)
+
+	_, err = LoadJobTemplates(map[string]string{JobDailyKLine: filepath.Join(dir, "missing.tmpl")})
+	assert.Error(t, err)
+
+	broken := filepath.Join(dir, "broken.tmpl")
+	require.NoError(t, os.WriteFile(broken, []byte("{{.Total"), 0o644))
+	_, err = LoadJobTemplates(map[string]string{JobDailyKLine: broken})
+	assert.Error(t, err)
+
+	// 引用不存在的变量在渲染时报错，而不是发出残缺的消息
+	typo := filepath.Join(dir, "typo.tmpl")
+	require.NoError(t, os.WriteFile(typo, []byte("{{.Totl}}"), 0o644))
+	templates, err := LoadJobTemplates(map[string]string{JobDailyKLine: typo})
+	require.NoError(t, err)
+	_, err = templates.Render(sampleJobSummary(JobDailyKLine))
+	assert.Error(t, err)
+}
@@ -10,6 +10,11 @@ import (
 // Manager 通知管理器实现
 type Manager struct {
 	bots   map[BotType]NotificationBot
+	limits map[BotType]int // 各机器人单条消息的字节上限
+	retry  *retryQueue     // 持久化重试队列，为nil表示未开启
+	jobs   *JobTemplates   // 采集任务通知模板，为nil时使用默认模板
+	sinks  []EventSink     // 任务事件接收端
+	bus    *EventBus       // 进程内事件总线，为nil表示未开启
 	mutex  sync.RWMutex
 	logger *logger.Logger
 }
@@ -18,6 +23,7 @@ type Manager struct {
 func NewManager(logger *logger.Logger) *Manager {
 	return &Manager{
 		bots:   make(map[BotType]NotificationBot),
+		limits: make(map[BotType]int),
 		logger: logger,
 	}
 }
@@ -36,6 +42,145 @@ func (m *Manager) RegisterBot(botType BotType, bot NotificationBot) error {
 	return nil
 }
 
+// SetMessageLimit 设置指定机器人单条消息的字节上限，maxBytes<=0时恢复为该类型的默认上限
+func (m *Manager) SetMessageLimit(botType BotType, maxBytes int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if maxBytes <= 0 {
+		delete(m.limits, botType)
+		return
+	}
+	m.limits[botType] = maxBytes
+}
+
+// messageLimit 获取指定机器人单条消息的字节上限，0表示不限制
+func (m *Manager) messageLimit(botType BotType) int {
+	m.mutex.RLock()
+	limit, ok := m.limits[botType]
+	m.mutex.RUnlock()
+	if ok {
+		return limit
+	}
+
+	switch botType {
+	case BotTypeDingTalk:
+		return DefaultDingTalkMaxBytes
+	case BotTypeWeWork:
+		return DefaultWeWorkMaxBytes
+	default:
+		return 0
+	}
+}
+
+// SetJobTemplates 设置采集任务通知模板
+func (m *Manager) SetJobTemplates(templates *JobTemplates) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.jobs = templates
+}
+
+// SendJobSummary 按任务类型的通知模板渲染采集任务统计并发送给所有机器人
+func (m *Manager) SendJobSummary(ctx context.Context, summary JobSummary) error {
+	m.mutex.RLock()
+	templates := m.jobs
+	m.mutex.RUnlock()
+
+	if templates == nil {
+		var err error
+		if templates, err = LoadJobTemplates(nil); err != nil {
+			return err
+		}
+	}
+
+	content, err := templates.Render(summary)
+	if err != nil {
+		m.logger.Errorf("Failed to render %s notification: %v", summary.Job, err)
+		return err
+	}
+	return m.SendToAllBots(ctx, &Message{
+		Content: content,
+		MsgType: MessageTypeText,
+	})
+}
+
+// partialSendError 拆分后的消息从第part条起发送失败，unsent为未送达的各条消息，写入重试队列时只补发这些消息
+type partialSendError struct {
+	part   int
+	total  int
+	unsent []*Message
+	err    error
+}
+
+func (e *partialSendError) Error() string {
+	return fmt.Sprintf("part %d/%d: %v", e.part, e.total, e.err)
+}
+
+func (e *partialSendError) Unwrap() error {
+	return e.err
+}
+
+// sendParts 逐条发送拆分后的消息，失败时返回带未送达消息的partialSendError
+func sendParts(parts []*Message, send func(part *Message) error) error {
+	for i, part := range parts {
+		if err := send(part); err != nil {
+			return &partialSendError{part: i + 1, total: len(parts), unsent: parts[i:], err: err}
+		}
+	}
+	return nil
+}
+
+// sendMessage 按机器人的消息上限拆分后逐条发送，@提醒只随第一条发送
+func (m *Manager) sendMessage(ctx context.Context, botType BotType, bot NotificationBot, message *Message) error {
+	if message.MsgType != MessageTypeText && message.MsgType != MessageTypeMarkdown {
+		return bot.SendMessage(ctx, message)
+	}
+
+	chunks := SplitMessage(message.Content, m.messageLimit(botType), maxMessageChunks)
+	if len(chunks) == 1 {
+		return bot.SendMessage(ctx, message)
+	}
+
+	m.logger.Infof("Message to %s bot exceeds size limit, split into %d parts", botType, len(chunks))
+	parts := make([]*Message, len(chunks))
+	for i, chunk := range chunks {
+		part := *message
+		part.Content = chunk
+		if i > 0 {
+			part.AtMobiles = nil
+			part.AtAll = false
+		}
+		if title, ok := message.Extra["title"].(string); ok {
+			part.Extra = make(map[string]interface{}, len(message.Extra))
+			for k, v := range message.Extra {
+				part.Extra[k] = v
+			}
+			part.Extra["title"] = fmt.Sprintf("%s (%d/%d)", title, i+1, len(chunks))
+		}
+		parts[i] = &part
+	}
+	return sendParts(parts, func(part *Message) error {
+		return bot.SendMessage(ctx, part)
+	})
+}
+
+// sendMarkdown 按机器人的消息上限拆分Markdown内容后逐条发送，多条时标题追加序号
+func (m *Manager) sendMarkdown(ctx context.Context, botType BotType, bot NotificationBot, title, content string) error {
+	chunks := SplitMessage(content, m.messageLimit(botType), maxMessageChunks)
+	if len(chunks) == 1 {
+		return bot.SendMarkdown(ctx, title, content)
+	}
+
+	m.logger.Infof("Markdown to %s bot exceeds size limit, split into %d parts", botType, len(chunks))
+	parts := make([]*Message, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = markdownMessage(fmt.Sprintf("%s (%d/%d)", title, i+1, len(chunks)), chunk)
+	}
+	return sendParts(parts, func(part *Message) error {
+		return bot.SendMarkdown(ctx, part.Extra["title"].(string), part.Content)
+	})
+}
+
 // SendToBot 发送消息到指定类型的机器人
 func (m *Manager) SendToBot(ctx context.Context, botType BotType, message *Message) error {
 	m.mutex.RLock()
@@ -47,7 +192,11 @@ func (m *Manager) SendToBot(ctx context.Context, botType BotType, message *Messa
 	}
 
 	m.logger.Infof("Sending message to %s bot", botType)
-	return bot.SendMessage(ctx, message)
+	if err := m.sendMessage(ctx, botType, bot, message); err != nil {
+		m.enqueue(botType, message, err)
+		return err
+	}
+	return nil
 }
 
 // SendToAllBots 发送消息到所有机器人
@@ -65,8 +214,9 @@ func (m *Manager) SendToAllBots(ctx context.Context, message *Message) error {
 
 	var errors []error
 	for botType, bot := range bots {
-		if err := bot.SendMessage(ctx, message); err != nil {
+		if err := m.sendMessage(ctx, botType, bot, message); err != nil {
 			m.logger.Errorf("Failed to send message to %s bot: %v", botType, err)
+			m.enqueue(botType, message, err)
 			errors = append(errors, fmt.Errorf("%s: %v", botType, err))
 		} else {
 			m.logger.Infof("Message sent to %s bot successfully", botType)
@@ -111,7 +261,11 @@ func (m *Manager) SendMarkdownToBot(ctx context.Context, botType BotType, title,
 		return fmt.Errorf("bot type %s not registered", botType)
 	}
 
-	return bot.SendMarkdown(ctx, title, content)
+	if err := m.sendMarkdown(ctx, botType, bot, title, content); err != nil {
+		m.enqueue(botType, markdownMessage(title, content), err)
+		return err
+	}
+	return nil
 }
 
 // SendMarkdownToAllBots 发送Markdown消息到所有机器人
@@ -129,8 +283,9 @@ func (m *Manager) SendMarkdownToAllBots(ctx context.Context, title, content stri
 
 	var errors []error
 	for botType, bot := range bots {
-		if err := bot.SendMarkdown(ctx, title, content); err != nil {
+		if err := m.sendMarkdown(ctx, botType, bot, title, content); err != nil {
 			m.logger.Errorf("Failed to send markdown to %s bot: %v", botType, err)
+			m.enqueue(botType, markdownMessage(title, content), err)
 			errors = append(errors, fmt.Errorf("%s: %v", botType, err))
 		} else {
 			m.logger.Infof("Markdown sent to %s bot successfully", botType)
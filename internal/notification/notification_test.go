@@ -2,11 +2,15 @@ package notification
 
 import (
 	"context"
+	"fmt"
 	"stock/internal/logger"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"stock/internal/model"
 )
 
@@ -122,3 +126,371 @@ func (m *MockBot) SendCard(ctx context.Context, card *Card) error {
 func (m *MockBot) GetBotType() BotType {
 	return m.botType
 }
+
+func TestSplitMessage(t *testing.T) {
+	// 未超出上限时原样返回
+	assert.Equal(t, []string{"short"}, SplitMessage("short", 100, 5))
+	assert.Equal(t, []string{"no limit"}, SplitMessage("no limit", 0, 5))
+
+	// 生成包含数百只失败股票的汇总消息
+	var sb strings.Builder
+	sb.WriteString("## 日K线更新完成\n")
+	sb.WriteString("失败股票列表：\n")
+	for i := 0; i < 300; i++ {
+		sb.WriteString(fmt.Sprintf("- %06d.SZ 获取数据失败\n", i))
+	}
+	content := strings.TrimSuffix(sb.String(), "\n")
+
+	chunks := SplitMessage(content, 1000, 0)
+	require.Greater(t, len(chunks), 1)
+
+	var lines []string
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 1000)
+		lines = append(lines, strings.Split(chunk, "\n")...)
+	}
+	// 按行拆分，拼接后内容不变，列表项不会被拆开
+	assert.Equal(t, strings.Split(content, "\n"), lines)
+}
+
+func TestSplitMessage_CodeFence(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("失败明细\n```\n")
+	for i := 0; i < 100; i++ {
+		sb.WriteString(fmt.Sprintf("%06d.SH timeout\n", i))
+	}
+	sb.WriteString("```\n结束")
+
+	chunks := SplitMessage(sb.String(), 500, 0)
+	require.Greater(t, len(chunks), 1)
+	for i, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 500)
+		// 每条消息内代码块标记成对出现
+		assert.Equal(t, 0, strings.Count(chunk, "```")%2, "chunk %d has unbalanced code fence", i)
+	}
+	assert.True(t, strings.HasPrefix(chunks[1], "```\n"))
+}
+
+func TestSplitMessage_TruncateAndLongLine(t *testing.T) {
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("第%03d行", i))
+	}
+
+	chunks := SplitMessage(strings.Join(lines, "\n"), 200, 3)
+	require.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 200)
+	}
+	assert.Contains(t, chunks[2], "...及其余")
+	assert.Contains(t, chunks[2], "行未显示")
+
+	// 超长单行按字符边界切分，不产生非法UTF-8
+	long := strings.Repeat("股票", 200)
+	parts := SplitMessage(long, 100, 0)
+	require.Greater(t, len(parts), 1)
+	assert.Equal(t, long, strings.Join(parts, ""))
+	for _, p := range parts {
+		assert.True(t, utf8.ValidString(p))
+		assert.LessOrEqual(t, len(p), 100)
+	}
+}
+
+func TestManager_SplitsOversizedMessage(t *testing.T) {
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &RecordingBot{botType: BotTypeWeWork}
+	assert.NoError(t, manager.RegisterBot(BotTypeWeWork, bot))
+	manager.SetMessageLimit(BotTypeWeWork, 300)
+
+	var lines []string
+	for i := 0; i < 60; i++ {
+		lines = append(lines, fmt.Sprintf("%06d.SZ", i))
+	}
+	err := manager.SendToAllBots(context.Background(), &Message{
+		Content: strings.Join(lines, "\n"),
+		MsgType: MessageTypeText,
+		AtAll:   true,
+	})
+	assert.NoError(t, err)
+
+	require.Greater(t, len(bot.messages), 1)
+	var received []string
+	for i, msg := range bot.messages {
+		assert.LessOrEqual(t, len(msg.Content), 300)
+		assert.Equal(t, i == 0, msg.AtAll, "only the first part mentions everyone")
+		received = append(received, strings.Split(msg.Content, "\n")...)
+	}
+	assert.Equal(t, lines, received)
+
+	// Markdown标题追加序号
+	err = manager.SendMarkdownToBot(context.Background(), BotTypeWeWork, "汇总", strings.Join(lines, "\n"))
+	assert.NoError(t, err)
+	require.Greater(t, len(bot.titles), 1)
+	assert.Equal(t, fmt.Sprintf("汇总 (1/%d)", len(bot.titles)), bot.titles[0])
+}
+
+// RecordingBot 记录收到的消息的模拟机器人
+type RecordingBot struct {
+	botType  BotType
+	messages []*Message
+	titles   []string
+}
+
+func (m *RecordingBot) SendMessage(ctx context.Context, message *Message) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *RecordingBot) SendMarkdown(ctx context.Context, title, content string) error {
+	m.titles = append(m.titles, title)
+	return nil
+}
+
+func (m *RecordingBot) SendCard(ctx context.Context, card *Card) error {
+	return nil
+}
+
+func (m *RecordingBot) GetBotType() BotType {
+	return m.botType
+}
+
+// FlakyBot 可切换是否发送失败的机器人
+type FlakyBot struct {
+	RecordingBot
+	down bool
+}
+
+func (m *FlakyBot) SendMessage(ctx context.Context, message *Message) error {
+	if m.down {
+		return fmt.Errorf("webhook unavailable")
+	}
+	return m.RecordingBot.SendMessage(ctx, message)
+}
+
+func (m *FlakyBot) SendMarkdown(ctx context.Context, title, content string) error {
+	if m.down {
+		return fmt.Errorf("webhook unavailable")
+	}
+	return m.RecordingBot.SendMarkdown(ctx, title, content)
+}
+
+// memoryPendingStore 内存实现的待重发通知存储
+type memoryPendingStore struct {
+	items  []model.PendingNotification
+	nextID uint
+	now    func() time.Time
+}
+
+func (s *memoryPendingStore) Save(item *model.PendingNotification) error {
+	if item.ID == 0 {
+		s.nextID++
+		item.ID = s.nextID
+		item.CreatedAt = s.now()
+		s.items = append(s.items, *item)
+		return nil
+	}
+	for i := range s.items {
+		if s.items[i].ID == item.ID {
+			s.items[i] = *item
+		}
+	}
+	return nil
+}
+
+func (s *memoryPendingStore) GetPending(limit int) ([]model.PendingNotification, error) {
+	items := append([]model.PendingNotification{}, s.items...)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *memoryPendingStore) Delete(id uint) error {
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestRetryQueue_EnqueueOnFailureDeliverOnRecovery(t *testing.T) {
+	now := time.Date(2025, 9, 10, 18, 0, 0, 0, time.Local)
+	store := &memoryPendingStore{now: func() time.Time { return now }}
+
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &FlakyBot{RecordingBot: RecordingBot{botType: BotTypeDingTalk}, down: true}
+	require.NoError(t, manager.RegisterBot(BotTypeDingTalk, bot))
+	manager.EnableRetryQueue(store, time.Hour)
+	manager.retry.now = func() time.Time { return now }
+
+	// 所有机器人都不可用时消息写入队列
+	err := manager.SendToAllBots(context.Background(), &Message{
+		Content: "日K线更新完成", MsgType: MessageTypeText, AtMobiles: []string{"13800000000"},
+	})
+	assert.Error(t, err)
+	assert.Error(t, manager.SendMarkdownToAllBots(context.Background(), "每日汇总", "## 汇总"))
+	require.Len(t, store.items, 2)
+	assert.Equal(t, "webhook unavailable", store.items[0].LastError)
+
+	// 仍不可用时保留消息并累计重试次数
+	delivered, dropped, err := manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 0, dropped)
+	require.Len(t, store.items, 2)
+	assert.Equal(t, 1, store.items[0].Attempts)
+	assert.Equal(t, 0, store.items[1].Attempts, "同一机器人失败后本轮不再尝试后续消息")
+
+	// 恢复后按入队顺序补发
+	bot.down = false
+	delivered, dropped, err = manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, 0, dropped)
+	assert.Empty(t, store.items)
+	require.Len(t, bot.messages, 2)
+	assert.Equal(t, "日K线更新完成", bot.messages[0].Content)
+	assert.Equal(t, []string{"13800000000"}, bot.messages[0].AtMobiles)
+	assert.Equal(t, MessageTypeMarkdown, bot.messages[1].MsgType)
+	assert.Equal(t, "每日汇总", bot.messages[1].Extra["title"])
+}
+
+func TestRetryQueue_DropExpired(t *testing.T) {
+	now := time.Date(2025, 9, 10, 18, 0, 0, 0, time.Local)
+	store := &memoryPendingStore{now: func() time.Time { return now }}
+
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &FlakyBot{RecordingBot: RecordingBot{botType: BotTypeWeWork}, down: true}
+	require.NoError(t, manager.RegisterBot(BotTypeWeWork, bot))
+	manager.EnableRetryQueue(store, time.Hour)
+
+	assert.Error(t, manager.SendToBot(context.Background(), BotTypeWeWork, &Message{Content: "告警", MsgType: MessageTypeText}))
+	require.Len(t, store.items, 1)
+
+	// 超过最长保留时间后丢弃，不再补发
+	bot.down = false
+	manager.retry.now = func() time.Time { return now.Add(2 * time.Hour) }
+	delivered, dropped, err := manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, dropped)
+	assert.Empty(t, store.items)
+	assert.Empty(t, bot.messages)
+}
+
+// FailAfterBot 成功发送failAfter条消息后开始失败的机器人
+type FailAfterBot struct {
+	RecordingBot
+	failAfter int
+}
+
+func (m *FailAfterBot) SendMessage(ctx context.Context, message *Message) error {
+	if len(m.messages)+len(m.titles) >= m.failAfter {
+		return fmt.Errorf("webhook unavailable")
+	}
+	return m.RecordingBot.SendMessage(ctx, message)
+}
+
+func (m *FailAfterBot) SendMarkdown(ctx context.Context, title, content string) error {
+	if len(m.messages)+len(m.titles) >= m.failAfter {
+		return fmt.Errorf("webhook unavailable")
+	}
+	return m.RecordingBot.SendMarkdown(ctx, title, content)
+}
+
+func TestRetryQueue_EnqueueOnlyUnsentParts(t *testing.T) {
+	now := time.Date(2025, 9, 10, 18, 0, 0, 0, time.Local)
+	store := &memoryPendingStore{now: func() time.Time { return now }}
+
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &FailAfterBot{RecordingBot: RecordingBot{botType: BotTypeWeWork}, failAfter: 2}
+	require.NoError(t, manager.RegisterBot(BotTypeWeWork, bot))
+	manager.SetMessageLimit(BotTypeWeWork, 300)
+	manager.EnableRetryQueue(store, time.Hour)
+	manager.retry.now = func() time.Time { return now }
+
+	var lines []string
+	for i := 0; i < 60; i++ {
+		lines = append(lines, fmt.Sprintf("%06d.SZ", i))
+	}
+	content := strings.Join(lines, "\n")
+	chunks := SplitMessage(content, 300, maxMessageChunks)
+	require.Greater(t, len(chunks), 2)
+
+	// 前两条送达后失败，只有剩余的各条写入队列
+	err := manager.SendToBot(context.Background(), BotTypeWeWork, &Message{Content: content, MsgType: MessageTypeText, AtAll: true})
+	assert.Error(t, err)
+	require.Len(t, bot.messages, 2)
+	require.Len(t, store.items, len(chunks)-2)
+	for i, item := range store.items {
+		assert.Equal(t, chunks[i+2], item.Content)
+		assert.False(t, item.AtAll, "@提醒已随第一条送达")
+	}
+
+	// 恢复后补发剩余部分，收到的内容与原消息一致且不重复
+	bot.failAfter = 100
+	delivered, _, err := manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(chunks)-2, delivered)
+	var received []string
+	for _, msg := range bot.messages {
+		received = append(received, strings.Split(msg.Content, "\n")...)
+	}
+	assert.Equal(t, lines, received)
+
+	// Markdown同样只补发未送达的部分，标题保留序号
+	store.items = nil
+	bot.messages, bot.titles = nil, nil
+	bot.failAfter = 1
+	assert.Error(t, manager.SendMarkdownToBot(context.Background(), BotTypeWeWork, "汇总", content))
+	require.Len(t, store.items, len(chunks)-1)
+	assert.Equal(t, fmt.Sprintf("汇总 (2/%d)", len(chunks)), store.items[0].Title)
+	assert.Equal(t, string(MessageTypeMarkdown), store.items[0].MsgType)
+}
+
+func TestRetryQueue_DrainKeepsOnlyUnsentParts(t *testing.T) {
+	now := time.Date(2025, 9, 10, 18, 0, 0, 0, time.Local)
+	store := &memoryPendingStore{now: func() time.Time { return now }}
+
+	manager := NewManager(logger.GetGlobalLogger())
+	bot := &FailAfterBot{RecordingBot: RecordingBot{botType: BotTypeWeWork}, failAfter: 2}
+	require.NoError(t, manager.RegisterBot(BotTypeWeWork, bot))
+	manager.SetMessageLimit(BotTypeWeWork, 300)
+	manager.EnableRetryQueue(store, time.Hour)
+	manager.retry.now = func() time.Time { return now }
+
+	var lines []string
+	for i := 0; i < 60; i++ {
+		lines = append(lines, fmt.Sprintf("%06d.SZ", i))
+	}
+	content := strings.Join(lines, "\n")
+	chunks := SplitMessage(content, 300, maxMessageChunks)
+	require.Greater(t, len(chunks), 2)
+	require.NoError(t, store.Save(&model.PendingNotification{
+		BotType: string(BotTypeWeWork), MsgType: string(MessageTypeText), Content: content, AtAll: true,
+	}))
+
+	// 补发时前两条送达后失败，记录中只保留未送达的部分
+	delivered, _, err := manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	require.Len(t, bot.messages, 2)
+	require.Len(t, store.items, 1)
+	assert.Equal(t, strings.Join(chunks[2:], "\n"), store.items[0].Content)
+	assert.False(t, store.items[0].AtAll, "@提醒已随第一条送达")
+	assert.Equal(t, 1, store.items[0].Attempts)
+
+	// 恢复后补发剩余部分，收到的内容与原消息一致且不重复
+	bot.failAfter = 100
+	delivered, _, err = manager.DrainPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Empty(t, store.items)
+	var received []string
+	for _, msg := range bot.messages {
+		received = append(received, strings.Split(msg.Content, "\n")...)
+	}
+	assert.Equal(t, lines, received)
+}
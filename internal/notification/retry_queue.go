@@ -0,0 +1,250 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"stock/internal/model"
+)
+
+// 待重发通知的默认参数
+const (
+	DefaultRetryInterval = time.Minute    // 默认重试间隔
+	DefaultRetryMaxAge   = 24 * time.Hour // 默认最长保留时间，超过后不再补发
+	retryDrainBatch      = 100            // 每轮重试最多处理的消息数
+	maxLastErrorLength   = 500            // 记录的失败原因最大长度，与表字段长度一致
+)
+
+// PendingStore 待重发通知的持久化存储
+type PendingStore interface {
+	// Save 保存待重发的通知，ID为0时新增
+	Save(item *model.PendingNotification) error
+
+	// GetPending 按入队顺序获取待重发的通知，limit<=0表示不限制
+	GetPending(limit int) ([]model.PendingNotification, error)
+
+	// Delete 删除已送达或已过期的通知
+	Delete(id uint) error
+}
+
+// retryQueue 持久化的通知重试队列
+type retryQueue struct {
+	store  PendingStore
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// EnableRetryQueue 开启持久化重试队列，发送失败的文本和Markdown消息按机器人写入store，由DrainPending补发
+// maxAge<=0时使用默认最长保留时间
+func (m *Manager) EnableRetryQueue(store PendingStore, maxAge time.Duration) {
+	if maxAge <= 0 {
+		maxAge = DefaultRetryMaxAge
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.retry = &retryQueue{store: store, maxAge: maxAge, now: time.Now}
+}
+
+// getRetryQueue 获取重试队列，未开启时返回nil
+func (m *Manager) getRetryQueue() *retryQueue {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.retry
+}
+
+// enqueue 将发送失败的消息写入重试队列，未开启重试队列或消息类型不支持时忽略
+// 拆分后的消息发送到一半失败时，只将未送达的各条消息按顺序写入队列，避免补发时重复发送已送达的部分
+func (m *Manager) enqueue(botType BotType, message *Message, sendErr error) {
+	queue := m.getRetryQueue()
+	if queue == nil {
+		return
+	}
+
+	var partial *partialSendError
+	if errors.As(sendErr, &partial) {
+		for _, part := range partial.unsent {
+			m.enqueueMessage(queue, botType, part, sendErr)
+		}
+		return
+	}
+	m.enqueueMessage(queue, botType, message, sendErr)
+}
+
+// enqueueMessage 将单条消息写入重试队列
+func (m *Manager) enqueueMessage(queue *retryQueue, botType BotType, message *Message, sendErr error) {
+	if message.MsgType != MessageTypeText && message.MsgType != MessageTypeMarkdown {
+		return
+	}
+
+	item := &model.PendingNotification{
+		BotType:   string(botType),
+		MsgType:   string(message.MsgType),
+		Content:   message.Content,
+		AtMobiles: strings.Join(message.AtMobiles, ","),
+		AtAll:     message.AtAll,
+		LastError: truncateError(sendErr),
+	}
+	if title, ok := message.Extra["title"].(string); ok {
+		item.Title = title
+	}
+
+	if err := queue.store.Save(item); err != nil {
+		m.logger.Errorf("Failed to enqueue notification for %s bot, message lost: %v", botType, err)
+		return
+	}
+	m.logger.Warnf("Notification to %s bot failed, queued for retry (id=%d)", botType, item.ID)
+}
+
+// DrainPending 补发重试队列中的消息，返回本轮送达和因过期丢弃的数量
+// 同一机器人补发失败后本轮不再发送其后的消息，保证按入队顺序送达
+func (m *Manager) DrainPending(ctx context.Context) (delivered, dropped int, err error) {
+	queue := m.getRetryQueue()
+	if queue == nil {
+		return 0, 0, nil
+	}
+
+	items, err := queue.store.GetPending(retryDrainBatch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load pending notifications: %w", err)
+	}
+
+	failedBots := make(map[BotType]bool)
+	for i := range items {
+		if ctx.Err() != nil {
+			return delivered, dropped, ctx.Err()
+		}
+
+		item := &items[i]
+		botType := BotType(item.BotType)
+
+		if queue.now().Sub(item.CreatedAt) > queue.maxAge {
+			m.logger.Warnf("Dropping pending notification %d for %s bot after %d attempts: exceeded max age %v",
+				item.ID, botType, item.Attempts, queue.maxAge)
+			if err := queue.store.Delete(item.ID); err != nil {
+				return delivered, dropped, err
+			}
+			dropped++
+			continue
+		}
+
+		if failedBots[botType] {
+			continue
+		}
+
+		bot, exists := m.GetBot(botType)
+		if !exists {
+			// 机器人可能在配置中被临时关闭，保留消息直至过期
+			continue
+		}
+
+		if sendErr := m.sendMessage(ctx, botType, bot, pendingToMessage(item)); sendErr != nil {
+			failedBots[botType] = true
+			item.Attempts++
+			item.LastError = truncateError(sendErr)
+			keepUnsent(item, sendErr)
+			if err := queue.store.Save(item); err != nil {
+				return delivered, dropped, err
+			}
+			continue
+		}
+
+		if err := queue.store.Delete(item.ID); err != nil {
+			return delivered, dropped, err
+		}
+		delivered++
+	}
+
+	if delivered > 0 || dropped > 0 {
+		m.logger.Infof("Drained pending notifications: %d delivered, %d dropped", delivered, dropped)
+	}
+	return delivered, dropped, nil
+}
+
+// keepUnsent 补发的消息拆分后发送到一半失败时，记录中只保留未送达的部分，避免下次补发重复发送已送达的内容
+// 未送达的各条按顺序合并回同一条记录，保持与队列中其后消息的先后顺序；@提醒已随第一条送达时不再保留
+func keepUnsent(item *model.PendingNotification, sendErr error) {
+	var partial *partialSendError
+	if !errors.As(sendErr, &partial) || len(partial.unsent) == 0 {
+		return
+	}
+
+	contents := make([]string, len(partial.unsent))
+	for i, part := range partial.unsent {
+		contents[i] = part.Content
+	}
+	item.Content = strings.Join(contents, "\n")
+	item.AtMobiles = strings.Join(partial.unsent[0].AtMobiles, ",")
+	item.AtAll = partial.unsent[0].AtAll
+}
+
+// StartRetryLoop 启动后台重试循环，按interval定期补发重试队列中的消息，ctx取消后退出
+// interval<=0时使用默认重试间隔
+func (m *Manager) StartRetryLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// 启动时立即补发一轮，处理上次进程退出前未送达的消息
+			if _, _, err := m.DrainPending(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Errorf("Failed to drain pending notifications: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pendingToMessage 将待重发的记录还原为消息
+func pendingToMessage(item *model.PendingNotification) *Message {
+	message := &Message{
+		Content: item.Content,
+		MsgType: MessageType(item.MsgType),
+		AtAll:   item.AtAll,
+	}
+	if item.AtMobiles != "" {
+		message.AtMobiles = strings.Split(item.AtMobiles, ",")
+	}
+	if item.Title != "" {
+		message.Extra = map[string]interface{}{"title": item.Title}
+	}
+	return message
+}
+
+// markdownMessage 构造带标题的Markdown消息，用于写入重试队列
+func markdownMessage(title, content string) *Message {
+	return &Message{
+		Content: content,
+		MsgType: MessageTypeMarkdown,
+		Extra:   map[string]interface{}{"title": title},
+	}
+}
+
+// truncateError 截断失败原因，避免超出表字段长度
+func truncateError(err error) string {
+	if err == nil {
+		return ""
+	}
+	msg := err.Error()
+	if len(msg) <= maxLastErrorLength {
+		return msg
+	}
+	cut := maxLastErrorLength
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut]
+}
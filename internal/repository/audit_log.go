@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计记录查询条件，零值字段不参与过滤
+type AuditLogFilter struct {
+	Source model.AuditSource
+	Action string
+	TsCode string
+	Since  time.Time // 包含
+	Until  time.Time // 不包含
+	Limit  int       // 不大于0时不限制条数
+}
+
+// AuditLog 审计记录仓库
+type AuditLog struct {
+	db *gorm.DB
+}
+
+// NewAuditLog 创建审计记录仓库
+func NewAuditLog(db *gorm.DB) *AuditLog {
+	return &AuditLog{
+		db: db,
+	}
+}
+
+// Create 保存一条审计记录
+func (r *AuditLog) Create(entry *model.AuditLog) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		logger.Errorf("Failed to create audit log for %s: %v", entry.Action, err)
+		return err
+	}
+	return nil
+}
+
+// List 按时间倒序获取符合条件的审计记录
+func (r *AuditLog) List(filter AuditLogFilter) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	query := r.db.Order("created_at DESC").Order("id DESC")
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TsCode != "" {
+		query = query.Where("ts_code = ?", filter.TsCode)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Find(&entries).Error; err != nil {
+		logger.Errorf("Failed to get audit logs: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
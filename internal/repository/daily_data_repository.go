@@ -9,6 +9,7 @@ import (
 	"stock/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DailyData 日线数据仓库
@@ -31,6 +32,10 @@ func (r *DailyData) getTableName(tsCode string) string {
 
 // SaveDailyData 保存日K线数据到对应的分表
 func (r *DailyData) SaveDailyData(data []model.DailyData) error {
+	data, err := rejectInvalidTradeDates("daily", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -61,6 +66,10 @@ func (r *DailyData) SaveDailyData(data []model.DailyData) error {
 
 // UpsertDailyData 更新或插入日K线数据到对应的分表（支持分批处理）
 func (r *DailyData) UpsertDailyData(data []model.DailyData) error {
+	data, err := rejectInvalidTradeDates("daily", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -100,6 +109,15 @@ func (r *DailyData) upsertDataInBatches(tableName string, data []model.DailyData
 		}
 
 		batch := data[i:end]
+		if SkipUnchangedUpsert() {
+			var err error
+			if batch, err = filterUnchangedKLines(r.db, tableName, batch, func(d model.DailyData) model.DailyData { return d }); err != nil {
+				return fmt.Errorf("failed to compare batch %d-%d: %w", i, end-1, err)
+			}
+			if len(batch) == 0 {
+				continue
+			}
+		}
 
 		// 使用 ON DUPLICATE KEY UPDATE 进行批量 upsert
 		if err := r.db.Table(tableName).Save(&batch).Error; err != nil {
@@ -115,7 +133,38 @@ func (r *DailyData) upsertDataInBatches(tableName string, data []model.DailyData
 // GetDailyData 获取指定股票的日K线数据
 func (r *DailyData) GetDailyData(tsCode string, startDate, endDate time.Time, limit int) ([]model.DailyData, error) {
 	var dataList []model.DailyData
+	if err := r.dailyDataQuery(tsCode, startDate, endDate, limit).Find(&dataList).Error; err != nil {
+		logger.Errorf("Failed to get daily data for %s: %v", tsCode, err)
+		return nil, err
+	}
+
+	return dataList, nil
+}
+
+// GetAnalysisDailyData 获取用于指标计算和回测的日K线数据，跳过该股票日K线的排除区间，
+// 排除条件在查询中过滤，limit限制的是排除后的K线数量；其余与GetDailyData一致
+func (r *DailyData) GetAnalysisDailyData(tsCode string, startDate, endDate time.Time, limit int) ([]model.DailyData, error) {
+	exclusions, err := NewDataExclusion(r.db).List(tsCode, model.TechnicalIndicatorPeriodDaily)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.dailyDataQuery(tsCode, startDate, endDate, limit)
+	for _, exclusion := range exclusions {
+		query = query.Where("trade_date NOT BETWEEN ? AND ?", exclusion.StartDate, exclusion.EndDate)
+	}
+
+	var dataList []model.DailyData
+	if err := query.Find(&dataList).Error; err != nil {
+		logger.Errorf("Failed to get analysis daily data for %s: %v", tsCode, err)
+		return nil, err
+	}
 
+	return dataList, nil
+}
+
+// dailyDataQuery 构造按日期区间查询股票日K线的语句，按交易日期降序，limit>0时限制条数
+func (r *DailyData) dailyDataQuery(tsCode string, startDate, endDate time.Time, limit int) *gorm.DB {
 	// 根据股票代码确定表名
 	tableName := r.getTableName(tsCode)
 
@@ -137,12 +186,86 @@ func (r *DailyData) GetDailyData(tsCode string, startDate, endDate time.Time, li
 		query = query.Limit(limit)
 	}
 
-	if err := query.Find(&dataList).Error; err != nil {
-		logger.Errorf("Failed to get daily data for %s: %v", tsCode, err)
+	return query
+}
+
+// EachDailyDataBatch 按交易日期升序分批读取股票交易日期不早于startDate（YYYYMMDD格式，<=0表示不限制）的日K线，
+// 每批最多batchSize条，按交易日期翻页，不一次性加载全部历史；fn返回错误时停止读取并返回该错误
+func (r *DailyData) EachDailyDataBatch(tsCode string, startDate, batchSize int, fn func([]model.DailyData) error) error {
+	return r.EachDailyDataBatchInRange(tsCode, startDate, 0, batchSize, fn)
+}
+
+// EachDailyDataBatchInRange 与EachDailyDataBatch相同，endDate>0时只读取交易日期不晚于endDate的日K线
+func (r *DailyData) EachDailyDataBatchInRange(tsCode string, startDate, endDate, batchSize int, fn func([]model.DailyData) error) error {
+	tableName := r.getTableName(tsCode)
+	after := startDate - 1
+	for {
+		var batch []model.DailyData
+		query := r.db.Table(tableName).Where("ts_code = ? AND trade_date > ?", tsCode, after)
+		if endDate > 0 {
+			query = query.Where("trade_date <= ?", endDate)
+		}
+		if err := query.Order("trade_date ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			logger.Errorf("Failed to get daily data batch for %s after %d: %v", tsCode, after, err)
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		after = batch[len(batch)-1].TradeDate
+	}
+}
+
+// legacyDailyDataColumns 迁移旧日K线表时读取的列，旧表的created_at为时间戳整数，不读取
+var legacyDailyDataColumns = []string{"ts_code", "trade_date", "open", "high", "low", "close", "volume", "amount"}
+
+// GetLegacyDailyDataBatch 从未分表的旧日K线表按(ts_code, trade_date)升序读取位于游标之后的一批数据
+// 按主键游标翻页而不是OFFSET，迁移过程中旧表有新写入时已读取的位置不会偏移；minTradeDate>0时只读取不早于该日期的数据
+func (r *DailyData) GetLegacyDailyDataBatch(sourceTable, afterTsCode string, afterTradeDate, minTradeDate, limit int) ([]model.DailyData, error) {
+	batch := make([]model.DailyData, 0, limit)
+	query := r.db.Table(sourceTable).Select(legacyDailyDataColumns).
+		Where("ts_code > ? OR (ts_code = ? AND trade_date > ?)", afterTsCode, afterTsCode, afterTradeDate)
+	if minTradeDate > 0 {
+		query = query.Where("trade_date >= ?", minTradeDate)
+	}
+	if err := query.Order("ts_code ASC, trade_date ASC").Limit(limit).Find(&batch).Error; err != nil {
+		logger.Errorf("Failed to get legacy daily data from %s after %s %d: %v", sourceTable, afterTsCode, afterTradeDate, err)
 		return nil, err
 	}
+	return batch, nil
+}
 
-	return dataList, nil
+// GetLegacyMaxTradeDate 获取旧日K线表的最新交易日期，表为空时返回0
+func (r *DailyData) GetLegacyMaxTradeDate(sourceTable string) (int, error) {
+	var maxDate *int
+	if err := r.db.Table(sourceTable).Select("MAX(trade_date)").Scan(&maxDate).Error; err != nil {
+		logger.Errorf("Failed to get max trade date of %s: %v", sourceTable, err)
+		return 0, err
+	}
+	if maxDate == nil {
+		return 0, nil
+	}
+	return *maxDate, nil
+}
+
+// GetTradeDates 获取指定股票在日期区间内（含首尾，YYYYMMDD格式）有日K线的交易日期，按日期升序返回
+// 只查询trade_date列，用于前端绘制稀疏日历和标记缺失的交易日
+func (r *DailyData) GetTradeDates(tsCode string, startDate, endDate int) ([]int, error) {
+	dates := make([]int, 0)
+	if err := r.db.Table(r.getTableName(tsCode)).
+		Where("ts_code = ? AND trade_date >= ? AND trade_date <= ?", tsCode, startDate, endDate).
+		Distinct("trade_date").Order("trade_date ASC").
+		Pluck("trade_date", &dates).Error; err != nil {
+		logger.Errorf("Failed to get trade dates for %s: %v", tsCode, err)
+		return nil, err
+	}
+	return dates, nil
 }
 
 // GetLatestDailyData 获取最新的日K线数据
@@ -164,6 +287,105 @@ func (r *DailyData) GetLatestDailyData(tsCode string) (*model.DailyData, error)
 	return &data, nil
 }
 
+// GetLatestDailyDataBatch 批量获取多只股票最新的日K线数据，按分表分组查询，没有数据的股票不在结果中
+func (r *DailyData) GetLatestDailyDataBatch(tsCodes []string) (map[string]model.DailyData, error) {
+	result := make(map[string]model.DailyData, len(tsCodes))
+	if len(tsCodes) == 0 {
+		return result, nil
+	}
+
+	// 按表名分组
+	tableGroups := make(map[string][]string)
+	for _, tsCode := range tsCodes {
+		tableName := r.getTableName(tsCode)
+		tableGroups[tableName] = append(tableGroups[tableName], tsCode)
+	}
+
+	for tableName, codes := range tableGroups {
+		var dataList []model.DailyData
+		if err := LatestPerGroup(r.db, tableName, "ts_code", "trade_date", codes).
+			Find(&dataList).Error; err != nil {
+			logger.Errorf("Failed to get latest daily data from %s: %v", tableName, err)
+			return nil, err
+		}
+		for _, data := range dataList {
+			result[data.TsCode] = data
+		}
+	}
+
+	return result, nil
+}
+
+// GetDailyDataByDate 获取全市场在指定交易日（YYYYMMDD格式）的日K线数据，逐个分表查询
+func (r *DailyData) GetDailyDataByDate(tradeDate int) ([]model.DailyData, error) {
+	result := make([]model.DailyData, 0)
+	for _, tableName := range model.KLineShardTables(model.DailyDataTablePrefix) {
+		var dataList []model.DailyData
+		if err := r.db.Table(tableName).Where("trade_date = ?", tradeDate).
+			Find(&dataList).Error; err != nil {
+			logger.Errorf("Failed to get daily data of %d from %s: %v", tradeDate, tableName, err)
+			return nil, err
+		}
+		result = append(result, dataList...)
+	}
+	return result, nil
+}
+
+// CountByTradeDate 统计tsCodes中在指定交易日（YYYYMMDD格式）有日K线的股票数，按分表分组查询
+func (r *DailyData) CountByTradeDate(tsCodes []string, tradeDate int) (int64, error) {
+	tableGroups := make(map[string][]string)
+	for _, tsCode := range tsCodes {
+		tableName := r.getTableName(tsCode)
+		tableGroups[tableName] = append(tableGroups[tableName], tsCode)
+	}
+
+	var total int64
+	for tableName, codes := range tableGroups {
+		var count int64
+		if err := r.db.Table(tableName).Where("trade_date = ? AND ts_code IN ?", tradeDate, codes).
+			Count(&count).Error; err != nil {
+			logger.Errorf("Failed to count daily data of %d from %s: %v", tradeDate, tableName, err)
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// GetPrevDailyDataBatch 批量获取多只股票在before（YYYYMMDD格式）之前最近一个交易日的日K线数据，
+// 按分表分组查询，停牌时取停牌前最后一根K线，没有数据的股票不在结果中
+func (r *DailyData) GetPrevDailyDataBatch(tsCodes []string, before int) (map[string]model.DailyData, error) {
+	result := make(map[string]model.DailyData, len(tsCodes))
+	if len(tsCodes) == 0 {
+		return result, nil
+	}
+
+	// 按表名分组
+	tableGroups := make(map[string][]string)
+	for _, tsCode := range tsCodes {
+		tableName := r.getTableName(tsCode)
+		tableGroups[tableName] = append(tableGroups[tableName], tsCode)
+	}
+
+	for tableName, codes := range tableGroups {
+		var dataList []model.DailyData
+		keys := latestKeysPerGroup(r.db, tableName, "ts_code", "trade_date", codes).
+			Where("trade_date < ?", before)
+		if err := r.db.Table(tableName).Where("(?, ?) IN (?)",
+			clause.Column{Table: tableName, Name: "ts_code"},
+			clause.Column{Table: tableName, Name: "trade_date"}, keys).
+			Find(&dataList).Error; err != nil {
+			logger.Errorf("Failed to get previous daily data before %d from %s: %v", before, tableName, err)
+			return nil, err
+		}
+		for _, data := range dataList {
+			result[data.TsCode] = data
+		}
+	}
+
+	return result, nil
+}
+
 // DeleteDailyData 删除日K线数据
 func (r *DailyData) DeleteDailyData(tsCode string, tradeDate time.Time) error {
 	// 根据股票代码确定表名
@@ -182,16 +404,21 @@ func (r *DailyData) DeleteDailyData(tsCode string, tradeDate time.Time) error {
 	return nil
 }
 
+// DeleteRange 删除股票交易日期在[startDate, endDate]（YYYYMMDD格式，<=0表示不限制）内的日K线数据，返回删除的行数
+func (r *DailyData) DeleteRange(tsCode string, startDate, endDate int) (int64, error) {
+	return deleteKLineRange(r.db, "daily", r.getTableName(tsCode), &model.DailyData{}, tsCode, startDate, endDate)
+}
+
+// PruneBefore 删除所有股票交易日期早于before（YYYYMMDD格式）的日K线数据，返回删除的行数
+func (r *DailyData) PruneBefore(before int) (int64, error) {
+	return pruneKLineTables(r.db, "daily", model.KLineShardTables(model.DailyDataTablePrefix), &model.DailyData{}, before)
+}
+
 // GetDailyDataCount 获取日K线数据总数
 func (r *DailyData) GetDailyDataCount(tsCode string) (int64, error) {
 	if tsCode == "" {
 		// 获取所有表的数据总数
-		tableNames := []string{
-			"daily_data_000", "daily_data_001", "daily_data_002",
-			"daily_data_300", "daily_data_301", "daily_data_600",
-			"daily_data_601", "daily_data_603", "daily_data_605",
-			"daily_data_688", "daily_data_other",
-		}
+		tableNames := model.KLineShardTables(model.DailyDataTablePrefix)
 
 		var totalCount int64
 		for _, tableName := range tableNames {
@@ -255,12 +482,7 @@ func (r *DailyData) GetDateRange(tsCode string) (startDate, endDate time.Time, e
 		}
 	} else {
 		// 获取所有表的日期范围
-		tableNames := []string{
-			"daily_data_000", "daily_data_001", "daily_data_002",
-			"daily_data_300", "daily_data_301", "daily_data_600",
-			"daily_data_601", "daily_data_603", "daily_data_605",
-			"daily_data_688", "daily_data_other",
-		}
+		tableNames := model.KLineShardTables(model.DailyDataTablePrefix)
 
 		var globalStartDate, globalEndDate int
 
@@ -307,12 +529,7 @@ func (r *DailyData) GetDateRange(tsCode string) (startDate, endDate time.Time, e
 
 // GetAllTableStats 获取所有分表的统计信息
 func (r *DailyData) GetAllTableStats() (map[string]interface{}, error) {
-	tableNames := []string{
-		"daily_data_000", "daily_data_001", "daily_data_002",
-		"daily_data_300", "daily_data_301", "daily_data_600",
-		"daily_data_601", "daily_data_603", "daily_data_605",
-		"daily_data_688", "daily_data_other",
-	}
+	tableNames := model.KLineShardTables(model.DailyDataTablePrefix)
 
 	tableStats := make(map[string]int64)
 	var totalCount int64
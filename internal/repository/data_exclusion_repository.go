@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// DataExclusion 数据排除区间仓库
+type DataExclusion struct {
+	db *gorm.DB
+}
+
+// NewDataExclusion 创建数据排除区间仓库
+func NewDataExclusion(db *gorm.DB) *DataExclusion {
+	return &DataExclusion{
+		db: db,
+	}
+}
+
+// Create 创建排除区间
+func (r *DataExclusion) Create(exclusion *model.DataExclusion) error {
+	if err := r.db.Create(exclusion).Error; err != nil {
+		logger.Errorf("Failed to create data exclusion for %s: %v", exclusion.TsCode, err)
+		return err
+	}
+	return nil
+}
+
+// GetByID 根据ID获取排除区间，不存在时返回nil
+func (r *DataExclusion) GetByID(id uint) (*model.DataExclusion, error) {
+	var exclusion model.DataExclusion
+	if err := r.db.First(&exclusion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get data exclusion %d: %v", id, err)
+		return nil, err
+	}
+	return &exclusion, nil
+}
+
+// List 获取排除区间，tsCode、period为空表示不限，按股票代码和起始日期排序
+func (r *DataExclusion) List(tsCode string, period model.TechnicalIndicatorPeriod) ([]model.DataExclusion, error) {
+	var exclusions []model.DataExclusion
+	query := r.db.Order("ts_code ASC").Order("start_date ASC")
+	if tsCode != "" {
+		query = query.Where("ts_code = ?", tsCode)
+	}
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+	if err := query.Find(&exclusions).Error; err != nil {
+		logger.Errorf("Failed to list data exclusions: %v", err)
+		return nil, err
+	}
+	return exclusions, nil
+}
+
+// Update 更新排除区间
+func (r *DataExclusion) Update(exclusion *model.DataExclusion) error {
+	if err := r.db.Save(exclusion).Error; err != nil {
+		logger.Errorf("Failed to update data exclusion %d: %v", exclusion.ID, err)
+		return err
+	}
+	return nil
+}
+
+// Delete 删除排除区间，返回删除的行数
+func (r *DataExclusion) Delete(id uint) (int64, error) {
+	result := r.db.Delete(&model.DataExclusion{}, id)
+	if result.Error != nil {
+		logger.Errorf("Failed to delete data exclusion %d: %v", id, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DataQuality 数据质量评分仓库
+type DataQuality struct {
+	db *gorm.DB
+}
+
+// NewDataQuality 创建数据质量评分仓库
+func NewDataQuality(db *gorm.DB) *DataQuality {
+	return &DataQuality{
+		db: db,
+	}
+}
+
+// Upsert 保存数据质量评分，同一股票的评分覆盖更新
+func (r *DataQuality) Upsert(qualities []model.StockDataQuality) error {
+	if len(qualities) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ts_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"score", "bars", "gaps", "anomalies", "stale_days", "last_bar_date", "evaluated_at", "updated_at",
+		}),
+	}).CreateInBatches(&qualities, 500).Error; err != nil {
+		logger.Errorf("Failed to upsert data quality: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetByTsCode 获取股票的数据质量评分，尚未评估时返回nil
+func (r *DataQuality) GetByTsCode(tsCode string) (*model.StockDataQuality, error) {
+	var quality model.StockDataQuality
+	if err := r.db.Where("ts_code = ?", tsCode).First(&quality).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get data quality for %s: %v", tsCode, err)
+		return nil, err
+	}
+	return &quality, nil
+}
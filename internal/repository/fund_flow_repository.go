@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FundFlow 个股资金流向仓库
+type FundFlow struct {
+	db *gorm.DB
+}
+
+// NewFundFlow 创建个股资金流向仓库
+func NewFundFlow(db *gorm.DB) *FundFlow {
+	return &FundFlow{
+		db: db,
+	}
+}
+
+// Upsert 保存资金流向，同一股票同一交易日的数据覆盖更新（盘中多次采集时以最后一次为准）
+func (r *FundFlow) Upsert(flows []model.StockFundFlow) error {
+	if len(flows) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ts_code"}, {Name: "trade_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"close", "change_pct",
+			"main_net_inflow", "main_net_inflow_pct",
+			"super_large_net_inflow", "super_large_net_inflow_pct",
+			"large_net_inflow", "large_net_inflow_pct",
+			"medium_net_inflow", "medium_net_inflow_pct",
+			"small_net_inflow", "small_net_inflow_pct",
+			"updated_at",
+		}),
+	}).CreateInBatches(&flows, 500).Error; err != nil {
+		logger.Errorf("Failed to upsert fund flows: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetByTsCode 获取股票在日期范围内的资金流向，按交易日期升序
+func (r *FundFlow) GetByTsCode(tsCode string, startDate, endDate int) ([]model.StockFundFlow, error) {
+	var flows []model.StockFundFlow
+	if err := r.db.Where("ts_code = ? AND trade_date >= ? AND trade_date <= ?", tsCode, startDate, endDate).
+		Order("trade_date ASC").Find(&flows).Error; err != nil {
+		logger.Errorf("Failed to get fund flows for %s: %v", tsCode, err)
+		return nil, err
+	}
+	return flows, nil
+}
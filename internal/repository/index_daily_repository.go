@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// IndexDaily 指数日线数据仓库
+type IndexDaily struct {
+	db *gorm.DB
+}
+
+// NewIndexDaily 创建指数日线数据仓库
+func NewIndexDaily(db *gorm.DB) *IndexDaily {
+	return &IndexDaily{
+		db: db,
+	}
+}
+
+// UpsertIndexDaily 更新或插入指数日线数据（支持分批处理）
+func (r *IndexDaily) UpsertIndexDaily(data []model.IndexDaily) error {
+	data, err := rejectInvalidTradeDates("index daily", data)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	const batchSize = 500 // 每批处理500条记录
+
+	for i := 0; i < len(data); i += batchSize {
+		end := i + batchSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		batch := data[i:end]
+		if err := r.db.Save(&batch).Error; err != nil {
+			return fmt.Errorf("failed to upsert index daily batch %d-%d: %w", i, end-1, err)
+		}
+	}
+
+	logger.Infof("Upserted %d index daily records", len(data))
+	return nil
+}
+
+// GetIndexDaily 获取指定指数的日线数据，按交易日期倒序返回
+func (r *IndexDaily) GetIndexDaily(tsCode string, startDate, endDate time.Time, limit int) ([]model.IndexDaily, error) {
+	var dataList []model.IndexDaily
+
+	query := r.db.Where("ts_code = ?", tsCode)
+
+	if !startDate.IsZero() {
+		startDateInt := startDate.Year()*10000 + int(startDate.Month())*100 + startDate.Day()
+		query = query.Where("trade_date >= ?", startDateInt)
+	}
+
+	if !endDate.IsZero() {
+		endDateInt := endDate.Year()*10000 + int(endDate.Month())*100 + endDate.Day()
+		query = query.Where("trade_date <= ?", endDateInt)
+	}
+
+	query = query.Order("trade_date DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&dataList).Error; err != nil {
+		logger.Errorf("Failed to get index daily data for %s: %v", tsCode, err)
+		return nil, err
+	}
+
+	return dataList, nil
+}
+
+// GetLatestIndexDaily 获取指定指数最新的日线数据
+func (r *IndexDaily) GetLatestIndexDaily(tsCode string) (*model.IndexDaily, error) {
+	var data model.IndexDaily
+
+	if err := r.db.Where("ts_code = ?", tsCode).
+		Order("trade_date DESC").First(&data).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get latest index daily data for %s: %v", tsCode, err)
+		return nil, err
+	}
+
+	return &data, nil
+}
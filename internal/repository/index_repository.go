@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Index 指数基础信息仓库
+type Index struct {
+	db *gorm.DB
+}
+
+// NewIndex 创建指数仓库
+func NewIndex(db *gorm.DB) *Index {
+	return &Index{
+		db: db,
+	}
+}
+
+// UpsertIndices 批量更新或插入指数记录
+func (r *Index) UpsertIndices(indices []model.Index) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ts_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"symbol", "name", "market", "updated_at"}),
+	}).Create(&indices).Error; err != nil {
+		logger.Errorf("Failed to upsert indices: %v", err)
+		return err
+	}
+
+	logger.Debugf("Upserted %d indices", len(indices))
+	return nil
+}
+
+// GetAllIndices 获取所有需要采集的指数
+func (r *Index) GetAllIndices() ([]model.Index, error) {
+	var indices []model.Index
+	if err := r.db.Where("is_active = ?", true).Order("ts_code ASC").Find(&indices).Error; err != nil {
+		logger.Errorf("Failed to get all indices: %v", err)
+		return nil, err
+	}
+	return indices, nil
+}
+
+// GetIndexByTsCode 根据指数代码获取指数信息
+func (r *Index) GetIndexByTsCode(tsCode string) (*model.Index, error) {
+	var index model.Index
+	if err := r.db.Where("ts_code = ?", tsCode).First(&index).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get index %s: %v", tsCode, err)
+		return nil, err
+	}
+	return &index, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// JobRun 采集任务运行记录仓库
+type JobRun struct {
+	db *gorm.DB
+}
+
+// NewJobRun 创建采集任务运行记录仓库
+func NewJobRun(db *gorm.DB) *JobRun {
+	return &JobRun{
+		db: db,
+	}
+}
+
+// Create 保存一次运行记录
+func (r *JobRun) Create(run *model.JobRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		logger.Errorf("Failed to create job run for %s: %v", run.Job, err)
+		return err
+	}
+	return nil
+}
+
+// List 按开始时间倒序获取运行记录，job为空时获取所有任务，limit不大于0时不限制条数
+func (r *JobRun) List(job string, limit int) ([]model.JobRun, error) {
+	var runs []model.JobRun
+	query := r.db.Order("started_at DESC").Order("id DESC")
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		logger.Errorf("Failed to get job runs: %v", err)
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"fmt"
+
+	"stock/internal/logger"
+
+	"gorm.io/gorm"
+)
+
+// pruneKLineTables 删除各K线表中交易日期早于before的数据，返回删除的总行数，value为对应周期的K线模型
+func pruneKLineTables(db *gorm.DB, period string, tables []string, value interface{}, before int) (int64, error) {
+	var total int64
+	for _, tableName := range tables {
+		result := db.Table(tableName).Where("trade_date < ?", before).Delete(value)
+		if result.Error != nil {
+			logger.Errorf("Failed to prune %s data in %s before %d: %v", period, tableName, before, result.Error)
+			return total, fmt.Errorf("清理%s早于%d的%s数据失败: %w", tableName, before, period, result.Error)
+		}
+		total += result.RowsAffected
+	}
+
+	logger.Infof("Pruned %d %s bars before %d", total, period, before)
+	return total, nil
+}
+
+// deleteKLineRange 删除股票交易日期在[startDate, endDate]内的K线数据，返回删除的行数
+// 日期为YYYYMMDD格式，<=0表示该端不限制，两端都不限制时删除该股票的全部数据；tableName为股票所在的分表
+func deleteKLineRange(db *gorm.DB, period, tableName string, value interface{}, tsCode string, startDate, endDate int) (int64, error) {
+	query := db.Table(tableName).Where("ts_code = ?", tsCode)
+	if startDate > 0 {
+		query = query.Where("trade_date >= ?", startDate)
+	}
+	if endDate > 0 {
+		query = query.Where("trade_date <= ?", endDate)
+	}
+
+	result := query.Delete(value)
+	if result.Error != nil {
+		logger.Errorf("Failed to delete %s data of %s in [%d, %d]: %v", period, tsCode, startDate, endDate, result.Error)
+		return 0, fmt.Errorf("删除%s在[%d, %d]内的%s数据失败: %w", tsCode, startDate, endDate, period, result.Error)
+	}
+
+	logger.Debugf("Deleted %d %s bars of %s in [%d, %d]", result.RowsAffected, period, tsCode, startDate, endDate)
+	return result.RowsAffected, nil
+}
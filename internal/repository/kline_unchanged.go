@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"sync/atomic"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// skipUnchangedUpsert upsert K线时是否跳过与已有记录完全相同的K线，启动时按配置设置
+// 默认每次重新同步都覆盖写入并刷新updated_at；开启后只写入新增或实际变化的K线，updated_at可用于判断数据何时真正变化
+var skipUnchangedUpsert atomic.Bool
+
+// SetSkipUnchangedUpsert 设置upsert K线时是否跳过未变化的K线
+func SetSkipUnchangedUpsert(enabled bool) {
+	skipUnchangedUpsert.Store(enabled)
+}
+
+// SkipUnchangedUpsert upsert K线时是否跳过未变化的K线
+func SkipUnchangedUpsert() bool {
+	return skipUnchangedUpsert.Load()
+}
+
+// klineKey K线的联合主键
+type klineKey struct {
+	tsCode    string
+	tradeDate int
+}
+
+// filterUnchangedKLines 查询tableName中已有的同一股票同一交易日期的K线，去掉开高低收、成交量和成交额与已有记录完全相同的K线
+// 返回需要写入的新增或变化的K线；各周期K线的字段与日K线一致，toDaily将K线转换为日K线结构后比较
+func filterUnchangedKLines[T any](db *gorm.DB, tableName string, data []T, toDaily func(T) model.DailyData) ([]T, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	keys := make([][]interface{}, 0, len(data))
+	for _, item := range data {
+		bar := toDaily(item)
+		keys = append(keys, []interface{}{bar.TsCode, bar.TradeDate})
+	}
+
+	var existing []model.DailyData
+	if err := db.Table(tableName).
+		Select("ts_code, trade_date, open, high, low, close, volume, amount").
+		Where("(ts_code, trade_date) IN ?", keys).
+		Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	stored := make(map[klineKey]model.DailyData, len(existing))
+	for _, bar := range existing {
+		stored[klineKey{bar.TsCode, bar.TradeDate}] = bar
+	}
+
+	changed := make([]T, 0, len(data))
+	for _, item := range data {
+		bar := toDaily(item)
+		if old, ok := stored[klineKey{bar.TsCode, bar.TradeDate}]; ok && sameKLineValues(old, bar) {
+			continue
+		}
+		changed = append(changed, item)
+	}
+	if skipped := len(data) - len(changed); skipped > 0 {
+		logger.Debugf("Skipped %d unchanged records in %s", skipped, tableName)
+	}
+	return changed, nil
+}
+
+// sameKLineValues 判断两根K线的值字段是否相同，价格和成交额按存储精度比较
+func sameKLineValues(a, b model.DailyData) bool {
+	return model.RoundPrice(a.Open) == model.RoundPrice(b.Open) &&
+		model.RoundPrice(a.High) == model.RoundPrice(b.High) &&
+		model.RoundPrice(a.Low) == model.RoundPrice(b.Low) &&
+		model.RoundPrice(a.Close) == model.RoundPrice(b.Close) &&
+		a.Volume == b.Volume &&
+		model.RoundAmount(a.Amount) == model.RoundAmount(b.Amount)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestDailyData_UpsertSkipsUnchanged(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 库中已有的K线，updated_at为上次实际变化的时间
+	updatedAt := time.Date(2025, 10, 9, 18, 30, 0, 0, time.UTC)
+	stored := []model.DailyData{
+		{TsCode: "600519.SH", TradeDate: 20251009, Open: 1450, High: 1462.5, Low: 1440, Close: 1455.21, Volume: 3200000, Amount: 4650000000, UpdatedAt: updatedAt},
+		{TsCode: "600519.SH", TradeDate: 20251010, Open: 1455, High: 1470, Low: 1451, Close: 1466, Volume: 2800000, Amount: 4100000000, UpdatedAt: updatedAt},
+	}
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]model.DailyData); ok {
+			*dest = append(*dest, stored...)
+		}
+	}))
+	var written []model.DailyData
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		if batch, ok := tx.Statement.Dest.(*[]model.DailyData); ok {
+			written = append(written, *batch...)
+		}
+	}))
+
+	SetSkipUnchangedUpsert(true)
+	t.Cleanup(func() { SetSkipUnchangedUpsert(false) })
+
+	// 重新同步：20251009与库中完全相同，20251010收盘价被修正，20251013为新增
+	resync := []model.DailyData{
+		{TsCode: "600519.SH", TradeDate: 20251009, Open: 1450, High: 1462.5, Low: 1440, Close: 1455.21, Volume: 3200000, Amount: 4650000000},
+		{TsCode: "600519.SH", TradeDate: 20251010, Open: 1455, High: 1470, Low: 1451, Close: 1465.5, Volume: 2800000, Amount: 4100000000},
+		{TsCode: "600519.SH", TradeDate: 20251013, Open: 1466, High: 1480, Low: 1460, Close: 1475, Volume: 2500000, Amount: 3700000000},
+	}
+	require.NoError(t, NewDailyData(db).UpsertDailyData(resync))
+
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], "WHERE (ts_code, trade_date) IN (('600519.SH',20251009),('600519.SH',20251010),('600519.SH',20251013))")
+
+	// 未变化的K线不写入，库中该K线的updated_at保持上次变化的时间
+	dates := make([]int, 0, len(written))
+	for _, bar := range written {
+		dates = append(dates, bar.TradeDate)
+	}
+	assert.Equal(t, []int{20251010, 20251013}, dates)
+
+	// 全部相同时不发起写入
+	written = nil
+	require.NoError(t, NewDailyData(db).UpsertDailyData(resync[:1]))
+	assert.Empty(t, written)
+
+	// 未开启时保持原有行为，全部覆盖写入
+	SetSkipUnchangedUpsert(false)
+	written, queries = nil, nil
+	require.NoError(t, NewDailyData(db).UpsertDailyData(resync))
+	assert.Len(t, written, 3)
+	assert.Empty(t, queries)
+}
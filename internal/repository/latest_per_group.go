@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// latestKeysPerGroup 构造每组最新一条记录的(分组列, 排序列)子查询，groups为nil时不限制分组
+func latestKeysPerGroup(db *gorm.DB, table, groupColumn, orderColumn string, groups []string) *gorm.DB {
+	query := db.Table(table).
+		Select("?, MAX(?)", clause.Column{Name: groupColumn}, clause.Column{Name: orderColumn}).
+		Group(groupColumn)
+	if groups != nil {
+		query = query.Where("? IN ?", clause.Column{Name: groupColumn}, groups)
+	}
+	return query
+}
+
+// LatestPerGroup 构造只保留每组最新一条记录的查询，如每只股票最新的K线、业绩报表、股东户数
+// 按groupColumn分组取orderColumn的最大值，再以(groupColumn, orderColumn)回表，一次查询得到所有分组的最新记录；
+// groups为nil时查询所有分组，否则只查询指定分组（空切片不返回任何记录）
+func LatestPerGroup(db *gorm.DB, table, groupColumn, orderColumn string, groups []string) *gorm.DB {
+	return db.Table(table).Where("(?, ?) IN (?)",
+		clause.Column{Table: table, Name: groupColumn},
+		clause.Column{Table: table, Name: orderColumn},
+		latestKeysPerGroup(db, table, groupColumn, orderColumn, groups))
+}
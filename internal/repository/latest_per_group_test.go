@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB 创建只生成SQL不连接数据库的MySQL连接，并记录每条查询语句
+// 子查询在拼接外层语句时也会经过查询回调，只记录带子查询的外层语句
+func newDryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql := db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+		if strings.Contains(sql, "IN (SELECT") {
+			queries = append(queries, sql)
+		}
+	}))
+	return db, &queries
+}
+
+func TestLatestPerGroup_DailyData(t *testing.T) {
+	db, queries := newDryRunDB(t)
+
+	_, err := NewDailyData(db).GetLatestDailyDataBatch([]string{"000001.SZ", "000002.SZ", "600000.SH"})
+	require.NoError(t, err)
+
+	// 按分表各查询一次，每张表一条语句取出所有股票的最新K线
+	require.Len(t, *queries, 2)
+	assert.ElementsMatch(t, []string{
+		"SELECT * FROM `daily_data_000` WHERE (`daily_data_000`.`ts_code`, `daily_data_000`.`trade_date`) IN " +
+			"(SELECT `ts_code`, MAX(`trade_date`) FROM `daily_data_000` WHERE `ts_code` IN ('000001.SZ','000002.SZ') GROUP BY `ts_code`)",
+		"SELECT * FROM `daily_data_600` WHERE (`daily_data_600`.`ts_code`, `daily_data_600`.`trade_date`) IN " +
+			"(SELECT `ts_code`, MAX(`trade_date`) FROM `daily_data_600` WHERE `ts_code` IN ('600000.SH') GROUP BY `ts_code`)",
+	}, *queries)
+}
+
+func TestDailyData_GetPrevDailyDataBatch(t *testing.T) {
+	db, queries := newDryRunDB(t)
+
+	_, err := NewDailyData(db).GetPrevDailyDataBatch([]string{"000001.SZ", "600000.SH"}, 20240105)
+	require.NoError(t, err)
+
+	// 子查询只在指定日期之前取最大交易日期，停牌股票取停牌前最后一根K线
+	require.Len(t, *queries, 2)
+	assert.ElementsMatch(t, []string{
+		"SELECT * FROM `daily_data_000` WHERE (`daily_data_000`.`ts_code`, `daily_data_000`.`trade_date`) IN " +
+			"(SELECT `ts_code`, MAX(`trade_date`) FROM `daily_data_000` WHERE `ts_code` IN ('000001.SZ') AND trade_date < 20240105 GROUP BY `ts_code`)",
+		"SELECT * FROM `daily_data_600` WHERE (`daily_data_600`.`ts_code`, `daily_data_600`.`trade_date`) IN " +
+			"(SELECT `ts_code`, MAX(`trade_date`) FROM `daily_data_600` WHERE `ts_code` IN ('600000.SH') AND trade_date < 20240105 GROUP BY `ts_code`)",
+	}, *queries)
+}
+
+func TestLatestPerGroup_PerformanceReports(t *testing.T) {
+	db, queries := newDryRunDB(t)
+	repo := NewPerformance(db)
+
+	_, err := repo.GetLatestBatch([]string{"000001.SZ"})
+	require.NoError(t, err)
+	_, err = repo.GetLatestBatch(nil)
+	require.NoError(t, err)
+	_, err = repo.GetTopPerformers(10, "roe", false)
+	require.NoError(t, err)
+
+	require.Len(t, *queries, 3)
+	assert.Equal(t, "SELECT * FROM `performance_reports` WHERE (`performance_reports`.`ts_code`, `performance_reports`.`report_date`) IN "+
+		"(SELECT `ts_code`, MAX(`report_date`) FROM `performance_reports` WHERE `ts_code` IN ('000001.SZ') GROUP BY `ts_code`)", (*queries)[0])
+	// 不指定股票时查询所有股票的最新报表
+	assert.Equal(t, "SELECT * FROM `performance_reports` WHERE (`performance_reports`.`ts_code`, `performance_reports`.`report_date`) IN "+
+		"(SELECT `ts_code`, MAX(`report_date`) FROM `performance_reports` GROUP BY `ts_code`)", (*queries)[1])
+	// 业绩排行只在各股票最新一期报表中排序
+	assert.Contains(t, (*queries)[2], "ORDER BY eps / NULLIF(bvps, 0) DESC")
+	assert.Contains(t, (*queries)[2], "WHERE (`performance_reports`.`ts_code`, `performance_reports`.`report_date`) IN "+
+		"(SELECT `ts_code`, MAX(`report_date`) FROM `performance_reports` GROUP BY `ts_code`)")
+}
+
+func TestLatestPerGroup_ShareholderCounts(t *testing.T) {
+	db, queries := newDryRunDB(t)
+
+	latest, err := NewShareholder(db).GetLatestBatch([]string{"000001.SZ", "600000.SH"})
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+
+	require.Len(t, *queries, 1)
+	assert.Equal(t, "SELECT * FROM `shareholder_counts` WHERE (`shareholder_counts`.`ts_code`, `shareholder_counts`.`end_date`) IN "+
+		"(SELECT `ts_code`, MAX(`end_date`) FROM `shareholder_counts` WHERE `ts_code` IN ('000001.SZ','600000.SH') GROUP BY `ts_code`)", (*queries)[0])
+}
+
+func TestLatestPerGroup_YearlyData(t *testing.T) {
+	db, _ := newDryRunDB(t)
+
+	var rows []model.YearlyData
+	stmt := LatestPerGroup(db, model.YearlyData{}.TableName(), "ts_code", "trade_date", []string{}).Find(&rows).Statement
+	// 空切片只查询指定的（零个）分组，不退化为查询所有股票
+	assert.Contains(t, db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...), "WHERE `ts_code` IN (NULL)")
+}
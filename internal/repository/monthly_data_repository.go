@@ -31,6 +31,10 @@ func (r *MonthlyData) getTableName(tsCode string) string {
 
 // SaveMonthlyData 保存月K线数据到对应的分表
 func (r *MonthlyData) SaveMonthlyData(data []model.MonthlyData) error {
+	data, err := rejectInvalidTradeDates("monthly", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -61,6 +65,10 @@ func (r *MonthlyData) SaveMonthlyData(data []model.MonthlyData) error {
 
 // UpsertMonthlyData 更新或插入月K线数据到对应的分表（支持分批处理）
 func (r *MonthlyData) UpsertMonthlyData(data []model.MonthlyData) error {
+	data, err := rejectInvalidTradeDates("monthly", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -100,6 +108,15 @@ func (r *MonthlyData) upsertDataInBatches(tableName string, data []model.Monthly
 		}
 
 		batch := data[i:end]
+		if SkipUnchangedUpsert() {
+			var err error
+			if batch, err = filterUnchangedKLines(r.db, tableName, batch, func(d model.MonthlyData) model.DailyData { return model.DailyData(d) }); err != nil {
+				return fmt.Errorf("failed to compare batch %d-%d: %w", i, end-1, err)
+			}
+			if len(batch) == 0 {
+				continue
+			}
+		}
 
 		// 使用 ON DUPLICATE KEY UPDATE 进行批量 upsert
 		if err := r.db.Table(tableName).Save(&batch).Error; err != nil {
@@ -182,16 +199,21 @@ func (r *MonthlyData) DeleteMonthlyData(tsCode string, tradeDate time.Time) erro
 	return nil
 }
 
+// DeleteRange 删除股票交易日期在[startDate, endDate]（YYYYMMDD格式，<=0表示不限制）内的月K线数据，返回删除的行数
+func (r *MonthlyData) DeleteRange(tsCode string, startDate, endDate int) (int64, error) {
+	return deleteKLineRange(r.db, "monthly", r.getTableName(tsCode), &model.MonthlyData{}, tsCode, startDate, endDate)
+}
+
+// PruneBefore 删除所有股票交易日期早于before（YYYYMMDD格式）的月K线数据，返回删除的行数
+func (r *MonthlyData) PruneBefore(before int) (int64, error) {
+	return pruneKLineTables(r.db, "monthly", model.KLineShardTables(model.MonthlyDataTablePrefix), &model.MonthlyData{}, before)
+}
+
 // GetMonthlyDataCount 获取月K线数据总数
 func (r *MonthlyData) GetMonthlyDataCount(tsCode string) (int64, error) {
 	if tsCode == "" {
 		// 获取所有表的数据总数
-		tableNames := []string{
-			"monthly_data_000", "monthly_data_001", "monthly_data_002",
-			"monthly_data_300", "monthly_data_301", "monthly_data_600",
-			"monthly_data_601", "monthly_data_603", "monthly_data_605",
-			"monthly_data_688", "monthly_data_other",
-		}
+		tableNames := model.KLineShardTables(model.MonthlyDataTablePrefix)
 
 		var totalCount int64
 		for _, tableName := range tableNames {
@@ -255,12 +277,7 @@ func (r *MonthlyData) GetDateRange(tsCode string) (startDate, endDate time.Time,
 		}
 	} else {
 		// 获取所有表的日期范围
-		tableNames := []string{
-			"monthly_data_000", "monthly_data_001", "monthly_data_002",
-			"monthly_data_300", "monthly_data_301", "monthly_data_600",
-			"monthly_data_601", "monthly_data_603", "monthly_data_605",
-			"monthly_data_688", "monthly_data_other",
-		}
+		tableNames := model.KLineShardTables(model.MonthlyDataTablePrefix)
 
 		var globalStartDate, globalEndDate int
 
@@ -307,12 +324,7 @@ func (r *MonthlyData) GetDateRange(tsCode string) (startDate, endDate time.Time,
 
 // GetAllTableStats 获取所有分表的统计信息
 func (r *MonthlyData) GetAllTableStats() (map[string]interface{}, error) {
-	tableNames := []string{
-		"monthly_data_000", "monthly_data_001", "monthly_data_002",
-		"monthly_data_300", "monthly_data_301", "monthly_data_600",
-		"monthly_data_601", "monthly_data_603", "monthly_data_605",
-		"monthly_data_688", "monthly_data_other",
-	}
+	tableNames := model.KLineShardTables(model.MonthlyDataTablePrefix)
 
 	tableStats := make(map[string]int64)
 	var totalCount int64
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Northbound 北向资金持股仓库
+type Northbound struct {
+	db *gorm.DB
+}
+
+// NewNorthbound 创建北向资金持股仓库
+func NewNorthbound(db *gorm.DB) *Northbound {
+	return &Northbound{
+		db: db,
+	}
+}
+
+// Upsert 保存北向持股，同一股票同一持股日期的记录覆盖更新，保留创建时间
+func (r *Northbound) Upsert(holdings []model.NorthboundHolding) error {
+	if len(holdings) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ts_code"}, {Name: "trade_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"close", "change_pct", "hold_shares", "hold_market_cap",
+			"free_shares_ratio", "total_shares_ratio", "share_change", "market_cap_change",
+			"updated_at",
+		}),
+	}).CreateInBatches(&holdings, 500).Error; err != nil {
+		logger.Errorf("Failed to upsert northbound holdings: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetByTsCode 获取股票在日期范围内的北向持股，按持股日期升序
+func (r *Northbound) GetByTsCode(tsCode string, startDate, endDate int) ([]model.NorthboundHolding, error) {
+	var holdings []model.NorthboundHolding
+	if err := r.db.Where("ts_code = ? AND trade_date >= ? AND trade_date <= ?", tsCode, startDate, endDate).
+		Order("trade_date ASC").Find(&holdings).Error; err != nil {
+		logger.Errorf("Failed to get northbound holdings for %s: %v", tsCode, err)
+		return nil, err
+	}
+	return holdings, nil
+}
+
+// GetLatestBatch 批量获取股票最新一期北向持股，tsCodes为nil时获取所有股票，没有记录的股票不在结果中
+func (r *Northbound) GetLatestBatch(tsCodes []string) (map[string]model.NorthboundHolding, error) {
+	var holdings []model.NorthboundHolding
+	if err := LatestPerGroup(r.db, model.NorthboundHolding{}.TableName(), "ts_code", "trade_date", tsCodes).
+		Find(&holdings).Error; err != nil {
+		logger.Errorf("Failed to get latest northbound holdings: %v", err)
+		return nil, err
+	}
+
+	result := make(map[string]model.NorthboundHolding, len(holdings))
+	for _, holding := range holdings {
+		result[holding.TsCode] = holding
+	}
+	return result, nil
+}
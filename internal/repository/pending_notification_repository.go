@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PendingNotification 待重发通知仓库
+type PendingNotification struct {
+	db *gorm.DB
+}
+
+// NewPendingNotification 创建待重发通知仓库
+func NewPendingNotification(db *gorm.DB) *PendingNotification {
+	return &PendingNotification{
+		db: db,
+	}
+}
+
+// Save 保存待重发的通知
+func (r *PendingNotification) Save(item *model.PendingNotification) error {
+	if err := r.db.Save(item).Error; err != nil {
+		logger.Errorf("Failed to save pending notification: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetPending 按入队顺序获取待重发的通知
+func (r *PendingNotification) GetPending(limit int) ([]model.PendingNotification, error) {
+	var items []model.PendingNotification
+	query := r.db.Order("id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		logger.Errorf("Failed to get pending notifications: %v", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// Delete 删除已送达或已过期的通知
+func (r *PendingNotification) Delete(id uint) error {
+	if err := r.db.Delete(&model.PendingNotification{}, id).Error; err != nil {
+		logger.Errorf("Failed to delete pending notification %d: %v", id, err)
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"stock/internal/model"
@@ -175,27 +177,155 @@ func (r *Performance) GetStatistics() (map[string]interface{}, error) {
 	return stats, nil
 }
 
-// GetTopPerformers 获取业绩表现最好的股票
-func (r *Performance) GetTopPerformers(limit int, orderBy string) ([]model.PerformanceReport, error) {
+// GetLatestBatch 批量获取股票最新一期业绩报表，tsCodes为nil时获取所有股票，没有报表的股票不在结果中
+func (r *Performance) GetLatestBatch(tsCodes []string) (map[string]model.PerformanceReport, error) {
 	var reports []model.PerformanceReport
+	if err := LatestPerGroup(r.db, model.PerformanceReport{}.TableName(), "ts_code", "report_date", tsCodes).
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]model.PerformanceReport, len(reports))
+	for _, report := range reports {
+		result[report.TsCode] = report
+	}
+	return result, nil
+}
+
+// GetSinceReportDate 批量获取报告期不早于minReportDate的业绩报表，按股票代码分组，每组按报告期降序
+// tsCodes为nil时获取所有股票，没有报表的股票不在结果中
+func (r *Performance) GetSinceReportDate(tsCodes []string, minReportDate int) (map[string][]model.PerformanceReport, error) {
+	query := r.db.Where("report_date >= ?", minReportDate)
+	if tsCodes != nil {
+		query = query.Where("ts_code IN ?", tsCodes)
+	}
+
+	var reports []model.PerformanceReport
+	if err := query.Order("ts_code ASC").Order("report_date DESC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
 
-	// 验证排序字段
-	validOrderFields := map[string]bool{
-		"eps":            true,
-		"roe":            true,
-		"roa":            true,
-		"gross_margin":   true,
-		"dividend_yield": true,
-		"revenue":        true,
-		"net_profit":     true,
+	result := make(map[string][]model.PerformanceReport)
+	for _, report := range reports {
+		result[report.TsCode] = append(result[report.TsCode], report)
 	}
+	return result, nil
+}
+
+// PerformanceKey 业绩报表的联合主键
+type PerformanceKey struct {
+	TsCode     string
+	ReportDate int
+}
 
-	if !validOrderFields[orderBy] {
-		orderBy = "eps" // 默认按每股收益排序
+// GetByKeys 批量获取指定股票和报告期的业绩报表，不存在的报表不在结果中
+func (r *Performance) GetByKeys(keys []PerformanceKey) (map[PerformanceKey]model.PerformanceReport, error) {
+	result := make(map[PerformanceKey]model.PerformanceReport, len(keys))
+	if len(keys) == 0 {
+		return result, nil
 	}
 
-	err := r.db.Where("report_date = (SELECT MAX(report_date) FROM performance_reports pr2 WHERE pr2.ts_code = performance_reports.ts_code)").
-		Order(fmt.Sprintf("%s DESC", orderBy)).
+	pairs := make([][]interface{}, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, []interface{}{key.TsCode, key.ReportDate})
+	}
+	var reports []model.PerformanceReport
+	if err := r.db.Where("(ts_code, report_date) IN ?", pairs).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		result[PerformanceKey{report.TsCode, report.ReportDate}] = report
+	}
+	return result, nil
+}
+
+// GetByAnnouncementDateRange 获取首次公告日期或最新公告日期在[from, to]之间的业绩报表，并关联股票名称
+// from、to按日期比较，包含首尾两天；按最新公告日期、股票代码升序排列
+func (r *Performance) GetByAnnouncementDateRange(from, to time.Time) ([]PerformanceWithStock, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+
+	var rows []PerformanceWithStock
+	err := r.db.Table(model.PerformanceReport{}.TableName()).
+		Select("performance_reports.*, stocks.name").
+		Joins("LEFT JOIN stocks ON stocks.ts_code = performance_reports.ts_code").
+		Where("(performance_reports.first_announcement_date >= ? AND performance_reports.first_announcement_date < ?) OR "+
+			"(performance_reports.latest_announcement_date >= ? AND performance_reports.latest_announcement_date < ?)",
+			start, end, start, end).
+		Order("performance_reports.latest_announcement_date ASC").
+		Order("performance_reports.ts_code ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// PerformanceWithStock 业绩报表及对应的股票名称
+type PerformanceWithStock struct {
+	model.PerformanceReport `gorm:"embedded"`
+	Name                    string `json:"name" gorm:"column:name"` // 股票简称
+}
+
+// GetLatestReportsWithStock 获取所有股票最新一期业绩报表，并关联股票名称
+func (r *Performance) GetLatestReportsWithStock() ([]PerformanceWithStock, error) {
+	var rows []PerformanceWithStock
+	err := LatestPerGroup(r.db, model.PerformanceReport{}.TableName(), "ts_code", "report_date", nil).
+		Select("performance_reports.*, stocks.name").
+		Joins("LEFT JOIN stocks ON stocks.ts_code = performance_reports.ts_code").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// DefaultTopPerformersOrder 业绩排行的默认排序字段
+const DefaultTopPerformersOrder = "eps"
+
+// topPerformerOrderFields 业绩排行支持的排序字段及对应的排序表达式
+// ROE没有单独的列，按每股收益/每股净资产计算，每股净资产为0的股票不参与排行
+var topPerformerOrderFields = map[string]string{
+	"eps":            "eps",
+	"weight_eps":     "weight_eps",
+	"roe":            "eps / NULLIF(bvps, 0)",
+	"bvps":           "bvps",
+	"revenue":        "revenue",
+	"revenue_yoy":    "revenue_yoy",
+	"net_profit":     "net_profit",
+	"net_profit_yoy": "net_profit_yoy",
+	"gross_margin":   "gross_margin",
+	"dividend_yield": "dividend_yield",
+}
+
+// TopPerformerOrderFields 业绩排行支持的排序字段，按名称排序
+func TopPerformerOrderFields() []string {
+	fields := make([]string, 0, len(topPerformerOrderFields))
+	for field := range topPerformerOrderFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ValidateTopPerformersOrder 校验业绩排行的排序字段，不支持时返回列出可选字段的错误
+func ValidateTopPerformersOrder(orderBy string) error {
+	if _, ok := topPerformerOrderFields[orderBy]; !ok {
+		return fmt.Errorf("unsupported order field %q, valid options: %s", orderBy, strings.Join(TopPerformerOrderFields(), ", "))
+	}
+	return nil
+}
+
+// GetTopPerformers 获取业绩表现最好的股票，asc为true时按升序排列
+func (r *Performance) GetTopPerformers(limit int, orderBy string, asc bool) ([]model.PerformanceReport, error) {
+	if err := ValidateTopPerformersOrder(orderBy); err != nil {
+		return nil, err
+	}
+
+	direction := "DESC"
+	if asc {
+		direction = "ASC"
+	}
+
+	var reports []model.PerformanceReport
+	err := LatestPerGroup(r.db, model.PerformanceReport{}.TableName(), "ts_code", "report_date", nil).
+		Where(fmt.Sprintf("%s IS NOT NULL", topPerformerOrderFields[orderBy])).
+		Order(fmt.Sprintf("%s %s", topPerformerOrderFields[orderBy], direction)).
+		Order("ts_code ASC").
 		Limit(limit).
 		Find(&reports).Error
 
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTopPerformersOrder(t *testing.T) {
+	for _, field := range []string{"eps", "roe", "revenue_yoy", "gross_margin", DefaultTopPerformersOrder} {
+		assert.NoError(t, ValidateTopPerformersOrder(field), field)
+	}
+
+	// ROA没有对应的列，也无法由现有字段计算
+	err := ValidateTopPerformersOrder("roa")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"roa"`)
+	for _, field := range TopPerformerOrderFields() {
+		assert.Contains(t, err.Error(), field)
+	}
+
+	// 排序字段会拼接进SQL，不能接受任意表达式
+	assert.Error(t, ValidateTopPerformersOrder("eps; DROP TABLE stocks"))
+	assert.Error(t, ValidateTopPerformersOrder(""))
+}
+
+func TestTopPerformerOrderFields_Sorted(t *testing.T) {
+	fields := TopPerformerOrderFields()
+	assert.IsIncreasing(t, fields)
+	assert.Contains(t, fields, "roe")
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RealtimeSnapshot 实时行情快照仓库
+type RealtimeSnapshot struct {
+	db *gorm.DB
+}
+
+// NewRealtimeSnapshot 创建实时行情快照仓库
+func NewRealtimeSnapshot(db *gorm.DB) *RealtimeSnapshot {
+	return &RealtimeSnapshot{
+		db: db,
+	}
+}
+
+// Upsert 保存实时行情快照，同一股票覆盖为最新一次采集的行情
+func (r *RealtimeSnapshot) Upsert(snapshots []model.RealtimeSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ts_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"trade_date", "open", "high", "low", "close", "volume", "amount", "fetched_at"}),
+	}).CreateInBatches(&snapshots, 500).Error; err != nil {
+		logger.Errorf("Failed to upsert realtime snapshots: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetByTsCodes 获取指定股票的实时行情快照，没有快照的股票不在结果中
+func (r *RealtimeSnapshot) GetByTsCodes(tsCodes []string) ([]model.RealtimeSnapshot, error) {
+	var snapshots []model.RealtimeSnapshot
+	if err := r.db.Where("ts_code IN ?", tsCodes).Order("ts_code ASC").Find(&snapshots).Error; err != nil {
+		logger.Errorf("Failed to get realtime snapshots: %v", err)
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SelectionResult 选股结果仓库
+type SelectionResult struct {
+	db *gorm.DB
+}
+
+// NewSelectionResult 创建选股结果仓库
+func NewSelectionResult(db *gorm.DB) *SelectionResult {
+	return &SelectionResult{
+		db: db,
+	}
+}
+
+// CreateBatch 批量保存选股结果
+func (r *SelectionResult) CreateBatch(results []model.SelectionResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&results).Error; err != nil {
+		logger.Errorf("Failed to save %d selection results: %v", len(results), err)
+		return err
+	}
+	return nil
+}
+
+// GetByID 根据ID获取选股结果，不存在时返回nil
+func (r *SelectionResult) GetByID(id uint) (*model.SelectionResult, error) {
+	var result model.SelectionResult
+	if err := r.db.First(&result, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get selection result %d: %v", id, err)
+		return nil, err
+	}
+	return &result, nil
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"stock/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Shareholder 股东户数数据仓库
@@ -50,34 +54,119 @@ func (r *Shareholder) GetLatest(tsCode string) (*model.ShareholderCount, error)
 	return &count, nil
 }
 
+// GetLatestBatch 批量获取股票最新一期股东户数，tsCodes为nil时获取所有股票，没有记录的股票不在结果中
+func (r *Shareholder) GetLatestBatch(tsCodes []string) (map[string]model.ShareholderCount, error) {
+	var counts []model.ShareholderCount
+	if err := LatestPerGroup(r.db, model.ShareholderCount{}.TableName(), "ts_code", "end_date", tsCodes).
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]model.ShareholderCount, len(counts))
+	for _, count := range counts {
+		result[count.TsCode] = count
+	}
+	return result, nil
+}
+
+// shareholderUpsertColumns 股东户数记录已存在时更新的字段，创建时间保持不变
+var shareholderUpsertColumns = []string{
+	"security_code", "security_name", "holder_num", "pre_holder_num", "holder_num_change", "holder_num_ratio",
+	"avg_market_cap", "avg_hold_num", "total_market_cap", "total_a_shares", "interval_chrate",
+	"change_shares", "change_reason", "hold_notice_date", "pre_end_date", "updated_at",
+}
+
 // UpsertBatch 批量插入或更新股东户数记录
+// 按(ts_code, end_date)执行ON DUPLICATE KEY UPDATE，重复同步重叠的历史季度不会主键冲突，
+// 已有记录更新可变字段和更新时间，保留创建时间
 func (r *Shareholder) UpsertBatch(counts []*model.ShareholderCount) error {
 	if len(counts) == 0 {
 		return nil
 	}
 
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		for _, count := range counts {
-			// 检查记录是否存在
-			var existing model.ShareholderCount
-			err := tx.Where("ts_code = ? AND end_date = ?", count.TsCode, count.EndDate).
-				First(&existing).Error
-
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				// 记录不存在，创建新记录
-				if err := tx.Create(count).Error; err != nil {
-					return fmt.Errorf("创建股东户数记录失败: %v", err)
-				}
-			} else if err != nil {
-				return fmt.Errorf("查询股东户数记录失败: %v", err)
-			} else {
-				// 记录存在，更新记录
-				count.CreatedAt = existing.CreatedAt
-				if err := tx.Save(count).Error; err != nil {
-					return fmt.Errorf("更新股东户数记录失败: %v", err)
-				}
-			}
+	now := time.Now()
+	for _, count := range counts {
+		if count.CreatedAt.IsZero() {
+			count.CreatedAt = now
 		}
-		return nil
-	})
+		count.UpdatedAt = now
+	}
+
+	err := r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ts_code"}, {Name: "end_date"}},
+		DoUpdates: clause.AssignmentColumns(shareholderUpsertColumns),
+	}).CreateInBatches(counts, 100).Error
+	if err != nil {
+		return fmt.Errorf("保存股东户数记录失败: %v", err)
+	}
+	return nil
+}
+
+// GetByEndDateRange 分页获取统计截止日期在[startEndDate, endEndDate]内的股东户数，按截止日期降序，同时返回总数
+func (r *Shareholder) GetByEndDateRange(startEndDate, endEndDate, offset, limit int) ([]*model.ShareholderCount, int64, error) {
+	query := r.db.Model(&model.ShareholderCount{}).Where("end_date BETWEEN ? AND ?", startEndDate, endEndDate)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var counts []*model.ShareholderCount
+	if err := query.Order("end_date DESC, ts_code").Offset(offset).Limit(limit).Find(&counts).Error; err != nil {
+		return nil, 0, err
+	}
+	return counts, total, nil
+}
+
+// DefaultTopShareholdersMetric 股东户数排行的默认排序指标
+const DefaultTopShareholdersMetric = "holder_num"
+
+// topShareholderMetrics 股东户数排行支持的排序指标及对应的列
+var topShareholderMetrics = map[string]string{
+	"holder_num":        "holder_num",
+	"avg_market_cap":    "avg_market_cap",
+	"total_market_cap":  "total_market_cap",
+	"holder_num_change": "holder_num_change",
+}
+
+// TopShareholderMetrics 股东户数排行支持的排序指标，按名称排序
+func TopShareholderMetrics() []string {
+	metrics := make([]string, 0, len(topShareholderMetrics))
+	for metric := range topShareholderMetrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// NormalizeTopShareholdersMetric 规范化并校验股东户数排行的排序指标
+// 忽略大小写和首尾空白，连字符视为下划线（如holder-num），不支持时返回列出可选指标的错误
+func NormalizeTopShareholdersMetric(metric string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(metric)), "-", "_")
+	if _, ok := topShareholderMetrics[normalized]; !ok {
+		return "", fmt.Errorf("unsupported shareholder metric %q, valid options: %s", metric, strings.Join(TopShareholderMetrics(), ", "))
+	}
+	return normalized, nil
+}
+
+// GetTopShareholders 按指标对各股票最新一期股东户数排序，asc为true时按升序排列
+func (r *Shareholder) GetTopShareholders(metric string, limit int, asc bool) ([]*model.ShareholderCount, error) {
+	metric, err := NormalizeTopShareholdersMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	direction := "DESC"
+	if asc {
+		direction = "ASC"
+	}
+
+	var counts []*model.ShareholderCount
+	err = LatestPerGroup(r.db, model.ShareholderCount{}.TableName(), "ts_code", "end_date", nil).
+		Order(fmt.Sprintf("%s %s", topShareholderMetrics[metric], direction)).
+		Order("ts_code ASC").
+		Limit(limit).
+		Find(&counts).Error
+
+	return counts, err
 }
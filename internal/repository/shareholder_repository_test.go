@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTopShareholdersMetric(t *testing.T) {
+	for _, metric := range TopShareholderMetrics() {
+		normalized, err := NormalizeTopShareholdersMetric(metric)
+		require.NoError(t, err, metric)
+		assert.Equal(t, metric, normalized)
+	}
+
+	// 忽略大小写和首尾空白，连字符与路由中的写法一致
+	normalized, err := NormalizeTopShareholdersMetric(" Avg-Market-Cap ")
+	require.NoError(t, err)
+	assert.Equal(t, "avg_market_cap", normalized)
+
+	// 排序列会拼接进SQL，不能接受任意列或表达式
+	for _, metric := range []string{"", "holder_num_ratio", "holder_num; DROP TABLE stocks"} {
+		_, err := NormalizeTopShareholdersMetric(metric)
+		require.Error(t, err, metric)
+		for _, valid := range TopShareholderMetrics() {
+			assert.Contains(t, err.Error(), valid)
+		}
+	}
+	assert.Equal(t, []string{"avg_market_cap", "holder_num", "holder_num_change", "total_market_cap"}, TopShareholderMetrics())
+}
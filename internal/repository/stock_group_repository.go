@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// StockGroup 股票分组仓库
+type StockGroup struct {
+	db *gorm.DB
+}
+
+// NewStockGroup 创建股票分组仓库
+func NewStockGroup(db *gorm.DB) *StockGroup {
+	return &StockGroup{
+		db: db,
+	}
+}
+
+// Create 创建股票分组
+func (r *StockGroup) Create(group *model.StockGroup) error {
+	if err := r.db.Create(group).Error; err != nil {
+		logger.Errorf("Failed to create stock group %s: %v", group.Name, err)
+		return err
+	}
+	return nil
+}
+
+// GetByID 根据ID获取股票分组，不存在时返回nil
+func (r *StockGroup) GetByID(id uint) (*model.StockGroup, error) {
+	var group model.StockGroup
+	if err := r.db.First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get stock group %d: %v", id, err)
+		return nil, err
+	}
+	return &group, nil
+}
+
+// List 获取股票分组，kind为空表示不限，按ID排序
+func (r *StockGroup) List(kind model.StockGroupKind) ([]model.StockGroup, error) {
+	var groups []model.StockGroup
+	query := r.db.Order("id ASC")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Find(&groups).Error; err != nil {
+		logger.Errorf("Failed to list stock groups: %v", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Update 更新股票分组
+func (r *StockGroup) Update(group *model.StockGroup) error {
+	if err := r.db.Save(group).Error; err != nil {
+		logger.Errorf("Failed to update stock group %d: %v", group.ID, err)
+		return err
+	}
+	return nil
+}
+
+// Delete 删除股票分组，返回删除的行数
+func (r *StockGroup) Delete(id uint) (int64, error) {
+	result := r.db.Delete(&model.StockGroup{}, id)
+	if result.Error != nil {
+		logger.Errorf("Failed to delete stock group %d: %v", id, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
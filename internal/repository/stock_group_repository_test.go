@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestStockGroup_CRUD(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var statements []string
+	capture := func(tx *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", capture))
+
+	repo := NewStockGroup(db)
+	group := &model.StockGroup{Name: "沪深300", Kind: model.StockGroupKindIndex, Source: "000300.SH"}
+	group.SetCodes([]string{"600519.sh", "000001.SZ"})
+	require.NoError(t, repo.Create(group))
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0], "INSERT INTO `stock_groups`")
+	assert.Contains(t, statements[0], "'600519.SH,000001.SZ'")
+
+	statements = nil
+	_, err = repo.GetByID(7)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0], "WHERE `stock_groups`.`id` = 7")
+
+	statements = nil
+	_, err = repo.List(model.StockGroupKindIndex)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, "SELECT * FROM `stock_groups` WHERE kind = 'index' ORDER BY id ASC", statements[0])
+
+	statements = nil
+	group.ID = 7
+	group.SetCodes([]string{"600036.SH"})
+	require.NoError(t, repo.Update(group))
+	require.NotEmpty(t, statements)
+	assert.Contains(t, statements[0], "UPDATE `stock_groups` SET")
+	assert.Contains(t, statements[0], "`ts_codes`='600036.SH'")
+	assert.Contains(t, statements[0], "WHERE `id` = 7")
+
+	statements = nil
+	_, err = repo.Delete(7)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, "DELETE FROM `stock_groups` WHERE `stock_groups`.`id` = 7", statements[0])
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// StockIdentityChange 股票身份变更记录仓库
+type StockIdentityChange struct {
+	db *gorm.DB
+}
+
+// NewStockIdentityChange 创建股票身份变更记录仓库
+func NewStockIdentityChange(db *gorm.DB) *StockIdentityChange {
+	return &StockIdentityChange{
+		db: db,
+	}
+}
+
+// Create 批量保存身份变更记录
+func (r *StockIdentityChange) Create(changes []model.StockIdentityChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&changes).Error; err != nil {
+		logger.Errorf("Failed to create stock identity changes: %v", err)
+		return err
+	}
+	return nil
+}
+
+// List 按发现时间倒序获取身份变更记录，tsCode为空表示不限股票，onlyNeedsReview为true时只返回待复核的记录
+func (r *StockIdentityChange) List(tsCode string, onlyNeedsReview bool, limit int) ([]model.StockIdentityChange, error) {
+	var changes []model.StockIdentityChange
+	query := r.db.Order("created_at DESC").Order("id DESC")
+	if tsCode != "" {
+		query = query.Where("ts_code = ?", tsCode)
+	}
+	if onlyNeedsReview {
+		query = query.Where("needs_review = ? AND reviewed_at IS NULL", true)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&changes).Error; err != nil {
+		logger.Errorf("Failed to list stock identity changes: %v", err)
+		return nil, err
+	}
+	return changes, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"sort"
+
 	"stock/internal/logger"
 	"stock/internal/model"
 
@@ -92,10 +94,18 @@ func (r *Stock) UpsertStocks(stocks []model.Stock) error {
 
 		batch := stocks[i:end]
 
-		// 使用Clauses来实现ON DUPLICATE KEY UPDATE
+		// 使用Clauses来实现ON DUPLICATE KEY UPDATE，数据源未返回上市日期或上市状态时保留已有值
+		updates := clause.AssignmentColumns([]string{"name", "area", "industry", "market", "is_active", "updated_at"})
+		updates = append(updates, clause.Assignment{
+			Column: clause.Column{Name: "list_date"},
+			Value:  gorm.Expr("COALESCE(VALUES(list_date), list_date)"),
+		}, clause.Assignment{
+			Column: clause.Column{Name: "status"},
+			Value:  gorm.Expr("COALESCE(NULLIF(VALUES(status), ''), status)"),
+		})
 		if err := tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "ts_code"}},
-			DoUpdates: clause.AssignmentColumns([]string{"name", "area", "industry", "market", "list_date", "is_active", "updated_at"}),
+			DoUpdates: updates,
 		}).Create(&batch).Error; err != nil {
 			tx.Rollback()
 			logger.Errorf("Failed to upsert stock batch: %v", err)
@@ -125,6 +135,19 @@ func (r *Stock) GetStockByTsCode(tsCode string) (*model.Stock, error) {
 	return &stock, nil
 }
 
+// GetStocksByTsCodes 根据股票代码批量获取股票信息，包括不活跃的股票
+func (r *Stock) GetStocksByTsCodes(tsCodes []string) ([]model.Stock, error) {
+	var stocks []model.Stock
+	if len(tsCodes) == 0 {
+		return stocks, nil
+	}
+	if err := r.db.Where("ts_code IN ?", tsCodes).Find(&stocks).Error; err != nil {
+		logger.Errorf("Failed to get stocks by codes: %v", err)
+		return nil, err
+	}
+	return stocks, nil
+}
+
 // GetAllStocks 获取所有股票列表
 func (r *Stock) GetAllStocks() ([]model.Stock, error) {
 	var stocks []model.Stock
@@ -135,6 +158,83 @@ func (r *Stock) GetAllStocks() ([]model.Stock, error) {
 	return stocks, nil
 }
 
+// GetAllStocksPriorityFirst 获取所有活跃股票列表，优先同步的股票排在前面
+func (r *Stock) GetAllStocksPriorityFirst() ([]model.Stock, error) {
+	var stocks []model.Stock
+	if err := r.db.Where("is_active = ?", true).
+		Order("priority DESC").Order("ts_code ASC").
+		Find(&stocks).Error; err != nil {
+		logger.Errorf("Failed to get priority ordered stocks: %v", err)
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// GetPriorityStocks 获取所有优先同步的活跃股票
+func (r *Stock) GetPriorityStocks() ([]model.Stock, error) {
+	var stocks []model.Stock
+	if err := r.db.Where("is_active = ? AND priority = ?", true, true).
+		Order("ts_code ASC").Find(&stocks).Error; err != nil {
+		logger.Errorf("Failed to get priority stocks: %v", err)
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// SetStockPriority 设置股票是否优先同步
+func (r *Stock) SetStockPriority(tsCodes []string, priority bool) (int64, error) {
+	if len(tsCodes) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.Model(&model.Stock{}).Where("ts_code IN ?", tsCodes).Update("priority", priority)
+	if result.Error != nil {
+		logger.Errorf("Failed to set priority=%v for stocks %v: %v", priority, tsCodes, result.Error)
+		return 0, result.Error
+	}
+	logger.Debugf("Set priority=%v for %d stocks", priority, result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
+// UpdateStockStatusBatch 批量更新股票是否活跃，并按是否活跃设置上市状态（活跃为上市，非活跃为退市）
+// 按目标状态分组，每组按ts_code IN分批更新，每批最多500只，返回受影响的行数
+func (r *Stock) UpdateStockStatusBatch(statuses map[string]bool) (int64, error) {
+	if len(statuses) == 0 {
+		return 0, nil
+	}
+
+	groups := make(map[bool][]string, 2)
+	for tsCode, isActive := range statuses {
+		groups[isActive] = append(groups[isActive], tsCode)
+	}
+
+	const batchSize = 500 // 每批最多500只股票
+	var affected int64
+	for _, isActive := range []bool{true, false} {
+		tsCodes := groups[isActive]
+		sort.Strings(tsCodes)
+		status := model.StockStatusListed
+		if !isActive {
+			status = model.StockStatusDelisted
+		}
+
+		for i := 0; i < len(tsCodes); i += batchSize {
+			batch := tsCodes[i:min(i+batchSize, len(tsCodes))]
+			result := r.db.Model(&model.Stock{}).Where("ts_code IN ?", batch).Updates(map[string]interface{}{
+				"is_active": isActive,
+				"status":    status,
+			})
+			if result.Error != nil {
+				logger.Errorf("Failed to update is_active=%v for %d stocks: %v", isActive, len(batch), result.Error)
+				return affected, result.Error
+			}
+			affected += result.RowsAffected
+		}
+	}
+	logger.Debugf("Updated status for %d stocks, %d rows affected", len(statuses), affected)
+	return affected, nil
+}
+
 // GetStocksByMarket 根据市场获取股票列表
 func (r *Stock) GetStocksByMarket(market string) ([]model.Stock, error) {
 	var stocks []model.Stock
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestStock_UpdateStockStatusBatch(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var statements []string
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		tx.RowsAffected = int64(len(tx.Statement.Vars) - 3) // 去掉is_active、status、updated_at，其余为股票代码
+	}))
+	repo := NewStock(db)
+
+	// 多只股票一次更新，按目标状态分组，每组一条语句
+	affected, err := repo.UpdateStockStatusBatch(map[string]bool{
+		"600000.SH": true,
+		"000001.SZ": true,
+		"600087.SH": false,
+		"000406.SZ": false,
+		"600001.SH": false,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), affected)
+	require.Len(t, statements, 2)
+	assert.Contains(t, statements[0], "UPDATE `stocks` SET")
+	assert.Contains(t, statements[0], "`is_active`=true")
+	assert.Contains(t, statements[0], "`status`='listed'")
+	assert.Contains(t, statements[0], "WHERE ts_code IN ('000001.SZ','600000.SH')")
+	assert.Contains(t, statements[1], "`is_active`=false")
+	assert.Contains(t, statements[1], "`status`='delisted'")
+	assert.Contains(t, statements[1], "WHERE ts_code IN ('000406.SZ','600001.SH','600087.SH')")
+
+	// 超过500只时分批
+	statements = nil
+	statuses := make(map[string]bool, 1200)
+	for i := 0; i < 1200; i++ {
+		statuses[fmt.Sprintf("%06d.SZ", i)] = false
+	}
+	affected, err = repo.UpdateStockStatusBatch(statuses)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1200), affected)
+	assert.Len(t, statements, 3)
+
+	// 没有变化时不执行语句
+	statements = nil
+	affected, err = repo.UpdateStockStatusBatch(nil)
+	require.NoError(t, err)
+	assert.Zero(t, affected)
+	assert.Empty(t, statements)
+}
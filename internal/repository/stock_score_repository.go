@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockScore 股票评分仓库
+type StockScore struct {
+	db *gorm.DB
+}
+
+// NewStockScore 创建股票评分仓库
+func NewStockScore(db *gorm.DB) *StockScore {
+	return &StockScore{
+		db: db,
+	}
+}
+
+// Upsert 保存评分，同一股票同一日期的评分覆盖更新
+func (r *StockScore) Upsert(scores []model.StockScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ts_code"}, {Name: "trade_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"score", "technical_score", "fundamental_score", "shareholder_score", "updated_at",
+		}),
+	}).CreateInBatches(&scores, 500).Error; err != nil {
+		logger.Errorf("Failed to upsert stock scores: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetLatest 获取指定股票最新一天的评分，不存在时返回nil
+func (r *StockScore) GetLatest(tsCode string) (*model.StockScore, error) {
+	var score model.StockScore
+	if err := r.db.Where("ts_code = ?", tsCode).Order("trade_date DESC").First(&score).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get latest score for %s: %v", tsCode, err)
+		return nil, err
+	}
+	return &score, nil
+}
+
+// GetLatestTradeDate 获取最新的评分日期，没有评分时返回0
+func (r *StockScore) GetLatestTradeDate() (int, error) {
+	var tradeDate *int
+	if err := r.db.Model(&model.StockScore{}).Select("MAX(trade_date)").Scan(&tradeDate).Error; err != nil {
+		logger.Errorf("Failed to get latest score date: %v", err)
+		return 0, err
+	}
+	if tradeDate == nil {
+		return 0, nil
+	}
+	return *tradeDate, nil
+}
+
+// GetRanking 获取指定日期评分最高的股票
+func (r *StockScore) GetRanking(tradeDate, limit int) ([]model.StockScore, error) {
+	var scores []model.StockScore
+	if err := r.db.Where("trade_date = ?", tradeDate).
+		Order("score DESC").Order("ts_code ASC").
+		Limit(limit).
+		Find(&scores).Error; err != nil {
+		logger.Errorf("Failed to get score ranking for %d: %v", tradeDate, err)
+		return nil, err
+	}
+	return scores, nil
+}
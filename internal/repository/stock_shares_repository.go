@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockShares 股本变动记录仓库
+type StockShares struct {
+	db *gorm.DB
+}
+
+// NewStockShares 创建股本变动记录仓库
+func NewStockShares(db *gorm.DB) *StockShares {
+	return &StockShares{
+		db: db,
+	}
+}
+
+// GetHistory 获取股票的股本变动记录，按生效日期升序
+func (r *StockShares) GetHistory(tsCode string) ([]model.StockShares, error) {
+	var records []model.StockShares
+	if err := r.db.Where("ts_code = ?", tsCode).Order("change_date ASC").Find(&records).Error; err != nil {
+		logger.Errorf("Failed to get stock shares history of %s: %v", tsCode, err)
+		return nil, err
+	}
+	return records, nil
+}
+
+// getLatestBefore 获取生效日期不晚于date的最后一条记录，没有时返回nil
+func (r *StockShares) getLatestBefore(tsCode string, date int) (*model.StockShares, error) {
+	var records []model.StockShares
+	err := r.db.Where("ts_code = ? AND change_date <= ?", tsCode, date).
+		Order("change_date DESC").
+		Limit(1).
+		Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+// RecordChange 与生效日期之前的最后一条记录比较，股本有变化时保存，返回是否保存
+// 只有总股本或只有流通股本的记录（如股东户数数据只有总股本），未知的一项沿用之前的值；
+// 同一天重复记录时以后一次为准
+func (r *StockShares) RecordChange(record model.StockShares) (bool, error) {
+	if record.TotalShares <= 0 && record.FloatShares <= 0 {
+		return false, nil
+	}
+
+	prev, err := r.getLatestBefore(record.TsCode, record.ChangeDate)
+	if err != nil {
+		logger.Errorf("Failed to get stock shares of %s: %v", record.TsCode, err)
+		return false, err
+	}
+	if prev != nil {
+		if prev.SameShares(record) {
+			return false, nil
+		}
+		if record.TotalShares <= 0 {
+			record.TotalShares = prev.TotalShares
+		}
+		if record.FloatShares <= 0 {
+			record.FloatShares = prev.FloatShares
+		}
+	}
+
+	err = r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ts_code"}, {Name: "change_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_shares", "float_shares", "source"}),
+	}).Create(&record).Error
+	if err != nil {
+		logger.Errorf("Failed to save stock shares of %s: %v", record.TsCode, err)
+		return false, err
+	}
+	return true, nil
+}
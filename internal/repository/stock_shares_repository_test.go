@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestStockShares_RecordChange(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 库中最近一条股本记录
+	var stored []model.StockShares
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]model.StockShares); ok {
+			*dest = append(*dest, stored...)
+		}
+	}))
+	var written []model.StockShares
+	var upsertSQL string
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		if record, ok := tx.Statement.Dest.(*model.StockShares); ok {
+			written = append(written, *record)
+			upsertSQL = tx.Statement.SQL.String()
+		}
+	}))
+	repo := NewStockShares(db)
+
+	// 没有历史记录时直接保存
+	saved, err := repo.RecordChange(model.StockShares{TsCode: "600000.SH", ChangeDate: 20250901, TotalShares: 29352000000, FloatShares: 29352000000, Source: model.SharesSourceDetail})
+	require.NoError(t, err)
+	assert.True(t, saved)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], "WHERE ts_code = '600000.SH' AND change_date <= 20250901 ORDER BY change_date DESC LIMIT 1")
+	assert.Contains(t, upsertSQL, "ON DUPLICATE KEY UPDATE")
+
+	// 股本未变化时不保存
+	stored = []model.StockShares{written[0]}
+	written = nil
+	saved, err = repo.RecordChange(model.StockShares{TsCode: "600000.SH", ChangeDate: 20251010, TotalShares: 29352000000, FloatShares: 29352000000, Source: model.SharesSourceDetail})
+	require.NoError(t, err)
+	assert.False(t, saved)
+	assert.Empty(t, written)
+
+	// 增发后总股本变化，只有总股本的记录沿用之前的流通股本
+	saved, err = repo.RecordChange(model.StockShares{TsCode: "600000.SH", ChangeDate: 20251231, TotalShares: 33306000000, Source: model.SharesSourceShareholder})
+	require.NoError(t, err)
+	assert.True(t, saved)
+	require.Len(t, written, 1)
+	assert.Equal(t, int64(33306000000), written[0].TotalShares)
+	assert.Equal(t, int64(29352000000), written[0].FloatShares)
+
+	// 股本未知时不查询也不保存
+	queries, written = nil, nil
+	saved, err = repo.RecordChange(model.StockShares{TsCode: "600000.SH", ChangeDate: 20260101})
+	require.NoError(t, err)
+	assert.False(t, saved)
+	assert.Empty(t, queries)
+}
@@ -285,6 +285,64 @@ func (r *TechnicalIndicatorRepository) GetSymbolsByDate(tradeDate int, period mo
 	return symbols, err
 }
 
+// GetByTradeDate 获取所有股票在指定交易日的技术指标
+func (r *TechnicalIndicatorRepository) GetByTradeDate(tradeDate int, period model.TechnicalIndicatorPeriod) ([]*model.TechnicalIndicator, error) {
+	var indicators []*model.TechnicalIndicator
+	indicator := model.NewTechnicalIndicator(period)
+
+	if err := r.db.Table(indicator.TableName()).
+		Where("trade_date = ?", tradeDate).
+		Order("symbol ASC").
+		Find(&indicators).Error; err != nil {
+		return nil, err
+	}
+
+	for _, ind := range indicators {
+		ind.Period = period
+	}
+	return indicators, nil
+}
+
+// GetPrevBatch 批量获取股票在before之前最近一个交易日的技术指标，键为股票代码，没有更早记录的股票不在结果中
+func (r *TechnicalIndicatorRepository) GetPrevBatch(symbols []string, before int, period model.TechnicalIndicatorPeriod) (map[string]*model.TechnicalIndicator, error) {
+	result := make(map[string]*model.TechnicalIndicator, len(symbols))
+	if len(symbols) == 0 {
+		return result, nil
+	}
+
+	tableName := model.NewTechnicalIndicator(period).TableName()
+	keys := latestKeysPerGroup(r.db, tableName, "symbol", "trade_date", symbols).
+		Where("trade_date < ?", before)
+
+	var indicators []*model.TechnicalIndicator
+	if err := r.db.Table(tableName).Where("(?, ?) IN (?)",
+		clause.Column{Table: tableName, Name: "symbol"},
+		clause.Column{Table: tableName, Name: "trade_date"}, keys).
+		Find(&indicators).Error; err != nil {
+		return nil, err
+	}
+
+	for _, ind := range indicators {
+		ind.Period = period
+		result[ind.Symbol] = ind
+	}
+	return result, nil
+}
+
+// GetLatestTradeDate 获取技术指标表中最新的交易日期，没有数据时返回0
+func (r *TechnicalIndicatorRepository) GetLatestTradeDate(period model.TechnicalIndicatorPeriod) (int, error) {
+	var latest *int
+	if err := r.db.Table(model.NewTechnicalIndicator(period).TableName()).
+		Select("MAX(trade_date)").
+		Scan(&latest).Error; err != nil {
+		return 0, err
+	}
+	if latest == nil {
+		return 0, nil
+	}
+	return *latest, nil
+}
+
 // GetDateRange 获取指定股票的技术指标日期范围
 func (r *TechnicalIndicatorRepository) GetDateRange(symbol string, period model.TechnicalIndicatorPeriod) (minDate, maxDate int, err error) {
 	indicator := model.NewTechnicalIndicator(period)
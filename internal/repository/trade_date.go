@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"fmt"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// rejectInvalidTradeDates 丢弃交易日期不是真实日历日期的K线数据并记录日志，避免写入后下游解析日期失败
+// 严格模式下存在非法日期时整批拒绝写入并返回错误
+func rejectInvalidTradeDates[T model.TradeDated](kind string, data []T) ([]T, error) {
+	valid, invalid := model.FilterValidTradeDates(data)
+	if len(invalid) > 0 {
+		msg := fmt.Sprintf("%d %s records with invalid trade dates: %v", len(invalid), kind, invalid)
+		if err := utils.StrictViolation(msg); err != nil {
+			return nil, err
+		}
+		logger.Warnf("Rejected %s", msg)
+	}
+	return valid, nil
+}
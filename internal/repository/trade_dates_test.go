@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestDailyData_GetTradeDates(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 稀疏序列：1月3日、4日停牌，1月8日之后缺数据
+	seeded := []int{20240102, 20240105, 20240108}
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]int); ok {
+			*dest = append(*dest, seeded...)
+		}
+	}))
+
+	dates, err := NewDailyData(db).GetTradeDates("000001.SZ", 20240101, 20240110)
+	require.NoError(t, err)
+	assert.Equal(t, seeded, dates)
+
+	// 只查询去重后的交易日期列，按分表和日期区间过滤
+	require.Len(t, queries, 1)
+	assert.Equal(t, "SELECT DISTINCT trade_date FROM `daily_data_000` "+
+		"WHERE ts_code = '000001.SZ' AND trade_date >= 20240101 AND trade_date <= 20240110 "+
+		"ORDER BY trade_date ASC", queries[0])
+}
+
+func TestDailyData_EachDailyDataBatch(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 依次返回满批、不满批，不满批后停止翻页
+	seeded := [][]model.DailyData{
+		{{TsCode: "600519.SH", TradeDate: 20240102}, {TsCode: "600519.SH", TradeDate: 20240103}},
+		{{TsCode: "600519.SH", TradeDate: 20240104}},
+	}
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]model.DailyData); ok && len(queries) <= len(seeded) {
+			*dest = append(*dest, seeded[len(queries)-1]...)
+		}
+	}))
+
+	var dates []int
+	require.NoError(t, NewDailyData(db).EachDailyDataBatch("600519.SH", 20240101, 2, func(batch []model.DailyData) error {
+		for _, d := range batch {
+			dates = append(dates, d.TradeDate)
+		}
+		return nil
+	}))
+	assert.Equal(t, []int{20240102, 20240103, 20240104}, dates)
+
+	// 按上一批最后的交易日期翻页
+	require.Len(t, queries, 2)
+	assert.Equal(t, "SELECT * FROM `daily_data_600` WHERE ts_code = '600519.SH' AND trade_date > 20240100 "+
+		"ORDER BY trade_date ASC LIMIT 2", queries[0])
+	assert.Equal(t, "SELECT * FROM `daily_data_600` WHERE ts_code = '600519.SH' AND trade_date > 20240103 "+
+		"ORDER BY trade_date ASC LIMIT 2", queries[1])
+}
+
+func TestDailyData_GetAnalysisDailyData(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 已标记两段问题区间
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]model.DataExclusion); ok {
+			*dest = append(*dest,
+				model.DataExclusion{TsCode: "600519.SH", Period: model.TechnicalIndicatorPeriodDaily, StartDate: 20230601, EndDate: 20230630},
+				model.DataExclusion{TsCode: "600519.SH", Period: model.TechnicalIndicatorPeriodDaily, StartDate: 20231102, EndDate: 20231102})
+		}
+	}))
+
+	_, err = NewDailyData(db).GetAnalysisDailyData("600519.SH", time.Time{}, time.Time{}, 120)
+	require.NoError(t, err)
+
+	// 排除区间在查询中过滤，limit作用于排除后的K线
+	require.Len(t, queries, 2)
+	assert.Equal(t, "SELECT * FROM `data_exclusions` WHERE ts_code = '600519.SH' AND period = 'daily' "+
+		"ORDER BY ts_code ASC,start_date ASC", queries[0])
+	assert.Equal(t, "SELECT * FROM `daily_data_600` WHERE ts_code = '600519.SH' "+
+		"AND (trade_date NOT BETWEEN 20230601 AND 20230630) AND (trade_date NOT BETWEEN 20231102 AND 20231102) "+
+		"ORDER BY trade_date DESC LIMIT 120", queries[1])
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Watchlist 自选股列表仓库
+type Watchlist struct {
+	db *gorm.DB
+}
+
+// NewWatchlist 创建自选股列表仓库
+func NewWatchlist(db *gorm.DB) *Watchlist {
+	return &Watchlist{
+		db: db,
+	}
+}
+
+// Create 创建自选股列表
+func (r *Watchlist) Create(watchlist *model.Watchlist) error {
+	if err := r.db.Create(watchlist).Error; err != nil {
+		logger.Errorf("Failed to create watchlist %s: %v", watchlist.Name, err)
+		return err
+	}
+	return nil
+}
+
+// GetByID 根据ID获取自选股列表，不存在时返回nil
+func (r *Watchlist) GetByID(id uint) (*model.Watchlist, error) {
+	var watchlist model.Watchlist
+	if err := r.db.First(&watchlist, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Errorf("Failed to get watchlist %d: %v", id, err)
+		return nil, err
+	}
+	return &watchlist, nil
+}
+
+// GetAll 获取所有自选股列表
+func (r *Watchlist) GetAll() ([]model.Watchlist, error) {
+	var watchlists []model.Watchlist
+	if err := r.db.Order("id ASC").Find(&watchlists).Error; err != nil {
+		logger.Errorf("Failed to get watchlists: %v", err)
+		return nil, err
+	}
+	return watchlists, nil
+}
@@ -31,6 +31,10 @@ func (r *WeeklyData) getTableName(tsCode string) string {
 
 // SaveWeeklyData 保存周K线数据到对应的分表
 func (r *WeeklyData) SaveWeeklyData(data []model.WeeklyData) error {
+	data, err := rejectInvalidTradeDates("weekly", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -61,6 +65,10 @@ func (r *WeeklyData) SaveWeeklyData(data []model.WeeklyData) error {
 
 // UpsertWeeklyData 更新或插入周K线数据到对应的分表（支持分批处理）
 func (r *WeeklyData) UpsertWeeklyData(data []model.WeeklyData) error {
+	data, err := rejectInvalidTradeDates("weekly", data)
+	if err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -100,6 +108,15 @@ func (r *WeeklyData) upsertDataInBatches(tableName string, data []model.WeeklyDa
 		}
 
 		batch := data[i:end]
+		if SkipUnchangedUpsert() {
+			var err error
+			if batch, err = filterUnchangedKLines(r.db, tableName, batch, func(d model.WeeklyData) model.DailyData { return model.DailyData(d) }); err != nil {
+				return fmt.Errorf("failed to compare batch %d-%d: %w", i, end-1, err)
+			}
+			if len(batch) == 0 {
+				continue
+			}
+		}
 
 		// 使用 ON DUPLICATE KEY UPDATE 进行批量 upsert
 		if err := r.db.Table(tableName).Save(&batch).Error; err != nil {
@@ -182,16 +199,21 @@ func (r *WeeklyData) DeleteWeeklyData(tsCode string, tradeDate time.Time) error
 	return nil
 }
 
+// DeleteRange 删除股票交易日期在[startDate, endDate]（YYYYMMDD格式，<=0表示不限制）内的周K线数据，返回删除的行数
+func (r *WeeklyData) DeleteRange(tsCode string, startDate, endDate int) (int64, error) {
+	return deleteKLineRange(r.db, "weekly", r.getTableName(tsCode), &model.WeeklyData{}, tsCode, startDate, endDate)
+}
+
+// PruneBefore 删除所有股票交易日期早于before（YYYYMMDD格式）的周K线数据，返回删除的行数
+func (r *WeeklyData) PruneBefore(before int) (int64, error) {
+	return pruneKLineTables(r.db, "weekly", model.KLineShardTables(model.WeeklyDataTablePrefix), &model.WeeklyData{}, before)
+}
+
 // GetWeeklyDataCount 获取周K线数据总数
 func (r *WeeklyData) GetWeeklyDataCount(tsCode string) (int64, error) {
 	if tsCode == "" {
 		// 获取所有表的数据总数
-		tableNames := []string{
-			"weekly_data_000", "weekly_data_001", "weekly_data_002",
-			"weekly_data_300", "weekly_data_301", "weekly_data_600",
-			"weekly_data_601", "weekly_data_603", "weekly_data_605",
-			"weekly_data_688", "weekly_data_other",
-		}
+		tableNames := model.KLineShardTables(model.WeeklyDataTablePrefix)
 
 		var totalCount int64
 		for _, tableName := range tableNames {
@@ -255,12 +277,7 @@ func (r *WeeklyData) GetDateRange(tsCode string) (startDate, endDate time.Time,
 		}
 	} else {
 		// 获取所有表的日期范围
-		tableNames := []string{
-			"weekly_data_000", "weekly_data_001", "weekly_data_002",
-			"weekly_data_300", "weekly_data_301", "weekly_data_600",
-			"weekly_data_601", "weekly_data_603", "weekly_data_605",
-			"weekly_data_688", "weekly_data_other",
-		}
+		tableNames := model.KLineShardTables(model.WeeklyDataTablePrefix)
 
 		var globalStartDate, globalEndDate int
 
@@ -307,12 +324,7 @@ func (r *WeeklyData) GetDateRange(tsCode string) (startDate, endDate time.Time,
 
 // GetAllTableStats 获取所有分表的统计信息
 func (r *WeeklyData) GetAllTableStats() (map[string]interface{}, error) {
-	tableNames := []string{
-		"weekly_data_000", "weekly_data_001", "weekly_data_002",
-		"weekly_data_300", "weekly_data_301", "weekly_data_600",
-		"weekly_data_601", "weekly_data_603", "weekly_data_605",
-		"weekly_data_688", "weekly_data_other",
-	}
+	tableNames := model.KLineShardTables(model.WeeklyDataTablePrefix)
 
 	tableStats := make(map[string]int64)
 	var totalCount int64
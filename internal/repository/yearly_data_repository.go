@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"stock/internal/logger"
@@ -24,6 +25,10 @@ func NewYearlyData(db *gorm.DB) *YearlyData {
 
 // Create 创建年K线数据
 func (r *YearlyData) Create(data *model.YearlyData) error {
+	if !model.ValidTradeDate(data.TradeDate) {
+		logger.Warnf("Rejected yearly data %s with invalid trade date %d", data.TsCode, data.TradeDate)
+		return fmt.Errorf("invalid trade date %d for %s", data.TradeDate, data.TsCode)
+	}
 	data.CreatedAt = time.Now()
 	if err := r.db.Create(data).Error; err != nil {
 		logger.Errorf("Failed to create yearly data: %v", err)
@@ -35,6 +40,10 @@ func (r *YearlyData) Create(data *model.YearlyData) error {
 
 // BatchCreate 批量创建年K线数据
 func (r *YearlyData) BatchCreate(dataList []model.YearlyData) error {
+	dataList, err := rejectInvalidTradeDates("yearly", dataList)
+	if err != nil {
+		return err
+	}
 	if len(dataList) == 0 {
 		return nil
 	}
@@ -55,6 +64,20 @@ func (r *YearlyData) BatchCreate(dataList []model.YearlyData) error {
 
 // Upsert 更新或插入年K线数据
 func (r *YearlyData) Upsert(data *model.YearlyData) error {
+	if !model.ValidTradeDate(data.TradeDate) {
+		logger.Warnf("Rejected yearly data %s with invalid trade date %d", data.TsCode, data.TradeDate)
+		return fmt.Errorf("invalid trade date %d for %s", data.TradeDate, data.TsCode)
+	}
+	if SkipUnchangedUpsert() {
+		changed, err := filterUnchangedKLines(r.db, data.TableName(), []model.YearlyData{*data}, yearlyToDaily)
+		if err != nil {
+			logger.Errorf("Failed to compare yearly data: %v", err)
+			return err
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+	}
 	now := time.Now()
 	data.UpdatedAt = now
 
@@ -84,6 +107,16 @@ func (r *YearlyData) Upsert(data *model.YearlyData) error {
 
 // BatchUpsert 批量更新或插入年K线数据
 func (r *YearlyData) BatchUpsert(dataList []model.YearlyData) error {
+	dataList, err := rejectInvalidTradeDates("yearly", dataList)
+	if err != nil {
+		return err
+	}
+	if SkipUnchangedUpsert() {
+		if dataList, err = filterUnchangedKLines(r.db, model.YearlyData{}.TableName(), dataList, yearlyToDaily); err != nil {
+			logger.Errorf("Failed to compare yearly data in batch: %v", err)
+			return err
+		}
+	}
 	if len(dataList) == 0 {
 		return nil
 	}
@@ -184,6 +217,16 @@ func (r *YearlyData) DeleteYearlyData(tsCode string, tradeDate time.Time) error
 	return nil
 }
 
+// DeleteRange 删除股票交易日期在[startDate, endDate]（YYYYMMDD格式，<=0表示不限制）内的年K线数据，返回删除的行数
+func (r *YearlyData) DeleteRange(tsCode string, startDate, endDate int) (int64, error) {
+	return deleteKLineRange(r.db, "yearly", model.YearlyData{}.TableName(), &model.YearlyData{}, tsCode, startDate, endDate)
+}
+
+// PruneBefore 删除所有股票交易日期早于before（YYYYMMDD格式）的年K线数据，返回删除的行数
+func (r *YearlyData) PruneBefore(before int) (int64, error) {
+	return pruneKLineTables(r.db, "yearly", []string{model.YearlyData{}.TableName()}, &model.YearlyData{}, before)
+}
+
 // GetYearlyDataCount 获取年K线数据总数
 func (r *YearlyData) GetYearlyDataCount(tsCode string) (int64, error) {
 	var count int64
@@ -228,3 +271,8 @@ func (r *YearlyData) intToDate(dateInt int) time.Time {
 	day := dateInt % 100
 	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 }
+
+// yearlyToDaily 年K线与日K线字段一致，转换后比较是否变化
+func yearlyToDaily(y model.YearlyData) model.DailyData {
+	return model.DailyData(y)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// AuditService 修改数据的操作的审计记录服务
+type AuditService struct {
+	repo *repository.AuditLog
+}
+
+var (
+	auditServiceInstance *AuditService
+	auditServiceOnce     sync.Once
+)
+
+// GetAuditService 获取审计记录服务单例
+func GetAuditService(db *gorm.DB) *AuditService {
+	auditServiceOnce.Do(func() {
+		auditServiceInstance = &AuditService{
+			repo: repository.NewAuditLog(db),
+		}
+	})
+	return auditServiceInstance
+}
+
+// NewAuditService 创建审计记录服务 (保持向后兼容)
+func NewAuditService(db *gorm.DB) *AuditService {
+	return GetAuditService(db)
+}
+
+// Record 保存一次操作的审计记录，params序列化为JSON保存，opErr为操作本身返回的错误，nil表示成功
+func (s *AuditService) Record(source model.AuditSource, action, tsCode, actor string, params any, opErr error) (*model.AuditLog, error) {
+	entry := &model.AuditLog{
+		Source:  source,
+		Action:  action,
+		TsCode:  tsCode,
+		Actor:   actor,
+		Success: opErr == nil,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit params of %s: %w", action, err)
+		}
+		entry.Params = string(data)
+	}
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+	if err := s.repo.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to record audit log of %s: %w", action, err)
+	}
+	return entry, nil
+}
+
+// List 按时间倒序获取符合条件的审计记录
+func (s *AuditService) List(filter repository.AuditLogFilter) ([]model.AuditLog, error) {
+	return s.repo.List(filter)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestAuditService_RecordsSyncRun(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var sqls []string
+	var recorded []model.AuditLog
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+		if entry, ok := tx.Statement.Dest.(*model.AuditLog); ok {
+			recorded = append(recorded, *entry)
+		}
+	}))
+
+	s := &AuditService{repo: repository.NewAuditLog(db)}
+	entry, err := s.Record(model.AuditSourceCron, "daily_kline", "", "worker",
+		map[string]int{"total": 5000, "failed": 10}, errors.New("10 stocks failed"))
+	require.NoError(t, err)
+
+	require.Len(t, sqls, 1)
+	assert.Contains(t, sqls[0], "INSERT INTO `audit_log`")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, *entry, recorded[0])
+	assert.Equal(t, model.AuditSourceCron, entry.Source)
+	assert.Equal(t, `{"failed":10,"total":5000}`, entry.Params)
+	assert.False(t, entry.Success)
+	assert.Equal(t, "10 stocks failed", entry.Error)
+}
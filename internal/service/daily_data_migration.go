@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// 旧日K线表迁移的默认参数
+const (
+	DefaultLegacyDailyTable      = "daily_data" // 未分表的旧日K线表
+	defaultDailyMigrationBatch   = 1000         // 默认每批迁移的行数
+	maxDailyMigrationBatchSize   = 10000        // 每批行数上限，避免单个事务过大
+	dailyMigrationPhaseCopy      = "copy"       // 按主键顺序复制全表
+	dailyMigrationPhaseCatchUp   = "catchup"    // 补迁移复制期间写入的最新交易日数据
+	dailyMigrationPhaseCompleted = "completed"  // 迁移完成
+)
+
+// legacyTableNamePattern 旧表名只允许字母、数字和下划线，表名会直接拼入SQL
+var legacyTableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// DailyDataMigrationOptions 旧日K线表迁移到分表的参数
+type DailyDataMigrationOptions struct {
+	SourceTable    string                                    // 旧日K线表名，为空时使用daily_data
+	BatchSize      int                                       // 每批迁移的行数，每批单独提交事务，<=0时使用默认值
+	CheckpointPath string                                    // 断点文件路径，为空时不记录断点
+	Progress       func(progress DailyDataMigrationProgress) // 每批提交后回调，可为nil
+}
+
+// DailyDataMigrationProgress 迁移进度
+type DailyDataMigrationProgress struct {
+	Phase         string        `json:"phase"`           // 当前阶段：copy、catchup
+	Migrated      int64         `json:"migrated"`        // 已迁移的行数，含断点之前的
+	LastTsCode    string        `json:"last_ts_code"`    // 最后提交的一行的股票代码
+	LastTradeDate int           `json:"last_trade_date"` // 最后提交的一行的交易日期
+	Elapsed       time.Duration `json:"elapsed"`         // 本次已用时间
+}
+
+// DailyDataMigrationResult 迁移结果
+type DailyDataMigrationResult struct {
+	Migrated    int64         `json:"migrated"`      // 累计迁移的行数，含断点之前的
+	Batches     int           `json:"batches"`       // 本次提交的批次数
+	Resumed     bool          `json:"resumed"`       // 是否从断点继续
+	CatchUpFrom int           `json:"catch_up_from"` // 补迁移的起始交易日期
+	Duration    time.Duration `json:"duration"`      // 本次耗时
+}
+
+// dailyDataMigrationCheckpoint 迁移断点，记录最后提交的一行的主键，重新执行时从其后继续
+type dailyDataMigrationCheckpoint struct {
+	SourceTable   string    `json:"source_table"`
+	Phase         string    `json:"phase"`
+	LastTsCode    string    `json:"last_ts_code"`
+	LastTradeDate int       `json:"last_trade_date"`
+	CatchUpFrom   int       `json:"catch_up_from"`
+	Migrated      int64     `json:"migrated"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// legacyDailyDataSource 旧日K线表的读取接口
+type legacyDailyDataSource interface {
+	GetLegacyDailyDataBatch(sourceTable, afterTsCode string, afterTradeDate, minTradeDate, limit int) ([]model.DailyData, error)
+	GetLegacyMaxTradeDate(sourceTable string) (int, error)
+}
+
+// MigrateLegacyDailyData 将未分表的旧日K线表迁移到按代码前缀分表的日K线表
+// 每批在单独的事务中提交，避免大表迁移长时间持有锁；每批提交后记录断点，中断后使用同一断点文件重新执行会从断点继续。
+// 迁移开始时记录旧表的最新交易日期，全表复制完成后补迁移不早于该日期的数据，覆盖复制期间旧表仍在写入的最新行情；
+// 复制期间对更早交易日的修改不会被补迁移，需要删除断点文件重新迁移
+func MigrateLegacyDailyData(ctx context.Context, db *gorm.DB, opts DailyDataMigrationOptions) (*DailyDataMigrationResult, error) {
+	return runDailyDataMigration(ctx, repository.NewDailyData(db), func(batch []model.DailyData) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return repository.NewDailyData(tx).UpsertDailyData(batch)
+		})
+	}, opts)
+}
+
+// runDailyDataMigration 执行迁移，commit在一个事务中写入一批数据
+func runDailyDataMigration(ctx context.Context, source legacyDailyDataSource, commit func(batch []model.DailyData) error,
+	opts DailyDataMigrationOptions) (*DailyDataMigrationResult, error) {
+	if opts.SourceTable == "" {
+		opts.SourceTable = DefaultLegacyDailyTable
+	}
+	if !legacyTableNamePattern.MatchString(opts.SourceTable) {
+		return nil, fmt.Errorf("invalid source table name %q", opts.SourceTable)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultDailyMigrationBatch
+	}
+	if opts.BatchSize > maxDailyMigrationBatchSize {
+		return nil, fmt.Errorf("batch size %d exceeds the limit of %d", opts.BatchSize, maxDailyMigrationBatchSize)
+	}
+
+	checkpoint, resumed, err := loadDailyMigrationCheckpoint(opts.CheckpointPath, opts.SourceTable)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		if checkpoint.CatchUpFrom, err = source.GetLegacyMaxTradeDate(opts.SourceTable); err != nil {
+			return nil, fmt.Errorf("failed to get max trade date of %s: %w", opts.SourceTable, err)
+		}
+	} else {
+		logger.Infof("Resuming daily data migration from checkpoint: phase %s, after %s %d, %d rows migrated",
+			checkpoint.Phase, checkpoint.LastTsCode, checkpoint.LastTradeDate, checkpoint.Migrated)
+	}
+
+	start := time.Now()
+	result := &DailyDataMigrationResult{Resumed: resumed, CatchUpFrom: checkpoint.CatchUpFrom}
+	for checkpoint.Phase != dailyMigrationPhaseCompleted {
+		if err := ctx.Err(); err != nil {
+			result.Migrated = checkpoint.Migrated
+			result.Duration = time.Since(start)
+			return result, err
+		}
+
+		minTradeDate := 0
+		if checkpoint.Phase == dailyMigrationPhaseCatchUp {
+			minTradeDate = checkpoint.CatchUpFrom
+		}
+		batch, err := source.GetLegacyDailyDataBatch(opts.SourceTable, checkpoint.LastTsCode, checkpoint.LastTradeDate,
+			minTradeDate, opts.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s after %s %d: %w",
+				opts.SourceTable, checkpoint.LastTsCode, checkpoint.LastTradeDate, err)
+		}
+
+		if len(batch) > 0 {
+			if err := commit(batch); err != nil {
+				return result, fmt.Errorf("failed to commit batch after %s %d: %w",
+					checkpoint.LastTsCode, checkpoint.LastTradeDate, err)
+			}
+			last := batch[len(batch)-1]
+			checkpoint.LastTsCode, checkpoint.LastTradeDate = last.TsCode, last.TradeDate
+			checkpoint.Migrated += int64(len(batch))
+			result.Batches++
+		}
+
+		// 读取到末尾时进入下一阶段，游标回到表头
+		if len(batch) < opts.BatchSize {
+			checkpoint.LastTsCode, checkpoint.LastTradeDate = "", 0
+			if checkpoint.Phase == dailyMigrationPhaseCopy && checkpoint.CatchUpFrom > 0 {
+				checkpoint.Phase = dailyMigrationPhaseCatchUp
+			} else {
+				checkpoint.Phase = dailyMigrationPhaseCompleted
+			}
+		}
+
+		if err := saveDailyMigrationCheckpoint(opts.CheckpointPath, checkpoint); err != nil {
+			return result, err
+		}
+		if opts.Progress != nil && len(batch) > 0 {
+			opts.Progress(DailyDataMigrationProgress{
+				Phase:         checkpoint.Phase,
+				Migrated:      checkpoint.Migrated,
+				LastTsCode:    batch[len(batch)-1].TsCode,
+				LastTradeDate: batch[len(batch)-1].TradeDate,
+				Elapsed:       time.Since(start),
+			})
+		}
+	}
+
+	result.Migrated = checkpoint.Migrated
+	result.Duration = time.Since(start)
+	logger.Infof("Daily data migration from %s finished: %d rows in %d batches, took %v",
+		opts.SourceTable, result.Migrated, result.Batches, result.Duration)
+	return result, nil
+}
+
+// loadDailyMigrationCheckpoint 读取迁移断点，路径为空或文件不存在时从表头开始，断点属于其他源表时返回错误
+func loadDailyMigrationCheckpoint(path, sourceTable string) (*dailyDataMigrationCheckpoint, bool, error) {
+	fresh := &dailyDataMigrationCheckpoint{SourceTable: sourceTable, Phase: dailyMigrationPhaseCopy}
+	if path == "" {
+		return fresh, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fresh, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read migration checkpoint: %w", err)
+	}
+
+	var checkpoint dailyDataMigrationCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, false, fmt.Errorf("failed to parse migration checkpoint %s: %w", path, err)
+	}
+	if checkpoint.SourceTable != sourceTable {
+		return nil, false, fmt.Errorf("migration checkpoint %s belongs to table %s, not %s",
+			path, checkpoint.SourceTable, sourceTable)
+	}
+	return &checkpoint, true, nil
+}
+
+// saveDailyMigrationCheckpoint 写入迁移断点
+func saveDailyMigrationCheckpoint(path string, checkpoint *dailyDataMigrationCheckpoint) error {
+	if path == "" {
+		return nil
+	}
+
+	checkpoint.UpdatedAt = time.Now()
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode migration checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write migration checkpoint: %w", err)
+	}
+	return nil
+}
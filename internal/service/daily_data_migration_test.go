@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLegacyDailySource 内存中的旧日K线表，按(ts_code, trade_date)排序后按游标分页
+type fakeLegacyDailySource struct {
+	rows []model.DailyData
+}
+
+func (f *fakeLegacyDailySource) insert(rows ...model.DailyData) {
+	f.rows = append(f.rows, rows...)
+	sort.Slice(f.rows, func(i, j int) bool {
+		if f.rows[i].TsCode != f.rows[j].TsCode {
+			return f.rows[i].TsCode < f.rows[j].TsCode
+		}
+		return f.rows[i].TradeDate < f.rows[j].TradeDate
+	})
+}
+
+func (f *fakeLegacyDailySource) GetLegacyDailyDataBatch(_ string, afterTsCode string, afterTradeDate, minTradeDate, limit int) ([]model.DailyData, error) {
+	batch := make([]model.DailyData, 0, limit)
+	for _, row := range f.rows {
+		if row.TsCode < afterTsCode || (row.TsCode == afterTsCode && row.TradeDate <= afterTradeDate) {
+			continue
+		}
+		if minTradeDate > 0 && row.TradeDate < minTradeDate {
+			continue
+		}
+		if batch = append(batch, row); len(batch) == limit {
+			break
+		}
+	}
+	return batch, nil
+}
+
+func (f *fakeLegacyDailySource) GetLegacyMaxTradeDate(string) (int, error) {
+	maxDate := 0
+	for _, row := range f.rows {
+		maxDate = max(maxDate, row.TradeDate)
+	}
+	return maxDate, nil
+}
+
+func TestRunDailyDataMigration_ResumeFromCheckpoint(t *testing.T) {
+	source := &fakeLegacyDailySource{}
+	for _, code := range []string{"000001.SZ", "000002.SZ", "600000.SH"} {
+		for _, date := range []int{20250926, 20250929, 20250930} {
+			source.insert(model.DailyData{TsCode: code, TradeDate: date, Close: 10})
+		}
+	}
+
+	checkpoint := filepath.Join(t.TempDir(), "migrate.json")
+	opts := DailyDataMigrationOptions{BatchSize: 2, CheckpointPath: checkpoint}
+	migrated := make(map[string]int)
+	key := func(row model.DailyData) string { return fmt.Sprintf("%s|%d", row.TsCode, row.TradeDate) }
+
+	// 第3批提交失败模拟中断，前两批已提交
+	commits := 0
+	_, err := runDailyDataMigration(context.Background(), source, func(batch []model.DailyData) error {
+		if commits++; commits == 3 {
+			return errors.New("connection lost")
+		}
+		for _, row := range batch {
+			migrated[key(row)]++
+		}
+		// 迁移期间旧表写入已复制过的股票的新交易日数据，位于游标之前
+		if commits == 2 {
+			source.insert(model.DailyData{TsCode: "000001.SZ", TradeDate: 20251009, Close: 11})
+		}
+		return nil
+	}, opts)
+	require.Error(t, err)
+	assert.Len(t, migrated, 4)
+
+	// 使用同一断点重新执行，从第4行之后继续，不重复复制已提交的批次
+	var batches [][]model.DailyData
+	result, err := runDailyDataMigration(context.Background(), source, func(batch []model.DailyData) error {
+		batches = append(batches, batch)
+		for _, row := range batch {
+			migrated[key(row)]++
+		}
+		return nil
+	}, opts)
+	require.NoError(t, err)
+	assert.True(t, result.Resumed)
+	assert.Equal(t, 20250930, result.CatchUpFrom)
+	require.NotEmpty(t, batches)
+	assert.Equal(t, model.DailyData{TsCode: "000002.SZ", TradeDate: 20250929, Close: 10}, batches[0][0])
+
+	// 全部行都已迁移，复制阶段的行只迁移一次，补迁移阶段重新写入不早于起始日期的行情，包括迁移期间新写入的行
+	assert.Len(t, migrated, 10)
+	assert.Equal(t, 1, migrated["000001.SZ|20251009"])
+	for _, code := range []string{"000001.SZ", "000002.SZ", "600000.SH"} {
+		assert.Equal(t, 1, migrated[key(model.DailyData{TsCode: code, TradeDate: 20250929})])
+		assert.Equal(t, 2, migrated[key(model.DailyData{TsCode: code, TradeDate: 20250930})])
+	}
+
+	// 已完成的断点再次执行不会重复迁移
+	result, err = runDailyDataMigration(context.Background(), source, func(batch []model.DailyData) error {
+		t.Fatalf("unexpected commit of %d rows", len(batch))
+		return nil
+	}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Batches)
+}
+
+func TestRunDailyDataMigration_RejectsInvalidOptions(t *testing.T) {
+	source := &fakeLegacyDailySource{}
+	noop := func([]model.DailyData) error { return nil }
+
+	_, err := runDailyDataMigration(context.Background(), source, noop, DailyDataMigrationOptions{SourceTable: "daily_data; DROP TABLE stocks"})
+	assert.Error(t, err)
+
+	_, err = runDailyDataMigration(context.Background(), source, noop, DailyDataMigrationOptions{BatchSize: maxDailyMigrationBatchSize + 1})
+	assert.Error(t, err)
+
+	// 断点属于其他源表时拒绝继续
+	checkpoint := filepath.Join(t.TempDir(), "migrate.json")
+	_, err = runDailyDataMigration(context.Background(), source, noop, DailyDataMigrationOptions{CheckpointPath: checkpoint})
+	require.NoError(t, err)
+	_, err = runDailyDataMigration(context.Background(), source, noop, DailyDataMigrationOptions{SourceTable: "daily_data_old", CheckpointPath: checkpoint})
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// DataQualityService 股票数据质量评分服务
+type DataQualityService struct {
+	qualityRepo   *repository.DataQuality
+	stockRepo     *repository.Stock
+	dailyDataRepo *repository.DailyData
+	now           func() time.Time
+}
+
+var (
+	dataQualityServiceInstance *DataQualityService
+	dataQualityServiceOnce     sync.Once
+)
+
+// GetDataQualityService 获取数据质量评分服务单例
+func GetDataQualityService(db *gorm.DB) *DataQualityService {
+	dataQualityServiceOnce.Do(func() {
+		dataQualityServiceInstance = &DataQualityService{
+			qualityRepo:   repository.NewDataQuality(db),
+			stockRepo:     repository.NewStock(db),
+			dailyDataRepo: repository.NewDailyData(db),
+			now:           time.Now,
+		}
+	})
+	return dataQualityServiceInstance
+}
+
+// NewDataQualityService 创建数据质量评分服务 (保持向后兼容)
+func NewDataQualityService(db *gorm.DB) *DataQualityService {
+	return GetDataQualityService(db)
+}
+
+// Evaluate 评估股票日K线的数据质量，不保存
+func (s *DataQualityService) Evaluate(stock model.Stock) (model.StockDataQuality, error) {
+	dailyData, err := s.dailyDataRepo.GetDailyData(stock.TsCode, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		return model.StockDataQuality{}, fmt.Errorf("failed to get daily data for %s: %w", stock.TsCode, err)
+	}
+	return model.EvaluateDataQuality(stock, dailyData, s.now()), nil
+}
+
+// EvaluateAllStocks 评估所有活跃股票的数据质量并保存，返回评估的股票数
+func (s *DataQualityService) EvaluateAllStocks() (int, error) {
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	qualities := make([]model.StockDataQuality, 0, len(stocks))
+	lowQuality := 0
+	for _, stock := range stocks {
+		quality, err := s.Evaluate(stock)
+		if err != nil {
+			logger.Warnf("Skip evaluating data quality of %s: %v", stock.TsCode, err)
+			continue
+		}
+		if quality.Score < 60 {
+			lowQuality++
+		}
+		qualities = append(qualities, quality)
+	}
+
+	if err := s.qualityRepo.Upsert(qualities); err != nil {
+		return 0, fmt.Errorf("failed to save data quality: %w", err)
+	}
+
+	logger.Infof("Evaluated data quality of %d stocks, %d below 60", len(qualities), lowQuality)
+	return len(qualities), nil
+}
+
+// GetDataQuality 获取股票已保存的数据质量评分，尚未评估时返回nil
+func (s *DataQualityService) GetDataQuality(tsCode string) (*model.StockDataQuality, error) {
+	quality, err := s.qualityRepo.GetByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data quality: %w", err)
+	}
+	return quality, nil
+}
@@ -1,17 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"stock/internal/collector"
 	"stock/internal/logger"
 	"stock/internal/model"
 	"stock/internal/repository"
+	"stock/internal/utils"
 
 	"gorm.io/gorm"
 )
@@ -25,7 +27,25 @@ type DataService struct {
 	weeklyDataRepo   *repository.WeeklyData
 	monthlyDataRepo  *repository.MonthlyData
 	yearlyDataRepo   *repository.YearlyData
+	identityRepo     *repository.StockIdentityChange
+	fundFlowRepo     *repository.FundFlow
+	snapshotRepo     *repository.RealtimeSnapshot
 	collectorFactory *collector.CollectorFactory
+
+	// writeLimit 同步K线时写数据库的并发限制，与采集并发分开控制，为nil时不限制
+	writeLimit atomic.Pointer[utils.Semaphore]
+
+	// realtimeSnapshot 同步实时行情时是否同时保存最新行情快照
+	realtimeSnapshot atomic.Bool
+
+	// chunkedHistory 跨年的日K线同步是否按自然年分段采集
+	chunkedHistory atomic.Bool
+
+	// localAggregation 周K、月K、年K线是否优先由已保存的日K线聚合，而不是从数据源获取
+	localAggregation atomic.Bool
+
+	// collectorManager 采集器管理器，设置后经管理器获取采集器，采集器熔断时改用配置的备用采集器，为nil时直接使用工厂
+	collectorManager atomic.Pointer[collector.CollectorManager]
 }
 
 var (
@@ -44,6 +64,9 @@ func GetDataService(db *gorm.DB, logger *logger.Logger) *DataService {
 			weeklyDataRepo:   repository.NewWeeklyData(db),
 			monthlyDataRepo:  repository.NewMonthlyData(db),
 			yearlyDataRepo:   repository.NewYearlyData(db),
+			identityRepo:     repository.NewStockIdentityChange(db),
+			fundFlowRepo:     repository.NewFundFlow(db),
+			snapshotRepo:     repository.NewRealtimeSnapshot(db),
 			collectorFactory: collector.GetCollectorFactory(logger),
 		}
 	})
@@ -55,6 +78,105 @@ func NewDataService(db *gorm.DB, logger *logger.Logger) *DataService {
 	return GetDataService(db, logger)
 }
 
+// SetWriteConcurrency 设置同步K线时最多同时写数据库的任务数，<=0表示不限制
+// 采集并发可以很高，写入集中在分表上容易耗尽连接池并产生锁竞争，因此写入单独限流
+func (s *DataService) SetWriteConcurrency(n int) {
+	s.writeLimit.Store(utils.NewSemaphore(n))
+}
+
+// SetRealtimeSnapshot 设置同步实时行情时是否保存最新行情快照，开启后实时行情接口可以直接返回最后一次采集的行情
+func (s *DataService) SetRealtimeSnapshot(enabled bool) {
+	s.realtimeSnapshot.Store(enabled)
+}
+
+// SetChunkedHistoryFetch 设置跨年的日K线同步是否按自然年分段采集
+// 开启后全量采集上市时间很长的股票时每次请求只返回一年的K线，中途失败时先保存已采集的年份，下次同步从最新一根K线继续
+func (s *DataService) SetChunkedHistoryFetch(enabled bool) {
+	s.chunkedHistory.Store(enabled)
+}
+
+// SetLocalKLineAggregation 设置周K、月K、年K线是否优先由已保存的日K线聚合
+// 开启后同步周期K线不再请求数据源，区间内没有已保存的日K线时（如新上市还未同步日K线）仍从数据源获取
+func (s *DataService) SetLocalKLineAggregation(enabled bool) {
+	s.localAggregation.Store(enabled)
+}
+
+// LocalKLineAggregation 周K、月K、年K线是否优先由已保存的日K线聚合
+func (s *DataService) LocalKLineAggregation() bool {
+	return s.localAggregation.Load()
+}
+
+// AggregatePeriodKLine 由已保存的日K线聚合tradeDate所在一期的周期K线，该期没有日K线时返回nil
+// 用于开启本地聚合时刷新当期K线，调用前应先同步日K线
+func (s *DataService) AggregatePeriodKLine(tsCode string, bucket model.KLineBucket, tradeDate int) (*model.DailyData, error) {
+	bars, err := s.aggregateStoredKLine(tsCode, bucket, bucket.StartDate(tradeDate), tradeDate)
+	if err != nil || len(bars) == 0 {
+		return nil, err
+	}
+	return &bars[len(bars)-1], nil
+}
+
+// aggregateStoredKLine 由已保存的日K线聚合[startDate, endDate]（YYYYMMDD格式）内的周期K线
+// 从startDate所在一期的第一天开始读取日K线，保证第一期完整
+func (s *DataService) aggregateStoredKLine(tsCode string, bucket model.KLineBucket, startDate, endDate int) ([]model.DailyData, error) {
+	var daily []model.DailyData
+	err := s.dailyDataRepo.EachDailyDataBatchInRange(tsCode, bucket.StartDate(startDate), endDate, 1000, func(batch []model.DailyData) error {
+		daily = append(daily, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取日K线数据失败: %v", err)
+	}
+	return model.NewKLineAggregator(bucket).Aggregate(daily), nil
+}
+
+// periodKLine 获取[startDate, endDate]内的周期K线
+// 开启本地聚合且区间内有已保存的日K线时由日K线聚合，否则创建同花顺采集器通过fetch从数据源获取
+func periodKLine[T model.WeeklyData | model.MonthlyData | model.YearlyData](s *DataService, tsCode string, bucket model.KLineBucket,
+	startDate, endDate time.Time, fetch func(c collector.DataCollector) ([]T, error)) ([]T, error) {
+	if s.localAggregation.Load() {
+		bars, err := s.aggregateStoredKLine(tsCode, bucket, utils.TradeDateOf(startDate), utils.TradeDateOf(endDate))
+		if err != nil {
+			return nil, err
+		}
+		if len(bars) > 0 {
+			s.logger.Debugf("股票 %s 由 %d 根已保存的日K线聚合周期K线", tsCode, len(bars))
+			result := make([]T, 0, len(bars))
+			for _, bar := range bars {
+				result = append(result, T(bar))
+			}
+			return result, nil
+		}
+	}
+
+	dataCollector, err := s.createCollector(collector.CollectorTypeTongHuaShun)
+	if err != nil {
+		return nil, fmt.Errorf("创建采集器失败: %v", err)
+	}
+
+	// 连接数据源
+	if err := dataCollector.Connect(); err != nil {
+		return nil, fmt.Errorf("连接数据源失败: %v", err)
+	}
+	defer dataCollector.Disconnect()
+
+	return fetch(dataCollector)
+}
+
+// SetCollectorManager 设置采集器管理器，K线、股票列表和实时行情同步经管理器获取采集器，采集器熔断时改用配置的备用采集器
+// 业绩报表、股东人数、股票详情等只有东方财富提供的数据不经过管理器，熔断时直接失败
+func (s *DataService) SetCollectorManager(m *collector.CollectorManager) {
+	s.collectorManager.Store(m)
+}
+
+// createCollector 获取采集器，设置了采集器管理器时经管理器获取以便熔断时故障切换
+func (s *DataService) createCollector(collectorType collector.CollectorType) (collector.DataCollector, error) {
+	if m := s.collectorManager.Load(); m != nil {
+		return m.GetCollector(string(collectorType))
+	}
+	return s.collectorFactory.CreateCollector(collectorType)
+}
+
 // GetDB 获取数据库连接
 func (s *DataService) GetDB() *gorm.DB {
 	return s.db
@@ -65,7 +187,7 @@ func (s *DataService) SyncStockList() error {
 	s.logger.Info("Starting stock list synchronization...")
 
 	// 创建采集器
-	collect, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
+	collect, err := s.createCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return fmt.Errorf("failed to create collector: %v", err)
 	}
@@ -84,6 +206,23 @@ func (s *DataService) SyncStockList() error {
 
 	s.logger.Infof("Fetched %d stocks", len(stocks))
 
+	// 更新前记录更名、长期不活跃后重新活跃等身份变更
+	codes := make([]string, len(stocks))
+	for i := range stocks {
+		codes[i] = stocks[i].TsCode
+	}
+	existing, err := s.stockRepo.GetStocksByTsCodes(codes)
+	if err != nil {
+		return fmt.Errorf("failed to get existing stocks: %v", err)
+	}
+	previous := make(map[string]*model.Stock, len(existing))
+	for i := range existing {
+		previous[existing[i].TsCode] = &existing[i]
+	}
+	for i := range stocks {
+		s.recordIdentityChanges(previous[stocks[i].TsCode], stocks[i])
+	}
+
 	// 批量更新或插入股票数据
 	if err := s.stockRepo.UpsertStocks(stocks); err != nil {
 		return fmt.Errorf("failed to upsert stocks: %v", err)
@@ -98,7 +237,7 @@ func (s *DataService) SyncStockActiveList() ([]*model.Stock, bool, error) {
 	logger.Info("Starting stock active list synchronization...")
 
 	// 创建采集器
-	collect, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
+	collect, err := s.createCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return nil, true, fmt.Errorf("failed to create collector: %v", err)
 	}
@@ -117,11 +256,13 @@ func (s *DataService) SyncStockActiveList() ([]*model.Stock, bool, error) {
 
 	//logger.Infof("Fetched %d stocks", len(stocks))
 
-	todayDate, _ := strconv.Atoi(time.Now().Format("20060102"))
+	todayDate := utils.TodayTradeDate()
 	dateCnt := 0 // 没有当日数据的股票数量
 	var codes []string
 	var res = make([]*model.Stock, 0, len(stocks))
 	var todayData = map[string]model.DailyData{}
+	var renamed []model.Stock             // 名称变化的股票，同步后一次写入
+	var statusChanges = map[string]bool{} // 上市状态变化的股票，同步后批量更新
 	for i, stock := range stocks {
 		today, name, err := collect.GetTodayData(stock.TsCode)
 		if err != nil {
@@ -135,22 +276,36 @@ func (s *DataService) SyncStockActiveList() ([]*model.Stock, bool, error) {
 			dateCnt++
 			continue
 		}
+		previous := *stock
 		stock.Name = name
+		s.recordIdentityChanges(&previous, *stock)
 		if strings.HasPrefix(name, "XD") { // 除权日清理所有k线数据
 			codes = append(codes, stock.TsCode)
-		} else if strings.HasPrefix(name, "PT") { // 退市
-			stock.IsActive = false
+		} else if model.ClassifyStockStatus(name, false) == model.StockStatusDelisted { // 退市
+			stock.SetStatus(model.StockStatusDelisted)
 		} else {
+			// 有当日行情说明正常交易，停牌的股票复牌后在这里恢复为上市状态
+			stock.SetStatus(model.StockStatusListed)
 			todayData[stock.TsCode] = *today
 		}
 		res = append(res, stock)
-		// 批量更新或插入股票数据
-		if err := s.stockRepo.UpsertStocks([]model.Stock{*stock}); err != nil {
-			logger.Errorf("failed to upsert stocks: %v", err)
-			continue
+		if stock.Name != previous.Name {
+			renamed = append(renamed, *stock)
+		}
+		if stock.IsActive != previous.IsActive || stock.Status != previous.Status {
+			statusChanges[stock.TsCode] = stock.IsActive
 		}
 	}
 
+	if err := s.stockRepo.UpsertStocks(renamed); err != nil {
+		logger.Errorf("failed to upsert renamed stocks: %v", err)
+	}
+	if updated, err := s.stockRepo.UpdateStockStatusBatch(statusChanges); err != nil {
+		logger.Errorf("failed to update stock status: %v", err)
+	} else if updated > 0 {
+		logger.Infof("Updated status for %d stocks", updated)
+	}
+
 	//for _, today := range todayData {
 	//	_ = s.dailyDataRepo.UpsertDailyData([]model.DailyData{today})
 	//}
@@ -167,6 +322,28 @@ func (s *DataService) SyncStockActiveList() ([]*model.Stock, bool, error) {
 	return res, true, nil
 }
 
+// recordIdentityChanges 检测并保存股票身份变更，需要复核的变更记录警告日志
+// 保存失败只记录日志，不影响股票同步
+func (s *DataService) recordIdentityChanges(previous *model.Stock, current model.Stock) {
+	changes := model.DetectIdentityChanges(previous, current, time.Now())
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, change := range changes {
+		if change.NeedsReview {
+			logger.Warnf("Stock %s identity changed (%s): %s -> %s, inactive %d days, K-line history needs review",
+				change.TsCode, change.ChangeType, change.OldName, change.NewName, change.InactiveDays)
+		} else {
+			logger.Infof("Stock %s renamed: %s -> %s", change.TsCode, change.OldName, change.NewName)
+		}
+	}
+
+	if err := s.identityRepo.Create(changes); err != nil {
+		logger.Errorf("Failed to record identity changes for %s: %v", current.TsCode, err)
+	}
+}
+
 // GetAllStocks 获取所有股票列表
 func (s *DataService) GetAllStocks() ([]*model.Stock, error) {
 	stocks, err := s.stockRepo.GetAllStocks()
@@ -182,6 +359,75 @@ func (s *DataService) GetAllStocks() ([]*model.Stock, error) {
 	return result, nil
 }
 
+// GetAllStocksPriorityFirst 获取所有股票列表，优先同步的股票排在前面
+func (s *DataService) GetAllStocksPriorityFirst() ([]*model.Stock, error) {
+	stocks, err := s.stockRepo.GetAllStocksPriorityFirst()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Stock, len(stocks))
+	for i := range stocks {
+		result[i] = &stocks[i]
+	}
+	return result, nil
+}
+
+// ErrEmptyStockUniverse 数据库中没有股票，通常是新部署后尚未同步股票列表
+var ErrEmptyStockUniverse = errors.New("stock universe empty — run stock-list sync first")
+
+// GetStockUniverse 获取批量采集任务的股票列表，优先同步的股票排在前面
+// 股票表为空时返回ErrEmptyStockUniverse，避免批量任务在零只股票上空跑并报告成功
+func (s *DataService) GetStockUniverse() ([]*model.Stock, error) {
+	stocks, err := s.GetAllStocksPriorityFirst()
+	if err != nil {
+		return nil, err
+	}
+	if len(stocks) == 0 {
+		return nil, ErrEmptyStockUniverse
+	}
+	return stocks, nil
+}
+
+// DailySyncCoverage 日K线同步的覆盖情况，统计股票池中有指定交易日K线的股票数
+type DailySyncCoverage struct {
+	TradeDate int // 交易日期，YYYYMMDD格式
+	Expected  int // 股票池中的股票数
+	Updated   int // 有该交易日K线的股票数
+}
+
+// Ratio 获取有该交易日K线的股票占比，股票池为空时返回1
+func (c DailySyncCoverage) Ratio() float64 {
+	if c.Expected <= 0 {
+		return 1
+	}
+	return float64(c.Updated) / float64(c.Expected)
+}
+
+// BelowThreshold 判断覆盖率是否低于threshold（0-1之间的比例），threshold<=0表示不检查
+func (c DailySyncCoverage) BelowThreshold(threshold float64) bool {
+	return threshold > 0 && c.Ratio() < threshold
+}
+
+// CheckDailySyncCoverage 统计股票池中有指定交易日日K线的股票数，用于批量同步后发现上游封禁、鉴权变化等导致的大面积静默失败
+func (s *DataService) CheckDailySyncCoverage(stocks []*model.Stock, tradeDate int) (DailySyncCoverage, error) {
+	tsCodes := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		tsCodes = append(tsCodes, stock.TsCode)
+	}
+
+	coverage := DailySyncCoverage{TradeDate: tradeDate, Expected: len(tsCodes)}
+	if len(tsCodes) == 0 {
+		return coverage, nil
+	}
+	updated, err := s.dailyDataRepo.CountByTradeDate(tsCodes, tradeDate)
+	if err != nil {
+		return coverage, fmt.Errorf("统计日K线更新数量失败: %w", err)
+	}
+	coverage.Updated = int(updated)
+	return coverage, nil
+}
+
 // UpdateStockStatus 更新股票状态
 func (s *DataService) UpdateStockStatus(tsCode string, isActive bool) error {
 	s.logger.Infof("更新股票 %s 状态为: %v", tsCode, isActive)
@@ -200,6 +446,99 @@ func (s *DataService) UpdateStockStatus(tsCode string, isActive bool) error {
 	return nil
 }
 
+// RefreshStockStatus 从东方财富查询股票的停牌状态并更新上市状态，同时刷新股本，股本变化时保存变动记录
+// 日K线长期未更新可能是长期停牌也可能是退市，以数据源的停牌标志为准，而不是按数据是否过期推断
+func (s *DataService) RefreshStockStatus(tsCode string) (model.StockStatus, error) {
+	detail, err := s.collectorFactory.GetEastMoneyCollector().GetStockDetail(tsCode)
+	if err != nil {
+		return "", fmt.Errorf("获取股票详情失败: %v", err)
+	}
+
+	status := detail.GetStatus()
+	updates := map[string]interface{}{
+		"status":    status,
+		"is_active": detail.IsActive,
+	}
+	// 股本随状态一起刷新，停牌或退市时数据源可能不返回，保留已有值
+	if detail.FloatShares > 0 {
+		updates["float_shares"] = detail.FloatShares
+	}
+	if detail.TotalShares > 0 {
+		updates["total_shares"] = detail.TotalShares
+	}
+	if detail.ListDate != nil {
+		updates["list_date"] = *detail.ListDate
+	}
+	result := s.db.Model(&model.Stock{}).Where("ts_code = ?", tsCode).Updates(updates)
+	if result.Error != nil {
+		return "", fmt.Errorf("更新股票上市状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", fmt.Errorf("未找到股票 %s", tsCode)
+	}
+	detail.TsCode = tsCode
+	if _, err := repository.NewStockShares(s.db).RecordChange(detail.SharesRecord(utils.TradeDateOf(utils.AppNow()))); err != nil {
+		s.logger.Warnf("保存股票 %s 股本变动记录失败: %v", tsCode, err)
+	}
+
+	s.logger.Infof("股票 %s 上市状态: %s", tsCode, status)
+	return status, nil
+}
+
+// ClampHistoryStart 将全量同步的起始日期限制在上市日期之后
+// 上市日期未知时先从东方财富个股详情补全并保存，补全失败时按原起始日期同步
+func (s *DataService) ClampHistoryStart(stock *model.Stock, startDate time.Time) time.Time {
+	if stock.ListDate == nil || stock.ListDate.IsZero() {
+		if err := s.fillListDate(stock, s.collectorFactory.GetEastMoneyCollector()); err != nil {
+			s.logger.Warnf("补全股票 %s 上市日期失败: %v", stock.TsCode, err)
+		}
+	}
+	return stock.ClampStartDate(startDate)
+}
+
+// fillListDate 从数据源获取股票详情，补全并保存上市日期，数据源未返回上市日期时不修改
+func (s *DataService) fillListDate(stock *model.Stock, source collector.DataCollector) error {
+	detail, err := source.GetStockDetail(stock.TsCode)
+	if err != nil {
+		return fmt.Errorf("获取股票详情失败: %v", err)
+	}
+	if detail.ListDate == nil || detail.ListDate.IsZero() {
+		return nil
+	}
+
+	if err := s.db.Model(&model.Stock{}).Where("ts_code = ?", stock.TsCode).Update("list_date", *detail.ListDate).Error; err != nil {
+		return fmt.Errorf("保存上市日期失败: %v", err)
+	}
+	listDate := *detail.ListDate
+	stock.ListDate = &listDate
+	s.logger.Infof("股票 %s 上市日期: %s", stock.TsCode, listDate.Format("2006-01-02"))
+	return nil
+}
+
+// SyncFundFlows 从东方财富股票列表一次抓取全市场当日资金流向并保存
+// 停牌股票当日无成交，资金流向全为0，不保存
+func (s *DataService) SyncFundFlows() (int, error) {
+	items, err := s.collectorFactory.GetEastMoneyCollector().GetStockListWithFlows()
+	if err != nil {
+		return 0, fmt.Errorf("获取资金流向失败: %v", err)
+	}
+
+	flows := make([]model.StockFundFlow, 0, len(items))
+	for _, item := range items {
+		if item.FundFlow.Close <= 0 {
+			continue
+		}
+		flows = append(flows, item.FundFlow)
+	}
+
+	if err := s.fundFlowRepo.Upsert(flows); err != nil {
+		return 0, fmt.Errorf("保存资金流向失败: %v", err)
+	}
+
+	s.logger.Infof("成功同步 %d 只股票的资金流向", len(flows))
+	return len(flows), nil
+}
+
 // UpdateALLStockStatus 更新所有股票状态
 func (s *DataService) UpdateALLStockStatus(isActive bool) error {
 	s.logger.Infof("更新所有股票状态为: %v", isActive)
@@ -224,7 +563,7 @@ func (s *DataService) SyncDailyData(tsCode string, startDate, endDate time.Time)
 		tsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
 	// 创建采集器
-	dataCollector, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
+	dataCollector, err := s.createCollector(collector.CollectorTypeTongHuaShun)
 	if err != nil {
 		return 0, fmt.Errorf("创建采集器失败: %v", err)
 	}
@@ -235,45 +574,59 @@ func (s *DataService) SyncDailyData(tsCode string, startDate, endDate time.Time)
 	}
 	defer dataCollector.Disconnect()
 
-	// 获取日K线数据
-	klineData, err := dataCollector.GetDailyKLine(tsCode, startDate, endDate)
-	if err != nil {
-		return 0, fmt.Errorf("获取日K线数据失败: %v", err)
+	// 获取日K线数据，分段采集中途失败时先保存已采集的部分
+	klineData, fetchErr := s.fetchDailyKLine(dataCollector, tsCode, startDate, endDate)
+	var chunkErr *collector.ChunkedFetchError
+	if fetchErr != nil && !errors.As(fetchErr, &chunkErr) {
+		return 0, fmt.Errorf("获取日K线数据失败: %v", fetchErr)
 	}
 
 	if len(klineData) == 0 {
+		if chunkErr != nil {
+			return 0, fmt.Errorf("获取日K线数据失败: %v", fetchErr)
+		}
 		s.logger.Debugf("股票 %s 在指定时间范围内没有日K线数据", tsCode)
 		return 0, nil
 	}
 
+	if err := checkKLineConsistency(s.logger, tsCode, "日", klineData); err != nil {
+		return 0, err
+	}
+
 	// 批量保存数据
-	if err := s.dailyDataRepo.UpsertDailyData(klineData); err != nil {
+	if err := s.writeLimit.Load().Do(func() error {
+		return s.dailyDataRepo.UpsertDailyData(klineData)
+	}); err != nil {
 		return 0, fmt.Errorf("保存日K线数据失败: %v", err)
 	}
 
+	if chunkErr != nil {
+		return len(klineData), fmt.Errorf("获取日K线数据失败，已保存 %s 之前的 %d 条记录: %v",
+			chunkErr.ResumeFrom.Format("2006-01-02"), len(klineData), fetchErr)
+	}
+
 	s.logger.Infof("成功同步股票 %s 的日K线数据，共 %d 条记录", tsCode, len(klineData))
 	return len(klineData), nil
 }
 
+// fetchDailyKLine 获取日K线数据，开启分段采集、区间跨年且采集器支持时按自然年分段采集
+func (s *DataService) fetchDailyKLine(dataCollector collector.DataCollector, tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
+	if chunked, ok := dataCollector.(collector.ChunkedDailyKLineCollector); ok && s.chunkedHistory.Load() && startDate.Year() < endDate.Year() {
+		return chunked.GetDailyKLineChunked(tsCode, startDate, endDate)
+	}
+	return dataCollector.GetDailyKLine(tsCode, startDate, endDate)
+}
+
 // SyncWeeklyData 同步周K线数据
 func (s *DataService) SyncWeeklyData(tsCode string, startDate, endDate time.Time) (int, error) {
 	s.logger.Infof("开始同步股票 %s 的周K线数据，时间范围: %s 到 %s",
 		tsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
-	// 创建东方财富采集器
-	eastMoney, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
-	if err != nil {
-		return 0, fmt.Errorf("创建采集器失败: %v", err)
-	}
-
-	// 连接数据源
-	if err := eastMoney.Connect(); err != nil {
-		return 0, fmt.Errorf("连接数据源失败: %v", err)
-	}
-	defer eastMoney.Disconnect()
-
-	// 获取周K线数据
-	klineData, err := eastMoney.GetWeeklyKLine(tsCode, startDate, endDate)
+	// 获取周K线数据，开启本地聚合时由已保存的日K线生成
+	klineData, err := periodKLine(s, tsCode, model.KLineBucketWeek, startDate, endDate,
+		func(c collector.DataCollector) ([]model.WeeklyData, error) {
+			return c.GetWeeklyKLine(tsCode, startDate, endDate)
+		})
 	if err != nil {
 		return 0, fmt.Errorf("获取周K线数据失败: %v", err)
 	}
@@ -283,22 +636,31 @@ func (s *DataService) SyncWeeklyData(tsCode string, startDate, endDate time.Time
 		return 0, nil
 	}
 
+	if err := checkKLineConsistency(s.logger, tsCode, "周", klineData); err != nil {
+		return 0, err
+	}
+
 	// 使用KLinePersistenceService保存周K线数据
 	klinePersistence := GetKLinePersistenceService(s.db, s.logger)
-	for _, data := range klineData {
-		weeklyData := model.WeeklyData{
-			TsCode:    data.TsCode,
-			TradeDate: data.TradeDate,
-			Open:      data.Open,
-			High:      data.High,
-			Low:       data.Low,
-			Close:     data.Close,
-			Volume:    data.Volume,
-			Amount:    data.Amount,
-		}
-		if err := klinePersistence.SaveWeeklyData(weeklyData); err != nil {
-			return 0, fmt.Errorf("保存周K线数据失败: %v", err)
+	if err := s.writeLimit.Load().Do(func() error {
+		for _, data := range klineData {
+			weeklyData := model.WeeklyData{
+				TsCode:    data.TsCode,
+				TradeDate: data.TradeDate,
+				Open:      data.Open,
+				High:      data.High,
+				Low:       data.Low,
+				Close:     data.Close,
+				Volume:    data.Volume,
+				Amount:    data.Amount,
+			}
+			if err := klinePersistence.SaveWeeklyData(weeklyData); err != nil {
+				return fmt.Errorf("保存周K线数据失败: %v", err)
+			}
 		}
+		return nil
+	}); err != nil {
+		return 0, err
 	}
 
 	s.logger.Infof("成功同步股票 %s 的周K线数据，共 %d 条记录", tsCode, len(klineData))
@@ -310,20 +672,11 @@ func (s *DataService) SyncMonthlyData(tsCode string, startDate, endDate time.Tim
 	s.logger.Infof("开始同步股票 %s 的月K线数据，时间范围: %s 到 %s",
 		tsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
-	// 创建东方财富采集器
-	eastMoney, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
-	if err != nil {
-		return 0, fmt.Errorf("创建采集器失败: %v", err)
-	}
-
-	// 连接数据源
-	if err := eastMoney.Connect(); err != nil {
-		return 0, fmt.Errorf("连接数据源失败: %v", err)
-	}
-	defer eastMoney.Disconnect()
-
-	// 获取月K线数据
-	klineData, err := eastMoney.GetMonthlyKLine(tsCode, startDate, endDate)
+	// 获取月K线数据，开启本地聚合时由已保存的日K线生成
+	klineData, err := periodKLine(s, tsCode, model.KLineBucketMonth, startDate, endDate,
+		func(c collector.DataCollector) ([]model.MonthlyData, error) {
+			return c.GetMonthlyKLine(tsCode, startDate, endDate)
+		})
 	if err != nil {
 		return 0, fmt.Errorf("获取月K线数据失败: %v", err)
 	}
@@ -333,22 +686,31 @@ func (s *DataService) SyncMonthlyData(tsCode string, startDate, endDate time.Tim
 		return 0, nil
 	}
 
+	if err := checkKLineConsistency(s.logger, tsCode, "月", klineData); err != nil {
+		return 0, err
+	}
+
 	// 使用KLinePersistenceService保存月K线数据
 	klinePersistence := GetKLinePersistenceService(s.db, s.logger)
-	for _, data := range klineData {
-		monthlyData := model.MonthlyData{
-			TsCode:    data.TsCode,
-			TradeDate: data.TradeDate,
-			Open:      data.Open,
-			High:      data.High,
-			Low:       data.Low,
-			Close:     data.Close,
-			Volume:    data.Volume,
-			Amount:    data.Amount,
-		}
-		if err := klinePersistence.SaveMonthlyData(monthlyData); err != nil {
-			return 0, fmt.Errorf("保存月K线数据失败: %v", err)
+	if err := s.writeLimit.Load().Do(func() error {
+		for _, data := range klineData {
+			monthlyData := model.MonthlyData{
+				TsCode:    data.TsCode,
+				TradeDate: data.TradeDate,
+				Open:      data.Open,
+				High:      data.High,
+				Low:       data.Low,
+				Close:     data.Close,
+				Volume:    data.Volume,
+				Amount:    data.Amount,
+			}
+			if err := klinePersistence.SaveMonthlyData(monthlyData); err != nil {
+				return fmt.Errorf("保存月K线数据失败: %v", err)
+			}
 		}
+		return nil
+	}); err != nil {
+		return 0, err
 	}
 
 	s.logger.Infof("成功同步股票 %s 的月K线数据，共 %d 条记录", tsCode, len(klineData))
@@ -360,20 +722,11 @@ func (s *DataService) SyncYearlyData(tsCode string, startDate, endDate time.Time
 	s.logger.Infof("开始同步股票 %s 的年K线数据，时间范围: %s 到 %s",
 		tsCode, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
-	// 创建东方财富采集器
-	dataCollector, err := s.collectorFactory.CreateCollector(collector.CollectorTypeTongHuaShun)
-	if err != nil {
-		return 0, fmt.Errorf("创建采集器失败: %v", err)
-	}
-
-	// 连接数据源
-	if err := dataCollector.Connect(); err != nil {
-		return 0, fmt.Errorf("连接数据源失败: %v", err)
-	}
-	defer dataCollector.Disconnect()
-
-	// 获取年K线数据
-	klineData, err := dataCollector.GetYearlyKLine(tsCode, startDate, endDate)
+	// 获取年K线数据，开启本地聚合时由已保存的日K线生成
+	klineData, err := periodKLine(s, tsCode, model.KLineBucketYear, startDate, endDate,
+		func(c collector.DataCollector) ([]model.YearlyData, error) {
+			return c.GetYearlyKLine(tsCode, startDate, endDate)
+		})
 	if err != nil {
 		return 0, fmt.Errorf("获取年K线数据失败: %v", err)
 	}
@@ -383,34 +736,43 @@ func (s *DataService) SyncYearlyData(tsCode string, startDate, endDate time.Time
 		return 0, nil
 	}
 
+	if err := checkKLineConsistency(s.logger, tsCode, "年", klineData); err != nil {
+		return 0, err
+	}
+
 	// 使用KLinePersistenceService保存年K线数据
 	klinePersistence := GetKLinePersistenceService(s.db, s.logger)
-	for _, data := range klineData {
-		yearlyData := model.YearlyData{
-			TsCode:    data.TsCode,
-			TradeDate: data.TradeDate,
-			Open:      data.Open,
-			High:      data.High,
-			Low:       data.Low,
-			Close:     data.Close,
-			Volume:    data.Volume,
-			Amount:    data.Amount,
-		}
-		if err := klinePersistence.SaveYearlyData(yearlyData); err != nil {
-			return 0, fmt.Errorf("保存年K线数据失败: %v", err)
+	if err := s.writeLimit.Load().Do(func() error {
+		for _, data := range klineData {
+			yearlyData := model.YearlyData{
+				TsCode:    data.TsCode,
+				TradeDate: data.TradeDate,
+				Open:      data.Open,
+				High:      data.High,
+				Low:       data.Low,
+				Close:     data.Close,
+				Volume:    data.Volume,
+				Amount:    data.Amount,
+			}
+			if err := klinePersistence.SaveYearlyData(yearlyData); err != nil {
+				return fmt.Errorf("保存年K线数据失败: %v", err)
+			}
 		}
+		return nil
+	}); err != nil {
+		return 0, err
 	}
 
 	s.logger.Infof("成功同步股票 %s 的年K线数据，共 %d 条记录", tsCode, len(klineData))
 	return len(klineData), nil
 }
 
-// SyncRealtimeData 同步实时数据
-func (s *DataService) SyncRealtimeData(tsCodes []string) error {
+// SyncRealtimeData 同步实时数据，ctx取消或超时时中止进行中的行情请求
+func (s *DataService) SyncRealtimeData(ctx context.Context, tsCodes []string) error {
 	s.logger.Infof("Starting realtime data synchronization for %d stocks", len(tsCodes))
 
 	// 创建东方财富采集器
-	eastMoney, err := s.collectorFactory.CreateCollector(collector.CollectorTypeEastMoney)
+	eastMoney, err := s.createCollector(collector.CollectorTypeEastMoney)
 	if err != nil {
 		return fmt.Errorf("failed to create EastMoney collector: %v", err)
 	}
@@ -421,41 +783,70 @@ func (s *DataService) SyncRealtimeData(tsCodes []string) error {
 	}
 	defer eastMoney.Disconnect()
 
-	// 获取实时数据
-	realtimeData, err := eastMoney.GetRealtimeData(tsCodes)
+	// 获取实时数据，采集器支持上下文时随ctx取消或超时
+	var realtimeData []model.DailyData
+	if c, ok := eastMoney.(collector.RealtimeContextCollector); ok {
+		realtimeData, err = c.GetRealtimeDataWithContext(ctx, tsCodes)
+	} else {
+		realtimeData, err = eastMoney.GetRealtimeData(tsCodes)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get realtime data: %v", err)
 	}
 
 	s.logger.Infof("Fetched realtime data for %d stocks", len(realtimeData))
 
+	if err := s.saveRealtimeData(realtimeData, time.Now()); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Successfully synchronized realtime data for %d stocks", len(realtimeData))
+	return nil
+}
+
+// saveRealtimeData 将实时行情写入当日日线，开启快照时同时覆盖各股票的最新行情快照
+func (s *DataService) saveRealtimeData(realtimeData []model.DailyData, fetchedAt time.Time) error {
 	// 批量更新或插入日线数据
 	if err := s.dailyDataRepo.UpsertDailyData(realtimeData); err != nil {
 		return fmt.Errorf("failed to upsert daily data: %v", err)
 	}
 
-	s.logger.Infof("Successfully synchronized realtime data for %d stocks", len(realtimeData))
+	if !s.realtimeSnapshot.Load() {
+		return nil
+	}
+	snapshots := make([]model.RealtimeSnapshot, len(realtimeData))
+	for i, data := range realtimeData {
+		snapshots[i] = model.NewRealtimeSnapshot(data, fetchedAt)
+	}
+	if err := s.snapshotRepo.Upsert(snapshots); err != nil {
+		return fmt.Errorf("failed to upsert realtime snapshots: %v", err)
+	}
 	return nil
 }
 
-// UpsertKLineData 更新K线数据
+// UpsertKLineData 更新K线数据，写入并发受SetWriteConcurrency限制
 func (s *DataService) UpsertKLineData(data interface{}) error {
 	switch v := data.(type) {
 	case []model.DailyData:
-		return s.dailyDataRepo.UpsertDailyData(v)
+		return s.writeLimit.Load().Do(func() error { return s.dailyDataRepo.UpsertDailyData(v) })
 	case []model.WeeklyData:
-		return s.weeklyDataRepo.UpsertWeeklyData(v)
+		return s.writeLimit.Load().Do(func() error { return s.weeklyDataRepo.UpsertWeeklyData(v) })
 	case []model.MonthlyData:
-		return s.monthlyDataRepo.UpsertMonthlyData(v)
+		return s.writeLimit.Load().Do(func() error { return s.monthlyDataRepo.UpsertMonthlyData(v) })
 	case []model.YearlyData:
-		return s.yearlyDataRepo.BatchUpsert(v)
+		return s.writeLimit.Load().Do(func() error { return s.yearlyDataRepo.BatchUpsert(v) })
 	default:
 		return errors.New("UpsertKLineData unknown type")
 	}
 }
 
+// realtimeBatchTimeout 实时行情单个批次的超时时间
+const realtimeBatchTimeout = 30 * time.Second
+
 // SyncAllRealtimeData 同步所有活跃股票的实时数据
-func (s *DataService) SyncAllRealtimeData() error {
+// 股票按batchSize分批，最多concurrency个批次并发请求，请求速率由采集器的限流器统一控制；
+// batchSize、concurrency<=0时分别使用100和4
+func (s *DataService) SyncAllRealtimeData(batchSize, concurrency int) error {
 	s.logger.Info("Starting full realtime data synchronization...")
 
 	// 获取所有活跃股票
@@ -470,25 +861,21 @@ func (s *DataService) SyncAllRealtimeData() error {
 		tsCodes[i] = stock.TsCode
 	}
 
-	// 分批同步实时数据（每批100只股票）
-	batchSize := 100
-	for i := 0; i < len(tsCodes); i += batchSize {
-		end := i + batchSize
-		if end > len(tsCodes) {
-			end = len(tsCodes)
-		}
-
-		batch := tsCodes[i:end]
-		if err := s.SyncRealtimeData(batch); err != nil {
-			s.logger.Errorf("Failed to sync batch %d-%d: %v", i, end, err)
-			continue // 继续处理下一批
-		}
-
-		// 避免请求过于频繁
-		time.Sleep(1 * time.Second)
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if concurrency <= 0 {
+		concurrency = 4
 	}
 
-	s.logger.Info("Completed full realtime data synchronization")
+	// 分批并发同步实时数据，单个批次失败不影响其他批次
+	stats := utils.ExecuteInBatches(context.Background(), tsCodes, batchSize, concurrency, realtimeBatchTimeout,
+		func(ctx context.Context, batch []string) error {
+			return s.SyncRealtimeData(ctx, batch)
+		})
+
+	s.logger.Infof("Completed full realtime data synchronization: %d batches, %d succeeded, %d failed, took %v",
+		stats.TotalTasks, stats.SuccessTasks, stats.FailedTasks, stats.EndTime.Sub(stats.StartTime))
 	return nil
 }
 
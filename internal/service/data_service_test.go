@@ -0,0 +1,303 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestDataService_WriteConcurrencyCap(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 每次写入停留一段时间，记录同时进行的写入数
+	var running, peak, writes atomic.Int32
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:slow_write", func(tx *gorm.DB) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		writes.Add(1)
+		running.Add(-1)
+	}))
+
+	s := &DataService{
+		db:             db,
+		dailyDataRepo:  repository.NewDailyData(db),
+		weeklyDataRepo: repository.NewWeeklyData(db),
+	}
+	s.SetWriteConcurrency(2)
+
+	// 模拟采集并发远大于写入并发的情况
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var data interface{} = []model.DailyData{{TsCode: "000001.SZ", TradeDate: 20250102 + i%5, Open: 10, High: 11, Low: 9, Close: 10.5}}
+			if i%2 == 1 {
+				data = []model.WeeklyData{{TsCode: "000001.SZ", TradeDate: 20250103, Open: 10, High: 11, Low: 9, Close: 10.5}}
+			}
+			assert.NoError(t, s.UpsertKLineData(data))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(16), writes.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestDataService_GetStockUniverse_Empty(t *testing.T) {
+	// DryRun模式下查询不返回任何行，相当于新部署后股票表为空
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	s := &DataService{db: db, stockRepo: repository.NewStock(db)}
+	stocks, err := s.GetStockUniverse()
+	assert.ErrorIs(t, err, ErrEmptyStockUniverse)
+	assert.Nil(t, stocks)
+	assert.Contains(t, err.Error(), "run stock-list sync first")
+}
+
+func TestDataService_SaveRealtimeDataSnapshot(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var snapshots []model.RealtimeSnapshot
+	var tables []string
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		tables = append(tables, tx.Statement.Table)
+		if batch, ok := tx.Statement.Dest.([]model.RealtimeSnapshot); ok {
+			snapshots = append(snapshots, batch...)
+		}
+	}))
+
+	s := &DataService{
+		db:            db,
+		dailyDataRepo: repository.NewDailyData(db),
+		snapshotRepo:  repository.NewRealtimeSnapshot(db),
+	}
+	data := []model.DailyData{{TsCode: "600519.SH", TradeDate: 20250910, Open: 1480, High: 1495.5, Low: 1475, Close: 1490.2}}
+	fetchedAt := time.Date(2025, 9, 10, 14, 55, 0, 0, time.UTC)
+
+	// 默认只写入日线
+	require.NoError(t, s.saveRealtimeData(data, fetchedAt))
+	assert.Empty(t, snapshots)
+	assert.NotContains(t, tables, "realtime_snapshots")
+
+	// 开启后同时覆盖最新行情快照
+	s.SetRealtimeSnapshot(true)
+	require.NoError(t, s.saveRealtimeData(data, fetchedAt))
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "600519.SH", snapshots[0].TsCode)
+	assert.Equal(t, 1490.2, snapshots[0].Close)
+	assert.Equal(t, fetchedAt, snapshots[0].FetchedAt)
+	assert.Contains(t, tables, "realtime_snapshots")
+}
+
+func TestDailySyncCoverage_BelowThreshold(t *testing.T) {
+	// 5000只股票中4400只更新，覆盖率88%，低于90%时告警
+	coverage := DailySyncCoverage{TradeDate: 20240611, Expected: 5000, Updated: 4400}
+	assert.InDelta(t, 0.88, coverage.Ratio(), 1e-9)
+	assert.True(t, coverage.BelowThreshold(0.9))
+	assert.False(t, coverage.BelowThreshold(0.85))
+
+	// 恰好达到阈值不告警，未配置阈值不检查
+	assert.False(t, DailySyncCoverage{Expected: 100, Updated: 90}.BelowThreshold(0.9))
+	assert.False(t, DailySyncCoverage{Expected: 100, Updated: 0}.BelowThreshold(0))
+
+	// 股票池为空时视为全部覆盖
+	assert.Equal(t, 1.0, DailySyncCoverage{}.Ratio())
+	assert.False(t, DailySyncCoverage{}.BelowThreshold(0.9))
+}
+
+func TestDataService_CheckDailySyncCoverage(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 每个分表返回1只已更新的股票，记录查询的分表
+	var mu sync.Mutex
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count", func(tx *gorm.DB) {
+		mu.Lock()
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		mu.Unlock()
+		if count, ok := tx.Statement.Dest.(*int64); ok {
+			*count = 1
+			tx.RowsAffected = 1
+		}
+	}))
+
+	s := &DataService{dailyDataRepo: repository.NewDailyData(db)}
+	stocks := []*model.Stock{{TsCode: "600519.SH"}, {TsCode: "600036.SH"}, {TsCode: "000001.SZ"}}
+	coverage, err := s.CheckDailySyncCoverage(stocks, 20240611)
+	require.NoError(t, err)
+	assert.Equal(t, DailySyncCoverage{TradeDate: 20240611, Expected: 3, Updated: 2}, coverage)
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.Contains(t, query, "trade_date = 20240611 AND ts_code IN")
+	}
+}
+
+func TestDataService_PeriodKLineLocalAggregation(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 已保存的日K线，20240102和20240103属于同一周
+	daily := []model.DailyData{
+		{TsCode: "600000.SH", TradeDate: 20240102, Open: 6.62, High: 6.64, Low: 6.53, Close: 6.55, Volume: 400, Amount: 2620},
+		{TsCode: "600000.SH", TradeDate: 20240103, Open: 6.55, High: 6.60, Low: 6.52, Close: 6.58, Volume: 300, Amount: 1974},
+		{TsCode: "600000.SH", TradeDate: 20240108, Open: 6.60, High: 6.70, Low: 6.58, Close: 6.68, Volume: 500, Amount: 3340},
+	}
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]model.DailyData); ok {
+			*dest = daily
+		}
+	}))
+
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	s := &DataService{db: db, logger: log, dailyDataRepo: repository.NewDailyData(db)}
+	s.SetLocalKLineAggregation(true)
+
+	loc := time.UTC
+	fetched := false
+	weekly, err := periodKLine(s, "600000.SH", model.KLineBucketWeek,
+		time.Date(2024, 1, 3, 0, 0, 0, 0, loc), time.Date(2024, 1, 10, 0, 0, 0, 0, loc),
+		func(c collector.DataCollector) ([]model.WeeklyData, error) {
+			fetched = true
+			return nil, nil
+		})
+	require.NoError(t, err)
+	assert.False(t, fetched, "本地有日K线时不请求数据源")
+	require.Len(t, weekly, 2)
+	assert.Equal(t, model.WeeklyData{TsCode: "600000.SH", TradeDate: 20240103, Open: 6.62, High: 6.64, Low: 6.52, Close: 6.58, Volume: 700, Amount: 4594}, weekly[0])
+	assert.Equal(t, 20240108, weekly[1].TradeDate)
+
+	// 从起始日期所在一周的周一开始读取，保证第一周完整
+	require.NotEmpty(t, queries)
+	assert.Contains(t, queries[0], "trade_date > 20240100")
+	assert.Contains(t, queries[0], "trade_date <= 20240110")
+
+	// 当期K线取所在一期的聚合结果
+	current, err := s.AggregatePeriodKLine("600000.SH", model.KLineBucketMonth, 20240110)
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, 20240108, current.TradeDate)
+	assert.Equal(t, int64(1200), current.Volume)
+}
+
+// fakeDetailCollector 只实现股票详情的数据源
+type fakeDetailCollector struct {
+	collector.DataCollector
+	detail *model.Stock
+	calls  int
+}
+
+func (f *fakeDetailCollector) GetStockDetail(tsCode string) (*model.Stock, error) {
+	f.calls++
+	return f.detail, nil
+}
+
+func TestDataService_FillListDateBoundsHistory(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var updates []string
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		updates = append(updates, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}))
+	s := &DataService{db: db, logger: logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})}
+
+	listDate := time.Date(2021, 12, 15, 0, 0, 0, 0, time.UTC)
+	source := &fakeDetailCollector{detail: &model.Stock{TsCode: "001319.SZ", ListDate: &listDate}}
+	stock := &model.Stock{TsCode: "001319.SZ"}
+	require.NoError(t, s.fillListDate(stock, source))
+	require.NotNil(t, stock.ListDate)
+	assert.Equal(t, listDate, *stock.ListDate)
+	require.Len(t, updates, 1)
+	assert.Contains(t, updates[0], "SET `list_date`='2021-12-15")
+	assert.Contains(t, updates[0], "WHERE ts_code = '001319.SZ'")
+
+	// 全量同步从上市日期开始，已知上市日期时不再请求详情
+	historyStart := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, listDate, s.ClampHistoryStart(stock, historyStart))
+	assert.Equal(t, 1, source.calls)
+	later := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, later, s.ClampHistoryStart(stock, later))
+
+	// 数据源没有上市日期时保持未知，不写数据库
+	updates = nil
+	unknown := &model.Stock{TsCode: "301589.SZ"}
+	require.NoError(t, s.fillListDate(unknown, &fakeDetailCollector{detail: &model.Stock{TsCode: "301589.SZ"}}))
+	assert.Nil(t, unknown.ListDate)
+	assert.Empty(t, updates)
+}
+
+// namedCollector 只有名称的已连接数据源
+type namedCollector struct {
+	collector.DataCollector
+	name string
+}
+
+func (c *namedCollector) GetName() string   { return c.name }
+func (c *namedCollector) IsConnected() bool { return true }
+
+func TestDataService_CreateCollectorFailover(t *testing.T) {
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	manager := collector.NewCollectorManager(log)
+	eastMoney := &namedCollector{name: "eastmoney"}
+	tongHuaShun := &namedCollector{name: "tonghuashun"}
+	require.NoError(t, manager.RegisterCollector(eastMoney))
+	require.NoError(t, manager.RegisterCollector(tongHuaShun))
+	breaker := collector.NewCircuitBreaker("tonghuashun", collector.CircuitBreakerConfig{FailureThreshold: 1})
+	manager.SetCircuitBreakers(map[string]*collector.CircuitBreaker{"tonghuashun": breaker},
+		map[string]string{"tonghuashun": "eastmoney"})
+
+	s := &DataService{logger: log}
+	s.SetCollectorManager(manager)
+
+	c, err := s.createCollector(collector.CollectorTypeTongHuaShun)
+	require.NoError(t, err)
+	assert.Same(t, tongHuaShun, c)
+
+	// 同花顺熔断后K线同步改用东方财富
+	breaker.Record(true)
+	c, err = s.createCollector(collector.CollectorTypeTongHuaShun)
+	require.NoError(t, err)
+	assert.Same(t, eastMoney, c)
+}
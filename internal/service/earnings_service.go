@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// EarningsService 业绩披露服务，按公告日期查询各股票披露的业绩报表
+type EarningsService struct {
+	performanceRepo *repository.Performance
+}
+
+var (
+	earningsServiceInstance *EarningsService
+	earningsServiceOnce     sync.Once
+)
+
+// GetEarningsService 获取业绩披露服务单例
+func GetEarningsService(db *gorm.DB) *EarningsService {
+	earningsServiceOnce.Do(func() {
+		earningsServiceInstance = &EarningsService{
+			performanceRepo: repository.NewPerformance(db),
+		}
+	})
+	return earningsServiceInstance
+}
+
+// NewEarningsService 创建业绩披露服务 (保持向后兼容)
+func NewEarningsService(db *gorm.DB) *EarningsService {
+	return GetEarningsService(db)
+}
+
+// ListAnnouncements 获取首次公告日期或最新公告日期在[from, to]之间的业绩报表，同一股票在区间内披露多期报表时各期分别返回
+// 每股收益或营业收入同比变动幅度达到threshold（单位：%）的报表标记为业绩大幅变动
+func (s *EarningsService) ListAnnouncements(from, to time.Time, threshold float64) ([]model.EarningsAnnouncement, error) {
+	rows, err := s.performanceRepo.GetByAnnouncementDateRange(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance reports announced between %s and %s: %w",
+			from.Format("20060102"), to.Format("20060102"), err)
+	}
+
+	// 加载上年同期报表，计算每股收益同比变动
+	keys := make([]repository.PerformanceKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, repository.PerformanceKey{TsCode: row.TsCode, ReportDate: model.PriorYearReportDate(row.ReportDate)})
+	}
+	priors, err := s.performanceRepo.GetByKeys(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prior year performance reports: %w", err)
+	}
+
+	announcements := make([]model.EarningsAnnouncement, 0, len(rows))
+	for i, row := range rows {
+		var prior *model.PerformanceReport
+		if report, ok := priors[keys[i]]; ok {
+			prior = &report
+		}
+		announcements = append(announcements, model.NewEarningsAnnouncement(row.Name, row.PerformanceReport, prior, threshold))
+	}
+	return announcements, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestEarningsService_ListAnnouncementsInRange(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	announced := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:seed", func(tx *gorm.DB) {
+		queries = append(queries, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		switch dest := tx.Statement.Dest.(type) {
+		case *[]repository.PerformanceWithStock:
+			*dest = append(*dest,
+				repository.PerformanceWithStock{Name: "贵州茅台", PerformanceReport: model.PerformanceReport{
+					TsCode: "600519.SH", ReportDate: 20250630, EPS: 1.5, RevenueYoY: 10, FirstAnnouncementDate: &announced, LatestAnnouncementDate: &announced}},
+				repository.PerformanceWithStock{Name: "平安银行", PerformanceReport: model.PerformanceReport{
+					TsCode: "000001.SZ", ReportDate: 20250630, EPS: 1.2, RevenueYoY: 5, FirstAnnouncementDate: &announced, LatestAnnouncementDate: &announced}})
+		case *[]model.PerformanceReport:
+			*dest = append(*dest, model.PerformanceReport{TsCode: "600519.SH", ReportDate: 20240630, EPS: 0.9})
+		}
+	}))
+
+	s := &EarningsService{performanceRepo: repository.NewPerformance(db)}
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	announcements, err := s.ListAnnouncements(from, to, model.DefaultEarningsSurpriseThreshold)
+	require.NoError(t, err)
+
+	// 区间包含结束日当天，按首次或最新公告日期过滤
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "(performance_reports.first_announcement_date >= '2025-07-01 00:00:00' AND performance_reports.first_announcement_date < '2025-08-01 00:00:00') OR "+
+		"(performance_reports.latest_announcement_date >= '2025-07-01 00:00:00' AND performance_reports.latest_announcement_date < '2025-08-01 00:00:00')")
+	assert.Contains(t, queries[1], "(ts_code, report_date) IN (('600519.SH',20240630),('000001.SZ',20240630))")
+
+	require.Len(t, announcements, 2)
+	assert.Equal(t, "600519.SH", announcements[0].TsCode)
+	assert.Equal(t, "贵州茅台", announcements[0].Name)
+	require.NotNil(t, announcements[0].EPSYoY)
+	assert.Equal(t, 66.67, *announcements[0].EPSYoY)
+	assert.True(t, announcements[0].Surprise)
+
+	// 没有上年同期报表
+	assert.Nil(t, announcements[1].EPSYoY)
+	assert.False(t, announcements[1].Surprise)
+}
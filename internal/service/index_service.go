@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/utils"
+)
+
+// IndexService 指数数据服务
+type IndexService struct {
+	indexRepo *repository.Index
+	repo      *repository.IndexDaily
+	collector collector.IndexCollector
+}
+
+var (
+	indexServiceInstance *IndexService
+	indexServiceOnce     sync.Once
+)
+
+// GetIndexService 获取指数数据服务单例
+func GetIndexService(indexRepo *repository.Index, repo *repository.IndexDaily, collector collector.IndexCollector,
+) *IndexService {
+	indexServiceOnce.Do(func() {
+		indexServiceInstance = &IndexService{
+			indexRepo: indexRepo,
+			repo:      repo,
+			collector: collector,
+		}
+	})
+	return indexServiceInstance
+}
+
+// NewIndexService 创建指数数据服务 (保持向后兼容)
+func NewIndexService(indexRepo *repository.Index, repo *repository.IndexDaily,
+	collector collector.IndexCollector) *IndexService {
+	return GetIndexService(indexRepo, repo, collector)
+}
+
+// defaultIndexHistoryStartDate 指数全量同步的默认起始日期（沪深300基日）
+var defaultIndexHistoryStartDate = time.Date(2004, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// EnsureDefaultIndices 确保默认跟踪的核心指数已登记
+func (s *IndexService) EnsureDefaultIndices() error {
+	indices := make([]model.Index, len(model.DefaultIndices))
+	copy(indices, model.DefaultIndices)
+	if err := s.indexRepo.UpsertIndices(indices); err != nil {
+		return fmt.Errorf("failed to save default indices: %w", err)
+	}
+	return nil
+}
+
+// GetAllIndices 获取所有需要采集的指数
+func (s *IndexService) GetAllIndices() ([]model.Index, error) {
+	indices, err := s.indexRepo.GetAllIndices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indices: %w", err)
+	}
+	return indices, nil
+}
+
+// UpdateIndexDaily 增量更新指数日线数据，从数据库最新交易日开始拉取（覆盖盘中写入的未收盘数据），返回更新条数
+func (s *IndexService) UpdateIndexDaily(tsCode string) (int, error) {
+	startDate := defaultIndexHistoryStartDate
+	latest, err := s.repo.GetLatestIndexDaily(tsCode)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query latest index daily data: %w", err)
+	}
+	if latest != nil {
+		if startDate, err = utils.ParseTradeDate(latest.TradeDate); err != nil {
+			return 0, err
+		}
+	}
+
+	data, err := s.SyncIndexDaily(tsCode, startDate, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// SyncAllIndexDaily 更新所有指数的日线数据，单个指数失败不影响其他指数
+func (s *IndexService) SyncAllIndexDaily() error {
+	if err := s.EnsureDefaultIndices(); err != nil {
+		return err
+	}
+
+	indices, err := s.GetAllIndices()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, index := range indices {
+		count, err := s.UpdateIndexDaily(index.TsCode)
+		if err != nil {
+			failed++
+			logger.Errorf("Failed to update index daily data for %s(%s): %v", index.TsCode, index.Name, err)
+			continue
+		}
+		logger.Infof("Updated %d index daily records for %s(%s)", count, index.TsCode, index.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d of %d indices", failed, len(indices))
+	}
+	return nil
+}
+
+// GetIndexDaily 获取指数日线数据，数据库中没有该区间的数据时从数据源拉取并保存
+func (s *IndexService) GetIndexDaily(tsCode string, startDate, endDate time.Time) ([]model.IndexDaily, error) {
+	data, err := s.repo.GetIndexDaily(tsCode, startDate, endDate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index daily data: %w", err)
+	}
+	if len(data) > 0 {
+		return data, nil
+	}
+
+	logger.Infof("No index daily data found in database for %s, fetching from collector", tsCode)
+	return s.SyncIndexDaily(tsCode, startDate, endDate)
+}
+
+// SyncIndexDaily 从数据源拉取指数日线数据并保存到数据库
+func (s *IndexService) SyncIndexDaily(tsCode string, startDate, endDate time.Time) ([]model.IndexDaily, error) {
+	if s.collector == nil {
+		return nil, fmt.Errorf("index collector is not available")
+	}
+
+	data, err := s.collector.GetIndexDailyKLine(tsCode, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index daily data for %s: %w", tsCode, err)
+	}
+
+	if err := s.repo.UpsertIndexDaily(data); err != nil {
+		logger.Warnf("Failed to save index daily data for %s: %v", tsCode, err)
+	}
+
+	return data, nil
+}
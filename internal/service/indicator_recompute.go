@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// 全量重算技术指标的默认参数
+const (
+	defaultRecomputeConcurrency = 10              // 默认并发数，只读写数据库，不请求数据源
+	recomputeCheckpointInterval = 50              // 每完成多少只股票记录一次断点
+	recomputeTaskTimeout        = 5 * time.Minute // 单只股票单个周期的计算超时
+)
+
+// IndicatorRecomputeOptions 全量重算技术指标的参数
+type IndicatorRecomputeOptions struct {
+	Periods        []model.TechnicalIndicatorPeriod          // 需要重算的周期
+	Concurrency    int                                       // 并发数，<=0时使用默认值
+	CheckpointPath string                                    // 断点文件路径，为空时不记录断点
+	Progress       func(progress IndicatorRecomputeProgress) // 每只股票处理完成后回调，可为nil
+}
+
+// IndicatorRecomputeProgress 全量重算进度
+type IndicatorRecomputeProgress struct {
+	Total   int           `json:"total"`   // 本次需要处理的股票数，不含断点中已完成的
+	Done    int           `json:"done"`    // 已处理的股票数，含失败
+	Failed  int           `json:"failed"`  // 计算或写入失败的股票数
+	Elapsed time.Duration `json:"elapsed"` // 已用时间
+	ETA     time.Duration `json:"eta"`     // 按当前速度估算的剩余时间
+}
+
+// IndicatorRecomputeResult 全量重算结果
+type IndicatorRecomputeResult struct {
+	Total       int           `json:"total"`        // 股票总数
+	Resumed     int           `json:"resumed"`      // 断点中已完成而跳过的股票数
+	Succeeded   int           `json:"succeeded"`    // 本次成功的股票数
+	Failed      int           `json:"failed"`       // 本次失败的股票数
+	FailedCodes []string      `json:"failed_codes"` // 失败的股票代码，重新执行时会再次计算
+	Duration    time.Duration `json:"duration"`     // 总耗时
+}
+
+// ParseIndicatorPeriods 解析需要重算的指标周期，all表示日、周、月、年全部周期
+func ParseIndicatorPeriods(value string) ([]model.TechnicalIndicatorPeriod, error) {
+	switch period := model.TechnicalIndicatorPeriod(strings.ToLower(strings.TrimSpace(value))); period {
+	case model.TechnicalIndicatorPeriodDaily, model.TechnicalIndicatorPeriodWeekly,
+		model.TechnicalIndicatorPeriodMonthly, model.TechnicalIndicatorPeriodYearly:
+		return []model.TechnicalIndicatorPeriod{period}, nil
+	case "all":
+		return []model.TechnicalIndicatorPeriod{
+			model.TechnicalIndicatorPeriodDaily, model.TechnicalIndicatorPeriodWeekly,
+			model.TechnicalIndicatorPeriodMonthly, model.TechnicalIndicatorPeriodYearly,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported indicator period %q, valid options: daily, weekly, monthly, yearly, all", value)
+	}
+}
+
+// RecomputeKDJByPeriod 按全部历史K线重新计算指定周期的KDJ指标并覆盖保存，返回保存的条数
+// 与CalculateKDJByPeriod的增量计算不同，不复用已保存的指标，用于修正指标算法或新增指标列后回填
+func (s *IndicatorService) RecomputeKDJByPeriod(stock model.Stock, period model.TechnicalIndicatorPeriod) (int, error) {
+	stocks, err := s.getIndStockList(stock, nil, period)
+	if err != nil {
+		return 0, err
+	}
+
+	inds := indicator.KDJ(stocks)
+	for _, ind := range inds {
+		ind.Period = period
+	}
+	if err := s.indicatorRepo.UpsertKdj(inds); err != nil {
+		return 0, err
+	}
+	return len(inds), nil
+}
+
+// RecomputeAllIndicators 为所有活跃股票重新计算指定周期的技术指标
+// 每完成一批股票记录断点，中断后使用同一断点文件重新执行会跳过已完成的股票
+func (s *IndicatorService) RecomputeAllIndicators(ctx context.Context, opts IndicatorRecomputeOptions) (*IndicatorRecomputeResult, error) {
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	return runIndicatorRecompute(ctx, stocks, func(stock model.Stock) error {
+		for _, period := range opts.Periods {
+			if _, err := s.RecomputeKDJByPeriod(stock, period); err != nil {
+				return fmt.Errorf("%s %s: %w", stock.TsCode, period, err)
+			}
+		}
+		return nil
+	}, opts)
+}
+
+// runIndicatorRecompute 执行全量重算，compute计算并保存单只股票所有周期的指标
+func runIndicatorRecompute(ctx context.Context, stocks []model.Stock, compute func(stock model.Stock) error,
+	opts IndicatorRecomputeOptions) (*IndicatorRecomputeResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultRecomputeConcurrency
+	}
+
+	completed, err := loadBackfillCheckpoint(opts.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]model.Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		if !completed[stock.TsCode] {
+			pending = append(pending, stock)
+		}
+	}
+
+	result := &IndicatorRecomputeResult{
+		Total:       len(stocks),
+		Resumed:     len(stocks) - len(pending),
+		FailedCodes: []string{},
+	}
+	if result.Resumed > 0 {
+		logger.Infof("Resuming indicator recompute from checkpoint, %d of %d stocks already completed", result.Resumed, len(stocks))
+	}
+
+	start := time.Now()
+	var (
+		mu        sync.Mutex
+		progress  = IndicatorRecomputeProgress{Total: len(pending)}
+		failed    = make(map[string]bool)
+		sinceSave int
+	)
+
+	executor := utils.NewConcurrentExecutor(opts.Concurrency, recomputeTaskTimeout*time.Duration(max(len(opts.Periods), 1)))
+	defer executor.Close()
+
+	tasks := make([]utils.Task, 0, len(pending))
+	for _, stock := range pending {
+		stock := stock
+		tasks = append(tasks, &utils.SimpleTask{
+			ID:          fmt.Sprintf("indicator-recompute-%s", stock.TsCode),
+			Description: fmt.Sprintf("重算股票 %s 的技术指标", stock.TsCode),
+			Func: func(ctx context.Context) error {
+				err := compute(stock)
+
+				mu.Lock()
+				defer mu.Unlock()
+				var saveErr error
+				if err != nil {
+					failed[stock.TsCode] = true
+					progress.Failed++
+				} else {
+					completed[stock.TsCode] = true
+					if sinceSave++; sinceSave >= recomputeCheckpointInterval {
+						saveErr = saveBackfillCheckpoint(opts.CheckpointPath, completed)
+						sinceSave = 0
+					}
+				}
+
+				progress.Done++
+				progress.Elapsed = time.Since(start)
+				progress.ETA = progress.Elapsed / time.Duration(progress.Done) * time.Duration(progress.Total-progress.Done)
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+
+				if err != nil {
+					return err
+				}
+				return saveErr
+			},
+		})
+	}
+
+	executor.ExecuteBatch(ctx, tasks)
+
+	mu.Lock()
+	if err := saveBackfillCheckpoint(opts.CheckpointPath, completed); err != nil {
+		logger.Errorf("Failed to save indicator recompute checkpoint: %v", err)
+	}
+	mu.Unlock()
+
+	for _, stock := range pending {
+		if failed[stock.TsCode] || !completed[stock.TsCode] {
+			result.FailedCodes = append(result.FailedCodes, stock.TsCode)
+		}
+	}
+	sort.Strings(result.FailedCodes)
+	result.Failed = len(result.FailedCodes)
+	result.Succeeded = len(pending) - result.Failed
+	result.Duration = time.Since(start)
+
+	logger.Infof("Indicator recompute finished: %d stocks, %d resumed, %d succeeded, %d failed in %v",
+		result.Total, result.Resumed, result.Succeeded, result.Failed, result.Duration)
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
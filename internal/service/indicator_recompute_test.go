@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIndicatorPeriods(t *testing.T) {
+	periods, err := ParseIndicatorPeriods("Daily")
+	require.NoError(t, err)
+	assert.Equal(t, []model.TechnicalIndicatorPeriod{model.TechnicalIndicatorPeriodDaily}, periods)
+
+	periods, err = ParseIndicatorPeriods("all")
+	require.NoError(t, err)
+	assert.Len(t, periods, 4)
+
+	_, err = ParseIndicatorPeriods("hourly")
+	assert.Error(t, err)
+}
+
+func TestRunIndicatorRecompute_ResumeFromCheckpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "recompute.json")
+	stocks := []model.Stock{{TsCode: "000001.SZ"}, {TsCode: "000002.SZ"}, {TsCode: "600000.SH"}, {TsCode: "600519.SH"}}
+
+	var (
+		mu       sync.Mutex
+		computed = make(map[string]int)
+		failing  = map[string]bool{"600000.SH": true}
+	)
+	compute := func(stock model.Stock) error {
+		mu.Lock()
+		defer mu.Unlock()
+		computed[stock.TsCode]++
+		if failing[stock.TsCode] {
+			return errors.New("bars unavailable")
+		}
+		return nil
+	}
+
+	var last IndicatorRecomputeProgress
+	result, err := runIndicatorRecompute(context.Background(), stocks, compute, IndicatorRecomputeOptions{
+		Concurrency:    2,
+		CheckpointPath: checkpoint,
+		Progress:       func(p IndicatorRecomputeProgress) { last = p },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, 0, result.Resumed)
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, []string{"600000.SH"}, result.FailedCodes)
+	assert.Equal(t, IndicatorRecomputeProgress{Total: 4, Done: 4, Failed: 1, Elapsed: last.Elapsed}, last)
+
+	// 重新执行只处理上次失败的股票
+	failing = map[string]bool{}
+	result, err = runIndicatorRecompute(context.Background(), stocks, compute, IndicatorRecomputeOptions{
+		CheckpointPath: checkpoint,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Resumed)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Empty(t, result.FailedCodes)
+	assert.Equal(t, map[string]int{"000001.SZ": 1, "000002.SZ": 1, "600000.SH": 2, "600519.SH": 1}, computed)
+
+	completed, err := loadBackfillCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Len(t, completed, 4)
+}
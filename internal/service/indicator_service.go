@@ -21,6 +21,7 @@ type IndicatorService struct {
 	weeklyRepo    *repository.WeeklyData
 	monthlyRepo   *repository.MonthlyData
 	yearlyRepo    *repository.YearlyData
+	exclusionRepo *repository.DataExclusion
 }
 
 var (
@@ -38,6 +39,7 @@ func GetIndicatorService(db *gorm.DB) *IndicatorService {
 			weeklyRepo:    repository.NewWeeklyData(db),
 			monthlyRepo:   repository.NewMonthlyData(db),
 			yearlyRepo:    repository.NewYearlyData(db),
+			exclusionRepo: repository.NewDataExclusion(db),
 		}
 	})
 	return indicatorServiceInstance
@@ -120,9 +122,16 @@ func (s *IndicatorService) getIndStockList(stock model.Stock, inds []*model.Tech
 	for _, ind := range inds {
 		indMap[ind.TradeDate] = ind
 	}
+
+	// 跳过已标记的问题数据区间，日K线在查询中过滤
+	exclusions, err := s.exclusionRepo.List(stock.TsCode, period)
+	if err != nil {
+		return nil, err
+	}
+
 	switch period {
 	case model.TechnicalIndicatorPeriodDaily:
-		list, err := s.dailyDataRepo.GetDailyData(stock.TsCode, start, time.Time{}, 0)
+		list, err := s.dailyDataRepo.GetAnalysisDailyData(stock.TsCode, start, time.Time{}, 0)
 		if err != nil {
 			return nil, err
 		}
@@ -145,6 +154,7 @@ func (s *IndicatorService) getIndStockList(stock model.Stock, inds []*model.Tech
 		if err != nil {
 			return nil, err
 		}
+		list = model.FilterExcluded(list, exclusions, func(d model.WeeklyData) int { return d.TradeDate })
 		for i := len(list) - 1; i >= 0; i-- {
 			v := list[i]
 			if ind, ok := indMap[v.TradeDate]; ok {
@@ -163,6 +173,7 @@ func (s *IndicatorService) getIndStockList(stock model.Stock, inds []*model.Tech
 		if err != nil {
 			return nil, err
 		}
+		list = model.FilterExcluded(list, exclusions, func(d model.MonthlyData) int { return d.TradeDate })
 		for i := len(list) - 1; i >= 0; i-- {
 			v := list[i]
 			if ind, ok := indMap[v.TradeDate]; ok {
@@ -181,6 +192,7 @@ func (s *IndicatorService) getIndStockList(stock model.Stock, inds []*model.Tech
 		if err != nil {
 			return nil, err
 		}
+		list = model.FilterExcluded(list, exclusions, func(d model.YearlyData) int { return d.TradeDate })
 		for i := len(list) - 1; i >= 0; i-- {
 			v := list[i]
 			if ind, ok := indMap[v.TradeDate]; ok {
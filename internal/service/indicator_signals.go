@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+
+	"stock/internal/model"
+)
+
+// GetLatestIndicatorDate 获取日线技术指标的最新交易日期，没有数据时返回0
+func (s *IndicatorService) GetLatestIndicatorDate() (int, error) {
+	return s.indicatorRepo.GetLatestTradeDate(model.TechnicalIndicatorPeriodDaily)
+}
+
+// GetMarketSignals 获取全市场在指定交易日出现任一信号的股票，按股票代码升序
+// 信号由已保存的日线技术指标判断，交叉类信号与该股票上一个有指标的交易日比较
+func (s *IndicatorService) GetMarketSignals(tradeDate int, signals []model.IndicatorSignal) ([]model.StockIndicatorSignal, error) {
+	period := model.TechnicalIndicatorPeriodDaily
+	current, err := s.indicatorRepo.GetByTradeDate(tradeDate, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indicators of %d: %w", tradeDate, err)
+	}
+	if len(current) == 0 {
+		return []model.StockIndicatorSignal{}, nil
+	}
+
+	symbols := make([]string, 0, len(current))
+	for _, ind := range current {
+		symbols = append(symbols, ind.Symbol)
+	}
+	prev, err := s.indicatorRepo.GetPrevBatch(symbols, tradeDate, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indicators before %d: %w", tradeDate, err)
+	}
+
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	return matchIndicatorSignals(stocks, current, prev, signals), nil
+}
+
+// matchIndicatorSignals 筛选出现任一信号的股票，指标表按不含交易所后缀的代码保存，通过股票列表换算为ts_code
+func matchIndicatorSignals(stocks []model.Stock, current []*model.TechnicalIndicator,
+	prev map[string]*model.TechnicalIndicator, signals []model.IndicatorSignal) []model.StockIndicatorSignal {
+	stockMap := make(map[string]model.Stock, len(stocks))
+	for _, stock := range stocks {
+		stockMap[stock.Symbol] = stock
+	}
+
+	result := make([]model.StockIndicatorSignal, 0)
+	for _, ind := range current {
+		matched := make([]model.IndicatorSignal, 0, len(signals))
+		for _, signal := range signals {
+			if signal.Detect(prev[ind.Symbol], ind) {
+				matched = append(matched, signal)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		stock, ok := stockMap[ind.Symbol]
+		if !ok {
+			stock = model.Stock{TsCode: ind.Symbol}
+		}
+		result = append(result, model.StockIndicatorSignal{
+			TsCode:     stock.TsCode,
+			Name:       stock.Name,
+			TradeDate:  ind.TradeDate,
+			Signals:    matched,
+			Indicators: ind,
+		})
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchIndicatorSignals(t *testing.T) {
+	stocks := []model.Stock{
+		{TsCode: "000001.SZ", Symbol: "000001", Name: "平安银行"},
+		{TsCode: "600519.SH", Symbol: "600519", Name: "贵州茅台"},
+	}
+	current := []*model.TechnicalIndicator{
+		// MACD金叉
+		{Symbol: "000001", TradeDate: 20250930, MacdDif: 0.12, MacdDea: 0.10, Rsi6: 55},
+		// DIF一直在DEA之上，不是金叉
+		{Symbol: "600519", TradeDate: 20250930, MacdDif: 1.5, MacdDea: 1.2, Rsi6: 75},
+		// 股票列表中没有的代码，没有上一交易日指标
+		{Symbol: "830799", TradeDate: 20250930, MacdDif: 0.3, MacdDea: 0.1, Rsi6: 25},
+	}
+	prev := map[string]*model.TechnicalIndicator{
+		"000001": {Symbol: "000001", TradeDate: 20250929, MacdDif: 0.08, MacdDea: 0.09},
+		"600519": {Symbol: "600519", TradeDate: 20250929, MacdDif: 1.4, MacdDea: 1.1},
+	}
+
+	result := matchIndicatorSignals(stocks, current, prev, []model.IndicatorSignal{model.SignalGoldenCross})
+	require.Len(t, result, 1)
+	assert.Equal(t, "000001.SZ", result[0].TsCode)
+	assert.Equal(t, "平安银行", result[0].Name)
+	assert.Equal(t, 20250930, result[0].TradeDate)
+	assert.Equal(t, []model.IndicatorSignal{model.SignalGoldenCross}, result[0].Signals)
+
+	// 多个信号类型时返回出现任一信号的股票
+	result = matchIndicatorSignals(stocks, current, prev,
+		[]model.IndicatorSignal{model.SignalRSIOverbought, model.SignalRSIOversold})
+	require.Len(t, result, 2)
+	assert.Equal(t, "600519.SH", result[0].TsCode)
+	assert.Equal(t, []model.IndicatorSignal{model.SignalRSIOverbought}, result[0].Signals)
+	assert.Equal(t, "830799", result[1].TsCode)
+	assert.Equal(t, []model.IndicatorSignal{model.SignalRSIOversold}, result[1].Signals)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/notification"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// JobRunService 采集任务运行记录服务
+type JobRunService struct {
+	repo *repository.JobRun
+}
+
+var (
+	jobRunServiceInstance *JobRunService
+	jobRunServiceOnce     sync.Once
+)
+
+// GetJobRunService 获取采集任务运行记录服务单例
+func GetJobRunService(db *gorm.DB) *JobRunService {
+	jobRunServiceOnce.Do(func() {
+		jobRunServiceInstance = &JobRunService{
+			repo: repository.NewJobRun(db),
+		}
+	})
+	return jobRunServiceInstance
+}
+
+// NewJobRunService 创建采集任务运行记录服务 (保持向后兼容)
+func NewJobRunService(db *gorm.DB) *JobRunService {
+	return GetJobRunService(db)
+}
+
+// Record 按任务完成后的统计保存一次运行记录，startedAt为任务开始时间
+func (s *JobRunService) Record(summary notification.JobSummary, startedAt time.Time) (*model.JobRun, error) {
+	run := &model.JobRun{
+		Job:               summary.Job,
+		StartedAt:         startedAt,
+		FinishedAt:        startedAt.Add(summary.Duration),
+		Total:             summary.Total,
+		Success:           summary.Success,
+		Failed:            summary.Failed,
+		DurationMs:        summary.Duration.Milliseconds(),
+		AverageDurationMs: summary.AverageDuration.Milliseconds(),
+	}
+	if err := s.repo.Create(run); err != nil {
+		return nil, fmt.Errorf("failed to record %s run: %w", summary.Job, err)
+	}
+	return run, nil
+}
+
+// List 按开始时间倒序获取运行记录，job为空时获取所有任务
+func (s *JobRunService) List(job string, limit int) ([]model.JobRun, error) {
+	return s.repo.List(job, limit)
+}
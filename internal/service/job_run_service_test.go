@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/notification"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestJobRunService_RecordsRun(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var sqls []string
+	var recorded []model.JobRun
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+		if run, ok := tx.Statement.Dest.(*model.JobRun); ok {
+			recorded = append(recorded, *run)
+		}
+	}))
+
+	s := &JobRunService{repo: repository.NewJobRun(db)}
+	startedAt := time.Date(2025, 10, 10, 15, 30, 0, 0, time.UTC)
+	run, err := s.Record(notification.JobSummary{
+		Job:             notification.JobWeeklyKLine,
+		Total:           5000,
+		Success:         4990,
+		Failed:          10,
+		Duration:        12*time.Minute + 30*time.Second,
+		AverageDuration: 150 * time.Millisecond,
+	}, startedAt)
+	require.NoError(t, err)
+
+	require.Len(t, sqls, 1)
+	assert.Contains(t, sqls[0], "INSERT INTO `job_runs`")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, *run, recorded[0])
+	assert.Equal(t, "weekly_kline", run.Job)
+	assert.Equal(t, startedAt, run.StartedAt)
+	assert.Equal(t, startedAt.Add(12*time.Minute+30*time.Second), run.FinishedAt)
+	assert.Equal(t, 5000, run.Total)
+	assert.Equal(t, 4990, run.Success)
+	assert.Equal(t, 10, run.Failed)
+	assert.Equal(t, int64(750000), run.DurationMs)
+	assert.Equal(t, int64(150), run.AverageDurationMs)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// checkKLineConsistency 检查采集到的K线：价格不自洽的K线，日K线还检查相邻K线之间的数据缺口
+// 宽松模式下只记录告警并照常保存；严格模式下返回包装utils.ErrDataInconsistency的错误，中止本次同步
+func checkKLineConsistency[T model.OHLCBar](log *logger.Logger, tsCode, period string, data []T) error {
+	for _, invalid := range model.FindInvalidBars(data) {
+		msg := fmt.Sprintf("股票 %s 的%sK线数据不自洽: %v", tsCode, period, invalid)
+		if err := utils.StrictViolation(msg); err != nil {
+			return err
+		}
+		log.Warnf("%s", msg)
+	}
+
+	daily, ok := any(data).([]model.DailyData)
+	if !ok {
+		return nil
+	}
+	for _, gap := range model.FindDailyGaps(daily) {
+		msg := fmt.Sprintf("股票 %s 的日K线数据在 %d 到 %d 之间缺失 %d 个工作日",
+			tsCode, gap.From, gap.To, gap.MissingWeekday)
+		if err := utils.StrictViolation(msg); err != nil {
+			return err
+		}
+		log.Warnf("%s", msg)
+	}
+	return nil
+}
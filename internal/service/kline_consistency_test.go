@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckKLineConsistency_BadBar(t *testing.T) {
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	// 最高价低于收盘价
+	data := []model.DailyData{
+		{TsCode: "000001.SZ", TradeDate: 20250929, Open: 10.2, High: 10.8, Low: 10.1, Close: 10.5},
+		{TsCode: "000001.SZ", TradeDate: 20250930, Open: 10.5, High: 10.4, Low: 10.3, Close: 10.6},
+	}
+	t.Cleanup(func() { utils.SetStrictMode(false) })
+
+	t.Run("lenient", func(t *testing.T) {
+		utils.SetStrictMode(false)
+		assert.NoError(t, checkKLineConsistency(log, "000001.SZ", "日", data))
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		utils.SetStrictMode(true)
+		err := checkKLineConsistency(log, "000001.SZ", "日", data)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, utils.ErrDataInconsistency), err)
+		assert.Contains(t, err.Error(), "20250930")
+
+		assert.NoError(t, checkKLineConsistency(log, "000001.SZ", "日", data[:1]))
+	})
+}
+
+func TestCheckKLineConsistency_DailyGap(t *testing.T) {
+	log := logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"})
+	// 两根日K线之间缺失一个多月的工作日
+	data := []model.DailyData{
+		{TradeDate: 20250801, Open: 10, High: 10.5, Low: 9.8, Close: 10.2},
+		{TradeDate: 20250915, Open: 10.2, High: 10.6, Low: 10, Close: 10.4},
+	}
+	weekly := []model.WeeklyData{
+		{TradeDate: 20250801, Open: 10, High: 10.5, Low: 9.8, Close: 10.2},
+		{TradeDate: 20250915, Open: 10.2, High: 10.6, Low: 10, Close: 10.4},
+	}
+	t.Cleanup(func() { utils.SetStrictMode(false) })
+
+	utils.SetStrictMode(false)
+	assert.NoError(t, checkKLineConsistency(log, "000001.SZ", "日", data))
+
+	utils.SetStrictMode(true)
+	err := checkKLineConsistency(log, "000001.SZ", "日", data)
+	assert.True(t, errors.Is(err, utils.ErrDataInconsistency), err)
+	// 缺口只针对日K线
+	assert.NoError(t, checkKLineConsistency(log, "000001.SZ", "周", weekly))
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seededKLineTables 在DryRun连接上模拟K线分表：删除语句按WHERE条件从内存中的交易日期序列移除
+type seededKLineTables map[string]map[string][]int // 表名 -> 股票代码 -> 交易日期
+
+// newSeededKLineService 创建使用模拟K线分表的持久化服务
+func newSeededKLineService(t *testing.T, tables seededKLineTables) *KLinePersistenceService {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:apply", func(tx *gorm.DB) {
+		where, ok := tx.Statement.Clauses["WHERE"].Expression.(clause.Where)
+		require.True(t, ok, "删除语句必须带条件")
+		for tsCode, dates := range tables[tx.Statement.Table] {
+			var kept []int
+			for _, date := range dates {
+				if matchesWhere(t, where, tsCode, date) {
+					tx.RowsAffected++
+				} else {
+					kept = append(kept, date)
+				}
+			}
+			tables[tx.Statement.Table][tsCode] = kept
+		}
+	}))
+
+	return &KLinePersistenceService{
+		db:            db,
+		dailyDataRepo: repository.NewDailyData(db),
+		weeklyRepo:    repository.NewWeeklyData(db),
+		monthlyRepo:   repository.NewMonthlyData(db),
+		yearlyRepo:    repository.NewYearlyData(db),
+	}
+}
+
+// matchesWhere 判断一根K线是否满足删除条件，条件只有ts_code和trade_date的比较
+func matchesWhere(t *testing.T, where clause.Where, tsCode string, date int) bool {
+	for _, e := range where.Exprs {
+		expr, ok := e.(clause.Expr)
+		require.True(t, ok)
+		fields := strings.Fields(expr.SQL)
+		require.Len(t, fields, 3, expr.SQL)
+		switch fields[0] {
+		case "ts_code":
+			if expr.Vars[0] != tsCode {
+				return false
+			}
+		case "trade_date":
+			v := expr.Vars[0].(int)
+			match := map[string]bool{"=": date == v, ">=": date >= v, "<=": date <= v}[fields[1]]
+			if !match {
+				return false
+			}
+		default:
+			t.Fatalf("unexpected condition %s", expr.SQL)
+		}
+	}
+	return true
+}
+
+func TestKLinePersistenceService_DeleteData(t *testing.T) {
+	series := []int{20240102, 20240103, 20240104, 20240105, 20240108}
+	tables := seededKLineTables{
+		"daily_data_600": {"600000.SH": series, "600036.SH": series},
+		"yearly_data":    {"600000.SH": {20221230, 20231229, 20240108}},
+	}
+	s := newSeededKLineService(t, tables)
+	loc := time.UTC
+
+	// 单根K线
+	require.NoError(t, s.DeleteData("600000.SH", time.Date(2024, 1, 3, 0, 0, 0, 0, loc), "daily"))
+	assert.Equal(t, []int{20240102, 20240104, 20240105, 20240108}, tables["daily_data_600"]["600000.SH"])
+
+	// 闭区间
+	deleted, err := s.DeleteDataRange("600000.SH", time.Date(2024, 1, 4, 0, 0, 0, 0, loc), time.Date(2024, 1, 5, 0, 0, 0, 0, loc), "daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.Equal(t, []int{20240102, 20240108}, tables["daily_data_600"]["600000.SH"])
+
+	// 只限制起始日期，删除之后的全部K线；同一分表的其他股票不受影响
+	deleted, err = s.DeleteDataRange("600000.SH", time.Date(2024, 1, 8, 0, 0, 0, 0, loc), time.Time{}, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, []int{20240102}, tables["daily_data_600"]["600000.SH"])
+	assert.Equal(t, series, tables["daily_data_600"]["600036.SH"])
+
+	// 只限制结束日期，年K线不分表
+	deleted, err = s.DeleteDataRange("600000.SH", time.Time{}, time.Date(2023, 12, 31, 0, 0, 0, 0, loc), "yearly")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.Equal(t, []int{20240108}, tables["yearly_data"]["600000.SH"])
+
+	// 全部数据
+	deleted, err = s.DeleteAllData("600036.SH", "daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+	assert.Empty(t, tables["daily_data_600"]["600036.SH"])
+	assert.Equal(t, []int{20240102}, tables["daily_data_600"]["600000.SH"])
+
+	_, err = s.DeleteAllData("600036.SH", "hourly")
+	assert.Error(t, err)
+}
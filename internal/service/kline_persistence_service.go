@@ -177,3 +177,32 @@ func (s *KLinePersistenceService) DeleteData(tsCode string, tradeDate time.Time,
 		return fmt.Errorf("unsupported data type: %s", dataType)
 	}
 }
+
+// DeleteDataRange 删除股票交易日期在[startDate, endDate]内的K线数据，返回删除的行数
+// startDate或endDate为零值时该端不限制，按股票代码路由到对应的分表
+func (s *KLinePersistenceService) DeleteDataRange(tsCode string, startDate, endDate time.Time, dataType string) (int64, error) {
+	var start, end int
+	if !startDate.IsZero() {
+		start = dateToInt(startDate)
+	}
+	if !endDate.IsZero() {
+		end = dateToInt(endDate)
+	}
+	switch dataType {
+	case "daily":
+		return s.dailyDataRepo.DeleteRange(tsCode, start, end)
+	case "weekly":
+		return s.weeklyRepo.DeleteRange(tsCode, start, end)
+	case "monthly":
+		return s.monthlyRepo.DeleteRange(tsCode, start, end)
+	case "yearly":
+		return s.yearlyRepo.DeleteRange(tsCode, start, end)
+	default:
+		return 0, fmt.Errorf("unsupported data type: %s", dataType)
+	}
+}
+
+// DeleteAllData 删除股票某个周期的全部K线数据，返回删除的行数
+func (s *KLinePersistenceService) DeleteAllData(tsCode string, dataType string) (int64, error) {
+	return s.DeleteDataRange(tsCode, time.Time{}, time.Time{}, dataType)
+}
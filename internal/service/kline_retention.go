@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stock/internal/config"
+)
+
+// MinKLineRetentionYears K线最短保留年限
+// 技术指标中周期最长的MA250需要约一年的预热数据，保留两年才能在保留期内完整计算最近一年的指标
+const MinKLineRetentionYears = 2
+
+// KLinePruneResult 各周期K线清理的行数
+type KLinePruneResult struct {
+	Daily   int64 `json:"daily"`
+	Weekly  int64 `json:"weekly"`
+	Monthly int64 `json:"monthly"`
+	Yearly  int64 `json:"yearly"`
+}
+
+// KLineRetentionCutoff 计算保留years年时的清理截止日期（YYYYMMDD格式），早于该日期的K线会被删除
+// years<=0表示永久保留，返回0；不足MinKLineRetentionYears时按MinKLineRetentionYears计算
+func KLineRetentionCutoff(years int, now time.Time) int {
+	if years <= 0 {
+		return 0
+	}
+	if years < MinKLineRetentionYears {
+		years = MinKLineRetentionYears
+	}
+	cutoff := now.AddDate(-years, 0, 0)
+	return cutoff.Year()*10000 + int(cutoff.Month())*100 + cutoff.Day()
+}
+
+// PruneKLineHistory 按各周期的保留年限删除过期的K线数据
+func (s *DataService) PruneKLineHistory(retention config.KLineRetentionConfig, now time.Time) (*KLinePruneResult, error) {
+	result := &KLinePruneResult{}
+	periods := []struct {
+		name  string
+		years int
+		prune func(before int) (int64, error)
+		count *int64
+	}{
+		{"日K线", retention.DailyYears, s.dailyDataRepo.PruneBefore, &result.Daily},
+		{"周K线", retention.WeeklyYears, s.weeklyDataRepo.PruneBefore, &result.Weekly},
+		{"月K线", retention.MonthlyYears, s.monthlyDataRepo.PruneBefore, &result.Monthly},
+		{"年K线", retention.YearlyYears, s.yearlyDataRepo.PruneBefore, &result.Yearly},
+	}
+
+	for _, period := range periods {
+		cutoff := KLineRetentionCutoff(period.years, now)
+		if cutoff == 0 {
+			continue
+		}
+		deleted, err := period.prune(cutoff)
+		*period.count += deleted
+		if err != nil {
+			return result, fmt.Errorf("清理%s失败: %v", period.name, err)
+		}
+		s.logger.Infof("清理%s %d 条，截止日期: %d", period.name, deleted, cutoff)
+	}
+
+	return result, nil
+}
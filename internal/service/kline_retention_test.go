@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/config"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// capturedDelete 记录DryRun模式下生成的删除语句
+type capturedDelete struct {
+	table  string
+	sql    string
+	before interface{}
+}
+
+func TestKLineRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, 10, 14, 3, 0, 0, 0, time.Local)
+
+	assert.Equal(t, 0, KLineRetentionCutoff(0, now))
+	assert.Equal(t, 0, KLineRetentionCutoff(-1, now))
+	assert.Equal(t, 20161014, KLineRetentionCutoff(10, now))
+	// 不足最短保留年限时按最短年限计算，保证指标预热数据
+	assert.Equal(t, 20241014, KLineRetentionCutoff(1, now))
+}
+
+func TestDataService_PruneKLineHistoryRespectsPerPeriodCaps(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var deletes []capturedDelete
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", func(tx *gorm.DB) {
+		require.Len(t, tx.Statement.Vars, 1)
+		deletes = append(deletes, capturedDelete{
+			table:  tx.Statement.Table,
+			sql:    tx.Statement.SQL.String(),
+			before: tx.Statement.Vars[0],
+		})
+	}))
+
+	s := &DataService{
+		db:              db,
+		logger:          logger.NewLogger(logger.LogConfig{Level: "error", Format: "text"}),
+		dailyDataRepo:   repository.NewDailyData(db),
+		weeklyDataRepo:  repository.NewWeeklyData(db),
+		monthlyDataRepo: repository.NewMonthlyData(db),
+		yearlyDataRepo:  repository.NewYearlyData(db),
+	}
+
+	now := time.Date(2026, 10, 14, 3, 0, 0, 0, time.Local)
+	_, err = s.PruneKLineHistory(config.KLineRetentionConfig{DailyYears: 10, WeeklyYears: 20}, now)
+	require.NoError(t, err)
+
+	dailyTables := model.KLineShardTables(model.DailyDataTablePrefix)
+	weeklyTables := model.KLineShardTables(model.WeeklyDataTablePrefix)
+	require.Len(t, deletes, len(dailyTables)+len(weeklyTables))
+
+	byTable := make(map[string]capturedDelete, len(deletes))
+	for _, d := range deletes {
+		byTable[d.table] = d
+		// 只删除早于截止日期的K线，截止日期及之后的数据保留
+		assert.Contains(t, d.sql, "WHERE trade_date < ?")
+		assert.NotContains(t, d.sql, "ts_code")
+	}
+	for _, table := range dailyTables {
+		require.Contains(t, byTable, table)
+		assert.Equal(t, 20161014, byTable[table].before)
+	}
+	for _, table := range weeklyTables {
+		require.Contains(t, byTable, table)
+		assert.Equal(t, 20061014, byTable[table].before)
+	}
+
+	// 月K线和年K线永久保留，不生成删除语句
+	for _, table := range append(model.KLineShardTables(model.MonthlyDataTablePrefix), model.YearlyData{}.TableName()) {
+		assert.NotContains(t, byTable, table)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -19,6 +20,7 @@ type KLineService struct {
 	logger           *logrus.Logger
 	collectorManager *collector.CollectorManager
 	dailyDataRepo    *repository.DailyData
+	stockRepo        *repository.Stock
 }
 
 var (
@@ -34,6 +36,7 @@ func GetKLineService(db *gorm.DB, log *logrus.Logger, collectorManager *collecto
 			logger:           log,
 			collectorManager: collectorManager,
 			dailyDataRepo:    repository.NewDailyData(db),
+			stockRepo:        repository.NewStock(db),
 		}
 	})
 	return klineServiceInstance
@@ -74,6 +77,114 @@ func (s *KLineService) GetKLineData(tsCode string, startDate, endDate time.Time)
 	return dailyData, nil
 }
 
+// DetectPriceAnomalies 检查数据库中股票的全部日K线，找出超过板块涨跌幅限制的价格跳变
+// 存在跳变说明除权除息后历史数据未重新复权，需要调用RefreshKLineData重新采集全部历史
+func (s *KLineService) DetectPriceAnomalies(tsCode string) ([]model.PriceAnomaly, error) {
+	stock, err := s.stockRepo.GetStockByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock %s: %w", tsCode, err)
+	}
+	if stock == nil {
+		// 股票信息缺失时只能按代码判断板块，无法识别ST股票和上市初期
+		stock = &model.Stock{TsCode: tsCode}
+	}
+
+	dailyData, err := s.dailyDataRepo.GetDailyData(tsCode, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily data for %s: %w", tsCode, err)
+	}
+
+	anomalies := model.FindPriceAnomalies(*stock, dailyData)
+	if len(anomalies) > 0 {
+		s.logger.Warnf("Found %d price anomalies in daily data of %s, latest at %d, data may need re-fetching with adjustment",
+			len(anomalies), tsCode, anomalies[len(anomalies)-1].TradeDate)
+	}
+	return anomalies, nil
+}
+
+// limitDaysLookbackDays 查询涨跌停时在区间起点之前多取的自然日数，用于获得区间首日的上一交易日收盘价
+const limitDaysLookbackDays = 30
+
+// GetLimitDays 获取股票在日期区间内收于涨停价或跌停价的交易日，按交易日期升序返回
+func (s *KLineService) GetLimitDays(tsCode string, startDate, endDate time.Time) ([]model.LimitDay, error) {
+	stock, err := s.stockRepo.GetStockByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock %s: %w", tsCode, err)
+	}
+	if stock == nil {
+		// 股票信息缺失时只能按代码判断板块，无法识别ST股票和上市初期
+		stock = &model.Stock{TsCode: tsCode}
+	}
+
+	dailyData, err := s.dailyDataRepo.GetDailyData(tsCode, startDate.AddDate(0, 0, -limitDaysLookbackDays), endDate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily data for %s: %w", tsCode, err)
+	}
+
+	start := dateToInt(startDate)
+	days := make([]model.LimitDay, 0)
+	for _, day := range model.FindLimitDays(*stock, dailyData) {
+		if day.TradeDate >= start {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// GetMarketLimitDays 获取全市场在指定交易日收于涨停价（或跌停价）的股票，按股票代码升序返回
+// 上一交易日收盘价取该日之前最近一根K线，没有上一根K线的新股不参与判断
+func (s *KLineService) GetMarketLimitDays(tradeDate time.Time, limitType model.LimitType) ([]model.StockLimitDay, error) {
+	date := dateToInt(tradeDate)
+	bars, err := s.dailyDataRepo.GetDailyDataByDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily data of %d: %w", date, err)
+	}
+	if len(bars) == 0 {
+		return []model.StockLimitDay{}, nil
+	}
+
+	tsCodes := make([]string, 0, len(bars))
+	for _, bar := range bars {
+		tsCodes = append(tsCodes, bar.TsCode)
+	}
+
+	prevBars, err := s.dailyDataRepo.GetPrevDailyDataBatch(tsCodes, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous daily data before %d: %w", date, err)
+	}
+
+	stocks, err := s.stockRepo.GetStocksByTsCodes(tsCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+	stockMap := make(map[string]model.Stock, len(stocks))
+	for _, stock := range stocks {
+		stockMap[stock.TsCode] = stock
+	}
+
+	result := make([]model.StockLimitDay, 0)
+	for _, bar := range bars {
+		prev, ok := prevBars[bar.TsCode]
+		if !ok {
+			continue
+		}
+		stock, ok := stockMap[bar.TsCode]
+		if !ok {
+			stock = model.Stock{TsCode: bar.TsCode}
+		}
+
+		day, ok := model.DetectLimitDay(stock, prev.Close, bar)
+		if !ok || day.Type != limitType {
+			continue
+		}
+		result = append(result, model.StockLimitDay{TsCode: bar.TsCode, Name: stock.Name, LimitDay: day})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].TsCode < result[j].TsCode })
+	s.logger.Infof("Found %d stocks at %s on %d", len(result), limitType, date)
+	return result, nil
+}
+
 // RefreshKLineData 从API刷新K线数据并保存到数据库
 func (s *KLineService) RefreshKLineData(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
 	s.logger.Infof("Refreshing daily data from API for %s", tsCode)
@@ -98,6 +209,15 @@ func (s *KLineService) RefreshKLineData(tsCode string, startDate, endDate time.T
 	return apiData, nil
 }
 
+// GetTradingDays 获取股票在日期区间内有日K线数据的交易日期（YYYYMMDD格式），按日期升序返回
+func (s *KLineService) GetTradingDays(tsCode string, startDate, endDate time.Time) ([]int, error) {
+	dates, err := s.dailyDataRepo.GetTradeDates(tsCode, dateToInt(startDate), dateToInt(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading days for %s: %w", tsCode, err)
+	}
+	return dates, nil
+}
+
 // GetDataRange 获取数据库中K线数据的时间范围和数量
 func (s *KLineService) GetDataRange(tsCode string) (startDate, endDate time.Time, count int64, err error) {
 	// 获取数据数量
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// MarketMetricService 全市场指标分布服务
+type MarketMetricService struct {
+	performanceRepo *repository.Performance
+	shareholderRepo *repository.Shareholder
+}
+
+var (
+	marketMetricServiceInstance *MarketMetricService
+	marketMetricServiceOnce     sync.Once
+)
+
+// GetMarketMetricService 获取全市场指标分布服务单例
+func GetMarketMetricService(db *gorm.DB) *MarketMetricService {
+	marketMetricServiceOnce.Do(func() {
+		marketMetricServiceInstance = &MarketMetricService{
+			performanceRepo: repository.NewPerformance(db),
+			shareholderRepo: repository.NewShareholder(db),
+		}
+	})
+	return marketMetricServiceInstance
+}
+
+// NewMarketMetricService 创建全市场指标分布服务 (保持向后兼容)
+func NewMarketMetricService(db *gorm.DB) *MarketMetricService {
+	return GetMarketMetricService(db)
+}
+
+// GetLatestValues 获取全市场各股票最新一期的指标值，键为股票代码，没有数据或指标值未知的股票不在结果中
+func (s *MarketMetricService) GetLatestValues(metric model.MarketMetric) (map[string]float64, error) {
+	if metric == model.MetricHolderNum {
+		counts, err := s.shareholderRepo.GetLatestBatch(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest shareholder counts: %w", err)
+		}
+		values := make(map[string]float64, len(counts))
+		for tsCode, count := range counts {
+			if count.HolderNum > 0 {
+				values[tsCode] = float64(count.HolderNum)
+			}
+		}
+		return values, nil
+	}
+
+	reports, err := s.performanceRepo.GetLatestBatch(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest performance reports: %w", err)
+	}
+	values := make(map[string]float64, len(reports))
+	for tsCode, report := range reports {
+		if value, ok := metric.FromPerformance(report); ok {
+			values[tsCode] = value
+		}
+	}
+	return values, nil
+}
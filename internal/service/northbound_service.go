@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"stock/internal/collector"
+	"stock/internal/model"
+	"stock/internal/repository"
+)
+
+// NorthboundService 北向资金持股服务
+type NorthboundService struct {
+	repo      *repository.Northbound
+	collector collector.NorthboundCollector
+}
+
+var (
+	northboundServiceInstance *NorthboundService
+	northboundServiceOnce     sync.Once
+)
+
+// GetNorthboundService 获取北向资金持股服务单例
+func GetNorthboundService(repo *repository.Northbound, collector collector.NorthboundCollector) *NorthboundService {
+	northboundServiceOnce.Do(func() {
+		northboundServiceInstance = &NorthboundService{
+			repo:      repo,
+			collector: collector,
+		}
+	})
+	return northboundServiceInstance
+}
+
+// NewNorthboundService 创建北向资金持股服务实例 (保持向后兼容)
+func NewNorthboundService(repo *repository.Northbound, collector collector.NorthboundCollector) *NorthboundService {
+	return GetNorthboundService(repo, collector)
+}
+
+// SyncNorthboundHoldings 同步单只股票的北向持股，返回保存的记录数；非沪深港通标的没有数据，返回0
+// ctx取消或超时时中止请求
+func (s *NorthboundService) SyncNorthboundHoldings(ctx context.Context, tsCode string) (int, error) {
+	holdings, err := s.collector.GetNorthboundHoldingsWithContext(ctx, tsCode)
+	if err != nil {
+		return 0, fmt.Errorf("获取北向持股数据失败: %v", err)
+	}
+
+	if err := s.repo.Upsert(holdings); err != nil {
+		return 0, fmt.Errorf("保存北向持股数据失败: %v", err)
+	}
+	return len(holdings), nil
+}
+
+// GetNorthboundHoldings 获取股票在日期范围内的北向持股，按持股日期升序
+func (s *NorthboundService) GetNorthboundHoldings(tsCode string, startDate, endDate int) ([]model.NorthboundHolding, error) {
+	return s.repo.GetByTsCode(tsCode, startDate, endDate)
+}
+
+// GetLatestNorthboundHoldings 批量获取股票最新一期北向持股，tsCodes为nil时获取所有股票，键为股票代码
+func (s *NorthboundService) GetLatestNorthboundHoldings(tsCodes []string) (map[string]model.NorthboundHolding, error) {
+	return s.repo.GetLatestBatch(tsCodes)
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// 全量回填业绩报表的默认参数
+const (
+	defaultBackfillConcurrency = 5               // 默认并发数
+	defaultBackfillRateLimit   = 5               // 默认每秒最多请求数，与数据中心接口的限流一致
+	defaultBackfillBatchSize   = 500             // 默认每批写入的报表条数
+	backfillTaskTimeout        = 2 * time.Minute // 单只股票的采集超时
+)
+
+// PerformanceBackfillOptions 全量回填业绩报表的参数
+type PerformanceBackfillOptions struct {
+	Concurrency    int                                        // 并发数，<=0时使用默认值
+	RateLimit      float64                                    // 每秒最多请求数，<=0时使用默认值
+	BatchSize      int                                        // 每批写入的报表条数，<=0时使用默认值
+	CheckpointPath string                                     // 断点文件路径，为空时不记录断点
+	Progress       func(progress PerformanceBackfillProgress) // 每只股票处理完成后回调，可为nil
+}
+
+// PerformanceBackfillProgress 全量回填进度
+type PerformanceBackfillProgress struct {
+	Total   int           `json:"total"`   // 本次需要处理的股票数，不含断点中已完成的
+	Done    int           `json:"done"`    // 已处理的股票数，含失败
+	Failed  int           `json:"failed"`  // 采集或写入失败的股票数
+	Elapsed time.Duration `json:"elapsed"` // 已用时间
+	ETA     time.Duration `json:"eta"`     // 按当前速度估算的剩余时间
+}
+
+// PerformanceBackfillResult 全量回填结果
+type PerformanceBackfillResult struct {
+	Total       int           `json:"total"`        // 股票总数
+	Resumed     int           `json:"resumed"`      // 断点中已完成而跳过的股票数
+	Succeeded   int           `json:"succeeded"`    // 本次成功的股票数
+	Failed      int           `json:"failed"`       // 本次失败的股票数
+	Reports     int           `json:"reports"`      // 本次写入的报表条数
+	FailedCodes []string      `json:"failed_codes"` // 失败的股票代码，重新执行时会再次采集
+	Duration    time.Duration `json:"duration"`     // 总耗时
+}
+
+// performanceBackfillCheckpoint 断点文件内容，记录已完成写入的股票
+type performanceBackfillCheckpoint struct {
+	Completed []string  `json:"completed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BackfillAllPerformanceReports 为所有股票全量回填业绩报表，用于从零构建基本面选股所需的数据
+// 与每日配额采集不同，该操作一次处理全部股票：按数据源限流并发采集、分批写入，
+// 每批写入后记录断点，中断后使用同一断点文件重新执行会跳过已完成的股票
+func (s *PerformanceService) BackfillAllPerformanceReports(ctx context.Context, opts PerformanceBackfillOptions) (*PerformanceBackfillResult, error) {
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	codes := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		codes = append(codes, stock.TsCode)
+	}
+
+	return runPerformanceBackfill(ctx, codes, s.collector.GetPerformanceReports, s.repo.UpsertBatch, opts)
+}
+
+// runPerformanceBackfill 执行全量回填，fetch采集单只股票的报表，save批量写入报表
+func runPerformanceBackfill(ctx context.Context, codes []string,
+	fetch func(tsCode string) ([]model.PerformanceReport, error),
+	save func(reports []model.PerformanceReport) error,
+	opts PerformanceBackfillOptions) (*PerformanceBackfillResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBackfillConcurrency
+	}
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = defaultBackfillRateLimit
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBackfillBatchSize
+	}
+
+	completed, err := loadBackfillCheckpoint(opts.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if !completed[code] {
+			pending = append(pending, code)
+		}
+	}
+
+	result := &PerformanceBackfillResult{
+		Total:       len(codes),
+		Resumed:     len(codes) - len(pending),
+		FailedCodes: []string{},
+	}
+	if result.Resumed > 0 {
+		logger.Infof("Resuming performance backfill from checkpoint, %d of %d stocks already completed", result.Resumed, len(codes))
+	}
+
+	start := time.Now()
+	var (
+		mu           sync.Mutex
+		buffer       []model.PerformanceReport
+		bufferCodes  []string
+		progress     = PerformanceBackfillProgress{Total: len(pending)}
+		failedByCode = make(map[string]bool)
+	)
+
+	// flushLocked 写入缓冲的报表并记录断点，调用方需持有锁
+	flushLocked := func() error {
+		if len(bufferCodes) == 0 {
+			return nil
+		}
+		if err := save(buffer); err != nil {
+			for _, code := range bufferCodes {
+				failedByCode[code] = true
+			}
+			buffer, bufferCodes = nil, nil
+			return fmt.Errorf("failed to save performance reports: %w", err)
+		}
+
+		result.Reports += len(buffer)
+		for _, code := range bufferCodes {
+			completed[code] = true
+		}
+		buffer, bufferCodes = nil, nil
+		return saveBackfillCheckpoint(opts.CheckpointPath, completed)
+	}
+
+	// reportLocked 更新进度并回调，调用方需持有锁
+	reportLocked := func(failed bool) {
+		progress.Done++
+		if failed {
+			progress.Failed++
+		}
+		progress.Elapsed = time.Since(start)
+		progress.ETA = progress.Elapsed / time.Duration(progress.Done) * time.Duration(progress.Total-progress.Done)
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	executor := utils.NewConcurrentExecutor(opts.Concurrency, backfillTaskTimeout)
+	executor.SetRateLimit(opts.RateLimit)
+	defer executor.Close()
+
+	tasks := make([]utils.Task, 0, len(pending))
+	for _, code := range pending {
+		tsCode := code
+		tasks = append(tasks, &utils.SimpleTask{
+			ID:          fmt.Sprintf("performance-backfill-%s", tsCode),
+			Description: fmt.Sprintf("回填股票 %s 的业绩报表", tsCode),
+			Func: func(ctx context.Context) error {
+				reports, err := fetch(tsCode)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failedByCode[tsCode] = true
+					reportLocked(true)
+					return err
+				}
+
+				buffer = append(buffer, reports...)
+				bufferCodes = append(bufferCodes, tsCode)
+				var flushErr error
+				if len(buffer) >= opts.BatchSize {
+					flushErr = flushLocked()
+				}
+				reportLocked(false)
+				return flushErr
+			},
+		})
+	}
+
+	executor.ExecuteBatch(ctx, tasks)
+
+	mu.Lock()
+	flushErr := flushLocked()
+	mu.Unlock()
+	if flushErr != nil {
+		logger.Errorf("Failed to flush performance backfill: %v", flushErr)
+	}
+
+	for _, code := range pending {
+		if failedByCode[code] || !completed[code] {
+			result.FailedCodes = append(result.FailedCodes, code)
+		}
+	}
+	sort.Strings(result.FailedCodes)
+	result.Failed = len(result.FailedCodes)
+	result.Succeeded = len(pending) - result.Failed
+	result.Duration = time.Since(start)
+
+	logger.Infof("Performance backfill finished: %d stocks, %d resumed, %d succeeded, %d failed, %d reports saved in %v",
+		result.Total, result.Resumed, result.Succeeded, result.Failed, result.Reports, result.Duration)
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// loadBackfillCheckpoint 读取断点文件，路径为空或文件不存在时返回空集合
+func loadBackfillCheckpoint(path string) (map[string]bool, error) {
+	completed := make(map[string]bool)
+	if path == "" {
+		return completed, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return completed, nil
+		}
+		return nil, fmt.Errorf("failed to read backfill checkpoint: %w", err)
+	}
+
+	var checkpoint performanceBackfillCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse backfill checkpoint %s: %w", path, err)
+	}
+	for _, code := range checkpoint.Completed {
+		completed[code] = true
+	}
+	return completed, nil
+}
+
+// saveBackfillCheckpoint 写入断点文件
+func saveBackfillCheckpoint(path string, completed map[string]bool) error {
+	if path == "" {
+		return nil
+	}
+
+	checkpoint := performanceBackfillCheckpoint{
+		Completed: make([]string, 0, len(completed)),
+		UpdatedAt: time.Now(),
+	}
+	for code := range completed {
+		checkpoint.Completed = append(checkpoint.Completed, code)
+	}
+	sort.Strings(checkpoint.Completed)
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode backfill checkpoint: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write backfill checkpoint: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免中断时留下不完整的断点文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
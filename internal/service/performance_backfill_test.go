@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackfillSource 模拟数据源和仓库，记录采集次数和写入批次
+type fakeBackfillSource struct {
+	mu      sync.Mutex
+	fetched map[string]int
+	batches [][]model.PerformanceReport
+	failing map[string]bool
+}
+
+func (f *fakeBackfillSource) fetch(tsCode string) ([]model.PerformanceReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched[tsCode]++
+	if f.failing[tsCode] {
+		return nil, errors.New("upstream unavailable")
+	}
+	return []model.PerformanceReport{
+		{TsCode: tsCode, ReportDate: 20250630},
+		{TsCode: tsCode, ReportDate: 20250331},
+	}, nil
+}
+
+func (f *fakeBackfillSource) save(reports []model.PerformanceReport) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	batch := make([]model.PerformanceReport, len(reports))
+	copy(batch, reports)
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func TestRunPerformanceBackfill_ResumeFromCheckpoint(t *testing.T) {
+	codes := []string{"000001.SZ", "000002.SZ", "600000.SH", "600519.SH", "300750.SZ"}
+	checkpoint := filepath.Join(t.TempDir(), "backfill.json")
+	source := &fakeBackfillSource{fetched: map[string]int{}, failing: map[string]bool{"600000.SH": true}}
+
+	var progress []PerformanceBackfillProgress
+	opts := PerformanceBackfillOptions{
+		Concurrency:    2,
+		RateLimit:      1000,
+		BatchSize:      4,
+		CheckpointPath: checkpoint,
+		Progress: func(p PerformanceBackfillProgress) {
+			progress = append(progress, p)
+		},
+	}
+
+	result, err := runPerformanceBackfill(context.Background(), codes, source.fetch, source.save, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Total)
+	assert.Equal(t, 0, result.Resumed)
+	assert.Equal(t, 4, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []string{"600000.SH"}, result.FailedCodes)
+	assert.Equal(t, 8, result.Reports)
+
+	// 每批达到4条即写入，4只成功的股票分两批写入
+	require.Len(t, source.batches, 2)
+	for _, batch := range source.batches {
+		assert.Len(t, batch, 4)
+	}
+
+	require.Len(t, progress, 5)
+	last := progress[len(progress)-1]
+	assert.Equal(t, 5, last.Total)
+	assert.Equal(t, 5, last.Done)
+	assert.Equal(t, 1, last.Failed)
+	assert.Zero(t, last.ETA)
+
+	completed, err := loadBackfillCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Len(t, completed, 4)
+	assert.False(t, completed["600000.SH"])
+
+	// 数据源恢复后重新执行，只采集上次失败的股票
+	source.failing = nil
+	progress = nil
+	result, err = runPerformanceBackfill(context.Background(), codes, source.fetch, source.save, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Resumed)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 0, result.Failed)
+	assert.Equal(t, 2, result.Reports)
+	require.Len(t, progress, 1)
+	assert.Equal(t, 1, progress[0].Total)
+
+	for _, code := range codes {
+		expected := 1
+		if code == "600000.SH" {
+			expected = 2
+		}
+		assert.Equal(t, expected, source.fetched[code], code)
+	}
+
+	completed, err = loadBackfillCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Len(t, completed, 5)
+}
+
+func TestRunPerformanceBackfill_SaveFailureNotCheckpointed(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "backfill.json")
+	source := &fakeBackfillSource{fetched: map[string]int{}}
+
+	result, err := runPerformanceBackfill(context.Background(), []string{"000001.SZ", "000002.SZ"}, source.fetch,
+		func([]model.PerformanceReport) error { return errors.New("too many connections") },
+		PerformanceBackfillOptions{RateLimit: 1000, CheckpointPath: checkpoint})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 0, result.Reports)
+
+	completed, err := loadBackfillCheckpoint(checkpoint)
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+}
@@ -81,12 +81,23 @@ func (s *PerformanceService) GetLatestPerformanceReport(ctx context.Context, tsC
 	return s.repo.GetLatestByTsCode(tsCode)
 }
 
+// GetLatestPerformanceReports 批量获取股票最新业绩报表，tsCodes为nil时获取所有股票，键为股票代码
+func (s *PerformanceService) GetLatestPerformanceReports(tsCodes []string) (map[string]model.PerformanceReport, error) {
+	return s.repo.GetLatestBatch(tsCodes)
+}
+
 // SyncPerformanceReports 同步业绩报表数据
 func (s *PerformanceService) SyncPerformanceReports(ctx context.Context, tsCode string) error {
 	logger.Infof("Syncing performance reports for stock: %s", tsCode)
 
-	// 从采集器获取最新数据
-	reports, err := s.collector.GetPerformanceReports(tsCode)
+	// 从采集器获取最新数据，采集器支持上下文时随ctx取消或超时
+	var reports []model.PerformanceReport
+	var err error
+	if c, ok := s.collector.(collector.DatacenterContextCollector); ok {
+		reports, err = c.GetPerformanceReportsWithContext(ctx, tsCode)
+	} else {
+		reports, err = s.collector.GetPerformanceReports(tsCode)
+	}
 	if err != nil {
 		logger.Errorf("Failed to fetch performance reports from collector: %v", err)
 		return fmt.Errorf("failed to fetch performance reports: %w", err)
@@ -157,11 +168,11 @@ func (s *PerformanceService) GetPerformanceReportsByDateRange(ctx context.Contex
 	return reports, nil
 }
 
-// GetTopPerformers 获取业绩表现最好的股票
-func (s *PerformanceService) GetTopPerformers(ctx context.Context, limit int, orderBy string) ([]model.PerformanceReport, error) {
-	logger.Infof("Getting top %d performers ordered by %s", limit, orderBy)
+// GetTopPerformers 获取业绩表现最好的股票，asc为true时按升序排列
+func (s *PerformanceService) GetTopPerformers(ctx context.Context, limit int, orderBy string, asc bool) ([]model.PerformanceReport, error) {
+	logger.Infof("Getting top %d performers ordered by %s (asc: %v)", limit, orderBy, asc)
 
-	reports, err := s.repo.GetTopPerformers(limit, orderBy)
+	reports, err := s.repo.GetTopPerformers(limit, orderBy, asc)
 	if err != nil {
 		logger.Errorf("Failed to get top performers: %v", err)
 		return nil, fmt.Errorf("failed to get top performers: %w", err)
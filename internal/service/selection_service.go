@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrSelectionResultNotFound 选股结果不存在
+var ErrSelectionResultNotFound = errors.New("selection result not found")
+
+// SelectionService 选股结果服务
+type SelectionService struct {
+	resultRepo *repository.SelectionResult
+}
+
+var (
+	selectionServiceInstance *SelectionService
+	selectionServiceOnce     sync.Once
+)
+
+// GetSelectionService 获取选股结果服务单例
+func GetSelectionService(db *gorm.DB) *SelectionService {
+	selectionServiceOnce.Do(func() {
+		selectionServiceInstance = &SelectionService{
+			resultRepo: repository.NewSelectionResult(db),
+		}
+	})
+	return selectionServiceInstance
+}
+
+// NewSelectionService 创建选股结果服务 (保持向后兼容)
+func NewSelectionService(db *gorm.DB) *SelectionService {
+	return GetSelectionService(db)
+}
+
+// ExplainSelectionResult 获取选股结果的分因子解释
+func (s *SelectionService) ExplainSelectionResult(id uint) (*model.SelectionExplanation, error) {
+	result, err := s.resultRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get selection result: %w", err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%w: %d", ErrSelectionResultNotFound, id)
+	}
+
+	explanation := result.Explain()
+	return &explanation, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"stock/internal/config"
 	"stock/internal/logger"
+	"stock/internal/model"
 )
 
 // Services 服务集合
@@ -14,7 +15,13 @@ type Services struct {
 	DataService        *DataService
 	PerformanceService *PerformanceService
 	ShareholderService *ShareholderService
+	NorthboundService  *NorthboundService
 	IndicatorService   *IndicatorService
+	IndexService       *IndexService
+	StockScoreService  *StockScoreService
+	DataQuality        *DataQualityService
+	JobRuns            *JobRunService
+	Audit              *AuditService
 	NotifyManger       *notification.Manager
 }
 
@@ -34,7 +41,10 @@ func NewServices(cfg *config.Config, logger *logger.Logger) (*Services, error) {
 		DataService:        nil, // 需要数据库连接后初始化
 		PerformanceService: nil, // 需要数据库连接后初始化
 		ShareholderService: nil, // 需要数据库连接后初始化
+		NorthboundService:  nil, // 需要数据库连接后初始化
 		IndicatorService:   nil, // 需要数据库连接后初始化
+		IndexService:       nil, // 需要数据库连接后初始化
+		StockScoreService:  nil, // 需要数据库连接后初始化
 	}, nil
 }
 
@@ -176,9 +186,10 @@ func NewStrategyEngineService(cfg *config.Config, logger *logger.Logger) *Strate
 
 // SelectionResult 选股结果
 type SelectionResult struct {
-	Stock  Stock   `json:"stock"`
-	Score  float64 `json:"score"`
-	Reason string  `json:"reason"`
+	Stock   Stock              `json:"stock"`
+	Score   float64            `json:"score"`
+	Reason  string             `json:"reason"`
+	Factors model.FactorScores `json:"factors"` // 分因子得分，合计等于Score
 }
 
 // Stock 股票信息
@@ -196,14 +207,16 @@ func (s *StrategyEngineService) ExecuteStrategy(strategy string, limit int) ([]S
 	// 这里返回一些示例数据
 	results := []SelectionResult{
 		{
-			Stock:  Stock{Code: "000001.SZ", Name: "平安银行", Industry: "银行"},
-			Score:  85.6,
-			Reason: "技术指标良好，RSI处于合理区间",
+			Stock:   Stock{Code: "000001.SZ", Name: "平安银行", Industry: "银行"},
+			Score:   85.6,
+			Reason:  "技术指标良好，RSI处于合理区间",
+			Factors: model.FactorScores{"ma_trend": 25, "macd_signal": 20, "rsi": 15, "volume": 25.6},
 		},
 		{
-			Stock:  Stock{Code: "000002.SZ", Name: "万科A", Industry: "房地产开发"},
-			Score:  78.9,
-			Reason: "基本面稳健，估值合理",
+			Stock:   Stock{Code: "000002.SZ", Name: "万科A", Industry: "房地产开发"},
+			Score:   78.9,
+			Reason:  "基本面稳健，估值合理",
+			Factors: model.FactorScores{"eps_growth": 25, "roe": 20, "revenue_growth": 15, "valuation": 18.9},
 		},
 	}
 
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"stock/internal/collector"
+	"stock/internal/logger"
 	"stock/internal/model"
 	"stock/internal/repository"
 	"stock/internal/utils"
@@ -13,8 +17,9 @@ import (
 
 // ShareholderService 股东户数服务
 type ShareholderService struct {
-	repo      *repository.Shareholder
-	collector collector.DataCollector
+	repo       *repository.Shareholder
+	collector  collector.DataCollector
+	sharesRepo *repository.StockShares // 保存股东户数数据中的总股本变动，为nil时不保存
 }
 
 // GetShareholderCounts 获取股东户数数据
@@ -27,6 +32,11 @@ func (s *ShareholderService) GetLatestShareholderCount(tsCode string) (*model.Sh
 	return s.repo.GetLatest(tsCode)
 }
 
+// GetLatestShareholderCounts 批量获取股票最新股东户数，tsCodes为nil时获取所有股票，键为股票代码
+func (s *ShareholderService) GetLatestShareholderCounts(tsCodes []string) (map[string]model.ShareholderCount, error) {
+	return s.repo.GetLatestBatch(tsCodes)
+}
+
 // GetShareholderCountsByDateRange 按日期范围获取股东户数
 func (s *ShareholderService) GetShareholderCountsByDateRange(tsCode string, startDate, endDate string) ([]*model.ShareholderCount, error) {
 	// 使用现有的GetByTsCode方法，然后过滤日期范围
@@ -57,9 +67,9 @@ func (s *ShareholderService) GetShareholderCountsByDateRange(tsCode string, star
 	return filteredCounts, nil
 }
 
-// SyncShareholderCounts 同步单只股票的股东户数
-func (s *ShareholderService) SyncShareholderCounts(tsCode string) error {
-	return s.SyncData(tsCode)
+// SyncShareholderCounts 同步单只股票的股东户数，ctx取消或超时时中止请求
+func (s *ShareholderService) SyncShareholderCounts(ctx context.Context, tsCode string) error {
+	return s.SyncData(ctx, tsCode)
 }
 
 // SyncAllStocksShareholderCounts 同步所有股票的股东户数
@@ -74,16 +84,43 @@ func (s *ShareholderService) GetStatistics() (map[string]interface{}, error) {
 	return make(map[string]interface{}), nil
 }
 
-// GetTopByHolderNum 按股东户数排序获取前N只股票
-func (s *ShareholderService) GetTopByHolderNum(limit int) ([]*model.ShareholderCount, error) {
-	// 简单实现，实际应该在repository中实现
-	return []*model.ShareholderCount{}, nil
+// 股东户数排行返回数量的默认值和上限
+const (
+	defaultTopShareholdersLimit = 10
+	maxTopShareholdersLimit     = 100
+)
+
+// GetTopShareholders 按指标对各股票最新一期股东户数排序并返回前limit条，asc为true时按升序
+// metric可选holder_num、avg_market_cap、total_market_cap、holder_num_change，不支持时返回错误；
+// limit<=0时使用默认值，超过上限时按上限返回
+func (s *ShareholderService) GetTopShareholders(ctx context.Context, metric string, limit int, asc bool) ([]*model.ShareholderCount, error) {
+	metric, err := repository.NormalizeTopShareholdersMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultTopShareholdersLimit
+	}
+	if limit > maxTopShareholdersLimit {
+		limit = maxTopShareholdersLimit
+	}
+
+	counts, err := s.repo.GetTopShareholders(metric, limit, asc)
+	if err != nil {
+		return nil, fmt.Errorf("获取股东户数排行失败: %w", err)
+	}
+	return counts, nil
 }
 
-// GetTopByAvgMarketCap 按平均市值排序获取前N只股票
-func (s *ShareholderService) GetTopByAvgMarketCap(limit int) ([]*model.ShareholderCount, error) {
-	// 简单实现，实际应该在repository中实现
-	return []*model.ShareholderCount{}, nil
+// GetTopByHolderNum 按股东户数排序获取前N只股票，ascending为true时按升序
+func (s *ShareholderService) GetTopByHolderNum(limit int, ascending bool) ([]*model.ShareholderCount, error) {
+	return s.GetTopShareholders(context.Background(), "holder_num", limit, ascending)
+}
+
+// GetTopByAvgMarketCap 按平均市值排序获取前N只股票，ascending为true时按升序
+func (s *ShareholderService) GetTopByAvgMarketCap(limit int, ascending bool) ([]*model.ShareholderCount, error) {
+	return s.GetTopShareholders(context.Background(), "avg_market_cap", limit, ascending)
 }
 
 // GetRecentChanges 获取最近变化的股东户数
@@ -92,14 +129,11 @@ func (s *ShareholderService) GetRecentChanges(days int) ([]*model.ShareholderCou
 	return []*model.ShareholderCount{}, nil
 }
 
-// GetShareholderCountsWithPagination 分页获取股东户数数据
-func (s *ShareholderService) GetShareholderCountsWithPagination(page, pageSize int, tsCode string) ([]*model.ShareholderCount, int64, error) {
-	// 简单实现，实际应该在repository中实现
-	counts, err := s.repo.GetByTsCode(tsCode)
-	if err != nil {
-		return nil, 0, err
-	}
-	return counts, int64(len(counts)), nil
+// GetShareholderCountsWithPagination 分页获取统计截止日期在[startDate, endDate]内的股东户数数据
+func (s *ShareholderService) GetShareholderCountsWithPagination(startDate, endDate time.Time, page, pageSize int) ([]*model.ShareholderCount, int64, error) {
+	start, _ := strconv.Atoi(startDate.Format("20060102"))
+	end, _ := strconv.Atoi(endDate.Format("20060102"))
+	return s.repo.GetByEndDateRange(start, end, (page-1)*pageSize, pageSize)
 }
 
 // ValidateShareholderCount 验证股东户数数据
@@ -129,10 +163,21 @@ func NewShareholderService(repo *repository.Shareholder, collector collector.Dat
 	return GetShareholderService(repo, collector)
 }
 
+// SetSharesRepository 设置股本变动记录仓库，同步股东户数时按截止日期保存总股本的变动
+func (s *ShareholderService) SetSharesRepository(repo *repository.StockShares) {
+	s.sharesRepo = repo
+}
+
 // SyncData 同步股东户数数据
-func (s *ShareholderService) SyncData(tsCode string) error {
-	// 从采集器获取数据
-	counts, err := s.collector.GetShareholderCounts(tsCode)
+func (s *ShareholderService) SyncData(ctx context.Context, tsCode string) error {
+	// 从采集器获取数据，采集器支持上下文时随ctx取消或超时
+	var counts []model.ShareholderCount
+	var err error
+	if c, ok := s.collector.(collector.DatacenterContextCollector); ok {
+		counts, err = c.GetShareholderCountsWithContext(ctx, tsCode)
+	} else {
+		counts, err = s.collector.GetShareholderCounts(tsCode)
+	}
 	if err != nil {
 		return fmt.Errorf("获取股东户数数据失败: %v", err)
 	}
@@ -153,6 +198,50 @@ func (s *ShareholderService) SyncData(tsCode string) error {
 		return fmt.Errorf("保存股东户数数据失败: %v", err)
 	}
 
+	// 股本变动记录是附带保存的，失败不影响股东户数同步结果
+	if s.sharesRepo != nil {
+		if err := s.recordShares(counts); err != nil {
+			logger.Warnf("Failed to record shares of %s from shareholder counts: %v", tsCode, err)
+		}
+	}
+	return nil
+}
+
+// recordShares 按截止日期升序保存各期的总股本，与之前的记录相同时跳过
+// 股东户数数据的总股本只含A股，A+H股公司与股票详情的总股本口径不同，因此只用于补充最早一条详情记录之前的历史
+func (s *ShareholderService) recordShares(counts []model.ShareholderCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	history, err := s.sharesRepo.GetHistory(counts[0].TsCode)
+	if err != nil {
+		return err
+	}
+	detailSince := 0
+	for _, record := range history {
+		if record.Source == model.SharesSourceDetail {
+			detailSince = record.ChangeDate
+			break
+		}
+	}
+
+	sorted := make([]model.ShareholderCount, len(counts))
+	copy(sorted, counts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].EndDate < sorted[j].EndDate })
+	for _, count := range sorted {
+		if detailSince > 0 && count.EndDate >= detailSince {
+			break
+		}
+		_, err := s.sharesRepo.RecordChange(model.StockShares{
+			TsCode:      count.TsCode,
+			ChangeDate:  count.EndDate,
+			TotalShares: count.TotalAShares,
+			Source:      model.SharesSourceShareholder,
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
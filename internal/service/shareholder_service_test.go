@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// fakeShareholderCollector 只实现股东户数采集的数据源
+type fakeShareholderCollector struct {
+	collector.DataCollector
+	counts []model.ShareholderCount
+}
+
+func (f *fakeShareholderCollector) GetShareholderCounts(tsCode string) ([]model.ShareholderCount, error) {
+	return f.counts, nil
+}
+
+// capturedInsert 记录DryRun模式下生成的写入语句
+type capturedInsert struct {
+	sql    string
+	counts []*model.ShareholderCount
+}
+
+func TestShareholderService_SyncTwiceIsIdempotent(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	var inserts []capturedInsert
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		counts, _ := tx.Statement.Dest.([]*model.ShareholderCount)
+		snapshot := make([]*model.ShareholderCount, len(counts))
+		for i, c := range counts {
+			copied := *c
+			snapshot[i] = &copied
+		}
+		inserts = append(inserts, capturedInsert{sql: tx.Statement.SQL.String(), counts: snapshot})
+	}))
+
+	// 两次同步返回重叠的历史季度
+	fake := &fakeShareholderCollector{counts: []model.ShareholderCount{
+		{TsCode: "000001.SZ", EndDate: 20250630, SecurityCode: "000001", SecurityName: "平安银行", HolderNum: 480000},
+		{TsCode: "000001.SZ", EndDate: 20250331, SecurityCode: "000001", SecurityName: "平安银行", HolderNum: 500000},
+	}}
+	s := &ShareholderService{repo: repository.NewShareholder(db), collector: fake}
+
+	require.NoError(t, s.SyncShareholderCounts(context.Background(), "000001.SZ"))
+	time.Sleep(2 * time.Millisecond)
+	fake.counts[0].HolderNum = 470000
+	require.NoError(t, s.SyncShareholderCounts(context.Background(), "000001.SZ"))
+
+	require.Len(t, inserts, 2)
+	for _, insert := range inserts {
+		// 主键冲突时更新可变字段和更新时间，不覆盖创建时间，也不写入关联的股票表
+		assert.Contains(t, insert.sql, "INSERT INTO `shareholder_counts`")
+		assert.Contains(t, insert.sql, "ON DUPLICATE KEY UPDATE")
+		assert.Contains(t, insert.sql, "`holder_num`=VALUES(`holder_num`)")
+		assert.Contains(t, insert.sql, "`updated_at`=VALUES(`updated_at`)")
+		assert.NotContains(t, insert.sql, "`created_at`=VALUES(`created_at`)")
+		assert.NotContains(t, insert.sql, "`stocks`")
+	}
+
+	first, second := inserts[0].counts, inserts[1].counts
+	require.Len(t, first, 2)
+	require.Len(t, second, 2)
+	assert.True(t, second[0].UpdatedAt.After(first[0].UpdatedAt), "re-sync should refresh updated_at")
+	assert.Equal(t, int64(470000), second[0].HolderNum)
+	for _, c := range append(first, second...) {
+		assert.False(t, c.CreatedAt.IsZero())
+		assert.False(t, c.UpdatedAt.Before(c.CreatedAt))
+	}
+}
+
+func TestShareholderService_GetTopShareholders(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	// 只记录排行的主查询，构造最新一期子查询时也会触发查询回调
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		if sql := tx.Statement.SQL.String(); strings.Contains(sql, "ORDER BY") {
+			queries = append(queries, tx.Dialector.Explain(sql, tx.Statement.Vars...))
+		}
+	}))
+	s := &ShareholderService{repo: repository.NewShareholder(db)}
+
+	for _, metric := range repository.TopShareholderMetrics() {
+		for _, asc := range []bool{true, false} {
+			queries = nil
+			_, err := s.GetTopShareholders(context.Background(), metric, 20, asc)
+			require.NoError(t, err)
+			require.Len(t, queries, 1)
+
+			direction := "DESC"
+			if asc {
+				direction = "ASC"
+			}
+			// 按各股票最新一期排序，同值时按股票代码保证顺序稳定
+			assert.Contains(t, queries[0], "MAX(`end_date`)", metric)
+			assert.Contains(t, queries[0], "ORDER BY "+metric+" "+direction+",ts_code ASC LIMIT 20", metric)
+		}
+	}
+
+	// 旧接口为固定指标的包装，limit越界时使用默认值或上限
+	queries = nil
+	_, err = s.GetTopByHolderNum(0, true)
+	require.NoError(t, err)
+	_, err = s.GetTopByAvgMarketCap(5000, false)
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "ORDER BY holder_num ASC,ts_code ASC LIMIT 10")
+	assert.Contains(t, queries[1], "ORDER BY avg_market_cap DESC,ts_code ASC LIMIT 100")
+
+	// 不支持的指标不发起查询
+	queries = nil
+	_, err = s.GetTopShareholders(context.Background(), "holder_num_ratio", 10, false)
+	assert.Error(t, err)
+	assert.Empty(t, queries)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/utils"
+)
+
+// 跨数据源核对的默认参数
+const (
+	DefaultSourceCheckSample    = 20    // 未指定股票时随机抽取的股票数
+	MaxSourceCheckSample        = 200   // 单次最多核对的股票数，每只股票对两个数据源各请求一次
+	DefaultSourceCheckDays      = 30    // 核对最近多少个自然日的日K线
+	DefaultSourceCheckTolerance = 0.001 // 相对差异容差，价格保留3位小数，0.1%足以区分精度和复权差异
+)
+
+// SourceCheckOptions 跨数据源核对参数
+type SourceCheckOptions struct {
+	TsCodes   []string // 指定核对的股票，为空时从活跃股票中随机抽取
+	Sample    int      // 随机抽取的股票数，<=0时使用默认值
+	Days      int      // 核对最近多少个自然日的日K线，<=0时使用默认值
+	Tolerance float64  // 相对差异容差，<=0时使用默认值
+}
+
+// SourceCheckService 跨数据源K线核对服务，对同一批股票分别从两个数据源获取最近的日K线并比较开高低收量额
+type SourceCheckService struct {
+	stockRepo *repository.Stock
+	primary   collector.DataCollector
+	secondary collector.DataCollector
+	now       func() time.Time
+}
+
+var (
+	sourceCheckServiceInstance *SourceCheckService
+	sourceCheckServiceOnce     sync.Once
+)
+
+// GetSourceCheckService 获取跨数据源K线核对服务单例，primary为主数据源（东方财富），secondary为对比数据源（同花顺）
+func GetSourceCheckService(stockRepo *repository.Stock, primary, secondary collector.DataCollector) *SourceCheckService {
+	sourceCheckServiceOnce.Do(func() {
+		sourceCheckServiceInstance = &SourceCheckService{
+			stockRepo: stockRepo,
+			primary:   primary,
+			secondary: secondary,
+			now:       utils.AppNow,
+		}
+	})
+	return sourceCheckServiceInstance
+}
+
+// NewSourceCheckService 创建跨数据源K线核对服务 (保持向后兼容)
+func NewSourceCheckService(stockRepo *repository.Stock, primary, secondary collector.DataCollector) *SourceCheckService {
+	return GetSourceCheckService(stockRepo, primary, secondary)
+}
+
+// Check 核对股票最近的日K线在两个数据源之间的差异，单只股票获取失败时记录在报告中并继续
+func (s *SourceCheckService) Check(ctx context.Context, opts SourceCheckOptions) (*model.SourceDiscrepancyReport, error) {
+	if s.primary == nil || s.secondary == nil {
+		return nil, fmt.Errorf("跨数据源核对需要两个数据源")
+	}
+	if opts.Days <= 0 {
+		opts.Days = DefaultSourceCheckDays
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultSourceCheckTolerance
+	}
+
+	tsCodes, err := s.sampleStocks(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	end := s.now()
+	start := end.AddDate(0, 0, -opts.Days)
+	report := model.NewSourceDiscrepancyReport(s.primary.GetName(), s.secondary.GetName(), opts.Tolerance)
+	for _, tsCode := range tsCodes {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		primaryBars, err := s.primary.GetDailyKLine(tsCode, start, end)
+		if err != nil {
+			report.AddFailure(tsCode, fmt.Errorf("%s: %v", s.primary.GetName(), err))
+			continue
+		}
+		secondaryBars, err := s.secondary.GetDailyKLine(tsCode, start, end)
+		if err != nil {
+			report.AddFailure(tsCode, fmt.Errorf("%s: %v", s.secondary.GetName(), err))
+			continue
+		}
+		report.AddStock(tsCode, primaryBars, secondaryBars)
+	}
+
+	if report.HasDiscrepancies() {
+		for _, field := range report.Fields {
+			if field.Mismatched > 0 {
+				logger.Warnf("Source discrepancy between %s and %s on %s: %d/%d bars in %d stocks, max relative diff %.4f",
+					report.Primary, report.Secondary, field.Field, field.Mismatched, field.Compared, field.Stocks, field.MaxRelDiff)
+			}
+		}
+	}
+	return report, nil
+}
+
+// sampleStocks 确定核对的股票：指定了股票时使用指定的股票，否则从活跃股票中随机抽取
+func (s *SourceCheckService) sampleStocks(opts SourceCheckOptions) ([]string, error) {
+	sample := opts.Sample
+	if sample <= 0 {
+		sample = DefaultSourceCheckSample
+	}
+	sample = min(sample, MaxSourceCheckSample)
+
+	if len(opts.TsCodes) > 0 {
+		return opts.TsCodes[:min(len(opts.TsCodes), MaxSourceCheckSample)], nil
+	}
+
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return nil, fmt.Errorf("获取股票列表失败: %w", err)
+	}
+	rand.Shuffle(len(stocks), func(i, j int) { stocks[i], stocks[j] = stocks[j], stocks[i] })
+
+	tsCodes := make([]string, 0, min(sample, len(stocks)))
+	for _, stock := range stocks[:min(sample, len(stocks))] {
+		tsCodes = append(tsCodes, stock.TsCode)
+	}
+	return tsCodes, nil
+}
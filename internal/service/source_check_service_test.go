@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock/internal/collector"
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKLineCollector 只实现日K线采集的数据源，按股票代码返回固定的K线
+type fakeKLineCollector struct {
+	collector.DataCollector
+	name string
+	bars map[string][]model.DailyData
+	err  map[string]error
+}
+
+func (f *fakeKLineCollector) GetName() string { return f.name }
+
+func (f *fakeKLineCollector) GetDailyKLine(tsCode string, startDate, endDate time.Time) ([]model.DailyData, error) {
+	if err := f.err[tsCode]; err != nil {
+		return nil, err
+	}
+	return f.bars[tsCode], nil
+}
+
+func TestSourceCheckService_ReportsDivergentBars(t *testing.T) {
+	bar := func(tsCode string, date int, open, high, low, close float64, volume int64, amount float64) model.DailyData {
+		return model.DailyData{TsCode: tsCode, TradeDate: date, Open: open, High: high, Low: low, Close: close, Volume: volume, Amount: amount}
+	}
+	eastMoney := &fakeKLineCollector{name: "eastmoney", bars: map[string][]model.DailyData{
+		"600000.SH": {
+			bar("600000.SH", 20240102, 6.62, 6.64, 6.53, 6.55, 40012500, 263008765.43),
+			bar("600000.SH", 20240103, 6.55, 6.60, 6.52, 6.58, 28764300, 188901234.56),
+			bar("600000.SH", 20240104, 6.58, 6.62, 6.55, 6.57, 25698700, 169002345.67),
+		},
+		"000001.SZ": {
+			bar("000001.SZ", 20240102, 9.39, 9.42, 9.21, 9.21, 115480000, 1075775000),
+			bar("000001.SZ", 20240103, 9.19, 9.22, 9.15, 9.20, 84845000, 779099000),
+		},
+	}}
+	tongHuaShun := &fakeKLineCollector{name: "tonghuashun", bars: map[string][]model.DailyData{
+		// 最高价解码错误，成交额缺失（all.js没有成交额），少一根K线
+		"600000.SH": {
+			bar("600000.SH", 20240102, 6.62, 6.74, 6.53, 6.55, 40012500, 0),
+			bar("600000.SH", 20240103, 6.55, 6.60, 6.52, 6.58, 28764300, 0),
+		},
+		// 复权方式不同，价格整体偏移；成交量一致
+		"000001.SZ": {
+			bar("000001.SZ", 20240102, 9.49, 9.52, 9.31, 9.31, 115480000, 1075775000),
+			bar("000001.SZ", 20240103, 9.19, 9.22, 9.15, 9.2004, 84845000, 779099000), // 差异在容差内
+		},
+	}, err: map[string]error{"300750.SZ": errors.New("HTTP 403")}}
+
+	s := &SourceCheckService{primary: eastMoney, secondary: tongHuaShun, now: time.Now}
+	report, err := s.Check(context.Background(), SourceCheckOptions{TsCodes: []string{"600000.SH", "000001.SZ", "300750.SZ"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "eastmoney", report.Primary)
+	assert.Equal(t, "tonghuashun", report.Secondary)
+	assert.Equal(t, DefaultSourceCheckTolerance, report.Tolerance)
+	assert.Equal(t, 2, report.Stocks)
+	assert.Equal(t, map[string]string{"300750.SZ": "tonghuashun: HTTP 403"}, report.Failed)
+	assert.Equal(t, 4, report.MatchedBars)
+	assert.Equal(t, 1, report.MissingBars)
+	assert.True(t, report.HasDiscrepancies())
+
+	fields := make(map[string]model.DiscrepancyFieldSummary)
+	for _, field := range report.Fields {
+		fields[field.Field] = field
+	}
+	assert.Equal(t, model.DiscrepancyFieldSummary{Field: "high", Compared: 4, Mismatched: 2, Stocks: 2, MaxRelDiff: fields["high"].MaxRelDiff}, fields["high"])
+	assert.InDelta(t, 0.1/6.74, fields["high"].MaxRelDiff, 1e-9)
+	assert.Equal(t, 1, fields["open"].Mismatched)
+	assert.Equal(t, 1, fields["close"].Mismatched)
+	assert.Equal(t, 0, fields["volume"].Mismatched)
+	assert.Equal(t, 4, fields["volume"].Compared)
+	assert.Equal(t, 2, fields["amount"].Compared, "一方没有成交额时不比较")
+
+	// 明细按相对差异降序
+	require.Len(t, report.Discrepancies, 5)
+	assert.Equal(t, model.SourceDiscrepancy{TsCode: "600000.SH", TradeDate: 20240102, Field: "high", Primary: 6.64, Secondary: 6.74,
+		RelDiff: report.Discrepancies[0].RelDiff}, report.Discrepancies[0])
+	for i := 1; i < len(report.Discrepancies); i++ {
+		assert.GreaterOrEqual(t, report.Discrepancies[i-1].RelDiff, report.Discrepancies[i].RelDiff)
+	}
+}
+
+func TestSourceCheckService_AgreeingSources(t *testing.T) {
+	bars := map[string][]model.DailyData{"600519.SH": {{TsCode: "600519.SH", TradeDate: 20240102, Open: 1715, High: 1718.19, Low: 1678.1, Close: 1685.01, Volume: 3215644}}}
+	s := &SourceCheckService{
+		primary:   &fakeKLineCollector{name: "eastmoney", bars: bars},
+		secondary: &fakeKLineCollector{name: "tonghuashun", bars: bars},
+		now:       time.Now,
+	}
+	report, err := s.Check(context.Background(), SourceCheckOptions{TsCodes: []string{"600519.SH"}, Tolerance: 0.0001})
+	require.NoError(t, err)
+	assert.False(t, report.HasDiscrepancies())
+	assert.Empty(t, report.Discrepancies)
+	assert.Equal(t, 1, report.MatchedBars)
+
+	_, err = (&SourceCheckService{primary: s.primary}).Check(context.Background(), SourceCheckOptions{})
+	assert.Error(t, err)
+}
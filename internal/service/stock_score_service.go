@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"stock/internal/indicator"
+	"stock/internal/logger"
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// scoreDailyBars 技术面评分读取的最近日K线数量
+const scoreDailyBars = 250
+
+// ErrInsufficientScoreData 各维度数据都不足，无法计算综合评分
+var ErrInsufficientScoreData = errors.New("insufficient data to score")
+
+// StockScoreService 股票综合评分服务
+type StockScoreService struct {
+	scoreRepo       *repository.StockScore
+	stockRepo       *repository.Stock
+	dailyDataRepo   *repository.DailyData
+	performanceRepo *repository.Performance
+	shareholderRepo *repository.Shareholder
+
+	weightsMu sync.RWMutex
+	weights   indicator.ScoreWeights
+}
+
+var (
+	stockScoreServiceInstance *StockScoreService
+	stockScoreServiceOnce     sync.Once
+)
+
+// GetStockScoreService 获取股票综合评分服务单例
+func GetStockScoreService(db *gorm.DB) *StockScoreService {
+	stockScoreServiceOnce.Do(func() {
+		stockScoreServiceInstance = &StockScoreService{
+			scoreRepo:       repository.NewStockScore(db),
+			stockRepo:       repository.NewStock(db),
+			dailyDataRepo:   repository.NewDailyData(db),
+			performanceRepo: repository.NewPerformance(db),
+			shareholderRepo: repository.NewShareholder(db),
+			weights:         indicator.DefaultScoreWeights,
+		}
+	})
+	return stockScoreServiceInstance
+}
+
+// NewStockScoreService 创建股票综合评分服务 (保持向后兼容)
+func NewStockScoreService(db *gorm.DB) *StockScoreService {
+	return GetStockScoreService(db)
+}
+
+// SetWeights 设置评分权重，权重全部<=0时使用默认权重
+func (s *StockScoreService) SetWeights(weights indicator.ScoreWeights) {
+	if weights.Technical <= 0 && weights.Fundamental <= 0 && weights.Shareholder <= 0 {
+		weights = indicator.DefaultScoreWeights
+	}
+
+	s.weightsMu.Lock()
+	defer s.weightsMu.Unlock()
+	s.weights = weights
+}
+
+// GetWeights 获取当前的评分权重
+func (s *StockScoreService) GetWeights() indicator.ScoreWeights {
+	s.weightsMu.RLock()
+	defer s.weightsMu.RUnlock()
+	return s.weights
+}
+
+// ComputeScore 计算股票的综合评分，评分日期为最新日K线的交易日期
+// 各维度数据不足时该维度记为空，所有维度都缺失时返回错误
+func (s *StockScoreService) ComputeScore(tsCode string) (*model.StockScore, error) {
+	daily, err := s.dailyDataRepo.GetAnalysisDailyData(tsCode, time.Time{}, time.Time{}, scoreDailyBars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily data: %w", err)
+	}
+	// 数据库按交易日期降序返回，指标计算需要升序
+	for i, j := 0, len(daily)-1; i < j; i, j = i+1, j-1 {
+		daily[i], daily[j] = daily[j], daily[i]
+	}
+
+	reports, err := s.performanceRepo.GetByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance reports: %w", err)
+	}
+
+	holders, err := s.shareholderRepo.GetByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shareholder counts: %w", err)
+	}
+
+	var components indicator.ScoreComponents
+	if v, ok := indicator.TechnicalScore(daily); ok {
+		components.Technical = &v
+	}
+	if v, ok := indicator.FundamentalScore(reports); ok {
+		components.Fundamental = &v
+	}
+	if v, ok := indicator.ShareholderScore(holders); ok {
+		components.Shareholder = &v
+	}
+
+	total, ok := indicator.CompositeScore(components, s.GetWeights())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientScoreData, tsCode)
+	}
+
+	tradeDate := 0
+	if len(daily) > 0 {
+		tradeDate = daily[len(daily)-1].TradeDate
+	} else {
+		now := time.Now()
+		tradeDate = now.Year()*10000 + int(now.Month())*100 + now.Day()
+	}
+
+	return &model.StockScore{
+		TsCode:           tsCode,
+		TradeDate:        tradeDate,
+		Score:            total,
+		TechnicalScore:   components.Technical,
+		FundamentalScore: components.Fundamental,
+		ShareholderScore: components.Shareholder,
+	}, nil
+}
+
+// ScoreAndSave 计算股票的综合评分并保存
+func (s *StockScoreService) ScoreAndSave(tsCode string) (*model.StockScore, error) {
+	score, err := s.ComputeScore(tsCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scoreRepo.Upsert([]model.StockScore{*score}); err != nil {
+		return nil, fmt.Errorf("failed to save score: %w", err)
+	}
+	return score, nil
+}
+
+// ScoreAllStocks 计算所有活跃股票的综合评分并保存，返回保存和因数据不足跳过的数量
+func (s *StockScoreService) ScoreAllStocks() (int, int, error) {
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	scores := make([]model.StockScore, 0, len(stocks))
+	skipped := 0
+	for _, stock := range stocks {
+		score, err := s.ComputeScore(stock.TsCode)
+		if err != nil {
+			logger.Debugf("Skip scoring %s: %v", stock.TsCode, err)
+			skipped++
+			continue
+		}
+		scores = append(scores, *score)
+	}
+
+	if err := s.scoreRepo.Upsert(scores); err != nil {
+		return 0, skipped, fmt.Errorf("failed to save scores: %w", err)
+	}
+
+	logger.Infof("Scored %d stocks, skipped %d with insufficient data", len(scores), skipped)
+	return len(scores), skipped, nil
+}
+
+// GetLatestScore 获取股票最新的综合评分，尚未评分时即时计算并保存
+func (s *StockScoreService) GetLatestScore(tsCode string) (*model.StockScore, error) {
+	score, err := s.scoreRepo.GetLatest(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest score: %w", err)
+	}
+	if score != nil {
+		return score, nil
+	}
+	return s.ScoreAndSave(tsCode)
+}
+
+// GetRanking 获取最新评分日期综合评分最高的股票，返回评分日期和排名
+func (s *StockScoreService) GetRanking(limit int) (int, []model.StockScore, error) {
+	tradeDate, err := s.scoreRepo.GetLatestTradeDate()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get latest score date: %w", err)
+	}
+	if tradeDate == 0 {
+		return 0, []model.StockScore{}, nil
+	}
+
+	scores, err := s.scoreRepo.GetRanking(tradeDate, limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get score ranking: %w", err)
+	}
+	return tradeDate, scores, nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"stock/internal/collector"
 	"stock/internal/model"
+	"stock/internal/repository"
+	"stock/internal/utils"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -244,7 +246,7 @@ func (s *StockService) RefreshStockDetail(tsCode string) (*model.Stock, error) {
 	now := time.Now()
 	stockDetail.UpdatedAt = now
 
-	if err := s.db.Model(&model.Stock{}).Where("ts_code = ?", tsCode).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"symbol":     stockDetail.Symbol,
 		"name":       stockDetail.Name,
 		"area":       stockDetail.Area,
@@ -252,10 +254,22 @@ func (s *StockService) RefreshStockDetail(tsCode string) (*model.Stock, error) {
 		"market":     stockDetail.Market,
 		"list_date":  stockDetail.ListDate,
 		"is_active":  stockDetail.IsActive,
+		"status":     stockDetail.GetStatus(),
 		"updated_at": now,
-	}).Error; err != nil {
+	}
+	// 数据源未返回股本时保留已有值
+	if stockDetail.FloatShares > 0 {
+		updates["float_shares"] = stockDetail.FloatShares
+	}
+	if stockDetail.TotalShares > 0 {
+		updates["total_shares"] = stockDetail.TotalShares
+	}
+	if err := s.db.Model(&model.Stock{}).Where("ts_code = ?", tsCode).Updates(updates).Error; err != nil {
 		return nil, fmt.Errorf("failed to update stock in database: %w", err)
 	}
+	if _, err := repository.NewStockShares(s.db).RecordChange(stockDetail.SharesRecord(utils.TradeDateOf(now))); err != nil {
+		s.logger.Warnf("Failed to record shares of %s: %v", tsCode, err)
+	}
 
 	s.logger.Infof("Successfully refreshed stock detail for %s", tsCode)
 	return stockDetail, nil
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/utils"
+)
+
+// StockKLineUpdate 单只股票增量更新的K线条数
+type StockKLineUpdate struct {
+	TsCode  string `json:"ts_code"`
+	Daily   int    `json:"daily"`
+	Weekly  int    `json:"weekly"`
+	Monthly int    `json:"monthly"`
+	Yearly  int    `json:"yearly"`
+}
+
+// UpdateStockKLine 增量更新单只股票的日、周、月、年K线
+// 没有数据时从historyStart开始全量采集；有数据时从最新一根K线的交易日期开始重新采集并覆盖。
+// 周、月、年K线的最新一根可能是尚未走完的周期，其交易日期会随周期推进而变化，重新采集前先删除
+func (s *DataService) UpdateStockKLine(stock *model.Stock, historyStart time.Time) (*StockKLineUpdate, error) {
+	result := &StockKLineUpdate{TsCode: stock.TsCode}
+	endDate := time.Now()
+
+	latestDaily, err := s.dailyDataRepo.GetLatestDailyData(stock.TsCode)
+	if err != nil {
+		return result, fmt.Errorf("获取最新日K线数据失败: %v", err)
+	}
+	startDate, err := incrementalStartDate(latestDaily, historyStart, nil)
+	if err != nil {
+		return result, err
+	}
+	if result.Daily, err = s.SyncDailyData(stock.TsCode, startDate, endDate); err != nil {
+		return result, err
+	}
+
+	latestWeekly, err := s.weeklyDataRepo.GetLatestWeeklyData(stock.TsCode)
+	if err != nil {
+		return result, fmt.Errorf("获取最新周K线数据失败: %v", err)
+	}
+	startDate, err = incrementalStartDate(latestWeekly, historyStart, func(tradeDate time.Time) error {
+		return s.weeklyDataRepo.DeleteWeeklyData(stock.TsCode, tradeDate)
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Weekly, err = s.SyncWeeklyData(stock.TsCode, startDate, endDate); err != nil {
+		return result, err
+	}
+
+	latestMonthly, err := s.monthlyDataRepo.GetLatestMonthlyData(stock.TsCode)
+	if err != nil {
+		return result, fmt.Errorf("获取最新月K线数据失败: %v", err)
+	}
+	startDate, err = incrementalStartDate(latestMonthly, historyStart, func(tradeDate time.Time) error {
+		return s.monthlyDataRepo.DeleteMonthlyData(stock.TsCode, tradeDate)
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Monthly, err = s.SyncMonthlyData(stock.TsCode, startDate, endDate); err != nil {
+		return result, err
+	}
+
+	latestYearly, err := s.yearlyDataRepo.GetLatestYearlyData(stock.TsCode)
+	if err != nil {
+		return result, fmt.Errorf("获取最新年K线数据失败: %v", err)
+	}
+	startDate, err = incrementalStartDate(latestYearly, historyStart, func(tradeDate time.Time) error {
+		return s.yearlyDataRepo.DeleteYearlyData(stock.TsCode, tradeDate)
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Yearly, err = s.SyncYearlyData(stock.TsCode, startDate, endDate); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// incrementalStartDate 确定增量采集的起始日期，latest为nil时返回historyStart
+// deleteLatest不为nil时先删除最新一根K线
+func incrementalStartDate[T model.TradeDated](latest *T, historyStart time.Time, deleteLatest func(tradeDate time.Time) error) (time.Time, error) {
+	if latest == nil {
+		return historyStart, nil
+	}
+
+	tradeDate, err := utils.ParseTradeDate((*latest).GetTradeDate())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析交易日期失败: %v", err)
+	}
+	if deleteLatest != nil {
+		if err := deleteLatest(tradeDate); err != nil {
+			return time.Time{}, fmt.Errorf("删除最新K线数据失败: %v", err)
+		}
+	}
+	return tradeDate, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"stock/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownStrategy 选股策略未注册
+var ErrUnknownStrategy = errors.New("unknown selection strategy")
+
+// 内置选股策略名称
+const (
+	StrategyTechnical   = "technical"   // 技术面策略
+	StrategyFundamental = "fundamental" // 基本面策略
+	StrategyCombined    = "combined"    // 综合策略
+)
+
+// Strategy 选股策略，按名称注册到StrategyRegistry后即可在CLI和接口中使用
+type Strategy interface {
+	// Name 策略名称，注册表中唯一
+	Name() string
+
+	// Description 策略描述
+	Description() string
+
+	// Evaluate 评估股票，返回入选股票的选股结果，结果无需排序
+	Evaluate(ctx context.Context, stocks []model.Stock) ([]model.SelectionResult, error)
+}
+
+// StrategyInfo 已注册选股策略的信息
+type StrategyInfo struct {
+	Name        string `json:"name"`        // 策略名称
+	Description string `json:"description"` // 策略描述
+}
+
+// StrategyRegistry 选股策略注册表，新增策略只需注册，无需修改CLI和接口
+type StrategyRegistry struct {
+	mu         sync.RWMutex
+	strategies map[string]Strategy
+}
+
+var (
+	strategyRegistryInstance *StrategyRegistry
+	strategyRegistryOnce     sync.Once
+)
+
+// GetStrategyRegistry 获取选股策略注册表单例，首次获取时注册内置的技术面、基本面和综合策略
+func GetStrategyRegistry(db *gorm.DB) *StrategyRegistry {
+	strategyRegistryOnce.Do(func() {
+		strategyRegistryInstance = NewStrategyRegistry()
+		scoreService := GetStockScoreService(db)
+		for _, strategy := range builtinStrategies(scoreService.ComputeScore) {
+			_ = strategyRegistryInstance.Register(strategy)
+		}
+	})
+	return strategyRegistryInstance
+}
+
+// NewStrategyRegistry 创建空的选股策略注册表
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{strategies: make(map[string]Strategy)}
+}
+
+// Register 注册选股策略，名称为空或已注册时返回错误
+func (r *StrategyRegistry) Register(strategy Strategy) error {
+	name := strategy.Name()
+	if name == "" {
+		return fmt.Errorf("strategy name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.strategies[name]; exists {
+		return fmt.Errorf("strategy %s already registered", name)
+	}
+	r.strategies[name] = strategy
+	return nil
+}
+
+// Get 按名称获取选股策略
+func (r *StrategyRegistry) Get(name string) (Strategy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	strategy, ok := r.strategies[name]
+	return strategy, ok
+}
+
+// List 获取所有已注册的选股策略，按名称排序
+func (r *StrategyRegistry) List() []StrategyInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]StrategyInfo, 0, len(r.strategies))
+	for _, strategy := range r.strategies {
+		infos = append(infos, StrategyInfo{Name: strategy.Name(), Description: strategy.Description()})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Select 使用指定策略选股，结果按评分降序排列，limit>0时只返回前limit只
+// 策略未注册时返回ErrUnknownStrategy
+func (r *StrategyRegistry) Select(ctx context.Context, name string, stocks []model.Stock, limit int) ([]model.SelectionResult, error) {
+	strategy, ok := r.Get(name)
+	if !ok {
+		names := make([]string, 0)
+		for _, info := range r.List() {
+			names = append(names, info.Name)
+		}
+		return nil, fmt.Errorf("%w: %s, available: %v", ErrUnknownStrategy, name, names)
+	}
+
+	results, err := strategy.Evaluate(ctx, stocks)
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s failed: %w", name, err)
+	}
+
+	for i := range results {
+		results[i].StrategyName = name
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// scoreStrategy 按综合评分中的某个维度选股的内置策略
+type scoreStrategy struct {
+	name        string
+	description string
+	label       string
+	factor      string
+	pick        func(score *model.StockScore) (float64, bool)
+	compute     func(tsCode string) (*model.StockScore, error)
+}
+
+// builtinStrategies 内置选股策略，compute计算单只股票的综合评分
+func builtinStrategies(compute func(tsCode string) (*model.StockScore, error)) []Strategy {
+	component := func(v *float64) (float64, bool) {
+		if v == nil {
+			return 0, false
+		}
+		return *v, true
+	}
+
+	return []Strategy{
+		&scoreStrategy{
+			name:        StrategyTechnical,
+			description: "技术面策略：按均线、MACD和近期看多信号评分",
+			label:       "技术面",
+			factor:      "technical",
+			pick:        func(s *model.StockScore) (float64, bool) { return component(s.TechnicalScore) },
+			compute:     compute,
+		},
+		&scoreStrategy{
+			name:        StrategyFundamental,
+			description: "基本面策略：按ROE、每股收益增长和营收增长评分",
+			label:       "基本面",
+			factor:      "fundamental",
+			pick:        func(s *model.StockScore) (float64, bool) { return component(s.FundamentalScore) },
+			compute:     compute,
+		},
+		&scoreStrategy{
+			name:        StrategyCombined,
+			description: "综合策略：按技术面、基本面和筹码集中度的加权综合评分",
+			label:       "综合",
+			factor:      "composite",
+			pick:        func(s *model.StockScore) (float64, bool) { return s.Score, true },
+			compute:     compute,
+		},
+	}
+}
+
+// Name 策略名称
+func (s *scoreStrategy) Name() string {
+	return s.name
+}
+
+// Description 策略描述
+func (s *scoreStrategy) Description() string {
+	return s.description
+}
+
+// Evaluate 逐只计算评分，数据不足无法评分的股票跳过
+func (s *scoreStrategy) Evaluate(ctx context.Context, stocks []model.Stock) ([]model.SelectionResult, error) {
+	now := time.Now()
+	results := make([]model.SelectionResult, 0)
+	for _, stock := range stocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		score, err := s.compute(stock.TsCode)
+		if err != nil {
+			if errors.Is(err, ErrInsufficientScoreData) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to score %s: %w", stock.TsCode, err)
+		}
+		value, ok := s.pick(score)
+		if !ok {
+			continue
+		}
+
+		results = append(results, model.NewSelectionResult(s.name, stock.TsCode, now,
+			model.FactorScores{s.factor: value}, fmt.Sprintf("%s评分%.2f", s.label, value)))
+	}
+	return results, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bankStrategy 只选银行股、按代码尾号打分的自定义策略
+type bankStrategy struct{}
+
+func (bankStrategy) Name() string        { return "bank" }
+func (bankStrategy) Description() string { return "银行股策略" }
+
+func (bankStrategy) Evaluate(ctx context.Context, stocks []model.Stock) ([]model.SelectionResult, error) {
+	results := make([]model.SelectionResult, 0)
+	for _, stock := range stocks {
+		if stock.Industry != "银行" {
+			continue
+		}
+		score := float64(stock.TsCode[5] - '0')
+		results = append(results, model.NewSelectionResult("", stock.TsCode, time.Now(),
+			model.FactorScores{"bank": score}, "银行股"))
+	}
+	return results, nil
+}
+
+func TestStrategyRegistry_CustomStrategy(t *testing.T) {
+	registry := NewStrategyRegistry()
+	require.NoError(t, registry.Register(bankStrategy{}))
+	assert.Error(t, registry.Register(bankStrategy{}))
+
+	assert.Equal(t, []StrategyInfo{{Name: "bank", Description: "银行股策略"}}, registry.List())
+
+	stocks := []model.Stock{
+		{TsCode: "000001.SZ", Industry: "银行"},
+		{TsCode: "600036.SH", Industry: "银行"},
+		{TsCode: "600519.SH", Industry: "白酒"},
+		{TsCode: "601398.SH", Industry: "银行"},
+	}
+	results, err := registry.Select(context.Background(), "bank", stocks, 2)
+	require.NoError(t, err)
+
+	// 按评分降序取前2只，策略名称由注册表填写
+	require.Len(t, results, 2)
+	assert.Equal(t, "601398.SH", results[0].TsCode)
+	assert.Equal(t, "600036.SH", results[1].TsCode)
+	for _, result := range results {
+		assert.Equal(t, "bank", result.StrategyName)
+	}
+
+	_, err = registry.Select(context.Background(), "momentum", stocks, 2)
+	assert.True(t, errors.Is(err, ErrUnknownStrategy))
+	assert.Contains(t, err.Error(), "bank")
+}
+
+func TestBuiltinStrategies(t *testing.T) {
+	technical, fundamental := 72.5, 40.0
+	scores := map[string]*model.StockScore{
+		"000001.SZ": {TsCode: "000001.SZ", Score: 60, TechnicalScore: &technical, FundamentalScore: &fundamental},
+		"600519.SH": {TsCode: "600519.SH", Score: 80, FundamentalScore: &fundamental},
+	}
+	compute := func(tsCode string) (*model.StockScore, error) {
+		if score, ok := scores[tsCode]; ok {
+			return score, nil
+		}
+		return nil, ErrInsufficientScoreData
+	}
+
+	registry := NewStrategyRegistry()
+	for _, strategy := range builtinStrategies(compute) {
+		require.NoError(t, registry.Register(strategy))
+	}
+	names := make([]string, 0)
+	for _, info := range registry.List() {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{StrategyCombined, StrategyFundamental, StrategyTechnical}, names)
+
+	stocks := []model.Stock{{TsCode: "000001.SZ"}, {TsCode: "600519.SH"}, {TsCode: "300750.SZ"}}
+
+	// 缺少技术面评分和无法评分的股票不入选
+	results, err := registry.Select(context.Background(), StrategyTechnical, stocks, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 72.5, results[0].Score)
+	assert.True(t, strings.HasPrefix(results[0].Reason, "技术面"))
+
+	results, err = registry.Select(context.Background(), StrategyCombined, stocks, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "600519.SH", results[0].TsCode)
+	assert.Equal(t, model.FactorScores{"composite": 80}, results[0].Factors)
+
+	// 评分出错时中止选股
+	failing := builtinStrategies(func(string) (*model.StockScore, error) { return nil, errors.New("db down") })
+	_, err = failing[0].Evaluate(context.Background(), stocks)
+	assert.Error(t, err)
+}
@@ -147,7 +147,7 @@ func (s *TaskService) UpdateTaskStatus(taskID string, status model.TaskStatus, p
 		updates["started_at"] = time.Now()
 	}
 
-	if status == model.TaskStatusCompleted || status == model.TaskStatusFailed {
+	if status == model.TaskStatusCompleted || status == model.TaskStatusFailed || status == model.TaskStatusCancelled {
 		updates["completed_at"] = time.Now()
 	}
 
@@ -208,15 +208,20 @@ func (s *TaskService) StartTask(taskID string, executor func(ctx context.Context
 			return
 		}
 
-		// 进度更新函数
+		// 进度更新函数，任务取消后不再更新，避免覆盖取消状态
 		updateProgress := func(progress int, message string) {
+			if ctx.Err() != nil {
+				return
+			}
 			if err := s.UpdateTaskStatus(taskID, model.TaskStatusRunning, progress, message); err != nil {
 				s.logger.Errorf("Failed to update task progress: %v", err)
 			}
 		}
 
-		// 执行任务
-		if err := executor(ctx, task, updateProgress); err != nil {
+		// 执行任务，被取消的任务由取消方更新状态
+		if err := executor(ctx, task, updateProgress); ctx.Err() != nil {
+			s.logger.Infof("Task %s cancelled", taskID)
+		} else if err != nil {
 			s.logger.Errorf("Task %s failed: %v", taskID, err)
 			s.UpdateTaskError(taskID, err.Error())
 		} else {
@@ -226,6 +231,9 @@ func (s *TaskService) StartTask(taskID string, executor func(ctx context.Context
 	}()
 }
 
+// taskCancelTimeout 取消任务后等待任务退出的最长时间
+const taskCancelTimeout = 5 * time.Second
+
 // CancelTask 取消任务
 func (s *TaskService) CancelTask(taskID string) error {
 	if runnerInterface, exists := s.runningTasks.Load(taskID); exists {
@@ -233,14 +241,107 @@ func (s *TaskService) CancelTask(taskID string) error {
 		runner.Cancel()
 
 		// 等待任务结束
-		select {
-		case <-runner.Done:
-		case <-time.After(5 * time.Second):
-			s.logger.Warnf("Task %s cancellation timeout", taskID)
-		}
+		s.waitTaskDone(taskID, runner, time.Now().Add(taskCancelTimeout))
 
-		return s.UpdateTaskStatus(taskID, model.TaskStatusFailed, 0, "任务已取消")
+		return s.UpdateTaskStatus(taskID, model.TaskStatusCancelled, 0, "任务已取消")
 	}
 
 	return fmt.Errorf("task %s is not running", taskID)
 }
+
+// CancelAllTasks 取消所有执行中和等待中的任务，用于维护窗口前停止后台工作，返回取消的任务数量
+// 执行中的任务先全部发出取消信号再统一等待退出；数据库中没有执行器的未结束任务（如进程重启前遗留的任务）一并标记为已取消
+func (s *TaskService) CancelAllTasks(reason string) (int64, error) {
+	runners := make(map[string]*TaskRunner)
+	s.runningTasks.Range(func(key, value interface{}) bool {
+		runner := value.(*TaskRunner)
+		runner.Cancel()
+		runners[key.(string)] = runner
+		return true
+	})
+
+	deadline := time.Now().Add(taskCancelTimeout)
+	ids := make([]string, 0, len(runners))
+	for taskID, runner := range runners {
+		s.waitTaskDone(taskID, runner, deadline)
+		ids = append(ids, taskID)
+	}
+
+	updates := map[string]interface{}{
+		"status":       model.TaskStatusCancelled,
+		"message":      reason,
+		"completed_at": time.Now(),
+		"updated_at":   time.Now(),
+	}
+
+	if len(ids) > 0 {
+		if err := s.db.Model(&model.Task{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return 0, fmt.Errorf("failed to mark running tasks cancelled: %w", err)
+		}
+	}
+
+	query := s.db.Model(&model.Task{}).Where("status IN ?", model.UnfinishedTaskStatuses)
+	if len(ids) > 0 {
+		query = query.Where("id NOT IN ?", ids)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return int64(len(ids)), fmt.Errorf("failed to mark queued tasks cancelled: %w", result.Error)
+	}
+
+	cancelled := int64(len(ids)) + result.RowsAffected
+	s.logger.Infof("Cancelled %d tasks (%d running, %d queued): %s", cancelled, len(ids), result.RowsAffected, reason)
+	return cancelled, nil
+}
+
+// waitTaskDone 等待任务执行器退出，超过deadline时记录警告后返回
+func (s *TaskService) waitTaskDone(taskID string, runner *TaskRunner, deadline time.Time) {
+	select {
+	case <-runner.Done:
+	case <-time.After(time.Until(deadline)):
+		s.logger.Warnf("Task %s cancellation timeout", taskID)
+	}
+}
+
+// CleanupFinishedTasks 删除结束时间早于cutoff的已完成和失败任务，等待中和执行中的任务不受影响，返回删除数量
+// 与model.Task.IsExpired的判断一致：结束时间取completed_at，为空时取updated_at
+func (s *TaskService) CleanupFinishedTasks(cutoff time.Time) (int64, error) {
+	result := s.db.Where("status IN ? AND COALESCE(completed_at, updated_at) < ?", model.FinishedTaskStatuses, cutoff).
+		Delete(&model.Task{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to cleanup finished tasks: %w", result.Error)
+	}
+
+	s.logger.Infof("Cleaned up %d finished tasks before %s", result.RowsAffected, cutoff.Format("2006-01-02 15:04:05"))
+	return result.RowsAffected, nil
+}
+
+// StartRetentionCleanup 启动任务保留期清理，每隔interval删除结束超过retention的任务，ctx取消后退出
+// retention<=0时不清理
+func (s *TaskService) StartRetentionCleanup(ctx context.Context, retention, interval time.Duration) {
+	if retention <= 0 {
+		s.logger.Info("Task retention cleanup disabled")
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	s.logger.Infof("Task retention cleanup started, retention: %v, interval: %v", retention, interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.CleanupFinishedTasks(time.Now().Add(-retention)); err != nil {
+				s.logger.Errorf("Task retention cleanup failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
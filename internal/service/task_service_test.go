@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newDryRunTaskService 创建不连接数据库的任务服务，数据库操作只生成SQL不执行
+func newDryRunTaskService(t *testing.T) *TaskService {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return &TaskService{db: db, logger: logrus.New()}
+}
+
+func TestTaskService_CancelAllTasks(t *testing.T) {
+	s := newDryRunTaskService(t)
+
+	stopped := make(chan string, 3)
+	for _, id := range []string{"task-1", "task-2", "task-3"} {
+		taskID := id
+		s.StartTask(taskID, func(ctx context.Context, task *model.Task, updateProgress func(int, string)) error {
+			<-ctx.Done()
+			stopped <- taskID
+			return ctx.Err()
+		})
+	}
+
+	cancelled, err := s.CancelAllTasks(model.TaskCancelReasonMaintenance)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cancelled)
+
+	// 所有执行中的任务都收到取消信号并已退出
+	got := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		select {
+		case id := <-stopped:
+			got[id] = true
+		case <-time.After(time.Second):
+			t.Fatal("task did not stop after cancel-all")
+		}
+	}
+	assert.Len(t, got, 3)
+
+	remaining := 0
+	s.runningTasks.Range(func(_, _ interface{}) bool {
+		remaining++
+		return true
+	})
+	assert.Zero(t, remaining)
+
+	// 没有执行中的任务时只处理数据库中遗留的任务
+	cancelled, err = s.CancelAllTasks(model.TaskCancelReasonMaintenance)
+	require.NoError(t, err)
+	assert.Zero(t, cancelled)
+}
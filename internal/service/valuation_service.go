@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"stock/internal/model"
+	"stock/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ValuationSortFields 估值排行支持的排序字段
+var ValuationSortFields = []string{"pe", "pb"}
+
+// ValuationFilter 估值筛选条件，为nil的阈值不参与筛选
+type ValuationFilter struct {
+	MaxPE  *float64 // 市盈率TTM上限，设置后亏损或无法计算市盈率的股票不入选
+	MaxPB  *float64 // 市净率上限，设置后无法计算市净率的股票不入选
+	SortBy string   // 排序字段：pe、pb，该指标无法计算的股票不入选
+	Asc    bool     // 是否升序
+	Limit  int      // 返回数量上限，<=0表示不限制
+}
+
+// ValuationService 个股估值服务，由最新收盘价和业绩报表计算市盈率TTM和市净率
+type ValuationService struct {
+	performanceRepo *repository.Performance
+	dailyRepo       *repository.DailyData
+	stockRepo       *repository.Stock
+}
+
+var (
+	valuationServiceInstance *ValuationService
+	valuationServiceOnce     sync.Once
+)
+
+// GetValuationService 获取个股估值服务单例
+func GetValuationService(db *gorm.DB) *ValuationService {
+	valuationServiceOnce.Do(func() {
+		valuationServiceInstance = &ValuationService{
+			performanceRepo: repository.NewPerformance(db),
+			dailyRepo:       repository.NewDailyData(db),
+			stockRepo:       repository.NewStock(db),
+		}
+	})
+	return valuationServiceInstance
+}
+
+// NewValuationService 创建个股估值服务 (保持向后兼容)
+func NewValuationService(db *gorm.DB) *ValuationService {
+	return GetValuationService(db)
+}
+
+// valuationMinReportDate 计算估值需要加载的最早报告期
+// 滚动每股收益需要上年年报和上年同期报表，年报披露前最新一期可能仍是上年三季报，因此从前年年初开始加载
+func valuationMinReportDate(now time.Time) int {
+	return (now.Year()-2)*10000 + 101
+}
+
+// GetValuation 获取单只股票的估值，没有业绩报表时返回nil
+func (s *ValuationService) GetValuation(tsCode string) (*model.Valuation, error) {
+	reports, err := s.performanceRepo.GetSinceReportDate([]string{tsCode}, valuationMinReportDate(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance reports of %s: %w", tsCode, err)
+	}
+	if len(reports[tsCode]) == 0 {
+		return nil, nil
+	}
+
+	latest, err := s.dailyRepo.GetLatestDailyDataBatch([]string{tsCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest daily data of %s: %w", tsCode, err)
+	}
+	stock, err := s.stockRepo.GetStockByTsCode(tsCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock %s: %w", tsCode, err)
+	}
+
+	var name string
+	var totalShares int64
+	if stock != nil {
+		name = stock.Name
+		totalShares = stock.TotalShares
+	}
+	var bar *model.DailyData
+	if data, ok := latest[tsCode]; ok {
+		bar = &data
+	}
+	valuation := model.NewValuation(tsCode, name, bar, reports[tsCode])
+	valuation.SetMarketCap(totalShares)
+	return &valuation, nil
+}
+
+// ScreenValuations 计算所有活跃股票的估值，按条件筛选并排序
+func (s *ValuationService) ScreenValuations(filter ValuationFilter) ([]model.Valuation, error) {
+	stocks, err := s.stockRepo.GetAllStocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+	reports, err := s.performanceRepo.GetSinceReportDate(nil, valuationMinReportDate(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance reports: %w", err)
+	}
+
+	tsCodes := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		if len(reports[stock.TsCode]) > 0 {
+			tsCodes = append(tsCodes, stock.TsCode)
+		}
+	}
+	latest, err := s.dailyRepo.GetLatestDailyDataBatch(tsCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest daily data: %w", err)
+	}
+
+	valuations := make([]model.Valuation, 0, len(tsCodes))
+	for _, stock := range stocks {
+		if len(reports[stock.TsCode]) == 0 {
+			continue
+		}
+		var bar *model.DailyData
+		if data, ok := latest[stock.TsCode]; ok {
+			bar = &data
+		}
+		valuation := model.NewValuation(stock.TsCode, stock.Name, bar, reports[stock.TsCode])
+		valuation.SetMarketCap(stock.TotalShares)
+		valuations = append(valuations, valuation)
+	}
+	return screenValuations(valuations, filter), nil
+}
+
+// screenValuations 按阈值筛选估值并排序，排序指标无法计算（为nil）的股票不入选
+func screenValuations(valuations []model.Valuation, filter ValuationFilter) []model.Valuation {
+	metric := func(v model.Valuation) *float64 { return v.PE }
+	if filter.SortBy == "pb" {
+		metric = func(v model.Valuation) *float64 { return v.PB }
+	}
+
+	results := make([]model.Valuation, 0)
+	for _, v := range valuations {
+		if metric(v) == nil {
+			continue
+		}
+		if filter.MaxPE != nil && (v.PE == nil || *v.PE > *filter.MaxPE) {
+			continue
+		}
+		if filter.MaxPB != nil && (v.PB == nil || *v.PB > *filter.MaxPB) {
+			continue
+		}
+		results = append(results, v)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := *metric(results[i]), *metric(results[j])
+		if a == b {
+			return results[i].TsCode < results[j].TsCode
+		}
+		if filter.Asc {
+			return a < b
+		}
+		return a > b
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results
+}
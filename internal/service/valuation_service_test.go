@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"stock/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func valuationOf(tsCode string, pe, pb *float64) model.Valuation {
+	status := model.PEStatusOK
+	if pe == nil {
+		status = model.PEStatusLoss
+	}
+	return model.Valuation{TsCode: tsCode, PE: pe, PB: pb, PEStatus: status}
+}
+
+func ratio(v float64) *float64 { return &v }
+
+func TestValuationMinReportDate(t *testing.T) {
+	assert.Equal(t, 20240101, valuationMinReportDate(time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)))
+}
+
+func TestScreenValuations(t *testing.T) {
+	valuations := []model.Valuation{
+		valuationOf("000001.SZ", ratio(12), ratio(1.2)),
+		valuationOf("000002.SZ", nil, ratio(0.8)), // 亏损
+		valuationOf("600000.SH", ratio(6), ratio(0.5)),
+		valuationOf("600036.SH", ratio(30), nil), // 每股净资产为负
+	}
+
+	// 按市盈率升序，亏损股票不参与排行
+	results := screenValuations(valuations, ValuationFilter{SortBy: "pe", Asc: true})
+	codes := make([]string, 0, len(results))
+	for _, r := range results {
+		codes = append(codes, r.TsCode)
+	}
+	assert.Equal(t, []string{"600000.SH", "000001.SZ", "600036.SH"}, codes)
+
+	// 按市净率降序，设置市盈率上限时亏损股票不入选
+	results = screenValuations(valuations, ValuationFilter{SortBy: "pb", MaxPE: ratio(20)})
+	codes = codes[:0]
+	for _, r := range results {
+		codes = append(codes, r.TsCode)
+	}
+	assert.Equal(t, []string{"000001.SZ", "600000.SH"}, codes)
+
+	results = screenValuations(valuations, ValuationFilter{SortBy: "pb", Asc: true, MaxPB: ratio(1), Limit: 1})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "600000.SH", results[0].TsCode)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SplitBatches 按batchSize将元素切分为连续的批次，batchSize<=0时整体作为一个批次
+func SplitBatches[T any](items []T, batchSize int) [][]T {
+	if len(items) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(items)
+	}
+
+	batches := make([][]T, 0, (len(items)+batchSize-1)/batchSize)
+	for i := 0; i < len(items); i += batchSize {
+		end := i + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		batches = append(batches, items[i:end])
+	}
+	return batches
+}
+
+// ExecuteInBatches 将元素分批后交给ConcurrentExecutor并发处理，单个批次失败不影响其他批次
+func ExecuteInBatches[T any](ctx context.Context, items []T, batchSize, concurrency int, timeout time.Duration,
+	fn func(ctx context.Context, batch []T) error) *ExecutionStats {
+	batches := SplitBatches(items, batchSize)
+	if len(batches) == 0 {
+		return &ExecutionStats{}
+	}
+
+	tasks := make([]Task, len(batches))
+	offset := 0
+	for i, batch := range batches {
+		batch := batch
+		tasks[i] = &SimpleTask{
+			ID:          fmt.Sprintf("batch-%d", i),
+			Description: fmt.Sprintf("处理第 %d-%d 条", offset, offset+len(batch)-1),
+			Func: func(ctx context.Context) error {
+				return fn(ctx, batch)
+			},
+		}
+		offset += len(batch)
+	}
+
+	executor := NewConcurrentExecutor(concurrency, timeout)
+	defer executor.Close()
+
+	_, stats := executor.ExecuteBatch(ctx, tasks)
+	return stats
+}
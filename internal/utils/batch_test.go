@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBatches(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+
+	batches := SplitBatches(items, 3)
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, batches)
+
+	assert.Equal(t, [][]int{items}, SplitBatches(items, 0))
+	assert.Equal(t, [][]int{items}, SplitBatches(items, 100))
+	assert.Nil(t, SplitBatches([]int{}, 3))
+}
+
+func TestExecuteInBatches_CoversAllBatches(t *testing.T) {
+	var codes []string
+	for i := 0; i < 1050; i++ {
+		codes = append(codes, fmt.Sprintf("%06d.SZ", i))
+	}
+
+	var (
+		mu      sync.Mutex
+		seen    = make(map[string]int)
+		running int32
+		peak    int32
+	)
+	stats := ExecuteInBatches(context.Background(), codes, 100, 4, 5*time.Second,
+		func(ctx context.Context, batch []string) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			assert.LessOrEqual(t, len(batch), 100)
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, code := range batch {
+				seen[code]++
+			}
+			return nil
+		})
+
+	require.NotNil(t, stats)
+	assert.Equal(t, 11, stats.TotalTasks)
+	assert.Equal(t, 11, stats.SuccessTasks)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4))
+
+	require.Len(t, seen, len(codes))
+	for _, code := range codes {
+		assert.Equal(t, 1, seen[code], "code %s should be processed exactly once", code)
+	}
+}
+
+func TestExecuteInBatches_FailedBatch(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	var processed int32
+	stats := ExecuteInBatches(context.Background(), items, 2, 2, time.Second,
+		func(ctx context.Context, batch []int) error {
+			atomic.AddInt32(&processed, int32(len(batch)))
+			if batch[0] == 3 {
+				return errors.New("batch failed")
+			}
+			return nil
+		})
+
+	assert.Equal(t, 3, stats.TotalTasks)
+	assert.Equal(t, 2, stats.SuccessTasks)
+	assert.Equal(t, 1, stats.FailedTasks)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&processed))
+
+	empty := ExecuteInBatches(context.Background(), nil, 2, 2, time.Second,
+		func(ctx context.Context, batch []int) error { return nil })
+	assert.Equal(t, 0, empty.TotalTasks)
+}
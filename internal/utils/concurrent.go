@@ -2,12 +2,15 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"sync"
 	"time"
 
 	logger "stock/internal/logger"
+
+	"golang.org/x/time/rate"
 )
 
 // ConcurrentExecutor 并发执行器，支持限制最大并发量
@@ -17,6 +20,8 @@ type ConcurrentExecutor struct {
 	wg             sync.WaitGroup
 	logger         *logger.Logger
 	timeout        time.Duration
+	limiter        *rate.Limiter       // 任务启动限流，为nil时不限流
+	backoff        *concurrencyBackoff // 数据库连接数耗尽时降低并发，为nil时不调整
 }
 
 // Task 任务接口
@@ -86,6 +91,38 @@ func NewConcurrentExecutor(maxConcurrency int, timeout time.Duration) *Concurren
 	}
 }
 
+// SetRateLimit 设置每秒最多启动的任务数，<=0表示不限流
+// 并发数限制同时执行的任务数，限流进一步控制任务启动的速率，避免短任务在高并发下把请求集中打到数据源
+func (ce *ConcurrentExecutor) SetRateLimit(tasksPerSecond float64) {
+	if tasksPerSecond <= 0 {
+		ce.limiter = nil
+		return
+	}
+	burst := int(tasksPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	ce.limiter = rate.NewLimiter(rate.Limit(tasksPerSecond), burst)
+}
+
+// SetDBBackoff 开启数据库过载退避，任务返回MySQL连接数耗尽错误时有效并发减半，最低降到minConcurrency
+// 连续成功一批任务后逐档恢复到最大并发；minConcurrency<=0表示关闭
+func (ce *ConcurrentExecutor) SetDBBackoff(minConcurrency int) {
+	if minConcurrency <= 0 {
+		ce.backoff = nil
+		return
+	}
+	ce.backoff = newConcurrencyBackoff(ce.maxConcurrency, minConcurrency)
+}
+
+// EffectiveConcurrency 当前有效并发数，未开启数据库过载退避时为最大并发数
+func (ce *ConcurrentExecutor) EffectiveConcurrency() int {
+	if ce.backoff == nil {
+		return ce.maxConcurrency
+	}
+	return ce.backoff.current()
+}
+
 // Execute 执行单个任务
 func (ce *ConcurrentExecutor) Execute(ctx context.Context, task Task) *TaskResult {
 	result := &TaskResult{
@@ -104,6 +141,30 @@ func (ce *ConcurrentExecutor) Execute(ctx context.Context, task Task) *TaskResul
 		return result
 	}
 
+	// 等待数据库过载退避放行，放行后无论任务如何结束（限流等待失败、panic）都要归还
+	// 任务没有正常结束时按非数据库错误归还，不计为成功
+	backoffErr := errTaskNotFinished
+	if ce.backoff != nil {
+		if err := ce.backoff.acquire(ctx); err != nil {
+			result.StartTime = time.Now()
+			result.Error = err
+			result.EndTime = time.Now()
+			return result
+		}
+		defer func() { ce.releaseBackoff(task, backoffErr) }()
+	}
+
+	// 等待限流，等待时间超过ctx的截止时间时Wait立即失败
+	if ce.limiter != nil {
+		if err := ce.limiter.Wait(ctx); err != nil {
+			backoffErr = err
+			result.StartTime = time.Now()
+			result.Error = err
+			result.EndTime = time.Now()
+			return result
+		}
+	}
+
 	// 创建带超时的上下文
 	taskCtx, cancel := context.WithTimeout(ctx, ce.timeout)
 	defer cancel()
@@ -112,6 +173,7 @@ func (ce *ConcurrentExecutor) Execute(ctx context.Context, task Task) *TaskResul
 	result.StartTime = time.Now()
 	// 执行任务
 	err := task.Execute(taskCtx)
+	backoffErr = err
 
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -127,6 +189,19 @@ func (ce *ConcurrentExecutor) Execute(ctx context.Context, task Task) *TaskResul
 	return result
 }
 
+// errTaskNotFinished 任务放行后没有正常结束（限流等待失败或panic），归还数据库退避并发时使用
+var errTaskNotFinished = errors.New("task did not finish")
+
+// releaseBackoff 任务结束后归还数据库退避的并发，并按任务结果调整有效并发数
+func (ce *ConcurrentExecutor) releaseBackoff(task Task, err error) {
+	before, after := ce.backoff.release(err)
+	if after < before {
+		ce.logger.Warnf("数据库连接数耗尽，并发数从 %d 降低到 %d: %s", before, after, task.GetID())
+	} else if after > before {
+		ce.logger.Infof("数据库写入恢复正常，并发数从 %d 恢复到 %d", before, after)
+	}
+}
+
 // ExecuteBatch 批量执行任务
 func (ce *ConcurrentExecutor) ExecuteBatch(ctx context.Context, tasks []Task) ([]*TaskResult, *ExecutionStats) {
 	if len(tasks) == 0 {
@@ -237,6 +312,7 @@ func (ce *ConcurrentExecutor) GetStats() map[string]interface{} {
 		"timeout":         ce.timeout.String(),
 		"available_slots": len(ce.semaphore),
 		"used_slots":      ce.maxConcurrency - len(ce.semaphore),
+		"effective_slots": ce.EffectiveConcurrency(),
 	}
 }
 
@@ -3,6 +3,8 @@ package utils
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -244,6 +246,108 @@ func TestConcurrentExecutor_ConcurrencyLimit(t *testing.T) {
 		"总时间 %v 应该至少为 600ms，说明并发限制生效", totalTime)
 }
 
+func TestConcurrentExecutor_RateLimit(t *testing.T) {
+	executor := NewConcurrentExecutor(10, 5*time.Second)
+	defer executor.Close()
+	executor.SetRateLimit(10) // 每秒10个，初始可立即启动10个
+
+	tasks := make([]Task, 15)
+	for i := range tasks {
+		tasks[i] = &MockTask{ID: fmt.Sprintf("rate-%d", i), Duration: time.Millisecond}
+	}
+
+	start := time.Now()
+	_, stats := executor.ExecuteBatch(context.Background(), tasks)
+	totalTime := time.Since(start)
+
+	assert.Equal(t, 15, stats.SuccessTasks)
+	// 超出突发量的5个任务需要按每秒10个的速率等待
+	assert.True(t, totalTime >= 400*time.Millisecond, "总时间 %v 应该至少为 400ms，说明限流生效", totalTime)
+
+	// 取消限流后不再等待
+	executor.SetRateLimit(0)
+	start = time.Now()
+	executor.ExecuteBatch(context.Background(), tasks)
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestConcurrentExecutor_DBBackoff(t *testing.T) {
+	executor := NewConcurrentExecutor(8, 5*time.Second)
+	defer executor.Close()
+	executor.SetDBBackoff(2)
+
+	// 任务路径上的错误按%v包装，仍能识别出连接数耗尽
+	tooMany := &MockTask{ID: "db-overload", ShouldFail: true,
+		FailError: fmt.Errorf("保存日K线失败: %v", errors.New("Error 1040 (08004): Too many connections"))}
+	executor.Execute(context.Background(), tooMany)
+	assert.Equal(t, 4, executor.EffectiveConcurrency())
+	executor.Execute(context.Background(), tooMany)
+	executor.Execute(context.Background(), tooMany)
+	assert.Equal(t, 2, executor.EffectiveConcurrency(), "不低于最小并发")
+
+	// 其他错误不影响并发
+	executor.Execute(context.Background(), &MockTask{ID: "other", ShouldFail: true, FailError: errors.New("HTTP 502")})
+	assert.Equal(t, 2, executor.EffectiveConcurrency())
+
+	// 降低后同时执行的任务数不超过有效并发
+	var running, peak int32
+	tasks := make([]Task, 6)
+	for i := range tasks {
+		tasks[i] = &SimpleTask{ID: fmt.Sprintf("backoff-%d", i), Func: func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}}
+	}
+	_, stats := executor.ExecuteBatch(context.Background(), tasks)
+	assert.Equal(t, 6, stats.SuccessTasks)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+
+	// 写入恢复正常后逐档恢复到最大并发
+	for i := 0; i < 2*dbBackoffRecoverAfter; i++ {
+		executor.Execute(context.Background(), &MockTask{ID: "ok"})
+	}
+	assert.Equal(t, 8, executor.EffectiveConcurrency())
+
+	executor.SetDBBackoff(0)
+	assert.Equal(t, 8, executor.EffectiveConcurrency())
+}
+
+func TestConcurrentExecutor_DBBackoffReleasedOnEarlyReturn(t *testing.T) {
+	executor := NewConcurrentExecutor(2, 5*time.Second)
+	defer executor.Close()
+	executor.SetDBBackoff(1)
+	executor.SetRateLimit(1)
+
+	// 用掉限流的初始令牌，下一个令牌要等1秒，超过ctx的截止时间时Wait立即失败
+	executor.Execute(context.Background(), &MockTask{ID: "first"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result := executor.Execute(ctx, &MockTask{ID: "rate-limited"})
+	require.Error(t, result.Error)
+	assert.NoError(t, ctx.Err(), "限流拒绝时ctx尚未结束")
+	assert.Equal(t, 0, executor.backoff.active)
+
+	// 任务panic时同样归还
+	executor.SetRateLimit(0)
+	assert.Panics(t, func() {
+		executor.Execute(context.Background(), &SimpleTask{ID: "panic", Func: func(ctx context.Context) error { panic("boom") }})
+	})
+	assert.Equal(t, 0, executor.backoff.active)
+	assert.Equal(t, 2, executor.EffectiveConcurrency())
+
+	// 归还后后续任务不会因并发名额耗尽而阻塞
+	result = executor.Execute(context.Background(), &MockTask{ID: "after"})
+	assert.True(t, result.Success)
+}
+
 // BenchmarkConcurrentExecutor 性能测试
 func BenchmarkConcurrentExecutor(b *testing.B) {
 	executor := NewConcurrentExecutor(4, 5*time.Second)
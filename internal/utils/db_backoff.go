@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// dbBackoffRecoverAfter 并发被降低后，连续成功多少个任务把并发恢复一档（翻倍）
+const dbBackoffRecoverAfter = 20
+
+// IsTooManyConnections 判断错误是否为MySQL连接数耗尽（Error 1040: Too many connections）
+// 任务路径上的错误经过多层fmt.Errorf包装，有的使用%v，按错误信息匹配
+func IsTooManyConnections(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "too many connections")
+}
+
+// concurrencyBackoff 数据库过载时动态降低执行器的有效并发数
+// 任务返回连接数耗尽错误时有效并发减半（不低于最小并发），之后连续成功dbBackoffRecoverAfter个任务恢复一档，直到回到最大并发
+type concurrencyBackoff struct {
+	mu        sync.Mutex
+	max       int
+	min       int
+	limit     int           // 当前有效并发数
+	active    int           // 正在执行的任务数
+	successes int           // 上次调整后连续成功的任务数
+	changed   chan struct{} // 有任务结束时关闭并替换，唤醒等待的任务
+}
+
+func newConcurrencyBackoff(maxConcurrency, minConcurrency int) *concurrencyBackoff {
+	if minConcurrency > maxConcurrency {
+		minConcurrency = maxConcurrency
+	}
+	return &concurrencyBackoff{
+		max:     maxConcurrency,
+		min:     minConcurrency,
+		limit:   maxConcurrency,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire 等待正在执行的任务数低于有效并发数
+func (b *concurrencyBackoff) acquire(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.active < b.limit {
+			b.active++
+			b.mu.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release 任务结束，按任务结果调整有效并发数
+// 返回调整前后的并发数，未调整时两者相等
+func (b *concurrencyBackoff) release(err error) (before, after int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.active--
+	before = b.limit
+	switch {
+	case IsTooManyConnections(err):
+		b.successes = 0
+		b.limit = max(b.limit/2, b.min)
+	case err == nil && b.limit < b.max:
+		b.successes++
+		if b.successes >= dbBackoffRecoverAfter {
+			b.successes = 0
+			b.limit = min(b.limit*2, b.max)
+		}
+	}
+
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return before, b.limit
+}
+
+// current 当前有效并发数
+func (b *concurrencyBackoff) current() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
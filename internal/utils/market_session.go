@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMarketCloseTime 默认的收盘后数据定型时刻，A股15:00收盘，收盘价和成交量在盘后稍晚才稳定
+const DefaultMarketCloseTime = "15:30"
+
+// DefaultDataFinalAfter 默认的当日K线成为最终数据的时刻，此前保存的当日K线视为临时数据
+const DefaultDataFinalAfter = "18:00"
+
+// IsTradingDay 判断是否为交易日（周一到周五，排除国庆和五一假期，简化版本，不考虑其他节假日）
+func IsTradingDay(date time.Time) bool {
+	weekday := date.Weekday()
+	// 周一到周五为工作日
+	if !(weekday >= time.Monday && weekday <= time.Friday) {
+		return false
+	}
+
+	if date.Month() == time.October && date.Day() <= 7 { // 国庆
+		return false
+	}
+
+	if date.Month() == time.May && date.Day() <= 4 { // 五一
+		return false
+	}
+
+	return true
+}
+
+// MarketSession 交易时段，判断某个交易日的行情是否已经收盘定型
+// 收盘后当日K线先有盘后数据，复权收盘价和最终成交量在之后逐步定型：收盘时刻之后、数据定型时刻之前保存的K线仍是临时数据
+type MarketSession struct {
+	closeAt  time.Duration  // 收盘后当日K线可用的时刻，相对当天零点
+	finalAt  time.Duration  // 当日K线成为最终数据的时刻，相对当天零点，不早于closeAt
+	location *time.Location // 交易所所在时区
+}
+
+// NewMarketSession 创建交易时段，closeTime为HH:MM格式的收盘后数据定型时刻，location为nil时使用应用时区
+func NewMarketSession(closeTime string, location *time.Location) (*MarketSession, error) {
+	if closeTime == "" {
+		closeTime = DefaultMarketCloseTime
+	}
+	closeAt, err := parseClock(closeTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid market close time %q, expected HH:MM: %w", closeTime, err)
+	}
+	if location == nil {
+		location = AppLocation()
+	}
+	return &MarketSession{
+		closeAt:  closeAt,
+		finalAt:  closeAt,
+		location: location,
+	}, nil
+}
+
+// SetDataFinalAfter 设置当日K线成为最终数据的时刻，HH:MM格式，为空时与收盘时刻相同；不能早于收盘时刻
+// 应在开始采集前调用
+func (s *MarketSession) SetDataFinalAfter(finalAfter string) error {
+	if finalAfter == "" {
+		s.finalAt = s.closeAt
+		return nil
+	}
+	finalAt, err := parseClock(finalAfter)
+	if err != nil {
+		return fmt.Errorf("invalid data final after time %q, expected HH:MM: %w", finalAfter, err)
+	}
+	if finalAt < s.closeAt {
+		return fmt.Errorf("data final after time %q is earlier than the market close time", finalAfter)
+	}
+	s.finalAt = finalAt
+	return nil
+}
+
+// parseClock 解析HH:MM格式的时刻，返回相对当天零点的时长
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// CloseAt 获取date所在日期（按交易所时区）收盘后数据定型的时刻
+func (s *MarketSession) CloseAt(date time.Time) time.Time {
+	date = date.In(s.location)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, s.location).Add(s.closeAt)
+}
+
+// FinalAt 获取date所在日期（按交易所时区）当日K线成为最终数据的时刻
+func (s *MarketSession) FinalAt(date time.Time) time.Time {
+	date = date.In(s.location)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, s.location).Add(s.finalAt)
+}
+
+// HasClosed 判断date当天的行情在now时是否已经收盘
+// 交易日需到达收盘时刻；非交易日没有行情，视为已收盘
+func (s *MarketSession) HasClosed(date, now time.Time) bool {
+	if !IsTradingDay(date.In(s.location)) {
+		return true
+	}
+	return !now.Before(s.CloseAt(date))
+}
+
+// IsFinal 判断updatedAt时保存的date当天的K线是否为最终数据，最终数据无需再采集
+// 交易日需在数据定型时刻及之后保存；收盘后、定型前保存的是临时数据，之后仍需重新采集；非交易日没有行情，视为最终数据
+func (s *MarketSession) IsFinal(date, updatedAt time.Time) bool {
+	if !IsTradingDay(date.In(s.location)) {
+		return true
+	}
+	return !updatedAt.Before(s.FinalAt(date))
+}
+
+// IsFresh 判断updatedAt时保存的行情在now时是否仍然有效，无需重新采集
+// 保存距今超过maxAge，或保存之后经过了某个交易日的数据定型时刻（定型前保存的数据不是最终数据）时视为过期；maxAge<=0时总是过期
+func (s *MarketSession) IsFresh(updatedAt, now time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 || updatedAt.IsZero() || now.Sub(updatedAt) > maxAge || now.Before(updatedAt) {
+		return false
+	}
+
+	for day := updatedAt.In(s.location); !s.FinalAt(day).After(now); day = day.AddDate(0, 0, 1) {
+		finalAt := s.FinalAt(day)
+		if IsTradingDay(day) && finalAt.After(updatedAt) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTradingDay(t *testing.T) {
+	assert.True(t, IsTradingDay(time.Date(2024, 6, 11, 0, 0, 0, 0, time.Local)))  // 周二
+	assert.False(t, IsTradingDay(time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local))) // 周六
+	assert.False(t, IsTradingDay(time.Date(2024, 10, 7, 0, 0, 0, 0, time.Local))) // 国庆
+	assert.False(t, IsTradingDay(time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)))  // 五一
+	assert.True(t, IsTradingDay(time.Date(2024, 10, 8, 0, 0, 0, 0, time.Local)))  // 节后首个交易日
+}
+
+func TestMarketSession_HasClosedAroundCloseTime(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	session, err := NewMarketSession("15:30", shanghai)
+	require.NoError(t, err)
+
+	tradeDate := time.Date(2024, 6, 11, 0, 0, 0, 0, shanghai)
+	assert.Equal(t, time.Date(2024, 6, 11, 15, 30, 0, 0, shanghai), session.CloseAt(tradeDate))
+
+	// 盘中和收盘后数据定型前都不是最终数据
+	assert.False(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 10, 0, 0, 0, shanghai)))
+	assert.False(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 15, 0, 0, 0, shanghai)))
+	assert.False(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 15, 29, 59, 0, shanghai)))
+	// 到达定型时刻及之后为最终数据
+	assert.True(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 15, 30, 0, 0, shanghai)))
+	assert.True(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 20, 0, 0, 0, shanghai)))
+	assert.True(t, session.HasClosed(tradeDate, time.Date(2024, 6, 12, 9, 0, 0, 0, shanghai)))
+
+	// 按交易所时区比较：UTC 07:29 为北京时间 15:29，UTC 07:30 为北京时间 15:30
+	assert.False(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 7, 29, 0, 0, time.UTC)))
+	assert.True(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 7, 30, 0, 0, time.UTC)))
+
+	// 非交易日没有行情，视为已收盘
+	saturday := time.Date(2024, 6, 15, 0, 0, 0, 0, shanghai)
+	assert.True(t, session.HasClosed(saturday, time.Date(2024, 6, 15, 10, 0, 0, 0, shanghai)))
+}
+
+func TestNewMarketSession(t *testing.T) {
+	// 未指定时区时使用应用时区
+	session, err := NewMarketSession("", nil)
+	require.NoError(t, err)
+	tradeDate := time.Date(2024, 6, 11, 0, 0, 0, 0, AppLocation())
+	assert.Equal(t, time.Date(2024, 6, 11, 15, 30, 0, 0, AppLocation()), session.CloseAt(tradeDate))
+
+	for _, invalid := range []string{"16:00:00", "25:00", "4pm"} {
+		_, err := NewMarketSession(invalid, nil)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMarketSession_IsFresh(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	session, err := NewMarketSession("15:30", shanghai)
+	require.NoError(t, err)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, shanghai)
+	}
+
+	// 盘中刚保存的当期K线在阈值内有效，超过阈值需重新采集
+	assert.True(t, session.IsFresh(at(11, 10, 0), at(11, 10, 30), time.Hour))
+	assert.False(t, session.IsFresh(at(11, 10, 0), at(11, 11, 1), time.Hour))
+
+	// 收盘前保存的数据在收盘定型后需要采集最终数据，即使仍在阈值内
+	assert.False(t, session.IsFresh(at(11, 15, 0), at(11, 15, 40), time.Hour))
+	// 收盘后保存的数据在阈值内有效
+	assert.True(t, session.IsFresh(at(11, 15, 45), at(11, 16, 30), time.Hour))
+
+	// 周五收盘后保存，周末期间没有新的收盘，阈值足够长时一直有效
+	assert.True(t, session.IsFresh(at(14, 16, 0), at(16, 20, 0), 72*time.Hour))
+	// 经过下一个交易日的收盘后过期
+	assert.False(t, session.IsFresh(at(14, 16, 0), at(17, 16, 0), 96*time.Hour))
+
+	// 未配置阈值、没有保存时间或保存时间晚于当前时间时总是重新采集
+	assert.False(t, session.IsFresh(at(11, 10, 0), at(11, 10, 30), 0))
+	assert.False(t, session.IsFresh(time.Time{}, at(11, 10, 30), time.Hour))
+	assert.False(t, session.IsFresh(at(11, 11, 0), at(11, 10, 30), time.Hour))
+}
+
+func TestMarketSession_IsFinalAroundDataFinalAfter(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	session, err := NewMarketSession("15:30", shanghai)
+	require.NoError(t, err)
+	require.NoError(t, session.SetDataFinalAfter("18:00"))
+
+	tradeDate := time.Date(2024, 6, 11, 0, 0, 0, 0, shanghai)
+	assert.Equal(t, time.Date(2024, 6, 11, 18, 0, 0, 0, shanghai), session.FinalAt(tradeDate))
+
+	// 收盘后、定型前保存的是临时数据
+	at := func(hour, min, sec int) time.Time { return time.Date(2024, 6, 11, hour, min, sec, 0, shanghai) }
+	assert.False(t, session.IsFinal(tradeDate, at(14, 0, 0)))
+	assert.True(t, session.HasClosed(tradeDate, at(15, 30, 0)))
+	assert.False(t, session.IsFinal(tradeDate, at(15, 30, 0)))
+	assert.False(t, session.IsFinal(tradeDate, at(17, 59, 59)))
+	// 到达定型时刻及之后保存的为最终数据
+	assert.True(t, session.IsFinal(tradeDate, at(18, 0, 0)))
+	assert.True(t, session.IsFinal(tradeDate, at(21, 0, 0)))
+	assert.True(t, session.IsFinal(tradeDate, time.Date(2024, 6, 12, 9, 0, 0, 0, shanghai)))
+	// 按交易所时区比较：UTC 09:59 为北京时间 17:59
+	assert.False(t, session.IsFinal(tradeDate, time.Date(2024, 6, 11, 9, 59, 0, 0, time.UTC)))
+	assert.True(t, session.IsFinal(tradeDate, time.Date(2024, 6, 11, 10, 0, 0, 0, time.UTC)))
+	// 非交易日没有行情
+	assert.True(t, session.IsFinal(time.Date(2024, 6, 15, 0, 0, 0, 0, shanghai), at(10, 0, 0)))
+
+	// 定型前保存的当期K线在经过定型时刻后过期
+	assert.True(t, session.IsFresh(at(16, 0, 0), at(17, 30, 0), 2*time.Hour))
+	assert.False(t, session.IsFresh(at(16, 0, 0), at(18, 0, 0), 4*time.Hour))
+	assert.True(t, session.IsFresh(at(18, 5, 0), at(19, 0, 0), 4*time.Hour))
+}
+
+func TestMarketSession_SetDataFinalAfter(t *testing.T) {
+	session, err := NewMarketSession("15:30", nil)
+	require.NoError(t, err)
+	tradeDate := time.Date(2024, 6, 11, 0, 0, 0, 0, AppLocation())
+
+	// 未设置时与收盘时刻相同
+	assert.Equal(t, session.CloseAt(tradeDate), session.FinalAt(tradeDate))
+	assert.True(t, session.IsFinal(tradeDate, session.CloseAt(tradeDate)))
+
+	require.NoError(t, session.SetDataFinalAfter("19:30"))
+	assert.Equal(t, time.Date(2024, 6, 11, 19, 30, 0, 0, AppLocation()), session.FinalAt(tradeDate))
+	require.NoError(t, session.SetDataFinalAfter(""))
+	assert.Equal(t, session.CloseAt(tradeDate), session.FinalAt(tradeDate))
+
+	for _, invalid := range []string{"18:00:00", "6pm", "15:00"} {
+		assert.Error(t, session.SetDataFinalAfter(invalid), invalid)
+	}
+	// 设置失败时保留原有的定型时刻
+	assert.Equal(t, session.CloseAt(tradeDate), session.FinalAt(tradeDate))
+}
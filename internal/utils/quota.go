@@ -0,0 +1,27 @@
+package utils
+
+// SelectWithQuota 按每日配额挑选需要处理的元素
+// 优先元素（isPriority返回true）始终全部入选并排在最前，即使数量超过配额也不会被截断；
+// 配额剩余部分再由其余元素按原有顺序经needed过滤后补足（quota<=0表示不限制）
+func SelectWithQuota[T any](items []T, quota int, isPriority func(T) bool, needed func(T) bool) []T {
+	var selected, rest []T
+	for _, item := range items {
+		if isPriority != nil && isPriority(item) {
+			selected = append(selected, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+
+	for _, item := range rest {
+		if quota > 0 && len(selected) >= quota {
+			break
+		}
+		if needed != nil && !needed(item) {
+			continue
+		}
+		selected = append(selected, item)
+	}
+
+	return selected
+}
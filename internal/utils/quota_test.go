@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type quotaItem struct {
+	code     string
+	priority bool
+	updated  bool
+}
+
+func buildQuotaItems(total int, priorityCodes ...string) []quotaItem {
+	prioritySet := make(map[string]bool, len(priorityCodes))
+	for _, code := range priorityCodes {
+		prioritySet[code] = true
+	}
+
+	items := make([]quotaItem, 0, total)
+	for i := 0; i < total; i++ {
+		code := fmt.Sprintf("%06d.SZ", i)
+		items = append(items, quotaItem{code: code, priority: prioritySet[code]})
+	}
+	return items
+}
+
+func TestSelectWithQuota_PriorityNeverStarved(t *testing.T) {
+	// 优先股票排在列表末尾，最容易被每日100条的配额挤掉
+	items := buildQuotaItems(5000, "004998.SZ", "004999.SZ")
+
+	selected := SelectWithQuota(items, 100,
+		func(i quotaItem) bool { return i.priority },
+		func(i quotaItem) bool { return true })
+
+	require.Len(t, selected, 100)
+	assert.Equal(t, "004998.SZ", selected[0].code)
+	assert.Equal(t, "004999.SZ", selected[1].code)
+	assert.Equal(t, "000000.SZ", selected[2].code)
+}
+
+func TestSelectWithQuota_PriorityIgnoresNeeded(t *testing.T) {
+	items := buildQuotaItems(10, "000003.SZ")
+	for i := range items {
+		items[i].updated = true // 所有股票近期都已更新
+	}
+
+	selected := SelectWithQuota(items, 100,
+		func(i quotaItem) bool { return i.priority },
+		func(i quotaItem) bool { return !i.updated })
+
+	require.Len(t, selected, 1)
+	assert.Equal(t, "000003.SZ", selected[0].code)
+}
+
+func TestSelectWithQuota_PriorityExceedsQuota(t *testing.T) {
+	items := buildQuotaItems(10, "000001.SZ", "000002.SZ", "000003.SZ")
+
+	selected := SelectWithQuota(items, 2,
+		func(i quotaItem) bool { return i.priority },
+		nil)
+
+	// 优先股票全部保留，不再补充普通股票
+	require.Len(t, selected, 3)
+	for _, item := range selected {
+		assert.True(t, item.priority)
+	}
+}
+
+func TestSelectWithQuota_Unlimited(t *testing.T) {
+	items := buildQuotaItems(10)
+
+	selected := SelectWithQuota(items, 0, nil,
+		func(i quotaItem) bool { return i.code != "000005.SZ" })
+
+	assert.Len(t, selected, 9)
+}
@@ -0,0 +1,25 @@
+package utils
+
+// Semaphore 限制同时执行的操作数，用于把并发任务中的某一段（如写数据库）限制在比任务并发更小的范围内
+// nil表示不限制
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore 创建最多允许n个操作同时执行的信号量，n<=0时返回nil，即不限制
+func NewSemaphore(n int) *Semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Do 获取执行名额后执行fn，名额用完时等待其他操作结束
+func (s *Semaphore) Do(fn func() error) error {
+	if s == nil {
+		return fn()
+	}
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+	return fn()
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runConcurrently 并发执行n次fn，返回同时执行的最大数量
+func runConcurrently(s *Semaphore, n int) int32 {
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.Do(func() error {
+				cur := running.Add(1)
+				for {
+					old := peak.Load()
+					if cur <= old || peak.CompareAndSwap(old, cur) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	return peak.Load()
+}
+
+func TestSemaphore_LimitsConcurrency(t *testing.T) {
+	assert.LessOrEqual(t, runConcurrently(NewSemaphore(3), 20), int32(3))
+}
+
+func TestSemaphore_NilIsUnlimited(t *testing.T) {
+	s := NewSemaphore(0)
+	assert.Nil(t, s)
+	assert.Greater(t, runConcurrently(s, 20), int32(3))
+}
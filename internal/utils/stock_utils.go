@@ -24,10 +24,10 @@ func ConvertToTsCode(code string) string {
 	return code
 }
 
-// ParseTradeDate 解析交易日期
+// ParseTradeDate 解析交易日期，返回应用时区的当天零点
 func ParseTradeDate(date int) (time.Time, error) {
 	tradeDateStr := fmt.Sprintf("%d", date)
-	tradeDate, err := time.ParseInLocation("20060102", tradeDateStr, time.Local)
+	tradeDate, err := time.ParseInLocation("20060102", tradeDateStr, AppLocation())
 	if err != nil {
 		return time.Time{}, fmt.Errorf("解析交易日期失败: %v", err)
 	}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrDataInconsistency 严格模式下发现数据不一致（非法K线、解析告警、记录数不匹配、数据缺口）时返回的错误
+var ErrDataInconsistency = errors.New("data inconsistency")
+
+// strictMode 是否启用严格模式，启动时按配置设置
+// 默认的宽松模式下数据不一致只记录日志并继续，严格模式下中止同步，用于不能容忍数据静默损坏的研究部署
+var strictMode atomic.Bool
+
+// SetStrictMode 设置是否启用严格模式
+func SetStrictMode(enabled bool) {
+	strictMode.Store(enabled)
+}
+
+// StrictMode 是否启用严格模式
+func StrictMode() bool {
+	return strictMode.Load()
+}
+
+// StrictViolation 报告一处数据不一致：严格模式下返回包装ErrDataInconsistency的错误，宽松模式下返回nil，由调用方记录日志后继续
+func StrictViolation(msg string) error {
+	if !StrictMode() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDataInconsistency, msg)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	// 内置时区数据库，精简镜像中没有系统时区文件时也能加载Asia/Shanghai
+	_ "time/tzdata"
+)
+
+// DefaultAppTimezone 默认的应用时区，交易日期和收盘时刻按交易所所在的北京时间计算
+const DefaultAppTimezone = "Asia/Shanghai"
+
+// appLocation 应用时区，启动时按配置设置，交易日期相关的计算不依赖服务器的本地时区
+var appLocation atomic.Pointer[time.Location]
+
+func init() {
+	location, err := time.LoadLocation(DefaultAppTimezone)
+	if err != nil {
+		location = time.FixedZone("CST", 8*3600)
+	}
+	appLocation.Store(location)
+}
+
+// SetAppTimezone 设置应用时区，name为IANA时区名称（如Asia/Shanghai），为空时使用默认时区
+func SetAppTimezone(name string) error {
+	if name == "" {
+		name = DefaultAppTimezone
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	appLocation.Store(location)
+	return nil
+}
+
+// AppLocation 获取应用时区
+func AppLocation() *time.Location {
+	return appLocation.Load()
+}
+
+// AppNow 获取应用时区的当前时间
+func AppNow() time.Time {
+	return time.Now().In(AppLocation())
+}
+
+// TradeDateOf 获取t在应用时区的日期，YYYYMMDD格式
+func TradeDateOf(t time.Time) int {
+	t = t.In(AppLocation())
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+// TodayTradeDate 获取应用时区的今天日期，YYYYMMDD格式
+func TodayTradeDate() int {
+	return TradeDateOf(time.Now())
+}
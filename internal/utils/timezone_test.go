@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withLocalTimezone 临时把进程本地时区设为name，模拟部署在其他时区的服务器
+func withLocalTimezone(t *testing.T, name string) {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	original := time.Local
+	time.Local = location
+	t.Cleanup(func() { time.Local = original })
+}
+
+func TestAppLocation_Default(t *testing.T) {
+	assert.Equal(t, DefaultAppTimezone, AppLocation().String())
+}
+
+func TestSetAppTimezone(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetAppTimezone("")) })
+
+	require.NoError(t, SetAppTimezone("UTC"))
+	assert.Equal(t, "UTC", AppLocation().String())
+
+	// 为空时恢复默认时区
+	require.NoError(t, SetAppTimezone(""))
+	assert.Equal(t, DefaultAppTimezone, AppLocation().String())
+
+	// 无效时区返回错误，保留原时区
+	assert.Error(t, SetAppTimezone("Mars/Olympus"))
+	assert.Equal(t, DefaultAppTimezone, AppLocation().String())
+}
+
+func TestTradeDateOf_NonLocalServer(t *testing.T) {
+	withLocalTimezone(t, "UTC")
+
+	// UTC 2024-06-11 17:30 为北京时间 2024-06-12 01:30，交易日期应为北京时间的12日
+	now := time.Date(2024, 6, 11, 17, 30, 0, 0, time.UTC)
+	assert.Equal(t, 20240611, now.Year()*10000+int(now.Month())*100+now.Day())
+	assert.Equal(t, 20240612, TradeDateOf(now))
+
+	// UTC 2024-06-11 15:59 为北京时间 2024-06-11 23:59，仍为11日
+	assert.Equal(t, 20240611, TradeDateOf(time.Date(2024, 6, 11, 15, 59, 0, 0, time.UTC)))
+
+	// 部署在美国东部时区时结果相同
+	withLocalTimezone(t, "America/New_York")
+	assert.Equal(t, 20240612, TradeDateOf(now))
+	assert.Equal(t, TradeDateOf(time.Now()), TodayTradeDate())
+}
+
+func TestParseTradeDate_NonLocalServer(t *testing.T) {
+	withLocalTimezone(t, "UTC")
+
+	tradeDate, err := ParseTradeDate(20240611)
+	require.NoError(t, err)
+	assert.Equal(t, AppLocation(), tradeDate.Location())
+	assert.Equal(t, 20240611, TradeDateOf(tradeDate))
+
+	// 按应用时区判断收盘：北京时间15:30定型，对应UTC 07:30
+	session, err := NewMarketSession("15:30", nil)
+	require.NoError(t, err)
+	assert.False(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 7, 29, 0, 0, time.UTC)))
+	assert.True(t, session.HasClosed(tradeDate, time.Date(2024, 6, 11, 7, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TTLCache 带过期时间的并发安全内存缓存
+// 过期的缓存项在读取时视为不存在，写入时按ttl间隔顺带清理，无需后台协程；
+// GetOrCompute对同一个键的并发请求只执行一次计算，其余请求等待并共享结果
+type TTLCache[K comparable, V any] struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	entries   map[K]ttlEntry[V]
+	calls     map[K]*ttlCall[V]
+	lastSweep time.Time
+}
+
+// ttlEntry 缓存项及其过期时间
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCall 正在进行的计算，等待者通过done获取结果
+type ttlCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// NewTTLCache 创建缓存，ttl<=0时缓存项永不过期
+func NewTTLCache[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[K]ttlEntry[V]),
+		calls:   make(map[K]*ttlCall[V]),
+	}
+}
+
+// SetClock 替换判断过期使用的时钟，time.Now以外的时钟用于测试
+func (c *TTLCache[K, V]) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Get 获取未过期的缓存项
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+// Set 写入缓存项，有效期从写入时开始计算
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value)
+}
+
+// Delete 删除缓存项
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Len 未过期的缓存项数量
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	count := 0
+	for _, entry := range c.entries {
+		if !entry.expired(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// GetOrCompute 获取缓存项，不存在或已过期时调用compute计算并写入缓存
+// 同一个键同时只有一次计算，并发请求等待该次计算完成并返回相同结果；计算失败时不写入缓存，下次请求重新计算
+func (c *TTLCache[K, V]) GetOrCompute(key K, compute func() (V, error)) (V, error) {
+	c.mu.Lock()
+	if value, ok := c.getLocked(key); ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := &ttlCall[V]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	// compute发生panic时也要唤醒等待者，避免其永久阻塞
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("compute panicked: %v", r)
+			c.finishCall(key, call, false)
+			panic(r)
+		}
+	}()
+
+	call.value, call.err = compute()
+	c.finishCall(key, call, call.err == nil)
+	return call.value, call.err
+}
+
+// finishCall 结束计算并唤醒等待者，store为true时写入缓存
+func (c *TTLCache[K, V]) finishCall(key K, call *ttlCall[V], store bool) {
+	c.mu.Lock()
+	if store {
+		c.setLocked(key, call.value)
+	}
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// getLocked 获取未过期的缓存项，调用方需持有锁
+func (c *TTLCache[K, V]) getLocked(key K) (V, bool) {
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(c.now()) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// setLocked 写入缓存项，距上次清理超过ttl时顺带清理过期项，调用方需持有锁
+func (c *TTLCache[K, V]) setLocked(key K, value V) {
+	now := c.now()
+	entry := ttlEntry[V]{value: value}
+	if c.ttl > 0 {
+		entry.expiresAt = now.Add(c.ttl)
+		if now.Sub(c.lastSweep) >= c.ttl {
+			for k, e := range c.entries {
+				if e.expired(now) {
+					delete(c.entries, k)
+				}
+			}
+			c.lastSweep = now
+		}
+	}
+	c.entries[key] = entry
+}
+
+// expired 判断缓存项是否已过期，过期时间为零值表示永不过期
+func (e ttlEntry[V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newTestTTLCache(ttl time.Duration) (*TTLCache[string, int], *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 2, 9, 30, 0, 0, time.Local)}
+	cache := NewTTLCache[string, int](ttl)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestTTLCache_GetSetExpiry(t *testing.T) {
+	cache, clock := newTestTTLCache(time.Minute)
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+
+	cache.Set("a", 1)
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	clock.Advance(59 * time.Second)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+
+	// 到达过期时间即失效
+	clock.Advance(time.Second)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+
+	// 重新写入后有效期重新计算
+	cache.Set("a", 2)
+	value, ok = cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	cache.Delete("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
+
+func TestTTLCache_SweepsExpiredEntriesOnSet(t *testing.T) {
+	cache, clock := newTestTTLCache(time.Minute)
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("k%d", i), i)
+	}
+	clock.Advance(2 * time.Minute)
+	cache.Set("fresh", 1)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	assert.Len(t, cache.entries, 1)
+}
+
+func TestTTLCache_NoExpiry(t *testing.T) {
+	cache, clock := newTestTTLCache(0)
+
+	cache.Set("a", 1)
+	clock.Advance(365 * 24 * time.Hour)
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
+
+func TestTTLCache_GetOrCompute(t *testing.T) {
+	cache, clock := newTestTTLCache(time.Minute)
+
+	calls := 0
+	compute := func() (int, error) {
+		calls++
+		return calls * 10, nil
+	}
+
+	value, err := cache.GetOrCompute("a", compute)
+	require.NoError(t, err)
+	assert.Equal(t, 10, value)
+
+	// 缓存有效期内不重新计算
+	value, err = cache.GetOrCompute("a", compute)
+	require.NoError(t, err)
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 1, calls)
+
+	// 过期后重新计算
+	clock.Advance(time.Minute)
+	value, err = cache.GetOrCompute("a", compute)
+	require.NoError(t, err)
+	assert.Equal(t, 20, value)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTTLCache_GetOrComputeErrorNotCached(t *testing.T) {
+	cache, _ := newTestTTLCache(time.Minute)
+
+	fetchErr := errors.New("upstream unavailable")
+	_, err := cache.GetOrCompute("a", func() (int, error) { return 0, fetchErr })
+	assert.ErrorIs(t, err, fetchErr)
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+
+	value, err := cache.GetOrCompute("a", func() (int, error) { return 7, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 7, value)
+}
+
+func TestTTLCache_GetOrComputeOnceUnderConcurrency(t *testing.T) {
+	cache := NewTTLCache[string, int](time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	compute := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	const workers = 50
+	var started, wg sync.WaitGroup
+	results := make([]int, workers)
+	started.Add(workers)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			value, err := cache.GetOrCompute("a", compute)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+
+	// 等待所有协程启动后再放行计算，期间的并发请求都应等待同一次计算
+	started.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, value := range results {
+		assert.Equal(t, 42, value)
+	}
+}
+
+func TestTTLCache_GetOrComputeDistinctKeysInParallel(t *testing.T) {
+	cache := NewTTLCache[string, int](time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("k%d", i%10)
+			value, err := cache.GetOrCompute(key, func() (int, error) { return i % 10, nil })
+			assert.NoError(t, err)
+			assert.Equal(t, i%10, value)
+			cache.Set(key, i%10)
+			_, _ = cache.Get(key)
+			_ = cache.Len()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 10, cache.Len())
+}
+
+func TestTTLCache_GetOrComputePanicReleasesWaiters(t *testing.T) {
+	cache := NewTTLCache[string, int](time.Minute)
+
+	assert.Panics(t, func() {
+		_, _ = cache.GetOrCompute("a", func() (int, error) { panic("boom") })
+	})
+
+	// panic后不残留进行中的计算，后续请求可正常计算
+	value, err := cache.GetOrCompute("a", func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+}